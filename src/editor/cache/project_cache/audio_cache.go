@@ -0,0 +1,78 @@
+/******************************************************************************/
+/* audio_cache.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package project_cache
+
+import (
+	"kaiju/engine/assets/asset_info"
+	"kaiju/platform/audio/decoder"
+	"kaiju/platform/audio/mixer"
+	"os"
+	"path/filepath"
+)
+
+func toCachedAudioPath(path string, adiID string) string {
+	return filepath.Join(path, adiID+".wav")
+}
+
+// CacheAudio writes the already converted sound to the cache as a WAVE
+// file, the loop points are kept in the file if loopEnd is past loopStart
+func CacheAudio(adiID string, sound *mixer.Sound, loopStart, loopEnd int64) error {
+	path := cachePath(audioCache)
+	f, err := os.Create(toCachedAudioPath(path, adiID))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return decoder.EncodeWav(f, sound, loopStart, loopEnd)
+}
+
+// OpenCachedAudio opens the converted WAVE file of the audio asset, the
+// caller is responsible for closing it
+func OpenCachedAudio(adiID string) (*os.File, error) {
+	return os.Open(toCachedAudioPath(cachePath(audioCache), adiID))
+}
+
+func DeleteAudio(adi asset_info.AssetDatabaseInfo) error {
+	path := cachePath(audioCache)
+	if err := os.Remove(toCachedAudioPath(path, adi.ID)); err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -46,6 +46,7 @@ const (
 	CacheFolder = ".cache"
 	editorFile  = "editor.json"
 	meshCache   = "meshes"
+	audioCache  = "audio"
 )
 
 var createdCachePaths = make(map[string]bool)
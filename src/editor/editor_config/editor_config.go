@@ -56,6 +56,14 @@ const (
 	FileExtensionRenderPass     FileExtension = ".renderpass"
 	FileExtensionShaderPipeline FileExtension = ".shaderpipeline"
 	FileExtensionMaterial       FileExtension = ".material"
+	FileExtensionParticles      FileExtension = ".particles"
+	FileExtensionOgg            FileExtension = ".ogg"
+	FileExtensionMp3            FileExtension = ".mp3"
+	FileExtensionFlac           FileExtension = ".flac"
+	FileExtensionWav            FileExtension = ".wav"
+	FileExtensionJson           FileExtension = ".json"
+	FileExtensionCsv            FileExtension = ".csv"
+	FileExtensionPo             FileExtension = ".po"
 	FileExtensionAssetDbInfo    FileExtension = ".adi"
 )
 
@@ -75,4 +83,7 @@ const (
 	AssetTypeRenderPass     AssetType = "renderpass"
 	AssetTypeShaderPipeline AssetType = "shaderpipeline"
 	AssetTypeMaterial       AssetType = "material"
+	AssetTypeParticles      AssetType = "particles"
+	AssetTypeAudio          AssetType = "audio"
+	AssetTypeStringTable    AssetType = "stringtable"
 )
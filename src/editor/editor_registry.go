@@ -53,6 +53,12 @@ func registerAssetImporters(ed *Editor) {
 	ed.assetImporters.Register(asset_importer.RenderPassImporter{})
 	ed.assetImporters.Register(asset_importer.ShaderPipelineImporter{})
 	ed.assetImporters.Register(asset_importer.MaterialImporter{})
+	ed.assetImporters.Register(asset_importer.ParticlesImporter{})
+	ed.assetImporters.Register(asset_importer.OggImporter{})
+	ed.assetImporters.Register(asset_importer.Mp3Importer{})
+	ed.assetImporters.Register(asset_importer.FlacImporter{})
+	ed.assetImporters.Register(asset_importer.WavImporter{})
+	ed.assetImporters.Register(asset_importer.StringTableImporter{})
 }
 
 func registerContentOpeners(ed *Editor) {
@@ -114,6 +114,10 @@ func (t *TabContainer) tabDragEnd(e *document.Element) {
 }
 
 func (t *TabContainer) tabDragEnter(e *document.Element) {
+	// Entering a tab shouldn't also highlight the root the tabs are in
+	if evt := e.Event(); evt != nil {
+		evt.StopPropagation()
+	}
 	tex, err := t.host.TextureCache().Texture("textures/window_tab_drag_enter.png",
 		rendering.TextureFilterNearest)
 	if err == nil {
@@ -122,6 +126,9 @@ func (t *TabContainer) tabDragEnter(e *document.Element) {
 }
 
 func (t *TabContainer) tabDragLeave(e *document.Element) {
+	if evt := e.Event(); evt != nil {
+		evt.StopPropagation()
+	}
 	tex, err := t.host.TextureCache().Texture("textures/window_tab.png",
 		rendering.TextureFilterNearest)
 	if err == nil {
@@ -135,12 +142,9 @@ func (t *TabContainer) calcTabWidths() {
 		lbl := tabElms[i].Children[0].UI.ToLabel()
 		width := int(lbl.Measure().X() + 8)
 		tabElms[i].UI.ToPanel().Base().Layout().ScaleWidth(float32(width))
-		// The tab has a CSS hover effect so we need to alter the styling
-		for j := range tabElms[i].StyleRules {
-			if tabElms[i].StyleRules[j].Property == "width" {
-				tabElms[i].StyleRules[j].Values[0].Str = strconv.Itoa(width) + "px"
-			}
-		}
+		// The tab has a CSS hover effect so the width is kept in its inline
+		// style, otherwise restyling it would put back the original width
+		t.doc.SetAttribute(tabElms[i], "style", "width: "+strconv.Itoa(width)+"px;")
 	}
 }
 
@@ -0,0 +1,102 @@
+/******************************************************************************/
+/* audio_importer.go                                                          */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package asset_importer
+
+import (
+	"kaiju/editor/cache/project_cache"
+	"kaiju/editor/editor_config"
+	"kaiju/engine/assets/asset_info"
+	"kaiju/platform/audio"
+	"kaiju/platform/audio/convert"
+	"kaiju/platform/audio/decoder"
+	"kaiju/platform/audio/mixer"
+	"os"
+)
+
+// AudioMetadata holds the import settings of a sound, the settings are read
+// back by the audio package when the sound is loaded and played
+type AudioMetadata = audio.ImportSettings
+
+func cleanupAudio(adi asset_info.AssetDatabaseInfo) {
+	project_cache.DeleteAudio(adi)
+}
+
+// importAudio decodes the audio file and caches it converted to the sample
+// rate of the mixer, mixed down to at most stereo. This is done once here so
+// that the sound doesn't need converting each time it is loaded.
+func importAudio(importer Importer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	d, err := decoder.New(path, f)
+	if err != nil {
+		return err
+	}
+	adi, err := createADI(importer, path, cleanupAudio)
+	if err != nil {
+		return err
+	}
+	meta, ok := adi.Metadata.(*AudioMetadata)
+	if !ok {
+		defaults := audio.DefaultImportSettings()
+		meta = &defaults
+		adi.Metadata = meta
+	}
+	scale := float64(mixer.DefaultSampleRate) / float64(d.SampleRate())
+	if w, ok := d.(*decoder.WavDecoder); ok && meta.LoopEnd == 0 {
+		if start, end, ok := w.LoopPoints(); ok {
+			meta.LoopStart = int64(float64(start) * scale)
+			meta.LoopEnd = int64(float64(end) * scale)
+		}
+	}
+	sound, err := decoder.DecodeAll(d)
+	if err != nil {
+		return err
+	}
+	channels := min(sound.Channels(), mixer.OutputChannels)
+	samples := convert.Rechannel(sound.Samples(), sound.Channels(), channels)
+	samples = convert.Resample(samples, channels, sound.SampleRate(), mixer.DefaultSampleRate)
+	sound = mixer.NewSound(samples, channels, mixer.DefaultSampleRate)
+	if err := project_cache.CacheAudio(adi.ID, sound, meta.LoopStart, meta.LoopEnd); err != nil {
+		return err
+	}
+	adi.Type = editor_config.AssetTypeAudio
+	return asset_info.Write(adi)
+}
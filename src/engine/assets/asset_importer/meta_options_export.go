@@ -1,7 +1,11 @@
 package asset_importer
 
+import "kaiju/platform/audio"
+
 var (
 	MetaOptions = map[string]any{
 		"textureFilterOptions": textureFilterOptions,
+		"audioBusOptions":      audio.BusOptions,
+		"audioLoadOptions":     audio.LoadOptions,
 	}
 )
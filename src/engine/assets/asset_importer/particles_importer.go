@@ -0,0 +1,75 @@
+/******************************************************************************/
+/* particles_importer.go                                                      */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package asset_importer
+
+import (
+	"kaiju/editor/editor_config"
+	"kaiju/engine/assets/asset_info"
+	"kaiju/engine/systems/particles"
+	"kaiju/platform/filesystem"
+	"path/filepath"
+)
+
+type ParticlesImporter struct{}
+
+type ParticlesMetadata struct{}
+
+func (m ParticlesImporter) MetadataStructure() any {
+	return &ParticlesMetadata{}
+}
+
+func (m ParticlesImporter) Handles(path string) bool {
+	return filepath.Ext(path) == editor_config.FileExtensionParticles
+}
+
+func (m ParticlesImporter) Import(path string) error {
+	src, err := filesystem.ReadTextFile(path)
+	if err != nil {
+		return err
+	}
+	// Make sure the definition is valid before it is added to the database
+	if _, err := particles.LoadEmitterDefinition(src); err != nil {
+		return err
+	}
+	adi, err := createADI(m, path, nil)
+	if err != nil {
+		return err
+	}
+	adi.Type = editor_config.AssetTypeParticles
+	return asset_info.Write(adi)
+}
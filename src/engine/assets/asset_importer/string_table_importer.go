@@ -0,0 +1,90 @@
+/******************************************************************************/
+/* string_table_importer.go                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package asset_importer
+
+import (
+	"kaiju/editor/editor_config"
+	"kaiju/engine/assets/asset_info"
+	"kaiju/engine/systems/localization"
+	"kaiju/platform/filesystem"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// StringTableImporter imports the localized text of the game from JSON, CSV
+// and gettext PO files
+type StringTableImporter struct{}
+
+type StringTableMetadata struct {
+	// Language is the language of a JSON or PO file that doesn't name its
+	// own, when empty it is taken from the file name (such as "fr.po")
+	Language string
+}
+
+func (m StringTableImporter) MetadataStructure() any {
+	return &StringTableMetadata{}
+}
+
+func (m StringTableImporter) Handles(path string) bool {
+	return slices.Contains([]string{editor_config.FileExtensionJson,
+		editor_config.FileExtensionCsv, editor_config.FileExtensionPo},
+		strings.ToLower(filepath.Ext(path)))
+}
+
+func (m StringTableImporter) Import(path string) error {
+	data, err := filesystem.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	adi, err := createADI(m, path, nil)
+	if err != nil {
+		return err
+	}
+	md, ok := adi.Metadata.(*StringTableMetadata)
+	if !ok {
+		md = &StringTableMetadata{}
+		adi.Metadata = md
+	}
+	// Make sure the tables can be read before they are added to the database
+	if _, err := localization.ParseFile(path, data, md.Language); err != nil {
+		return err
+	}
+	adi.Type = editor_config.AssetTypeStringTable
+	return asset_info.Write(adi)
+}
@@ -38,8 +38,10 @@
 package assets
 
 import (
+	"io"
 	"kaiju/platform/filesystem"
 	"kaiju/platform/profiler/tracing"
+	"os"
 )
 
 type Database struct {
@@ -60,6 +62,14 @@ func (a *Database) Read(key string) ([]byte, error) {
 	return filesystem.ReadFile(a.toContentPath(key))
 }
 
+// Open opens the asset for reading without loading all of it into memory,
+// this is used for assets that are streamed, such as music. The caller is
+// responsible for closing the returned file.
+func (a *Database) Open(key string) (io.ReadSeekCloser, error) {
+	defer tracing.NewRegion("AssetDatabase::Open: " + key).End()
+	return os.Open(a.toContentPath(key))
+}
+
 func (a *Database) Exists(key string) bool {
 	return filesystem.FileExists(a.toContentPath(key))
 }
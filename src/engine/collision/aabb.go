@@ -86,6 +86,12 @@ func (box *AABB) LongestAxis() int {
 // Size returns the size of the AABB
 func (box AABB) Size() matrix.Vec3 { return box.Extent.Scale(2) }
 
+// SurfaceArea returns the total area of the 6 faces of the AABB
+func (box AABB) SurfaceArea() matrix.Float {
+	s := box.Size()
+	return 2 * (s.X()*s.Y() + s.Y()*s.Z() + s.Z()*s.X())
+}
+
 // ClosestDistance returns the closest distance between two AABBs
 func (a AABB) ClosestDistance(b AABB) matrix.Float {
 	d := a.Center.Subtract(b.Center)
@@ -126,6 +132,36 @@ func (box *AABB) RayHit(ray Ray) (matrix.Vec3, bool) {
 	return hit, true
 }
 
+// RayDistance returns the distance along the ray where it enters the AABB and
+// whether the ray hit the AABB. If the ray starts inside of the AABB, then the
+// distance will be 0. The distance is in units of the ray direction length.
+func (box *AABB) RayDistance(ray Ray) (matrix.Float, bool) {
+	tMin := matrix.Float(0)
+	tMax := matrix.Inf(1)
+	for i := 0; i < 3; i++ {
+		bMin := box.Center[i] - box.Extent[i]
+		bMax := box.Center[i] + box.Extent[i]
+		if matrix.Abs(ray.Direction[i]) < matrix.FloatSmallestNonzero {
+			if ray.Origin[i] < bMin || ray.Origin[i] > bMax {
+				return 0, false
+			}
+		} else {
+			ood := 1.0 / ray.Direction[i]
+			t1 := (bMin - ray.Origin[i]) * ood
+			t2 := (bMax - ray.Origin[i]) * ood
+			if t1 > t2 {
+				t1, t2 = t2, t1
+			}
+			tMin = max(tMin, t1)
+			tMax = min(tMax, t2)
+			if tMin > tMax {
+				return 0, false
+			}
+		}
+	}
+	return tMin, true
+}
+
 // Contains returns whether the AABB contains the point
 func (box *AABB) Contains(point matrix.Vec3) bool {
 	return point.X() >= (box.Center.X()-box.Extent.X()) &&
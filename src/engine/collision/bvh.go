@@ -241,6 +241,68 @@ func BVHInsert(into, other *BVH) *BVH {
 	}
 }
 
+// Refit recalculates the bounds of this node and all of its children without
+// changing the structure of the tree. Leaf bounds are pulled from their data,
+// so this is to be called after the data (such as the triangles of a skinned
+// or deformed mesh) has moved. Refitting is much cheaper than rebuilding, but
+// the tree will slowly lose quality if the data moves far from where it was
+// when the tree was built.
+func (b *BVH) Refit() {
+	if b.IsLeaf() {
+		if b.Data != nil {
+			b.bounds = b.Data.Bounds()
+		}
+		return
+	}
+	if b.Left != nil {
+		b.Left.Refit()
+	}
+	if b.Right != nil {
+		b.Right.Refit()
+	}
+	if b.Left != nil && b.Right != nil {
+		b.bounds = AABBUnion(b.Left.Bounds(), b.Right.Bounds())
+	} else if b.Left != nil {
+		b.bounds = b.Left.Bounds()
+	} else {
+		b.bounds = b.Right.Bounds()
+	}
+}
+
+// ClosestHit traverses the BVH in its local space and returns the closest
+// leaf data that the ray hits within the given length, along with the distance
+// to the hit. The #BVH.Transform of each node is ignored, to raycast against
+// transformed BVHs use a #TopLevelBVH.
+func (b *BVH) ClosestHit(ray Ray, length matrix.Float) (HitObject, matrix.Float, bool) {
+	closest := length
+	var hit HitObject
+	stack := []*BVH{b}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if d, ok := node.bounds.RayDistance(ray); !ok || d > closest {
+			continue
+		}
+		if node.IsLeaf() {
+			if node.Data == nil {
+				continue
+			}
+			if d, ok := hitObjectDistance(node.Data, ray, closest); ok && d <= closest {
+				closest = d
+				hit = node.Data
+			}
+			continue
+		}
+		if node.Left != nil {
+			stack = append(stack, node.Left)
+		}
+		if node.Right != nil {
+			stack = append(stack, node.Right)
+		}
+	}
+	return hit, closest, hit != nil
+}
+
 // RemoveNode removes a node from the BVH and adjusts the tree accordingly. If
 // the node is the root, nothing is done.
 func (b *BVH) RemoveNode() {
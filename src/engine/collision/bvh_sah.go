@@ -0,0 +1,236 @@
+/******************************************************************************/
+/* bvh_sah.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package collision
+
+import (
+	"kaiju/matrix"
+	"kaiju/platform/concurrent"
+	"sync"
+)
+
+const (
+	// sahBinCount is the number of buckets that the centroids are placed in
+	// along each axis when searching for the cheapest split
+	sahBinCount = 12
+	// sahParallelMinLeaves is the smallest number of leaves a branch must
+	// have before it is handed off to another thread during a parallel build
+	sahParallelMinLeaves = 512
+)
+
+type sahBin struct {
+	bounds AABB
+	count  int
+}
+
+// BVHBuildSAH constructs a BVH from a list of triangles using a binned surface
+// area heuristic (SAH). This takes longer to build than #BVHBottomUp for small
+// meshes, but produces a tree that is much cheaper to traverse. The leaves of
+// the tree point directly into the triangles slice, so the slice should be
+// kept around if the BVH is to be refit later using #BVH.Refit.
+func BVHBuildSAH(triangles []DetailedTriangle) *BVH {
+	return BVHBuildSAHParallel(triangles, nil)
+}
+
+// BVHBuildSAHParallel is the same as #BVHBuildSAH, except that large branches
+// of the tree are built on the supplied threads. The threads are expected to
+// already be started. If threads is nil, the build will happen entirely on
+// the calling goroutine.
+func BVHBuildSAHParallel(triangles []DetailedTriangle, threads *concurrent.Threads) *BVH {
+	if len(triangles) == 0 {
+		return NewBVH()
+	}
+	leaves := make([]*BVH, len(triangles))
+	for i := range triangles {
+		leaves[i] = &BVH{
+			bounds: triangles[i].Bounds(),
+			Data:   &triangles[i],
+		}
+	}
+	return bvhBuildSAHLeaves(leaves, threads)
+}
+
+// BVHBuildSAHObjects constructs a BVH from arbitrary hit objects using the
+// same surface area heuristic as #BVHBuildSAH. Each object will be placed into
+// its own leaf of the tree.
+func BVHBuildSAHObjects(objects []HitObject) *BVH {
+	if len(objects) == 0 {
+		return NewBVH()
+	}
+	leaves := make([]*BVH, len(objects))
+	for i := range objects {
+		leaves[i] = &BVH{
+			bounds: objects[i].Bounds(),
+			Data:   objects[i],
+		}
+	}
+	return bvhBuildSAHLeaves(leaves, nil)
+}
+
+func bvhBuildSAHLeaves(leaves []*BVH, threads *concurrent.Threads) *BVH {
+	wg := sync.WaitGroup{}
+	// Only split off work for the top few levels of the tree, otherwise the
+	// threads pipe could be flooded by workers waiting to add more work
+	parallelDepth := 0
+	if threads != nil {
+		for (1 << parallelDepth) < threads.ThreadCount()*2 {
+			parallelDepth++
+		}
+	}
+	root := sahBuild(leaves, threads, &wg, parallelDepth)
+	wg.Wait()
+	root.Parent = nil
+	return root
+}
+
+func sahBuild(leaves []*BVH, threads *concurrent.Threads, wg *sync.WaitGroup, parallelDepth int) *BVH {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	bounds := leaves[0].Bounds()
+	cMin := bounds.Center
+	cMax := bounds.Center
+	for i := 1; i < len(leaves); i++ {
+		b := leaves[i].Bounds()
+		bounds = AABBUnion(bounds, b)
+		cMin = matrix.Vec3Min(cMin, b.Center)
+		cMax = matrix.Vec3Max(cMax, b.Center)
+	}
+	mid := sahPartition(leaves, cMin, cMax)
+	node := &BVH{bounds: bounds}
+	left, right := leaves[:mid], leaves[mid:]
+	if threads != nil && parallelDepth > 0 && len(leaves) >= sahParallelMinLeaves {
+		wg.Add(1)
+		threads.AddWork(func(int) {
+			node.Left = sahBuild(left, threads, wg, parallelDepth-1)
+			node.Left.Parent = node
+			wg.Done()
+		})
+	} else {
+		node.Left = sahBuild(left, threads, wg, parallelDepth-1)
+		node.Left.Parent = node
+	}
+	node.Right = sahBuild(right, threads, wg, parallelDepth-1)
+	node.Right.Parent = node
+	return node
+}
+
+// sahPartition sorts the leaves in place so that all of the leaves for the
+// left side of the split come first, it returns the index of the first leaf
+// for the right side of the split
+func sahPartition(leaves []*BVH, cMin, cMax matrix.Vec3) int {
+	extent := cMax.Subtract(cMin)
+	bestAxis := -1
+	bestSplit := 0
+	bestCost := matrix.Inf(1)
+	var bins [sahBinCount]sahBin
+	var rightArea [sahBinCount]matrix.Float
+	var rightCount [sahBinCount]int
+	for axis := 0; axis < 3; axis++ {
+		if extent[axis] <= matrix.FloatSmallestNonzero {
+			continue
+		}
+		for i := range bins {
+			bins[i] = sahBin{}
+		}
+		for i := range leaves {
+			b := leaves[i].Bounds()
+			idx := sahBinIndex(b.Center[axis], cMin[axis], extent[axis])
+			if bins[idx].count == 0 {
+				bins[idx].bounds = b
+			} else {
+				bins[idx].bounds = AABBUnion(bins[idx].bounds, b)
+			}
+			bins[idx].count++
+		}
+		// Sweep from the right to collect the area/count for each split
+		acc := AABB{}
+		count := 0
+		for i := sahBinCount - 1; i > 0; i-- {
+			acc, count = sahAccumulate(acc, count, bins[i])
+			rightArea[i] = acc.SurfaceArea()
+			rightCount[i] = count
+		}
+		acc = AABB{}
+		count = 0
+		for i := 0; i < sahBinCount-1; i++ {
+			acc, count = sahAccumulate(acc, count, bins[i])
+			if count == 0 || rightCount[i+1] == 0 {
+				continue
+			}
+			cost := acc.SurfaceArea()*matrix.Float(count) +
+				rightArea[i+1]*matrix.Float(rightCount[i+1])
+			if cost < bestCost {
+				bestCost = cost
+				bestAxis = axis
+				bestSplit = i + 1
+			}
+		}
+	}
+	if bestAxis < 0 {
+		// All of the centroids are in the same location, so any split is
+		// as good as another
+		return len(leaves) / 2
+	}
+	mid := 0
+	for i := range leaves {
+		c := leaves[i].Bounds().Center[bestAxis]
+		if sahBinIndex(c, cMin[bestAxis], extent[bestAxis]) < bestSplit {
+			leaves[i], leaves[mid] = leaves[mid], leaves[i]
+			mid++
+		}
+	}
+	if mid == 0 || mid == len(leaves) {
+		return len(leaves) / 2
+	}
+	return mid
+}
+
+func sahBinIndex(center, low, extent matrix.Float) int {
+	idx := int((center - low) / extent * sahBinCount)
+	return max(0, min(idx, sahBinCount-1))
+}
+
+func sahAccumulate(acc AABB, count int, bin sahBin) (AABB, int) {
+	if bin.count == 0 {
+		return acc, count
+	}
+	if count == 0 {
+		return bin.bounds, bin.count
+	}
+	return AABBUnion(acc, bin.bounds), count + bin.count
+}
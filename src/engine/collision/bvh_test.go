@@ -0,0 +1,171 @@
+/******************************************************************************/
+/* bvh_test.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package collision
+
+import (
+	"kaiju/matrix"
+	"kaiju/platform/concurrent"
+	"testing"
+)
+
+func testGridTriangles(width, depth int) []DetailedTriangle {
+	tris := make([]DetailedTriangle, 0, width*depth*2)
+	for x := 0; x < width; x++ {
+		for z := 0; z < depth; z++ {
+			fx, fz := matrix.Float(x), matrix.Float(z)
+			tris = append(tris, DetailedTriangleFromPoints([3]matrix.Vec3{
+				{fx, 0, fz}, {fx + 1, 0, fz}, {fx, 0, fz + 1},
+			}))
+			tris = append(tris, DetailedTriangleFromPoints([3]matrix.Vec3{
+				{fx + 1, 0, fz}, {fx + 1, 0, fz + 1}, {fx, 0, fz + 1},
+			}))
+		}
+	}
+	return tris
+}
+
+func testCountLeaves(t *testing.T, b *BVH) int {
+	if b.IsLeaf() {
+		return 1
+	}
+	count := 0
+	for _, c := range []*BVH{b.Left, b.Right} {
+		if c == nil {
+			continue
+		}
+		if c.Parent != b {
+			t.Fatal("child does not point back to its parent")
+		}
+		if !b.bounds.ContainsAABB(c.bounds) {
+			t.Fatal("node bounds do not contain child bounds")
+		}
+		count += testCountLeaves(t, c)
+	}
+	return count
+}
+
+func TestBVHBuildSAH(t *testing.T) {
+	tris := testGridTriangles(8, 8)
+	bvh := BVHBuildSAH(tris)
+	if count := testCountLeaves(t, bvh); count != len(tris) {
+		t.Fatalf("expected %d leaves, got %d", len(tris), count)
+	}
+	ray := Ray{Origin: matrix.Vec3{3.25, 5, 4.25}, Direction: matrix.Vec3Down()}
+	hit, dist, ok := bvh.ClosestHit(ray, 100)
+	if !ok {
+		t.Fatal("expected hit")
+	}
+	if !matrix.ApproxTo(dist, 5, 0.0001) {
+		t.Errorf("expected distance 5, got %f", dist)
+	}
+	if b := hit.Bounds(); !b.Contains(ray.Point(dist)) {
+		t.Error("hit triangle does not contain the hit point")
+	}
+	ray.Origin = matrix.Vec3{-3, 5, -3}
+	if _, _, ok := bvh.ClosestHit(ray, 100); ok {
+		t.Error("expected miss")
+	}
+}
+
+func TestBVHBuildSAHParallel(t *testing.T) {
+	threads := concurrent.NewThreads()
+	threads.Start()
+	defer threads.Stop()
+	tris := testGridTriangles(64, 64)
+	bvh := BVHBuildSAHParallel(tris, &threads)
+	if count := testCountLeaves(t, bvh); count != len(tris) {
+		t.Fatalf("expected %d leaves, got %d", len(tris), count)
+	}
+	ray := Ray{Origin: matrix.Vec3{40.5, 2, 10.1}, Direction: matrix.Vec3Down()}
+	if _, dist, ok := bvh.ClosestHit(ray, 10); !ok || !matrix.ApproxTo(dist, 2, 0.0001) {
+		t.Error("expected hit at distance 2")
+	}
+}
+
+func TestBVHRefit(t *testing.T) {
+	tris := testGridTriangles(4, 4)
+	bvh := BVHBuildSAH(tris)
+	ray := Ray{Origin: matrix.Vec3{1.25, 10, 1.25}, Direction: matrix.Vec3Down()}
+	for i := range tris {
+		p := tris[i].Points
+		for j := range p {
+			p[j].AddAssign(matrix.Vec3{0, 3, 0})
+		}
+		tris[i].SetPoints(p)
+	}
+	bvh.Refit()
+	testCountLeaves(t, bvh)
+	if _, dist, ok := bvh.ClosestHit(ray, 100); !ok || !matrix.ApproxTo(dist, 7, 0.0001) {
+		t.Errorf("expected hit at distance 7 after refit, got %f", dist)
+	}
+}
+
+func TestTopLevelBVHRayCast(t *testing.T) {
+	blas := BVHBuildSAH(testGridTriangles(2, 2))
+	tlas := NewTopLevelBVH()
+	wg := concurrent.WorkGroup{}
+	near := matrix.NewTransform(&wg)
+	near.SetPosition(matrix.Vec3{0, 2, 0})
+	far := matrix.NewTransform(&wg)
+	far.SetPosition(matrix.Vec3{10, 0, 0})
+	nearInst := tlas.Add(blas, &near, "near")
+	tlas.Add(blas, &far, "far")
+	tlas.Add(blas, nil, "origin")
+	ray := Ray{Origin: matrix.Vec3{1, 10, 1}, Direction: matrix.Vec3Down()}
+	hit, ok := tlas.RayCast(ray, 100)
+	if !ok || hit.Instance != nearInst {
+		t.Fatal("expected to hit the near instance")
+	}
+	if !matrix.ApproxTo(hit.Distance, 8, 0.0001) {
+		t.Errorf("expected distance 8, got %f", hit.Distance)
+	}
+	ray.Origin = matrix.Vec3{11, 10, 1}
+	if hit, ok = tlas.RayCast(ray, 100); !ok || hit.Instance.Target != "far" {
+		t.Fatal("expected to hit the far instance")
+	}
+	far.SetPosition(matrix.Vec3{20, 0, 0})
+	tlas.Refit()
+	if _, ok = tlas.RayCast(ray, 100); ok {
+		t.Error("expected miss after the far instance moved")
+	}
+	tlas.Remove(nearInst)
+	ray.Origin = matrix.Vec3{1, 10, 1}
+	if hit, ok = tlas.RayCast(ray, 100); !ok || hit.Instance.Target != "origin" {
+		t.Error("expected to hit the origin instance after removing near")
+	}
+}
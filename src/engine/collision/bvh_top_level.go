@@ -0,0 +1,259 @@
+/******************************************************************************/
+/* bvh_top_level.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package collision
+
+import (
+	"kaiju/klib"
+	"kaiju/matrix"
+)
+
+// RayDistanceObject is a #HitObject that is able to report how far along a
+// ray it was hit. Objects that implement this will be correctly sorted when
+// searching for the closest hit in a BVH, otherwise the distance to the
+// object's bounds will be used.
+type RayDistanceObject interface {
+	HitObject
+	RayDistance(ray Ray, length matrix.Float) (matrix.Float, bool)
+}
+
+// BVHInstance is a bottom level BVH (BLAS) that has been placed into the world
+// through a transform. Many instances can share the same BLAS, for example
+// when the same mesh is used on multiple entities.
+type BVHInstance struct {
+	BLAS      *BVH
+	Transform *matrix.Transform
+	// Target is any arbitrary data that should be returned on a hit, this is
+	// typically the entity that owns the transform
+	Target any
+}
+
+// TopLevelBVH is a BVH where each of the leaves is a #BVHInstance. It is used
+// to raycast against an entire scene where each entity has its own BLAS that
+// can freely move around through its transform.
+type TopLevelBVH struct {
+	root      *BVH
+	instances []*BVHInstance
+	dirty     bool
+}
+
+// TopLevelBVHHit is the result of a successful raycast against a #TopLevelBVH
+type TopLevelBVHHit struct {
+	Instance *BVHInstance
+	Data     HitObject
+	Point    matrix.Vec3
+	Distance matrix.Float
+}
+
+// NewTopLevelBVH creates a new, empty, top level BVH
+func NewTopLevelBVH() *TopLevelBVH {
+	return &TopLevelBVH{
+		instances: make([]*BVHInstance, 0),
+	}
+}
+
+// Bounds returns the world space AABB that contains the transformed BLAS
+func (i *BVHInstance) Bounds() AABB {
+	local := i.BLAS.bounds
+	if i.Transform == nil {
+		return local
+	}
+	mat := i.Transform.WorldMatrix()
+	lMin := local.Min()
+	lMax := local.Max()
+	wMin := matrix.Vec3Inf(1)
+	wMax := matrix.Vec3Inf(-1)
+	for c := 0; c < 8; c++ {
+		corner := lMin
+		if c&1 != 0 {
+			corner[matrix.Vx] = lMax.X()
+		}
+		if c&2 != 0 {
+			corner[matrix.Vy] = lMax.Y()
+		}
+		if c&4 != 0 {
+			corner[matrix.Vz] = lMax.Z()
+		}
+		p := mat.TransformPoint(corner)
+		wMin = matrix.Vec3Min(wMin, p)
+		wMax = matrix.Vec3Max(wMax, p)
+	}
+	return AABBFromMinMax(wMin, wMax)
+}
+
+// RayIntersect returns true if the world space ray hits the BLAS within the
+// given length
+func (i *BVHInstance) RayIntersect(ray Ray, length float32) bool {
+	_, ok := i.RayDistance(ray, length)
+	return ok
+}
+
+// RayDistance returns the distance along the world space ray to the closest
+// hit within the BLAS
+func (i *BVHInstance) RayDistance(ray Ray, length matrix.Float) (matrix.Float, bool) {
+	_, d, ok := i.closestHit(ray, length)
+	return d, ok
+}
+
+func (i *BVHInstance) closestHit(ray Ray, length matrix.Float) (HitObject, matrix.Float, bool) {
+	return i.BLAS.ClosestHit(i.localRay(ray), length)
+}
+
+// localRay moves the ray into the local space of the BLAS. The direction is
+// intentionally not normalized so that distances along the local ray match
+// the distances along the world ray.
+func (i *BVHInstance) localRay(ray Ray) Ray {
+	if i.Transform == nil {
+		return ray
+	}
+	inv := i.Transform.WorldMatrix()
+	inv.Inverse()
+	origin := inv.TransformPoint(ray.Origin)
+	return Ray{
+		Origin:    origin,
+		Direction: inv.TransformPoint(ray.Origin.Add(ray.Direction)).Subtract(origin),
+	}
+}
+
+// Instances returns all of the instances that have been added to the BVH
+func (t *TopLevelBVH) Instances() []*BVHInstance { return t.instances }
+
+// Add will place the BLAS into the top level BVH through the given transform.
+// The transform can be nil if the BLAS is already in world space. The tree
+// will be rebuilt on the next call to #TopLevelBVH.Refit or a raycast.
+func (t *TopLevelBVH) Add(blas *BVH, transform *matrix.Transform, target any) *BVHInstance {
+	inst := &BVHInstance{
+		BLAS:      blas,
+		Transform: transform,
+		Target:    target,
+	}
+	t.instances = append(t.instances, inst)
+	t.dirty = true
+	return inst
+}
+
+// Remove will remove the instance from the top level BVH. The tree will be
+// rebuilt on the next call to #TopLevelBVH.Refit or a raycast.
+func (t *TopLevelBVH) Remove(instance *BVHInstance) {
+	for i := range t.instances {
+		if t.instances[i] == instance {
+			t.instances = klib.RemoveUnordered(t.instances, i)
+			t.dirty = true
+			return
+		}
+	}
+}
+
+// Rebuild will construct the tree from scratch using the current bounds of all
+// of the instances
+func (t *TopLevelBVH) Rebuild() {
+	t.dirty = false
+	if len(t.instances) == 0 {
+		t.root = nil
+		return
+	}
+	objects := make([]HitObject, len(t.instances))
+	for i := range t.instances {
+		objects[i] = t.instances[i]
+	}
+	t.root = BVHBuildSAHObjects(objects)
+}
+
+// Refit will update the bounds of the tree to match the current transforms of
+// all of the instances. This should be called after instances have moved or
+// their BLAS has been refit. If instances were added or removed since the last
+// build, then the tree is rebuilt instead.
+func (t *TopLevelBVH) Refit() {
+	if t.dirty || t.root == nil {
+		t.Rebuild()
+	} else {
+		t.root.Refit()
+	}
+}
+
+// RayCast will find the closest instance that the ray hits within the given
+// length. The ray direction is expected to be normalized so that the returned
+// distance is in world units.
+func (t *TopLevelBVH) RayCast(ray Ray, length matrix.Float) (TopLevelBVHHit, bool) {
+	if t.dirty {
+		t.Rebuild()
+	}
+	hit := TopLevelBVHHit{Distance: length}
+	if t.root == nil {
+		return hit, false
+	}
+	found := false
+	stack := []*BVH{t.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if d, ok := node.bounds.RayDistance(ray); !ok || d > hit.Distance {
+			continue
+		}
+		if node.IsLeaf() {
+			inst := node.Data.(*BVHInstance)
+			if data, d, ok := inst.closestHit(ray, hit.Distance); ok {
+				hit.Instance = inst
+				hit.Data = data
+				hit.Distance = d
+				found = true
+			}
+			continue
+		}
+		if node.Left != nil {
+			stack = append(stack, node.Left)
+		}
+		if node.Right != nil {
+			stack = append(stack, node.Right)
+		}
+	}
+	if found {
+		hit.Point = ray.Point(hit.Distance)
+	}
+	return hit, found
+}
+
+func hitObjectDistance(obj HitObject, ray Ray, length matrix.Float) (matrix.Float, bool) {
+	if rd, ok := obj.(RayDistanceObject); ok {
+		return rd.RayDistance(ray, length)
+	}
+	if !obj.RayIntersect(ray, length) {
+		return 0, false
+	}
+	b := obj.Bounds()
+	return b.RayDistance(ray)
+}
@@ -69,6 +69,40 @@ func (t *DetailedTriangle) RayIntersect(ray Ray, length float32) bool {
 	return ray.TriangleHit(length, t.Points[0], t.Points[1], t.Points[2])
 }
 
+// RayDistance returns the distance along the ray to the point where it hits
+// the triangle and whether or not the ray hit within the given length. Both
+// sides of the triangle are considered for the hit. The distance is in units
+// of the ray direction length.
+func (t *DetailedTriangle) RayDistance(ray Ray, length matrix.Float) (matrix.Float, bool) {
+	e0 := t.Points[1].Subtract(t.Points[0])
+	e1 := t.Points[2].Subtract(t.Points[0])
+	p := matrix.Vec3Cross(ray.Direction, e1)
+	det := matrix.Vec3Dot(e0, p)
+	if matrix.Abs(det) < matrix.FloatSmallestNonzero {
+		return 0, false
+	}
+	invDet := 1.0 / det
+	s := ray.Origin.Subtract(t.Points[0])
+	u := matrix.Vec3Dot(s, p) * invDet
+	if u < 0 || u > 1 {
+		return 0, false
+	}
+	q := matrix.Vec3Cross(s, e0)
+	v := matrix.Vec3Dot(ray.Direction, q) * invDet
+	if v < 0 || u+v > 1 {
+		return 0, false
+	}
+	dist := matrix.Vec3Dot(e1, q) * invDet
+	return dist, dist >= 0 && dist <= length
+}
+
+// SetPoints updates the points of the triangle and recalculates the normal,
+// centroid, and radius. This is typically followed by a call to #BVH.Refit
+// on any BVH that is referencing the triangle.
+func (t *DetailedTriangle) SetPoints(points [3]matrix.Vec3) {
+	*t = DetailedTriangleFromPoints(points)
+}
+
 // DetailedTriangleFromPoints creates a detailed triangle from three points, a
 // detailed triangle is different from a regular triangle in that it contains
 // additional information such as the centroid and radius
@@ -40,4 +40,7 @@ package engine
 const (
 	DefaultWindowWidth  = 944
 	DefaultWindowHeight = 500
+	// DefaultLanguage is the starting and fallback language of the host's
+	// localization
+	DefaultLanguage = "en"
 )
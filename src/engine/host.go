@@ -48,6 +48,7 @@ import (
 	"kaiju/platform/profiler/tracing"
 	"kaiju/rendering"
 	"kaiju/engine/systems/events"
+	"kaiju/engine/systems/localization"
 	"kaiju/engine/systems/logging"
 	"kaiju/platform/windowing"
 	"math"
@@ -108,6 +109,8 @@ type Host struct {
 	CloseSignal      chan struct{}
 	frameRateLimit   *time.Ticker
 	inEditorEntity   int
+	inputHook        func(deltaTime float64) float64
+	localizer        *localization.Localizer
 }
 
 // NewHost creates a new host with the given name and log stream. The log stream
@@ -137,6 +140,7 @@ func NewHost(name string, logStream *logging.LogStream) *Host {
 		frameRunner:    make([]frameRun, 0),
 		entityLookup:   make(map[EntityId]*Entity),
 		threads:        concurrent.NewThreads(),
+		localizer:      localization.New(DefaultLanguage),
 	}
 	return host
 }
@@ -239,6 +243,11 @@ func (host *Host) AssetDatabase() *assets.Database {
 	return &host.assetDatabase
 }
 
+// Localization returns the translated text of the game for the host
+func (host *Host) Localization() *localization.Localizer {
+	return host.localizer
+}
+
 // Audio returns the audio system for the host
 func (host *Host) Audio() *audio.Audio {
 	return &host.audio
@@ -334,8 +343,11 @@ func (host *Host) NewEntity() *Entity {
 func (host *Host) Update(deltaTime float64) {
 	defer tracing.NewRegion("Host::Update").End()
 	host.frame++
-	host.frameTime += deltaTime
 	host.Window.Poll()
+	if host.inputHook != nil {
+		deltaTime = host.inputHook(deltaTime)
+	}
+	host.frameTime += deltaTime
 	for i := 0; i < len(host.frameRunner); i++ {
 		if host.frameRunner[i].frame <= host.frame {
 			host.frameRunner[i].call()
@@ -346,6 +358,7 @@ func (host *Host) Update(deltaTime float64) {
 	host.UIUpdater.Update(deltaTime)
 	host.UILateUpdater.Update(deltaTime)
 	host.Updater.Update(deltaTime)
+	host.updateAudioListener(deltaTime)
 	host.LateUpdater.Update(deltaTime)
 	host.collisionManager.Update(deltaTime)
 	if host.Window.IsClosed() || host.Window.IsCrashed() {
@@ -366,6 +379,21 @@ func (host *Host) Update(deltaTime float64) {
 	host.Window.EndUpdate()
 }
 
+// updateAudioListener moves the audio listener to the camera, or to the
+// transform the listener has been set to follow. This happens after the
+// regular updates so that emitters updating in the late update hear from
+// where the camera is this frame.
+func (host *Host) updateAudioListener(deltaTime float64) {
+	listener := host.audio.Listener()
+	if t := host.audio.ListenerTransform(); t != nil {
+		world := t.WorldMatrix()
+		listener.Update(t.WorldPosition(), world.Forward(), world.Up(), deltaTime)
+	} else if host.Camera != nil {
+		listener.Update(host.Camera.Position(), host.Camera.Forward(),
+			host.Camera.Up(), deltaTime)
+	}
+}
+
 // Render will render the scene. This starts by preparing any drawings that are
 // pending. It also creates any pending shaders, textures, and meshes before
 // the start of the render. The frame is then readied, buffers swapped, and any
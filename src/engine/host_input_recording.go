@@ -0,0 +1,91 @@
+/******************************************************************************/
+/* host_input_recording.go                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package engine
+
+import "kaiju/platform/hid/input_recording"
+
+// RecordInput starts recording the input of the window each frame, along
+// with the frame's delta time. The seed is kept with the recording so that
+// the game can seed its random numbers the same way when it is played back.
+// Any recording or playback that is already running is stopped.
+func (host *Host) RecordInput(seed int64) *input_recording.Recorder {
+	host.StopInputRecording()
+	r := input_recording.NewRecorder(host.inputDevices(), seed)
+	host.inputHook = func(deltaTime float64) float64 {
+		r.Capture(deltaTime)
+		return deltaTime
+	}
+	return r
+}
+
+// PlayInput feeds the recording into the window's devices one frame at a
+// time, each frame is updated with the delta time it was recorded with so
+// that the session plays out the same way. The window's own input is
+// ignored while the recording plays so that it isn't mixed in with the
+// recorded input. Once the recording has finished the host goes back to its
+// regular input and delta time.
+func (host *Host) PlayInput(recording *input_recording.Recording) *input_recording.Player {
+	host.StopInputRecording()
+	p := input_recording.NewPlayer(recording, host.inputDevices())
+	host.Window.SetInputIgnored(true)
+	host.inputHook = func(deltaTime float64) float64 {
+		if dt, ok := p.Next(); ok {
+			return dt
+		}
+		host.StopInputRecording()
+		return deltaTime
+	}
+	return p
+}
+
+// StopInputRecording stops any input recording or playback that is running
+func (host *Host) StopInputRecording() {
+	host.inputHook = nil
+	host.Window.SetInputIgnored(false)
+}
+
+func (host *Host) inputDevices() input_recording.Devices {
+	w := host.Window
+	return input_recording.Devices{
+		Keyboard:   &w.Keyboard,
+		Mouse:      &w.Mouse,
+		Controller: &w.Controller,
+		Touch:      &w.Touch,
+		Stylus:     &w.Stylus,
+	}
+}
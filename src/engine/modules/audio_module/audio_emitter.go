@@ -0,0 +1,191 @@
+package audio_module
+
+import (
+	"kaiju/engine"
+	"kaiju/matrix"
+	"kaiju/platform/audio"
+	"kaiju/platform/audio/mixer"
+	"kaiju/platform/audio/spatial"
+	"log/slog"
+)
+
+const (
+	AudioEmitterEntityDataName = "AudioEmitter"
+)
+
+type AudioEmitterModuleBinding struct {
+	Sound string
+	// Bus is the mixer bus to play through, empty plays through the bus the
+	// sound was imported to
+	Bus    string
+	Volume float32 `default:"1"`
+	Pitch  float32 `default:"1"`
+	Loop   bool
+	// Stream streams the sound even if it was imported to be preloaded
+	Stream      bool
+	PlayOnStart bool `default:"true"`
+	// 0 = none, 1 = inverse, 2 = linear, 3 = exponential
+	Attenuation    int     `clamp:"1,0,3"`
+	MinDistance    float32 `default:"1"`
+	MaxDistance    float32 `default:"100"`
+	Rolloff        float32 `default:"1"`
+	ConeInnerAngle float32 `default:"360"`
+	ConeOuterAngle float32 `default:"360"`
+	ConeOuterGain  float32 `default:"1"`
+	DopplerFactor  float32 `default:"1"`
+}
+
+// AudioEmitter plays a sound from the position of its entity. Every frame
+// the volume, pan and pitch of the sound are updated from where the entity
+// is relative to the listener of the host's audio.
+type AudioEmitter struct {
+	host     *engine.Host
+	entity   *engine.Entity
+	key      string
+	stream   bool
+	sound    *mixer.Sound
+	voice    *mixer.Voice
+	options  mixer.PlayOptions
+	spatial  spatial.Emitter
+	updateId int
+}
+
+func (b *AudioEmitterModuleBinding) Init(e *engine.Entity, host *engine.Host) {
+	settings, _ := audio.ReadImportSettings(b.Sound)
+	a := &AudioEmitter{
+		host:    host,
+		entity:  e,
+		key:     b.Sound,
+		stream:  b.Stream || settings.IsStream(),
+		options: settings.PlayOptions(),
+		spatial: spatial.NewEmitter(),
+	}
+	if b.Bus != "" {
+		a.options.Bus = b.Bus
+	}
+	a.options.Volume = max(b.Volume, 0)
+	a.options.Pitch = b.Pitch
+	a.options.Loop = b.Loop
+	a.spatial.Attenuation = spatial.AttenuationModel(b.Attenuation)
+	a.spatial.MinDistance = matrix.Float(b.MinDistance)
+	a.spatial.MaxDistance = matrix.Float(b.MaxDistance)
+	a.spatial.Rolloff = matrix.Float(b.Rolloff)
+	a.spatial.Cone = spatial.Cone{
+		InnerAngle: matrix.Float(b.ConeInnerAngle),
+		OuterAngle: matrix.Float(b.ConeOuterAngle),
+		OuterGain:  matrix.Float(b.ConeOuterGain),
+	}
+	a.spatial.DopplerFactor = matrix.Float(b.DopplerFactor)
+	a.place(0)
+	// Emitters update late so they hear from where the listener is this frame
+	a.updateId = host.LateUpdater.AddUpdate(a.update)
+	e.AddNamedData(AudioEmitterEntityDataName, a)
+	e.OnDestroy.Add(a.destroy)
+	e.OnDeactivate.Add(a.Stop)
+	e.OnActivate.Add(func() {
+		if b.PlayOnStart {
+			a.Play()
+		}
+	})
+	if b.PlayOnStart && e.IsActive() {
+		a.Play()
+	}
+}
+
+// Settings returns the positional settings of the emitter, such as the
+// attenuation and cone, which can be changed at any time
+func (a *AudioEmitter) Settings() *spatial.Emitter { return &a.spatial }
+
+// Voice returns the voice of the sound that is currently playing, this will
+// be nil if the emitter hasn't been played
+func (a *AudioEmitter) Voice() *mixer.Voice { return a.voice }
+
+// IsPlaying returns true if the emitter's sound is currently playing
+func (a *AudioEmitter) IsPlaying() bool {
+	return a.voice != nil && a.voice.IsPlaying()
+}
+
+// Volume returns the volume of the emitter before it is attenuated
+func (a *AudioEmitter) Volume() float32 { return a.options.Volume }
+
+// SetVolume sets the volume of the emitter before it is attenuated
+func (a *AudioEmitter) SetVolume(volume float32) { a.options.Volume = max(volume, 0) }
+
+// Pitch returns the pitch of the emitter before the Doppler shift
+func (a *AudioEmitter) Pitch() float32 { return a.options.Pitch }
+
+// SetPitch sets the pitch of the emitter before the Doppler shift
+func (a *AudioEmitter) SetPitch(pitch float32) { a.options.Pitch = pitch }
+
+// Play starts the emitter's sound from the beginning, stopping it first if
+// it is already playing
+func (a *AudioEmitter) Play() {
+	a.Stop()
+	mix := a.host.Audio().Mixer()
+	if mix == nil {
+		slog.Error("audio has not been initialized")
+		return
+	}
+	options := a.options
+	a.spatialize(&options)
+	if a.stream {
+		v, err := a.host.Audio().PlayStream(a.host.AssetDatabase(), a.key, options)
+		if err != nil {
+			slog.Error("failed to stream the audio emitter sound", "sound", a.key, "error", err)
+			return
+		}
+		a.voice = v
+		return
+	}
+	if a.sound == nil {
+		sound, err := audio.LoadSound(a.host.AssetDatabase(), a.key)
+		if err != nil {
+			slog.Error("failed to load the audio emitter sound", "sound", a.key, "error", err)
+			return
+		}
+		a.sound = sound
+	}
+	a.voice = mix.Play(a.sound, options)
+}
+
+// Stop stops the emitter's sound if it is playing
+func (a *AudioEmitter) Stop() {
+	if a.voice != nil {
+		a.voice.Stop()
+		a.voice = nil
+	}
+}
+
+func (a *AudioEmitter) place(deltaTime float64) {
+	t := &a.entity.Transform
+	a.spatial.Update(t.WorldPosition(), t.WorldMatrix().Forward(), deltaTime)
+}
+
+// spatialize applies the position of the emitter relative to the listener
+// to the options of the voice
+func (a *AudioEmitter) spatialize(options *mixer.PlayOptions) {
+	r := spatial.Spatialize(a.host.Audio().Listener(), &a.spatial)
+	options.Volume = a.options.Volume * r.Gain
+	options.Pan = r.Pan
+	options.Pitch = a.options.Pitch * r.Pitch
+}
+
+func (a *AudioEmitter) update(deltaTime float64) {
+	if !a.entity.IsActive() {
+		return
+	}
+	a.place(deltaTime)
+	if a.voice == nil || a.voice.IsStopped() {
+		return
+	}
+	options := a.options
+	a.spatialize(&options)
+	a.voice.SetVolume(options.Volume)
+	a.voice.SetPan(options.Pan)
+	a.voice.SetPitch(options.Pitch)
+}
+
+func (a *AudioEmitter) destroy() {
+	a.Stop()
+	a.host.LateUpdater.RemoveUpdate(a.updateId)
+}
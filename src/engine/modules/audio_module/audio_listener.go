@@ -0,0 +1,29 @@
+package audio_module
+
+import "kaiju/engine"
+
+const (
+	AudioListenerEntityDataName = "AudioListener"
+)
+
+// AudioListenerModuleBinding makes the entity the listener that positional
+// sounds are heard from instead of the camera. If the entity is deactivated
+// or destroyed, the listener goes back to following the camera.
+type AudioListenerModuleBinding struct{}
+
+func (b *AudioListenerModuleBinding) Init(e *engine.Entity, host *engine.Host) {
+	a := host.Audio()
+	follow := func() { a.SetListenerTransform(&e.Transform) }
+	release := func() {
+		if a.ListenerTransform() == &e.Transform {
+			a.SetListenerTransform(nil)
+		}
+	}
+	e.AddNamedData(AudioListenerEntityDataName, b)
+	e.OnDestroy.Add(release)
+	e.OnDeactivate.Add(release)
+	e.OnActivate.Add(follow)
+	if e.IsActive() {
+		follow()
+	}
+}
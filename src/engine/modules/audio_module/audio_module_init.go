@@ -0,0 +1,10 @@
+//go:build !editor
+
+package audio_module
+
+import "kaiju/engine"
+
+func init() {
+	engine.RegisterEntityData(&AudioEmitterModuleBinding{})
+	engine.RegisterEntityData(&AudioListenerModuleBinding{})
+}
@@ -0,0 +1,44 @@
+package particle_module
+
+import (
+	"kaiju/engine"
+	"kaiju/engine/systems/particles"
+	"log/slog"
+)
+
+const (
+	ParticleEmitterEntityDataName = "ParticleEmitter"
+)
+
+type ParticleEmitterModuleBinding struct {
+	Definition  string
+	PlayOnStart bool `default:"true"`
+}
+
+func (b *ParticleEmitterModuleBinding) Init(e *engine.Entity, host *engine.Host) {
+	src, err := host.AssetDatabase().ReadText(b.Definition)
+	if err != nil {
+		slog.Error("failed to read the particle emitter definition",
+			"definition", b.Definition, "error", err)
+		return
+	}
+	def, err := particles.LoadEmitterDefinition(src)
+	if err != nil {
+		slog.Error("failed to load the particle emitter definition",
+			"definition", b.Definition, "error", err)
+		return
+	}
+	emitter, err := particles.NewEmitter(host, def, &e.Transform)
+	if err != nil {
+		slog.Error("failed to create the particle emitter",
+			"definition", b.Definition, "error", err)
+		return
+	}
+	e.AddNamedData(ParticleEmitterEntityDataName, emitter)
+	e.OnDestroy.Add(emitter.Destroy)
+	e.OnDeactivate.Add(emitter.Stop)
+	e.OnActivate.Add(emitter.Play)
+	if b.PlayOnStart && e.IsActive() {
+		emitter.Play()
+	}
+}
@@ -0,0 +1,309 @@
+/******************************************************************************/
+/* import.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package localization
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxPluralForms is the most plural forms a language can have
+const maxPluralForms = 6
+
+var allPluralForms = []PluralForm{PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther}
+
+// LanguageFromPath guesses the language of a string table from its file
+// name, "fr.po" and "menu.fr.json" both give "fr"
+func LanguageFromPath(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// ParseFile reads the string tables from the data of a JSON, CSV or PO file,
+// picking the format from the extension of the path. The language is used
+// for JSON and PO files that don't name their own language, if it is empty
+// it is taken from the file name.
+func ParseFile(path string, data []byte, language string) ([]*Table, error) {
+	if language == "" {
+		language = LanguageFromPath(path)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ParseJSON(data, language)
+	case ".csv":
+		return ParseCSV(strings.NewReader(string(data)))
+	case ".po":
+		return ParsePO(strings.NewReader(string(data)), language)
+	}
+	return nil, errors.New("unsupported string table file " + path)
+}
+
+// ParseJSON reads a string table from a JSON object of keys to text. Nested
+// objects are joined to their keys with a dot, so {"menu":{"play":"Play"}}
+// is the key "menu.play". An object whose keys are all plural forms is the
+// plural text of a key, such as {"one":"{0} apple","other":"{0} apples"}.
+// The language can be set in the file with an "@language" key.
+func ParseJSON(data []byte, language string) ([]*Table, error) {
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if l, ok := root["@language"].(string); ok {
+		language = l
+		delete(root, "@language")
+	}
+	t := NewTable(language)
+	if err := readJSONObject(t, "", root); err != nil {
+		return nil, err
+	}
+	return []*Table{t}, nil
+}
+
+func isPluralObject(obj map[string]any) bool {
+	for k, v := range obj {
+		if _, ok := v.(string); !ok || !slices.Contains(allPluralForms, k) {
+			return false
+		}
+	}
+	return len(obj) > 0
+}
+
+func readJSONObject(t *Table, prefix string, obj map[string]any) error {
+	for k, v := range obj {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch value := v.(type) {
+		case string:
+			t.Set(key, value)
+		case map[string]any:
+			if isPluralObject(value) {
+				for form, text := range value {
+					t.SetPlural(key, form, text.(string))
+				}
+			} else if err := readJSONObject(t, key, value); err != nil {
+				return err
+			}
+		default:
+			return errors.New("the string table key " + key + " must be text or an object")
+		}
+	}
+	return nil
+}
+
+// ParseCSV reads string tables from a spreadsheet with a column for each
+// language. The first row is the header, its first cell is ignored and the
+// rest are the languages. The first cell of every other row is the key, a
+// plural form is given by ending the key with # and the form, such as
+// "apples#one". Empty cells are missing translations and are skipped.
+func ParseCSV(r io.Reader) ([]*Table, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(header) < 2 {
+		return nil, errors.New("the string table header needs a key column and at least one language")
+	}
+	tables := make([]*Table, len(header)-1)
+	for i := range tables {
+		tables[i] = NewTable(strings.TrimSpace(header[i+1]))
+	}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if len(row) == 0 || strings.TrimSpace(row[0]) == "" {
+			continue
+		}
+		key, form, plural := strings.Cut(strings.TrimSpace(row[0]), "#")
+		if plural && !slices.Contains(allPluralForms, form) {
+			return nil, errors.New("unknown plural form " + form + " for " + key)
+		}
+		for i, text := range row[1:min(len(row), len(header))] {
+			if text == "" {
+				continue
+			}
+			if plural {
+				tables[i].SetPlural(key, form, text)
+			} else {
+				tables[i].Set(key, text)
+			}
+		}
+	}
+	return tables, nil
+}
+
+type poEntry struct {
+	context    string
+	id         string
+	idPlural   string
+	strs       [maxPluralForms]string
+	hasStr     bool
+	fuzzy      bool
+	hasContext bool
+}
+
+// ParsePO reads a gettext PO file. The key of each entry is its msgctxt if
+// it has one, otherwise its msgid. The language is taken from the header of
+// the file when it has one. Plural entries are matched to the plural forms
+// of the language in order, fuzzy and untranslated entries are skipped the
+// same as gettext does.
+func ParsePO(r io.Reader, language string) ([]*Table, error) {
+	var entries []*poEntry
+	var current *poEntry
+	var target *string
+	scanner := bufio.NewScanner(r)
+	fuzzy := false
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "":
+			current, target = nil, nil
+			continue
+		case strings.HasPrefix(text, "#,"):
+			fuzzy = fuzzy || strings.Contains(text, "fuzzy")
+			continue
+		case strings.HasPrefix(text, "#"):
+			continue
+		case strings.HasPrefix(text, "\""):
+			if target == nil {
+				return nil, errors.New("unexpected string on line " + strconv.Itoa(line))
+			}
+			s, err := strconv.Unquote(text)
+			if err != nil {
+				return nil, errors.New("invalid string on line " + strconv.Itoa(line))
+			}
+			*target += s
+			continue
+		}
+		keyword, value, _ := strings.Cut(text, " ")
+		s, err := strconv.Unquote(strings.TrimSpace(value))
+		if err != nil {
+			return nil, errors.New("invalid string on line " + strconv.Itoa(line))
+		}
+		if current == nil || (keyword == "msgctxt" || keyword == "msgid") && current.hasStr {
+			current = &poEntry{fuzzy: fuzzy}
+			entries = append(entries, current)
+			fuzzy = false
+		}
+		switch {
+		case keyword == "msgctxt":
+			current.context, current.hasContext = s, true
+			target = &current.context
+		case keyword == "msgid":
+			current.id = s
+			target = &current.id
+		case keyword == "msgid_plural":
+			current.idPlural = s
+			target = &current.idPlural
+		case keyword == "msgstr" || strings.HasPrefix(keyword, "msgstr["):
+			idx := 0
+			if keyword != "msgstr" {
+				idx, err = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(keyword, "msgstr["), "]"))
+				if err != nil || idx < 0 || idx >= len(current.strs) {
+					return nil, errors.New("invalid plural index on line " + strconv.Itoa(line))
+				}
+			}
+			current.strs[idx], current.hasStr = s, true
+			target = &current.strs[idx]
+		default:
+			return nil, errors.New("unknown keyword " + keyword + " on line " + strconv.Itoa(line))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.id == "" && !e.hasContext {
+			if l := poHeaderLanguage(e.strs[0]); l != "" {
+				language = l
+			}
+		}
+	}
+	t := NewTable(language)
+	forms := PluralForms(language)
+	for _, e := range entries {
+		if e.fuzzy || e.id == "" && !e.hasContext {
+			continue
+		}
+		key := e.id
+		if e.hasContext {
+			key = e.context
+		}
+		if e.idPlural == "" {
+			if s := e.strs[0]; s != "" {
+				t.Set(key, s)
+			}
+			continue
+		}
+		for idx, s := range e.strs {
+			if s != "" && idx < len(forms) {
+				t.SetPlural(key, forms[idx], s)
+			}
+		}
+	}
+	return []*Table{t}, nil
+}
+
+func poHeaderLanguage(header string) string {
+	for _, line := range strings.Split(header, "\n") {
+		if k, v, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(k) == "Language" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,272 @@
+/******************************************************************************/
+/* localization_test.go                                                       */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package localization
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPluralRules(t *testing.T) {
+	cases := []struct {
+		language string
+		count    int
+		expected PluralForm
+	}{
+		{"en", 1, PluralOne}, {"en", 0, PluralOther}, {"en-GB", 2, PluralOther},
+		{"fr", 0, PluralOne}, {"fr", 1, PluralOne}, {"fr", 2, PluralOther},
+		{"ja", 1, PluralOther},
+		{"ru", 1, PluralOne}, {"ru", 21, PluralOne}, {"ru", 11, PluralMany},
+		{"ru", 3, PluralFew}, {"ru", 13, PluralMany}, {"ru", 25, PluralMany},
+		{"pl", 21, PluralMany}, {"pl", 22, PluralFew},
+		{"cs", 3, PluralFew}, {"cs", 5, PluralOther},
+		{"ar", 0, PluralZero}, {"ar", 2, PluralTwo}, {"ar", 105, PluralFew},
+		{"ar", 111, PluralMany}, {"ar", 100, PluralOther},
+	}
+	for _, c := range cases {
+		if got := Plural(c.language, c.count); got != c.expected {
+			t.Errorf("%s %d expected %s, got %s", c.language, c.count, c.expected, got)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if got := Format("{1} has {0} coins {{0}}", 3, "Ana"); got != "Ana has 3 coins {0}" {
+		t.Errorf("unexpected format %q", got)
+	}
+	if got := Format("{0} and {2}", "a"); got != "a and {2}" {
+		t.Errorf("expected the missing argument to be kept, got %q", got)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	tables, err := ParseFile("ui/menu.de.json", []byte(`{
+		"menu": {"play": "Spielen", "quit": "Beenden"},
+		"apples": {"one": "{0} Apfel", "other": "{0} Äpfel"}
+	}`), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl := tables[0]
+	if tbl.Language != "de" || tbl.Len() != 3 {
+		t.Fatalf("expected 3 German keys, got %s %v", tbl.Language, tbl.Keys())
+	}
+	if e, _ := tbl.Entry("menu.play"); e.Text != "Spielen" {
+		t.Errorf("unexpected nested key %+v", e)
+	}
+	if e, _ := tbl.Entry("apples"); e.Form(PluralOther) != "{0} Äpfel" {
+		t.Errorf("unexpected plural %+v", e)
+	}
+	tables, _ = ParseJSON([]byte(`{"@language": "es", "a": "b"}`), "en")
+	if tables[0].Language != "es" || tables[0].Has("@language") {
+		t.Error("expected the language to come from the file")
+	}
+	if _, err := ParseJSON([]byte(`{"a": 1}`), "en"); err == nil {
+		t.Error("expected an error for a number")
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	tables, err := ParseCSV(strings.NewReader("key,en,fr\n" +
+		"menu.play,Play,Jouer\n" +
+		"apples#one,{0} apple,{0} pomme\n" +
+		"apples#other,{0} apples,{0} pommes\n" +
+		"menu.quit,Quit,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 || tables[1].Language != "fr" {
+		t.Fatalf("expected an English and French table, got %d", len(tables))
+	}
+	if tables[1].Has("menu.quit") {
+		t.Error("expected the empty cell to be skipped")
+	}
+	if e, _ := tables[1].Entry("apples"); e.Form(PluralOne) != "{0} pomme" {
+		t.Errorf("unexpected plural %+v", e)
+	}
+	if _, err := ParseCSV(strings.NewReader("key,en\nx#lots,y\n")); err == nil {
+		t.Error("expected an error for an unknown plural form")
+	}
+}
+
+const testPO = `# Russian translation
+msgid ""
+msgstr ""
+"Language: ru\n"
+"Plural-Forms: nplurals=3;\n"
+
+#: menu.html:4
+msgid "Play"
+msgstr "Играть"
+
+msgctxt "menu.quit"
+msgid "Quit"
+msgstr ""
+"Вый"
+"ти"
+
+msgid "{0} apple"
+msgid_plural "{0} apples"
+msgstr[0] "{0} яблоко"
+msgstr[1] "{0} яблока"
+msgstr[2] "{0} яблок"
+
+#, fuzzy
+msgid "Options"
+msgstr "Опции"
+
+msgid "Credits"
+msgstr ""
+`
+
+func TestParsePO(t *testing.T) {
+	tables, err := ParseFile("strings.po", []byte(testPO), "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl := tables[0]
+	if tbl.Language != "ru" {
+		t.Errorf("expected the language from the header, got %s", tbl.Language)
+	}
+	if !slices.Equal(tbl.Keys(), []string{"Play", "menu.quit", "{0} apple"}) {
+		t.Errorf("unexpected keys %v", tbl.Keys())
+	}
+	if e, _ := tbl.Entry("menu.quit"); e.Text != "Выйти" {
+		t.Errorf("expected the continued string, got %q", e.Text)
+	}
+	if e, _ := tbl.Entry("{0} apple"); e.Form(PluralMany) != "{0} яблок" {
+		t.Errorf("unexpected plural %+v", e)
+	}
+	if _, err := ParsePO(strings.NewReader("msgid \"a\"\nbogus \"b\"\n"), "en"); err == nil {
+		t.Error("expected an error for an unknown keyword")
+	}
+}
+
+func testLocalizer(t *testing.T) *Localizer {
+	t.Helper()
+	l := New("en")
+	tables, err := ParseCSV(strings.NewReader("key,en,fr,fr-CA\n" +
+		"menu.play,Play,Jouer,\n" +
+		"menu.quit,Quit,,\n" +
+		"menu.car,Car,Voiture,Char\n" +
+		"apples#one,{0} apple for {1},{0} pomme pour {1},\n" +
+		"apples#other,{0} apples for {1},{0} pommes pour {1},\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tbl := range tables {
+		l.AddTable(tbl)
+	}
+	return l
+}
+
+func TestLocalizerLookup(t *testing.T) {
+	l := testLocalizer(t)
+	changed := 0
+	l.OnLanguageChanged.Add(func() { changed++ })
+	if got := l.Text("menu.play"); got != "Play" {
+		t.Errorf("expected English, got %s", got)
+	}
+	if err := l.SetLanguage("fr-CA"); err != nil {
+		t.Fatal(err)
+	}
+	if changed != 1 {
+		t.Errorf("expected the language change event, got %d", changed)
+	}
+	// The region, then the base language, then the fallback
+	if got := l.Text("menu.car"); got != "Char" {
+		t.Errorf("expected the region text, got %s", got)
+	}
+	if got := l.Text("menu.play"); got != "Jouer" {
+		t.Errorf("expected the base language text, got %s", got)
+	}
+	if got := l.Text("menu.quit"); got != "Quit" {
+		t.Errorf("expected the fallback text, got %s", got)
+	}
+	if got := l.Text("menu.unknown"); got != "menu.unknown" {
+		t.Errorf("expected the key, got %s", got)
+	}
+	if got := l.Plural("apples", 0, "Ana"); got != "0 pomme pour Ana" {
+		t.Errorf("unexpected French plural %s", got)
+	}
+	l.SetLanguage("en")
+	if got := l.Plural("apples", 2, "Ana"); got != "2 apples for Ana" {
+		t.Errorf("unexpected English plural %s", got)
+	}
+	if err := l.SetLanguage("de"); err == nil || l.Language() != "en" {
+		t.Error("expected a language without a table to fail")
+	}
+	var nilLocalizer *Localizer
+	if got := nilLocalizer.Text("a.{0}", 1); got != "a.1" {
+		t.Errorf("expected a nil localizer to format the key, got %s", got)
+	}
+}
+
+func TestMissingReport(t *testing.T) {
+	l := testLocalizer(t)
+	l.SetLanguage("fr")
+	l.Text("menu.quit")
+	l.Text("menu.quit")
+	l.Text("menu.unknown")
+	missing := l.MissingKeys()
+	if !slices.Equal(missing["fr"], []string{"menu.quit", "menu.unknown"}) {
+		t.Errorf("unexpected missing keys %v", missing)
+	}
+	if got := l.UntranslatedKeys("fr-CA"); !slices.Equal(got, []string{"apples", "menu.play", "menu.quit"}) {
+		t.Errorf("unexpected untranslated keys %v", got)
+	}
+	buf := bytes.Buffer{}
+	if err := l.WriteMissingReport(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := "language,key,source,requests\n" +
+		"fr,menu.quit,Quit,2\n" +
+		"fr,menu.unknown,,1\n" +
+		"fr-CA,apples,one: {0} apple for {1} | other: {0} apples for {1},0\n" +
+		"fr-CA,menu.play,Play,0\n" +
+		"fr-CA,menu.quit,Quit,0\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected report\n%s", buf.String())
+	}
+	l.ClearMissingKeys()
+	if len(l.MissingKeys()) != 0 {
+		t.Error("expected the missing keys to be cleared")
+	}
+}
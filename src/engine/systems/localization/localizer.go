@@ -0,0 +1,348 @@
+/******************************************************************************/
+/* localizer.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+// Package localization holds the translated text of a game. Text is stored
+// in a string table for each language, which can be read from JSON, CSV or
+// gettext PO files. Keys are looked up in the current language, then the
+// language without its region, then the fallback language. Keys that are
+// missing are recorded so that a report can be given to translators.
+package localization
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"kaiju/engine/assets"
+	"kaiju/engine/systems/events"
+	"kaiju/klib"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Localizer looks up the text of keys in the current language. It is safe
+// to look up text from any goroutine, changing the language raises
+// #Localizer.OnLanguageChanged on the goroutine that changed it.
+type Localizer struct {
+	OnLanguageChanged events.Event
+	mutex             sync.RWMutex
+	tables            map[string]*Table
+	language          string
+	fallback          string
+	missing           map[string]map[string]int
+}
+
+// New creates a localizer with the fallback language, which is also the
+// starting language. The fallback is usually the language the game was
+// written in.
+func New(fallback string) *Localizer {
+	l := &Localizer{}
+	l.init(fallback)
+	return l
+}
+
+func (l *Localizer) init(fallback string) {
+	l.tables = make(map[string]*Table)
+	l.missing = make(map[string]map[string]int)
+	l.language = fallback
+	l.fallback = fallback
+}
+
+// Language returns the current language
+func (l *Localizer) Language() string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return l.language
+}
+
+// Fallback returns the language used for keys that are missing from the
+// current language
+func (l *Localizer) Fallback() string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return l.fallback
+}
+
+// SetFallback changes the language used for keys that are missing from the
+// current language
+func (l *Localizer) SetFallback(language string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.fallback = language
+}
+
+// Languages returns the languages that have a string table, sorted
+func (l *Localizer) Languages() []string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return klib.MapKeysSorted(l.tables)
+}
+
+// Table returns the string table for the language
+func (l *Localizer) Table(language string) (*Table, bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	t, ok := l.tables[language]
+	return t, ok
+}
+
+// AddTable adds the string table, if there already is a table for its
+// language the entries are merged into it so a language can be split across
+// many files
+func (l *Localizer) AddTable(table *Table) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if t, ok := l.tables[table.Language]; ok {
+		t.Merge(table)
+	} else {
+		l.tables[table.Language] = table
+	}
+}
+
+// Load reads the string tables from a JSON, CSV or PO file in the asset
+// database, see #ParseFile for how the language is picked
+func (l *Localizer) Load(adb *assets.Database, key, language string) error {
+	data, err := adb.Read(key)
+	if err != nil {
+		return err
+	}
+	tables, err := ParseFile(key, data, language)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		l.AddTable(t)
+	}
+	return nil
+}
+
+// SetLanguage changes the current language and raises
+// #Localizer.OnLanguageChanged so open documents can apply the new text. An
+// error is returned if there is no string table for the language.
+func (l *Localizer) SetLanguage(language string) error {
+	l.mutex.Lock()
+	if l.language == language {
+		l.mutex.Unlock()
+		return nil
+	}
+	if _, ok := l.tables[language]; !ok && language != l.fallback {
+		l.mutex.Unlock()
+		return errors.New("there is no string table for the language " + language)
+	}
+	l.language = language
+	l.mutex.Unlock()
+	l.OnLanguageChanged.Execute()
+	return nil
+}
+
+// lookup finds the entry for the key, recording the key as missing if it
+// isn't in the current language
+func (l *Localizer) lookup(key string) (Entry, string, bool) {
+	l.mutex.RLock()
+	language, fallback := l.language, l.fallback
+	candidates := [...]string{language, BaseLanguage(language), fallback}
+	var entry Entry
+	found := ""
+	for i, c := range candidates {
+		if t, ok := l.tables[c]; ok {
+			if e, ok := t.entries[key]; ok {
+				entry, found = e, c
+				if i < 2 {
+					l.mutex.RUnlock()
+					return entry, found, true
+				}
+				break
+			}
+		}
+	}
+	l.mutex.RUnlock()
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	m, ok := l.missing[language]
+	if !ok {
+		m = make(map[string]int)
+		l.missing[language] = m
+	}
+	m[key]++
+	return entry, found, found != ""
+}
+
+// Text returns the text of the key in the current language with the
+// arguments formatted into it (see #Format). If the key is missing from
+// every language the key itself is returned so the gap is visible. A nil
+// localizer formats the key.
+func (l *Localizer) Text(key string, args ...any) string {
+	if l == nil {
+		return Format(key, args...)
+	}
+	e, _, ok := l.lookup(key)
+	if !ok {
+		return Format(key, args...)
+	}
+	return Format(e.Text, args...)
+}
+
+// Plural returns the text of the key for the count in the current language.
+// The count is the first argument, so it is {0} in the text and the other
+// arguments start from {1}.
+func (l *Localizer) Plural(key string, count int, args ...any) string {
+	args = append([]any{count}, args...)
+	if l == nil {
+		return Format(key, args...)
+	}
+	e, language, ok := l.lookup(key)
+	if !ok {
+		return Format(key, args...)
+	}
+	return Format(e.Form(Plural(language, count)), args...)
+}
+
+// Has returns true if the key is in the current or fallback language
+func (l *Localizer) Has(key string) bool {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	for _, c := range [...]string{l.language, BaseLanguage(l.language), l.fallback} {
+		if t, ok := l.tables[c]; ok && t.Has(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingKeys returns the keys that were looked up but weren't in the
+// language, sorted, for each language that had missing keys
+func (l *Localizer) MissingKeys() map[string][]string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	out := make(map[string][]string, len(l.missing))
+	for language, keys := range l.missing {
+		out[language] = klib.MapKeysSorted(keys)
+	}
+	return out
+}
+
+// ClearMissingKeys forgets the keys that were recorded as missing
+func (l *Localizer) ClearMissingKeys() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	clear(l.missing)
+}
+
+// UntranslatedKeys returns the keys of the fallback language that the
+// language doesn't have, sorted. Unlike #Localizer.MissingKeys this doesn't
+// depend on what text the game has shown.
+func (l *Localizer) UntranslatedKeys(language string) []string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	source, ok := l.tables[l.fallback]
+	if !ok {
+		return []string{}
+	}
+	target := l.tables[language]
+	out := []string{}
+	for _, k := range source.Keys() {
+		if target == nil || !target.Has(k) {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// WriteMissingReport writes a CSV for translators with a row for every key
+// that is missing from a language. Each row has the language, the key, the
+// text of the fallback language and how many times the game asked for it,
+// which is 0 for keys that are untranslated but haven't been shown yet.
+func (l *Localizer) WriteMissingReport(w io.Writer) error {
+	missing := l.MissingKeys()
+	l.mutex.RLock()
+	source := l.tables[l.fallback]
+	languages := klib.MapKeysSorted(l.tables)
+	l.mutex.RUnlock()
+	rows := map[string]map[string]int{}
+	for _, language := range languages {
+		if language == l.Fallback() {
+			continue
+		}
+		rows[language] = map[string]int{}
+		for _, k := range l.UntranslatedKeys(language) {
+			rows[language][k] = 0
+		}
+	}
+	l.mutex.RLock()
+	for language, keys := range missing {
+		if rows[language] == nil {
+			rows[language] = map[string]int{}
+		}
+		for _, k := range keys {
+			rows[language][k] = l.missing[language][k]
+		}
+	}
+	l.mutex.RUnlock()
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"language", "key", "source", "requests"}); err != nil {
+		return err
+	}
+	for _, language := range klib.MapKeysSorted(rows) {
+		for _, k := range klib.MapKeysSorted(rows[language]) {
+			text := ""
+			if source != nil {
+				if e, ok := source.Entry(k); ok {
+					text = e.Text
+					if text == "" {
+						text = strings.Join(pluralTexts(e), " | ")
+					}
+				}
+			}
+			err := out.Write([]string{language, k, text, strconv.Itoa(rows[language][k])})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func pluralTexts(e Entry) []string {
+	texts := []string{}
+	for _, form := range allPluralForms {
+		if t, ok := e.Plurals[form]; ok {
+			texts = append(texts, form+": "+t)
+		}
+	}
+	return texts
+}
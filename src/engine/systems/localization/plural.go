@@ -0,0 +1,181 @@
+/******************************************************************************/
+/* plural.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package localization
+
+import "strings"
+
+// PluralForm is one of the CLDR plural categories, a language only uses the
+// ones its grammar needs and every language uses #PluralOther
+type PluralForm = string
+
+const (
+	PluralZero  PluralForm = "zero"
+	PluralOne   PluralForm = "one"
+	PluralTwo   PluralForm = "two"
+	PluralFew   PluralForm = "few"
+	PluralMany  PluralForm = "many"
+	PluralOther PluralForm = "other"
+)
+
+// pluralRule picks the plural form for a count, along with the forms the
+// rule can return in the order gettext numbers them for msgstr[n]
+type pluralRule struct {
+	forms  []PluralForm
+	choose func(n int) PluralForm
+}
+
+var (
+	ruleOther = pluralRule{
+		forms:  []PluralForm{PluralOther},
+		choose: func(n int) PluralForm { return PluralOther },
+	}
+	ruleOneOther = pluralRule{
+		forms: []PluralForm{PluralOne, PluralOther},
+		choose: func(n int) PluralForm {
+			if n == 1 {
+				return PluralOne
+			}
+			return PluralOther
+		},
+	}
+	// ruleZeroOneOther is used by languages (such as French) where 0 is
+	// singular
+	ruleZeroOneOther = pluralRule{
+		forms: []PluralForm{PluralOne, PluralOther},
+		choose: func(n int) PluralForm {
+			if n == 0 || n == 1 {
+				return PluralOne
+			}
+			return PluralOther
+		},
+	}
+	ruleSlavic = pluralRule{
+		forms: []PluralForm{PluralOne, PluralFew, PluralMany},
+		choose: func(n int) PluralForm {
+			switch {
+			case n%10 == 1 && n%100 != 11:
+				return PluralOne
+			case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+				return PluralFew
+			}
+			return PluralMany
+		},
+	}
+	rulePolish = pluralRule{
+		forms: []PluralForm{PluralOne, PluralFew, PluralMany},
+		choose: func(n int) PluralForm {
+			switch {
+			case n == 1:
+				return PluralOne
+			case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+				return PluralFew
+			}
+			return PluralMany
+		},
+	}
+	ruleCzech = pluralRule{
+		forms: []PluralForm{PluralOne, PluralFew, PluralOther},
+		choose: func(n int) PluralForm {
+			switch {
+			case n == 1:
+				return PluralOne
+			case n >= 2 && n <= 4:
+				return PluralFew
+			}
+			return PluralOther
+		},
+	}
+	ruleArabic = pluralRule{
+		forms: []PluralForm{PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther},
+		choose: func(n int) PluralForm {
+			switch {
+			case n == 0:
+				return PluralZero
+			case n == 1:
+				return PluralOne
+			case n == 2:
+				return PluralTwo
+			case n%100 >= 3 && n%100 <= 10:
+				return PluralFew
+			case n%100 >= 11:
+				return PluralMany
+			}
+			return PluralOther
+		},
+	}
+)
+
+var pluralRules = map[string]*pluralRule{
+	"ja": &ruleOther, "zh": &ruleOther, "ko": &ruleOther, "th": &ruleOther,
+	"vi": &ruleOther, "id": &ruleOther, "ms": &ruleOther, "tr": &ruleOneOther,
+	"fr": &ruleZeroOneOther, "pt-br": &ruleZeroOneOther, "hi": &ruleZeroOneOther,
+	"ru": &ruleSlavic, "uk": &ruleSlavic, "be": &ruleSlavic, "sr": &ruleSlavic,
+	"hr": &ruleSlavic, "bs": &ruleSlavic, "pl": &rulePolish,
+	"cs": &ruleCzech, "sk": &ruleCzech, "ar": &ruleArabic,
+}
+
+// BaseLanguage returns the language without its region, "pt-BR" gives "pt"
+func BaseLanguage(language string) string {
+	base, _, _ := strings.Cut(strings.ReplaceAll(language, "_", "-"), "-")
+	return strings.ToLower(base)
+}
+
+func rulesFor(language string) *pluralRule {
+	if r, ok := pluralRules[strings.ToLower(strings.ReplaceAll(language, "_", "-"))]; ok {
+		return r
+	}
+	if r, ok := pluralRules[BaseLanguage(language)]; ok {
+		return r
+	}
+	// English, German, Spanish, Italian and most other European languages
+	return &ruleOneOther
+}
+
+// Plural returns the plural form the language uses for the count
+func Plural(language string, count int) PluralForm {
+	if count < 0 {
+		count = -count
+	}
+	return rulesFor(language).choose(count)
+}
+
+// PluralForms returns the plural forms the language uses, in the order they
+// are numbered in PO files
+func PluralForms(language string) []PluralForm {
+	return rulesFor(language).forms
+}
@@ -0,0 +1,117 @@
+/******************************************************************************/
+/* table.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package localization
+
+import (
+	"kaiju/klib"
+)
+
+// Entry is the text for a key in one language. Keys that change with a
+// count have a text for each of the language's plural forms, with Text
+// being used for any form that is missing.
+type Entry struct {
+	Text    string
+	Plurals map[PluralForm]string
+}
+
+// Form returns the text of the entry for the plural form
+func (e Entry) Form(form PluralForm) string {
+	if t, ok := e.Plurals[form]; ok {
+		return t
+	}
+	if t, ok := e.Plurals[PluralOther]; ok && e.Text == "" {
+		return t
+	}
+	return e.Text
+}
+
+// Table holds all of the text for one language
+type Table struct {
+	Language string
+	entries  map[string]Entry
+}
+
+func NewTable(language string) *Table {
+	return &Table{
+		Language: language,
+		entries:  make(map[string]Entry),
+	}
+}
+
+// Set sets the text of the key
+func (t *Table) Set(key, text string) {
+	e := t.entries[key]
+	e.Text = text
+	t.entries[key] = e
+}
+
+// SetPlural sets the text of the key for one plural form
+func (t *Table) SetPlural(key string, form PluralForm, text string) {
+	e := t.entries[key]
+	if e.Plurals == nil {
+		e.Plurals = make(map[PluralForm]string)
+	}
+	e.Plurals[form] = text
+	t.entries[key] = e
+}
+
+// Entry returns the entry for the key
+func (t *Table) Entry(key string) (Entry, bool) {
+	e, ok := t.entries[key]
+	return e, ok
+}
+
+// Has returns true if the table has the key
+func (t *Table) Has(key string) bool {
+	_, ok := t.entries[key]
+	return ok
+}
+
+// Keys returns every key in the table, sorted
+func (t *Table) Keys() []string { return klib.MapKeysSorted(t.entries) }
+
+// Len returns the number of keys in the table
+func (t *Table) Len() int { return len(t.entries) }
+
+// Merge copies every entry of the other table into this one, replacing the
+// entries that have the same key
+func (t *Table) Merge(other *Table) {
+	for k, e := range other.entries {
+		t.entries[k] = e
+	}
+}
@@ -0,0 +1,173 @@
+/******************************************************************************/
+/* emitter.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"kaiju/engine"
+	"kaiju/engine/systems/visual2d/sprite"
+	"kaiju/matrix"
+	"kaiju/rendering"
+	"sync"
+)
+
+// Emitter is the bridge between a particle #Simulation and the renderer. It
+// owns one draw instance per possible particle and, each frame, writes the
+// live particles into those instances as camera facing quads. Instances for
+// dead particles are deactivated so they are skipped by the renderer.
+type Emitter struct {
+	host       *engine.Host
+	transform  *matrix.Transform
+	sim        *Simulation
+	shaderData []sprite.ShaderData
+	drawn      int
+	updateId   int
+}
+
+// NewEmitter creates an emitter for the definition that follows the given
+// transform. The emitter will immediately begin updating on the host, though
+// it will not emit anything until #Emitter.Play is called.
+func NewEmitter(host *engine.Host, def EmitterDefinition, transform *matrix.Transform) (*Emitter, error) {
+	mat, err := host.MaterialCache().Material(def.Material)
+	if err != nil {
+		return nil, err
+	}
+	tex, err := host.TextureCache().Texture(def.Texture, rendering.TextureFilterLinear)
+	if err != nil {
+		return nil, err
+	}
+	e := &Emitter{
+		host:       host,
+		transform:  transform,
+		sim:        NewSimulation(def),
+		shaderData: make([]sprite.ShaderData, max(def.MaxParticles, 1)),
+	}
+	mesh := rendering.NewMeshQuad(host.MeshCache())
+	material := mat.CreateInstance([]*rendering.Texture{tex})
+	for i := range e.shaderData {
+		e.shaderData[i] = sprite.ShaderData{
+			ShaderDataBase: rendering.NewShaderDataBase(),
+			UVs:            matrix.Vec4{0, 0, 1, 1},
+			FgColor:        matrix.ColorWhite(),
+		}
+		e.shaderData[i].Deactivate()
+		host.Drawings.AddDrawing(rendering.Drawing{
+			Renderer:   host.Window.Renderer,
+			Material:   material,
+			Mesh:       mesh,
+			ShaderData: &e.shaderData[i],
+		})
+	}
+	e.updateId = host.Updater.AddUpdate(e.update)
+	return e, nil
+}
+
+// Simulation returns the CPU simulation that is driving this emitter
+func (e *Emitter) Simulation() *Simulation { return e.sim }
+
+// Play starts emitting particles from the beginning of the emission cycle
+func (e *Emitter) Play() { e.sim.Play() }
+
+// Stop will stop emitting new particles, live particles will finish their life
+func (e *Emitter) Stop() { e.sim.Stop() }
+
+// Burst will immediately emit the given number of particles
+func (e *Emitter) Burst(count int) { e.sim.Emit(count, e.worldMatrix()) }
+
+// Destroy removes the emitter from the host updates and releases all of its
+// draw instances
+func (e *Emitter) Destroy() {
+	if e.updateId == 0 {
+		return
+	}
+	e.host.Updater.RemoveUpdate(e.updateId)
+	e.updateId = 0
+	for i := range e.shaderData {
+		e.shaderData[i].Destroy()
+	}
+}
+
+func (e *Emitter) worldMatrix() matrix.Mat4 {
+	if e.transform == nil {
+		return matrix.Mat4Identity()
+	}
+	return e.transform.WorldMatrix()
+}
+
+func (e *Emitter) update(deltaTime float64) {
+	world := e.worldMatrix()
+	threads := e.host.Threads()
+	e.sim.Update(matrix.Float(deltaTime), world, threads)
+	particles := e.sim.Particles()
+	// The camera orientation without its translation, used to billboard
+	billboard := e.host.Camera.View().Invert()
+	billboard.SetTranslation(matrix.Vec3Zero())
+	if e.sim.Definition.WorldSpace {
+		world = matrix.Mat4Identity()
+	}
+	count := len(particles)
+	wg := sync.WaitGroup{}
+	for from := 0; from < count; from += simulationChunkSize {
+		to := min(from+simulationChunkSize, count)
+		wg.Add(1)
+		threads.AddWork(func(int) {
+			for i := from; i < to; i++ {
+				e.writeInstance(&e.shaderData[i], &particles[i], &billboard, &world)
+			}
+			wg.Done()
+		})
+	}
+	wg.Wait()
+	for i := count; i < e.drawn; i++ {
+		e.shaderData[i].Deactivate()
+	}
+	e.drawn = count
+}
+
+func (e *Emitter) writeInstance(sd *sprite.ShaderData, p *Particle, billboard, world *matrix.Mat4) {
+	m := matrix.Mat4Identity()
+	m.Scale(matrix.Vec3{p.Size, p.Size, p.Size})
+	if p.Rotation != 0 {
+		m.RotateZ(p.Rotation)
+	}
+	m.MultiplyAssign(*billboard)
+	m.Translate(world.TransformPoint(p.Position))
+	sd.SetModel(m)
+	sd.FgColor = p.Color
+	sd.UVs = p.UVs
+	sd.Activate()
+}
@@ -0,0 +1,147 @@
+/******************************************************************************/
+/* emitter_definition.go                                                      */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"encoding/json"
+	"kaiju/engine/assets"
+	"kaiju/klib"
+	"kaiju/matrix"
+	"strings"
+)
+
+// Burst will emit a number of particles all at once at the given time (in
+// seconds) since the emitter started playing. If Cycles is greater than 1,
+// then the burst will repeat every Interval seconds.
+type Burst struct {
+	Time     matrix.Float
+	Count    int
+	Cycles   int
+	Interval matrix.Float
+}
+
+// EmitterDefinition is the description of a particle emitter, it is what is
+// stored in the particle emitter JSON asset. The modules are all optional and
+// a nil module will not be processed.
+type EmitterDefinition struct {
+	Name         string
+	Texture      string
+	Material     string
+	MaxParticles int
+	// Duration is how long, in seconds, the emitter will emit particles for.
+	// If Looping is true, then the emitter restarts after the duration.
+	Duration matrix.Float
+	Looping  bool
+	// Rate is the number of particles emitted per second
+	Rate   matrix.Float
+	Bursts []Burst
+	Shape  EmitterShape
+	// WorldSpace particles are not moved along with the emitter once they
+	// have been spawned
+	WorldSpace    bool
+	Seed          int64
+	StartSize     Range
+	StartRotation Range
+	StartColor    matrix.Color
+
+	Lifetime          *LifetimeModule          `json:",omitempty"`
+	Velocity          *VelocityModule          `json:",omitempty"`
+	Gravity           *GravityModule           `json:",omitempty"`
+	Drag              *DragModule              `json:",omitempty"`
+	ColorOverLifetime *ColorOverLifetimeModule `json:",omitempty"`
+	SizeOverLifetime  *SizeOverLifetimeModule  `json:",omitempty"`
+	SpriteSheet       *SpriteSheetModule       `json:",omitempty"`
+}
+
+// NewEmitterDefinition creates an emitter definition with the default values
+// used for any field that is not specified in the JSON asset
+func NewEmitterDefinition() EmitterDefinition {
+	return EmitterDefinition{
+		Texture:      assets.TextureSquare,
+		Material:     assets.MaterialDefinitionSpriteTransparent,
+		MaxParticles: 100,
+		Duration:     5,
+		Looping:      true,
+		Rate:         10,
+		Shape:        EmitterShape{Type: EmitterShapePoint},
+		StartSize:    Range{1, 1},
+		StartColor:   matrix.ColorWhite(),
+	}
+}
+
+// LoadEmitterDefinition reads an emitter definition from the JSON string
+func LoadEmitterDefinition(jsonStr string) (EmitterDefinition, error) {
+	def := NewEmitterDefinition()
+	err := klib.JsonDecode(json.NewDecoder(strings.NewReader(jsonStr)), &def)
+	def.MaxParticles = max(def.MaxParticles, 1)
+	return def, err
+}
+
+// Json serializes the definition so that it can be written to an asset file
+func (d *EmitterDefinition) Json() (string, error) {
+	b, err := json.MarshalIndent(d, "", "\t")
+	return string(b), err
+}
+
+// Modules returns all of the modules that are set on the definition in the
+// order that they should be processed
+func (d *EmitterDefinition) Modules() []Module {
+	modules := make([]Module, 0, 7)
+	if d.Lifetime != nil {
+		modules = append(modules, d.Lifetime)
+	}
+	if d.Velocity != nil {
+		modules = append(modules, d.Velocity)
+	}
+	if d.Gravity != nil {
+		modules = append(modules, d.Gravity)
+	}
+	if d.Drag != nil {
+		modules = append(modules, d.Drag)
+	}
+	if d.ColorOverLifetime != nil {
+		modules = append(modules, d.ColorOverLifetime)
+	}
+	if d.SizeOverLifetime != nil {
+		modules = append(modules, d.SizeOverLifetime)
+	}
+	if d.SpriteSheet != nil {
+		modules = append(modules, d.SpriteSheet)
+	}
+	return modules
+}
@@ -0,0 +1,124 @@
+/******************************************************************************/
+/* emitter_shape.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"kaiju/matrix"
+	"math"
+	"math/rand"
+)
+
+type EmitterShapeType = string
+
+const (
+	EmitterShapePoint  EmitterShapeType = "point"
+	EmitterShapeSphere EmitterShapeType = "sphere"
+	EmitterShapeBox    EmitterShapeType = "box"
+	EmitterShapeCone   EmitterShapeType = "cone"
+	EmitterShapeCircle EmitterShapeType = "circle"
+)
+
+// EmitterShape describes the volume that particles are spawned within and the
+// direction that they initially travel in. All shapes are centered on the
+// emitter and the "up" direction for cones and boxes is the emitter's +Y axis.
+type EmitterShape struct {
+	Type EmitterShapeType
+	// Radius is used by the sphere, cone, and circle shapes
+	Radius matrix.Float
+	// Extent is the half size of the box shape
+	Extent matrix.Vec3
+	// Angle is the half angle, in degrees, of the cone shape
+	Angle matrix.Float
+	// Surface will only spawn particles on the surface of the shape rather
+	// than anywhere within its volume
+	Surface bool
+}
+
+// Sample selects a random local position and normalized direction for a
+// particle that is spawning from this shape
+func (s EmitterShape) Sample(rng *rand.Rand) (position, direction matrix.Vec3) {
+	switch s.Type {
+	case EmitterShapeSphere:
+		direction = randomUnitVector(rng)
+		position = direction.Scale(s.radius(rng))
+	case EmitterShapeBox:
+		direction = matrix.Vec3Up()
+		for i := range position {
+			position[i] = (matrix.Float(rng.Float64())*2 - 1) * s.Extent[i]
+		}
+		if s.Surface {
+			axis := rng.Intn(3)
+			if rng.Intn(2) == 0 {
+				position[axis] = -s.Extent[axis]
+			} else {
+				position[axis] = s.Extent[axis]
+			}
+		}
+	case EmitterShapeCone:
+		theta := matrix.Float(rng.Float64() * 2 * math.Pi)
+		spread := matrix.Deg2Rad(s.Angle) * matrix.Float(rng.Float64())
+		direction = matrix.Vec3{
+			matrix.Sin(spread) * matrix.Cos(theta),
+			matrix.Cos(spread),
+			matrix.Sin(spread) * matrix.Sin(theta),
+		}
+		r := s.radius(rng)
+		position = matrix.Vec3{r * matrix.Cos(theta), 0, r * matrix.Sin(theta)}
+	case EmitterShapeCircle:
+		theta := matrix.Float(rng.Float64() * 2 * math.Pi)
+		direction = matrix.Vec3{matrix.Cos(theta), 0, matrix.Sin(theta)}
+		position = direction.Scale(s.radius(rng))
+	default:
+		direction = randomUnitVector(rng)
+	}
+	return position, direction
+}
+
+func (s EmitterShape) radius(rng *rand.Rand) matrix.Float {
+	if s.Surface {
+		return s.Radius
+	}
+	return s.Radius * matrix.Float(math.Cbrt(rng.Float64()))
+}
+
+func randomUnitVector(rng *rand.Rand) matrix.Vec3 {
+	z := matrix.Float(rng.Float64()*2 - 1)
+	theta := matrix.Float(rng.Float64() * 2 * math.Pi)
+	r := matrix.Sqrt(1 - z*z)
+	return matrix.Vec3{r * matrix.Cos(theta), r * matrix.Sin(theta), z}
+}
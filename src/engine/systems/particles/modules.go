@@ -0,0 +1,212 @@
+/******************************************************************************/
+/* modules.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"kaiju/matrix"
+	"math/rand"
+)
+
+// SpawnContext holds the information about a particle that is being emitted
+// which is needed by the modules to initialize it
+type SpawnContext struct {
+	// Direction is the normalized direction that the emitter shape selected
+	// for the particle to travel in
+	Direction matrix.Vec3
+	Rand      *rand.Rand
+}
+
+// Module is a single piece of particle behavior. Spawn is called for each new
+// particle on the thread that is updating the emitter. Update is called with
+// a slice of the live particles and can be called from many threads at the
+// same time with different slices, so it should not share any mutable state.
+type Module interface {
+	Spawn(p *Particle, ctx *SpawnContext)
+	Update(particles []Particle, deltaTime matrix.Float)
+}
+
+// LifetimeModule selects how long a particle will live for, in seconds
+type LifetimeModule struct {
+	Lifetime Range
+}
+
+func (m *LifetimeModule) Spawn(p *Particle, ctx *SpawnContext) {
+	p.Lifetime = m.Lifetime.Random(ctx.Rand)
+}
+
+func (m *LifetimeModule) Update([]Particle, matrix.Float) {}
+
+// VelocityModule gives the particle an initial speed along the direction
+// selected by the emitter shape. Constant is added to the initial velocity
+// regardless of the emit direction.
+type VelocityModule struct {
+	Speed    Range
+	Constant matrix.Vec3
+}
+
+func (m *VelocityModule) Spawn(p *Particle, ctx *SpawnContext) {
+	p.Velocity = ctx.Direction.Scale(m.Speed.Random(ctx.Rand)).Add(m.Constant)
+}
+
+func (m *VelocityModule) Update([]Particle, matrix.Float) {}
+
+// GravityModule applies a constant acceleration to the particles
+type GravityModule struct {
+	Gravity matrix.Vec3
+}
+
+func (m *GravityModule) Spawn(*Particle, *SpawnContext) {}
+
+func (m *GravityModule) Update(particles []Particle, deltaTime matrix.Float) {
+	step := m.Gravity.Scale(deltaTime)
+	for i := range particles {
+		particles[i].Velocity.AddAssign(step)
+	}
+}
+
+// DragModule slows the particles down over time, a drag of 1 will remove the
+// entire velocity of the particle over 1 second
+type DragModule struct {
+	Drag matrix.Float
+}
+
+func (m *DragModule) Spawn(*Particle, *SpawnContext) {}
+
+func (m *DragModule) Update(particles []Particle, deltaTime matrix.Float) {
+	scale := max(0, 1-m.Drag*deltaTime)
+	for i := range particles {
+		particles[i].Velocity.ScaleAssign(scale)
+	}
+}
+
+// ColorOverLifetimeModule multiplies the start color of the particle by the
+// color of the curve at the particle's current point in its life
+type ColorOverLifetimeModule struct {
+	Curve ColorCurve
+}
+
+func (m *ColorOverLifetimeModule) Spawn(p *Particle, _ *SpawnContext) {
+	m.apply(p)
+}
+
+func (m *ColorOverLifetimeModule) Update(particles []Particle, _ matrix.Float) {
+	for i := range particles {
+		m.apply(&particles[i])
+	}
+}
+
+func (m *ColorOverLifetimeModule) apply(p *Particle) {
+	c := m.Curve.Evaluate(p.Life())
+	for j := range c {
+		p.Color[j] = p.StartColor[j] * c[j]
+	}
+}
+
+// SizeOverLifetimeModule multiplies the start size of the particle by the
+// value of the curve at the particle's current point in its life
+type SizeOverLifetimeModule struct {
+	Curve FloatCurve
+}
+
+func (m *SizeOverLifetimeModule) Spawn(p *Particle, _ *SpawnContext) {
+	p.Size = p.StartSize * m.Curve.Evaluate(0)
+}
+
+func (m *SizeOverLifetimeModule) Update(particles []Particle, _ matrix.Float) {
+	for i := range particles {
+		p := &particles[i]
+		p.Size = p.StartSize * m.Curve.Evaluate(p.Life())
+	}
+}
+
+// SpriteSheetModule animates the particle texture through a grid of frames.
+// Frames are read left to right, top to bottom. If FPS is 0, then the frames
+// are spread evenly over the lifetime of the particle.
+type SpriteSheetModule struct {
+	Columns     int
+	Rows        int
+	FrameCount  int
+	FPS         matrix.Float
+	RandomStart bool
+}
+
+func (m *SpriteSheetModule) frames() int {
+	if m.FrameCount > 0 {
+		return m.FrameCount
+	}
+	return max(1, m.Columns*m.Rows)
+}
+
+func (m *SpriteSheetModule) Spawn(p *Particle, ctx *SpawnContext) {
+	p.StartFrame = 0
+	if m.RandomStart {
+		p.StartFrame = ctx.Rand.Intn(m.frames())
+	}
+	p.UVs = m.FrameUVs(p.StartFrame)
+}
+
+func (m *SpriteSheetModule) Update(particles []Particle, _ matrix.Float) {
+	count := m.frames()
+	for i := range particles {
+		p := &particles[i]
+		var frame int
+		if m.FPS > 0 {
+			frame = int(p.Age * m.FPS)
+		} else {
+			frame = int(p.Life() * matrix.Float(count))
+		}
+		if m.RandomStart || m.FPS > 0 {
+			frame = (frame + p.StartFrame) % count
+		} else {
+			frame = min(frame, count-1)
+		}
+		p.UVs = m.FrameUVs(frame)
+	}
+}
+
+// FrameUVs returns the UV rectangle (x, y, width, height) of the given frame
+// in the format that the sprite shader expects
+func (m *SpriteSheetModule) FrameUVs(frame int) matrix.Vec4 {
+	cols := max(1, m.Columns)
+	rows := max(1, m.Rows)
+	w := 1.0 / matrix.Float(cols)
+	h := 1.0 / matrix.Float(rows)
+	x := matrix.Float(frame%cols) * w
+	y := matrix.Float((frame/cols)%rows) * h
+	return matrix.Vec4{x, 1.0 - h - y, w, h}
+}
@@ -0,0 +1,152 @@
+/******************************************************************************/
+/* particle.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"kaiju/matrix"
+	"math/rand"
+)
+
+// Particle is a single simulated particle. Particles are plain data so that
+// they can be stored contiguously and processed by the modules on many threads
+// at once.
+type Particle struct {
+	Position   matrix.Vec3
+	Velocity   matrix.Vec3
+	Color      matrix.Color
+	StartColor matrix.Color
+	UVs        matrix.Vec4
+	Size       matrix.Float
+	StartSize  matrix.Float
+	Rotation   matrix.Float
+	Age        matrix.Float
+	Lifetime   matrix.Float
+	StartFrame int
+}
+
+// Life returns the normalized age of the particle, 0 when it is spawned and 1
+// when it is about to die
+func (p *Particle) Life() matrix.Float {
+	if p.Lifetime <= 0 {
+		return 1
+	}
+	return matrix.Clamp(p.Age/p.Lifetime, 0, 1)
+}
+
+// IsAlive returns true if the particle has not yet reached its lifetime
+func (p *Particle) IsAlive() bool { return p.Age < p.Lifetime }
+
+// Range is a minimum and maximum value that a random value is selected between
+type Range struct {
+	Min matrix.Float
+	Max matrix.Float
+}
+
+// Random selects a random value within the range
+func (r Range) Random(rng *rand.Rand) matrix.Float {
+	if r.Max <= r.Min {
+		return r.Min
+	}
+	return r.Min + matrix.Float(rng.Float64())*(r.Max-r.Min)
+}
+
+// FloatKey is a single key on a #FloatCurve
+type FloatKey struct {
+	Time  matrix.Float
+	Value matrix.Float
+}
+
+// ColorKey is a single key on a #ColorCurve
+type ColorKey struct {
+	Time  matrix.Float
+	Color matrix.Color
+}
+
+// FloatCurve is a list of keys that are linearly interpolated between. The
+// keys are expected to be sorted by time, with time being in the range of 0
+// to 1.
+type FloatCurve []FloatKey
+
+// ColorCurve is a list of keys that are linearly interpolated between. The
+// keys are expected to be sorted by time, with time being in the range of 0
+// to 1.
+type ColorCurve []ColorKey
+
+// Evaluate returns the interpolated value of the curve at the given time. If
+// the curve has no keys, then 1 is returned.
+func (c FloatCurve) Evaluate(t matrix.Float) matrix.Float {
+	if len(c) == 0 {
+		return 1
+	}
+	if t <= c[0].Time {
+		return c[0].Value
+	}
+	for i := 1; i < len(c); i++ {
+		if t <= c[i].Time {
+			a, b := c[i-1], c[i]
+			span := b.Time - a.Time
+			if span <= 0 {
+				return b.Value
+			}
+			return a.Value + (b.Value-a.Value)*((t-a.Time)/span)
+		}
+	}
+	return c[len(c)-1].Value
+}
+
+// Evaluate returns the interpolated color of the curve at the given time. If
+// the curve has no keys, then white is returned.
+func (c ColorCurve) Evaluate(t matrix.Float) matrix.Color {
+	if len(c) == 0 {
+		return matrix.ColorWhite()
+	}
+	if t <= c[0].Time {
+		return c[0].Color
+	}
+	for i := 1; i < len(c); i++ {
+		if t <= c[i].Time {
+			a, b := c[i-1], c[i]
+			span := b.Time - a.Time
+			if span <= 0 {
+				return b.Color
+			}
+			return matrix.ColorMix(a.Color, b.Color, (t-a.Time)/span)
+		}
+	}
+	return c[len(c)-1].Color
+}
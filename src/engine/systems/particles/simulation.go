@@ -0,0 +1,242 @@
+/******************************************************************************/
+/* simulation.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"kaiju/matrix"
+	"kaiju/platform/concurrent"
+	"math/rand"
+	"sync"
+)
+
+// simulationChunkSize is the number of particles that are given to a single
+// thread at a time while updating the simulation
+const simulationChunkSize = 256
+
+type burstState struct {
+	next   matrix.Float
+	cycles int
+}
+
+// Simulation is the CPU side of a particle emitter. It spawns, updates, and
+// kills particles based on an #EmitterDefinition. It has no knowledge of the
+// rendering side of things so that it can be stepped on its own.
+type Simulation struct {
+	Definition EmitterDefinition
+	particles  []Particle
+	modules    []Module
+	bursts     []burstState
+	rng        *rand.Rand
+	spawnCtx   SpawnContext
+	time       matrix.Float
+	spawnAccum matrix.Float
+	playing    bool
+	emitting   bool
+}
+
+// NewSimulation creates a new simulation for the given definition. The
+// simulation will not start emitting until #Simulation.Play is called.
+func NewSimulation(def EmitterDefinition) *Simulation {
+	s := &Simulation{
+		Definition: def,
+		particles:  make([]Particle, 0, max(def.MaxParticles, 1)),
+		modules:    def.Modules(),
+		rng:        rand.New(rand.NewSource(def.Seed)),
+	}
+	s.spawnCtx.Rand = s.rng
+	s.resetBursts()
+	return s
+}
+
+// Particles returns all of the particles that are currently alive
+func (s *Simulation) Particles() []Particle { return s.particles }
+
+// IsPlaying returns true if the simulation is emitting or still has particles
+// that are alive
+func (s *Simulation) IsPlaying() bool { return s.playing }
+
+// IsEmitting returns true if the simulation is still spawning new particles
+func (s *Simulation) IsEmitting() bool { return s.emitting }
+
+// Time returns how long, in seconds, the current emission cycle has been
+// running for
+func (s *Simulation) Time() matrix.Float { return s.time }
+
+// Play will restart the emission of particles from the beginning, any live
+// particles will continue to live
+func (s *Simulation) Play() {
+	s.playing = true
+	s.emitting = true
+	s.time = 0
+	s.spawnAccum = 0
+	s.resetBursts()
+}
+
+// Stop will stop emitting new particles, the particles that are alive will
+// continue to be simulated until they die
+func (s *Simulation) Stop() { s.emitting = false }
+
+// Clear will immediately kill all of the live particles
+func (s *Simulation) Clear() { s.particles = s.particles[:0] }
+
+// Emit will immediately spawn the given number of particles, regardless of
+// whether or not the simulation is emitting. The emitter matrix is the world
+// matrix of the emitter and is only used for world space simulations.
+func (s *Simulation) Emit(count int, emitter matrix.Mat4) {
+	s.playing = true
+	for i := 0; i < count && len(s.particles) < cap(s.particles); i++ {
+		s.spawn(emitter)
+	}
+}
+
+// Update steps the simulation forward by the delta time. The emitter matrix
+// is the world matrix of the emitter and is only used for world space
+// simulations. If threads is not nil, then the particle modules are updated
+// across the threads, otherwise they are updated on the calling goroutine.
+func (s *Simulation) Update(deltaTime matrix.Float, emitter matrix.Mat4, threads *concurrent.Threads) {
+	if !s.playing {
+		return
+	}
+	if s.emitting {
+		s.updateEmission(deltaTime, emitter)
+	}
+	s.updateParticles(deltaTime, threads)
+	for i := 0; i < len(s.particles); i++ {
+		if !s.particles[i].IsAlive() {
+			last := len(s.particles) - 1
+			s.particles[i] = s.particles[last]
+			s.particles = s.particles[:last]
+			i--
+		}
+	}
+	if !s.emitting && len(s.particles) == 0 {
+		s.playing = false
+	}
+}
+
+func (s *Simulation) updateEmission(deltaTime matrix.Float, emitter matrix.Mat4) {
+	def := &s.Definition
+	s.time += deltaTime
+	s.spawnAccum += def.Rate * deltaTime
+	for s.spawnAccum >= 1 {
+		s.spawnAccum--
+		if len(s.particles) < cap(s.particles) {
+			s.spawn(emitter)
+		}
+	}
+	for i := range s.bursts {
+		b := &s.bursts[i]
+		for b.cycles > 0 && s.time >= b.next {
+			s.Emit(def.Bursts[i].Count, emitter)
+			b.cycles--
+			b.next += max(def.Bursts[i].Interval, matrix.FloatSmallestNonzero)
+		}
+	}
+	if def.Duration > 0 && s.time >= def.Duration {
+		if def.Looping {
+			s.time -= def.Duration
+			s.resetBursts()
+		} else {
+			s.emitting = false
+		}
+	}
+}
+
+func (s *Simulation) updateParticles(deltaTime matrix.Float, threads *concurrent.Threads) {
+	count := len(s.particles)
+	if threads == nil || threads.ThreadCount() == 0 || count <= simulationChunkSize {
+		s.updateRange(s.particles, deltaTime)
+		return
+	}
+	wg := sync.WaitGroup{}
+	for from := 0; from < count; from += simulationChunkSize {
+		chunk := s.particles[from:min(from+simulationChunkSize, count)]
+		wg.Add(1)
+		threads.AddWork(func(int) {
+			s.updateRange(chunk, deltaTime)
+			wg.Done()
+		})
+	}
+	wg.Wait()
+}
+
+func (s *Simulation) updateRange(particles []Particle, deltaTime matrix.Float) {
+	for i := range s.modules {
+		s.modules[i].Update(particles, deltaTime)
+	}
+	for i := range particles {
+		p := &particles[i]
+		p.Position.AddAssign(p.Velocity.Scale(deltaTime))
+		p.Age += deltaTime
+	}
+}
+
+func (s *Simulation) spawn(emitter matrix.Mat4) {
+	def := &s.Definition
+	pos, dir := def.Shape.Sample(s.rng)
+	if def.WorldSpace {
+		origin := emitter.TransformPoint(matrix.Vec3Zero())
+		pos = emitter.TransformPoint(pos)
+		dir = emitter.TransformPoint(dir).Subtract(origin).Normal()
+	}
+	s.particles = append(s.particles, Particle{
+		Position:   pos,
+		Color:      def.StartColor,
+		StartColor: def.StartColor,
+		UVs:        matrix.Vec4{0, 0, 1, 1},
+		Size:       def.StartSize.Random(s.rng),
+		Rotation:   def.StartRotation.Random(s.rng),
+		Lifetime:   1,
+	})
+	p := &s.particles[len(s.particles)-1]
+	p.StartSize = p.Size
+	s.spawnCtx.Direction = dir
+	for i := range s.modules {
+		s.modules[i].Spawn(p, &s.spawnCtx)
+	}
+}
+
+func (s *Simulation) resetBursts() {
+	s.bursts = s.bursts[:0]
+	for _, b := range s.Definition.Bursts {
+		s.bursts = append(s.bursts, burstState{
+			next:   b.Time,
+			cycles: max(b.Cycles, 1),
+		})
+	}
+}
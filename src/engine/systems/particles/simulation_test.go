@@ -0,0 +1,201 @@
+/******************************************************************************/
+/* simulation_test.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"kaiju/matrix"
+	"testing"
+)
+
+// testDefinition emits nothing on its own and keeps its particles alive for
+// the given number of seconds
+func testDefinition(lifetime matrix.Float) EmitterDefinition {
+	def := NewEmitterDefinition()
+	def.Rate = 0
+	def.Duration = 10
+	def.Looping = false
+	def.Lifetime = &LifetimeModule{Lifetime: Range{lifetime, lifetime}}
+	return def
+}
+
+// step updates the simulation the number of times by the delta time
+func step(s *Simulation, deltaTime matrix.Float, times int) {
+	for range times {
+		s.Update(deltaTime, matrix.Mat4Identity(), nil)
+	}
+}
+
+func expectCount(t *testing.T, s *Simulation, expected int) {
+	t.Helper()
+	if got := len(s.Particles()); got != expected {
+		t.Errorf("expected %d particles but got %d", expected, got)
+	}
+}
+
+func TestEmissionRate(t *testing.T) {
+	def := testDefinition(100)
+	def.Rate = 4
+	s := NewSimulation(def)
+	step(s, 0.25, 4)
+	expectCount(t, s, 0)
+	s.Play()
+	step(s, 0.25, 2)
+	expectCount(t, s, 2)
+	step(s, 0.25, 2)
+	expectCount(t, s, 4)
+	def.MaxParticles = 3
+	s = NewSimulation(def)
+	s.Play()
+	step(s, 0.25, 8)
+	expectCount(t, s, 3)
+}
+
+func TestBursts(t *testing.T) {
+	def := testDefinition(100)
+	def.Bursts = []Burst{{Time: 0.5, Count: 3, Cycles: 2, Interval: 0.5}}
+	s := NewSimulation(def)
+	s.Play()
+	step(s, 0.25, 1)
+	expectCount(t, s, 0)
+	step(s, 0.25, 1)
+	expectCount(t, s, 3)
+	step(s, 0.5, 1)
+	expectCount(t, s, 6)
+	step(s, 0.5, 4)
+	expectCount(t, s, 6)
+}
+
+func TestLooping(t *testing.T) {
+	def := testDefinition(100)
+	def.Duration = 1
+	def.Looping = true
+	def.Bursts = []Burst{{Count: 1}}
+	s := NewSimulation(def)
+	s.Play()
+	step(s, 0.5, 2)
+	expectCount(t, s, 1)
+	if !s.IsEmitting() || s.Time() != 0 {
+		t.Errorf("expected a looping emitter to start over, time is %f", s.Time())
+	}
+	step(s, 0.5, 2)
+	expectCount(t, s, 2)
+	def.Looping = false
+	s = NewSimulation(def)
+	s.Play()
+	step(s, 0.5, 4)
+	expectCount(t, s, 1)
+	if s.IsEmitting() {
+		t.Error("expected the emitter to stop at the end of its duration")
+	}
+}
+
+func TestLifetimeExpiry(t *testing.T) {
+	s := NewSimulation(testDefinition(1))
+	s.Emit(2, matrix.Mat4Identity())
+	if !s.IsPlaying() {
+		t.Error("expected emitting to start the simulation playing")
+	}
+	step(s, 0.5, 1)
+	expectCount(t, s, 2)
+	step(s, 0.5, 1)
+	expectCount(t, s, 0)
+	if s.IsPlaying() {
+		t.Error("expected the simulation to stop once its particles died")
+	}
+}
+
+func TestModules(t *testing.T) {
+	def := testDefinition(2)
+	def.Velocity = &VelocityModule{Constant: matrix.Vec3{1, 0, 0}}
+	def.Gravity = &GravityModule{Gravity: matrix.Vec3{0, -2, 0}}
+	def.SizeOverLifetime = &SizeOverLifetimeModule{Curve: FloatCurve{{0, 1}, {1, 3}}}
+	def.ColorOverLifetime = &ColorOverLifetimeModule{
+		Curve: ColorCurve{{0, matrix.ColorWhite()}, {1, matrix.ColorBlack()}},
+	}
+	s := NewSimulation(def)
+	s.Emit(1, matrix.Mat4Identity())
+	step(s, 0.5, 2)
+	p := s.Particles()[0]
+	if !matrix.Vec3Approx(p.Velocity, matrix.Vec3{1, -2, 0}) {
+		t.Errorf("unexpected velocity %v", p.Velocity)
+	}
+	if !matrix.Vec3Approx(p.Position, matrix.Vec3{1, -1.5, 0}) {
+		t.Errorf("unexpected position %v", p.Position)
+	}
+	// The modules update before the particle ages, so they last saw it at a
+	// quarter of its life
+	if !matrix.Approx(p.Size, 1.5) {
+		t.Errorf("expected a size of 1.5 but got %f", p.Size)
+	}
+	if !matrix.Approx(p.Color.R(), 0.75) || !matrix.Approx(p.Color.A(), 1) {
+		t.Errorf("unexpected color %v", p.Color)
+	}
+	particles := []Particle{{Velocity: matrix.Vec3{2, 0, 0}}}
+	(&DragModule{Drag: 1}).Update(particles, 0.5)
+	if !matrix.Vec3Approx(particles[0].Velocity, matrix.Vec3{1, 0, 0}) {
+		t.Errorf("expected drag to halve the velocity, got %v", particles[0].Velocity)
+	}
+	sheet := SpriteSheetModule{Columns: 2, Rows: 2}
+	if uvs := sheet.FrameUVs(0); uvs != (matrix.Vec4{0, 0.5, 0.5, 0.5}) {
+		t.Errorf("unexpected UVs for the first frame %v", uvs)
+	}
+	if uvs := sheet.FrameUVs(3); uvs != (matrix.Vec4{0.5, 0, 0.5, 0.5}) {
+		t.Errorf("unexpected UVs for the last frame %v", uvs)
+	}
+}
+
+func TestCurves(t *testing.T) {
+	if v := (FloatCurve{}).Evaluate(0.5); v != 1 {
+		t.Errorf("expected an empty curve to be 1, got %f", v)
+	}
+	curve := FloatCurve{{0, 0}, {0.5, 2}, {1, 0}}
+	for _, c := range []struct{ t, expected matrix.Float }{
+		{-1, 0}, {0.25, 1}, {0.5, 2}, {0.75, 1}, {2, 0},
+	} {
+		if v := curve.Evaluate(c.t); !matrix.Approx(v, c.expected) {
+			t.Errorf("expected %f at %f but got %f", c.expected, c.t, v)
+		}
+	}
+	if c := (ColorCurve{}).Evaluate(0.5); c != matrix.ColorWhite() {
+		t.Errorf("expected an empty curve to be white, got %v", c)
+	}
+	colors := ColorCurve{{0, matrix.ColorBlack()}, {1, matrix.ColorWhite()}}
+	if c := colors.Evaluate(0.5); !matrix.Approx(c.G(), 0.5) {
+		t.Errorf("expected half way between black and white, got %v", c)
+	}
+}
@@ -78,6 +78,7 @@ func (b *Button) Init(texture *rendering.Texture, text string, anchor Anchor) {
 		color: matrix.ColorWhite(),
 	}
 	p.Init(texture, anchor, ElementTypeButton)
+	b.Base().SetFocusable(true)
 	p.SetColor(matrix.ColorWhite())
 	b.setupEvents()
 	ps := p.layout.PixelSize()
@@ -99,6 +99,7 @@ func (cb *Checkbox) Init(anchor Anchor) {
 		ld.textures[i].MipLevels = 1
 	}
 	p.Init(ld.textures[texOffIdle], anchor, ElementTypeCheckbox)
+	base.SetFocusable(true)
 	base.AddEvent(EventTypeEnter, cb.onHover)
 	base.AddEvent(EventTypeExit, cb.onBlur)
 	base.AddEvent(EventTypeDown, cb.onDown)
@@ -184,6 +185,7 @@ func (cb *Checkbox) SetChecked(isChecked bool) {
 		}
 	}
 	(*Panel)(cb).SetBackground(target)
+	(*UI)(cb).requestEvent(EventTypeInput)
 	(*UI)(cb).requestEvent(EventTypeChange)
 }
 
@@ -0,0 +1,398 @@
+/******************************************************************************/
+/* data_grid.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package ui
+
+import (
+	"kaiju/engine/ui/virtual"
+	"kaiju/matrix"
+	"kaiju/rendering"
+)
+
+const (
+	dataGridHandleWidth = 6
+	dataGridCellPadding = 4
+)
+
+// DataGridColumn describes a column of a #DataGrid
+type DataGridColumn struct {
+	Title    string
+	Width    float32
+	MinWidth float32
+	// Less compares the items at a and b for sorting by the column, columns
+	// without it can't be sorted
+	Less func(a, b int) bool
+}
+
+// DataGridSource gives a #DataGrid its items
+type DataGridSource struct {
+	// Count is the number of items in the grid
+	Count func() int
+	// Cell is the text of the item for the column
+	Cell func(item, column int) string
+}
+
+type dataGridData struct {
+	panelData
+	source        DataGridSource
+	columns       []DataGridColumn
+	sizes         []virtual.Column
+	sort          virtual.Sort
+	order         []int
+	header        *Panel
+	headerCells   []*Panel
+	handles       []*Panel
+	list          *ListView
+	rowHeight     float32
+	headerScroll  float32
+	selectedColor matrix.Color
+	resizeColumn  int
+	resizeStart   float32
+	resizeWidth   float32
+	resizeUpdate  int
+}
+
+func (g *dataGridData) innerPanelData() *panelData { return &g.panelData }
+
+// DataGrid is a table with a header of columns over a #ListView of the items.
+// Clicking the title of a column sorts the items by it and dragging the edge
+// of a title resizes the column.
+type DataGrid Panel
+
+func (u *UI) ToDataGrid() *DataGrid { return (*DataGrid)(u) }
+func (g *DataGrid) Base() *UI       { return (*UI)(g) }
+
+func (g *DataGrid) DataGridData() *dataGridData {
+	return g.elmData.(*dataGridData)
+}
+
+func (g *DataGrid) Init(source DataGridSource, columns []DataGridColumn, rowHeight float32, anchor Anchor) {
+	gd := &dataGridData{
+		source:        source,
+		columns:       columns,
+		sizes:         make([]virtual.Column, len(columns)),
+		sort:          virtual.Unsorted,
+		rowHeight:     max(rowHeight, 1),
+		selectedColor: matrix.ColorDarkBlue(),
+		resizeColumn:  -1,
+	}
+	for i, c := range columns {
+		gd.sizes[i] = virtual.Column{Width: c.Width, MinWidth: c.MinWidth}
+	}
+	g.elmData = gd
+	p := (*Panel)(g)
+	p.Init(nil, anchor, ElementTypePanel)
+	p.DontFitContent()
+	p.SetScrollDirection(PanelScrollDirectionNone)
+	gd.header = g.man.Add().ToPanel()
+	gd.header.Init(nil, AnchorTopLeft, ElementTypePanel)
+	gd.header.DontFitContent()
+	gd.header.SetScrollDirection(PanelScrollDirectionNone)
+	gd.header.SetOverflow(OverflowHidden)
+	gd.header.layout.AddFunction(func(l *Layout) {
+		w, _ := p.layout.ContentSize()
+		l.Scale(w, gd.rowHeight)
+	})
+	for i := range columns {
+		gd.headerCells = append(gd.headerCells, g.headerCell(i))
+		gd.handles = append(gd.handles, g.resizeHandle(i))
+	}
+	gd.list = g.man.Add().ToListView()
+	gd.list.Init(ListViewSource{
+		Count:     g.count,
+		CreateRow: g.createRow,
+		BindRow:   g.bindRow,
+		RowHeight: func(int) float32 { return gd.rowHeight },
+	}, gd.rowHeight, AnchorTopLeft)
+	gd.list.layout.AddFunction(func(l *Layout) {
+		w, h := p.layout.ContentSize()
+		l.Scale(w, max(h-gd.rowHeight, 0))
+	})
+	p.AddChild(gd.header.Base())
+	p.AddChild(gd.list.Base())
+	gd.list.Base().AddEvent(EventTypeRender, g.followScroll)
+	g.columnsChanged()
+	g.Refresh()
+}
+
+// List is the list of the rows of the grid, the rows of the list are in the
+// sorted order, use #DataGrid.Item to get the item of a row
+func (g *DataGrid) List() *ListView { return g.DataGridData().list }
+
+// Item gives the index of the item shown in the row
+func (g *DataGrid) Item(row int) int {
+	gd := g.DataGridData()
+	if row < 0 || row >= len(gd.order) {
+		return -1
+	}
+	return gd.order[row]
+}
+
+// SelectedItems gives the items of the selected rows
+func (g *DataGrid) SelectedItems() []int {
+	rows := g.DataGridData().list.Selection()
+	for i := range rows {
+		rows[i] = g.Item(rows[i])
+	}
+	return rows
+}
+
+func (g *DataGrid) SetSelectedColor(color matrix.Color) {
+	gd := g.DataGridData()
+	gd.selectedColor = color
+	gd.list.ListViewData().recycler.Rebind()
+}
+
+// Refresh updates the grid after items were added, removed or changed, the
+// items are sorted again if the grid is sorted
+func (g *DataGrid) Refresh() {
+	gd := g.DataGridData()
+	g.resort()
+	gd.list.Refresh()
+	g.placeHeader()
+}
+
+// SortBy sorts the items by the column, -1 puts them back in their own order
+func (g *DataGrid) SortBy(column int, descending bool) {
+	gd := g.DataGridData()
+	if column < 0 || column >= len(gd.columns) || gd.columns[column].Less == nil {
+		gd.sort = virtual.Unsorted
+	} else {
+		gd.sort = virtual.Sort{Column: column, Descending: descending}
+	}
+	g.Refresh()
+}
+
+// Sort gives the column the grid is sorted by (-1 if it isn't) and if the
+// sort is descending
+func (g *DataGrid) Sort() (column int, descending bool) {
+	s := g.DataGridData().sort
+	return s.Column, s.Descending
+}
+
+func (g *DataGrid) ColumnWidth(column int) float32 {
+	gd := g.DataGridData()
+	if column < 0 || column >= len(gd.sizes) {
+		return 0
+	}
+	return gd.sizes[column].Width
+}
+
+func (g *DataGrid) SetColumnWidth(column int, width float32) {
+	gd := g.DataGridData()
+	if column < 0 || column >= len(gd.sizes) {
+		return
+	}
+	virtual.Resize(gd.sizes, column, width-gd.sizes[column].Width)
+	g.columnsChanged()
+}
+
+func (g *DataGrid) count() int { return len(g.DataGridData().order) }
+
+// resort builds the order of the items for the sort, the selection follows
+// the items it was on
+func (g *DataGrid) resort() {
+	gd := g.DataGridData()
+	count := 0
+	if gd.source.Count != nil {
+		count = gd.source.Count()
+	}
+	var less func(a, b int) bool
+	if gd.sort.Column >= 0 {
+		less = gd.columns[gd.sort.Column].Less
+	}
+	order := virtual.Order(count, less, gd.sort.Descending)
+	if len(gd.order) == count {
+		// The rows are remapped from where each item was to where it is now
+		shownAt := make([]int, count)
+		for row, item := range gd.order {
+			shownAt[item] = row
+		}
+		remap := make([]int, count)
+		for row, item := range order {
+			remap[row] = shownAt[item]
+		}
+		gd.list.remapSelection(remap)
+	}
+	gd.order = order
+}
+
+func (g *DataGrid) columnsChanged() {
+	gd := g.DataGridData()
+	gd.list.SetContentWidth(virtual.Offsets(gd.sizes)[len(gd.sizes)])
+	gd.list.ListViewData().recycler.Rebind()
+	g.placeHeader()
+}
+
+func (g *DataGrid) headerCell(column int) *Panel {
+	gd := g.DataGridData()
+	cell := g.cell(gd.header)
+	cell.Base().AddEvent(EventTypeClick, func() {
+		if gd.columns[column].Less == nil {
+			return
+		}
+		s := gd.sort.Toggle(column)
+		g.SortBy(s.Column, s.Descending)
+	})
+	return cell
+}
+
+func (g *DataGrid) resizeHandle(column int) *Panel {
+	gd := g.DataGridData()
+	handle := g.man.Add().ToPanel()
+	handle.Init(nil, AnchorTopLeft, ElementTypePanel)
+	handle.DontFitContent()
+	handle.layout.SetPositioning(PositioningAbsolute)
+	handle.SetColor(matrix.ColorGray())
+	gd.header.AddChild(handle.Base())
+	handle.Base().AddEvent(EventTypeDown, func() {
+		gd.resizeColumn = column
+		gd.resizeStart = g.man.Host.Window.Cursor.ScreenPosition().X()
+		gd.resizeWidth = gd.sizes[column].Width
+		if gd.resizeUpdate == 0 {
+			gd.resizeUpdate = g.man.Host.UIUpdater.AddUpdate(g.resizeDrag)
+		}
+	})
+	return handle
+}
+
+// resizeDrag follows the cursor while the edge of a column is held
+func (g *DataGrid) resizeDrag(float64) {
+	gd := g.DataGridData()
+	if gd.resizeColumn < 0 || !gd.handles[gd.resizeColumn].Base().IsDown() {
+		gd.resizeColumn = -1
+		g.man.Host.UIUpdater.RemoveUpdate(gd.resizeUpdate)
+		gd.resizeUpdate = 0
+		return
+	}
+	x := g.man.Host.Window.Cursor.ScreenPosition().X()
+	width := gd.resizeWidth + x - gd.resizeStart
+	if width != gd.sizes[gd.resizeColumn].Width {
+		g.SetColumnWidth(gd.resizeColumn, width)
+	}
+}
+
+// cell makes a panel holding a label that is placed in its parent by the
+// offset of the column
+func (g *DataGrid) cell(parent *Panel) *Panel {
+	cell := g.man.Add().ToPanel()
+	cell.Init(nil, AnchorTopLeft, ElementTypePanel)
+	cell.DontFitContent()
+	cell.SetScrollDirection(PanelScrollDirectionNone)
+	cell.SetOverflow(OverflowHidden)
+	cell.layout.SetPositioning(PositioningAbsolute)
+	cell.layout.SetPadding(dataGridCellPadding, 0, dataGridCellPadding, 0)
+	label := g.man.Add().ToLabel()
+	label.Init("", AnchorTopLeft)
+	label.SetBaseline(rendering.FontBaselineCenter)
+	label.SetBGColor(matrix.ColorTransparent())
+	cell.AddChild(label.Base())
+	parent.AddChild(cell.Base())
+	return cell
+}
+
+func (g *DataGrid) placeCell(cell *Panel, x, width float32, text string) {
+	gd := g.DataGridData()
+	cell.layout.SetInnerOffsetLeft(x)
+	cell.layout.Scale(max(width, 1), gd.rowHeight)
+	label := cell.Base().entity.Children[0]
+	if lbl := FirstOnEntity(label); lbl != nil && lbl.ToLabel().Text() != text {
+		lbl.ToLabel().SetText(text)
+	}
+}
+
+func (g *DataGrid) placeHeader() {
+	gd := g.DataGridData()
+	offsets := virtual.Offsets(gd.sizes)
+	for i, cell := range gd.headerCells {
+		title := gd.columns[i].Title
+		if gd.sort.Column == i {
+			if gd.sort.Descending {
+				title += " v"
+			} else {
+				title += " ^"
+			}
+		}
+		x := offsets[i] - gd.headerScroll
+		g.placeCell(cell, x, gd.sizes[i].Width-dataGridHandleWidth, title)
+		handle := gd.handles[i]
+		handle.layout.SetInnerOffsetLeft(x + gd.sizes[i].Width - dataGridHandleWidth)
+		handle.layout.Scale(dataGridHandleWidth, gd.rowHeight)
+	}
+}
+
+// followScroll keeps the header over the columns when the rows are scrolled
+// horizontally
+func (g *DataGrid) followScroll() {
+	gd := g.DataGridData()
+	if x := (*Panel)(gd.list).ScrollX(); x != gd.headerScroll {
+		gd.headerScroll = x
+		g.placeHeader()
+	}
+}
+
+func (g *DataGrid) createRow(man *Manager) *Panel {
+	gd := g.DataGridData()
+	row := man.Add().ToPanel()
+	row.Init(nil, AnchorTopLeft, ElementTypePanel)
+	row.SetScrollDirection(PanelScrollDirectionNone)
+	for range gd.columns {
+		g.cell(row)
+	}
+	return row
+}
+
+func (g *DataGrid) bindRow(row *Panel, index int, selected bool) {
+	gd := g.DataGridData()
+	item := gd.order[index]
+	offsets := virtual.Offsets(gd.sizes)
+	for i, kid := range row.entity.Children {
+		if i >= len(gd.columns) {
+			break
+		}
+		text := ""
+		if gd.source.Cell != nil {
+			text = gd.source.Cell(item, i)
+		}
+		g.placeCell(FirstPanelOnEntity(kid), offsets[i], gd.sizes[i].Width, text)
+	}
+	if selected {
+		row.SetColor(gd.selectedColor)
+	} else {
+		row.SetColor(matrix.ColorTransparent())
+	}
+}
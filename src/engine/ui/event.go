@@ -63,5 +63,8 @@ const (
 	EventTypeRender
 	EventTypeKeyDown
 	EventTypeKeyUp
+	EventTypeFocus
+	EventTypeBlur
+	EventTypeInput
 	EventTypeEnd
 )
@@ -0,0 +1,530 @@
+/******************************************************************************/
+/* flex.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+// Package flex is the CSS flexible box layout algorithm. It works on plain
+// sizes so that it can be used (and tested) without any UI, #ui.Panel uses
+// it to place its children when it is a flex container.
+package flex
+
+import (
+	"math"
+	"slices"
+)
+
+type Direction int
+
+const (
+	DirectionRow = Direction(iota)
+	DirectionRowReverse
+	DirectionColumn
+	DirectionColumnReverse
+)
+
+func (d Direction) IsRow() bool     { return d == DirectionRow || d == DirectionRowReverse }
+func (d Direction) IsReverse() bool { return d == DirectionRowReverse || d == DirectionColumnReverse }
+
+type Wrap int
+
+const (
+	WrapNone = Wrap(iota)
+	WrapWrap
+	WrapReverse
+)
+
+// Justify is how the free space of a line (justify-content) or of the
+// container's cross axis (align-content) is handed out
+type Justify int
+
+const (
+	JustifyStart = Justify(iota)
+	JustifyEnd
+	JustifyCenter
+	JustifySpaceBetween
+	JustifySpaceAround
+	JustifySpaceEvenly
+	// JustifyStretch grows the lines to fill the container, it is only used
+	// by align-content and acts as #JustifyStart for justify-content
+	JustifyStretch
+)
+
+// Align is how an item is placed on the cross axis of its line
+type Align int
+
+const (
+	// AlignAuto uses the align-items of the container, on the container it
+	// is the same as #AlignStretch
+	AlignAuto = Align(iota)
+	AlignStart
+	AlignEnd
+	AlignCenter
+	AlignStretch
+	// AlignBaseline is placed the same as #AlignStart as the items don't
+	// know where their text baseline is
+	AlignBaseline
+)
+
+// Auto is used for a flex basis that comes from the size of the item
+const Auto = -1
+
+type Container struct {
+	Direction    Direction
+	Wrap         Wrap
+	Justify      Justify
+	AlignItems   Align
+	AlignContent Justify
+	RowGap       float32
+	ColumnGap    float32
+}
+
+// DefaultContainer returns a container with the initial values CSS uses
+func DefaultContainer() Container {
+	return Container{AlignItems: AlignStretch, AlignContent: JustifyStretch}
+}
+
+// Item is a child of a flex container. The sizes are of the border box of
+// the item, margins are outside of them.
+type Item struct {
+	// Width and Height are the size the item would be without flexing
+	Width, Height float32
+	// Basis is the starting main size of the item, or #Auto to use its
+	// width or height
+	Basis  float32
+	Grow   float32
+	Shrink float32
+	// The limits of the item's size, a max of 0 has no limit
+	MinWidth, MinHeight float32
+	MaxWidth, MaxHeight float32
+	// Margin is the left, top, right and bottom margin
+	Margin    [4]float32
+	Order     int
+	AlignSelf Align
+	// FixedWidth and FixedHeight are set when the item has an explicit size
+	// that stretching on the cross axis must not change
+	FixedWidth, FixedHeight bool
+}
+
+// DefaultItem returns an item with the initial values CSS uses
+func DefaultItem() Item {
+	return Item{Basis: Auto, Shrink: 1}
+}
+
+// Rect is where an item was placed, relative to the top left of the content
+// box of the container
+type Rect struct {
+	X, Y, Width, Height float32
+}
+
+// Result is the placement of each item, in the same order as the items that
+// were given, along with the size the items take up so containers that fit
+// their content can size themselves
+type Result struct {
+	Rects         []Rect
+	ContentWidth  float32
+	ContentHeight float32
+}
+
+// axisItem is an item with its sizes swapped into main and cross axes
+type axisItem struct {
+	index                      int
+	base, hypothetical, target float32
+	min, max                   float32
+	crossSize                  float32
+	crossMin, crossMax         float32
+	marginMainStart            float32
+	marginMainEnd              float32
+	marginCrossStart           float32
+	marginCrossEnd             float32
+	grow, shrink               float32
+	align                      Align
+	fixedCross                 bool
+	frozen                     bool
+	violation                  float32
+	mainPos, crossPos          float32
+}
+
+func (a *axisItem) outerMain() float32 {
+	return a.target + a.marginMainStart + a.marginMainEnd
+}
+
+func (a *axisItem) outerCross() float32 {
+	return a.crossSize + a.marginCrossStart + a.marginCrossEnd
+}
+
+type line struct {
+	items      []*axisItem
+	crossSize  float32
+	crossStart float32
+}
+
+func clampSize(v, lo, hi float32) float32 {
+	if hi > 0 && v > hi {
+		v = hi
+	}
+	return max(v, lo)
+}
+
+// Arrange places the items in a container with the content size. A width or
+// height less than 0 is indefinite, as it is for a container that fits its
+// content, the items are then given the size they need.
+func Arrange(c Container, width, height float32, items []Item) Result {
+	isRow := c.Direction.IsRow()
+	mainSize, crossSize := width, height
+	mainGap, crossGap := c.ColumnGap, c.RowGap
+	if !isRow {
+		mainSize, crossSize = height, width
+		mainGap, crossGap = c.RowGap, c.ColumnGap
+	}
+	axis := make([]axisItem, len(items))
+	for i := range items {
+		axis[i] = toAxis(c, &items[i], i, isRow)
+	}
+	ordered := make([]*axisItem, len(axis))
+	for i := range axis {
+		ordered[i] = &axis[i]
+	}
+	slices.SortStableFunc(ordered, func(a, b *axisItem) int {
+		return items[a.index].Order - items[b.index].Order
+	})
+	lines := collectLines(c, ordered, mainSize, mainGap)
+	usedMain := float32(0)
+	for i := range lines {
+		resolveFlexible(&lines[i], mainSize, mainGap)
+		used := float32(0)
+		for _, it := range lines[i].items {
+			used += it.outerMain()
+		}
+		used += mainGap * float32(max(len(lines[i].items)-1, 0))
+		usedMain = max(usedMain, used)
+	}
+	if mainSize < 0 {
+		mainSize = usedMain
+	}
+	crossSizeLines(c, lines, crossSize)
+	totalCross := float32(0)
+	for i := range lines {
+		totalCross += lines[i].crossSize
+	}
+	totalCross += crossGap * float32(max(len(lines)-1, 0))
+	if crossSize < 0 {
+		crossSize = totalCross
+	}
+	placeLines(c, lines, crossSize, crossGap, totalCross)
+	for i := range lines {
+		justifyLine(c, &lines[i], mainSize, mainGap)
+		alignLine(c, &lines[i])
+	}
+	res := Result{Rects: make([]Rect, len(items))}
+	for i := range axis {
+		a := &axis[i]
+		mainPos, crossPos := a.mainPos, a.crossPos
+		if c.Direction.IsReverse() {
+			mainPos = mainSize - mainPos - a.target
+		}
+		if c.Wrap == WrapReverse {
+			crossPos = crossSize - crossPos - a.crossSize
+		}
+		if isRow {
+			res.Rects[a.index] = Rect{mainPos, crossPos, a.target, a.crossSize}
+		} else {
+			res.Rects[a.index] = Rect{crossPos, mainPos, a.crossSize, a.target}
+		}
+	}
+	if isRow {
+		res.ContentWidth, res.ContentHeight = usedMain, totalCross
+	} else {
+		res.ContentWidth, res.ContentHeight = totalCross, usedMain
+	}
+	return res
+}
+
+func toAxis(c Container, item *Item, index int, isRow bool) axisItem {
+	a := axisItem{
+		index:  index,
+		grow:   max(item.Grow, 0),
+		shrink: max(item.Shrink, 0),
+		align:  item.AlignSelf,
+	}
+	if a.align == AlignAuto {
+		a.align = c.AlignItems
+		if a.align == AlignAuto {
+			a.align = AlignStretch
+		}
+	}
+	m := item.Margin
+	natural := item.Width
+	if isRow {
+		a.min, a.max = item.MinWidth, item.MaxWidth
+		a.crossSize, a.crossMin, a.crossMax = item.Height, item.MinHeight, item.MaxHeight
+		a.marginMainStart, a.marginMainEnd = m[0], m[2]
+		a.marginCrossStart, a.marginCrossEnd = m[1], m[3]
+		a.fixedCross = item.FixedHeight
+	} else {
+		natural = item.Height
+		a.min, a.max = item.MinHeight, item.MaxHeight
+		a.crossSize, a.crossMin, a.crossMax = item.Width, item.MinWidth, item.MaxWidth
+		a.marginMainStart, a.marginMainEnd = m[1], m[3]
+		a.marginCrossStart, a.marginCrossEnd = m[0], m[2]
+		a.fixedCross = item.FixedWidth
+	}
+	a.base = natural
+	if item.Basis >= 0 {
+		a.base = item.Basis
+	}
+	a.hypothetical = clampSize(a.base, a.min, a.max)
+	a.target = a.hypothetical
+	a.crossSize = clampSize(a.crossSize, a.crossMin, a.crossMax)
+	return a
+}
+
+func collectLines(c Container, items []*axisItem, mainSize, gap float32) []line {
+	lines := []line{}
+	current := line{}
+	used := float32(0)
+	for _, it := range items {
+		outer := it.hypothetical + it.marginMainStart + it.marginMainEnd
+		if c.Wrap != WrapNone && mainSize >= 0 && len(current.items) > 0 &&
+			used+gap+outer > mainSize {
+			lines = append(lines, current)
+			current, used = line{}, 0
+		}
+		if len(current.items) > 0 {
+			used += gap
+		}
+		used += outer
+		current.items = append(current.items, it)
+	}
+	return append(lines, current)
+}
+
+// resolveFlexible grows or shrinks the items of the line to fill the main
+// size, following the freezing loop of the CSS specification so that items
+// that hit their min or max hand their share to the others
+func resolveFlexible(l *line, mainSize, gap float32) {
+	if mainSize < 0 || len(l.items) == 0 {
+		return
+	}
+	available := mainSize - gap*float32(len(l.items)-1)
+	hypothetical := float32(0)
+	for _, it := range l.items {
+		hypothetical += it.hypothetical + it.marginMainStart + it.marginMainEnd
+	}
+	growing := hypothetical < available
+	for _, it := range l.items {
+		it.frozen = false
+		factor := it.shrink
+		if growing {
+			factor = it.grow
+		}
+		if factor == 0 || growing && it.base > it.hypothetical ||
+			!growing && it.base < it.hypothetical {
+			it.frozen = true
+		}
+		it.target = it.hypothetical
+	}
+	for iteration := 0; iteration <= len(l.items); iteration++ {
+		free := available
+		sumFactors := float32(0)
+		sumScaledShrink := float32(0)
+		unfrozen := 0
+		for _, it := range l.items {
+			if it.frozen {
+				free -= it.outerMain()
+			} else {
+				free -= it.base + it.marginMainStart + it.marginMainEnd
+				sumFactors += it.grow
+				sumScaledShrink += it.shrink * it.base
+				unfrozen++
+			}
+		}
+		if unfrozen == 0 {
+			break
+		}
+		// Factors that add up to less than 1 only take that part of the space
+		if growing && sumFactors < 1 {
+			free *= sumFactors
+		} else if !growing {
+			shrinkSum := float32(0)
+			for _, it := range l.items {
+				if !it.frozen {
+					shrinkSum += it.shrink
+				}
+			}
+			if shrinkSum < 1 {
+				free *= shrinkSum
+			}
+		}
+		totalViolation := float32(0)
+		for _, it := range l.items {
+			if it.frozen {
+				continue
+			}
+			target := it.base
+			if growing && sumFactors > 0 {
+				target += free * it.grow / sumFactors
+			} else if !growing && sumScaledShrink > 0 {
+				target += free * it.shrink * it.base / sumScaledShrink
+			}
+			it.target = clampSize(max(target, 0), it.min, it.max)
+			it.violation = it.target - target
+			totalViolation += it.violation
+		}
+		done := true
+		for _, it := range l.items {
+			if it.frozen {
+				continue
+			}
+			switch {
+			case math.Abs(float64(totalViolation)) < 0.0001:
+				it.frozen = true
+			case totalViolation > 0 && it.violation > 0:
+				it.frozen = true
+			case totalViolation < 0 && it.violation < 0:
+				it.frozen = true
+			}
+			done = done && it.frozen
+		}
+		if done {
+			break
+		}
+	}
+}
+
+func crossSizeLines(c Container, lines []line, crossSize float32) {
+	for i := range lines {
+		l := &lines[i]
+		for _, it := range l.items {
+			l.crossSize = max(l.crossSize, it.outerCross())
+		}
+	}
+	// A single line fills a container with a definite cross size
+	if c.Wrap == WrapNone && crossSize >= 0 && len(lines) == 1 {
+		lines[0].crossSize = crossSize
+	}
+	for i := range lines {
+		l := &lines[i]
+		for _, it := range l.items {
+			if it.align == AlignStretch && !it.fixedCross {
+				it.crossSize = clampSize(l.crossSize-it.marginCrossStart-it.marginCrossEnd,
+					it.crossMin, it.crossMax)
+			}
+		}
+	}
+}
+
+// distribute returns where the first of count things starts and the extra
+// space between them for the free space
+func distribute(j Justify, free float32, count int) (float32, float32) {
+	if count == 0 {
+		return 0, 0
+	}
+	switch j {
+	case JustifyEnd:
+		return free, 0
+	case JustifyCenter:
+		return free / 2, 0
+	case JustifySpaceBetween:
+		if count == 1 || free < 0 {
+			return 0, 0
+		}
+		return 0, free / float32(count-1)
+	case JustifySpaceAround:
+		if free < 0 {
+			return free / 2, 0
+		}
+		space := free / float32(count)
+		return space / 2, space
+	case JustifySpaceEvenly:
+		if free < 0 {
+			return free / 2, 0
+		}
+		space := free / float32(count+1)
+		return space, space
+	}
+	return 0, 0
+}
+
+func placeLines(c Container, lines []line, crossSize, gap, totalCross float32) {
+	free := crossSize - totalCross
+	// A single line that doesn't wrap already fills the container
+	if c.Wrap == WrapNone {
+		free = 0
+	}
+	if c.AlignContent == JustifyStretch && free > 0 {
+		extra := free / float32(len(lines))
+		for i := range lines {
+			lines[i].crossSize += extra
+			for _, it := range lines[i].items {
+				if it.align == AlignStretch && !it.fixedCross {
+					it.crossSize = clampSize(lines[i].crossSize-it.marginCrossStart-it.marginCrossEnd,
+						it.crossMin, it.crossMax)
+				}
+			}
+		}
+		free = 0
+	}
+	pos, between := distribute(c.AlignContent, free, len(lines))
+	for i := range lines {
+		lines[i].crossStart = pos
+		pos += lines[i].crossSize + gap + between
+	}
+}
+
+func justifyLine(c Container, l *line, mainSize, gap float32) {
+	used := gap * float32(max(len(l.items)-1, 0))
+	for _, it := range l.items {
+		used += it.outerMain()
+	}
+	pos, between := distribute(c.Justify, mainSize-used, len(l.items))
+	for _, it := range l.items {
+		it.mainPos = pos + it.marginMainStart
+		pos += it.outerMain() + gap + between
+	}
+}
+
+func alignLine(c Container, l *line) {
+	for _, it := range l.items {
+		free := l.crossSize - it.outerCross()
+		offset := float32(0)
+		switch it.align {
+		case AlignEnd:
+			offset = free
+		case AlignCenter:
+			offset = free / 2
+		}
+		it.crossPos = l.crossStart + offset + it.marginCrossStart
+	}
+}
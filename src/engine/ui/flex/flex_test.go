@@ -0,0 +1,226 @@
+/******************************************************************************/
+/* flex_test.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package flex
+
+import (
+	"math"
+	"testing"
+)
+
+func item(width, height float32) Item {
+	i := DefaultItem()
+	i.Width, i.Height = width, height
+	return i
+}
+
+func expectRects(t *testing.T, res Result, expected []Rect) {
+	t.Helper()
+	if len(res.Rects) != len(expected) {
+		t.Fatalf("expected %d rects but got %d", len(expected), len(res.Rects))
+	}
+	for i := range expected {
+		a, b := res.Rects[i], expected[i]
+		if math.Abs(float64(a.X-b.X)) > 0.001 || math.Abs(float64(a.Y-b.Y)) > 0.001 ||
+			math.Abs(float64(a.Width-b.Width)) > 0.001 ||
+			math.Abs(float64(a.Height-b.Height)) > 0.001 {
+			t.Errorf("rect %d expected %v but got %v", i, b, a)
+		}
+	}
+}
+
+func TestGrow(t *testing.T) {
+	items := []Item{item(50, 20), item(50, 20), item(50, 20)}
+	items[0].Grow = 1
+	items[1].Grow = 2
+	res := Arrange(DefaultContainer(), 300, 100, items)
+	expectRects(t, res, []Rect{
+		{0, 0, 100, 100}, {100, 0, 150, 100}, {250, 0, 50, 100},
+	})
+}
+
+func TestShrinkIsWeightedByBasis(t *testing.T) {
+	items := []Item{item(100, 10), item(100, 10), item(100, 10)}
+	items[2].Shrink = 2
+	c := DefaultContainer()
+	c.AlignItems = AlignStart
+	res := Arrange(c, 200, 50, items)
+	expectRects(t, res, []Rect{
+		{0, 0, 75, 10}, {75, 0, 75, 10}, {150, 0, 50, 10},
+	})
+}
+
+func TestShrinkFreezesAtMin(t *testing.T) {
+	items := []Item{item(100, 10), item(100, 10)}
+	items[0].MinWidth = 80
+	c := DefaultContainer()
+	c.AlignItems = AlignStart
+	res := Arrange(c, 100, 50, items)
+	expectRects(t, res, []Rect{{0, 0, 80, 10}, {80, 0, 20, 10}})
+}
+
+func TestGrowFreezesAtMax(t *testing.T) {
+	items := []Item{item(0, 10), item(0, 10)}
+	items[0].Grow, items[1].Grow = 1, 1
+	items[0].MaxWidth = 30
+	c := DefaultContainer()
+	c.AlignItems = AlignStart
+	res := Arrange(c, 100, 50, items)
+	expectRects(t, res, []Rect{{0, 0, 30, 10}, {30, 0, 70, 10}})
+}
+
+func TestBasis(t *testing.T) {
+	items := []Item{item(10, 10), item(10, 10)}
+	items[0].Basis = 60
+	items[0].Grow, items[1].Grow = 1, 1
+	c := DefaultContainer()
+	c.AlignItems = AlignStart
+	res := Arrange(c, 100, 50, items)
+	expectRects(t, res, []Rect{{0, 0, 75, 10}, {75, 0, 25, 10}})
+}
+
+func TestWrapWithGaps(t *testing.T) {
+	c := DefaultContainer()
+	c.Wrap = WrapWrap
+	c.ColumnGap = 10
+	c.RowGap = 5
+	items := []Item{item(40, 10), item(40, 20), item(40, 10)}
+	res := Arrange(c, 100, -1, items)
+	expectRects(t, res, []Rect{
+		{0, 0, 40, 20}, {50, 0, 40, 20}, {0, 25, 40, 10},
+	})
+	if res.ContentWidth != 90 || res.ContentHeight != 35 {
+		t.Errorf("expected content size 90x35 but got %vx%v",
+			res.ContentWidth, res.ContentHeight)
+	}
+}
+
+func TestWrapReverse(t *testing.T) {
+	c := DefaultContainer()
+	c.Wrap = WrapReverse
+	c.AlignItems = AlignStart
+	items := []Item{item(60, 20), item(60, 20)}
+	res := Arrange(c, 100, -1, items)
+	expectRects(t, res, []Rect{{0, 20, 60, 20}, {0, 0, 60, 20}})
+}
+
+func TestJustifyAndAlign(t *testing.T) {
+	c := DefaultContainer()
+	c.AlignItems = AlignCenter
+	tests := []struct {
+		justify Justify
+		x       [2]float32
+	}{
+		{JustifyStart, [2]float32{0, 40}},
+		{JustifyEnd, [2]float32{120, 160}},
+		{JustifyCenter, [2]float32{60, 100}},
+		{JustifySpaceBetween, [2]float32{0, 160}},
+		{JustifySpaceAround, [2]float32{30, 130}},
+		{JustifySpaceEvenly, [2]float32{40, 120}},
+	}
+	for _, test := range tests {
+		c.Justify = test.justify
+		res := Arrange(c, 200, 50, []Item{item(40, 10), item(40, 10)})
+		expectRects(t, res, []Rect{
+			{test.x[0], 20, 40, 10}, {test.x[1], 20, 40, 10},
+		})
+	}
+}
+
+func TestAlignSelf(t *testing.T) {
+	items := []Item{item(10, 10), item(10, 10), item(10, 10), item(10, 10)}
+	items[0].AlignSelf = AlignStart
+	items[1].AlignSelf = AlignEnd
+	items[2].AlignSelf = AlignCenter
+	items[3].FixedHeight = true
+	res := Arrange(DefaultContainer(), 100, 50, items)
+	expectRects(t, res, []Rect{
+		{0, 0, 10, 10}, {10, 40, 10, 10}, {20, 20, 10, 10}, {30, 0, 10, 10},
+	})
+}
+
+func TestRowReverse(t *testing.T) {
+	c := DefaultContainer()
+	c.Direction = DirectionRowReverse
+	c.AlignItems = AlignStart
+	res := Arrange(c, 200, 50, []Item{item(40, 10), item(30, 10)})
+	expectRects(t, res, []Rect{{160, 0, 40, 10}, {130, 0, 30, 10}})
+}
+
+func TestColumnWithOrder(t *testing.T) {
+	c := DefaultContainer()
+	c.Direction = DirectionColumn
+	c.Justify = JustifyCenter
+	c.AlignItems = AlignStart
+	items := []Item{item(30, 50), item(30, 60)}
+	items[0].Order = 1
+	res := Arrange(c, 100, 300, items)
+	expectRects(t, res, []Rect{{0, 155, 30, 50}, {0, 95, 30, 60}})
+}
+
+func TestColumnStretchesWidth(t *testing.T) {
+	c := DefaultContainer()
+	c.Direction = DirectionColumnReverse
+	res := Arrange(c, 100, 100, []Item{item(30, 20), item(30, 30)})
+	expectRects(t, res, []Rect{{0, 80, 100, 20}, {0, 50, 100, 30}})
+}
+
+func TestMargins(t *testing.T) {
+	c := DefaultContainer()
+	c.AlignItems = AlignStart
+	items := []Item{item(50, 20), item(50, 20)}
+	items[0].Margin = [4]float32{10, 5, 10, 5}
+	res := Arrange(c, 200, 100, items)
+	expectRects(t, res, []Rect{{10, 5, 50, 20}, {70, 0, 50, 20}})
+}
+
+func TestAlignContent(t *testing.T) {
+	c := DefaultContainer()
+	c.Wrap = WrapWrap
+	c.AlignItems = AlignStart
+	items := []Item{item(60, 20), item(60, 20)}
+	c.AlignContent = JustifySpaceBetween
+	expectRects(t, Arrange(c, 100, 100, items),
+		[]Rect{{0, 0, 60, 20}, {0, 80, 60, 20}})
+	c.AlignContent = JustifyCenter
+	expectRects(t, Arrange(c, 100, 100, items),
+		[]Rect{{0, 30, 60, 20}, {0, 50, 60, 20}})
+	c.AlignContent = JustifyStretch
+	c.AlignItems = AlignStretch
+	expectRects(t, Arrange(c, 100, 100, items),
+		[]Rect{{0, 0, 60, 50}, {0, 50, 60, 50}})
+}
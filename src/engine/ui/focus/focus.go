@@ -0,0 +1,186 @@
+/******************************************************************************/
+/* focus.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+// Package focus decides where keyboard and gamepad focus moves to between the
+// elements of a #ui.Group, either along the tab order or to the nearest
+// element in a direction. It has no UI so that it can be tested on its own.
+package focus
+
+import (
+	"kaiju/matrix"
+	"slices"
+)
+
+// Direction is a direction that the focus can be moved in
+type Direction int
+
+const (
+	DirectionUp = Direction(iota)
+	DirectionDown
+	DirectionLeft
+	DirectionRight
+)
+
+// offAxisWeight is how much worse it is for an element to be off to the side
+// of the direction than to be further away in the direction
+const offAxisWeight = 2
+
+// Target is an element that can take focus. Bounds are the left, bottom,
+// right and top edges of the element with Y going up. A negative TabIndex can
+// only be focused directly, it is skipped by both the tab order and the
+// directional navigation.
+type Target struct {
+	Bounds   matrix.Vec4
+	TabIndex int
+}
+
+func (t Target) left() float32   { return t.Bounds.X() }
+func (t Target) bottom() float32 { return t.Bounds.Y() }
+func (t Target) right() float32  { return t.Bounds.Z() }
+func (t Target) top() float32    { return t.Bounds.W() }
+
+func (t Target) center() matrix.Vec2 {
+	return matrix.Vec2{(t.left() + t.right()) * 0.5, (t.bottom() + t.top()) * 0.5}
+}
+
+// TabOrder is the index of each target in the order tab moves through them,
+// the targets with a positive tab index come first in the order of their tab
+// index and then the rest in the order they were given
+func TabOrder(targets []Target) []int {
+	order := make([]int, 0, len(targets))
+	for i := range targets {
+		if targets[i].TabIndex >= 0 {
+			order = append(order, i)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		ta, tb := targets[a].TabIndex, targets[b].TabIndex
+		switch {
+		case ta == tb:
+			return 0
+		case ta == 0:
+			return 1
+		case tb == 0:
+			return -1
+		}
+		return ta - tb
+	})
+	return order
+}
+
+// Next is the index of the target after the current one in the tab order, or
+// before it when reverse, wrapping around at the ends. When the current index
+// is not in the tab order the first (or last) target is picked. Returns -1 if
+// there are no targets in the tab order.
+func Next(targets []Target, current int, reverse bool) int {
+	order := TabOrder(targets)
+	if len(order) == 0 {
+		return -1
+	}
+	at := slices.Index(order, current)
+	switch {
+	case at < 0 && reverse:
+		return order[len(order)-1]
+	case at < 0:
+		return order[0]
+	case reverse:
+		return order[(at+len(order)-1)%len(order)]
+	}
+	return order[(at+1)%len(order)]
+}
+
+// Nearest is the index of the target closest to the current one in the
+// direction, -1 if there is nothing in that direction. Targets that line up
+// with the current one are preferred over ones that are closer but off to
+// the side.
+func Nearest(targets []Target, current int, dir Direction) int {
+	if current < 0 || current >= len(targets) {
+		return -1
+	}
+	from := targets[current]
+	best, bestScore, bestOffset := -1, float32(0), float32(0)
+	for i, t := range targets {
+		if i == current || t.TabIndex < 0 {
+			continue
+		}
+		along, side, offset, ok := distance(from, t, dir)
+		if !ok {
+			continue
+		}
+		score := along + side*offAxisWeight
+		if best < 0 || score < bestScore || (score == bestScore && offset < bestOffset) {
+			best, bestScore, bestOffset = i, score, offset
+		}
+	}
+	return best
+}
+
+// distance is how far the target is from the current one in the direction
+// (along), how far off to the side of it the target is (side, 0 when they
+// overlap) and how far apart their centers are to the side (offset). Not ok
+// if the target isn't in that direction.
+func distance(from, to Target, dir Direction) (along, side, offset float32, ok bool) {
+	fc, tc := from.center(), to.center()
+	switch dir {
+	case DirectionUp:
+		ok = tc.Y() > fc.Y() && to.top() > from.top()
+		along = to.bottom() - from.top()
+	case DirectionDown:
+		ok = tc.Y() < fc.Y() && to.bottom() < from.bottom()
+		along = from.bottom() - to.top()
+	case DirectionLeft:
+		ok = tc.X() < fc.X() && to.left() < from.left()
+		along = from.left() - to.right()
+	case DirectionRight:
+		ok = tc.X() > fc.X() && to.right() > from.right()
+		along = to.left() - from.right()
+	}
+	along = max(along, 0)
+	if dir == DirectionUp || dir == DirectionDown {
+		side = gap(from.left(), from.right(), to.left(), to.right())
+		offset = matrix.Abs(tc.X() - fc.X())
+	} else {
+		side = gap(from.bottom(), from.top(), to.bottom(), to.top())
+		offset = matrix.Abs(tc.Y() - fc.Y())
+	}
+	return along, side, offset, ok
+}
+
+// gap is the space between the two ranges, 0 if they overlap
+func gap(aMin, aMax, bMin, bMax float32) float32 {
+	return max(bMin-aMax, aMin-bMax, 0)
+}
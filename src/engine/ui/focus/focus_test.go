@@ -0,0 +1,142 @@
+/******************************************************************************/
+/* focus_test.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package focus
+
+import (
+	"kaiju/matrix"
+	"slices"
+	"testing"
+)
+
+// box is a target at x, y (the bottom left) of the size
+func box(x, y, w, h float32, tabIndex int) Target {
+	return Target{Bounds: matrix.Vec4{x, y, x + w, y + h}, TabIndex: tabIndex}
+}
+
+func TestTabOrder(t *testing.T) {
+	targets := []Target{
+		box(0, 0, 10, 10, 0),
+		box(0, 0, 10, 10, 2),
+		box(0, 0, 10, 10, -1),
+		box(0, 0, 10, 10, 1),
+		box(0, 0, 10, 10, 0),
+		box(0, 0, 10, 10, 1),
+	}
+	expected := []int{3, 5, 1, 0, 4}
+	if got := TabOrder(targets); !slices.Equal(got, expected) {
+		t.Errorf("expected the tab order %v but got %v", expected, got)
+	}
+}
+
+func TestNext(t *testing.T) {
+	targets := []Target{
+		box(0, 0, 10, 10, 0),
+		box(0, 0, 10, 10, -1),
+		box(0, 0, 10, 10, 0),
+	}
+	tests := []struct {
+		current  int
+		reverse  bool
+		expected int
+	}{
+		{-1, false, 0},
+		{-1, true, 2},
+		{0, false, 2},
+		{2, false, 0},
+		{0, true, 2},
+		{1, false, 0},
+	}
+	for _, test := range tests {
+		if got := Next(targets, test.current, test.reverse); got != test.expected {
+			t.Errorf("from %d (reverse %t) expected %d but got %d",
+				test.current, test.reverse, test.expected, got)
+		}
+	}
+	if got := Next([]Target{box(0, 0, 1, 1, -1)}, -1, false); got != -1 {
+		t.Errorf("expected nothing to be in the tab order but got %d", got)
+	}
+}
+
+func TestNearest(t *testing.T) {
+	// A 3x3 grid of buttons, index 4 is the middle one, with a wide button
+	// under all of them that is off to the side of nothing
+	targets := make([]Target, 0, 10)
+	for row := range 3 {
+		for col := range 3 {
+			targets = append(targets,
+				box(float32(col)*20, float32(2-row)*20, 10, 10, 0))
+		}
+	}
+	targets = append(targets, box(0, -20, 50, 10, 0))
+	tests := []struct {
+		current  int
+		dir      Direction
+		expected int
+	}{
+		{4, DirectionUp, 1},
+		{4, DirectionDown, 7},
+		{4, DirectionLeft, 3},
+		{4, DirectionRight, 5},
+		{0, DirectionUp, -1},
+		{0, DirectionLeft, -1},
+		{8, DirectionDown, 9},
+		{9, DirectionUp, 7},
+		{9, DirectionRight, -1},
+	}
+	for _, test := range tests {
+		if got := Nearest(targets, test.current, test.dir); got != test.expected {
+			t.Errorf("from %d in direction %d expected %d but got %d",
+				test.current, test.dir, test.expected, got)
+		}
+	}
+}
+
+func TestNearestPrefersLinedUp(t *testing.T) {
+	targets := []Target{
+		box(0, 0, 10, 10, 0),
+		// Close but well off to the side
+		box(15, 30, 10, 10, 0),
+		// Further away but straight to the right
+		box(40, 2, 10, 10, 0),
+		// Straight to the right but can't be navigated to
+		box(20, 0, 10, 10, -1),
+	}
+	if got := Nearest(targets, 0, DirectionRight); got != 2 {
+		t.Errorf("expected the lined up target 2 but got %d", got)
+	}
+}
@@ -0,0 +1,273 @@
+/******************************************************************************/
+/* grid.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+// Package grid is the CSS grid layout algorithm. Like the #flex package it
+// works on plain sizes so it can be used and tested without any UI,
+// #ui.Panel uses it to place its children when it is a grid container.
+package grid
+
+type SizeKind int
+
+const (
+	SizeAuto = SizeKind(iota)
+	SizeLength
+	// SizePercent is a fraction (0-1) of the size of the container, when
+	// the container fits its content it is the same as #SizeAuto
+	SizePercent
+	SizeFraction
+	SizeMinContent
+	SizeMaxContent
+)
+
+type Size struct {
+	Kind  SizeKind
+	Value float32
+}
+
+// Track is the size of a row or column, a track that isn't a minmax() has
+// the same min and max, other than fractions which have a min of auto
+type Track struct {
+	Min, Max Size
+}
+
+func Length(px float32) Track {
+	return Track{Size{SizeLength, px}, Size{SizeLength, px}}
+}
+
+func Percent(fraction float32) Track {
+	return Track{Size{SizePercent, fraction}, Size{SizePercent, fraction}}
+}
+
+func Fraction(fr float32) Track {
+	return Track{Size{SizeAuto, 0}, Size{SizeFraction, fr}}
+}
+
+func Auto() Track { return Track{} }
+
+func MinMax(min, max Size) Track { return Track{min, max} }
+
+type Flow int
+
+const (
+	FlowRow = Flow(iota)
+	FlowColumn
+)
+
+// Align is how an item is placed within its grid area on one axis
+type Align int
+
+const (
+	// AlignAuto uses the justify-items or align-items of the container, on
+	// the container it is the same as #AlignStretch
+	AlignAuto = Align(iota)
+	AlignStart
+	AlignEnd
+	AlignCenter
+	AlignStretch
+)
+
+// Area is a named area of the grid, the lines start at 1 and the end lines
+// are the line after the last track of the area
+type Area struct {
+	RowStart, RowEnd       int
+	ColumnStart, ColumnEnd int
+}
+
+// Line is one side of where an item is placed. An index of 1 is the first
+// line, -1 is the last line of the explicit grid and 0 is automatic. Span is
+// used when there is no index, and Area is a named area whose matching side
+// is used.
+type Line struct {
+	Index int
+	Span  int
+	Area  string
+}
+
+type Placement struct {
+	RowStart, RowEnd       Line
+	ColumnStart, ColumnEnd Line
+}
+
+// AreaPlacement places an item in all of the named area
+func AreaPlacement(name string) Placement {
+	l := Line{Area: name}
+	return Placement{l, l, l, l}
+}
+
+type Container struct {
+	Columns, Rows            []Track
+	AutoColumns, AutoRows    Track
+	Areas                    map[string]Area
+	Flow                     Flow
+	Dense                    bool
+	RowGap, ColumnGap        float32
+	JustifyItems, AlignItems Align
+}
+
+// Item is a child of a grid container. The sizes are of the border box of
+// the item, margins are outside of them.
+type Item struct {
+	// Width and Height are the size the item would be without the grid
+	Width, Height float32
+	Placement     Placement
+	// Margin is the left, top, right and bottom margin
+	Margin                 [4]float32
+	JustifySelf, AlignSelf Align
+	// FixedWidth and FixedHeight are set when the item has an explicit size
+	// that stretching must not change
+	FixedWidth, FixedHeight bool
+}
+
+// Rect is where an item was placed, relative to the top left of the content
+// box of the container
+type Rect struct {
+	X, Y, Width, Height float32
+}
+
+// Result is the placement of each item, in the same order as the items that
+// were given, along with the sizes of the tracks and the size the grid takes
+// up so containers that fit their content can size themselves
+type Result struct {
+	Rects         []Rect
+	Columns, Rows []float32
+	ContentWidth  float32
+	ContentHeight float32
+}
+
+// Arrange places the items in a container with the content size. A width or
+// height less than 0 is indefinite, as it is for a container that fits its
+// content, the tracks are then sized to what the items need.
+func Arrange(c Container, width, height float32, items []Item) Result {
+	cells := place(c, items)
+	columns, rows := len(c.Columns), len(c.Rows)
+	for _, a := range c.Areas {
+		columns = max(columns, a.ColumnEnd-1)
+		rows = max(rows, a.RowEnd-1)
+	}
+	for i := range cells {
+		columns = max(columns, cells[i].column+cells[i].columnSpan)
+		rows = max(rows, cells[i].row+cells[i].rowSpan)
+	}
+	columnTracks := implicitTracks(c.Columns, c.AutoColumns, columns)
+	rowTracks := implicitTracks(c.Rows, c.AutoRows, rows)
+	columnSizes := make([]contribution, len(items))
+	rowSizes := make([]contribution, len(items))
+	for i := range items {
+		m := items[i].Margin
+		columnSizes[i] = contribution{cells[i].column, cells[i].columnSpan,
+			items[i].Width + m[0] + m[2]}
+		rowSizes[i] = contribution{cells[i].row, cells[i].rowSpan,
+			items[i].Height + m[1] + m[3]}
+	}
+	res := Result{
+		Rects:   make([]Rect, len(items)),
+		Columns: sizeTracks(columnTracks, width, c.ColumnGap, columnSizes),
+		Rows:    sizeTracks(rowTracks, height, c.RowGap, rowSizes),
+	}
+	columnStarts, contentWidth := trackStarts(res.Columns, c.ColumnGap)
+	rowStarts, contentHeight := trackStarts(res.Rows, c.RowGap)
+	res.ContentWidth, res.ContentHeight = contentWidth, contentHeight
+	for i := range items {
+		it := &items[i]
+		cell := cells[i]
+		x, w := alignInArea(resolveAlign(it.JustifySelf, c.JustifyItems),
+			columnStarts[cell.column],
+			spanSize(res.Columns, cell.column, cell.columnSpan, c.ColumnGap),
+			it.Width, it.Margin[0], it.Margin[2], it.FixedWidth)
+		y, h := alignInArea(resolveAlign(it.AlignSelf, c.AlignItems),
+			rowStarts[cell.row],
+			spanSize(res.Rows, cell.row, cell.rowSpan, c.RowGap),
+			it.Height, it.Margin[1], it.Margin[3], it.FixedHeight)
+		res.Rects[i] = Rect{x, y, w, h}
+	}
+	return res
+}
+
+func implicitTracks(explicit []Track, auto Track, count int) []Track {
+	tracks := make([]Track, count)
+	copy(tracks, explicit)
+	for i := len(explicit); i < count; i++ {
+		tracks[i] = auto
+	}
+	return tracks
+}
+
+func trackStarts(sizes []float32, gap float32) ([]float32, float32) {
+	starts := make([]float32, len(sizes))
+	pos := float32(0)
+	for i := range sizes {
+		if i > 0 {
+			pos += gap
+		}
+		starts[i] = pos
+		pos += sizes[i]
+	}
+	return starts, pos
+}
+
+func spanSize(sizes []float32, start, span int, gap float32) float32 {
+	size := gap * float32(span-1)
+	for i := start; i < start+span; i++ {
+		size += sizes[i]
+	}
+	return size
+}
+
+func resolveAlign(self, container Align) Align {
+	if self == AlignAuto {
+		self = container
+	}
+	if self == AlignAuto {
+		self = AlignStretch
+	}
+	return self
+}
+
+func alignInArea(align Align, start, areaSize, size, marginStart, marginEnd float32, fixed bool) (float32, float32) {
+	free := areaSize - size - marginStart - marginEnd
+	switch align {
+	case AlignEnd:
+		return start + marginStart + free, size
+	case AlignCenter:
+		return start + marginStart + free/2, size
+	case AlignStretch:
+		if !fixed {
+			return start + marginStart, max(0, areaSize-marginStart-marginEnd)
+		}
+	}
+	return start + marginStart, size
+}
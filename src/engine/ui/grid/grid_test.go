@@ -0,0 +1,281 @@
+/******************************************************************************/
+/* grid_test.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package grid
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func pixels(str string) float32 {
+	v, _ := strconv.ParseFloat(strings.TrimSuffix(str, "px"), 32)
+	return float32(v)
+}
+
+func item(width, height float32) Item {
+	return Item{Width: width, Height: height}
+}
+
+func approx(a, b float32) bool { return math.Abs(float64(a-b)) < 0.001 }
+
+func expectRects(t *testing.T, res Result, expected []Rect) {
+	t.Helper()
+	if len(res.Rects) != len(expected) {
+		t.Fatalf("expected %d rects but got %d", len(expected), len(res.Rects))
+	}
+	for i := range expected {
+		a, b := res.Rects[i], expected[i]
+		if !approx(a.X, b.X) || !approx(a.Y, b.Y) ||
+			!approx(a.Width, b.Width) || !approx(a.Height, b.Height) {
+			t.Errorf("rect %d expected %v but got %v", i, b, a)
+		}
+	}
+}
+
+func expectSizes(t *testing.T, sizes, expected []float32) {
+	t.Helper()
+	if len(sizes) != len(expected) {
+		t.Fatalf("expected %d tracks but got %d", len(expected), len(sizes))
+	}
+	for i := range expected {
+		if !approx(sizes[i], expected[i]) {
+			t.Errorf("track %d expected %v but got %v", i, expected[i], sizes[i])
+		}
+	}
+}
+
+func TestFractions(t *testing.T) {
+	c := Container{
+		Columns:   []Track{Length(100), Fraction(1), Fraction(2)},
+		ColumnGap: 10,
+	}
+	res := Arrange(c, 400, -1, []Item{item(10, 20), item(10, 20), item(10, 20)})
+	expectRects(t, res, []Rect{
+		{0, 0, 100, 20}, {110, 0, 280.0 / 3, 20}, {110 + 280.0/3 + 10, 0, 560.0 / 3, 20},
+	})
+	if !approx(res.ContentWidth, 400) || !approx(res.ContentHeight, 20) {
+		t.Errorf("expected content size 400x20 but got %vx%v",
+			res.ContentWidth, res.ContentHeight)
+	}
+}
+
+func TestAutoPlacementAddsRows(t *testing.T) {
+	tracks, err := ParseTracks([]string{"repeat(", "2", "50px", ")"}, pixels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Container{Columns: tracks, RowGap: 5,
+		JustifyItems: AlignStart, AlignItems: AlignStart}
+	res := Arrange(c, -1, -1, []Item{item(10, 10), item(10, 30), item(10, 5)})
+	expectRects(t, res, []Rect{{0, 0, 10, 10}, {50, 0, 10, 30}, {0, 35, 10, 5}})
+	expectSizes(t, res.Rows, []float32{30, 5})
+	if !approx(res.ContentWidth, 100) || !approx(res.ContentHeight, 40) {
+		t.Errorf("expected content size 100x40 but got %vx%v",
+			res.ContentWidth, res.ContentHeight)
+	}
+}
+
+func TestLinePlacementAndSpans(t *testing.T) {
+	c := Container{Columns: []Track{Length(10), Length(10), Length(10)}}
+	items := []Item{item(5, 10), item(5, 10), item(5, 10)}
+	items[0].Placement = Placement{
+		RowStart:    Line{Index: 1},
+		ColumnStart: Line{Index: 2},
+		ColumnEnd:   Line{Span: 2},
+	}
+	items[2].Placement = Placement{
+		ColumnStart: Line{Span: 3},
+		ColumnEnd:   Line{Index: -1},
+	}
+	res := Arrange(c, -1, -1, items)
+	expectRects(t, res, []Rect{{10, 0, 20, 10}, {0, 0, 10, 10}, {0, 10, 30, 10}})
+}
+
+func TestNamedAreas(t *testing.T) {
+	areas, rows, columns, err := ParseAreas([]string{`"head head"`, `"side main"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows != 2 || columns != 2 {
+		t.Fatalf("expected 2x2 areas but got %dx%d", rows, columns)
+	}
+	c := Container{
+		Columns: []Track{Length(50), Fraction(1)},
+		Rows:    []Track{Length(20), Fraction(1)},
+		Areas:   areas,
+	}
+	items := []Item{item(1, 1), item(1, 1), item(1, 1)}
+	items[0].Placement = AreaPlacement("main")
+	items[1].Placement = AreaPlacement("head")
+	items[2].Placement = AreaPlacement("side")
+	res := Arrange(c, 200, 100, items)
+	expectRects(t, res, []Rect{
+		{50, 20, 150, 80}, {0, 0, 200, 20}, {0, 20, 50, 80},
+	})
+}
+
+func TestAreasMustBeRectangles(t *testing.T) {
+	if _, _, _, err := ParseAreas([]string{`"a b"`, `"b a"`}); err == nil {
+		t.Error("expected an error for areas that aren't rectangles")
+	}
+	if _, _, _, err := ParseAreas([]string{`"a b"`, `"a"`}); err == nil {
+		t.Error("expected an error for rows of different lengths")
+	}
+}
+
+func TestMinMaxKeepsMinimum(t *testing.T) {
+	track := MinMax(Size{SizeLength, 100}, Size{SizeFraction, 1})
+	c := Container{Columns: []Track{track, track}}
+	res := Arrange(c, 150, -1, []Item{item(10, 10), item(10, 10)})
+	expectSizes(t, res.Columns, []float32{100, 100})
+	res = Arrange(c, 300, -1, []Item{item(10, 10), item(10, 10)})
+	expectSizes(t, res.Columns, []float32{150, 150})
+}
+
+func TestAutoTracksStretch(t *testing.T) {
+	c := Container{Columns: []Track{Auto(), Length(50)}}
+	res := Arrange(c, 200, -1, []Item{item(30, 10)})
+	expectSizes(t, res.Columns, []float32{150, 50})
+	res = Arrange(c, -1, -1, []Item{item(30, 10)})
+	expectSizes(t, res.Columns, []float32{30, 50})
+}
+
+func TestSpanningItemGrowsAutoTracks(t *testing.T) {
+	c := Container{Columns: []Track{Auto(), Auto()}, ColumnGap: 10}
+	items := []Item{item(20, 10), item(100, 10)}
+	items[1].Placement.ColumnStart = Line{Index: 1}
+	items[1].Placement.ColumnEnd = Line{Span: 2}
+	res := Arrange(c, -1, -1, items)
+	expectSizes(t, res.Columns, []float32{55, 35})
+}
+
+func TestPercentTracks(t *testing.T) {
+	c := Container{Columns: []Track{Percent(0.25), Percent(0.75)}}
+	res := Arrange(c, 200, -1, []Item{item(10, 10), item(10, 10)})
+	expectSizes(t, res.Columns, []float32{50, 150})
+	c = Container{Columns: []Track{Percent(0.5), Percent(0.5)}, ColumnGap: 20}
+	res = Arrange(c, 200, -1, []Item{item(10, 10), item(10, 10)})
+	expectSizes(t, res.Columns, []float32{100, 100})
+	c = Container{Columns: []Track{Percent(0.5), Fraction(1)}, ColumnGap: 20}
+	res = Arrange(c, 200, -1, []Item{item(10, 10), item(10, 10)})
+	expectSizes(t, res.Columns, []float32{100, 80})
+}
+
+func TestColumnFlow(t *testing.T) {
+	c := Container{
+		Rows:         []Track{Length(10), Length(10)},
+		Flow:         FlowColumn,
+		JustifyItems: AlignStart,
+		AlignItems:   AlignStart,
+	}
+	res := Arrange(c, -1, -1, []Item{item(5, 5), item(5, 5), item(5, 5)})
+	expectRects(t, res, []Rect{{0, 0, 5, 5}, {0, 10, 5, 5}, {5, 0, 5, 5}})
+}
+
+func TestDenseFillsHoles(t *testing.T) {
+	c := Container{Columns: []Track{Length(10), Length(10)}}
+	items := []Item{item(5, 5), item(5, 5), item(5, 5)}
+	items[0].Placement.ColumnStart = Line{Index: 2}
+	items[0].Placement.RowStart = Line{Index: 1}
+	items[1].Placement.ColumnEnd = Line{Span: 2}
+	res := Arrange(c, -1, -1, items)
+	if res.Rects[2].Y != 10 || res.Rects[2].X != 0 {
+		t.Errorf("expected sparse placement after the spanning item, got %v", res.Rects[2])
+	}
+	c.Dense = true
+	res = Arrange(c, -1, -1, items)
+	if res.Rects[2].Y != 0 || res.Rects[2].X != 0 {
+		t.Errorf("expected dense placement in the first hole, got %v", res.Rects[2])
+	}
+}
+
+func TestSelfAlignment(t *testing.T) {
+	c := Container{Columns: []Track{Length(100)}, Rows: []Track{Length(50)}}
+	items := []Item{item(20, 10), item(20, 10)}
+	items[0].JustifySelf = AlignCenter
+	items[0].AlignSelf = AlignEnd
+	items[1].FixedWidth = true
+	items[1].Margin = [4]float32{5, 5, 5, 5}
+	items[1].Placement = AreaPlacement("missing")
+	res := Arrange(c, -1, -1, items)
+	expectRects(t, res, []Rect{{40, 40, 20, 10}, {5, 55, 20, 10}})
+}
+
+func TestParseTracks(t *testing.T) {
+	tokens := []string{"[", "start", "]", "100px",
+		"repeat(", "2", "minmax(", "10px", "1fr", ")", "auto", ")", "20%"}
+	tracks, err := ParseTracks(tokens, pixels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mm := MinMax(Size{SizeLength, 10}, Size{SizeFraction, 1})
+	expected := []Track{Length(100), mm, Auto(), mm, Auto(), Percent(0.2)}
+	if len(tracks) != len(expected) {
+		t.Fatalf("expected %d tracks but got %d", len(expected), len(tracks))
+	}
+	for i := range expected {
+		if tracks[i] != expected[i] {
+			t.Errorf("track %d expected %v but got %v", i, expected[i], tracks[i])
+		}
+	}
+	for _, bad := range [][]string{{"repeat(", "auto-fill", "10px", ")"},
+		{"minmax(", "1fr", "10px", ")"}, {"wide"}} {
+		if _, err := ParseTracks(bad, pixels); err == nil {
+			t.Errorf("expected an error parsing %v", bad)
+		}
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	lines, err := ParseLines([]string{"1", "/", "span", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 || lines[0] != (Line{Index: 1}) || lines[1] != (Line{Span: 2}) {
+		t.Errorf("unexpected lines %v", lines)
+	}
+	lines, _ = ParseLines([]string{"head"})
+	if len(lines) != 1 || lines[0].Area != "head" {
+		t.Errorf("unexpected lines %v", lines)
+	}
+	if _, err := ParseLines([]string{"0"}); err == nil {
+		t.Error("expected an error for line 0")
+	}
+}
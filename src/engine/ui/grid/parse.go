@@ -0,0 +1,273 @@
+/******************************************************************************/
+/* parse.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package grid
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LengthFunc converts a length like "12px" or "2em" to pixels
+type LengthFunc func(str string) float32
+
+// tokenReader walks the tokens of a CSS value, functions are given as their
+// name with the opening parenthesis ("minmax(") and close with ")"
+type tokenReader struct {
+	tokens []string
+	pos    int
+}
+
+func (r *tokenReader) done() bool { return r.pos >= len(r.tokens) }
+func (r *tokenReader) peek() string {
+	if r.done() {
+		return ""
+	}
+	return r.tokens[r.pos]
+}
+func (r *tokenReader) next() string {
+	t := r.peek()
+	r.pos++
+	return t
+}
+
+func (r *tokenReader) expect(token string) error {
+	if t := r.next(); t != token {
+		return fmt.Errorf("expected '%s' but got '%s'", token, t)
+	}
+	return nil
+}
+
+// skipLineNames skips over [name] line names, they aren't used for placement
+func (r *tokenReader) skipLineNames() {
+	for r.peek() == "[" {
+		for !r.done() && r.next() != "]" {
+		}
+	}
+}
+
+func ParseSize(str string, length LengthFunc) (Size, error) {
+	switch str {
+	case "auto":
+		return Size{Kind: SizeAuto}, nil
+	case "min-content":
+		return Size{Kind: SizeMinContent}, nil
+	case "max-content":
+		return Size{Kind: SizeMaxContent}, nil
+	}
+	if v, ok := strings.CutSuffix(str, "fr"); ok {
+		fr, err := strconv.ParseFloat(v, 32)
+		if err != nil || fr < 0 {
+			return Size{}, fmt.Errorf("invalid fraction: %s", str)
+		}
+		return Size{SizeFraction, float32(fr)}, nil
+	}
+	if v, ok := strings.CutSuffix(str, "%"); ok {
+		p, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return Size{}, fmt.Errorf("invalid percentage: %s", str)
+		}
+		return Size{SizePercent, float32(p) / 100}, nil
+	}
+	if str == "0" {
+		return Size{Kind: SizeLength}, nil
+	}
+	if str == "" || (str[0] < '0' || str[0] > '9') && str[0] != '.' {
+		return Size{}, fmt.Errorf("invalid track size: %s", str)
+	}
+	return Size{SizeLength, length(str)}, nil
+}
+
+func (r *tokenReader) track(length LengthFunc) (Track, error) {
+	tok := r.next()
+	if tok == "minmax(" {
+		lo, err := ParseSize(r.next(), length)
+		if err != nil {
+			return Track{}, err
+		}
+		hi, err := ParseSize(r.next(), length)
+		if err != nil {
+			return Track{}, err
+		}
+		if lo.Kind == SizeFraction {
+			return Track{}, errors.New("minmax can't have a fraction as its minimum")
+		}
+		return MinMax(lo, hi), r.expect(")")
+	}
+	s, err := ParseSize(tok, length)
+	if err != nil {
+		return Track{}, err
+	}
+	if s.Kind == SizeFraction {
+		return Fraction(s.Value), nil
+	}
+	return Track{s, s}, nil
+}
+
+// ParseTracks reads a grid-template-rows or grid-template-columns track list
+// made up of lengths, percentages, fractions, auto, minmax() and repeat()
+func ParseTracks(tokens []string, length LengthFunc) ([]Track, error) {
+	r := tokenReader{tokens: tokens}
+	tracks := []Track{}
+	if len(tokens) == 1 && tokens[0] == "none" {
+		return tracks, nil
+	}
+	for r.skipLineNames(); !r.done(); r.skipLineNames() {
+		if r.peek() != "repeat(" {
+			t, err := r.track(length)
+			if err != nil {
+				return nil, err
+			}
+			tracks = append(tracks, t)
+			continue
+		}
+		r.next()
+		countStr := r.next()
+		count, err := strconv.Atoi(countStr)
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("repeat count not supported: %s", countStr)
+		}
+		repeated := []Track{}
+		for r.skipLineNames(); !r.done() && r.peek() != ")"; r.skipLineNames() {
+			t, err := r.track(length)
+			if err != nil {
+				return nil, err
+			}
+			repeated = append(repeated, t)
+		}
+		if err := r.expect(")"); err != nil {
+			return nil, err
+		}
+		if len(repeated) == 0 {
+			return nil, errors.New("repeat has no tracks")
+		}
+		for range count {
+			tracks = append(tracks, repeated...)
+		}
+	}
+	return tracks, nil
+}
+
+// ParseAreas reads the rows of grid-template-areas, each string is a row of
+// space separated names where "." is a cell without a name. It returns the
+// areas along with the number of rows and columns they make up.
+func ParseAreas(rows []string) (map[string]Area, int, int, error) {
+	areas := map[string]Area{}
+	columns := -1
+	for r, row := range rows {
+		names := strings.Fields(strings.Trim(row, "\"'"))
+		if columns >= 0 && len(names) != columns {
+			return nil, 0, 0, fmt.Errorf("area row %d has %d columns, expected %d", r+1, len(names), columns)
+		}
+		columns = len(names)
+		for c, name := range names {
+			if strings.Trim(name, ".") == "" {
+				continue
+			}
+			a, ok := areas[name]
+			if !ok {
+				areas[name] = Area{r + 1, r + 2, c + 1, c + 2}
+				continue
+			}
+			a.RowEnd = max(a.RowEnd, r+2)
+			a.ColumnEnd = max(a.ColumnEnd, c+2)
+			areas[name] = a
+		}
+	}
+	// Each area must be a filled rectangle
+	for name, a := range areas {
+		for r := a.RowStart; r < a.RowEnd; r++ {
+			names := strings.Fields(strings.Trim(rows[r-1], "\"'"))
+			for c := a.ColumnStart; c < a.ColumnEnd; c++ {
+				if names[c-1] != name {
+					return nil, 0, 0, fmt.Errorf("area %s isn't a rectangle", name)
+				}
+			}
+		}
+	}
+	return areas, len(rows), max(columns, 0), nil
+}
+
+// ParseLine reads one side of a placement: auto, a line number, span and a
+// count, or the name of an area
+func ParseLine(tokens []string) (Line, error) {
+	switch {
+	case len(tokens) == 0:
+		return Line{}, errors.New("expected a grid line")
+	case len(tokens) == 1 && tokens[0] == "auto":
+		return Line{}, nil
+	case tokens[0] == "span":
+		if len(tokens) != 2 {
+			return Line{}, errors.New("span expects a count")
+		}
+		span, err := strconv.Atoi(tokens[1])
+		if err != nil || span < 1 {
+			return Line{}, fmt.Errorf("invalid span: %s", tokens[1])
+		}
+		return Line{Span: span}, nil
+	case len(tokens) == 1:
+		if index, err := strconv.Atoi(tokens[0]); err == nil {
+			if index == 0 {
+				return Line{}, errors.New("grid line 0 is invalid")
+			}
+			return Line{Index: index}, nil
+		}
+		return Line{Area: tokens[0]}, nil
+	}
+	return Line{}, fmt.Errorf("invalid grid line: %s", strings.Join(tokens, " "))
+}
+
+// ParseLines reads placements separated by "/", as used by grid-row,
+// grid-column and grid-area
+func ParseLines(tokens []string) ([]Line, error) {
+	lines := []Line{}
+	start := 0
+	for i := 0; i <= len(tokens); i++ {
+		if i < len(tokens) && tokens[i] != "/" {
+			continue
+		}
+		l, err := ParseLine(tokens[start:i])
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+		start = i + 1
+	}
+	return lines, nil
+}
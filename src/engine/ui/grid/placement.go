@@ -0,0 +1,224 @@
+/******************************************************************************/
+/* placement.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package grid
+
+// cell is where an item was placed, the tracks start at 0
+type cell struct {
+	row, rowSpan       int
+	column, columnSpan int
+}
+
+// axisPlacement is the resolved start and span of an item on one axis, the
+// start is only meaningful when it is definite
+type axisPlacement struct {
+	start, span int
+	definite    bool
+}
+
+// resolveLines turns the start and end lines of an item on one axis into
+// tracks, count is the number of explicit tracks on the axis
+func resolveLines(start, end Line, count int, areas map[string]Area, isRow bool) axisPlacement {
+	lineIndex := func(l Line, isStart bool) (int, bool) {
+		if l.Area != "" {
+			a, ok := areas[l.Area]
+			if !ok {
+				return 0, false
+			}
+			switch {
+			case isRow && isStart:
+				return a.RowStart - 1, true
+			case isRow:
+				return a.RowEnd - 1, true
+			case isStart:
+				return a.ColumnStart - 1, true
+			default:
+				return a.ColumnEnd - 1, true
+			}
+		}
+		switch {
+		case l.Index > 0:
+			return l.Index - 1, true
+		case l.Index < 0:
+			return max(0, count+1+l.Index), true
+		}
+		return 0, false
+	}
+	s, hasStart := lineIndex(start, true)
+	e, hasEnd := lineIndex(end, false)
+	span := max(1, start.Span, end.Span)
+	switch {
+	case hasStart && hasEnd:
+		if e < s {
+			s, e = e, s
+		} else if e == s {
+			e = s + 1
+		}
+		return axisPlacement{s, e - s, true}
+	case hasStart:
+		return axisPlacement{s, max(1, end.Span), true}
+	case hasEnd:
+		return axisPlacement{max(0, e-max(1, start.Span)), min(e, max(1, start.Span)), true}
+	}
+	return axisPlacement{0, span, false}
+}
+
+type occupancy struct {
+	taken map[[2]int]bool
+}
+
+func (o *occupancy) fits(major, majorSpan, minor, minorSpan int) bool {
+	for i := major; i < major+majorSpan; i++ {
+		for j := minor; j < minor+minorSpan; j++ {
+			if o.taken[[2]int{i, j}] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (o *occupancy) take(major, majorSpan, minor, minorSpan int) {
+	for i := major; i < major+majorSpan; i++ {
+		for j := minor; j < minor+minorSpan; j++ {
+			o.taken[[2]int{i, j}] = true
+		}
+	}
+}
+
+// place resolves where every item goes, following the grid item placement
+// algorithm. The flow direction is the major axis, items that don't say where
+// they go are placed along the minor axis before moving to the next track.
+func place(c Container, items []Item) []cell {
+	isRowFlow := c.Flow == FlowRow
+	majors := make([]axisPlacement, len(items))
+	minors := make([]axisPlacement, len(items))
+	minorCount := len(c.Columns)
+	if !isRowFlow {
+		minorCount = len(c.Rows)
+	}
+	for i := range items {
+		p := items[i].Placement
+		rows := resolveLines(p.RowStart, p.RowEnd, len(c.Rows), c.Areas, true)
+		columns := resolveLines(p.ColumnStart, p.ColumnEnd, len(c.Columns), c.Areas, false)
+		if isRowFlow {
+			majors[i], minors[i] = rows, columns
+		} else {
+			majors[i], minors[i] = columns, rows
+		}
+		if minors[i].definite {
+			minorCount = max(minorCount, minors[i].start+minors[i].span)
+		} else {
+			minorCount = max(minorCount, minors[i].span)
+		}
+	}
+	o := occupancy{taken: map[[2]int]bool{}}
+	placed := make([]bool, len(items))
+	// Items with both sides definite go first
+	for i := range items {
+		if majors[i].definite && minors[i].definite {
+			o.take(majors[i].start, majors[i].span, minors[i].start, minors[i].span)
+			placed[i] = true
+		}
+	}
+	// Then items that are locked to a track of the major axis
+	lockedCursor := map[int]int{}
+	for i := range items {
+		if placed[i] || !majors[i].definite {
+			continue
+		}
+		m := &majors[i]
+		minor := 0
+		if !c.Dense {
+			minor = lockedCursor[m.start]
+		}
+		for !o.fits(m.start, m.span, minor, minors[i].span) {
+			minor++
+		}
+		minors[i].start = minor
+		minorCount = max(minorCount, minor+minors[i].span)
+		lockedCursor[m.start] = minor + minors[i].span
+		o.take(m.start, m.span, minor, minors[i].span)
+		placed[i] = true
+	}
+	// Lastly everything else in order, following the cursor
+	major, minor := 0, 0
+	for i := range items {
+		if placed[i] {
+			continue
+		}
+		if c.Dense {
+			major, minor = 0, 0
+		}
+		span := majors[i].span
+		if minors[i].definite {
+			if minors[i].start < minor {
+				major++
+			}
+			minor = minors[i].start
+			for !o.fits(major, span, minor, minors[i].span) {
+				major++
+			}
+		} else {
+			minorSpan := minors[i].span
+			for {
+				if minor+minorSpan > minorCount {
+					major++
+					minor = 0
+					continue
+				}
+				if o.fits(major, span, minor, minorSpan) {
+					break
+				}
+				minor++
+			}
+			minors[i].start = minor
+		}
+		majors[i].start = major
+		o.take(major, span, minors[i].start, minors[i].span)
+		minor = minors[i].start + minors[i].span
+	}
+	cells := make([]cell, len(items))
+	for i := range items {
+		if isRowFlow {
+			cells[i] = cell{majors[i].start, majors[i].span, minors[i].start, minors[i].span}
+		} else {
+			cells[i] = cell{minors[i].start, minors[i].span, majors[i].start, majors[i].span}
+		}
+	}
+	return cells
+}
@@ -0,0 +1,221 @@
+/******************************************************************************/
+/* tracks.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package grid
+
+import "slices"
+
+// contribution is the outer size an item needs across the tracks it spans
+type contribution struct {
+	start, span int
+	size        float32
+}
+
+func fixedSize(s Size, available float32) (float32, bool) {
+	switch s.Kind {
+	case SizeLength:
+		return s.Value, true
+	case SizePercent:
+		if available >= 0 {
+			return s.Value * available, true
+		}
+	}
+	return 0, false
+}
+
+func isFlexible(t Track) bool { return t.Max.Kind == SizeFraction }
+
+// sizeTracks is a simplified version of the CSS grid track sizing algorithm.
+// The tracks start at their fixed minimum and grow to fit the items in them,
+// fixed and content tracks then grow into the free space up to their max,
+// fractions share out what is left and, if there are no fractions, auto
+// tracks are stretched to fill the container.
+func sizeTracks(tracks []Track, available, gap float32, items []contribution) []float32 {
+	count := len(tracks)
+	base := make([]float32, count)
+	limit := make([]float32, count)
+	hasLimit := make([]bool, count)
+	// Percentages are of the whole content size, the gaps only take from the
+	// space that is shared out
+	content := available
+	if available >= 0 {
+		available = max(0, available-gap*float32(max(count-1, 0)))
+	}
+	for i, t := range tracks {
+		base[i], _ = fixedSize(t.Min, content)
+		limit[i], hasLimit[i] = fixedSize(t.Max, content)
+	}
+	contentMax := make([]float32, count)
+	sorted := slices.Clone(items)
+	slices.SortStableFunc(sorted, func(a, b contribution) int { return a.span - b.span })
+	for _, it := range sorted {
+		if it.span == 1 {
+			i := it.start
+			if _, fixed := fixedSize(tracks[i].Min, content); !fixed {
+				base[i] = max(base[i], it.size)
+			}
+			contentMax[i] = max(contentMax[i], it.size)
+			continue
+		}
+		// Items spanning several tracks share what they need over the tracks
+		// they span that size to their content, skipping any fractions
+		spanned := tracks[it.start : it.start+it.span]
+		if slices.ContainsFunc(spanned, isFlexible) {
+			continue
+		}
+		extra := it.size - gap*float32(it.span-1)
+		growable := 0
+		for i := it.start; i < it.start+it.span; i++ {
+			extra -= base[i]
+			if _, fixed := fixedSize(tracks[i].Min, content); !fixed {
+				growable++
+			}
+		}
+		if extra <= 0 || growable == 0 {
+			continue
+		}
+		share := extra / float32(growable)
+		for i := it.start; i < it.start+it.span; i++ {
+			if _, fixed := fixedSize(tracks[i].Min, content); !fixed {
+				base[i] += share
+				contentMax[i] = max(contentMax[i], base[i])
+			}
+		}
+	}
+	for i := range tracks {
+		if !hasLimit[i] {
+			limit[i] = contentMax[i]
+		}
+		limit[i] = max(limit[i], base[i])
+	}
+	sizes := slices.Clone(base)
+	if available < 0 {
+		for i := range tracks {
+			if !isFlexible(tracks[i]) {
+				sizes[i] = limit[i]
+			}
+		}
+		flexibleSizes(tracks, sizes, base, -1)
+		return sizes
+	}
+	free := available
+	for i := range sizes {
+		free -= sizes[i]
+	}
+	// Grow the tracks that aren't fractions up to their limits
+	for free > 0.001 {
+		growing := 0
+		for i := range tracks {
+			if !isFlexible(tracks[i]) && sizes[i] < limit[i] {
+				growing++
+			}
+		}
+		if growing == 0 {
+			break
+		}
+		share := free / float32(growing)
+		for i := range tracks {
+			if !isFlexible(tracks[i]) && sizes[i] < limit[i] {
+				grow := min(share, limit[i]-sizes[i])
+				sizes[i] += grow
+				free -= grow
+			}
+		}
+	}
+	if slices.ContainsFunc(tracks, isFlexible) {
+		flexibleSizes(tracks, sizes, base, available)
+	} else if free > 0 {
+		autos := 0
+		for i := range tracks {
+			if tracks[i].Max.Kind == SizeAuto {
+				autos++
+			}
+		}
+		for i := range tracks {
+			if tracks[i].Max.Kind == SizeAuto {
+				sizes[i] += free / float32(autos)
+			}
+		}
+	}
+	return sizes
+}
+
+// flexibleSizes sets the size of the fraction tracks. With a definite size
+// the space left over is shared out by the fractions, a fraction that would
+// be smaller than its base size keeps the base and is taken out of the share.
+// With an indefinite size each fraction is as big as it needs to be for the
+// biggest base size per fraction.
+func flexibleSizes(tracks []Track, sizes, base []float32, available float32) {
+	inflexible := make([]bool, len(tracks))
+	frSize := float32(0)
+	if available < 0 {
+		for i := range tracks {
+			if isFlexible(tracks[i]) {
+				frSize = max(frSize, base[i]/max(tracks[i].Max.Value, 1))
+			}
+		}
+	} else {
+		for {
+			leftover := available
+			factors := float32(0)
+			for i := range tracks {
+				if isFlexible(tracks[i]) && !inflexible[i] {
+					factors += tracks[i].Max.Value
+				} else {
+					leftover -= sizes[i]
+				}
+			}
+			frSize = max(0, leftover) / max(factors, 1)
+			changed := false
+			for i := range tracks {
+				if isFlexible(tracks[i]) && !inflexible[i] &&
+					frSize*tracks[i].Max.Value < base[i] {
+					inflexible[i] = true
+					changed = true
+				}
+			}
+			if !changed {
+				break
+			}
+		}
+	}
+	for i := range tracks {
+		if isFlexible(tracks[i]) && !inflexible[i] {
+			sizes[i] = max(base[i], frSize*tracks[i].Max.Value)
+		}
+	}
+}
@@ -39,6 +39,7 @@ package ui
 
 import (
 	"kaiju/engine"
+	"kaiju/platform/hid"
 	"log/slog"
 	"sort"
 	"sync"
@@ -55,11 +56,19 @@ const (
 type groupRequest struct {
 	target    *UI
 	eventType EventType
+	// key is the key of a key down or key up request
+	key hid.KeyboardKey
 }
 
 type Group struct {
 	requests    []groupRequest
 	focus       *UI
+	focused     *UI
+	focusables  []*UI
+	host        *engine.Host
+	navigation  focusNavigation
+	keyId       int
+	prevented   bool
 	updateId    int
 	lock        sync.Mutex
 	hadRequests requestState
@@ -78,6 +87,18 @@ func (group *Group) HasRequests() bool {
 }
 
 func (group *Group) requestEvent(ui *UI, eType EventType) {
+	group.addRequest(groupRequest{target: ui, eventType: eType})
+}
+
+// requestKeyEvent requests a key down or key up event, the key is given to
+// the target as its last key when the event is executed as many keys could
+// be requested in the same frame
+func (group *Group) requestKeyEvent(ui *UI, eType EventType, keyId hid.KeyboardKey) {
+	group.addRequest(groupRequest{target: ui, eventType: eType, key: keyId})
+}
+
+func (group *Group) addRequest(req groupRequest) {
+	ui, eType := req.target, req.eventType
 	if eType < EventTypeInvalid || eType >= EventTypeEnd {
 		slog.Error("Invalid UI event type")
 		return
@@ -95,10 +116,7 @@ func (group *Group) requestEvent(ui *UI, eType EventType) {
 	if group.isThreaded {
 		group.lock.Lock()
 	}
-	group.requests = append(group.requests, groupRequest{
-		target:    ui,
-		eventType: eType,
-	})
+	group.requests = append(group.requests, req)
 	if group.isThreaded {
 		group.lock.Unlock()
 	}
@@ -111,18 +129,24 @@ func (group *Group) setFocus(ui *UI) {
 	group.focus = ui
 	if group.focus != nil {
 		group.focus.ExecuteEvent(EventTypeClick)
+		group.Focus(ui)
 	}
 }
 
 func (group *Group) Attach(host *engine.Host) {
+	group.host = host
 	group.updateId = host.UILateUpdater.AddUpdate(func(dt float64) {
 		group.lateUpdate()
+		group.updateFocus(dt)
 	})
+	group.keyId = host.Window.Keyboard.AddKeyCallback(group.keyPressed)
 }
 
 func (group *Group) Detach(host *engine.Host) {
 	host.UILateUpdater.RemoveUpdate(group.updateId)
+	host.Window.Keyboard.RemoveKeyCallback(group.keyId)
 	group.updateId = -1
+	group.host = nil
 }
 
 func sortElements(a *UI, b *UI) bool { return a.IsInFrontOf(b) }
@@ -133,7 +157,9 @@ func sortRequests(a *groupRequest, b *groupRequest) bool {
 
 func (group *Group) lateUpdate() {
 	if len(group.requests) > 0 {
-		sort.Slice(group.requests, func(i, j int) bool {
+		// Stable so that the keys of the same element stay in the order that
+		// they were pressed
+		sort.SliceStable(group.requests, func(i, j int) bool {
 			return sortRequests(&group.requests[i], &group.requests[j])
 		})
 		requestSets := [EventTypeEnd][]groupRequest{}
@@ -158,8 +184,11 @@ func (group *Group) lateUpdate() {
 				req := &g[j]
 				if shouldContinue {
 					switch req.eventType {
-					case EventTypeMiss, EventTypeKeyDown, EventTypeKeyUp,
-						EventTypeChange, EventTypeSubmit:
+					case EventTypeKeyDown, EventTypeKeyUp:
+						req.target.lastKey = req.key
+						req.target.ExecuteEvent(req.eventType)
+					case EventTypeMiss, EventTypeChange, EventTypeSubmit,
+						EventTypeFocus, EventTypeBlur, EventTypeInput:
 						req.target.ExecuteEvent(req.eventType)
 					case EventTypeEnter, EventTypeMove, EventTypeDown, EventTypeUp,
 						EventTypeDropEnter, EventTypeDragStart:
@@ -0,0 +1,422 @@
+/******************************************************************************/
+/* group_focus.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package ui
+
+import (
+	"kaiju/engine/ui/focus"
+	"kaiju/matrix"
+	"kaiju/platform/hid"
+	"slices"
+)
+
+const (
+	// focusRepeatDelay is how long a direction is held before the focus
+	// starts to repeatedly move in that direction
+	focusRepeatDelay = 0.4
+	// focusRepeatRate is the time between each move while it repeats
+	focusRepeatRate = 0.1
+	// focusStickThreshold is how far a gamepad stick has to be pushed to
+	// move the focus
+	focusStickThreshold = 0.5
+	// sliderFocusStep is how much a focused slider moves with each press
+	sliderFocusStep = 0.05
+)
+
+// focusNavigation is the direction being held to move the focus and how
+// long until it moves again
+type focusNavigation struct {
+	direction focus.Direction
+	held      bool
+	// prevented is set when the key down of the held direction had its
+	// default prevented, it stays set until the direction is let go
+	prevented bool
+	wait      float64
+}
+
+// SetFocusable sets if the element can be reached with the keyboard and
+// gamepad. Buttons, checkboxes, sliders, selects, inputs and text areas are
+// focusable when they are created.
+func (ui *UI) SetFocusable(focusable bool) {
+	if ui.focusable == focusable {
+		return
+	}
+	ui.focusable = focusable
+	if ui.group == nil {
+		return
+	}
+	if focusable {
+		ui.group.addFocusable(ui)
+	} else {
+		ui.group.removeFocusable(ui)
+	}
+}
+
+func (ui *UI) IsFocusable() bool { return ui.focusable }
+
+// SetTabIndex makes the element focusable and sets where it is in the tab
+// order. Elements with a positive index are tabbed to first, lowest index
+// first, then the elements with an index of 0 in the order they were created.
+// A negative index can only be focused by #UI.Focus.
+func (ui *UI) SetTabIndex(index int) {
+	ui.tabIndex = index
+	ui.SetFocusable(true)
+}
+
+func (ui *UI) TabIndex() int { return ui.tabIndex }
+
+// HasFocus returns true if this is the element the keyboard and gamepad are
+// currently on
+func (ui *UI) HasFocus() bool { return ui.group != nil && ui.group.focused == ui }
+
+// Focus moves the keyboard and gamepad focus of the group to this element
+func (ui *UI) Focus() {
+	if ui.group != nil {
+		ui.group.Focus(ui)
+	}
+}
+
+// Blur removes the focus from the element if it has it
+func (ui *UI) Blur() {
+	if ui.HasFocus() {
+		ui.group.Focus(nil)
+	}
+}
+
+func (group *Group) addFocusable(ui *UI) {
+	if group.isThreaded {
+		group.lock.Lock()
+		defer group.lock.Unlock()
+	}
+	group.focusables = append(group.focusables, ui)
+}
+
+func (group *Group) removeFocusable(ui *UI) {
+	if group.isThreaded {
+		group.lock.Lock()
+		defer group.lock.Unlock()
+	}
+	if idx := slices.Index(group.focusables, ui); idx >= 0 {
+		group.focusables = slices.Delete(group.focusables, idx, idx+1)
+	}
+	if group.focused == ui {
+		group.focused = nil
+	}
+}
+
+// Focused is the element the keyboard and gamepad are on, nil if none
+func (group *Group) Focused() *UI { return group.focused }
+
+// Focus moves the keyboard and gamepad focus to the target, nil removes the
+// focus. Inputs and text areas are given the keyboard for typing when they
+// are focused. The element losing focus gets a #EventTypeBlur and the one
+// gaining it a #EventTypeFocus, which is what the :focus style follows.
+func (group *Group) Focus(target *UI) {
+	if group.focused == target {
+		return
+	}
+	last := group.focused
+	group.focused = target
+	if last != nil {
+		switch last.elmType {
+		case ElementTypeInput:
+			last.ToInput().RemoveFocus()
+		case ElementTypeTextArea:
+			last.ToTextArea().RemoveFocus()
+		case ElementTypeSelect:
+			if target != nil {
+				last.ToSelect().collapse()
+			}
+			last.requestEvent(EventTypeBlur)
+		default:
+			last.requestEvent(EventTypeBlur)
+		}
+	}
+	if target != nil {
+		switch target.elmType {
+		case ElementTypeInput:
+			target.ToInput().Focus()
+		case ElementTypeTextArea:
+			target.ToTextArea().Focus()
+		default:
+			target.requestEvent(EventTypeFocus)
+		}
+	}
+}
+
+// FocusNext moves the focus to the next element in the tab order, or the
+// previous one when reverse
+func (group *Group) FocusNext(reverse bool) {
+	targets, elms := group.focusTargets()
+	if next := focus.Next(targets, slices.Index(elms, group.focused), reverse); next >= 0 {
+		group.Focus(elms[next])
+	}
+}
+
+// FocusDirection moves the focus to the nearest element on the screen in the
+// direction. If nothing is focused, the first element in the tab order is.
+func (group *Group) FocusDirection(dir focus.Direction) {
+	targets, elms := group.focusTargets()
+	current := slices.Index(elms, group.focused)
+	if current < 0 {
+		group.FocusNext(false)
+	} else if next := focus.Nearest(targets, current, dir); next >= 0 {
+		group.Focus(elms[next])
+	}
+}
+
+// Activate presses the focused element as if it were clicked
+func (group *Group) Activate() {
+	target := group.focused
+	if target == nil || !target.isActive() {
+		return
+	}
+	switch target.elmType {
+	case ElementTypeInput:
+		target.ToInput().Focus()
+	case ElementTypeTextArea:
+		target.ToTextArea().Focus()
+	default:
+		target.ExecuteEvent(EventTypeClick)
+	}
+}
+
+// focusTargets are the active focusable elements along with where they are
+// on the screen
+func (group *Group) focusTargets() ([]focus.Target, []*UI) {
+	targets := make([]focus.Target, 0, len(group.focusables))
+	elms := make([]*UI, 0, len(group.focusables))
+	for _, ui := range group.focusables {
+		if !ui.isActive() {
+			continue
+		}
+		t := &ui.entity.Transform
+		pos, size := t.WorldPosition(), t.WorldScale()
+		targets = append(targets, focus.Target{
+			Bounds: matrix.Vec4{
+				pos.X() - size.X()*0.5, pos.Y() - size.Y()*0.5,
+				pos.X() + size.X()*0.5, pos.Y() + size.Y()*0.5,
+			},
+			TabIndex: ui.tabIndex,
+		})
+		elms = append(elms, ui)
+	}
+	return targets, elms
+}
+
+// adjustFocused lets the focused element use the direction, such as a slider
+// moving its value, before it is used to move the focus
+func (group *Group) adjustFocused(dir focus.Direction) bool {
+	target := group.focused
+	switch target.elmType {
+	case ElementTypeSlider:
+		slider := target.ToSlider()
+		switch dir {
+		case focus.DirectionLeft:
+			slider.SetValue(slider.Value() - sliderFocusStep)
+			return true
+		case focus.DirectionRight:
+			slider.SetValue(slider.Value() + sliderFocusStep)
+			return true
+		}
+	case ElementTypeSelect:
+		sel := target.ToSelect()
+		data := sel.SelectData()
+		if !data.isOpen || len(data.options) == 0 {
+			return false
+		}
+		switch dir {
+		case focus.DirectionUp:
+			sel.PickOption(max(data.selected-1, 0))
+		case focus.DirectionDown:
+			sel.PickOption(min(data.selected+1, len(data.options)-1))
+		default:
+			return false
+		}
+		sel.expand()
+		return true
+	}
+	return false
+}
+
+// tabMoves returns true if tab should move the focus rather than being used
+// by the element being typed in, an input only keeps tab when it has its own
+// next input to go to
+func (group *Group) tabMoves() bool {
+	switch {
+	case group.focus == nil:
+		return true
+	case group.focus.IsType(ElementTypeInput):
+		return group.focus.ToInput().InputData().nextFocusInput == nil
+	}
+	return false
+}
+
+// keyPressed sends the keys to the focused element as key down and up
+// events, the elements that are typed in send their own
+func (group *Group) keyPressed(keyId int, keyState hid.KeyState) {
+	target := group.focused
+	if target == nil || group.focus != nil || !target.isActive() {
+		return
+	}
+	switch keyState {
+	case hid.KeyStateDown:
+		target.requestKeyEvent(EventTypeKeyDown, keyId)
+	case hid.KeyStateUp:
+		target.requestKeyEvent(EventTypeKeyUp, keyId)
+	}
+}
+
+// updateFocus moves and activates the focus from the keyboard and gamepads.
+// Tab and the bumpers start the focus, the arrow keys, d-pad and left stick
+// only move it once something is focused. While an input or text area is
+// being typed in the keyboard belongs to it.
+func (group *Group) updateFocus(deltaTime float64) {
+	if group.host == nil {
+		return
+	}
+	window := group.host.Window
+	if window.Cursor.Pressed() && group.focused != nil && !group.focused.hovering {
+		group.Focus(nil)
+	}
+	// A key handler that prevented the default keeps the keys of this frame
+	// from moving or activating the focus
+	if group.prevented {
+		group.prevented = false
+		group.navigation.prevented = true
+		return
+	}
+	kb := &window.Keyboard
+	typing := group.focus != nil
+	if kb.KeyDown(hid.KeyboardKeyTab) && group.tabMoves() {
+		group.FocusNext(kb.HasShift())
+	}
+	if !typing {
+		if kb.KeyDown(hid.KeyboardKeyReturn) || kb.KeyDown(hid.KeyboardKeyEnter) ||
+			kb.KeyDown(hid.KeyboardKeySpace) {
+			group.Activate()
+		}
+		if kb.KeyDown(hid.KeyboardKeyEscape) {
+			group.Focus(nil)
+		}
+	}
+	pad := &window.Controller
+	for id := range hid.ControllerMaxDevices {
+		if !pad.Available(id) {
+			continue
+		}
+		switch {
+		case pad.IsButtonDown(id, hid.ControllerButtonA):
+			group.Activate()
+		case pad.IsButtonDown(id, hid.ControllerButtonB):
+			group.Focus(nil)
+		case pad.IsButtonDown(id, hid.ControllerButtonRightBumper):
+			group.FocusNext(false)
+		case pad.IsButtonDown(id, hid.ControllerButtonLeftBumper):
+			group.FocusNext(true)
+		}
+	}
+	dir, held := group.heldDirection(!typing)
+	group.navigate(dir, held, deltaTime)
+}
+
+// heldDirection is the direction the arrow keys, d-pad or left stick are
+// held in. The arrow keys are skipped while typing as they move the cursor.
+func (group *Group) heldDirection(arrows bool) (focus.Direction, bool) {
+	window := group.host.Window
+	kb := &window.Keyboard
+	if arrows {
+		switch {
+		case kb.KeyHeld(hid.KeyboardKeyUp):
+			return focus.DirectionUp, true
+		case kb.KeyHeld(hid.KeyboardKeyDown):
+			return focus.DirectionDown, true
+		case kb.KeyHeld(hid.KeyboardKeyLeft):
+			return focus.DirectionLeft, true
+		case kb.KeyHeld(hid.KeyboardKeyRight):
+			return focus.DirectionRight, true
+		}
+	}
+	pad := &window.Controller
+	for id := range hid.ControllerMaxDevices {
+		if !pad.Available(id) {
+			continue
+		}
+		held := func(button int) bool {
+			return pad.IsButtonDown(id, button) || pad.IsButtonHeld(id, button)
+		}
+		// The sticks follow the standard gamepad layout where up is negative
+		x := pad.Axis(id, hid.ControllerAxisLeftHorizontal)
+		y := pad.Axis(id, hid.ControllerAxisLeftVertical)
+		switch {
+		case held(hid.ControllerButtonUp) || y < -focusStickThreshold:
+			return focus.DirectionUp, true
+		case held(hid.ControllerButtonDown) || y > focusStickThreshold:
+			return focus.DirectionDown, true
+		case held(hid.ControllerButtonLeft) || x < -focusStickThreshold:
+			return focus.DirectionLeft, true
+		case held(hid.ControllerButtonRight) || x > focusStickThreshold:
+			return focus.DirectionRight, true
+		}
+	}
+	return focus.DirectionUp, false
+}
+
+// navigate moves the focus when a direction is first held and then
+// repeatedly while it stays held
+func (group *Group) navigate(dir focus.Direction, held bool, deltaTime float64) {
+	nav := &group.navigation
+	if !held || group.focused == nil {
+		nav.held, nav.prevented = false, false
+		return
+	}
+	if nav.prevented {
+		return
+	}
+	if nav.held && nav.direction == dir {
+		nav.wait -= deltaTime
+		if nav.wait > 0 {
+			return
+		}
+		nav.wait += focusRepeatRate
+	} else {
+		nav.direction, nav.held, nav.wait = dir, true, focusRepeatDelay
+	}
+	if !group.adjustFocused(dir) {
+		group.FocusDirection(dir)
+	}
+}
@@ -110,6 +110,7 @@ func (input *Input) Init(placeholderText string, anchor Anchor) {
 	host := p.man.Host
 	tex, _ := host.TextureCache().Texture(assets.TextureSquare, rendering.TextureFilterLinear)
 	p.Init(tex, anchor, ElementTypeInput)
+	input.Base().SetFocusable(true)
 	p.DontFitContent()
 
 	// Label
@@ -306,6 +307,7 @@ func (input *Input) setText(text string, skipEvent bool) {
 	// TODO:  The global set text sets the cursor position after this call,
 	// something to consider with order of operations
 	if !skipEvent {
+		(*UI)(input).ExecuteEvent(EventTypeInput)
 		(*UI)(input).ExecuteEvent(EventTypeChange)
 	}
 	input.hideHighlight()
@@ -627,6 +629,7 @@ func (input *Input) Focus() {
 		if input.group != nil {
 			input.group.setFocus((*UI)(input))
 		}
+		(*UI)(input).requestEvent(EventTypeFocus)
 	}
 }
 
@@ -639,6 +642,7 @@ func (input *Input) RemoveFocus() {
 		if input.group != nil {
 			input.group.setFocus(nil)
 		}
+		(*UI)(input).requestEvent(EventTypeBlur)
 	}
 }
 
@@ -705,9 +709,9 @@ func (input *Input) keyPressed(keyId int, keyState hid.KeyState) {
 					})
 				}
 			}
-			(*UI)(input).requestEvent(EventTypeKeyDown)
+			(*UI)(input).requestKeyEvent(EventTypeKeyDown, keyId)
 		} else if keyState == hid.KeyStateUp {
-			(*UI)(input).requestEvent(EventTypeKeyUp)
+			(*UI)(input).requestKeyEvent(EventTypeKeyUp, keyId)
 		}
 	}
 }
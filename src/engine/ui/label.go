@@ -38,6 +38,7 @@
 package ui
 
 import (
+	"kaiju/engine/ui/richtext"
 	"kaiju/matrix"
 	"kaiju/platform/profiler/tracing"
 	"kaiju/rendering"
@@ -54,27 +55,32 @@ type colorRange struct {
 }
 
 type labelData struct {
-	colorRanges       []colorRange
-	text              string
-	textLength        int
-	fontSize          float32
-	lineHeight        float32
-	overrideMaxWidth  float32
-	fgColor           matrix.Color
-	bgColor           matrix.Color
-	justify           rendering.FontJustify
-	baseline          rendering.FontBaseline
-	diffScore         int
-	runeShaderData    []*rendering.TextShaderData
-	runeDrawings      []rendering.Drawing
-	fontFace          rendering.FontFace
-	lastRenderWidth   float32
-	unEnforcedFGColor matrix.Color
-	unEnforcedBGColor matrix.Color
-	isForcedFGColor   bool
-	isForcedBGColor   bool
-	wordWrap          bool
-	renderRequired    bool
+	colorRanges        []colorRange
+	text               string
+	textLength         int
+	fontSize           float32
+	lineHeight         float32
+	overrideMaxWidth   float32
+	fgColor            matrix.Color
+	bgColor            matrix.Color
+	justify            rendering.FontJustify
+	baseline           rendering.FontBaseline
+	diffScore          int
+	runeShaderData     []*rendering.TextShaderData
+	runeDrawings       []rendering.Drawing
+	fontFace           rendering.FontFace
+	lastRenderWidth    float32
+	unEnforcedFGColor  matrix.Color
+	unEnforcedBGColor  matrix.Color
+	isForcedFGColor    bool
+	isForcedBGColor    bool
+	wordWrap           bool
+	renderRequired     bool
+	spans              []richtext.Span
+	runeSpans          []int
+	decorations        []*ShaderData
+	decorationSpans    []int
+	decorationDrawings []rendering.Drawing
 }
 
 func (l *labelData) innerPanelData() *panelData { panic("label isn't a panel") }
@@ -143,6 +149,9 @@ func (label *Label) activateDrawings() {
 	for i := range ld.runeDrawings {
 		ld.runeDrawings[i].ShaderData.Activate()
 	}
+	for i := range ld.decorations {
+		ld.decorations[i].Activate()
+	}
 }
 
 func (label *Label) deactivateDrawings() {
@@ -150,6 +159,9 @@ func (label *Label) deactivateDrawings() {
 	for i := range ld.runeDrawings {
 		ld.runeDrawings[i].ShaderData.Deactivate()
 	}
+	for i := range ld.decorations {
+		ld.decorations[i].Deactivate()
+	}
 }
 
 func (label *Label) FontFace() rendering.FontFace { return label.LabelData().fontFace }
@@ -168,8 +180,15 @@ func (label *Label) clearDrawings() {
 	for i := range ld.runeShaderData {
 		ld.runeShaderData[i].Destroy()
 	}
+	for i := range ld.decorations {
+		ld.decorations[i].Destroy()
+	}
 	ld.runeShaderData = ld.runeShaderData[:0]
 	ld.runeDrawings = ld.runeDrawings[:0]
+	ld.runeSpans = ld.runeSpans[:0]
+	ld.decorations = ld.decorations[:0]
+	ld.decorationSpans = ld.decorationSpans[:0]
+	ld.decorationDrawings = ld.decorationDrawings[:0]
 }
 
 func (label *Label) labelPostLayoutUpdate() {
@@ -197,6 +216,9 @@ func (label *Label) updateHeight(maxWidth float32) {
 
 func (label *Label) measure(maxWidth float32) matrix.Vec2 {
 	ld := label.LabelData()
+	if len(ld.spans) > 0 {
+		return label.measureSpans(maxWidth)
+	}
 	return label.man.Host.FontCache().MeasureStringWithin(ld.fontFace,
 		ld.text, ld.fontSize, maxWidth, ld.lineHeight)
 }
@@ -218,7 +240,9 @@ func (label *Label) renderText() {
 	}
 	label.clearDrawings()
 	label.entity.Transform.SetDirty()
-	if ld.textLength > 0 {
+	if len(ld.spans) > 0 {
+		label.renderSpans(maxWidth)
+	} else if ld.textLength > 0 {
 		ld.runeDrawings = label.man.Host.FontCache().RenderMeshes(
 			label.man.Host, ld.text, 0, 0, 0, ld.fontSize,
 			maxWidth, ld.fgColor, ld.bgColor, ld.justify,
@@ -270,9 +294,12 @@ func (label *Label) labelRender() {
 func (label *Label) updateColors() {
 	ld := label.LabelData()
 	for i := range ld.runeShaderData {
-		ld.runeShaderData[i].FgColor = ld.fgColor
+		ld.runeShaderData[i].FgColor = label.runeColor(i)
 		ld.runeShaderData[i].BgColor = ld.bgColor
 	}
+	for i := range ld.decorations {
+		ld.decorations[i].FgColor = label.spanColor(ld.decorationSpans[i])
+	}
 }
 
 func (label *Label) FontSize() float32 { return label.LabelData().fontSize }
@@ -299,6 +326,7 @@ func (label *Label) SetText(text string) {
 	ld.textLength = utf8.RuneCountInString(ld.text)
 	label.Base().SetDirty(DirtyTypeGenerated)
 	ld.colorRanges = ld.colorRanges[:0]
+	ld.spans = nil
 }
 
 func (label *Label) setLabelScissors() {
@@ -311,6 +339,9 @@ func (label *Label) setLabelScissors() {
 	for i := 0; i < len(ld.runeDrawings); i++ {
 		ld.runeDrawings[i].ShaderData.(*rendering.TextShaderData).Scissor = s
 	}
+	for i := range ld.decorations {
+		ld.decorations[i].Scissor = s
+	}
 }
 
 func (label *Label) SetColor(newColor matrix.Color) {
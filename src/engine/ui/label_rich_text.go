@@ -0,0 +1,284 @@
+/******************************************************************************/
+/* label_rich_text.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package ui
+
+import (
+	"kaiju/engine/assets"
+	"kaiju/engine/ui/richtext"
+	"kaiju/matrix"
+	"kaiju/rendering"
+	"log/slog"
+	"slices"
+)
+
+// strikethroughHeight is how far above the baseline the strikethrough line
+// is, as a fraction of the ascender of the font
+const strikethroughHeight = 0.3
+
+// labelMeasurer sizes the runes of the spans of a label in the font face
+// and size of their style
+type labelMeasurer struct{ label *Label }
+
+func (m labelMeasurer) Advance(r rune, style richtext.Style) float32 {
+	face, size := m.label.spanFont(style)
+	return m.label.man.Host.FontCache().MeasureString(face, string(r), size)
+}
+
+func (m labelMeasurer) LineMetrics(style richtext.Style) (height, ascent float32) {
+	face, size := m.label.spanFont(style)
+	fm := m.label.man.Host.FontCache().Metrics(face, size)
+	height, ascent = fm.LineHeight, fm.LineHeight+fm.Descender
+	// A line height set on the label centers the text in the taller line
+	if lh := m.label.LabelData().lineHeight; lh > height {
+		ascent += (lh - height) * 0.5
+		height = lh
+	}
+	return height, ascent
+}
+
+// SetRichText sets the text of the label from the lightweight rich text
+// markup, like "<b>bold</b> and <color=#f00>red</color>" (see
+// #richtext.Parse for all of the tags)
+func (label *Label) SetRichText(markup string) {
+	label.SetSpans(richtext.Parse(markup))
+}
+
+// SetSpans sets the text of the label as spans that each have their own
+// style. The parts of the style a span leaves empty come from the label, so
+// the color, font size and weight of the label still apply to them.
+func (label *Label) SetSpans(spans []richtext.Span) {
+	label.SetText(richtext.Plain(spans))
+	label.LabelData().spans = slices.Clone(spans)
+}
+
+// Spans are the spans of the text, nil if the text is plain
+func (label *Label) Spans() []richtext.Span { return label.LabelData().spans }
+
+func (label *Label) HasSpans() bool { return len(label.LabelData().spans) > 0 }
+
+// MeasureWithin is the size of the text of the label when it is wrapped to
+// the given width, the spans of the label are measured in their own fonts
+func (label *Label) MeasureWithin(maxWidth float32) matrix.Vec2 {
+	return label.measure(maxWidth)
+}
+
+// spanFont is the font face and size the style is drawn in
+func (label *Label) spanFont(style richtext.Style) (rendering.FontFace, float32) {
+	ld := label.LabelData()
+	size := ld.fontSize
+	if style.Size > 0 {
+		size = style.Size
+	}
+	weight := style.Weight
+	if weight == "" {
+		weight = fontFaceWeight(ld.fontFace)
+	}
+	return fontFaceFor(weight, style.Italic || ld.fontFace.IsItalic()), size
+}
+
+func fontFaceWeight(face rendering.FontFace) string {
+	switch {
+	case face.IsExtraBold():
+		return "bolder"
+	case face.IsBold():
+		return "bold"
+	case face.IsLight():
+		return "lighter"
+	}
+	return "normal"
+}
+
+func fontFaceFor(weight string, italic bool) rendering.FontFace {
+	switch weight {
+	case "bold":
+		return pickFace(italic, rendering.FontBoldItalic, rendering.FontBold)
+	case "bolder":
+		return pickFace(italic, rendering.FontExtraBoldItalic, rendering.FontExtraBold)
+	case "lighter":
+		return pickFace(italic, rendering.FontLightItalic, rendering.FontLight)
+	}
+	return pickFace(italic, rendering.FontItalic, rendering.FontRegular)
+}
+
+func pickFace(italic bool, italicFace, face rendering.FontFace) rendering.FontFace {
+	if italic {
+		return italicFace
+	}
+	return face
+}
+
+func (label *Label) measureSpans(maxWidth float32) matrix.Vec2 {
+	ld := label.LabelData()
+	if maxWidth >= matrix.FloatMax {
+		maxWidth = 0
+	}
+	size := matrix.Vec2Zero()
+	for _, line := range richtext.Layout(ld.spans, maxWidth, labelMeasurer{label}) {
+		size.SetX(max(size.X(), line.Width))
+		size.SetY(line.Y + line.Height)
+	}
+	return size
+}
+
+// renderSpans draws each run of the spans on its own so that it can have its
+// own font, the runs are lined up on the baseline of their line
+func (label *Label) renderSpans(maxWidth float32) {
+	ld := label.LabelData()
+	host := label.man.Host
+	ws := label.entity.Transform.WorldScale()
+	wrapWidth := maxWidth
+	if wrapWidth >= matrix.FloatMax {
+		wrapWidth = 0
+	}
+	measurer := labelMeasurer{label}
+	lines := richtext.Layout(ld.spans, wrapWidth, measurer)
+	total := float32(0)
+	if len(lines) > 0 {
+		last := lines[len(lines)-1]
+		total = last.Y + last.Height
+	}
+	top := float32(0)
+	switch ld.baseline {
+	case rendering.FontBaselineCenter:
+		top = (ws.Y() - total) * 0.5
+	case rendering.FontBaselineBottom:
+		top = ws.Y() - total
+	}
+	width := min(maxWidth, ws.X())
+	texts := make([][]rune, len(ld.spans))
+	for i := range ld.spans {
+		texts[i] = []rune(ld.spans[i].Text)
+	}
+	for _, line := range lines {
+		left := float32(0)
+		switch ld.justify {
+		case rendering.FontJustifyCenter:
+			left = (width - line.Width) * 0.5
+		case rendering.FontJustifyRight:
+			left = width - line.Width
+		}
+		baseline := top + line.Y + line.Ascent
+		for _, run := range line.Runs {
+			style := ld.spans[run.Span].Style
+			face, size := label.spanFont(style)
+			_, ascent := measurer.LineMetrics(style)
+			x, y := left+run.X, baseline-ascent
+			drawings := host.FontCache().RenderMeshes(host,
+				string(texts[run.Span][run.Start:run.End]), x/ws.X(), -y/ws.Y(), 0,
+				size, 0, ld.fgColor, ld.bgColor, rendering.FontJustifyLeft,
+				rendering.FontBaselineTop, ws, true, false, face, 0)
+			for i := range drawings {
+				drawings[i].Transform = &label.entity.Transform
+				ld.runeShaderData = append(ld.runeShaderData,
+					drawings[i].ShaderData.(*rendering.TextShaderData))
+				ld.runeSpans = append(ld.runeSpans, run.Span)
+			}
+			ld.runeDrawings = append(ld.runeDrawings, drawings...)
+			metrics := host.FontCache().Metrics(face, size)
+			thickness := max(metrics.UnderlineThickness, 1)
+			if style.Underline {
+				label.addDecoration(run, x, baseline-metrics.UnderlineY, thickness)
+			}
+			if style.Strikethrough {
+				label.addDecoration(run, x,
+					baseline-metrics.Ascender*strikethroughHeight, thickness)
+			}
+		}
+	}
+	host.Drawings.AddDrawings(ld.runeDrawings)
+	host.Drawings.AddDrawings(ld.decorationDrawings)
+}
+
+// addDecoration adds a line under or through the run, y is how far down the
+// label the center of the line is
+func (label *Label) addDecoration(run richtext.Run, x, y, thickness float32) {
+	ld := label.LabelData()
+	host := label.man.Host
+	tex, err := host.TextureCache().Texture(assets.TextureSquare, rendering.TextureFilterLinear)
+	if err != nil {
+		slog.Error("failed to load the texture for the text decoration", "error", err)
+		return
+	}
+	material, err := host.MaterialCache().Material(assets.MaterialDefinitionUI)
+	if err != nil {
+		slog.Error("failed to load the ui material for the text decoration", "error", err)
+		return
+	}
+	ws := label.entity.Transform.WorldScale()
+	sd := &ShaderData{
+		ShaderDataBase: rendering.NewShaderDataBase(),
+		FgColor:        label.spanColor(run.Span),
+		UVs:            matrix.Vec4{0, 0, 1, 1},
+		BorderLen:      matrix.Vec2{8, 8},
+		Size2D:         matrix.Vec4{run.Width, thickness, float32(tex.Width), float32(tex.Height)},
+		Scissor:        matrix.Vec4{-matrix.FloatMax, -matrix.FloatMax, matrix.FloatMax, matrix.FloatMax},
+	}
+	model := matrix.Mat4Identity()
+	model.Scale(matrix.Vec3{run.Width / ws.X(), thickness / ws.Y(), 1})
+	model.Translate(matrix.Vec3{
+		(x+run.Width*0.5)/ws.X() - 0.5, 0.5 - y/ws.Y(), 0})
+	sd.SetModel(model)
+	ld.decorations = append(ld.decorations, sd)
+	ld.decorationSpans = append(ld.decorationSpans, run.Span)
+	ld.decorationDrawings = append(ld.decorationDrawings, rendering.Drawing{
+		Renderer:   host.Window.Renderer,
+		Material:   material.CreateInstance([]*rendering.Texture{tex}),
+		Mesh:       rendering.NewMeshQuad(host.MeshCache()),
+		ShaderData: sd,
+		Transform:  &label.entity.Transform,
+	})
+}
+
+// spanColor is the color of the span, the label color unless the span sets
+// its own
+func (label *Label) spanColor(span int) matrix.Color {
+	ld := label.LabelData()
+	if span < len(ld.spans) && ld.spans[span].Style.HasColor {
+		return ld.spans[span].Style.Color
+	}
+	return ld.fgColor
+}
+
+// runeColor is the color of the drawing of the rune at the index
+func (label *Label) runeColor(index int) matrix.Color {
+	ld := label.LabelData()
+	if index < len(ld.runeSpans) {
+		return label.spanColor(ld.runeSpans[index])
+	}
+	return ld.fgColor
+}
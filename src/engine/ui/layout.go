@@ -38,6 +38,7 @@
 package ui
 
 import (
+	"kaiju/engine/ui/flex"
 	"kaiju/matrix"
 	"log/slog"
 )
@@ -196,6 +197,7 @@ type Layout struct {
 	positioning      Positioning
 	functions        LayoutFunctions
 	runningFuncs     bool
+	item             layoutItem
 }
 
 func (l *Layout) AddFunction(fn func(layout *Layout)) LayoutFuncId {
@@ -401,6 +403,7 @@ func (l *Layout) bounds() matrix.Vec2 {
 func (l *Layout) initialize(ui *UI, anchor Anchor) {
 	l.anchor = matrix.Vec2Zero()
 	l.ui = ui
+	l.item.flex = flex.DefaultItem()
 	l.AnchorTo(anchor)
 	//l.prepare()
 	//l.update()
@@ -476,6 +479,19 @@ func (l *Layout) InnerOffset() matrix.Vec4 {
 	}
 }
 
+// anchoredInnerOffset is the inner offset on the sides the layout is anchored to
+func (l *Layout) anchoredInnerOffset() matrix.Vec2 {
+	inner := l.InnerOffset()
+	offset := matrix.Vec2{inner.Right(), inner.Bottom()}
+	if l.Anchor().IsLeft() {
+		offset.SetX(inner.Left())
+	}
+	if l.Anchor().IsTop() {
+		offset.SetY(inner.Top())
+	}
+	return offset
+}
+
 func (l *Layout) SetStretch(left, top, right, bottom float32) {
 	changed := !matrix.Approx(l.left, left) ||
 		!matrix.Approx(l.top, top) ||
@@ -505,6 +521,10 @@ func (l *Layout) SetStretchRatio(leftRatio, topRatio, rightRatio, bottomRatio fl
 func (l *Layout) Scale(width, height float32) bool {
 	width += l.padding.X() + l.padding.Z()
 	height += l.padding.Y() + l.padding.W()
+	if l.item.managed {
+		l.setManagedBase(width, height)
+		return false
+	}
 	ps := l.PixelSize()
 	if matrix.Vec2ApproxTo(ps, matrix.Vec2{width, height}, fractionOfPixel) {
 		return false
@@ -523,6 +543,10 @@ func (l *Layout) Scale(width, height float32) bool {
 
 func (l *Layout) ScaleWidth(width float32) bool {
 	width += l.padding.X() + l.padding.Z()
+	if l.item.managed {
+		l.setManagedBase(width, l.item.base.Y())
+		return false
+	}
 	ps := l.PixelSize()
 	if matrix.ApproxTo(ps[matrix.Vx], width, fractionOfPixel) {
 		return false
@@ -539,6 +563,10 @@ func (l *Layout) ScaleWidth(width float32) bool {
 
 func (l *Layout) ScaleHeight(height float32) bool {
 	height += l.padding.Y() + l.padding.W()
+	if l.item.managed {
+		l.setManagedBase(l.item.base.X(), height)
+		return false
+	}
 	ps := l.PixelSize()
 	if matrix.ApproxTo(ps.Y(), height, fractionOfPixel) {
 		return false
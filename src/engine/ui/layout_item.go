@@ -0,0 +1,183 @@
+/******************************************************************************/
+/* layout_item.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package ui
+
+import (
+	"kaiju/engine/ui/flex"
+	"kaiju/engine/ui/grid"
+	"kaiju/matrix"
+)
+
+// layoutItem is the state of a layout that is a child of a flex or grid
+// container. While the layout is managed, the container is the only thing
+// that sizes it, anything else that scales it (fit content, width functions,
+// etc.) instead sets the base size the container starts from.
+type layoutItem struct {
+	flex    flex.Item
+	grid    grid.Item
+	base    matrix.Vec2
+	managed bool
+}
+
+// FlexItem returns the settings used when this layout is the child of a flex
+// container, the size of the item is filled in by the container
+func (l *Layout) FlexItem() flex.Item { return l.item.flex }
+
+// SetFlexItem sets the grow, shrink, basis, order and alignment used when this
+// layout is the child of a flex container
+func (l *Layout) SetFlexItem(item flex.Item) {
+	l.item.flex = item
+	l.itemChanged()
+}
+
+// GridItem returns the settings used when this layout is the child of a grid
+// container, the size of the item is filled in by the container
+func (l *Layout) GridItem() grid.Item { return l.item.grid }
+
+// SetGridItem sets the placement and alignment used when this layout is the
+// child of a grid container
+func (l *Layout) SetGridItem(item grid.Item) {
+	l.item.grid = item
+	l.itemChanged()
+}
+
+// IsContainerManaged returns true if the size of this layout is currently set
+// by the flex or grid container it is within
+func (l *Layout) IsContainerManaged() bool { return l.item.managed }
+
+func (l *Layout) itemChanged() {
+	l.ui.SetDirty(DirtyTypeLayout)
+	if p := l.parentUI(); p != nil {
+		p.SetDirty(DirtyTypeLayout)
+	}
+}
+
+func (l *Layout) parentUI() *UI {
+	if l.ui.Entity().Parent == nil {
+		return nil
+	}
+	return FirstOnEntity(l.ui.Entity().Parent)
+}
+
+func (l *Layout) setManagedBase(width, height float32) {
+	base := matrix.Vec2{width, height}
+	if matrix.Vec2ApproxTo(l.item.base, base, fractionOfPixel) {
+		return
+	}
+	l.item.base = base
+	l.ui.layoutChanged(DirtyTypeResize)
+}
+
+// manageSize hands the size of the layout over to its container, the size it
+// has now becomes the base size that the container works from
+func (l *Layout) manageSize() {
+	if l.item.managed {
+		return
+	}
+	l.item.base = l.PixelSize()
+	l.item.managed = true
+}
+
+// releaseSize gives the layout back the size it would have had if it weren't
+// in a flex or grid container
+func (l *Layout) releaseSize() {
+	if !l.item.managed {
+		return
+	}
+	l.item.managed = false
+	l.scaleManaged(l.item.base.X(), l.item.base.Y())
+}
+
+// scaleManaged sets the pixel size (including padding) given by the container
+func (l *Layout) scaleManaged(width, height float32) {
+	if matrix.Vec2ApproxTo(l.PixelSize(), matrix.Vec2{width, height}, fractionOfPixel) {
+		return
+	}
+	if matrix.Approx(width, 0) || matrix.Approx(height, 0) {
+		return
+	}
+	size := matrix.Vec3{width, height, 1.0}
+	if l.ui.Entity().Parent != nil {
+		size.DivideAssign(l.ui.Entity().Parent.Transform.WorldScale())
+	}
+	l.ui.Entity().Transform.ScaleWithoutChildren(size)
+	l.ui.layoutChanged(DirtyTypeResize)
+}
+
+// naturalSize returns the size the layout would be without its container
+// and if that size was set explicitly. Text is measured on one line for its
+// width, and at the width it was given for its height, so it wraps within the
+// space it gets.
+func (l *Layout) naturalSize() (width, height float32, fixedWidth, fixedHeight bool) {
+	if l.ui.elmType == ElementTypeLabel {
+		label := l.ui.ToLabel()
+		width = label.measure(matrix.FloatMax).X() + 0.1
+		wrapWidth := l.PixelSize().X()
+		if wrapWidth <= 0 {
+			wrapWidth = width
+		}
+		return width, label.measure(wrapWidth).Y(), false, false
+	}
+	p := l.ui.ToPanel()
+	return l.item.base.X(), l.item.base.Y(),
+		!p.FittingContentWidth(), !p.FittingContentHeight()
+}
+
+func (l *Layout) itemMargin() [4]float32 {
+	return [4]float32{l.margin.X(), l.margin.Y(), l.margin.Z(), l.margin.W()}
+}
+
+func (l *Layout) flexItemSized() flex.Item {
+	item := l.item.flex
+	item.Margin = l.itemMargin()
+	w, h, fw, fh := l.naturalSize()
+	item.Width, item.Height = w, h
+	item.FixedWidth = item.FixedWidth || fw
+	item.FixedHeight = item.FixedHeight || fh
+	return item
+}
+
+func (l *Layout) gridItemSized() grid.Item {
+	item := l.item.grid
+	item.Margin = l.itemMargin()
+	w, h, fw, fh := l.naturalSize()
+	item.Width, item.Height = w, h
+	item.FixedWidth = item.FixedWidth || fw
+	item.FixedHeight = item.FixedHeight || fh
+	return item
+}
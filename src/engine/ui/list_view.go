@@ -0,0 +1,368 @@
+/******************************************************************************/
+/* list_view.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package ui
+
+import (
+	"kaiju/engine/systems/events"
+	"kaiju/engine/ui/virtual"
+	"kaiju/matrix"
+)
+
+// ListViewSource gives a #ListView its items. Only the rows that can be seen
+// are created, as the list is scrolled the rows that leave the view are bound
+// to the items coming into it.
+type ListViewSource struct {
+	// Count is the number of items in the list
+	Count func() int
+	// CreateRow makes an empty row, the list adds it to itself
+	CreateRow func(man *Manager) *Panel
+	// BindRow fills the row to show the item at index
+	BindRow func(row *Panel, index int, selected bool)
+	// RowHeight is optional, when it is nil the rows fit their content and
+	// are measured after they are bound
+	RowHeight func(index int) float32
+	// Click is optional, when it is set it is called for clicks on the rows
+	// instead of the list selecting them
+	Click func(row *Panel, index int)
+}
+
+type listViewData struct {
+	panelData
+	source       ListViewSource
+	rows         *virtual.Rows
+	recycler     *virtual.Recycler[*Panel]
+	shown        map[*Panel]int
+	selection    virtual.Selection
+	onSelect     events.Event
+	overscan     int
+	contentWidth float32
+}
+
+func (l *listViewData) innerPanelData() *panelData { return &l.panelData }
+
+// ListView is a vertically scrolling list that only has panels for the rows
+// that can be seen, so it can show any number of items
+type ListView Panel
+
+func (u *UI) ToListView() *ListView { return (*ListView)(u) }
+func (l *ListView) Base() *UI       { return (*UI)(l) }
+
+func (l *ListView) ListViewData() *listViewData {
+	return l.elmData.(*listViewData)
+}
+
+// Init sets up the list for the items of the source, estimatedRowHeight is
+// used for the rows that weren't seen yet when the source has no RowHeight
+func (l *ListView) Init(source ListViewSource, estimatedRowHeight float32, anchor Anchor) {
+	ld := &listViewData{
+		source:   source,
+		rows:     virtual.NewRows(estimatedRowHeight),
+		shown:    make(map[*Panel]int),
+		overscan: 2,
+	}
+	l.elmData = ld
+	p := (*Panel)(l)
+	p.Init(nil, anchor, ElementTypePanel)
+	p.DontFitContent()
+	p.SetScrollDirection(PanelScrollDirectionVertical)
+	ld.recycler = virtual.NewRecycler(l.createRow, l.bindRow)
+	l.postLayoutUpdate = l.listPostLayoutUpdate
+	l.Base().AddEvent(EventTypeRender, l.measureRows)
+	l.Refresh()
+}
+
+// OnSelectionChanged is called after rows were selected or deselected
+func (l *ListView) OnSelectionChanged() *events.Event {
+	return &l.ListViewData().onSelect
+}
+
+// SetMultiSelect allows more than one row to be selected by holding ctrl or
+// shift when clicking rows
+func (l *ListView) SetMultiSelect(multiple bool) {
+	ld := l.ListViewData()
+	ld.selection.Multiple = multiple
+	if !multiple && ld.selection.Len() > 1 {
+		l.Select(ld.selection.Anchor())
+	}
+}
+
+// SetOverscan sets how many rows are kept above and below the ones that can
+// be seen, so they are ready before they are scrolled into view
+func (l *ListView) SetOverscan(rows int) {
+	l.ListViewData().overscan = max(rows, 0)
+	l.Base().SetDirty(DirtyTypeLayout)
+}
+
+// SetContentWidth makes the rows wider than the list so that it scrolls
+// horizontally, 0 makes the rows as wide as the list
+func (l *ListView) SetContentWidth(width float32) {
+	ld := l.ListViewData()
+	ld.contentWidth = max(width, 0)
+	dir := PanelScrollDirection(PanelScrollDirectionVertical)
+	if ld.contentWidth > 0 {
+		dir = PanelScrollDirectionBoth
+	}
+	(*Panel)(l).SetScrollDirection(dir)
+}
+
+// Refresh updates the list after items were added, removed or changed, the
+// rows that can be seen are bound again
+func (l *ListView) Refresh() {
+	ld := l.ListViewData()
+	count := 0
+	if ld.source.Count != nil {
+		count = ld.source.Count()
+	}
+	ld.rows.SetLen(count)
+	ld.selection.Truncate(count)
+	if ld.source.RowHeight != nil {
+		for i := range count {
+			ld.rows.SetHeight(i, ld.source.RowHeight(i))
+		}
+	}
+	ld.recycler.Rebind()
+	l.Base().SetDirty(DirtyTypeLayout)
+}
+
+// Reset is for when all the items of the list were replaced, the heights that
+// were measured for the rows are forgotten and the selection is cleared
+func (l *ListView) Reset() {
+	ld := l.ListViewData()
+	ld.rows.Reset()
+	ld.selection.Clear()
+	l.Refresh()
+}
+
+// RefreshRow binds the row of the item again if it can be seen
+func (l *ListView) RefreshRow(index int) {
+	ld := l.ListViewData()
+	if row, ok := ld.recycler.Item(index); ok {
+		l.bindRow(row, index)
+	}
+}
+
+// ScrollTo scrolls the list so the row of the item is at the top
+func (l *ListView) ScrollTo(index int) {
+	(*Panel)(l).SetScrollY(l.ListViewData().rows.Offset(index))
+}
+
+// ScrollIntoView scrolls the list as little as needed for the whole row of
+// the item to be seen
+func (l *ListView) ScrollIntoView(index int) {
+	p := (*Panel)(l)
+	ld := l.ListViewData()
+	_, height := p.containerContentSize()
+	top := ld.rows.Offset(index)
+	bottom := top + ld.rows.Height(index)
+	if scroll := p.ScrollY(); top < scroll {
+		p.SetScrollY(top)
+	} else if bottom > scroll+height {
+		p.SetScrollY(bottom - height)
+	}
+}
+
+// RowAtCursor finds the item under the cursor and how far down its row the
+// cursor is, from 0 at the top to 1 at the bottom
+func (l *ListView) RowAtCursor() (index int, fraction float32, ok bool) {
+	p := (*Panel)(l)
+	ld := l.ListViewData()
+	pos := l.Base().cursorPos(&l.man.Host.Window.Cursor)
+	if !l.entity.Transform.ContainsPoint2D(pos) {
+		return -1, 0, false
+	}
+	wp := l.entity.Transform.WorldPosition()
+	ws := l.entity.Transform.WorldScale()
+	y := wp.Y() + ws.Y()*0.5 - pos.Y() - p.layout.padding.Top() -
+		p.layout.border.Top() + p.ScrollY()
+	if index = ld.rows.IndexAt(y); index < 0 {
+		return -1, 0, false
+	}
+	h := ld.rows.Height(index)
+	if h <= 0 {
+		return index, 0, true
+	}
+	return index, matrix.Clamp((y-ld.rows.Offset(index))/h, 0, 1), true
+}
+
+// VisibleRange gives the items [first, last) that have rows
+func (l *ListView) VisibleRange() (first, last int) {
+	indices := l.ListViewData().recycler.Indices()
+	if len(indices) == 0 {
+		return 0, 0
+	}
+	return indices[0], indices[len(indices)-1] + 1
+}
+
+// Row returns the row showing the item, if the item has one
+func (l *ListView) Row(index int) (*Panel, bool) {
+	return l.ListViewData().recycler.Item(index)
+}
+
+// Selection returns the selected items in order
+func (l *ListView) Selection() []int { return l.ListViewData().selection.Indices() }
+
+func (l *ListView) IsSelected(index int) bool {
+	return l.ListViewData().selection.IsSelected(index)
+}
+
+// Select makes the item the only one selected
+func (l *ListView) Select(index int) {
+	ld := l.ListViewData()
+	ld.selection.Click(index, false, false)
+	l.selectionChanged()
+}
+
+// SetSelected selects or deselects the item, keeping the other selected items
+// if the list allows more than one
+func (l *ListView) SetSelected(index int, selected bool) {
+	ld := l.ListViewData()
+	if !ld.selection.Multiple && selected {
+		ld.selection.Click(index, false, false)
+	} else {
+		ld.selection.Set(index, selected)
+	}
+	l.selectionChanged()
+}
+
+func (l *ListView) ClearSelection() {
+	l.ListViewData().selection.Clear()
+	l.selectionChanged()
+}
+
+// remapSelection moves the selection with the items after they were sorted,
+// order[i] is the old index of the item now at i
+func (l *ListView) remapSelection(order []int) {
+	l.ListViewData().selection.Remap(order)
+}
+
+func (l *ListView) selectionChanged() {
+	ld := l.ListViewData()
+	ld.recycler.Rebind()
+	ld.onSelect.Execute()
+}
+
+func (l *ListView) createRow() *Panel {
+	ld := l.ListViewData()
+	row := ld.source.CreateRow(l.man)
+	if ld.source.RowHeight != nil {
+		row.DontFitContent()
+	} else {
+		row.DontFitContentWidth()
+	}
+	(*Panel)(l).AddChild(row.Base())
+	row.Base().AddEvent(EventTypeClick, func() { l.rowClicked(row) })
+	return row
+}
+
+func (l *ListView) bindRow(row *Panel, index int) {
+	ld := l.ListViewData()
+	ld.shown[row] = index
+	if !row.entity.IsActive() {
+		row.entity.Activate()
+	}
+	ld.source.BindRow(row, index, ld.selection.IsSelected(index))
+}
+
+func (l *ListView) rowClicked(row *Panel) {
+	ld := l.ListViewData()
+	index, ok := ld.shown[row]
+	if !ok {
+		return
+	} else if ld.source.Click != nil {
+		ld.source.Click(row, index)
+		return
+	}
+	kb := &l.man.Host.Window.Keyboard
+	ld.selection.Click(index, kb.HasCtrl(), kb.HasShift())
+	l.selectionChanged()
+}
+
+// measureRows reads the heights the rows took after they were laid out, if
+// any row isn't the height it was placed for the list is laid out again
+func (l *ListView) measureRows() {
+	ld := l.ListViewData()
+	if ld.source.RowHeight != nil {
+		return
+	}
+	changed := false
+	for _, idx := range ld.recycler.Indices() {
+		row, _ := ld.recycler.Item(idx)
+		if h := row.layout.PixelSize().Height(); h > 0 && ld.rows.SetHeight(idx, h) {
+			changed = true
+		}
+	}
+	if changed {
+		l.Base().SetDirty(DirtyTypeLayout)
+	}
+}
+
+func (l *ListView) listPostLayoutUpdate() {
+	p := (*Panel)(l)
+	pd := p.PanelData()
+	ld := l.ListViewData()
+	pad, border := p.layout.padding, p.layout.border
+	width, height := p.containerContentSize()
+	rowWidth := max(width, ld.contentWidth)
+	p.updateMaxScroll(matrix.Vec2{
+		rowWidth + pad.Left() + pad.Right() + border.Left() + border.Right(),
+		ld.rows.Total() + pad.Top() + pad.Bottom() + border.Top() + border.Bottom(),
+	})
+	// The list may have gotten shorter than where it was scrolled to
+	pd.scroll.SetX(matrix.Clamp(pd.scroll.X(), 0, pd.maxScroll.X()))
+	pd.scroll.SetY(matrix.Clamp(pd.scroll.Y(), -pd.maxScroll.Y(), 0))
+	offsetStart := p.applyScrollRequests()
+	first, last := ld.rows.Visible(-offsetStart.Y(), height, ld.overscan)
+	ld.recycler.Show(first, last)
+	for _, row := range ld.recycler.Free() {
+		if row.entity.IsActive() {
+			row.entity.Deactivate()
+		}
+		delete(ld.shown, row)
+	}
+	origin := offsetStart.Add(matrix.Vec2{
+		pad.Left() + border.Left(), pad.Top() + border.Top()})
+	for idx := first; idx < last; idx++ {
+		row, _ := ld.recycler.Item(idx)
+		if ld.source.RowHeight != nil {
+			row.layout.Scale(rowWidth, ld.rows.Height(idx))
+		} else {
+			row.layout.ScaleWidth(rowWidth)
+		}
+		row.layout.SetRowLayoutOffset(origin.Add(matrix.Vec2{0, ld.rows.Offset(idx)}))
+	}
+}
@@ -0,0 +1,194 @@
+/******************************************************************************/
+/* binding_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package binding
+
+import (
+	"testing"
+)
+
+type item struct {
+	Name  string
+	Count int
+}
+
+type player struct {
+	Name    string
+	Health  float32
+	Alive   bool
+	Items   []item
+	Stats   map[string]int
+	Friend  *player
+	private int
+}
+
+func (p player) Title() string  { return "Sir " + p.Name }
+func (p *player) Wounded() bool { return p.Health < 50 }
+func (p player) Add(x int) int  { return x }
+
+func testModel() *player {
+	return &player{
+		Name:   "Ada",
+		Health: 75.5,
+		Alive:  true,
+		Items:  []item{{"Sword", 1}, {"Potion", 3}},
+		Stats:  map[string]int{"str": 7},
+	}
+}
+
+func lookup(t *testing.T, s *Scope, path string) string {
+	t.Helper()
+	v, err := s.Lookup(path)
+	if err != nil {
+		t.Fatalf("%s failed: %v", path, err)
+	}
+	return Format(v)
+}
+
+func TestLookup(t *testing.T) {
+	s := NewScope(testModel())
+	tests := map[string]string{
+		"Name":          "Ada",
+		"Health":        "75.5",
+		"Items[1].Name": "Potion",
+		"Items[0]":      "{Sword 1}",
+		"Stats[str]":    "7",
+		"Stats[none]":   "",
+		"Title":         "Sir Ada",
+		"Wounded":       "false",
+		"Friend":        "",
+	}
+	for path, want := range tests {
+		if got := lookup(t, s, path); got != want {
+			t.Errorf("%s expected %q but got %q", path, want, got)
+		}
+	}
+	for _, bad := range []string{"Missing", "Items[5]", "Items[x]", "private", "Add", "Friend.Name", "Name[0"} {
+		if _, err := s.Lookup(bad); err == nil {
+			t.Errorf("%s expected an error", bad)
+		}
+	}
+}
+
+func TestLoopScope(t *testing.T) {
+	root := NewScope(testModel())
+	name, path, err := ParseLoop("it in Items")
+	if err != nil || name != "it" || path != "Items" {
+		t.Fatalf("unexpected loop %q %q %v", name, path, err)
+	}
+	row := root.With(name, path+"[1]")
+	if got := lookup(t, row, "it.Count"); got != "3" {
+		t.Errorf("expected 3 but got %s", got)
+	}
+	if got := row.Expand("it.Name"); got != "Items[1].Name" {
+		t.Errorf("unexpected expansion %s", got)
+	}
+	if got := lookup(t, row, "Name"); got != "Ada" {
+		t.Errorf("expected the root to be visible but got %s", got)
+	}
+	nested := row.With("n", "it.Name")
+	if got := nested.Expand("n"); got != "Items[1].Name" {
+		t.Errorf("unexpected nested expansion %s", got)
+	}
+	if _, _, err := ParseLoop("Items"); err == nil {
+		t.Error("expected an error for a loop without a name")
+	}
+}
+
+func TestSet(t *testing.T) {
+	m := testModel()
+	s := NewScope(m)
+	row := s.With("it", "Items[0]")
+	steps := []struct {
+		path  string
+		value any
+	}{
+		{"Name", "Bob"},
+		{"Health", "20"},
+		{"Alive", false},
+		{"it.Count", float32(4.0)},
+		{"Stats[dex]", "9"},
+	}
+	for _, step := range steps {
+		if err := row.Set(step.path, step.value); err != nil {
+			t.Fatalf("setting %s failed: %v", step.path, err)
+		}
+	}
+	if m.Name != "Bob" || m.Health != 20 || m.Alive || m.Items[0].Count != 4 || m.Stats["dex"] != 9 {
+		t.Errorf("unexpected model after setting %+v", m)
+	}
+	if err := s.Set("Health", "lots"); err == nil {
+		t.Error("expected an error for an invalid number")
+	}
+	if err := NewScope(*m).Set("Name", "Cy"); err == nil {
+		t.Error("expected an error when the model is not a pointer")
+	}
+	m.Stats = nil
+	if err := s.Set("Stats[hp]", "5"); err != nil || m.Stats["hp"] != 5 {
+		t.Errorf("expected a nil map to be made when set, got %v %v", m.Stats, err)
+	}
+	var stats map[string]int
+	if err := NewScope(stats).Set("[hp]", "5"); err == nil {
+		t.Error("expected an error for a nil map that can't be made")
+	}
+}
+
+func TestTruthy(t *testing.T) {
+	m := testModel()
+	s := NewScope(m)
+	tests := map[string]bool{
+		"Alive": true, "Friend": false, "Items": true, "Health": true,
+		"Stats[none]": false, "Name": true, "Items[0]": true,
+	}
+	for path, want := range tests {
+		v, err := s.Lookup(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if Truthy(v) != want {
+			t.Errorf("%s expected %v", path, want)
+		}
+	}
+	v, _ := s.Lookup("Items")
+	if n, err := Len(v); err != nil || n != 2 {
+		t.Errorf("expected 2 items but got %d %v", n, err)
+	}
+	v, _ = s.Lookup("Name")
+	if _, err := Len(v); err == nil {
+		t.Error("expected an error looping over a string")
+	}
+}
@@ -0,0 +1,196 @@
+/******************************************************************************/
+/* path.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type segment struct {
+	name  string
+	index bool
+}
+
+// parsePath splits a path like Players[0].Name into its segments
+func parsePath(path string) ([]segment, error) {
+	out := make([]segment, 0, 4)
+	for path != "" {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("missing ] in %s", path)
+			}
+			out = append(out, segment{name: strings.Trim(path[1:end], `"'`), index: true})
+			path = path[end+1:]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			out = append(out, segment{name: path[:end]})
+			path = path[end:]
+		}
+	}
+	return out, nil
+}
+
+// indirect follows pointers and interfaces to the value they hold
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func (s segment) step(v reflect.Value) (reflect.Value, error) {
+	if !s.index {
+		// Methods can be on the pointer, so they are looked for first
+		if v.IsValid() && v.Kind() != reflect.Interface {
+			if m := v.MethodByName(s.name); m.IsValid() {
+				return callMethod(m, s.name)
+			} else if v.Kind() != reflect.Pointer && v.CanAddr() {
+				if m := v.Addr().MethodByName(s.name); m.IsValid() {
+					return callMethod(m, s.name)
+				}
+			}
+		}
+	}
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return v, fmt.Errorf("can't read %s of a nil value", s.name)
+	case reflect.Struct:
+		if s.index {
+			return reflect.Value{}, fmt.Errorf("can't index a struct with [%s]", s.name)
+		}
+		f, ok := v.Type().FieldByName(s.name)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("no field or method named %s", s.name)
+		} else if !f.IsExported() {
+			return reflect.Value{}, fmt.Errorf("the field %s is not exported", s.name)
+		}
+		return v.FieldByIndex(f.Index), nil
+	case reflect.Slice, reflect.Array, reflect.String:
+		i, err := strconv.Atoi(s.name)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid index %s", s.name)
+		} else if i < 0 || i >= v.Len() {
+			return reflect.Value{}, fmt.Errorf("index %d is out of range", i)
+		}
+		return v.Index(i), nil
+	case reflect.Map:
+		key, err := mapKey(v, s.name)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return v.MapIndex(key), nil
+	}
+	return reflect.Value{}, fmt.Errorf("can't read %s of a %s", s.name, v.Kind())
+}
+
+func callMethod(m reflect.Value, name string) (reflect.Value, error) {
+	if m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return reflect.Value{}, fmt.Errorf("the method %s must take no arguments and return one value", name)
+	}
+	return m.Call(nil)[0], nil
+}
+
+func mapKey(m reflect.Value, key string) (reflect.Value, error) {
+	k := reflect.New(m.Type().Key()).Elem()
+	if err := assign(k, key); err != nil {
+		return reflect.Value{}, fmt.Errorf("invalid map key %s", key)
+	}
+	return k, nil
+}
+
+// assign sets the value into dst, strings and numbers are converted to the
+// kind of dst
+func assign(dst reflect.Value, value any) error {
+	src := reflect.ValueOf(value)
+	if !src.IsValid() {
+		dst.SetZero()
+		return nil
+	} else if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+	str := Format(src)
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(str)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return err
+		}
+		dst.SetInt(int64(f))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return err
+		} else if f < 0 {
+			return errors.New("can't set a negative value to an unsigned number")
+		}
+		dst.SetUint(uint64(f))
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(f)
+	default:
+		return fmt.Errorf("can't set a %s from %q", dst.Kind(), str)
+	}
+	return nil
+}
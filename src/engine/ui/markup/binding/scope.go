@@ -0,0 +1,150 @@
+/******************************************************************************/
+/* scope.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Scope is what the paths of bindings are looked up in. The root scope holds
+// the model, each data-for loop adds a scope that names the current item of
+// the loop, like "item" for data-for="item in Inventory.Items".
+type Scope struct {
+	model  reflect.Value
+	parent *Scope
+	name   string
+	path   string
+}
+
+// NewScope creates the root scope for the model, the model should be a
+// pointer for values to be written back to it
+func NewScope(model any) *Scope {
+	return &Scope{model: reflect.ValueOf(model)}
+}
+
+// With creates a child scope where name stands for the path, the path is
+// relative to this scope
+func (s *Scope) With(name, path string) *Scope {
+	return &Scope{model: s.model, parent: s, name: name, path: s.Expand(path)}
+}
+
+// Expand replaces the names of the loops at the start of the path with the
+// paths they stand for, giving a path from the root of the model
+func (s *Scope) Expand(path string) string {
+	for scope := s; scope.parent != nil; scope = scope.parent {
+		first, rest := splitFirst(path)
+		if first == scope.name {
+			return scope.path + rest
+		}
+	}
+	return path
+}
+
+// Lookup finds the value at the path, a path is made of field names (or
+// methods without arguments), indexes and map keys, like Players[0].Name or
+// Stats[health]
+func (s *Scope) Lookup(path string) (reflect.Value, error) {
+	segments, err := parsePath(s.Expand(path))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	v := s.model
+	for _, seg := range segments {
+		if v, err = seg.step(v); err != nil {
+			return reflect.Value{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return v, nil
+}
+
+// Set writes the value to the model at the path, strings are parsed into the
+// kind of value found at the path
+func (s *Scope) Set(path string, value any) error {
+	segments, err := parsePath(s.Expand(path))
+	if err != nil {
+		return err
+	} else if len(segments) == 0 {
+		return errors.New("can't set the model itself")
+	}
+	v := s.model
+	for _, seg := range segments[:len(segments)-1] {
+		if v, err = seg.step(v); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	last := segments[len(segments)-1]
+	v = indirect(v)
+	if v.Kind() == reflect.Map {
+		elm := reflect.New(v.Type().Elem()).Elem()
+		if err := assign(elm, value); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		key, err := mapKey(v, last.name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if v.IsNil() {
+			if !v.CanSet() {
+				return fmt.Errorf("%s: the map is nil and can't be made", path)
+			}
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		v.SetMapIndex(key, elm)
+		return nil
+	}
+	target, err := last.step(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	} else if !target.CanSet() {
+		return fmt.Errorf("%s: the value can't be set, the model should be a pointer", path)
+	}
+	if err := assign(target, value); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func splitFirst(path string) (string, string) {
+	if i := strings.IndexAny(path, ".["); i >= 0 {
+		return path[:i], path[i:]
+	}
+	return path, ""
+}
@@ -0,0 +1,104 @@
+/******************************************************************************/
+/* value.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package binding
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Format writes the value as the text shown in the document, nil values are
+// empty
+func Format(v reflect.Value) string {
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return ""
+	case reflect.String:
+		return v.String()
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+	if v.CanInterface() {
+		return fmt.Sprint(v.Interface())
+	}
+	return ""
+}
+
+// Truthy is how data-if decides if an element is shown. False, zero, empty
+// and nil values are false, everything else is true.
+func Truthy(v reflect.Value) bool {
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return false
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len() > 0
+	case reflect.Struct:
+		return true
+	}
+	return !v.IsZero()
+}
+
+// Len is the number of items a data-for loop makes elements for
+func Len(v reflect.Value) (int, error) {
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return 0, nil
+	case reflect.Slice, reflect.Array:
+		return v.Len(), nil
+	}
+	return 0, fmt.Errorf("can't loop over a %s", v.Kind())
+}
+
+// ParseLoop reads the value of a data-for attribute, written as
+// "item in Path.To.Slice"
+func ParseLoop(expr string) (name, path string, err error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 3 || fields[1] != "in" {
+		return "", "", fmt.Errorf("expected \"name in path\" but got %q", expr)
+	}
+	return fields[0], fields[2], nil
+}
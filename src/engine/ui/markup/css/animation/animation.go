@@ -0,0 +1,413 @@
+/******************************************************************************/
+/* animation.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package animation
+
+import (
+	"fmt"
+	"kaiju/engine/ui/markup/css/rules"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Direction int
+
+const (
+	DirectionNormal Direction = iota
+	DirectionReverse
+	DirectionAlternate
+	DirectionAlternateReverse
+)
+
+type FillMode int
+
+const (
+	FillNone FillMode = iota
+	FillForwards
+	FillBackwards
+	FillBoth
+)
+
+type PlayState int
+
+const (
+	PlayStateRunning PlayState = iota
+	PlayStatePaused
+)
+
+var directions = map[string]Direction{
+	"normal":            DirectionNormal,
+	"reverse":           DirectionReverse,
+	"alternate":         DirectionAlternate,
+	"alternate-reverse": DirectionAlternateReverse,
+}
+
+var fillModes = map[string]FillMode{
+	"none":      FillNone,
+	"forwards":  FillForwards,
+	"backwards": FillBackwards,
+	"both":      FillBoth,
+}
+
+var playStates = map[string]PlayState{
+	"running": PlayStateRunning,
+	"paused":  PlayStatePaused,
+}
+
+// Infinite is the iteration count of an animation that never ends
+var Infinite = math.Inf(1)
+
+// Animation describes how a set of @keyframes is played, the duration and
+// delay are in seconds
+type Animation struct {
+	Name       string
+	Duration   float64
+	Delay      float64
+	Timing     Timing
+	Iterations float64
+	Direction  Direction
+	Fill       FillMode
+	PlayState  PlayState
+}
+
+func DefaultAnimation() Animation {
+	return Animation{
+		Timing:     Ease,
+		Iterations: 1,
+	}
+}
+
+func (a Animation) activeDuration() float64 {
+	if a.Duration <= 0 || a.Iterations <= 0 {
+		return 0
+	}
+	return a.Duration * a.Iterations
+}
+
+// Finished returns true once the animation has played all of its iterations
+func (a Animation) Finished(elapsed float64) bool {
+	return elapsed >= a.Delay+a.activeDuration()
+}
+
+// Progress returns the progress (0 to 1) through the keyframes at the given
+// number of seconds since the animation started. The direction of the current
+// iteration and the fill mode are accounted for, the timing function is not
+// as it applies between each pair of keyframes. If the animation has no
+// effect at this time, active will be false.
+func (a Animation) Progress(elapsed float64) (progress float64, active bool) {
+	local := elapsed - a.Delay
+	var overall float64
+	after := false
+	if local < 0 {
+		if a.Fill != FillBackwards && a.Fill != FillBoth {
+			return 0, false
+		}
+		overall = 0
+	} else if local >= a.activeDuration() {
+		if a.Fill != FillForwards && a.Fill != FillBoth {
+			return 0, false
+		}
+		overall = max(a.Iterations, 0)
+		after = true
+	} else {
+		overall = local / a.Duration
+	}
+	iteration := math.Floor(overall)
+	progress = overall - iteration
+	if after && progress == 0 && overall > 0 {
+		// Ending exactly on an iteration boundary holds the end of the last
+		// iteration rather than the start of the next one
+		iteration--
+		progress = 1
+	}
+	odd := math.Mod(iteration, 2) == 1
+	switch a.Direction {
+	case DirectionReverse:
+		progress = 1 - progress
+	case DirectionAlternate:
+		if odd {
+			progress = 1 - progress
+		}
+	case DirectionAlternateReverse:
+		if !odd {
+			progress = 1 - progress
+		}
+	}
+	return progress, true
+}
+
+// ParseTime reads a CSS time value like 2s or 150ms into seconds
+func ParseTime(str string) (float64, bool) {
+	n, unit, ok := splitNumber(str)
+	if !ok {
+		return 0, false
+	}
+	switch strings.ToLower(unit) {
+	case "s":
+		return n, true
+	case "ms":
+		return n / 1000, true
+	case "":
+		return n, n == 0
+	}
+	return 0, false
+}
+
+func parseIterations(str string) (float64, bool) {
+	if str == "infinite" {
+		return Infinite, true
+	}
+	n, err := strconv.ParseFloat(str, 64)
+	return n, err == nil && n >= 0
+}
+
+type animationLists struct {
+	names      []string
+	durations  []float64
+	delays     []float64
+	timings    []Timing
+	iterations []float64
+	directions []Direction
+	fills      []FillMode
+	states     []PlayState
+}
+
+func (l *animationLists) reset(property string) {
+	switch property {
+	case "animation":
+		*l = animationLists{}
+	case "animation-name":
+		l.names = nil
+	case "animation-duration":
+		l.durations = nil
+	case "animation-delay":
+		l.delays = nil
+	case "animation-timing-function":
+		l.timings = nil
+	case "animation-iteration-count":
+		l.iterations = nil
+	case "animation-direction":
+		l.directions = nil
+	case "animation-fill-mode":
+		l.fills = nil
+	case "animation-play-state":
+		l.states = nil
+	}
+}
+
+func isGlobalKeyword(values []rules.PropertyValue) bool {
+	return len(values) == 1 && (values[0].Str == "initial" || values[0].Str == "inherit")
+}
+
+// ParseAnimations reads the animation and animation-* rules, in the order
+// they are given, into the animations that they describe. Animations named
+// none are skipped. The list separating commas are not kept by the style sheet
+// parser, so the shorthand starts a new animation when it finds a value that
+// was already set for the current one; use the longhand properties for lists
+// that would be ambiguous.
+func ParseAnimations(style []rules.Rule) ([]Animation, error) {
+	lists := animationLists{}
+	for i := range style {
+		r := &style[i]
+		if isGlobalKeyword(r.Values) {
+			// There is no parent animation to inherit, both start over from
+			// the initial value
+			lists.reset(r.Property)
+			continue
+		}
+		var err error
+		switch r.Property {
+		case "animation":
+			var all []Animation
+			if all, err = parseAnimationShorthand(r.Values); err == nil {
+				lists = animationLists{}
+				for _, a := range all {
+					lists.names = append(lists.names, a.Name)
+					lists.durations = append(lists.durations, a.Duration)
+					lists.delays = append(lists.delays, a.Delay)
+					lists.timings = append(lists.timings, a.Timing)
+					lists.iterations = append(lists.iterations, a.Iterations)
+					lists.directions = append(lists.directions, a.Direction)
+					lists.fills = append(lists.fills, a.Fill)
+					lists.states = append(lists.states, a.PlayState)
+				}
+			}
+		case "animation-name":
+			lists.names = lists.names[:0]
+			for _, v := range r.Values {
+				lists.names = append(lists.names, v.Str)
+			}
+		case "animation-duration":
+			lists.durations, err = parseList(r.Values, timeValue)
+		case "animation-delay":
+			lists.delays, err = parseList(r.Values, timeValue)
+		case "animation-timing-function":
+			lists.timings, err = parseList(r.Values, ParseTiming)
+		case "animation-iteration-count":
+			lists.iterations, err = parseList(r.Values, keywordValue(parseIterations))
+		case "animation-direction":
+			lists.directions, err = parseList(r.Values, mapValue(directions))
+		case "animation-fill-mode":
+			lists.fills, err = parseList(r.Values, mapValue(fillModes))
+		case "animation-play-state":
+			lists.states, err = parseList(r.Values, mapValue(playStates))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", r.Property, err)
+		}
+	}
+	out := make([]Animation, 0, len(lists.names))
+	for i, name := range lists.names {
+		if name == "none" || name == "" {
+			continue
+		}
+		a := DefaultAnimation()
+		a.Name = name
+		a.Duration = pick(lists.durations, i, a.Duration)
+		a.Delay = pick(lists.delays, i, a.Delay)
+		a.Timing = pick(lists.timings, i, a.Timing)
+		a.Iterations = pick(lists.iterations, i, a.Iterations)
+		a.Direction = pick(lists.directions, i, a.Direction)
+		a.Fill = pick(lists.fills, i, a.Fill)
+		a.PlayState = pick(lists.states, i, a.PlayState)
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// ValidateAnimation checks the values of one of the animation properties
+func ValidateAnimation(property string, values []rules.PropertyValue) error {
+	_, err := ParseAnimations([]rules.Rule{{Property: property, Values: values}})
+	return err
+}
+
+func parseAnimationShorthand(values []rules.PropertyValue) ([]Animation, error) {
+	out := []Animation{}
+	var a *Animation
+	var times int
+	var hasTiming, hasIterations, hasDirection, hasFill, hasState bool
+	next := func() {
+		out = append(out, DefaultAnimation())
+		a = &out[len(out)-1]
+		times = 0
+		hasTiming, hasIterations, hasDirection, hasFill, hasState = false, false, false, false, false
+	}
+	next()
+	for _, v := range values {
+		if t, ok := ParseTime(v.Str); ok && !v.IsFunction() {
+			if times == 2 {
+				next()
+			}
+			if times == 0 {
+				a.Duration = t
+			} else {
+				a.Delay = t
+			}
+			times++
+		} else if IsTiming(v) {
+			if hasTiming {
+				next()
+			}
+			timing, err := ParseTiming(v)
+			if err != nil {
+				return nil, err
+			}
+			a.Timing, hasTiming = timing, true
+		} else if n, ok := parseIterations(v.Str); ok {
+			if hasIterations {
+				next()
+			}
+			a.Iterations, hasIterations = n, true
+		} else if d, ok := directions[v.Str]; ok && !hasDirection {
+			a.Direction, hasDirection = d, true
+		} else if f, ok := fillModes[v.Str]; ok && !hasFill && (v.Str != "none" || a.Name != "") {
+			a.Fill, hasFill = f, true
+		} else if s, ok := playStates[v.Str]; ok && !hasState {
+			a.PlayState, hasState = s, true
+		} else {
+			if a.Name != "" {
+				next()
+			}
+			a.Name = v.Str
+		}
+	}
+	return out, nil
+}
+
+func pick[T any](list []T, i int, fallback T) T {
+	if len(list) == 0 {
+		return fallback
+	}
+	return list[i%len(list)]
+}
+
+func parseList[T any](values []rules.PropertyValue, parse func(rules.PropertyValue) (T, error)) ([]T, error) {
+	out := make([]T, 0, len(values))
+	for _, v := range values {
+		t, err := parse(v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func timeValue(v rules.PropertyValue) (float64, error) {
+	if t, ok := ParseTime(v.Str); ok && !v.IsFunction() {
+		return t, nil
+	}
+	return 0, fmt.Errorf("invalid time: %s", v.Str)
+}
+
+func keywordValue[T any](parse func(string) (T, bool)) func(rules.PropertyValue) (T, error) {
+	return func(v rules.PropertyValue) (T, error) {
+		t, ok := parse(v.Str)
+		if !ok {
+			return t, fmt.Errorf("invalid value: %s", v.Str)
+		}
+		return t, nil
+	}
+}
+
+func mapValue[T any](m map[string]T) func(rules.PropertyValue) (T, error) {
+	return keywordValue(func(s string) (T, bool) {
+		t, ok := m[s]
+		return t, ok
+	})
+}
@@ -0,0 +1,315 @@
+/******************************************************************************/
+/* animation_test.go                                                          */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package animation
+
+import (
+	"kaiju/engine/ui/markup/css/rules"
+	"math"
+	"testing"
+)
+
+func value(str string, args ...string) rules.PropertyValue {
+	if args == nil {
+		args = []string{}
+	}
+	return rules.PropertyValue{Str: str, Args: args}
+}
+
+func expectValues(t *testing.T, got []rules.PropertyValue, expected ...rules.PropertyValue) {
+	t.Helper()
+	if !equalValues(got, expected) {
+		t.Errorf("expected %v but got %v", expected, got)
+	}
+}
+
+func expectNear(t *testing.T, got, expected float64) {
+	t.Helper()
+	if math.Abs(got-expected) > 0.001 {
+		t.Errorf("expected %f but got %f", expected, got)
+	}
+}
+
+func parseStyleSheet(css string) rules.StyleSheet {
+	s := rules.NewStyleSheet()
+	s.Parse(css)
+	return s
+}
+
+func TestTimingFunctions(t *testing.T) {
+	expectNear(t, Linear.Apply(0.3), 0.3)
+	expectNear(t, Ease.Apply(0), 0)
+	expectNear(t, Ease.Apply(1), 1)
+	expectNear(t, Ease.Apply(0.5), 0.8024)
+	expectNear(t, EaseIn.Apply(0.5), 0.3153)
+	expectNear(t, EaseInOut.Apply(0.5), 0.5)
+	expectNear(t, StepEnd.Apply(0.99), 0)
+	expectNear(t, StepStart.Apply(0.01), 1)
+	expectNear(t, Steps(4, JumpEnd).Apply(0.3), 0.25)
+	expectNear(t, Steps(4, JumpBoth).Apply(0), 0.2)
+	expectNear(t, Steps(3, JumpNone).Apply(0.5), 0.5)
+}
+
+func TestParseCubicBezier(t *testing.T) {
+	timing, err := ParseTiming(value("cubic-bezier", "0", "0", "1", "1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectNear(t, timing.Apply(0.25), 0.25)
+	// Overshooting curves leave the 0 to 1 range
+	timing, _ = ParseTiming(value("cubic-bezier", "0.3", "1.5", "0.7", "1.5"))
+	if timing.Apply(0.6) <= 1 {
+		t.Errorf("expected the curve to overshoot but got %f", timing.Apply(0.6))
+	}
+	if _, err = ParseTiming(value("cubic-bezier", "1.5", "0", "0", "1")); err == nil {
+		t.Error("expected an error for an x value outside of [0, 1]")
+	}
+	if _, err = ParseTiming(value("bounce")); err == nil {
+		t.Error("expected an error for an unknown timing function")
+	}
+}
+
+func TestInterpolateColors(t *testing.T) {
+	in := Interpolator{NamedColors: map[string]string{"white": "#FFFFFF"}}
+	from := []rules.PropertyValue{value("#000000")}
+	expectValues(t, in.Interpolate(from, []rules.PropertyValue{value("white")}, 0.5), value("#808080ff"))
+	expectValues(t, in.Interpolate(from, []rules.PropertyValue{value("#ff000000")}, 0.25), value("#400000bf"))
+}
+
+func TestInterpolateLengths(t *testing.T) {
+	in := Interpolator{}
+	from := []rules.PropertyValue{value("10px"), value("0")}
+	to := []rules.PropertyValue{value("20px"), value("50%")}
+	expectValues(t, in.Interpolate(from, to, 0.25), value("12.5px"), value("12.5%"))
+	// Overshooting the end extrapolates the length
+	expectValues(t, in.Interpolate(from[:1], to[:1], 1.5), value("25px"))
+	// Lengths in different units can't be blended and switch halfway
+	mixed := []rules.PropertyValue{value("2em")}
+	expectValues(t, in.Interpolate(from[:1], mixed, 0.4), value("10px"))
+	expectValues(t, in.Interpolate(from[:1], mixed, 0.6), value("2em"))
+}
+
+func TestInterpolateTransforms(t *testing.T) {
+	in := Interpolator{}
+	from := []rules.PropertyValue{value("translate", "0px", "10%")}
+	to := []rules.PropertyValue{value("translate", "100px", "30%")}
+	expectValues(t, in.Interpolate(from, to, 0.5), value("translate", "50px", "20%"))
+	rotate := []rules.PropertyValue{value("rotate", "45deg")}
+	expectValues(t, in.Interpolate(from, rotate, 0.49), from...)
+	expectValues(t, in.Interpolate(from, rotate, 0.5), rotate...)
+}
+
+func TestAnimationProgress(t *testing.T) {
+	a := DefaultAnimation()
+	a.Duration = 2
+	a.Delay = 1
+	a.Iterations = 2
+	a.Direction = DirectionAlternate
+	if _, active := a.Progress(0.5); active {
+		t.Error("expected no effect during the delay without a backwards fill")
+	}
+	p, _ := a.Progress(2)
+	expectNear(t, p, 0.5)
+	p, _ = a.Progress(3.5)
+	expectNear(t, p, 0.75)
+	if _, active := a.Progress(6); active {
+		t.Error("expected no effect after the end without a forwards fill")
+	}
+	a.Fill = FillBoth
+	p, _ = a.Progress(0)
+	expectNear(t, p, 0)
+	p, _ = a.Progress(6)
+	expectNear(t, p, 0)
+	a.Direction = DirectionNormal
+	p, _ = a.Progress(6)
+	expectNear(t, p, 1)
+	if !a.Finished(5) {
+		t.Error("expected the animation to be finished")
+	}
+	a.Iterations = Infinite
+	if a.Finished(1000) {
+		t.Error("expected an infinite animation to never finish")
+	}
+}
+
+func TestParseAnimations(t *testing.T) {
+	s := parseStyleSheet(`div {
+		animation: spin 2s linear 500ms infinite alternate both, fade 1s;
+		animation-play-state: paused running;
+	}`)
+	all, err := ParseAnimations(s.Groups[0].Rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 animations but got %d", len(all))
+	}
+	spin, fade := all[0], all[1]
+	if spin.Name != "spin" || spin.Duration != 2 || spin.Delay != 0.5 ||
+		spin.Iterations != Infinite || spin.Direction != DirectionAlternate ||
+		spin.Fill != FillBoth || spin.PlayState != PlayStatePaused {
+		t.Errorf("unexpected spin animation %+v", spin)
+	}
+	if fade.Name != "fade" || fade.Duration != 1 || fade.Iterations != 1 ||
+		fade.PlayState != PlayStateRunning {
+		t.Errorf("unexpected fade animation %+v", fade)
+	}
+}
+
+func TestParseTransitions(t *testing.T) {
+	s := parseStyleSheet(`div {
+		transition: background-color 300ms ease-in, width 1s steps(2) 0.5s;
+		transition-delay: 0s;
+	}`)
+	all, err := ParseTransitions(s.Groups[0].Rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 transitions but got %d", len(all))
+	}
+	if all[0].Property != "background-color" || all[0].Duration != 0.3 || all[0].Timing != EaseIn {
+		t.Errorf("unexpected transition %+v", all[0])
+	}
+	if all[1].Property != "width" || all[1].Duration != 1 || all[1].Delay != 0 {
+		t.Errorf("unexpected transition %+v", all[1])
+	}
+	if err = ValidateTransition("transition-duration", []rules.PropertyValue{value("fast")}); err == nil {
+		t.Error("expected an error for an invalid duration")
+	}
+}
+
+func TestParseKeyframes(t *testing.T) {
+	s := parseStyleSheet(`
+		div { width: 10px; }
+		@keyframes grow {
+			from { width: 10px; }
+			50%, 75% { width: 20px; }
+			to { width: 40px; }
+		}
+		p { height: 5px; }`)
+	if len(s.Groups) != 2 {
+		t.Fatalf("expected the keyframes to not create selector groups, got %d groups", len(s.Groups))
+	}
+	kf, ok := s.Keyframes["grow"]
+	if !ok {
+		t.Fatal("expected the grow keyframes")
+	}
+	offsets := []float64{0, 0.5, 0.75, 1}
+	if len(kf.Frames) != len(offsets) {
+		t.Fatalf("expected %d frames but got %d", len(offsets), len(kf.Frames))
+	}
+	for i := range offsets {
+		expectNear(t, kf.Frames[i].Offset, offsets[i])
+		if len(kf.Frames[i].Rules) != 1 {
+			t.Errorf("expected frame %d to have 1 rule", i)
+		}
+	}
+}
+
+func TestSampleKeyframes(t *testing.T) {
+	s := parseStyleSheet(`@keyframes slide {
+		50% { transform: translate(100px, 0px); width: 20px; }
+		to { width: 40px; }
+	}`)
+	in := Interpolator{}
+	base := map[string][]rules.PropertyValue{
+		"transform": {value("translate", "0px", "0px")},
+		"width":     {value("10px")},
+	}
+	out := map[string][]rules.PropertyValue{}
+	in.SampleKeyframes(s.Keyframes["slide"], 0.25, Linear, base, out)
+	expectValues(t, out["transform"], value("translate", "50px", "0px"))
+	expectValues(t, out["width"], value("15px"))
+	in.SampleKeyframes(s.Keyframes["slide"], 0.75, Linear, base, out)
+	expectValues(t, out["transform"], value("translate", "50px", "0px"))
+	expectValues(t, out["width"], value("30px"))
+}
+
+func TestPlayerTransition(t *testing.T) {
+	s := parseStyleSheet(`
+		div { width: 10px; transition: width 1s linear; }
+		div:hover { width: 20px; }`)
+	base, hover := s.Groups[0].Rules, s.Groups[1].Rules
+	p := NewPlayer(s.Keyframes, Interpolator{})
+	if err := p.SetStyle(base); err != nil {
+		t.Fatal(err)
+	}
+	if p.Playing() {
+		t.Error("expected the first style to not transition")
+	}
+	p.SetStyle(append(base, hover...))
+	if !p.Update(0.25) {
+		t.Fatal("expected the transition to be playing")
+	}
+	v, _ := p.Value("width")
+	expectValues(t, v, value("12.5px"))
+	// Reversing part way through starts from the value being shown
+	p.SetStyle(base)
+	p.Update(0.5)
+	v, _ = p.Value("width")
+	expectValues(t, v, value("11.25px"))
+	if p.Update(0.5) {
+		t.Error("expected the transition to have finished")
+	}
+	v, _ = p.Value("width")
+	expectValues(t, v, value("10px"))
+}
+
+func TestPlayerAnimation(t *testing.T) {
+	s := parseStyleSheet(`
+		@keyframes pulse { to { height: 30px; } }
+		div { height: 10px; animation: pulse 2s linear forwards; }`)
+	style := s.Groups[0].Rules
+	p := NewPlayer(s.Keyframes, Interpolator{})
+	p.SetStyle(style)
+	p.Update(1)
+	applied := p.Apply(style)
+	if applied[0].Property != "height" {
+		t.Fatalf("expected the height rule first but got %s", applied[0].Property)
+	}
+	expectValues(t, applied[0].Values, value("20px"))
+	if p.Update(1.5) {
+		t.Error("expected the animation to have finished")
+	}
+	v, _ := p.Value("height")
+	expectValues(t, v, value("30px"))
+	// Restyling with the same animation keeps its progress
+	p.SetStyle(style)
+	v, _ = p.Value("height")
+	expectValues(t, v, value("30px"))
+}
@@ -0,0 +1,181 @@
+/******************************************************************************/
+/* interpolate.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package animation
+
+import (
+	"kaiju/engine/ui/markup/css/rules"
+	"kaiju/matrix"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Interpolator blends property values between two states. Colors, numbers,
+// lengths with matching units and the arguments of matching functions (like
+// the functions of a transform) are blended, any other value switches from
+// the first to the second value halfway through.
+type Interpolator struct {
+	// NamedColors maps a color name (red, blue, etc.) to its hex string so
+	// that named colors can be blended with each other and with hex colors
+	NamedColors map[string]string
+}
+
+// Interpolate blends the values of a property, t is the eased progress where 0
+// is from and 1 is to. Values of t outside of 0 to 1 (from an overshooting
+// timing function) extrapolate numbers and lengths.
+func (in Interpolator) Interpolate(from, to []rules.PropertyValue, t float64) []rules.PropertyValue {
+	if len(from) != len(to) {
+		return cloneValues(discrete(from, to, t))
+	}
+	out := make([]rules.PropertyValue, len(from))
+	for i := range from {
+		out[i] = in.value(from[i], to[i], t)
+	}
+	return out
+}
+
+func (in Interpolator) value(a, b rules.PropertyValue, t float64) rules.PropertyValue {
+	if a.IsFunction() || b.IsFunction() {
+		if a.Str != b.Str || len(a.Args) != len(b.Args) {
+			v := discrete(a, b, t)
+			return v.Clone()
+		}
+		out := rules.PropertyValue{Str: a.Str, Args: make([]string, len(a.Args))}
+		for i := range a.Args {
+			out.Args[i] = in.token(a.Args[i], b.Args[i], t)
+		}
+		return out
+	}
+	return rules.PropertyValue{Str: in.token(a.Str, b.Str, t), Args: []string{}}
+}
+
+func (in Interpolator) token(a, b string, t float64) string {
+	if a == b {
+		return a
+	}
+	if ca, ok := in.color(a); ok {
+		if cb, ok := in.color(b); ok {
+			return mixColor(ca, cb, t).Hex()
+		}
+	}
+	na, ua, okA := splitNumber(a)
+	nb, ub, okB := splitNumber(b)
+	if okA && okB {
+		// A unitless zero takes on the unit of the other length
+		if ua == "" && na == 0 {
+			ua = ub
+		} else if ub == "" && nb == 0 {
+			ub = ua
+		}
+		if ua == ub {
+			return formatNumber(na+(nb-na)*t) + ua
+		}
+	}
+	return discrete(a, b, t)
+}
+
+func (in Interpolator) color(str string) (matrix.Color8, bool) {
+	if !strings.HasPrefix(str, "#") {
+		hex, ok := in.NamedColors[strings.ToLower(str)]
+		if !ok {
+			return matrix.Color8{}, false
+		}
+		str = hex
+	}
+	c, err := matrix.Color8FromHexString(str)
+	return c, err == nil
+}
+
+func mixColor(a, b matrix.Color8, t float64) matrix.Color8 {
+	mix := func(x, y uint8) uint8 {
+		v := math.Round(float64(x) + (float64(y)-float64(x))*t)
+		return uint8(max(0, min(255, v)))
+	}
+	return matrix.Color8{
+		R: mix(a.R, b.R),
+		G: mix(a.G, b.G),
+		B: mix(a.B, b.B),
+		A: mix(a.A, b.A),
+	}
+}
+
+// splitNumber separates a number from its unit, "12.5px" is 12.5 and "px"
+func splitNumber(str string) (float64, string, bool) {
+	end := 0
+	for end < len(str) && strings.IndexByte("+-.0123456789", str[end]) >= 0 {
+		end++
+	}
+	if end == 0 {
+		return 0, "", false
+	}
+	n, err := strconv.ParseFloat(str[:end], 64)
+	if err != nil {
+		return 0, "", false
+	}
+	unit := str[end:]
+	for i := 0; i < len(unit); i++ {
+		c := unit[i]
+		if !(c == '%' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')) {
+			return 0, "", false
+		}
+	}
+	return n, unit, true
+}
+
+func formatNumber(v float64) string {
+	v = math.Round(v*10000) / 10000
+	if v == 0 {
+		v = 0 // Drop the sign of negative zero
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func discrete[T any](a, b T, t float64) T {
+	if t < 0.5 {
+		return a
+	}
+	return b
+}
+
+func cloneValues(values []rules.PropertyValue) []rules.PropertyValue {
+	out := make([]rules.PropertyValue, len(values))
+	for i := range values {
+		out[i] = values[i].Clone()
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+/******************************************************************************/
+/* keyframes.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package animation
+
+import (
+	"kaiju/engine/ui/markup/css/rules"
+	"slices"
+	"strings"
+)
+
+type keyframePoint struct {
+	offset float64
+	values []rules.PropertyValue
+	timing Timing
+}
+
+// IsAnimationProperty returns true for the properties that configure
+// transitions and animations, these are never animated themselves
+func IsAnimationProperty(property string) bool {
+	return property == "transition" || property == "animation" ||
+		strings.HasPrefix(property, "transition-") || strings.HasPrefix(property, "animation-")
+}
+
+// SampleKeyframes writes the value of each property animated by the
+// keyframes at the given progress (0 to 1) into out. The timing is used
+// between keyframes unless a keyframe sets its own animation-timing-function.
+// When the keyframes have no frame at 0% or 100% for a property, the value in
+// base is used in its place, much like the element's own style is in CSS.
+func (in Interpolator) SampleKeyframes(kf rules.Keyframes, progress float64, timing Timing, base, out map[string][]rules.PropertyValue) {
+	frames := slices.Clone(kf.Frames)
+	slices.SortStableFunc(frames, func(a, b rules.Keyframe) int {
+		if a.Offset < b.Offset {
+			return -1
+		} else if a.Offset > b.Offset {
+			return 1
+		}
+		return 0
+	})
+	points := map[string][]keyframePoint{}
+	order := []string{}
+	for _, f := range frames {
+		frameTiming := timing
+		for _, r := range f.Rules {
+			if r.Property == "animation-timing-function" && len(r.Values) == 1 {
+				if t, err := ParseTiming(r.Values[0]); err == nil {
+					frameTiming = t
+				}
+			}
+		}
+		for _, r := range f.Rules {
+			if IsAnimationProperty(r.Property) {
+				continue
+			}
+			if _, ok := points[r.Property]; !ok {
+				order = append(order, r.Property)
+			}
+			points[r.Property] = append(points[r.Property], keyframePoint{
+				offset: f.Offset,
+				values: r.Values,
+				timing: frameTiming,
+			})
+		}
+	}
+	for _, prop := range order {
+		pts := points[prop]
+		if b, ok := base[prop]; ok {
+			if pts[0].offset > 0 {
+				pts = slices.Insert(pts, 0, keyframePoint{0, b, timing})
+			}
+			if pts[len(pts)-1].offset < 1 {
+				pts = append(pts, keyframePoint{1, b, timing})
+			}
+		}
+		out[prop] = samplePoints(in, pts, progress)
+	}
+}
+
+func samplePoints(in Interpolator, pts []keyframePoint, progress float64) []rules.PropertyValue {
+	if progress <= pts[0].offset {
+		return cloneValues(pts[0].values)
+	}
+	last := pts[len(pts)-1]
+	if progress >= last.offset {
+		return cloneValues(last.values)
+	}
+	i := 0
+	for i+1 < len(pts) && pts[i+1].offset <= progress {
+		i++
+	}
+	a, b := pts[i], pts[i+1]
+	local := (progress - a.offset) / (b.offset - a.offset)
+	return in.Interpolate(a.values, b.values, a.timing.Apply(local))
+}
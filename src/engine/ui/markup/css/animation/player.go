@@ -0,0 +1,248 @@
+/******************************************************************************/
+/* player.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package animation
+
+import (
+	"kaiju/engine/ui/markup/css/rules"
+	"slices"
+)
+
+type runningTransition struct {
+	Transition
+	from    []rules.PropertyValue
+	to      []rules.PropertyValue
+	elapsed float64
+}
+
+type runningAnimation struct {
+	Animation
+	elapsed float64
+}
+
+// Player tracks the transitions and animations of a single element. The
+// element's style is given to #Player.SetStyle each time it changes (like when
+// the element is hovered) and #Player.Update moves time forward, the values
+// to show are then read back from #Player.Apply.
+type Player struct {
+	keyframes    map[string]rules.Keyframes
+	interpolator Interpolator
+	style        map[string][]rules.PropertyValue
+	shown        map[string][]rules.PropertyValue
+	transitions  []Transition
+	running      map[string]*runningTransition
+	animations   []*runningAnimation
+}
+
+func NewPlayer(keyframes map[string]rules.Keyframes, interpolator Interpolator) *Player {
+	return &Player{
+		keyframes:    keyframes,
+		interpolator: interpolator,
+		shown:        make(map[string][]rules.PropertyValue),
+		running:      make(map[string]*runningTransition),
+	}
+}
+
+// HasEffects returns true if the style declares any transitions or
+// animations, elements without them have no need for a player
+func HasEffects(style []rules.Rule) bool {
+	for i := range style {
+		if IsAnimationProperty(style[i].Property) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetStyle gives the player the rules the element is now styled with, in the
+// order they apply. Properties that changed value and have a transition start
+// transitioning from the value that is currently shown. Animations that were
+// already playing keep their progress, new ones start from the beginning.
+func (p *Player) SetStyle(style []rules.Rule) error {
+	transitions, err := ParseTransitions(style)
+	if err != nil {
+		return err
+	}
+	animations, err := ParseAnimations(style)
+	if err != nil {
+		return err
+	}
+	next := make(map[string][]rules.PropertyValue)
+	for i := range style {
+		if !IsAnimationProperty(style[i].Property) {
+			next[style[i].Property] = style[i].Values
+		}
+	}
+	if p.style != nil {
+		for prop := range p.running {
+			if _, ok := next[prop]; !ok {
+				delete(p.running, prop)
+			}
+		}
+		for prop, to := range next {
+			from, ok := p.shown[prop]
+			t, hasTransition := lookupTransition(transitions, prop)
+			if !ok || !hasTransition || t.Duration+t.Delay <= 0 || equalValues(from, to) {
+				delete(p.running, prop)
+				continue
+			}
+			if r, ok := p.running[prop]; ok && equalValues(r.to, to) {
+				continue
+			}
+			p.running[prop] = &runningTransition{
+				Transition: t,
+				from:       cloneValues(from),
+				to:         to,
+			}
+		}
+	}
+	p.style = next
+	p.transitions = transitions
+	p.setAnimations(animations)
+	p.sample()
+	return nil
+}
+
+func (p *Player) setAnimations(animations []Animation) {
+	runs := make([]*runningAnimation, 0, len(animations))
+	for _, a := range animations {
+		if _, ok := p.keyframes[a.Name]; !ok {
+			continue
+		}
+		run := &runningAnimation{Animation: a}
+		for i, old := range p.animations {
+			if old != nil && old.Name == a.Name {
+				run.elapsed = old.elapsed
+				p.animations[i] = nil
+				break
+			}
+		}
+		runs = append(runs, run)
+	}
+	p.animations = runs
+}
+
+// Update moves the transitions and animations forward by dt seconds and
+// returns true if any of them are still playing
+func (p *Player) Update(dt float64) bool {
+	for prop, r := range p.running {
+		r.elapsed += dt
+		if r.elapsed >= r.Delay+r.Duration {
+			delete(p.running, prop)
+		}
+	}
+	for _, a := range p.animations {
+		if a.PlayState == PlayStateRunning {
+			a.elapsed += dt
+		}
+	}
+	p.sample()
+	return p.Playing()
+}
+
+// Playing returns true while there is a transition or a running animation
+// that has not yet finished
+func (p *Player) Playing() bool {
+	if len(p.running) > 0 {
+		return true
+	}
+	for _, a := range p.animations {
+		if a.PlayState == PlayStateRunning && !a.Finished(a.elapsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the value of the property that is currently shown
+func (p *Player) Value(property string) ([]rules.PropertyValue, bool) {
+	v, ok := p.shown[property]
+	return v, ok
+}
+
+// Apply returns a copy of the style rules with the currently shown values in
+// place of the styled ones. Properties that are only set by an animation are
+// added to the end of the rules.
+func (p *Player) Apply(style []rules.Rule) []rules.Rule {
+	out := make([]rules.Rule, 0, len(style))
+	seen := make(map[string]bool, len(style))
+	for i := range style {
+		r := style[i].Clone()
+		if v, ok := p.shown[r.Property]; ok {
+			r.Values = cloneValues(v)
+		}
+		seen[r.Property] = true
+		out = append(out, r)
+	}
+	extra := make([]string, 0)
+	for prop := range p.shown {
+		if !seen[prop] {
+			extra = append(extra, prop)
+		}
+	}
+	slices.Sort(extra)
+	for _, prop := range extra {
+		out = append(out, rules.Rule{
+			Property: prop,
+			Values:   cloneValues(p.shown[prop]),
+		})
+	}
+	return out
+}
+
+func (p *Player) sample() {
+	shown := make(map[string][]rules.PropertyValue, len(p.style))
+	for prop, v := range p.style {
+		shown[prop] = v
+	}
+	for _, a := range p.animations {
+		if progress, active := a.Progress(a.elapsed); active {
+			p.interpolator.SampleKeyframes(p.keyframes[a.Name], progress, a.Timing, p.style, shown)
+		}
+	}
+	for prop, r := range p.running {
+		t := r.Timing.Apply(r.Progress(r.elapsed))
+		shown[prop] = p.interpolator.Interpolate(r.from, r.to, t)
+	}
+	p.shown = shown
+}
+
+func equalValues(a, b []rules.PropertyValue) bool {
+	return slices.EqualFunc(a, b, func(x, y rules.PropertyValue) bool {
+		return x.Str == y.Str && slices.Equal(x.Args, y.Args)
+	})
+}
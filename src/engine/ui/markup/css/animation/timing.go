@@ -0,0 +1,258 @@
+/******************************************************************************/
+/* timing.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package animation
+
+import (
+	"errors"
+	"fmt"
+	"kaiju/engine/ui/markup/css/rules"
+	"math"
+	"strconv"
+)
+
+// StepPosition is where the jumps happen for a steps() timing function
+type StepPosition int
+
+const (
+	JumpEnd StepPosition = iota
+	JumpStart
+	JumpNone
+	JumpBoth
+)
+
+// Timing is an easing curve that maps the linear progress of a transition or
+// animation (0 to 1) into the eased progress. The zero value is linear.
+type Timing struct {
+	bezier   bool
+	x1, y1   float64
+	x2, y2   float64
+	steps    int
+	position StepPosition
+}
+
+var (
+	Linear    = Timing{}
+	Ease      = CubicBezier(0.25, 0.1, 0.25, 1)
+	EaseIn    = CubicBezier(0.42, 0, 1, 1)
+	EaseOut   = CubicBezier(0, 0, 0.58, 1)
+	EaseInOut = CubicBezier(0.42, 0, 0.58, 1)
+	StepStart = Steps(1, JumpStart)
+	StepEnd   = Steps(1, JumpEnd)
+)
+
+var namedTimings = map[string]Timing{
+	"linear":      Linear,
+	"ease":        Ease,
+	"ease-in":     EaseIn,
+	"ease-out":    EaseOut,
+	"ease-in-out": EaseInOut,
+	"step-start":  StepStart,
+	"step-end":    StepEnd,
+}
+
+var stepPositions = map[string]StepPosition{
+	"jump-start": JumpStart,
+	"start":      JumpStart,
+	"jump-end":   JumpEnd,
+	"end":        JumpEnd,
+	"jump-none":  JumpNone,
+	"jump-both":  JumpBoth,
+}
+
+// CubicBezier creates a timing curve with the control points (x1, y1) and
+// (x2, y2), the end points are fixed at (0, 0) and (1, 1)
+func CubicBezier(x1, y1, x2, y2 float64) Timing {
+	return Timing{bezier: true, x1: x1, y1: y1, x2: x2, y2: y2}
+}
+
+// Steps creates a timing curve that jumps between n equal steps
+func Steps(n int, position StepPosition) Timing {
+	return Timing{steps: max(n, 1), position: position}
+}
+
+// IsTiming returns true if the value can be read by #ParseTiming
+func IsTiming(value rules.PropertyValue) bool {
+	if value.IsFunction() {
+		return value.Str == "cubic-bezier" || value.Str == "steps"
+	}
+	_, ok := namedTimings[value.Str]
+	return ok
+}
+
+// ParseTiming reads a timing function keyword or a cubic-bezier() or steps()
+// function value
+func ParseTiming(value rules.PropertyValue) (Timing, error) {
+	switch value.Str {
+	case "cubic-bezier":
+		if len(value.Args) != 4 {
+			return Linear, errors.New("cubic-bezier expects 4 values")
+		}
+		var p [4]float64
+		for i := range p {
+			v, err := strconv.ParseFloat(value.Args[i], 64)
+			if err != nil {
+				return Linear, fmt.Errorf("invalid cubic-bezier value: %s", value.Args[i])
+			}
+			p[i] = v
+		}
+		if p[0] < 0 || p[0] > 1 || p[2] < 0 || p[2] > 1 {
+			return Linear, errors.New("cubic-bezier x values must be in the range [0, 1]")
+		}
+		return CubicBezier(p[0], p[1], p[2], p[3]), nil
+	case "steps":
+		if len(value.Args) == 0 || len(value.Args) > 2 {
+			return Linear, errors.New("steps expects 1 or 2 values")
+		}
+		n, err := strconv.Atoi(value.Args[0])
+		if err != nil || n < 1 {
+			return Linear, fmt.Errorf("invalid number of steps: %s", value.Args[0])
+		}
+		pos := JumpEnd
+		if len(value.Args) == 2 {
+			var ok bool
+			if pos, ok = stepPositions[value.Args[1]]; !ok {
+				return Linear, fmt.Errorf("invalid step position: %s", value.Args[1])
+			}
+		}
+		if pos == JumpNone && n < 2 {
+			return Linear, errors.New("steps with jump-none requires at least 2 steps")
+		}
+		return Steps(n, pos), nil
+	}
+	if t, ok := namedTimings[value.Str]; ok {
+		return t, nil
+	}
+	return Linear, fmt.Errorf("invalid timing function: %s", value.Str)
+}
+
+// Apply returns the eased progress for the linear progress x
+func (t Timing) Apply(x float64) float64 {
+	if t.bezier {
+		return t.solveBezier(x)
+	} else if t.steps > 0 {
+		return t.step(x)
+	}
+	return x
+}
+
+func (t Timing) step(x float64) float64 {
+	n := float64(t.steps)
+	current := math.Floor(x * n)
+	if t.position == JumpStart || t.position == JumpBoth {
+		current++
+	}
+	if x >= 0 && current < 0 {
+		current = 0
+	}
+	jumps := n
+	switch t.position {
+	case JumpNone:
+		jumps = n - 1
+	case JumpBoth:
+		jumps = n + 1
+	}
+	if x <= 1 && current > jumps {
+		current = jumps
+	}
+	return current / jumps
+}
+
+func bezierCoordinate(t, p1, p2 float64) float64 {
+	// B(t) = 3(1-t)^2 t p1 + 3(1-t) t^2 p2 + t^3
+	it := 1 - t
+	return 3*it*it*t*p1 + 3*it*t*t*p2 + t*t*t
+}
+
+func bezierSlope(t, p1, p2 float64) float64 {
+	it := 1 - t
+	return 3*it*it*p1 + 6*it*t*(p2-p1) + 3*t*t*(1-p2)
+}
+
+func (t Timing) solveBezier(x float64) float64 {
+	if x <= 0 || x >= 1 {
+		// Outside of the curve the line continues along the end tangents
+		return t.extrapolate(x)
+	}
+	const epsilon = 1e-7
+	// Newton's method converges quickly for most curves, fall back to
+	// bisection when the slope is too flat to make progress
+	u := x
+	for range 8 {
+		diff := bezierCoordinate(u, t.x1, t.x2) - x
+		if math.Abs(diff) < epsilon {
+			return bezierCoordinate(u, t.y1, t.y2)
+		}
+		slope := bezierSlope(u, t.x1, t.x2)
+		if math.Abs(slope) < 1e-6 {
+			break
+		}
+		u -= diff / slope
+	}
+	lo, hi := 0.0, 1.0
+	u = x
+	for range 64 {
+		bx := bezierCoordinate(u, t.x1, t.x2)
+		if math.Abs(bx-x) < epsilon {
+			break
+		}
+		if bx < x {
+			lo = u
+		} else {
+			hi = u
+		}
+		u = (lo + hi) * 0.5
+	}
+	return bezierCoordinate(u, t.y1, t.y2)
+}
+
+func (t Timing) extrapolate(x float64) float64 {
+	if x <= 0 {
+		if t.x1 > 0 {
+			return x * t.y1 / t.x1
+		} else if t.y1 == 0 && t.x2 > 0 {
+			return x * t.y2 / t.x2
+		}
+		return 0
+	}
+	if t.x2 < 1 {
+		return 1 + (x-1)*(t.y2-1)/(t.x2-1)
+	} else if t.y2 == 1 && t.x1 < 1 {
+		return 1 + (x-1)*(t.y1-1)/(t.x1-1)
+	}
+	return 1
+}
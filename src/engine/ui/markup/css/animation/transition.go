@@ -0,0 +1,215 @@
+/******************************************************************************/
+/* transition.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package animation
+
+import (
+	"fmt"
+	"kaiju/engine/ui/markup/css/rules"
+)
+
+// Transition describes how changes to a property are blended over time, the
+// property "all" applies to every property. The duration and delay are in
+// seconds.
+type Transition struct {
+	Property string
+	Duration float64
+	Delay    float64
+	Timing   Timing
+}
+
+func DefaultTransition() Transition {
+	return Transition{
+		Property: "all",
+		Timing:   Ease,
+	}
+}
+
+// Applies returns true if changes to the given property use this transition
+func (t Transition) Applies(property string) bool {
+	return t.Property == "all" || t.Property == property
+}
+
+// Progress returns the linear progress (0 to 1) of the transition at the
+// given number of seconds since it started
+func (t Transition) Progress(elapsed float64) float64 {
+	if elapsed <= t.Delay {
+		return 0
+	} else if t.Duration <= 0 || elapsed >= t.Delay+t.Duration {
+		return 1
+	}
+	return (elapsed - t.Delay) / t.Duration
+}
+
+type transitionLists struct {
+	properties []string
+	durations  []float64
+	delays     []float64
+	timings    []Timing
+}
+
+func (l *transitionLists) reset(property string) {
+	switch property {
+	case "transition":
+		*l = transitionLists{}
+	case "transition-property":
+		l.properties = nil
+	case "transition-duration":
+		l.durations = nil
+	case "transition-delay":
+		l.delays = nil
+	case "transition-timing-function":
+		l.timings = nil
+	}
+}
+
+// ParseTransitions reads the transition and transition-* rules, in the order
+// they are given, into the transitions they describe. Much like the animation
+// shorthand, the transition shorthand starts a new transition when it finds a
+// value that was already set for the current one.
+func ParseTransitions(style []rules.Rule) ([]Transition, error) {
+	lists := transitionLists{}
+	for i := range style {
+		r := &style[i]
+		if isGlobalKeyword(r.Values) {
+			lists.reset(r.Property)
+			continue
+		}
+		var err error
+		switch r.Property {
+		case "transition":
+			var all []Transition
+			if all, err = parseTransitionShorthand(r.Values); err == nil {
+				lists = transitionLists{}
+				for _, t := range all {
+					lists.properties = append(lists.properties, t.Property)
+					lists.durations = append(lists.durations, t.Duration)
+					lists.delays = append(lists.delays, t.Delay)
+					lists.timings = append(lists.timings, t.Timing)
+				}
+			}
+		case "transition-property":
+			lists.properties = lists.properties[:0]
+			for _, v := range r.Values {
+				lists.properties = append(lists.properties, v.Str)
+			}
+		case "transition-duration":
+			lists.durations, err = parseList(r.Values, timeValue)
+		case "transition-delay":
+			lists.delays, err = parseList(r.Values, timeValue)
+		case "transition-timing-function":
+			lists.timings, err = parseList(r.Values, ParseTiming)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", r.Property, err)
+		}
+	}
+	out := make([]Transition, 0, len(lists.properties))
+	for i, prop := range lists.properties {
+		if prop == "none" {
+			continue
+		}
+		t := DefaultTransition()
+		t.Property = prop
+		t.Duration = pick(lists.durations, i, t.Duration)
+		t.Delay = pick(lists.delays, i, t.Delay)
+		t.Timing = pick(lists.timings, i, t.Timing)
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// ValidateTransition checks the values of one of the transition properties
+func ValidateTransition(property string, values []rules.PropertyValue) error {
+	_, err := ParseTransitions([]rules.Rule{{Property: property, Values: values}})
+	return err
+}
+
+func parseTransitionShorthand(values []rules.PropertyValue) ([]Transition, error) {
+	out := []Transition{}
+	var t *Transition
+	var times int
+	var hasProperty, hasTiming bool
+	next := func() {
+		out = append(out, DefaultTransition())
+		t = &out[len(out)-1]
+		times = 0
+		hasProperty, hasTiming = false, false
+	}
+	next()
+	for _, v := range values {
+		if d, ok := ParseTime(v.Str); ok && !v.IsFunction() {
+			if times == 2 {
+				next()
+			}
+			if times == 0 {
+				t.Duration = d
+			} else {
+				t.Delay = d
+			}
+			times++
+		} else if IsTiming(v) {
+			if hasTiming {
+				next()
+			}
+			timing, err := ParseTiming(v)
+			if err != nil {
+				return nil, err
+			}
+			t.Timing, hasTiming = timing, true
+		} else if !v.IsFunction() {
+			if hasProperty {
+				next()
+			}
+			t.Property, hasProperty = v.Str, true
+		} else {
+			return nil, fmt.Errorf("unexpected transition value: %s", v.Str)
+		}
+	}
+	return out, nil
+}
+
+// lookupTransition finds the transition for the property, later transitions
+// in the list take priority over earlier ones
+func lookupTransition(transitions []Transition, property string) (Transition, bool) {
+	for i := len(transitions) - 1; i >= 0; i-- {
+		if transitions[i].Applies(property) {
+			return transitions[i], true
+		}
+	}
+	return Transition{}, false
+}
@@ -0,0 +1,118 @@
+/******************************************************************************/
+/* animations.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package css
+
+import (
+	"kaiju/engine"
+	"kaiju/engine/ui/markup/css/animation"
+	"kaiju/engine/ui/markup/css/helpers"
+	"kaiju/engine/ui/markup/css/properties"
+	"kaiju/engine/ui/markup/css/rules"
+	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui"
+)
+
+var interpolator = animation.Interpolator{NamedColors: helpers.ColorMap}
+
+func setupAnimations(elm *document.Element, keyframes map[string]rules.Keyframes, host *engine.Host) {
+	if !animation.HasEffects(elm.StyleRules) {
+		stopAnimations(elm, host)
+		elm.Animations = nil
+		return
+	}
+	if elm.Animations == nil {
+		elm.Animations = &document.ElementAnimations{}
+		elm.UI.AddEvent(ui.EventTypeDestroy, func() { stopAnimations(elm, host) })
+	}
+	// A new player is made as the keyframes may have changed along with the
+	// style sheet, the animations of the new style start over
+	stopAnimations(elm, host)
+	elm.Animations.Player = animation.NewPlayer(keyframes, interpolator)
+}
+
+func stopAnimations(elm *document.Element, host *engine.Host) {
+	if elm.Animations != nil && elm.Animations.UpdateId != 0 {
+		host.UIUpdater.RemoveUpdate(elm.Animations.UpdateId)
+		elm.Animations.UpdateId = 0
+	}
+}
+
+// animateStyle gives the element's player the style it should now show and
+// starts driving it from the UI updater if anything began to play
+func animateStyle(elm *document.Element, style []rules.Rule, host *engine.Host) {
+	anim := elm.Animations
+	if anim == nil {
+		return
+	}
+	anim.Style = style
+	// The values were already checked when the properties were processed
+	anim.Player.SetStyle(style)
+	if anim.UpdateId != 0 || !anim.Player.Playing() {
+		return
+	}
+	anim.UpdateId = host.UIUpdater.AddUpdate(func(deltaTime float64) {
+		playing := anim.Player.Update(deltaTime)
+		elm.ClearStyleFunctions()
+		applyStyle(elm, anim.Style, host)
+		if !playing {
+			stopAnimations(elm, host)
+		}
+	})
+}
+
+// applyStyle processes the rules of a style, in order, on the element. While
+// the element has animations, the values being shown by them are processed in
+// place of the styled values.
+func applyStyle(elm *document.Element, style []rules.Rule, host *engine.Host) []error {
+	problems := make([]error, 0)
+	if elm.Animations != nil {
+		// The keyframes can hold var() and other functions that the styled
+		// values have already had resolved
+		var errs []error
+		style, errs = resolveStyle(elm, elm.Animations.Player.Apply(style))
+		problems = append(problems, errs...)
+	}
+	for i := range style {
+		if p, ok := properties.PropertyMap[style[i].Property]; ok {
+			if err := p.Process(elm.UIPanel, elm, style[i].Values, host); err != nil {
+				problems = append(problems, err)
+			}
+		}
+	}
+	return problems
+}
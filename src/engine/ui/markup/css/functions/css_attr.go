@@ -38,12 +38,35 @@
 package functions
 
 import (
-	"errors"
+	"fmt"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
+	"slices"
+	"strings"
 )
 
+var attrUnits = []string{"px", "em", "ex", "cm", "mm", "in", "pt", "pc", "%"}
+
+// attr(name type, fallback), when the type is a unit it is added to the value
+// of the attribute, the fallback is used if the element lacks the attribute
 func (f Attr) Process(panel *ui.Panel, elm *document.Element, value rules.PropertyValue) (string, error) {
-	return "", errors.New("not implemented")
+	if len(value.Args) == 0 {
+		return "", fmt.Errorf("attr() expects the name of an attribute")
+	}
+	name, rest := value.Args[0], value.Args[1:]
+	unit := ""
+	if len(rest) > 0 && (slices.Contains(attrUnits, rest[0]) ||
+		rest[0] == "string" || rest[0] == "number") {
+		unit, rest = rest[0], rest[1:]
+	}
+	if elm.HasAttribute(name) {
+		if slices.Contains(attrUnits, unit) {
+			return elm.Attribute(name) + unit, nil
+		}
+		return elm.Attribute(name), nil
+	} else if len(rest) > 0 {
+		return strings.Join(rest, " "), nil
+	}
+	return "", fmt.Errorf("the element does not have the attribute %s", name)
 }
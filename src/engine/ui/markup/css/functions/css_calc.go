@@ -40,80 +40,50 @@ package functions
 import (
 	"kaiju/engine/ui/markup/css/helpers"
 	"kaiju/engine/ui/markup/css/rules"
+	"kaiju/engine/ui/markup/css/values"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 	"strconv"
 	"strings"
 )
 
-type calcOp int
-
-const (
-	calcOpNone = calcOp(iota)
-	calcOpAdd
-	calcOpSub
-	calcOpMul
-	calcOpDiv
-)
-
-type calcEntry struct {
-	value float32
-	op    calcOp
-}
-
-func (f Calc) Process(panel *ui.Panel, elm *document.Element, value rules.PropertyValue) (string, error) {
-	prop := value.Args[len(value.Args)-1]
-	value.Args = value.Args[:len(value.Args)-1]
-	entries := make([]calcEntry, len(value.Args))
-	for i := range value.Args {
-		switch value.Args[i] {
-		case "+":
-			entries[i].op = calcOpAdd
-		case "-":
-			entries[i].op = calcOpSub
-		case "*":
-			entries[i].op = calcOpMul
-		case "/":
-			entries[i].op = calcOpDiv
-		default:
-			v := helpers.NumFromLength(value.Args[i], panel.Base().Host().Window)
-			if strings.HasSuffix(value.Args[i], "%") {
-				if prop == "width" {
-					pl := elm.Parent.Value().UI.Layout()
-					p := pl.Padding()
-					v *= pl.PixelSize().Width() - p.X() - p.Z()
-				} else if prop == "height" {
-					pl := elm.Parent.Value().UI.Layout()
-					p := pl.Padding()
-					v *= pl.PixelSize().Height() - p.Y() - p.W()
-				}
+// lengthResolver reads the operands of a math function. Percentages of width
+// and height are of the inner size of the parent, for other properties they
+// are left as a fraction.
+func lengthResolver(panel *ui.Panel, elm *document.Element, prop string) func(string) float64 {
+	return func(token string) float64 {
+		v := helpers.NumFromLength(token, panel.Base().Host().Window)
+		parent := elm.Parent.Value()
+		if strings.HasSuffix(token, "%") && parent != nil {
+			if prop == "width" {
+				pl := parent.UI.Layout()
+				p := pl.Padding()
+				v *= pl.PixelSize().Width() - p.X() - p.Z()
+			} else if prop == "height" {
+				pl := parent.UI.Layout()
+				p := pl.Padding()
+				v *= pl.PixelSize().Height() - p.Y() - p.W()
 			}
-			entries[i].value = v
-		}
-	}
-	// Go through and do all the multiply and divide
-	for i := range entries {
-		if entries[i].op == calcOpMul {
-			entries[i-1].value *= entries[i+1].value
-			entries = append(entries[:i], entries[i+2:]...)
-			i--
-		} else if entries[i].op == calcOpDiv {
-			entries[i-1].value /= entries[i+1].value
-			entries = append(entries[:i], entries[i+2:]...)
-			i--
 		}
+		return float64(v)
 	}
-	// Go through and do all the add and subtract
-	for i := 0; i < len(entries); i++ {
-		if entries[i].op == calcOpAdd {
-			entries[i-1].value += entries[i+1].value
-			entries = append(entries[:i], entries[i+2:]...)
-			i--
-		} else if entries[i].op == calcOpSub {
-			entries[i-1].value -= entries[i+1].value
-			entries = append(entries[:i], entries[i+2:]...)
-			i--
-		}
+}
+
+// mathFunction solves calc(), min() and max(), the last argument of the value
+// is the name of the property the function is being solved for
+func mathFunction(panel *ui.Panel, elm *document.Element, value rules.PropertyValue, name string) (string, error) {
+	prop := value.Args[len(value.Args)-1]
+	tokens := make([]string, 0, len(value.Args)+1)
+	tokens = append(tokens, name+"(")
+	tokens = append(tokens, value.Args[:len(value.Args)-1]...)
+	tokens = append(tokens, ")")
+	v, err := values.Evaluate(tokens, lengthResolver(panel, elm, prop))
+	if err != nil {
+		return "", err
 	}
-	return strconv.FormatFloat(float64(entries[0].value), 'f', 5, 32) + "px", nil
+	return strconv.FormatFloat(v, 'f', 5, 32) + "px", nil
+}
+
+func (f Calc) Process(panel *ui.Panel, elm *document.Element, value rules.PropertyValue) (string, error) {
+	return mathFunction(panel, elm, value, "calc")
 }
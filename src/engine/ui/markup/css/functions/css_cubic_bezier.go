@@ -38,12 +38,18 @@
 package functions
 
 import (
-	"errors"
+	"kaiju/engine/ui/markup/css/animation"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
+	"strings"
 )
 
+// cubic-bezier(x1, y1, x2, y2), the curve is evaluated by the animation
+// package, here it is checked and written back out as a timing function
 func (f CubicBezier) Process(panel *ui.Panel, elm *document.Element, value rules.PropertyValue) (string, error) {
-	return "", errors.New("not implemented")
+	if _, err := animation.ParseTiming(value); err != nil {
+		return "", err
+	}
+	return "cubic-bezier(" + strings.Join(value.Args, ", ") + ")", nil
 }
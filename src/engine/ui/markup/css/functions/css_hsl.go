@@ -38,12 +38,17 @@
 package functions
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
+	"kaiju/engine/ui/markup/css/values"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// hsl(h, s, l) or hsl(h s l / a), written back out as a hex color
 func (f Hsl) Process(panel *ui.Panel, elm *document.Element, value rules.PropertyValue) (string, error) {
-	return "", errors.New("not implemented")
+	c, err := values.HSL(value.Args)
+	if err != nil {
+		return "", err
+	}
+	return c.Hex(), nil
 }
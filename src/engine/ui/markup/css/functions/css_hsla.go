@@ -38,12 +38,16 @@
 package functions
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
+	"kaiju/engine/ui/markup/css/values"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
 func (f Hsla) Process(panel *ui.Panel, elm *document.Element, value rules.PropertyValue) (string, error) {
-	return "", errors.New("not implemented")
+	c, err := values.HSL(value.Args)
+	if err != nil {
+		return "", err
+	}
+	return c.Hex(), nil
 }
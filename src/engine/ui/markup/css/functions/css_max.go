@@ -38,12 +38,11 @@
 package functions
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
 func (f Max) Process(panel *ui.Panel, elm *document.Element, value rules.PropertyValue) (string, error) {
-	return "", errors.New("not implemented")
+	return mathFunction(panel, elm, value, "max")
 }
@@ -38,12 +38,18 @@
 package functions
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
+	"kaiju/engine/ui/markup/css/values"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// rgb(r, g, b) or rgb(r g b / a), the color is written back out as a hex
+// color so the properties and transitions can read it
 func (f Rgb) Process(panel *ui.Panel, elm *document.Element, value rules.PropertyValue) (string, error) {
-	return "", errors.New("not implemented")
+	c, err := values.RGB(value.Args)
+	if err != nil {
+		return "", err
+	}
+	return c.Hex(), nil
 }
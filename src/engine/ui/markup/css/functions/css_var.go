@@ -38,12 +38,19 @@
 package functions
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
+	"kaiju/engine/ui/markup/css/values"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
+	"strings"
 )
 
+// var(--name, fallback) is read from the custom properties the element has
+// or inherited, the fallback is used when the property is not defined
 func (f Var) Process(panel *ui.Panel, elm *document.Element, value rules.PropertyValue) (string, error) {
-	return "", errors.New("not implemented")
+	res, err := values.Substitute([]rules.PropertyValue{value}, elm.CustomProperty)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(values.Tokens(res), " "), nil
 }
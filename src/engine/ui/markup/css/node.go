@@ -0,0 +1,114 @@
+/******************************************************************************/
+/* node.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package css
+
+import (
+	"kaiju/engine/ui"
+	"kaiju/engine/ui/markup/css/pseudos"
+	"kaiju/engine/ui/markup/css/selector"
+	"kaiju/engine/ui/markup/document"
+	"strings"
+)
+
+var matcher = selector.Matcher{Pseudo: pseudos.Lookup}
+
+// elementNode is the document element as seen by the selector matcher
+type elementNode struct {
+	elm *document.Element
+}
+
+func (n elementNode) Tag() string { return strings.ToLower(n.elm.Data()) }
+
+func (n elementNode) Attribute(name string) (string, bool) {
+	return n.elm.Attribute(name), n.elm.HasAttribute(name)
+}
+
+func (n elementNode) Parent() selector.Node {
+	p := n.elm.Parent.Value()
+	if p == nil || !p.IsElement() {
+		return nil
+	}
+	return elementNode{p}
+}
+
+func (n elementNode) Children() []selector.Node {
+	out := make([]selector.Node, 0, len(n.elm.Children))
+	for _, c := range n.elm.Children {
+		if c.IsElement() {
+			out = append(out, elementNode{c})
+		}
+	}
+	return out
+}
+
+func (n elementNode) Text() string {
+	sb := strings.Builder{}
+	for _, c := range n.elm.Children {
+		if !c.IsElement() {
+			sb.WriteString(c.Data())
+		}
+	}
+	return sb.String()
+}
+
+func (n elementNode) HasState(state selector.State) bool {
+	u := n.elm.UI
+	switch state {
+	case selector.StateHover:
+		return u != nil && u.IsHovered()
+	case selector.StateActive:
+		return u != nil && u.IsDown()
+	case selector.StateFocus:
+		if u == nil {
+			return false
+		} else if u.IsType(ui.ElementTypeTextArea) {
+			return u.ToTextArea().IsFocused() || u.HasFocus()
+		} else if u.IsType(ui.ElementTypeInput) {
+			return u.ToInput().IsFocused() || u.HasFocus()
+		}
+		return u.HasFocus()
+	case selector.StateChecked:
+		if u != nil && u.IsType(ui.ElementTypeCheckbox) {
+			return u.ToCheckbox().IsChecked()
+		}
+		return n.elm.HasAttribute("checked") || n.elm.HasAttribute("selected")
+	case selector.StateDisabled:
+		return n.elm.HasAttribute("disabled")
+	}
+	return false
+}
@@ -39,14 +39,34 @@ package properties
 
 import (
 	"errors"
+	"fmt"
 	"kaiju/engine"
+	"kaiju/engine/ui/flex"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// stretch|center|flex-start|flex-end|space-between|space-around|space-evenly|initial|inherit
 func (p AlignContent) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("AlignContent not implemented")}
-
-	return problems[0]
+	if len(values) != 1 {
+		return errors.New("AlignContent expects 1 value")
+	}
+	c := panel.FlexContainer()
+	switch values[0].Str {
+	case "inherit":
+		if elm.Parent.Value() != nil && elm.Parent.Value().UIPanel != nil {
+			c.AlignContent = elm.Parent.Value().UIPanel.FlexContainer().AlignContent
+		}
+	case "initial", "normal":
+		c.AlignContent = flex.JustifyStretch
+	default:
+		j, ok := flexJustifies[values[0].Str]
+		if !ok {
+			return fmt.Errorf("AlignContent expected a valid value, but got: %s", values[0].Str)
+		}
+		c.AlignContent = j
+	}
+	panel.SetFlexContainer(c)
+	return nil
 }
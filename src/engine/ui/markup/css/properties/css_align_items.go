@@ -39,14 +39,54 @@ package properties
 
 import (
 	"errors"
+	"fmt"
 	"kaiju/engine"
+	"kaiju/engine/ui/flex"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
-func (p AlignItems) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("AlignItems not implemented")}
+var flexAligns = map[string]flex.Align{
+	"auto":       flex.AlignAuto,
+	"normal":     flex.AlignStretch,
+	"stretch":    flex.AlignStretch,
+	"center":     flex.AlignCenter,
+	"flex-start": flex.AlignStart,
+	"start":      flex.AlignStart,
+	"self-start": flex.AlignStart,
+	"flex-end":   flex.AlignEnd,
+	"end":        flex.AlignEnd,
+	"self-end":   flex.AlignEnd,
+	"baseline":   flex.AlignBaseline,
+}
 
-	return problems[0]
+// normal|stretch|center|flex-start|flex-end|start|end|baseline|initial|inherit
+func (p AlignItems) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
+	if len(values) != 1 {
+		return errors.New("AlignItems expects 1 value")
+	}
+	c := panel.FlexContainer()
+	switch values[0].Str {
+	case "inherit":
+		if elm.Parent.Value() != nil && elm.Parent.Value().UIPanel != nil {
+			c.AlignItems = elm.Parent.Value().UIPanel.FlexContainer().AlignItems
+		}
+	case "initial":
+		c.AlignItems = flex.AlignStretch
+	default:
+		a, ok := flexAligns[values[0].Str]
+		if !ok || a == flex.AlignAuto {
+			return fmt.Errorf("AlignItems expected a valid value, but got: %s", values[0].Str)
+		}
+		c.AlignItems = a
+	}
+	panel.SetFlexContainer(c)
+	g := panel.GridContainer()
+	g.AlignItems = gridAligns[values[0].Str]
+	if values[0].Str == "inherit" && elm.Parent.Value() != nil && elm.Parent.Value().UIPanel != nil {
+		g.AlignItems = elm.Parent.Value().UIPanel.GridContainer().AlignItems
+	}
+	panel.SetGridContainer(g)
+	return nil
 }
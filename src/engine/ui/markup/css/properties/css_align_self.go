@@ -39,14 +39,41 @@ package properties
 
 import (
 	"errors"
+	"fmt"
 	"kaiju/engine"
+	"kaiju/engine/ui/flex"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// auto|stretch|center|flex-start|flex-end|start|end|baseline|initial|inherit
 func (p AlignSelf) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("AlignSelf not implemented")}
-
-	return problems[0]
+	if len(values) != 1 {
+		return errors.New("AlignSelf expects 1 value")
+	}
+	layout := panel.Base().Layout()
+	item := layout.FlexItem()
+	switch values[0].Str {
+	case "inherit":
+		if elm.Parent.Value() != nil && elm.Parent.Value().UI != nil {
+			item.AlignSelf = elm.Parent.Value().UI.Layout().FlexItem().AlignSelf
+		}
+	case "initial":
+		item.AlignSelf = flex.AlignAuto
+	default:
+		a, ok := flexAligns[values[0].Str]
+		if !ok {
+			return fmt.Errorf("AlignSelf expected a valid value, but got: %s", values[0].Str)
+		}
+		item.AlignSelf = a
+	}
+	layout.SetFlexItem(item)
+	gridItem := layout.GridItem()
+	gridItem.AlignSelf = gridAligns[values[0].Str]
+	if values[0].Str == "inherit" && elm.Parent.Value() != nil && elm.Parent.Value().UI != nil {
+		gridItem.AlignSelf = elm.Parent.Value().UI.Layout().GridItem().AlignSelf
+	}
+	layout.SetGridItem(gridItem)
+	return nil
 }
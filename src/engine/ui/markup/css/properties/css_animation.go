@@ -38,15 +38,17 @@
 package properties
 
 import (
-	"errors"
 	"kaiju/engine"
+	"kaiju/engine/ui/markup/css/animation"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// name duration timing-function delay iteration-count direction fill-mode play-state
+//
+// The animation itself is played by the element's animation player when the
+// style is applied (see css.ApplyElementStyle), only the values are checked here
 func (p Animation) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("Animation not implemented")}
-
-	return problems[0]
+	return animation.ValidateAnimation(p.Key(), values)
 }
@@ -38,15 +38,14 @@
 package properties
 
 import (
-	"errors"
 	"kaiju/engine"
+	"kaiju/engine/ui/markup/css/animation"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// normal|reverse|alternate|alternate-reverse|initial|inherit
 func (p AnimationDirection) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("AnimationDirection not implemented")}
-
-	return problems[0]
+	return animation.ValidateAnimation(p.Key(), values)
 }
@@ -38,15 +38,14 @@
 package properties
 
 import (
-	"errors"
 	"kaiju/engine"
+	"kaiju/engine/ui/markup/css/animation"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// none|forwards|backwards|both|initial|inherit
 func (p AnimationFillMode) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("AnimationFillMode not implemented")}
-
-	return problems[0]
+	return animation.ValidateAnimation(p.Key(), values)
 }
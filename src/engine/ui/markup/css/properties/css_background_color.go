@@ -71,7 +71,7 @@ func (p BackgroundColor) Process(panel *ui.Panel, elm *document.Element, values
 	if hex == "inherit" {
 		if applyPanelColor {
 			pBase := panel.Base()
-			pBase.AddEvent(ui.EventTypeRender, func() {
+			elm.SetStyleEvent("background-color", ui.EventTypeRender, func() {
 				if pBase.Entity().Parent != nil {
 					p := ui.FirstPanelOnEntity(pBase.Entity().Parent)
 					panel.SetColor(p.Base().ShaderData().FgColor)
@@ -43,14 +43,42 @@ import (
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
+	"strings"
 )
 
 // block|inline|inline-block|flex|inline-flex|grid|inline-grid|flow-root|none|contents|block flex|block flow|block flow-root|block grid|inline flex|inline flow|inline flow-root|inline grid|table|table-row|list-item|inherit|initial|revert|revert-layer|unset
 func (p Display) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	switch values[0].Str {
+	keywords := make([]string, len(values))
+	for i := range values {
+		keywords[i] = values[i].Str
+	}
+	switch strings.Join(keywords, " ") {
 	case "none":
 		panel.Base().Hide()
 		return nil
+	case "flex", "inline-flex", "block flex", "inline flex":
+		panel.EnableFlex()
+		return nil
+	case "grid", "inline-grid", "block grid", "inline grid":
+		panel.EnableGrid()
+		return nil
+	case "block", "inline", "inline-block", "flow-root", "block flow",
+		"block flow-root", "inline flow", "inline flow-root",
+		"initial", "revert", "revert-layer", "unset":
+		panel.DisableFlex()
+		panel.DisableGrid()
+		return nil
+	case "inherit":
+		parent := elm.Parent.Value()
+		if parent != nil && parent.UIPanel != nil && parent.UIPanel.IsFlex() {
+			panel.EnableFlex()
+		} else if parent != nil && parent.UIPanel != nil && parent.UIPanel.IsGrid() {
+			panel.EnableGrid()
+		} else {
+			panel.DisableFlex()
+			panel.DisableGrid()
+		}
+		return nil
 	default:
 		return errors.New("Display not implemented")
 	}
@@ -39,14 +39,122 @@ package properties
 
 import (
 	"errors"
+	"fmt"
 	"kaiju/engine"
+	"kaiju/engine/ui/flex"
+	"kaiju/engine/ui/markup/css/helpers"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
+	"kaiju/matrix"
+	"strconv"
+	"strings"
 )
 
-func (p Flex) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("Flex not implemented")}
+func flexNumber(str string) (float32, error) {
+	v, err := strconv.ParseFloat(str, 32)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("expected a positive number but got: %s", str)
+	}
+	return float32(v), nil
+}
+
+func isFlexNumber(str string) bool {
+	_, err := strconv.ParseFloat(str, 32)
+	return err == nil
+}
 
-	return problems[0]
+// setFlexBasis sets the basis of the item, percentages are of the main size of
+// the container so they are updated as the container is laid out
+func setFlexBasis(panel *ui.Panel, elm *document.Element, str string, host *engine.Host) error {
+	layout := panel.Base().Layout()
+	item := layout.FlexItem()
+	switch str {
+	case "auto", "content", "initial":
+		item.Basis = flex.Auto
+	case "0":
+		item.Basis = 0
+	default:
+		if strings.HasSuffix(str, "%") {
+			percent := helpers.NumFromLength(str, host.Window)
+			elm.AddStyleFunction(func(l *ui.Layout) {
+				if l.Ui().Entity().IsRoot() {
+					return
+				}
+				parent := ui.FirstPanelOnEntity(l.Ui().Entity().Parent)
+				w, h := parent.Base().Layout().ContentSize()
+				size := w
+				if !parent.FlexContainer().Direction.IsRow() {
+					size = h
+				}
+				item := l.FlexItem()
+				if basis := size * percent; !matrix.Approx(item.Basis, basis) {
+					item.Basis = basis
+					l.SetFlexItem(item)
+				}
+			})
+			return nil
+		}
+		item.Basis = helpers.NumFromLength(str, host.Window)
+		if item.Basis <= 0 && !strings.HasPrefix(str, "0") {
+			return fmt.Errorf("invalid flex basis: %s", str)
+		}
+	}
+	layout.SetFlexItem(item)
+	return nil
+}
+
+// none|auto|initial|<grow> <shrink>? <basis>?
+func (p Flex) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
+	layout := panel.Base().Layout()
+	item := layout.FlexItem()
+	if len(values) == 0 || len(values) > 3 {
+		return errors.New("Flex expects 1-3 values")
+	}
+	switch values[0].Str {
+	case "none":
+		item.Grow, item.Shrink, item.Basis = 0, 0, flex.Auto
+		layout.SetFlexItem(item)
+		return nil
+	case "auto":
+		item.Grow, item.Shrink, item.Basis = 1, 1, flex.Auto
+		layout.SetFlexItem(item)
+		return nil
+	case "initial":
+		item.Grow, item.Shrink, item.Basis = 0, 1, flex.Auto
+		layout.SetFlexItem(item)
+		return nil
+	}
+	// A single number is the grow with a basis of 0
+	item.Grow, item.Shrink, item.Basis = 0, 1, 0
+	basis := ""
+	numbers := 0
+	for i := range values {
+		str := values[i].Str
+		if isFlexNumber(str) && numbers < 2 {
+			v, err := flexNumber(str)
+			if err != nil {
+				return err
+			}
+			if numbers == 0 {
+				item.Grow = v
+			} else {
+				item.Shrink = v
+			}
+			numbers++
+		} else if basis == "" {
+			basis = str
+		} else {
+			return fmt.Errorf("Flex has an unexpected value: %s", str)
+		}
+	}
+	// Only a basis grows the same as auto
+	if numbers == 0 {
+		item.Grow = 1
+	}
+	layout.SetFlexItem(item)
+	if basis != "" {
+		return setFlexBasis(panel, elm, basis, host)
+	}
+	return nil
 }
@@ -45,8 +45,19 @@ import (
 	"kaiju/engine/ui"
 )
 
+// number|auto|initial|inherit
 func (p FlexBasis) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("FlexBasis not implemented")}
-
-	return problems[0]
+	if len(values) != 1 {
+		return errors.New("FlexBasis expects 1 value")
+	}
+	if values[0].Str == "inherit" {
+		if elm.Parent.Value() != nil && elm.Parent.Value().UI != nil {
+			layout := panel.Base().Layout()
+			item := layout.FlexItem()
+			item.Basis = elm.Parent.Value().UI.Layout().FlexItem().Basis
+			layout.SetFlexItem(item)
+		}
+		return nil
+	}
+	return setFlexBasis(panel, elm, values[0].Str, host)
 }
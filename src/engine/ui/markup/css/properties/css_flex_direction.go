@@ -39,14 +39,37 @@ package properties
 
 import (
 	"errors"
+	"fmt"
 	"kaiju/engine"
+	"kaiju/engine/ui/flex"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
-func (p FlexDirection) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("FlexDirection not implemented")}
+var flexDirections = map[string]flex.Direction{
+	"row":            flex.DirectionRow,
+	"row-reverse":    flex.DirectionRowReverse,
+	"column":         flex.DirectionColumn,
+	"column-reverse": flex.DirectionColumnReverse,
+	"initial":        flex.DirectionRow,
+}
 
-	return problems[0]
+// row|row-reverse|column|column-reverse|initial|inherit
+func (p FlexDirection) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
+	if len(values) != 1 {
+		return errors.New("FlexDirection expects 1 value")
+	}
+	c := panel.FlexContainer()
+	if values[0].Str == "inherit" {
+		if elm.Parent.Value() != nil && elm.Parent.Value().UIPanel != nil {
+			c.Direction = elm.Parent.Value().UIPanel.FlexContainer().Direction
+		}
+	} else if d, ok := flexDirections[values[0].Str]; ok {
+		c.Direction = d
+	} else {
+		return fmt.Errorf("FlexDirection expected a valid value, but got: %s", values[0].Str)
+	}
+	panel.SetFlexContainer(c)
+	return nil
 }
@@ -39,14 +39,33 @@ package properties
 
 import (
 	"errors"
+	"fmt"
 	"kaiju/engine"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// flex-direction flex-wrap|initial|inherit
 func (p FlexFlow) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("FlexFlow not implemented")}
-
-	return problems[0]
+	if len(values) == 0 || len(values) > 2 {
+		return errors.New("FlexFlow expects 1 or 2 values")
+	}
+	for i := range values {
+		var err error
+		if _, ok := flexDirections[values[i].Str]; ok {
+			err = FlexDirection{}.Process(panel, elm, values[i:i+1], host)
+		} else if _, ok := flexWraps[values[i].Str]; ok {
+			err = FlexWrap{}.Process(panel, elm, values[i:i+1], host)
+		} else if values[i].Str == "inherit" {
+			FlexDirection{}.Process(panel, elm, values[i:i+1], host)
+			err = FlexWrap{}.Process(panel, elm, values[i:i+1], host)
+		} else {
+			err = fmt.Errorf("FlexFlow expected a valid value, but got: %s", values[i].Str)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
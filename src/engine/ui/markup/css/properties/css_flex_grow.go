@@ -45,8 +45,27 @@ import (
 	"kaiju/engine/ui"
 )
 
+// number|initial|inherit
 func (p FlexGrow) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("FlexGrow not implemented")}
-
-	return problems[0]
+	if len(values) != 1 {
+		return errors.New("FlexGrow expects 1 value")
+	}
+	layout := panel.Base().Layout()
+	item := layout.FlexItem()
+	switch values[0].Str {
+	case "initial":
+		item.Grow = 0
+	case "inherit":
+		if elm.Parent.Value() != nil && elm.Parent.Value().UI != nil {
+			item.Grow = elm.Parent.Value().UI.Layout().FlexItem().Grow
+		}
+	default:
+		v, err := flexNumber(values[0].Str)
+		if err != nil {
+			return err
+		}
+		item.Grow = v
+	}
+	layout.SetFlexItem(item)
+	return nil
 }
@@ -45,8 +45,27 @@ import (
 	"kaiju/engine/ui"
 )
 
+// number|initial|inherit
 func (p FlexShrink) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("FlexShrink not implemented")}
-
-	return problems[0]
+	if len(values) != 1 {
+		return errors.New("FlexShrink expects 1 value")
+	}
+	layout := panel.Base().Layout()
+	item := layout.FlexItem()
+	switch values[0].Str {
+	case "initial":
+		item.Shrink = 1
+	case "inherit":
+		if elm.Parent.Value() != nil && elm.Parent.Value().UI != nil {
+			item.Shrink = elm.Parent.Value().UI.Layout().FlexItem().Shrink
+		}
+	default:
+		v, err := flexNumber(values[0].Str)
+		if err != nil {
+			return err
+		}
+		item.Shrink = v
+	}
+	layout.SetFlexItem(item)
+	return nil
 }
@@ -39,14 +39,36 @@ package properties
 
 import (
 	"errors"
+	"fmt"
 	"kaiju/engine"
+	"kaiju/engine/ui/flex"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
-func (p FlexWrap) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("FlexWrap not implemented")}
+var flexWraps = map[string]flex.Wrap{
+	"nowrap":       flex.WrapNone,
+	"wrap":         flex.WrapWrap,
+	"wrap-reverse": flex.WrapReverse,
+	"initial":      flex.WrapNone,
+}
 
-	return problems[0]
+// nowrap|wrap|wrap-reverse|initial|inherit
+func (p FlexWrap) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
+	if len(values) != 1 {
+		return errors.New("FlexWrap expects 1 value")
+	}
+	c := panel.FlexContainer()
+	if values[0].Str == "inherit" {
+		if elm.Parent.Value() != nil && elm.Parent.Value().UIPanel != nil {
+			c.Wrap = elm.Parent.Value().UIPanel.FlexContainer().Wrap
+		}
+	} else if w, ok := flexWraps[values[0].Str]; ok {
+		c.Wrap = w
+	} else {
+		return fmt.Errorf("FlexWrap expected a valid value, but got: %s", values[0].Str)
+	}
+	panel.SetFlexContainer(c)
+	return nil
 }
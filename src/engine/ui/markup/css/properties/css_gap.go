@@ -45,8 +45,20 @@ import (
 	"kaiju/engine/ui"
 )
 
+// row-gap column-gap|initial|inherit
 func (p Gap) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("Gap not implemented")}
-
-	return problems[0]
+	switch len(values) {
+	case 1:
+		if err := (RowGap{}).Process(panel, elm, values, host); err != nil {
+			return err
+		}
+		return ColumnGap{}.Process(panel, elm, values, host)
+	case 2:
+		if err := (RowGap{}).Process(panel, elm, values[:1], host); err != nil {
+			return err
+		}
+		return ColumnGap{}.Process(panel, elm, values[1:], host)
+	default:
+		return errors.New("Gap expects 1 or 2 values")
+	}
 }
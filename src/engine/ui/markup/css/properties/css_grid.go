@@ -40,13 +40,66 @@ package properties
 import (
 	"errors"
 	"kaiju/engine"
+	"kaiju/engine/ui/grid"
+	"kaiju/engine/ui/markup/css/helpers"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
-func (p Grid) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("Grid not implemented")}
+var gridAligns = map[string]grid.Align{
+	"auto":       grid.AlignAuto,
+	"normal":     grid.AlignStretch,
+	"stretch":    grid.AlignStretch,
+	"center":     grid.AlignCenter,
+	"start":      grid.AlignStart,
+	"flex-start": grid.AlignStart,
+	"self-start": grid.AlignStart,
+	"left":       grid.AlignStart,
+	"baseline":   grid.AlignStart,
+	"end":        grid.AlignEnd,
+	"flex-end":   grid.AlignEnd,
+	"self-end":   grid.AlignEnd,
+	"right":      grid.AlignEnd,
+}
 
-	return problems[0]
+// gridTokens flattens the values of a grid property so functions like
+// repeat() and minmax() can be read in order along with their arguments
+func gridTokens(values []rules.PropertyValue) []string {
+	tokens := make([]string, 0, len(values))
+	for i := range values {
+		if values[i].IsFunction() {
+			tokens = append(tokens, values[i].Str+"(")
+			tokens = append(tokens, values[i].Args...)
+			tokens = append(tokens, ")")
+		} else {
+			tokens = append(tokens, values[i].Str)
+		}
+	}
+	return tokens
+}
+
+func gridLength(host *engine.Host) grid.LengthFunc {
+	return func(str string) float32 { return helpers.NumFromLength(str, host.Window) }
+}
+
+// splitGridValues splits the values on the "/" between them
+func splitGridValues(values []rules.PropertyValue) [][]rules.PropertyValue {
+	parts := [][]rules.PropertyValue{{}}
+	for i := range values {
+		if values[i].Str == "/" {
+			parts = append(parts, []rules.PropertyValue{})
+		} else {
+			parts[len(parts)-1] = append(parts[len(parts)-1], values[i])
+		}
+	}
+	return parts
+}
+
+// none|grid-template-rows / grid-template-columns|grid-template-areas|initial|inherit
+func (p Grid) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
+	if len(values) == 0 {
+		return errors.New("Grid expects at least 1 value")
+	}
+	return GridTemplate{}.Process(panel, elm, values, host)
 }
@@ -40,13 +40,50 @@ package properties
 import (
 	"errors"
 	"kaiju/engine"
+	"kaiju/engine/ui/grid"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// grid-row-start / grid-column-start / grid-row-end / grid-column-end|itemname|initial|inherit
 func (p GridArea) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridArea not implemented")}
-
-	return problems[0]
+	layout := panel.Base().Layout()
+	item := layout.GridItem()
+	if len(values) == 1 && values[0].Str == "inherit" {
+		if elm.Parent.Value() != nil && elm.Parent.Value().UI != nil {
+			item.Placement = elm.Parent.Value().UI.Layout().GridItem().Placement
+		}
+		layout.SetGridItem(item)
+		return nil
+	}
+	if len(values) == 1 && values[0].Str == "initial" {
+		item.Placement = grid.Placement{}
+		layout.SetGridItem(item)
+		return nil
+	}
+	lines, err := grid.ParseLines(gridTokens(values))
+	if err != nil {
+		return err
+	}
+	if len(lines) > 4 {
+		return errors.New("GridArea expects 1-4 lines")
+	}
+	// Sides that are left out use the area named by the first side, or auto
+	named := func(l grid.Line) grid.Line { return grid.Line{Area: l.Area} }
+	if len(lines) < 2 {
+		lines = append(lines, named(lines[0]))
+	}
+	if len(lines) < 3 {
+		lines = append(lines, named(lines[0]))
+	}
+	if len(lines) < 4 {
+		lines = append(lines, named(lines[1]))
+	}
+	item.Placement = grid.Placement{
+		RowStart: lines[0], ColumnStart: lines[1],
+		RowEnd: lines[2], ColumnEnd: lines[3],
+	}
+	layout.SetGridItem(item)
+	return nil
 }
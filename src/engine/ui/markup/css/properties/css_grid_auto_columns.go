@@ -40,13 +40,35 @@ package properties
 import (
 	"errors"
 	"kaiju/engine"
+	"kaiju/engine/ui/grid"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// auto|max-content|min-content|length|percentage|flex|minmax()|initial|inherit
 func (p GridAutoColumns) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridAutoColumns not implemented")}
-
-	return problems[0]
+	if len(values) == 0 {
+		return errors.New("GridAutoColumns expects at least 1 value")
+	}
+	c := panel.GridContainer()
+	switch values[0].Str {
+	case "initial":
+		c.AutoColumns = grid.Auto()
+	case "inherit":
+		if elm.Parent.Value() != nil && elm.Parent.Value().UIPanel != nil {
+			c.AutoColumns = elm.Parent.Value().UIPanel.GridContainer().AutoColumns
+		}
+	default:
+		tracks, err := grid.ParseTracks(gridTokens(values), gridLength(host))
+		if err != nil {
+			return err
+		}
+		if len(tracks) != 1 {
+			return errors.New("GridAutoColumns only supports a single track size")
+		}
+		c.AutoColumns = tracks[0]
+	}
+	panel.SetGridContainer(c)
+	return nil
 }
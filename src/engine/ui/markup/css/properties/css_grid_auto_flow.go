@@ -39,14 +39,41 @@ package properties
 
 import (
 	"errors"
+	"fmt"
 	"kaiju/engine"
+	"kaiju/engine/ui/grid"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// row|column|dense|row dense|column dense|initial|inherit
 func (p GridAutoFlow) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridAutoFlow not implemented")}
-
-	return problems[0]
+	if len(values) == 0 || len(values) > 2 {
+		return errors.New("GridAutoFlow expects 1 or 2 values")
+	}
+	c := panel.GridContainer()
+	if values[0].Str == "inherit" {
+		if elm.Parent.Value() != nil && elm.Parent.Value().UIPanel != nil {
+			parent := elm.Parent.Value().UIPanel.GridContainer()
+			c.Flow, c.Dense = parent.Flow, parent.Dense
+		}
+		panel.SetGridContainer(c)
+		return nil
+	}
+	c.Flow, c.Dense = grid.FlowRow, false
+	for i := range values {
+		switch values[i].Str {
+		case "row", "initial":
+			c.Flow = grid.FlowRow
+		case "column":
+			c.Flow = grid.FlowColumn
+		case "dense":
+			c.Dense = true
+		default:
+			return fmt.Errorf("GridAutoFlow expected a valid value, but got: %s", values[i].Str)
+		}
+	}
+	panel.SetGridContainer(c)
+	return nil
 }
@@ -40,13 +40,43 @@ package properties
 import (
 	"errors"
 	"kaiju/engine"
+	"kaiju/engine/ui/grid"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// grid-column-start / grid-column-end|initial|inherit
 func (p GridColumn) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridColumn not implemented")}
-
-	return problems[0]
+	layout := panel.Base().Layout()
+	item := layout.GridItem()
+	if len(values) == 1 && values[0].Str == "inherit" {
+		if elm.Parent.Value() != nil && elm.Parent.Value().UI != nil {
+			parent := elm.Parent.Value().UI.Layout().GridItem().Placement
+			item.Placement.ColumnStart, item.Placement.ColumnEnd = parent.ColumnStart, parent.ColumnEnd
+		}
+		layout.SetGridItem(item)
+		return nil
+	}
+	if len(values) == 1 && values[0].Str == "initial" {
+		item.Placement.ColumnStart, item.Placement.ColumnEnd = grid.Line{}, grid.Line{}
+		layout.SetGridItem(item)
+		return nil
+	}
+	lines, err := grid.ParseLines(gridTokens(values))
+	if err != nil {
+		return err
+	}
+	switch len(lines) {
+	case 1:
+		item.Placement.ColumnStart = lines[0]
+		// A named area on its own is used for both sides
+		item.Placement.ColumnEnd = grid.Line{Area: lines[0].Area}
+	case 2:
+		item.Placement.ColumnStart, item.Placement.ColumnEnd = lines[0], lines[1]
+	default:
+		return errors.New("GridColumn expects 1 or 2 lines")
+	}
+	layout.SetGridItem(item)
+	return nil
 }
@@ -38,15 +38,13 @@
 package properties
 
 import (
-	"errors"
 	"kaiju/engine"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// length|initial|inherit
 func (p GridColumnGap) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridColumnGap not implemented")}
-
-	return problems[0]
+	return ColumnGap{}.Process(panel, elm, values, host)
 }
@@ -38,15 +38,13 @@
 package properties
 
 import (
-	"errors"
 	"kaiju/engine"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// grid-row-gap grid-column-gap|initial|inherit
 func (p GridGap) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridGap not implemented")}
-
-	return problems[0]
+	return Gap{}.Process(panel, elm, values, host)
 }
@@ -38,15 +38,30 @@
 package properties
 
 import (
-	"errors"
 	"kaiju/engine"
+	"kaiju/engine/ui/grid"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// auto|span n|column-line|area|initial|inherit
 func (p GridRowEnd) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridRowEnd not implemented")}
-
-	return problems[0]
+	layout := panel.Base().Layout()
+	item := layout.GridItem()
+	if len(values) == 1 && values[0].Str == "inherit" {
+		if elm.Parent.Value() != nil && elm.Parent.Value().UI != nil {
+			item.Placement.RowEnd = elm.Parent.Value().UI.Layout().GridItem().Placement.RowEnd
+		}
+	} else if len(values) == 1 && values[0].Str == "initial" {
+		item.Placement.RowEnd = grid.Line{}
+	} else {
+		line, err := grid.ParseLine(gridTokens(values))
+		if err != nil {
+			return err
+		}
+		item.Placement.RowEnd = line
+	}
+	layout.SetGridItem(item)
+	return nil
 }
@@ -45,8 +45,31 @@ import (
 	"kaiju/engine/ui"
 )
 
+// none|grid-template-rows / grid-template-columns|grid-template-areas|initial|inherit
 func (p GridTemplate) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridTemplate not implemented")}
-
-	return problems[0]
+	if len(values) == 0 {
+		return errors.New("GridTemplate expects at least 1 value")
+	}
+	switch values[0].Str {
+	case "none", "initial", "inherit":
+		if err := (GridTemplateRows{}).Process(panel, elm, values[:1], host); err != nil {
+			return err
+		}
+		if err := (GridTemplateColumns{}).Process(panel, elm, values[:1], host); err != nil {
+			return err
+		}
+		return GridTemplateAreas{}.Process(panel, elm, values[:1], host)
+	}
+	// Only area strings
+	if isGridAreaString(values[0].Str) {
+		return GridTemplateAreas{}.Process(panel, elm, values, host)
+	}
+	parts := splitGridValues(values)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return errors.New("GridTemplate expects rows / columns")
+	}
+	if err := (GridTemplateRows{}).Process(panel, elm, parts[0], host); err != nil {
+		return err
+	}
+	return GridTemplateColumns{}.Process(panel, elm, parts[1], host)
 }
@@ -40,13 +40,44 @@ package properties
 import (
 	"errors"
 	"kaiju/engine"
+	"kaiju/engine/ui/grid"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
+	"strings"
 )
 
-func (p GridTemplateAreas) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridTemplateAreas not implemented")}
+func isGridAreaString(str string) bool {
+	return strings.HasPrefix(str, "\"") || strings.HasPrefix(str, "'")
+}
 
-	return problems[0]
+// none|itemnames|initial|inherit
+func (p GridTemplateAreas) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
+	if len(values) == 0 {
+		return errors.New("GridTemplateAreas expects at least 1 value")
+	}
+	c := panel.GridContainer()
+	switch values[0].Str {
+	case "none", "initial":
+		c.Areas = nil
+	case "inherit":
+		if elm.Parent.Value() != nil && elm.Parent.Value().UIPanel != nil {
+			c.Areas = elm.Parent.Value().UIPanel.GridContainer().Areas
+		}
+	default:
+		rows := make([]string, len(values))
+		for i := range values {
+			if !isGridAreaString(values[i].Str) {
+				return errors.New("GridTemplateAreas expects strings of area names")
+			}
+			rows[i] = values[i].Str
+		}
+		areas, _, _, err := grid.ParseAreas(rows)
+		if err != nil {
+			return err
+		}
+		c.Areas = areas
+	}
+	panel.SetGridContainer(c)
+	return nil
 }
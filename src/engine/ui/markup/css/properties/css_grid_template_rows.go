@@ -40,13 +40,32 @@ package properties
 import (
 	"errors"
 	"kaiju/engine"
+	"kaiju/engine/ui/grid"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// none|auto|max-content|min-content|length|percentage|flex|minmax()|repeat()|initial|inherit
 func (p GridTemplateRows) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridTemplateRows not implemented")}
-
-	return problems[0]
+	if len(values) == 0 {
+		return errors.New("GridTemplateRows expects at least 1 value")
+	}
+	c := panel.GridContainer()
+	switch values[0].Str {
+	case "none", "initial":
+		c.Rows = []grid.Track{}
+	case "inherit":
+		if elm.Parent.Value() != nil && elm.Parent.Value().UIPanel != nil {
+			c.Rows = elm.Parent.Value().UIPanel.GridContainer().Rows
+		}
+	default:
+		tracks, err := grid.ParseTracks(gridTokens(values), gridLength(host))
+		if err != nil {
+			return err
+		}
+		c.Rows = tracks
+	}
+	panel.SetGridContainer(c)
+	return nil
 }
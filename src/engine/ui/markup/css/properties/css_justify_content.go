@@ -39,14 +39,45 @@ package properties
 
 import (
 	"errors"
+	"fmt"
 	"kaiju/engine"
+	"kaiju/engine/ui/flex"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
-func (p JustifyContent) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("JustifyContent not implemented")}
+var flexJustifies = map[string]flex.Justify{
+	"flex-start":    flex.JustifyStart,
+	"start":         flex.JustifyStart,
+	"left":          flex.JustifyStart,
+	"normal":        flex.JustifyStart,
+	"flex-end":      flex.JustifyEnd,
+	"end":           flex.JustifyEnd,
+	"right":         flex.JustifyEnd,
+	"center":        flex.JustifyCenter,
+	"space-between": flex.JustifySpaceBetween,
+	"space-around":  flex.JustifySpaceAround,
+	"space-evenly":  flex.JustifySpaceEvenly,
+	"stretch":       flex.JustifyStretch,
+	"initial":       flex.JustifyStart,
+}
 
-	return problems[0]
+// flex-start|flex-end|center|space-between|space-around|space-evenly|initial|inherit
+func (p JustifyContent) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
+	if len(values) != 1 {
+		return errors.New("JustifyContent expects 1 value")
+	}
+	c := panel.FlexContainer()
+	if values[0].Str == "inherit" {
+		if elm.Parent.Value() != nil && elm.Parent.Value().UIPanel != nil {
+			c.Justify = elm.Parent.Value().UIPanel.FlexContainer().Justify
+		}
+	} else if j, ok := flexJustifies[values[0].Str]; ok {
+		c.Justify = j
+	} else {
+		return fmt.Errorf("JustifyContent expected a valid value, but got: %s", values[0].Str)
+	}
+	panel.SetFlexContainer(c)
+	return nil
 }
@@ -39,14 +39,34 @@ package properties
 
 import (
 	"errors"
+	"fmt"
 	"kaiju/engine"
+	"kaiju/engine/ui/grid"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// normal|stretch|center|start|end|left|right|baseline|initial|inherit
 func (p JustifyItems) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("JustifyItems not implemented")}
-
-	return problems[0]
+	if len(values) != 1 {
+		return errors.New("JustifyItems expects 1 value")
+	}
+	c := panel.GridContainer()
+	switch values[0].Str {
+	case "inherit":
+		if elm.Parent.Value() != nil && elm.Parent.Value().UIPanel != nil {
+			c.JustifyItems = elm.Parent.Value().UIPanel.GridContainer().JustifyItems
+		}
+	case "initial":
+		c.JustifyItems = grid.AlignStretch
+	default:
+		a, ok := gridAligns[values[0].Str]
+		if !ok || a == grid.AlignAuto {
+			return fmt.Errorf("JustifyItems expected a valid value, but got: %s", values[0].Str)
+		}
+		c.JustifyItems = a
+	}
+	panel.SetGridContainer(c)
+	return nil
 }
@@ -39,14 +39,35 @@ package properties
 
 import (
 	"errors"
+	"fmt"
 	"kaiju/engine"
+	"kaiju/engine/ui/grid"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// auto|normal|stretch|center|start|end|left|right|baseline|initial|inherit
 func (p JustifySelf) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("JustifySelf not implemented")}
-
-	return problems[0]
+	if len(values) != 1 {
+		return errors.New("JustifySelf expects 1 value")
+	}
+	layout := panel.Base().Layout()
+	item := layout.GridItem()
+	switch values[0].Str {
+	case "inherit":
+		if elm.Parent.Value() != nil && elm.Parent.Value().UI != nil {
+			item.JustifySelf = elm.Parent.Value().UI.Layout().GridItem().JustifySelf
+		}
+	case "initial":
+		item.JustifySelf = grid.AlignAuto
+	default:
+		a, ok := gridAligns[values[0].Str]
+		if !ok {
+			return fmt.Errorf("JustifySelf expected a valid value, but got: %s", values[0].Str)
+		}
+		item.JustifySelf = a
+	}
+	layout.SetGridItem(item)
+	return nil
 }
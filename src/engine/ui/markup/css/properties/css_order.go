@@ -39,14 +39,34 @@ package properties
 
 import (
 	"errors"
+	"strconv"
 	"kaiju/engine"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// number|initial|inherit
 func (p Order) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("Order not implemented")}
-
-	return problems[0]
+	if len(values) != 1 {
+		return errors.New("Order expects 1 value")
+	}
+	layout := panel.Base().Layout()
+	item := layout.FlexItem()
+	switch values[0].Str {
+	case "initial":
+		item.Order = 0
+	case "inherit":
+		if elm.Parent.Value() != nil && elm.Parent.Value().UI != nil {
+			item.Order = elm.Parent.Value().UI.Layout().FlexItem().Order
+		}
+	default:
+		order, err := strconv.Atoi(values[0].Str)
+		if err != nil {
+			return err
+		}
+		item.Order = order
+	}
+	layout.SetFlexItem(item)
+	return nil
 }
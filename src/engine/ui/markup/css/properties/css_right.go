@@ -68,7 +68,7 @@ func (p Right) Process(panel *ui.Panel, elm *document.Element, values []rules.Pr
 		default:
 			val := -helpers.NumFromLength(values[0].Str, host.Window)
 			if strings.HasSuffix(values[0].Str, "%") {
-				panel.Base().Layout().AddFunction(func(l *ui.Layout) {
+				elm.AddStyleFunction(func(l *ui.Layout) {
 					if l.Ui().Entity().IsRoot() {
 						return
 					}
@@ -40,13 +40,34 @@ package properties
 import (
 	"errors"
 	"kaiju/engine"
+	"kaiju/engine/ui/markup/css/helpers"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// length|normal|initial|inherit
 func (p RowGap) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("RowGap not implemented")}
-
-	return problems[0]
+	if len(values) != 1 {
+		return errors.New("RowGap expects 1 value")
+	}
+	var gap float32
+	switch values[0].Str {
+	case "normal", "initial":
+		gap = 0
+	case "inherit":
+		if elm.Parent.Value() != nil && elm.Parent.Value().UIPanel != nil {
+			gap = elm.Parent.Value().UIPanel.FlexContainer().RowGap
+		}
+	default:
+		gap = helpers.NumFromLength(values[0].Str, host.Window)
+	}
+	// The gap is shared by flex and grid layouts
+	c := panel.FlexContainer()
+	c.RowGap = gap
+	panel.SetFlexContainer(c)
+	g := panel.GridContainer()
+	g.RowGap = gap
+	panel.SetGridContainer(g)
+	return nil
 }
@@ -48,26 +48,30 @@ import (
 	"strings"
 )
 
-func translateXYZ(str string, panel *ui.Panel, host *engine.Host, vc matrix.VectorComponent) {
+// The x and y translation is kept in the local inner offset, separate from the
+// offsets of left, top, etc., and replaces the previous translation so that
+// reapplying the style (on hover or while animating) doesn't add up
+func translateXYZ(str string, panel *ui.Panel, elm *document.Element, host *engine.Host, vc matrix.VectorComponent) {
 	if vc == matrix.Vz {
 		p := panel.Base().Entity().Transform.Position()
 		p[vc] += helpers.NumFromLength(str, host.Window)
 		panel.Base().Entity().Transform.SetPosition(p)
 	} else {
-		offset := panel.Base().Layout().InnerOffset()
+		layout := panel.Base().Layout()
 		p := helpers.NumFromLength(str, host.Window)
 		if vc == matrix.Vy {
 			p *= -1.0
 		}
 		if strings.HasSuffix(str, "%") {
-			panel.Base().Layout().AddFunction(func(l *ui.Layout) {
+			elm.AddStyleFunction(func(l *ui.Layout) {
 				localInnerOffset := l.LocalInnerOffset()
 				localInnerOffset[vc] = l.PixelSize()[vc] * p
 				l.SetLocalInnerOffset(localInnerOffset.X(), localInnerOffset.Y(), localInnerOffset.Z(), localInnerOffset.W())
 			})
 		} else {
-			offset[vc] += p
-			panel.Base().Layout().SetInnerOffset(offset.X(), offset.Y(), offset.Z(), offset.W())
+			offset := layout.LocalInnerOffset()
+			offset[vc] = p
+			layout.SetLocalInnerOffset(offset.X(), offset.Y(), offset.Z(), offset.W())
 		}
 	}
 }
@@ -79,31 +83,34 @@ func (p Transform) Process(panel *ui.Panel, elm *document.Element, values []rule
 	}
 	switch values[0].Str {
 	case "none":
+		layout := panel.Base().Layout()
+		offset := layout.LocalInnerOffset()
+		layout.SetLocalInnerOffset(0, 0, offset.Z(), offset.W())
 	case "initial":
 	case "inherit":
 	case "matrix":
 	case "matrix3d":
 	case "translate":
 		if len(values[0].Args) == 2 {
-			translateXYZ(values[0].Args[0], panel, host, matrix.Vx)
-			translateXYZ(values[0].Args[1], panel, host, matrix.Vy)
+			translateXYZ(values[0].Args[0], panel, elm, host, matrix.Vx)
+			translateXYZ(values[0].Args[1], panel, elm, host, matrix.Vy)
 		} else {
 			return errors.New("translate expects 2 values")
 		}
 	case "translate3d":
 		if len(values[0].Args) == 3 {
-			translateXYZ(values[0].Args[0], panel, host, matrix.Vx)
-			translateXYZ(values[0].Args[1], panel, host, matrix.Vy)
-			translateXYZ(values[0].Args[2], panel, host, matrix.Vz)
+			translateXYZ(values[0].Args[0], panel, elm, host, matrix.Vx)
+			translateXYZ(values[0].Args[1], panel, elm, host, matrix.Vy)
+			translateXYZ(values[0].Args[2], panel, elm, host, matrix.Vz)
 		} else {
 			return errors.New("translate3d expects 3 values")
 		}
 	case "translateX":
-		translateXYZ(values[0].Args[0], panel, host, matrix.Vx)
+		translateXYZ(values[0].Args[0], panel, elm, host, matrix.Vx)
 	case "translateY":
-		translateXYZ(values[0].Args[0], panel, host, matrix.Vy)
+		translateXYZ(values[0].Args[0], panel, elm, host, matrix.Vy)
 	case "translateZ":
-		translateXYZ(values[0].Args[0], panel, host, matrix.Vz)
+		translateXYZ(values[0].Args[0], panel, elm, host, matrix.Vz)
 	case "scale":
 	case "scale3d":
 	case "scaleX":
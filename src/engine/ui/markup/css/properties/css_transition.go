@@ -38,15 +38,17 @@
 package properties
 
 import (
-	"errors"
 	"kaiju/engine"
+	"kaiju/engine/ui/markup/css/animation"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
+// property duration timing-function delay|initial|inherit
+//
+// Changes to the listed properties are blended by the element's animation
+// player (see css.ApplyElementStyle), only the values are checked here
 func (p Transition) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("Transition not implemented")}
-
-	return problems[0]
+	return animation.ValidateTransition(p.Key(), values)
 }
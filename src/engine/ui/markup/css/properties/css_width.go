@@ -64,7 +64,7 @@ func (p Width) Process(panel *ui.Panel, elm *document.Element, values []rules.Pr
 	}
 	if err == nil {
 		if strings.HasSuffix(values[0].Str, "%") && elm.Parent.Value() != nil {
-			panel.Base().Layout().AddFunction(func(l *ui.Layout) {
+			elm.AddStyleFunction(func(l *ui.Layout) {
 				if l.Ui().Entity().IsRoot() {
 					return
 				}
@@ -78,11 +78,15 @@ func (p Width) Process(panel *ui.Panel, elm *document.Element, values []rules.Pr
 				l.ScaleWidth(w)
 			})
 		} else if values[0].IsFunction() {
-			if values[0].Str == "calc" {
-				panel.Base().Layout().AddFunction(func(l *ui.Layout) {
+			// calc(), min() and max() are solved at layout as their
+			// percentages are of the size of the parent
+			switch values[0].Str {
+			case "calc", "min", "max":
+				f := functions.FunctionMap[values[0].Str]
+				elm.AddStyleFunction(func(l *ui.Layout) {
 					val := values[0]
 					val.Args = append(val.Args, "width")
-					res, _ := functions.Calc{}.Process(panel, elm, val)
+					res, _ := f.Process(panel, elm, val)
 					width = helpers.NumFromLength(res, host.Window)
 					l.ScaleWidth(width)
 				})
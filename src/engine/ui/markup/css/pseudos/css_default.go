@@ -40,9 +40,9 @@ package pseudos
 import (
 	"errors"
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p Default) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	return []*document.Element{elm}, errors.New("not implemented")
+func (p Default) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	return false, errors.New("not implemented")
 }
@@ -38,11 +38,19 @@
 package pseudos
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p Enabled) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	return []*document.Element{elm}, errors.New("not implemented")
+func (p Enabled) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	return canDisable(node) && !node.HasState(selector.StateDisabled), nil
+}
+
+// canDisable returns true for the elements that the disabled attribute applies to
+func canDisable(node selector.Node) bool {
+	switch node.Tag() {
+	case "button", "input", "select", "textarea", "option", "optgroup", "fieldset":
+		return true
+	}
+	return false
 }
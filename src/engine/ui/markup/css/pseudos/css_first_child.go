@@ -38,15 +38,11 @@
 package pseudos
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p FirstChild) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	if len(elm.Children) == 0 {
-		return []*document.Element{}, errors.New("no children")
-	} else {
-		return []*document.Element{elm.Children[0]}, nil
-	}
+func (p FirstChild) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	index, _ := selector.Position(node, false)
+	return index == 0, nil
 }
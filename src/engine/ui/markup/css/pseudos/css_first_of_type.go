@@ -38,11 +38,11 @@
 package pseudos
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p FirstOfType) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	return []*document.Element{elm}, errors.New("not implemented")
+func (p FirstOfType) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	index, _ := selector.Position(node, true)
+	return index == 0, nil
 }
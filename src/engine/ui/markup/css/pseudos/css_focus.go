@@ -38,11 +38,10 @@
 package pseudos
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p Focus) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	return []*document.Element{elm}, errors.New("not implemented")
+func (p Focus) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	return node.HasState(selector.StateFocus), nil
 }
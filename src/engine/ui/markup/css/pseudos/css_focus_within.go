@@ -38,11 +38,22 @@
 package pseudos
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p FocusWithin) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	return []*document.Element{elm}, errors.New("not implemented")
+func (p FocusWithin) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	return focusWithin(node), nil
+}
+
+func focusWithin(node selector.Node) bool {
+	if node.HasState(selector.StateFocus) {
+		return true
+	}
+	for _, c := range node.Children() {
+		if focusWithin(c) {
+			return true
+		}
+	}
+	return false
 }
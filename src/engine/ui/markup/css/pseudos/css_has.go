@@ -38,11 +38,19 @@
 package pseudos
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p Has) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	return []*document.Element{elm}, errors.New("not implemented")
+func (p Has) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	return hasDescendant(m, node, part.Selectors), nil
+}
+
+func hasDescendant(m selector.Matcher, node selector.Node, list []rules.Selector) bool {
+	for _, c := range node.Children() {
+		if m.MatchesAny(list, c) || hasDescendant(m, c, list) {
+			return true
+		}
+	}
+	return false
 }
@@ -39,16 +39,9 @@ package pseudos
 
 import (
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p Hover) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	return []*document.Element{elm}, nil
-}
-
-func (p Hover) AlterRules(inRules []rules.Rule) []rules.Rule {
-	for i := range inRules {
-		inRules[i].Invocation = rules.RuleInvokeHover
-	}
-	return inRules
+func (p Hover) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	return node.HasState(selector.StateHover), nil
 }
@@ -40,9 +40,18 @@ package pseudos
 import (
 	"errors"
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p Lang) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	return []*document.Element{elm}, errors.New("not implemented")
+func (p Lang) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	if len(part.Args) == 0 {
+		return false, errors.New("lang expects a language")
+	}
+	want := part.Args[0]
+	for n := node; n != nil; n = n.Parent() {
+		if lang, ok := n.Attribute("lang"); ok {
+			return lang == want || len(lang) > len(want) && lang[:len(want)+1] == want+"-", nil
+		}
+	}
+	return false, nil
 }
@@ -38,16 +38,11 @@
 package pseudos
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p LastChild) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	if len(elm.Children) == 0 {
-		return []*document.Element{}, errors.New("no children")
-	} else {
-		idx := len(elm.Children) - 1
-		return []*document.Element{elm.Children[idx]}, nil
-	}
+func (p LastChild) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	index, count := selector.Position(node, false)
+	return index == count-1, nil
 }
@@ -38,11 +38,15 @@
 package pseudos
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p Link) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	return []*document.Element{elm}, errors.New("not implemented")
+func (p Link) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	return isLink(node), nil
+}
+
+func isLink(node selector.Node) bool {
+	_, href := node.Attribute("href")
+	return href && (node.Tag() == "a" || node.Tag() == "area")
 }
@@ -38,46 +38,22 @@
 package pseudos
 
 import (
-	"errors"
-	"fmt"
-	"kaiju/engine/ui/markup/css/helpers"
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func nth(args []string, count int) (int, int, error) {
-	if len(args) == 0 {
-		return 0, 0, errors.New("no arguments supplied")
-	} else if count == 0 {
-		return 0, 0, errors.New("no children")
-	} else {
-		start := 0
-		skip := 0
-		var err error
-		if args[0] == "even" {
-			args[0] = "2"
-		} else if args[0] == "odd" {
-			start = 1
-			args[0] = "2"
-		}
-		helpers.ChangeNToChildCount(args, count)
-		if skip, err = helpers.ArithmeticString(args); err != nil {
-			return 0, 0, err
-		} else if skip <= 0 {
-			return 0, 0, fmt.Errorf("invalid skip value: %d", skip)
-		}
-		return start, skip, nil
-	}
+func (p NthChild) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	return nth(node, part, false, false)
 }
 
-func (p NthChild) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	if start, skip, err := nth(value.Args, len(elm.Children)); err == nil {
-		selected := make([]*document.Element, 0)
-		for i := start; i < len(elm.Children); i += skip {
-			selected = append(selected, elm.Children[i])
-		}
-		return selected, nil
-	} else {
-		return []*document.Element{}, err
+func nth(node selector.Node, part rules.SelectorPart, ofType, fromEnd bool) (bool, error) {
+	pattern, err := selector.ParseNth(part.Args)
+	if err != nil {
+		return false, err
+	}
+	index, count := selector.Position(node, ofType)
+	if fromEnd {
+		index = count - 1 - index
 	}
+	return pattern.Matches(index + 1), nil
 }
@@ -39,17 +39,9 @@ package pseudos
 
 import (
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p NthLastChild) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	if start, skip, err := nth(value.Args, len(elm.Children)); err == nil {
-		selected := make([]*document.Element, 0)
-		for i := len(elm.Children) - 1 - start; i >= 0; i -= skip {
-			selected = append(selected, elm.Children[i])
-		}
-		return selected, nil
-	} else {
-		return []*document.Element{}, err
-	}
+func (p NthLastChild) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	return nth(node, part, false, true)
 }
@@ -38,11 +38,11 @@
 package pseudos
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p OnlyOfType) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	return []*document.Element{elm}, errors.New("not implemented")
+func (p OnlyOfType) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	_, count := selector.Position(node, true)
+	return count == 1, nil
 }
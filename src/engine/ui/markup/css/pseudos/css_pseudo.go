@@ -39,14 +39,20 @@ package pseudos
 
 import (
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
 type Pseudo interface {
 	Key() string
 	IsFunction() bool
-	Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error)
-	AlterRules(rules []rules.Rule) []rules.Rule
+	Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error)
+}
+
+// Lookup finds a pseudo-class by its name, it is what a selector.Matcher
+// uses to match the pseudo-classes of a selector
+func Lookup(name string) (selector.Pseudo, bool) {
+	p, ok := PseudoMap[name]
+	return p, ok
 }
 
 var PseudoMap = map[string]Pseudo{
@@ -38,11 +38,19 @@
 package pseudos
 
 import (
-	"errors"
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/document"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p ReadWrite) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	return []*document.Element{elm}, errors.New("not implemented")
+func (p ReadWrite) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	return isEditable(node), nil
+}
+
+// isEditable returns true for the inputs that can be typed into
+func isEditable(node selector.Node) bool {
+	if node.Tag() != "input" && node.Tag() != "textarea" {
+		return false
+	}
+	_, readOnly := node.Attribute("readonly")
+	return !readOnly && !node.HasState(selector.StateDisabled)
 }
@@ -0,0 +1,206 @@
+/******************************************************************************/
+/* pseudos_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package pseudos
+
+import (
+	"kaiju/engine/ui/markup/css/rules"
+	"kaiju/engine/ui/markup/css/selector"
+	"strings"
+	"testing"
+)
+
+type testNode struct {
+	tag      string
+	attrs    map[string]string
+	text     string
+	parent   *testNode
+	children []*testNode
+	states   map[selector.State]bool
+}
+
+func (n *testNode) Tag() string { return n.tag }
+
+func (n *testNode) Attribute(name string) (string, bool) {
+	v, ok := n.attrs[name]
+	return v, ok
+}
+
+func (n *testNode) Parent() selector.Node {
+	if n.parent == nil {
+		return nil
+	}
+	return n.parent
+}
+
+func (n *testNode) Children() []selector.Node {
+	out := make([]selector.Node, len(n.children))
+	for i := range n.children {
+		out[i] = n.children[i]
+	}
+	return out
+}
+
+func (n *testNode) Text() string                       { return n.text }
+func (n *testNode) HasState(state selector.State) bool { return n.states[state] }
+
+func el(tag, attrs string, children ...*testNode) *testNode {
+	n := &testNode{
+		tag:      tag,
+		attrs:    map[string]string{},
+		children: children,
+		states:   map[selector.State]bool{},
+	}
+	for _, a := range strings.Fields(attrs) {
+		k, v, _ := strings.Cut(a, "=")
+		n.attrs[k] = v
+	}
+	for _, c := range children {
+		c.parent = n
+	}
+	return n
+}
+
+var matcher = selector.Matcher{Pseudo: Lookup}
+
+func matches(t *testing.T, sel string, node selector.Node) bool {
+	t.Helper()
+	sheet := rules.NewStyleSheet()
+	sheet.Parse(sel + " { color: red; }")
+	if len(sheet.Groups) != 1 {
+		t.Fatalf("expected 1 group for %q but got %d", sel, len(sheet.Groups))
+	}
+	return matcher.MatchesAny(sheet.Groups[0].Selectors, node)
+}
+
+func TestStates(t *testing.T) {
+	btn := el("button", "")
+	input := el("input", "type=checkbox")
+	el("div", "", btn, input)
+	btn.states[selector.StateHover] = true
+	btn.states[selector.StateActive] = true
+	input.states[selector.StateFocus] = true
+	input.states[selector.StateChecked] = true
+	tests := []struct {
+		sel   string
+		node  *testNode
+		match bool
+	}{
+		{"button:hover", btn, true},
+		{"button:active", btn, true},
+		{"button:focus", btn, false},
+		{"input:focus", input, true},
+		{"input:checked", input, true},
+		{"button:checked", btn, false},
+		{"div:focus-within > input", input, true},
+		{"button:enabled", btn, true},
+		{"button:disabled", btn, false},
+	}
+	for _, test := range tests {
+		if got := matches(t, test.sel, test.node); got != test.match {
+			t.Errorf("%q expected %v but got %v", test.sel, test.match, got)
+		}
+	}
+	btn.states[selector.StateDisabled] = true
+	if !matches(t, "button:disabled", btn) || matches(t, "button:enabled", btn) {
+		t.Error("expected the disabled button to not be enabled")
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	blank := el("div", "")
+	blank.text = "  \n"
+	withText := el("div", "")
+	withText.text = "hi"
+	withChild := el("div", "", el("span", ""))
+	el("body", "", blank, withText, withChild)
+	if !matches(t, "div:empty", blank) {
+		t.Error("expected white space to be empty")
+	}
+	if matches(t, "div:empty", withText) || matches(t, "div:empty", withChild) {
+		t.Error("expected text and children to not be empty")
+	}
+}
+
+func TestLogical(t *testing.T) {
+	node := el("p", "class=a")
+	el("div", "id=main", node)
+	tests := []struct {
+		sel   string
+		match bool
+	}{
+		{"p:not(.b)", true},
+		{"p:not(.a)", false},
+		{"p:not(.b, .a)", false},
+		{":is(span, p).a", true},
+		{":is(span, div)", false},
+		{":where(#main) > p", true},
+		{"div:has(> .a)", false},
+	}
+	for _, test := range tests {
+		if got := matches(t, test.sel, node); got != test.match {
+			t.Errorf("%q expected %v but got %v", test.sel, test.match, got)
+		}
+	}
+	if !matches(t, "div:has(.a)", node.parent) {
+		t.Error("expected :has() to find the descendant")
+	}
+}
+
+func TestStructural(t *testing.T) {
+	items := []*testNode{el("li", ""), el("li", ""), el("b", ""), el("li", ""), el("li", "")}
+	el("ul", "", items...)
+	tests := []struct {
+		sel  string
+		want []bool
+	}{
+		{"li:nth-child(odd)", []bool{true, false, false, false, true}},
+		{"li:nth-child(2n)", []bool{false, true, false, true, false}},
+		{"li:nth-of-type(odd)", []bool{true, false, false, true, false}},
+		{"li:nth-last-child(1)", []bool{false, false, false, false, true}},
+		{":first-child", []bool{true, false, false, false, false}},
+		{"li:last-of-type", []bool{false, false, false, false, true}},
+		{"b:only-of-type", []bool{false, false, true, false, false}},
+	}
+	for _, test := range tests {
+		for i, node := range items {
+			if got := matches(t, test.sel, node); got != test.want[i] {
+				t.Errorf("%q item %d expected %v but got %v", test.sel, i, test.want[i], got)
+			}
+		}
+	}
+}
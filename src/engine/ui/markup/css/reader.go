@@ -39,55 +39,14 @@ package css
 
 import (
 	"kaiju/engine"
-	"kaiju/engine/ui"
-	"kaiju/engine/ui/markup/css/properties"
-	"kaiju/engine/ui/markup/css/pseudos"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
-	"slices"
 )
 
-type CSSMap map[*ui.UI][]rules.Rule
-
-func (m CSSMap) add(elm *ui.UI, rule []rules.Rule) {
-	if _, ok := m[elm]; !ok {
-		m[elm] = make([]rules.Rule, 0)
-	}
-	m[elm] = append(m[elm], rule...)
-}
-
-func ApplyElementStyle(elm *document.Element, host *engine.Host) []error {
-	panel := elm.UIPanel
-	hasHover := false
-	for i := 0; i < len(elm.StyleRules) && !hasHover; i++ {
-		hasHover = elm.StyleRules[i].Invocation == rules.RuleInvokeHover
-	}
-	proc := func(invokeType rules.RuleInvoke) []error {
-		problems := make([]error, 0)
-		for i := range elm.StyleRules {
-			rule := &elm.StyleRules[i]
-			if p, ok := properties.PropertyMap[rule.Property]; ok {
-				if rule.Invocation == invokeType {
-					if err := p.Process(panel, elm, rule.Values, host); err != nil {
-						problems = append(problems, err)
-					}
-				}
-			}
-		}
-		return problems
-	}
-	problems := proc(rules.RuleInvokeImmediate)
-	if hasHover {
-		elm.UI.AddEvent(ui.EventTypeEnter, func() {
-			elm.UI.Layout().ClearFunctions()
-			proc(rules.RuleInvokeImmediate)
-			proc(rules.RuleInvokeHover)
-		})
-		elm.UI.AddEvent(ui.EventTypeExit, func() {
-			elm.UI.Layout().ClearFunctions()
-			proc(rules.RuleInvokeImmediate)
-		})
-	}
+func ApplyElementStyle(elm *document.Element, keyframes map[string]rules.Keyframes, host *engine.Host) []error {
+	setupAnimations(elm, keyframes, host)
+	animateStyle(elm, elm.StyleRules, host)
+	problems := applyStyle(elm, elm.StyleRules, host)
 	//if len(problems) > 0 {
 	//	slog.Error("There were errors during processing the document", "count", len(problems))
 	//	for i := range problems {
@@ -97,112 +56,12 @@ func ApplyElementStyle(elm *document.Element, host *engine.Host) []error {
 	return problems
 }
 
-func applyToElement(inRules []rules.Rule, elm *document.Element, host *engine.Host) []error {
-	elm.StyleRules = make([]rules.Rule, len(inRules))
-	for i := range inRules {
-		elm.StyleRules[i] = inRules[i].Clone()
-	}
-	return ApplyElementStyle(elm, host)
-}
-
-func applyMappings(doc *document.Document, cssMap map[*ui.UI][]rules.Rule, host *engine.Host) {
-	for i := range doc.Elements {
-		// TODO:  Make sure this is applying in order from parent to child
-		// Since this array is intrinsically ordered, it should be fine
-		if rules, ok := cssMap[doc.Elements[i].UI]; ok {
-			applyToElement(rules, doc.Elements[i], host)
-		}
-	}
-}
-
-func applyDirect(part rules.SelectorPart, applyRules []rules.Rule, doc *document.Document, host *engine.Host, cssMap CSSMap) {
-	switch part.SelectType {
-	case rules.ReadingId:
-		if elm, ok := doc.GetElementById(part.Name); ok {
-			cssMap.add(elm.UI, applyRules)
-		}
-	case rules.ReadingClass:
-		for _, elm := range doc.GetElementsByClass(part.Name) {
-			cssMap.add(elm.UI, applyRules)
-		}
-	case rules.ReadingTag:
-		for _, elm := range doc.GetElementsByTagName(part.Name) {
-			cssMap.add(elm.UI, applyRules)
-		}
-	}
-}
-
-func applyIndirect(parts []rules.SelectorPart, applyRules []rules.Rule, doc *document.Document, host *engine.Host, cssMap CSSMap) {
-	elms := make([]*document.Element, 0)
-	switch parts[0].SelectType {
-	case rules.ReadingId:
-		if elm, ok := doc.GetElementById(parts[0].Name); ok {
-			elms = append(elms, elm)
-		}
-	case rules.ReadingClass:
-		for _, elm := range doc.GetElementsByClass(parts[0].Name) {
-			elms = append(elms, elm)
-		}
-	case rules.ReadingTag:
-		for _, elm := range doc.GetElementsByTagName(parts[0].Name) {
-			elms = append(elms, elm)
-		}
-	}
-	targets := make([]*document.Element, 0)
-	for _, part := range parts[1:] {
-		for _, elm := range elms {
-			if p, ok := pseudos.PseudoMap[part.Name]; ok {
-				if selects, err := p.Process(elm, part); err == nil {
-					targets = append(targets, selects...)
-					applyRules = p.AlterRules(applyRules)
-				}
-			} else {
-				tagged := doc.GetElementsByTagName(part.Name)
-				for _, t := range tagged {
-					if t.Parent.Value() == elm {
-						targets = append(targets, t)
-					}
-				}
-			}
-		}
-	}
-	for _, target := range targets {
-		cssMap.add(target.UI, applyRules)
-	}
-}
-
-func cleanMapDuplicates(cssMap CSSMap) {
-	for k, v := range cssMap {
-		for i := 0; i < len(v); i++ {
-			for j := i + 1; j < len(v); j++ {
-				if v[i].Property == v[j].Property && v[i].Invocation == v[j].Invocation {
-					v = slices.Delete(v, i, i+1)
-					i--
-					break
-				}
-			}
-		}
-		cssMap[k] = v
-	}
-}
-
+// Apply matches the selectors of the style sheet against the elements of the
+// document and styles each element with its rules, cascaded by specificity
+// and source order. The document keeps the styler so elements are restyled
+// as their state (hover, focus, checked, ...) changes.
 func Apply(s rules.StyleSheet, doc *document.Document, host *engine.Host) {
-	cssMap := CSSMap(make(map[*ui.UI][]rules.Rule))
-	for _, group := range s.Groups {
-		for _, sel := range group.Selectors {
-			if len(sel.Parts) == 1 {
-				applyDirect(sel.Parts[0], group.Rules, doc, host, cssMap)
-			} else if len(sel.Parts) > 1 {
-				applyIndirect(sel.Parts, group.Rules, doc, host, cssMap)
-			}
-		}
-	}
-	cleanMapDuplicates(cssMap)
-	applyMappings(doc, cssMap, host)
-	for _, elm := range doc.Elements {
-		if inlineStyle := elm.Attribute("style"); inlineStyle != "" {
-			group := s.ParseInline(inlineStyle)
-			applyToElement(group.Rules, elm, host)
-		}
-	}
+	st := newStyler(s, doc, host)
+	doc.SetStyleState(st)
+	st.apply()
 }
@@ -39,6 +39,7 @@ package rules
 
 import (
 	"bytes"
+	"strconv"
 	"strings"
 
 	"github.com/tdewolff/parse/v2"
@@ -48,7 +49,12 @@ import (
 type StyleSheet struct {
 	Groups     []SelectorGroup
 	CustomVars map[string][]string
+	Keyframes  map[string]Keyframes
 	state      RuleState
+	keyframes  *Keyframes
+	frameStart int
+	offsets    []float64
+	selector   []selectorToken
 }
 
 func (s *StyleSheet) addGroup() {
@@ -66,70 +72,92 @@ func (s *StyleSheet) currentGroup() *SelectorGroup {
 	return &s.Groups[len(s.Groups)-1]
 }
 
-func (s *StyleSheet) readSelector(cssParser *css.Parser) {
-	sel := Selector{
-		Parts: make([]SelectorPart, 0),
-	}
+func (s *StyleSheet) readKeyframeOffsets(cssParser *css.Parser) {
 	for _, val := range cssParser.Values() {
 		switch val.TokenType {
 		case css.IdentToken:
-			fallthrough
-		case css.NumberToken:
-			if s.state == ReadingPseudoFunction {
-				idx := len(sel.Parts) - 1
-				sel.Parts[idx].Args = append(sel.Parts[idx].Args, string(val.Data))
-			} else {
-				sel.Parts = append(sel.Parts, SelectorPart{
-					Name:       string(val.Data),
-					SelectType: s.state,
-				})
+			switch strings.ToLower(string(val.Data)) {
+			case "from":
+				s.offsets = append(s.offsets, 0)
+			case "to":
+				s.offsets = append(s.offsets, 1)
 			}
-		case css.HashToken:
-			id := strings.TrimPrefix(string(val.Data), "#")
-			sel.Parts = append(sel.Parts, SelectorPart{
-				Name:       id,
-				SelectType: ReadingId,
-			})
-		case css.ColonToken:
-			s.state = ReadingPseudo
-		case css.FunctionToken:
-			s.state = ReadingPseudoFunction
-			sel.Parts = append(sel.Parts, SelectorPart{
-				Name:       strings.TrimSuffix(string(val.Data), "("),
-				SelectType: ReadingId,
-			})
-		case css.RightParenthesisToken:
-			s.state = ReadingPseudo
-		case css.DelimToken:
-			switch string(val.Data) {
-			case "#":
-				s.state = ReadingId
-			case ".":
-				s.state = ReadingClass
-			case ">":
-				s.state = ReadingChild
-			case "~":
-				s.state = ReadingSibling
-			case "+":
-				s.state = ReadingAdjacent
-			case ":":
-				s.state = ReadingPseudo
+		case css.PercentageToken:
+			pct := strings.TrimSuffix(string(val.Data), "%")
+			if v, err := strconv.ParseFloat(pct, 64); err == nil {
+				s.offsets = append(s.offsets, v/100)
 			}
 		}
 	}
-	idx := len(s.Groups) - 1
-	s.Groups[idx].Selectors = append(s.Groups[idx].Selectors, sel)
+}
+
+func (s *StyleSheet) beginKeyframes(cssParser *css.Parser) {
+	s.keyframes = &Keyframes{Frames: make([]Keyframe, 0)}
+	for _, val := range cssParser.Values() {
+		switch val.TokenType {
+		case css.IdentToken:
+			s.keyframes.Name = string(val.Data)
+		case css.StringToken:
+			s.keyframes.Name = strings.Trim(string(val.Data), "\"'")
+		}
+	}
 }
 
 func (s *StyleSheet) readProperty(prop string, cssParser *css.Parser) {
+	r := s.parseRule(prop, cssParser)
+	if s.keyframes != nil {
+		for i := s.frameStart; i < len(s.keyframes.Frames); i++ {
+			s.keyframes.Frames[i].Rules = append(s.keyframes.Frames[i].Rules, r.Clone())
+		}
+	} else {
+		s.currentGroup().AddRule(r)
+	}
+}
+
+// readCustomProperty adds a custom property (--name) as a rule so that it
+// cascades like any other property. The value of a custom property is given
+// as raw text, so it is parsed again as the value of a regular declaration.
+func (s *StyleSheet) readCustomProperty(name string, cssParser *css.Parser) {
+	vals := make([]string, 0)
+	raw := strings.Builder{}
+	for _, val := range cssParser.Values() {
+		vals = append(vals, string(val.Data))
+		raw.Write(val.Data)
+	}
+	s.CustomVars[name] = vals
+	valueParser := css.NewParser(parse.NewInput(
+		bytes.NewBufferString("v:"+raw.String())), true)
+	for gt, _, _ := valueParser.Next(); gt != css.ErrorGrammar; gt, _, _ = valueParser.Next() {
+		if gt == css.DeclarationGrammar {
+			state := s.state
+			r := s.parseRule(name, valueParser)
+			s.state = state
+			if s.keyframes == nil {
+				s.currentGroup().AddRule(r)
+			}
+			return
+		}
+	}
+}
+
+func (s *StyleSheet) parseRule(prop string, cssParser *css.Parser) Rule {
 	r := Rule{
 		Property: prop,
 		Values:   make([]PropertyValue, 0),
 	}
+	// Functions within functions, like repeat(2, minmax(10px, 1fr)), are kept
+	// as arguments of the outer function, including their parentheses
+	depth := 0
 	for _, val := range cssParser.Values() {
 		switch val.TokenType {
 		case css.FunctionToken:
+			if depth > 0 {
+				r.Values[len(r.Values)-1].Args = append(r.Values[len(r.Values)-1].Args, string(val.Data))
+				depth++
+				continue
+			}
 			s.state = ReadingPropertyFunction
+			depth = 1
 			r.Values = append(r.Values, PropertyValue{
 				Str:  strings.TrimSuffix(string(val.Data), "("),
 				Args: make([]string, 0),
@@ -137,7 +165,20 @@ func (s *StyleSheet) readProperty(prop string, cssParser *css.Parser) {
 		case css.CommaToken:
 		case css.CommentToken:
 		case css.WhitespaceToken:
+		case css.LeftParenthesisToken:
+			if depth > 0 {
+				r.Values[len(r.Values)-1].Args = append(r.Values[len(r.Values)-1].Args, "(")
+				depth++
+			} else {
+				r.Values = append(r.Values, PropertyValue{Str: "(", Args: make([]string, 0)})
+			}
 		case css.RightParenthesisToken:
+			if depth > 1 {
+				r.Values[len(r.Values)-1].Args = append(r.Values[len(r.Values)-1].Args, ")")
+				depth--
+				continue
+			}
+			depth = 0
 			s.state = ReadingProperty
 		default:
 			if s.state == ReadingPropertyFunction {
@@ -150,7 +191,7 @@ func (s *StyleSheet) readProperty(prop string, cssParser *css.Parser) {
 			}
 		}
 	}
-	s.currentGroup().AddRule(r)
+	return r
 }
 
 func NewStyleSheet() StyleSheet {
@@ -158,6 +199,7 @@ func NewStyleSheet() StyleSheet {
 		Groups:     make([]SelectorGroup, 0),
 		state:      ReadingTag,
 		CustomVars: make(map[string][]string),
+		Keyframes:  make(map[string]Keyframes),
 	}
 }
 
@@ -173,28 +215,43 @@ func (s *StyleSheet) Parse(cssStr string) {
 		case css.CommentGrammar:
 			// Do nothing
 		case css.BeginAtRuleGrammar:
+			if strings.EqualFold(string(propData), "@keyframes") {
+				s.beginKeyframes(cssParser)
+			}
 		case css.AtRuleGrammar:
 		case css.EndAtRuleGrammar:
+			if s.keyframes != nil {
+				if s.keyframes.Name != "" {
+					s.Keyframes[s.keyframes.Name] = *s.keyframes
+				}
+				s.keyframes = nil
+			}
 		case css.QualifiedRuleGrammar:
-			if s.state < ReadingProperty {
-				s.readSelector(cssParser)
+			if s.keyframes != nil {
+				s.readKeyframeOffsets(cssParser)
+			} else if s.state < ReadingProperty {
+				s.readSelector(cssParser, false)
 			}
 		case css.BeginRulesetGrammar:
-			s.readSelector(cssParser)
+			if s.keyframes != nil {
+				s.readKeyframeOffsets(cssParser)
+				s.frameStart = len(s.keyframes.Frames)
+				s.keyframes.addFrames(s.offsets)
+				s.offsets = s.offsets[:0]
+			} else {
+				s.readSelector(cssParser, true)
+			}
 			s.state = ReadingProperty
 		case css.EndRulesetGrammar:
 			s.state = ReadingTag
-			s.addGroup()
+			if s.keyframes == nil {
+				s.addGroup()
+			}
 		case css.DeclarationGrammar:
 			s.readProperty(string(propData), cssParser)
 		case css.TokenGrammar:
 		case css.CustomPropertyGrammar:
-			name := string(propData)
-			vals := make([]string, 0)
-			for _, val := range cssParser.Values() {
-				vals = append(vals, string(val.Data))
-			}
-			s.CustomVars[name] = vals
+			s.readCustomProperty(string(propData), cssParser)
 		}
 	}
 	s.removeLastGroup()
@@ -213,6 +270,8 @@ func (s *StyleSheet) ParseInline(cssStr string) *SelectorGroup {
 			// Do nothing
 		case css.DeclarationGrammar:
 			s.readProperty(string(propData), cssParser)
+		case css.CustomPropertyGrammar:
+			s.readCustomProperty(string(propData), cssParser)
 		}
 	}
 	group := s.currentGroup()
@@ -39,13 +39,6 @@ package rules
 
 import "slices"
 
-type RuleInvoke = int
-
-const (
-	RuleInvokeImmediate RuleInvoke = iota
-	RuleInvokeHover
-)
-
 type PropertyValue struct {
 	Str  string
 	Args []string
@@ -63,16 +56,14 @@ func (p PropertyValue) IsFunction() bool {
 }
 
 type Rule struct {
-	Property   string
-	Values     []PropertyValue
-	Invocation RuleInvoke
+	Property string
+	Values   []PropertyValue
 }
 
 func (r *Rule) Clone() Rule {
 	out := Rule{
-		Property:   r.Property,
-		Invocation: r.Invocation,
-		Values:     make([]PropertyValue, len(r.Values)),
+		Property: r.Property,
+		Values:   make([]PropertyValue, len(r.Values)),
 	}
 	for i := range r.Values {
 		out.Values[i] = r.Values[i].Clone()
@@ -51,18 +51,53 @@ const (
 	ReadingProperty
 	ReadingPropertyValue
 	ReadingPropertyFunction
+	ReadingAttribute
+	ReadingPseudoElement
 )
 
+// Combinator is how a compound selector relates to the one before it
+type Combinator = int
+
+const (
+	// CombinatorNone joins the part to the part before it in the same
+	// compound selector, like the .b in a.b
+	CombinatorNone Combinator = iota
+	CombinatorDescendant
+	CombinatorChild
+	CombinatorSibling
+	CombinatorAdjacent
+)
+
+// SelectorPart is a single simple selector (tag, id, class, attribute or
+// pseudo-class). For attributes, the Args are the operator, the value and the
+// optional case flag, an attribute that only needs to exist has no Args. The
+// Selectors are the selector list given to :not(), :is(), :where() or :has().
 type SelectorPart struct {
 	Name       string
 	Args       []string
 	SelectType RuleState
+	Combinator Combinator
+	Selectors  []Selector
 }
 
 type Selector struct {
 	Parts []SelectorPart
 }
 
+// Compounds splits the parts of the selector into its compound selectors, the
+// first part of each compound holds the combinator to the compound before it
+func (s Selector) Compounds() [][]SelectorPart {
+	out := make([][]SelectorPart, 0, 1)
+	start := 0
+	for i := 1; i <= len(s.Parts); i++ {
+		if i == len(s.Parts) || s.Parts[i].Combinator != CombinatorNone {
+			out = append(out, s.Parts[start:i])
+			start = i
+		}
+	}
+	return out
+}
+
 type SelectorGroup struct {
 	Selectors []Selector
 	Rules     []Rule
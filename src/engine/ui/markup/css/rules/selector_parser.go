@@ -0,0 +1,235 @@
+/******************************************************************************/
+/* selector_parser.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rules
+
+import (
+	"strings"
+
+	"github.com/tdewolff/parse/v2/css"
+)
+
+// selectorToken is a copy of a token from the css parser, the parser reuses
+// the memory of its tokens as it reads
+type selectorToken struct {
+	kind css.TokenType
+	data string
+}
+
+// bufferSelector reads the tokens of the current selector. The css parser
+// splits selectors on every comma, including the commas within a selector
+// list like :not(.a, .b), so the tokens are kept until the parenthesis close.
+// It returns true once the buffered tokens are a complete selector.
+func (s *StyleSheet) bufferSelector(cssParser *css.Parser) bool {
+	if len(s.selector) > 0 {
+		s.selector = append(s.selector, selectorToken{css.CommaToken, ","})
+	}
+	for _, val := range cssParser.Values() {
+		s.selector = append(s.selector, selectorToken{val.TokenType, string(val.Data)})
+	}
+	depth := 0
+	for _, t := range s.selector {
+		switch t.kind {
+		case css.FunctionToken, css.LeftParenthesisToken:
+			depth++
+		case css.RightParenthesisToken:
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// readSelector adds the selector to the current group once it is complete, the
+// last selector of a group is always complete as the rule set begins after it
+func (s *StyleSheet) readSelector(cssParser *css.Parser, last bool) {
+	if !s.bufferSelector(cssParser) && !last {
+		return
+	}
+	sel := parseSelector(s.selector)
+	s.selector = s.selector[:0]
+	idx := len(s.Groups) - 1
+	s.Groups[idx].Selectors = append(s.Groups[idx].Selectors, sel)
+}
+
+// closingIndex finds the index of the token that closes the bracket or
+// parenthesis opened at tokens[start]
+func closingIndex(tokens []selectorToken, start int) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch tokens[i].kind {
+		case css.FunctionToken, css.LeftParenthesisToken, css.LeftBracketToken:
+			depth++
+		case css.RightParenthesisToken, css.RightBracketToken:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(tokens)
+}
+
+func parseSelectorList(tokens []selectorToken) []Selector {
+	out := make([]Selector, 0, 1)
+	depth, start := 0, 0
+	for i := 0; i <= len(tokens); i++ {
+		if i == len(tokens) || (depth == 0 && tokens[i].kind == css.CommaToken) {
+			if sel := parseSelector(tokens[start:i]); len(sel.Parts) > 0 {
+				out = append(out, sel)
+			}
+			start = i + 1
+			continue
+		}
+		switch tokens[i].kind {
+		case css.FunctionToken, css.LeftParenthesisToken:
+			depth++
+		case css.RightParenthesisToken:
+			depth--
+		}
+	}
+	return out
+}
+
+func isSelectorListPseudo(name string) bool {
+	switch name {
+	case "not", "is", "where", "has":
+		return true
+	}
+	return false
+}
+
+func parseSelector(tokens []selectorToken) Selector {
+	sel := Selector{Parts: make([]SelectorPart, 0)}
+	combinator := CombinatorNone
+	state := ReadingTag
+	add := func(part SelectorPart) {
+		if len(sel.Parts) > 0 {
+			part.Combinator = combinator
+		}
+		combinator = CombinatorNone
+		state = ReadingTag
+		sel.Parts = append(sel.Parts, part)
+	}
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch t.kind {
+		case css.WhitespaceToken:
+			if len(sel.Parts) > 0 && combinator == CombinatorNone {
+				combinator = CombinatorDescendant
+			}
+		case css.IdentToken, css.NumberToken, css.PercentageToken:
+			add(SelectorPart{Name: t.data, SelectType: state})
+		case css.HashToken:
+			add(SelectorPart{
+				Name:       strings.TrimPrefix(t.data, "#"),
+				SelectType: ReadingId,
+			})
+		case css.ColonToken:
+			if state == ReadingPseudo {
+				state = ReadingPseudoElement
+			} else {
+				state = ReadingPseudo
+			}
+		case css.DelimToken:
+			switch t.data {
+			case ".":
+				state = ReadingClass
+			case "#":
+				state = ReadingId
+			case "*":
+				add(SelectorPart{Name: "*", SelectType: ReadingTag})
+			case ">":
+				combinator = CombinatorChild
+			case "~":
+				combinator = CombinatorSibling
+			case "+":
+				combinator = CombinatorAdjacent
+			}
+		case css.FunctionToken:
+			end := closingIndex(tokens, i)
+			inner := tokens[i+1 : min(end, len(tokens))]
+			part := SelectorPart{
+				Name:       strings.TrimSuffix(t.data, "("),
+				SelectType: ReadingPseudoFunction,
+				Args:       make([]string, 0, len(inner)),
+			}
+			if state == ReadingPseudoElement {
+				part.SelectType = ReadingPseudoElement
+			}
+			for _, a := range inner {
+				if a.kind != css.WhitespaceToken {
+					part.Args = append(part.Args, a.data)
+				}
+			}
+			if isSelectorListPseudo(part.Name) {
+				part.Selectors = parseSelectorList(inner)
+			}
+			add(part)
+			i = end
+		case css.LeftBracketToken:
+			end := closingIndex(tokens, i)
+			if part, ok := parseAttribute(tokens[i+1 : min(end, len(tokens))]); ok {
+				add(part)
+			}
+			i = end
+		}
+	}
+	return sel
+}
+
+func parseAttribute(tokens []selectorToken) (SelectorPart, bool) {
+	part := SelectorPart{SelectType: ReadingAttribute, Args: make([]string, 0, 3)}
+	for _, t := range tokens {
+		switch t.kind {
+		case css.WhitespaceToken:
+		case css.DelimToken, css.IncludeMatchToken, css.DashMatchToken,
+			css.PrefixMatchToken, css.SuffixMatchToken, css.SubstringMatchToken:
+			if part.Name != "" && len(part.Args) == 0 {
+				part.Args = append(part.Args, t.data)
+			}
+		case css.StringToken:
+			part.Args = append(part.Args, t.data[1:len(t.data)-1])
+		default:
+			if part.Name == "" {
+				part.Name = t.data
+			} else {
+				part.Args = append(part.Args, t.data)
+			}
+		}
+	}
+	return part, part.Name != "" && len(part.Args) != 1
+}
@@ -0,0 +1,80 @@
+/******************************************************************************/
+/* cascade.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package selector
+
+import (
+	"kaiju/engine/ui/markup/css/rules"
+	"slices"
+)
+
+// Match is a selector that matched an element along with the rules of its
+// group. Order is the position of the selector in the style sheet.
+type Match struct {
+	Specificity Specificity
+	Order       int
+	Rules       []rules.Rule
+}
+
+// Cascade orders the rules of the matches by specificity and then by source
+// order, followed by the inline rules of the element. Only the last rule for
+// each property is kept, in the position it would have been applied.
+func Cascade(matches []Match, inline []rules.Rule) []rules.Rule {
+	sorted := slices.Clone(matches)
+	slices.SortStableFunc(sorted, func(a, b Match) int {
+		if c := a.Specificity.Compare(b.Specificity); c != 0 {
+			return c
+		}
+		return a.Order - b.Order
+	})
+	all := make([]rules.Rule, 0, len(inline))
+	for i := range sorted {
+		all = append(all, sorted[i].Rules...)
+	}
+	all = append(all, inline...)
+	seen := make(map[string]bool, len(all))
+	out := make([]rules.Rule, 0, len(all))
+	for i := len(all) - 1; i >= 0; i-- {
+		if seen[all[i].Property] {
+			continue
+		}
+		seen[all[i].Property] = true
+		out = append(out, all[i].Clone())
+	}
+	slices.Reverse(out)
+	return out
+}
@@ -0,0 +1,85 @@
+/******************************************************************************/
+/* dependency.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package selector
+
+import "kaiju/engine/ui/markup/css/rules"
+
+var pseudoStates = map[string]State{
+	"hover":         StateHover,
+	"active":        StateActive,
+	"focus":         StateFocus,
+	"focus-visible": StateFocus,
+	"focus-within":  StateFocus,
+	"checked":       StateChecked,
+	"disabled":      StateDisabled,
+	"enabled":       StateDisabled,
+}
+
+// Dependency is an element state that a selector depends on. When an element
+// that matches the compound selector Parts changes this state, what the
+// selector matches can change. Parts is nil if the state of any element
+// matters, like the focus of the descendants for :focus-within.
+type Dependency struct {
+	State State
+	Parts []rules.SelectorPart
+}
+
+// Dependencies returns the element states that the selector depends on, a
+// selector without any only changes what it matches when the document does
+func Dependencies(sel rules.Selector) []Dependency {
+	out := make([]Dependency, 0)
+	for _, compound := range sel.Compounds() {
+		for i := range compound {
+			part := &compound[i]
+			if part.SelectType != rules.ReadingPseudo && part.SelectType != rules.ReadingPseudoFunction {
+				continue
+			}
+			if state, ok := pseudoStates[part.Name]; ok {
+				dep := Dependency{State: state, Parts: compound}
+				if part.Name == "focus-within" {
+					dep.Parts = nil
+				}
+				out = append(out, dep)
+			}
+			for j := range part.Selectors {
+				out = append(out, Dependencies(part.Selectors[j])...)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+/******************************************************************************/
+/* nth.go                                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package selector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Nth is the An+B pattern of the :nth-*() pseudo-classes
+type Nth struct {
+	A int
+	B int
+}
+
+// ParseNth reads an An+B pattern (like 2n+1, -n+3, odd or 4) from the
+// arguments of a :nth-*() pseudo-class
+func ParseNth(args []string) (Nth, error) {
+	str := strings.ToLower(strings.Join(args, ""))
+	switch str {
+	case "odd":
+		return Nth{2, 1}, nil
+	case "even":
+		return Nth{2, 0}, nil
+	case "":
+		return Nth{}, fmt.Errorf("missing An+B pattern")
+	}
+	idx := strings.IndexByte(str, 'n')
+	if idx < 0 {
+		b, err := strconv.Atoi(str)
+		if err != nil {
+			return Nth{}, fmt.Errorf("invalid An+B pattern: %s", str)
+		}
+		return Nth{0, b}, nil
+	}
+	n := Nth{}
+	switch a := str[:idx]; a {
+	case "", "+":
+		n.A = 1
+	case "-":
+		n.A = -1
+	default:
+		var err error
+		if n.A, err = strconv.Atoi(a); err != nil {
+			return Nth{}, fmt.Errorf("invalid An+B pattern: %s", str)
+		}
+	}
+	if b := str[idx+1:]; b != "" {
+		var err error
+		if n.B, err = strconv.Atoi(b); err != nil {
+			return Nth{}, fmt.Errorf("invalid An+B pattern: %s", str)
+		}
+	}
+	return n, nil
+}
+
+// Matches returns true if the 1 based position is selected by the pattern
+func (n Nth) Matches(position int) bool {
+	if n.A == 0 {
+		return position == n.B
+	}
+	diff := position - n.B
+	return diff%n.A == 0 && diff/n.A >= 0
+}
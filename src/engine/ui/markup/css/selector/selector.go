@@ -0,0 +1,256 @@
+/******************************************************************************/
+/* selector.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package selector
+
+import (
+	"kaiju/engine/ui/markup/css/rules"
+	"strings"
+)
+
+// State is a state of an element that pseudo-classes like :hover select on
+type State int
+
+const (
+	StateHover State = iota
+	StateActive
+	StateFocus
+	StateChecked
+	StateDisabled
+)
+
+// Node is an element of a document that selectors are matched against. Nodes
+// are compared with ==, so the same element must always be the same Node.
+type Node interface {
+	// Tag is the lower case tag name of the element
+	Tag() string
+	Attribute(name string) (string, bool)
+	// Parent is nil for the root of the document
+	Parent() Node
+	// Children are only the child elements, text is not included
+	Children() []Node
+	// Text is the text directly within the element
+	Text() string
+	HasState(state State) bool
+}
+
+// Pseudo is a pseudo-class, like :hover or :nth-child()
+type Pseudo interface {
+	Matches(m Matcher, node Node, part rules.SelectorPart) (bool, error)
+}
+
+// Matcher matches selectors against nodes, the pseudo-classes are found
+// through the Pseudo function. Unknown pseudo-classes and pseudo-elements
+// never match.
+type Matcher struct {
+	Pseudo func(name string) (Pseudo, bool)
+}
+
+// Matches returns true if the node is selected by the selector
+func (m Matcher) Matches(sel rules.Selector, node Node) bool {
+	compounds := sel.Compounds()
+	return len(compounds) > 0 && m.matchFrom(compounds, len(compounds)-1, node)
+}
+
+// MatchesAny returns true if the node is selected by any of the selectors
+func (m Matcher) MatchesAny(list []rules.Selector, node Node) bool {
+	for i := range list {
+		if m.Matches(list[i], node) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Matcher) matchFrom(compounds [][]rules.SelectorPart, i int, node Node) bool {
+	if !m.matchCompound(compounds[i], node) {
+		return false
+	} else if i == 0 {
+		return true
+	}
+	switch compounds[i][0].Combinator {
+	case rules.CombinatorChild:
+		p := node.Parent()
+		return p != nil && m.matchFrom(compounds, i-1, p)
+	case rules.CombinatorAdjacent:
+		prev := PreviousSiblings(node)
+		return len(prev) > 0 && m.matchFrom(compounds, i-1, prev[len(prev)-1])
+	case rules.CombinatorSibling:
+		for _, s := range PreviousSiblings(node) {
+			if m.matchFrom(compounds, i-1, s) {
+				return true
+			}
+		}
+	default:
+		for p := node.Parent(); p != nil; p = p.Parent() {
+			if m.matchFrom(compounds, i-1, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m Matcher) matchCompound(parts []rules.SelectorPart, node Node) bool {
+	for i := range parts {
+		if !m.matchPart(&parts[i], node) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Matcher) matchPart(part *rules.SelectorPart, node Node) bool {
+	switch part.SelectType {
+	case rules.ReadingPseudo, rules.ReadingPseudoFunction:
+		if m.Pseudo == nil {
+			return false
+		}
+		p, ok := m.Pseudo(part.Name)
+		if !ok {
+			return false
+		}
+		match, err := p.Matches(m, node, *part)
+		return match && err == nil
+	case rules.ReadingPseudoElement:
+		return false
+	}
+	return matchStaticPart(part, node)
+}
+
+func matchStaticPart(part *rules.SelectorPart, node Node) bool {
+	switch part.SelectType {
+	case rules.ReadingTag:
+		return part.Name == "*" || strings.EqualFold(part.Name, node.Tag())
+	case rules.ReadingId:
+		id, _ := node.Attribute("id")
+		return id == part.Name
+	case rules.ReadingClass:
+		class, _ := node.Attribute("class")
+		for _, c := range strings.Fields(class) {
+			if c == part.Name {
+				return true
+			}
+		}
+		return false
+	case rules.ReadingAttribute:
+		return matchAttribute(part, node)
+	}
+	return true
+}
+
+// MatchesStatic checks only the tags, ids, classes and attributes of a
+// compound selector. The node can only match the compound if this is true, it
+// is used to skip nodes before matching the rest of the selector.
+func MatchesStatic(parts []rules.SelectorPart, node Node) bool {
+	for i := range parts {
+		if !matchStaticPart(&parts[i], node) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAttribute(part *rules.SelectorPart, node Node) bool {
+	value, ok := node.Attribute(part.Name)
+	if !ok {
+		return false
+	} else if len(part.Args) < 2 {
+		return true
+	}
+	op, want := part.Args[0], part.Args[1]
+	if len(part.Args) > 2 && strings.EqualFold(part.Args[2], "i") {
+		value, want = strings.ToLower(value), strings.ToLower(want)
+	}
+	switch op {
+	case "=":
+		return value == want
+	case "~=":
+		for _, v := range strings.Fields(value) {
+			if v == want {
+				return true
+			}
+		}
+		return false
+	case "|=":
+		return value == want || strings.HasPrefix(value, want+"-")
+	case "^=":
+		return want != "" && strings.HasPrefix(value, want)
+	case "$=":
+		return want != "" && strings.HasSuffix(value, want)
+	case "*=":
+		return want != "" && strings.Contains(value, want)
+	}
+	return false
+}
+
+// PreviousSiblings returns the element siblings that come before the node, in
+// document order
+func PreviousSiblings(node Node) []Node {
+	p := node.Parent()
+	if p == nil {
+		return nil
+	}
+	siblings := p.Children()
+	for i := range siblings {
+		if siblings[i] == node {
+			return siblings[:i]
+		}
+	}
+	return nil
+}
+
+// Position returns the 0 based index of the node among its element siblings
+// and the number of siblings, counting only siblings with the same tag when
+// ofType is true. A node without a parent is the only child of the document.
+func Position(node Node, ofType bool) (index, count int) {
+	p := node.Parent()
+	if p == nil {
+		return 0, 1
+	}
+	index = -1
+	for _, s := range p.Children() {
+		if ofType && s.Tag() != node.Tag() {
+			continue
+		}
+		if s == node {
+			index = count
+		}
+		count++
+	}
+	return index, count
+}
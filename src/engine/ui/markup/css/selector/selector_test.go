@@ -0,0 +1,274 @@
+/******************************************************************************/
+/* selector_test.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package selector
+
+import (
+	"kaiju/engine/ui/markup/css/rules"
+	"strings"
+	"testing"
+)
+
+type testNode struct {
+	tag      string
+	attrs    map[string]string
+	parent   *testNode
+	children []*testNode
+	states   map[State]bool
+}
+
+func (n *testNode) Tag() string { return n.tag }
+
+func (n *testNode) Attribute(name string) (string, bool) {
+	v, ok := n.attrs[name]
+	return v, ok
+}
+
+func (n *testNode) Parent() Node {
+	if n.parent == nil {
+		return nil
+	}
+	return n.parent
+}
+
+func (n *testNode) Children() []Node {
+	out := make([]Node, len(n.children))
+	for i := range n.children {
+		out[i] = n.children[i]
+	}
+	return out
+}
+
+func (n *testNode) Text() string              { return "" }
+func (n *testNode) HasState(state State) bool { return n.states[state] }
+
+// el makes a node from a tag and attributes written as key=value pairs
+func el(tag, attrs string, children ...*testNode) *testNode {
+	n := &testNode{tag: tag, attrs: map[string]string{}, children: children}
+	for _, a := range strings.Fields(attrs) {
+		k, v, _ := strings.Cut(a, "=")
+		n.attrs[k] = strings.ReplaceAll(v, "_", " ")
+	}
+	for _, c := range children {
+		c.parent = n
+	}
+	return n
+}
+
+func parse(t *testing.T, sel string) []rules.Selector {
+	t.Helper()
+	sheet := rules.NewStyleSheet()
+	sheet.Parse(sel + " { color: red; }")
+	if len(sheet.Groups) != 1 {
+		t.Fatalf("expected 1 group for %q but got %d", sel, len(sheet.Groups))
+	}
+	return sheet.Groups[0].Selectors
+}
+
+func TestCombinators(t *testing.T) {
+	target := el("span", "class=target")
+	el("div", "id=root",
+		el("p", "class=first"),
+		el("section", "",
+			el("b", ""),
+			target,
+		),
+	)
+	m := Matcher{}
+	tests := []struct {
+		sel   string
+		match bool
+	}{
+		{"#root .target", true},
+		{"div > .target", false},
+		{"section > span.target", true},
+		{"b + span", true},
+		{"p + span", false},
+		{"b ~ .target", true},
+		{"p ~ section > span", true},
+		{"p ~ span", false},
+		{"#root > section span", true},
+		{"* > *", true},
+		{"section > b", false},
+	}
+	for _, test := range tests {
+		sel := parse(t, test.sel)[0]
+		if got := m.Matches(sel, target); got != test.match {
+			t.Errorf("%q expected %v but got %v", test.sel, test.match, got)
+		}
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	node := el("input", "type=text lang=en-US data-x=one_two_three")
+	m := Matcher{}
+	tests := []struct {
+		sel   string
+		match bool
+	}{
+		{"[type]", true},
+		{"[name]", false},
+		{"[type=text]", true},
+		{"[type=\"text\"]", true},
+		{"[type=TEXT]", false},
+		{"[type=TEXT i]", true},
+		{"[data-x~=two]", true},
+		{"[data-x~=tw]", false},
+		{"[lang|=en]", true},
+		{"[data-x^=one]", true},
+		{"[data-x$=three]", true},
+		{"[data-x*=two]", true},
+		{"input[type=text][lang]", true},
+	}
+	for _, test := range tests {
+		sel := parse(t, test.sel)[0]
+		if got := m.Matches(sel, node); got != test.match {
+			t.Errorf("%q expected %v but got %v", test.sel, test.match, got)
+		}
+	}
+}
+
+func TestSelectorList(t *testing.T) {
+	list := parse(t, "a, .b, #c")
+	if len(list) != 3 {
+		t.Fatalf("expected 3 selectors but got %d", len(list))
+	}
+	if !(Matcher{}).MatchesAny(list, el("div", "class=x_b")) {
+		t.Error("expected the list to match by class")
+	}
+}
+
+func TestSpecificity(t *testing.T) {
+	tests := []struct {
+		sel  string
+		want Specificity
+	}{
+		{"*", Specificity{0, 0, 0}},
+		{"li", Specificity{0, 0, 1}},
+		{"ul li", Specificity{0, 0, 2}},
+		{"ul > li.red", Specificity{0, 1, 2}},
+		{"#a .b:hover", Specificity{1, 2, 0}},
+		{"a[href]", Specificity{0, 1, 1}},
+		{":not(#a, .b)", Specificity{1, 0, 0}},
+		{":where(#a) p", Specificity{0, 0, 1}},
+		{"p::before", Specificity{0, 0, 2}},
+	}
+	for _, test := range tests {
+		if got := Of(parse(t, test.sel)[0]); got != test.want {
+			t.Errorf("%q expected %v but got %v", test.sel, test.want, got)
+		}
+	}
+	if (Specificity{1, 0, 0}).Compare(Specificity{0, 9, 9}) != 1 {
+		t.Error("expected ids to outweigh classes and tags")
+	}
+}
+
+func TestNth(t *testing.T) {
+	tests := []struct {
+		args  []string
+		match []int
+	}{
+		{[]string{"odd"}, []int{1, 3, 5}},
+		{[]string{"even"}, []int{2, 4, 6}},
+		{[]string{"3"}, []int{3}},
+		{[]string{"3n", "+", "1"}, []int{1, 4}},
+		{[]string{"-n+3"}, []int{1, 2, 3}},
+	}
+	for _, test := range tests {
+		n, err := ParseNth(test.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []int{}
+		for i := 1; i <= 6; i++ {
+			if n.Matches(i) {
+				got = append(got, i)
+			}
+		}
+		if len(got) != len(test.match) {
+			t.Errorf("%v expected %v but got %v", test.args, test.match, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.match[i] {
+				t.Errorf("%v expected %v but got %v", test.args, test.match, got)
+				break
+			}
+		}
+	}
+	if _, err := ParseNth([]string{"x"}); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestCascade(t *testing.T) {
+	rule := func(prop, val string) rules.Rule {
+		return rules.Rule{Property: prop, Values: []rules.PropertyValue{{Str: val}}}
+	}
+	matches := []Match{
+		{Specificity{1, 0, 0}, 0, []rules.Rule{rule("color", "red")}},
+		{Specificity{0, 1, 0}, 1, []rules.Rule{rule("color", "blue"), rule("width", "1px")}},
+		{Specificity{0, 1, 0}, 2, []rules.Rule{rule("width", "2px")}},
+	}
+	inline := []rules.Rule{rule("height", "3px")}
+	got := Cascade(matches, inline)
+	want := []rules.Rule{rule("width", "2px"), rule("color", "red"), rule("height", "3px")}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d rules but got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Property != want[i].Property || got[i].Values[0].Str != want[i].Values[0].Str {
+			t.Errorf("rule %d expected %v but got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	deps := Dependencies(parse(t, ".a:hover > .b:not(:checked)")[0])
+	if len(deps) != 2 {
+		t.Fatalf("expected 2 dependencies but got %d", len(deps))
+	}
+	if deps[0].State != StateHover || deps[0].Parts[0].Name != "a" {
+		t.Errorf("expected hover on .a but got %v", deps[0])
+	}
+	if deps[1].State != StateChecked {
+		t.Errorf("expected checked but got %v", deps[1].State)
+	}
+	if len(Dependencies(parse(t, "div .a")[0])) != 0 {
+		t.Error("expected no dependencies for a static selector")
+	}
+}
@@ -0,0 +1,102 @@
+/******************************************************************************/
+/* specificity.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package selector
+
+import "kaiju/engine/ui/markup/css/rules"
+
+// Specificity is the weight of a selector in the cascade, made up of the
+// count of ids, the count of classes, attributes and pseudo-classes and the
+// count of tags and pseudo-elements
+type Specificity [3]int
+
+// Compare returns -1, 0 or 1 if s is less than, equal to or greater than o
+func (s Specificity) Compare(o Specificity) int {
+	for i := range s {
+		if s[i] < o[i] {
+			return -1
+		} else if s[i] > o[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (s Specificity) add(o Specificity) Specificity {
+	return Specificity{s[0] + o[0], s[1] + o[1], s[2] + o[2]}
+}
+
+// Of returns the specificity of the selector
+func Of(sel rules.Selector) Specificity {
+	s := Specificity{}
+	for i := range sel.Parts {
+		part := &sel.Parts[i]
+		switch part.SelectType {
+		case rules.ReadingId:
+			s[0]++
+		case rules.ReadingClass, rules.ReadingAttribute:
+			s[1]++
+		case rules.ReadingTag:
+			if part.Name != "*" {
+				s[2]++
+			}
+		case rules.ReadingPseudoElement:
+			s[2]++
+		case rules.ReadingPseudo, rules.ReadingPseudoFunction:
+			switch part.Name {
+			case "where":
+				// :where() never adds to the specificity
+			case "not", "is", "has":
+				s = s.add(OfList(part.Selectors))
+			default:
+				s[1]++
+			}
+		}
+	}
+	return s
+}
+
+// OfList returns the greatest specificity of the selectors in the list
+func OfList(list []rules.Selector) Specificity {
+	best := Specificity{}
+	for i := range list {
+		if s := Of(list[i]); s.Compare(best) > 0 {
+			best = s
+		}
+	}
+	return best
+}
@@ -0,0 +1,388 @@
+/******************************************************************************/
+/* styler.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package css
+
+import (
+	"kaiju/engine"
+	"kaiju/engine/systems/events"
+	"kaiju/engine/ui"
+	"kaiju/engine/ui/markup/css/animation"
+	"kaiju/engine/ui/markup/css/rules"
+	"kaiju/engine/ui/markup/css/selector"
+	"kaiju/engine/ui/markup/document"
+	"slices"
+)
+
+// stateEvents are the UI events that are raised when an element changes one
+// of the states that selectors can depend on
+var stateEvents = map[selector.State][]ui.EventType{
+	selector.StateHover:   {ui.EventTypeEnter, ui.EventTypeExit},
+	selector.StateActive:  {ui.EventTypeDown, ui.EventTypeDragEnd},
+	selector.StateFocus:   {ui.EventTypeFocus, ui.EventTypeBlur},
+	selector.StateChecked: {ui.EventTypeChange},
+}
+
+type styleEntry struct {
+	selector rules.Selector
+	subject  []rules.SelectorPart
+	match    selector.Match
+	// dynamic entries depend on the state of elements, they are matched
+	// again whenever one of those states changes
+	dynamic bool
+}
+
+type styledElement struct {
+	elm        *document.Element
+	candidates []int
+	matched    []int
+	inline     []rules.Rule
+}
+
+type styleEvent struct {
+	ui      *ui.UI
+	evtType ui.EventType
+	id      events.Id
+}
+
+// styler holds the style sheet of a document after it has been applied so
+// that the elements can be restyled as their state changes
+type styler struct {
+	doc       *document.Document
+	host      *engine.Host
+	keyframes map[string]rules.Keyframes
+	entries   []styleEntry
+	elements  []styledElement
+	index     map[*document.Element]int
+	unstyled  map[*document.Element]bool
+	events    []styleEvent
+}
+
+func newStyler(s rules.StyleSheet, doc *document.Document, host *engine.Host) *styler {
+	st := &styler{doc: doc, host: host, keyframes: s.Keyframes}
+	for _, group := range s.Groups {
+		for _, sel := range group.Selectors {
+			compounds := sel.Compounds()
+			if len(compounds) == 0 {
+				continue
+			}
+			st.entries = append(st.entries, styleEntry{
+				selector: sel,
+				subject:  compounds[len(compounds)-1],
+				match: selector.Match{
+					Specificity: selector.Of(sel),
+					Order:       len(st.entries),
+					Rules:       group.Rules,
+				},
+				dynamic: len(selector.Dependencies(sel)) > 0,
+			})
+		}
+	}
+	return st
+}
+
+// apply matches every element of the document and styles the ones that have
+// any rules, this is done in document order so parents are styled first
+func (s *styler) apply() {
+	s.elements = s.elements[:0]
+	s.index = make(map[*document.Element]int)
+	s.unstyled = make(map[*document.Element]bool)
+	for _, elm := range s.doc.Elements {
+		if elm.IsElement() && elm.UIPanel != nil {
+			s.index[elm] = len(s.elements)
+			s.elements = append(s.elements, styledElement{elm: elm})
+		}
+	}
+	for i := range s.elements {
+		se := &s.elements[i]
+		se.inline = parseInline(se.elm)
+		s.findCandidates(se)
+		se.matched = s.match(se)
+		// Elements without rules are still styled for the custom properties
+		// they inherit and pass on to their children
+		s.style(se)
+		if len(se.matched) > 0 || len(se.inline) > 0 {
+			ApplyElementStyle(se.elm, s.keyframes, s.host)
+		}
+	}
+	s.subscribe()
+}
+
+func parseInline(elm *document.Element) []rules.Rule {
+	inlineStyle := elm.Attribute("style")
+	if inlineStyle == "" {
+		return nil
+	}
+	sheet := rules.NewStyleSheet()
+	return sheet.ParseInline(inlineStyle).Rules
+}
+
+// findCandidates keeps the entries that could match the element, only the
+// dynamic ones need to be matched again when a state changes
+func (s *styler) findCandidates(se *styledElement) {
+	se.candidates = se.candidates[:0]
+	node := elementNode{se.elm}
+	for i := range s.entries {
+		if selector.MatchesStatic(s.entries[i].subject, node) {
+			se.candidates = append(se.candidates, i)
+		}
+	}
+}
+
+func (s *styler) match(se *styledElement) []int {
+	matched := make([]int, 0, len(se.candidates))
+	node := elementNode{se.elm}
+	for _, i := range se.candidates {
+		if matcher.Matches(s.entries[i].selector, node) {
+			matched = append(matched, i)
+		}
+	}
+	return matched
+}
+
+// style cascades the matched rules of the element, resolving the custom
+// properties it inherits and the functions within its values
+func (s *styler) style(se *styledElement) {
+	matches := make([]selector.Match, len(se.matched))
+	for i, m := range se.matched {
+		matches[i] = s.entries[m].match
+	}
+	style := selector.Cascade(matches, se.inline)
+	se.elm.CustomProperties = customProperties(s.inheritedVars(se.elm), style)
+	se.elm.StyleRules, _ = resolveStyle(se.elm, style)
+}
+
+// inheritedVars are the custom properties of the parent of the element.
+// Parents that are not styled, like <html>, are matched here so the custom
+// properties set on :root are inherited.
+func (s *styler) inheritedVars(elm *document.Element) map[string][]rules.PropertyValue {
+	p := elm.Parent.Value()
+	if p == nil || !p.IsElement() {
+		return nil
+	}
+	if _, ok := s.index[p]; ok || s.unstyled[p] {
+		return p.CustomProperties
+	}
+	s.unstyled[p] = true
+	matches := make([]selector.Match, 0)
+	for i := range s.entries {
+		if matcher.Matches(s.entries[i].selector, elementNode{p}) {
+			matches = append(matches, s.entries[i].match)
+		}
+	}
+	style := selector.Cascade(matches, parseInline(p))
+	p.CustomProperties = customProperties(s.inheritedVars(p), style)
+	return p.CustomProperties
+}
+
+// restyle applies the style of the element again, when this changes the
+// custom properties of the element its descendants are restyled as well
+func (s *styler) restyle(se *styledElement) {
+	before := se.elm.CustomProperties
+	s.restyleElement(se)
+	if sameCustomProperties(before, se.elm.CustomProperties) {
+		return
+	}
+	for i := range s.elements {
+		if isDescendant(s.elements[i].elm, se.elm) {
+			s.restyleElement(&s.elements[i])
+		}
+	}
+}
+
+func isDescendant(elm, ancestor *document.Element) bool {
+	for p := elm.Parent.Value(); p != nil; p = p.Parent.Value() {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// restyleElement applies the style of the element again without starting
+// over the animations it is playing, so changes in state can be transitioned
+func (s *styler) restyleElement(se *styledElement) {
+	s.style(se)
+	elm := se.elm
+	elm.ClearStyleFunctions()
+	// A property that is no longer in the style should not keep its events
+	elm.ClearStyleEvents()
+	if elm.Animations == nil || !animation.HasEffects(elm.StyleRules) {
+		setupAnimations(elm, s.keyframes, s.host)
+	}
+	animateStyle(elm, elm.StyleRules, s.host)
+	applyStyle(elm, elm.StyleRules, s.host)
+}
+
+// update matches the elements again and restyles the ones whose matches have
+// changed, when dynamicOnly is set only the elements that could match a
+// dynamic entry are matched again
+func (s *styler) update(dynamicOnly bool) {
+	for i := range s.elements {
+		se := &s.elements[i]
+		if dynamicOnly && !s.isDynamic(se) {
+			continue
+		}
+		if matched := s.match(se); !slices.Equal(matched, se.matched) {
+			se.matched = matched
+			s.restyle(se)
+		}
+	}
+}
+
+func (s *styler) isDynamic(se *styledElement) bool {
+	for _, c := range se.candidates {
+		if s.entries[c].dynamic {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *styler) stateChanged() { s.update(true) }
+
+// subscribe listens for the state changes of the elements that the dynamic
+// entries depend on
+func (s *styler) subscribe() {
+	s.unsubscribe()
+	type key struct {
+		ui      *ui.UI
+		evtType ui.EventType
+	}
+	added := make(map[key]bool)
+	listen := func(elm *document.Element, state selector.State) {
+		for _, evtType := range stateEvents[state] {
+			k := key{elm.UI, evtType}
+			if added[k] {
+				continue
+			}
+			added[k] = true
+			id := elm.UI.AddEvent(evtType, s.stateChanged)
+			s.events = append(s.events, styleEvent{elm.UI, evtType, id})
+		}
+	}
+	for i := range s.entries {
+		if !s.entries[i].dynamic {
+			continue
+		}
+		for _, dep := range selector.Dependencies(s.entries[i].selector) {
+			for j := range s.elements {
+				elm := s.elements[j].elm
+				if dep.Parts == nil || selector.MatchesStatic(dep.Parts, elementNode{elm}) {
+					listen(elm, dep.State)
+				}
+			}
+		}
+	}
+}
+
+func (s *styler) unsubscribe() {
+	for _, e := range s.events {
+		e.ui.RemoveEvent(e.evtType, e.id)
+	}
+	s.events = s.events[:0]
+}
+
+// AttributeChanged restyles the document for the changed attribute of the
+// element, changes to the inline style only restyle the element itself
+func (s *styler) AttributeChanged(elm *document.Element, key string) {
+	if key == "style" {
+		if i, ok := s.index[elm]; ok {
+			s.elements[i].inline = parseInline(elm)
+			s.restyle(&s.elements[i])
+		}
+		return
+	}
+	clear(s.unstyled)
+	for i := range s.elements {
+		s.findCandidates(&s.elements[i])
+	}
+	s.update(false)
+	// The element is restyled even if its matches are the same, as its
+	// values may read the attribute through attr()
+	if i, ok := s.index[elm]; ok {
+		s.restyle(&s.elements[i])
+	}
+	s.subscribe()
+}
+
+// ElementsChanged styles the elements that were added to the document and
+// restyles the ones whose matches changed with the structure of the document
+func (s *styler) ElementsChanged(added []*document.Element) {
+	previous := make(map[*document.Element]styledElement, len(s.elements))
+	for _, se := range s.elements {
+		previous[se.elm] = se
+	}
+	s.elements = s.elements[:0]
+	clear(s.index)
+	clear(s.unstyled)
+	for _, elm := range s.doc.Elements {
+		if !elm.IsElement() || elm.UIPanel == nil {
+			continue
+		}
+		se, ok := previous[elm]
+		if !ok {
+			se = styledElement{elm: elm, inline: parseInline(elm)}
+		}
+		s.index[elm] = len(s.elements)
+		s.elements = append(s.elements, se)
+	}
+	for i := range s.elements {
+		se := &s.elements[i]
+		s.findCandidates(se)
+		if _, existed := previous[se.elm]; !existed {
+			se.matched = s.match(se)
+		}
+	}
+	s.update(false)
+	for _, elm := range added {
+		if i, ok := s.index[elm]; ok {
+			if _, existed := previous[elm]; !existed {
+				s.style(&s.elements[i])
+				ApplyElementStyle(elm, s.keyframes, s.host)
+			}
+		} else if p := elm.Parent.Value(); p != nil && !elm.IsElement() {
+			// New text takes on the text styles of its parent
+			if i, ok := s.index[p]; ok {
+				s.restyle(&s.elements[i])
+			}
+		}
+	}
+	s.subscribe()
+}
+
+func (s *styler) Release() { s.unsubscribe() }
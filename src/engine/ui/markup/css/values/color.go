@@ -0,0 +1,154 @@
+/******************************************************************************/
+/* color.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package values
+
+import (
+	"fmt"
+	"kaiju/matrix"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RGB reads the arguments of rgb() or rgba(), either as 3 channels with an
+// optional alpha or in the space separated form, like rgb(255 0 0 / 50%)
+func RGB(args []string) (matrix.Color8, error) {
+	parts, err := colorParts(args)
+	if err != nil {
+		return matrix.Color8{}, err
+	}
+	c := [3]float64{}
+	for i := range c {
+		if v, ok := strings.CutSuffix(parts[i], "%"); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return matrix.Color8{}, fmt.Errorf("invalid color channel: %s", parts[i])
+			}
+			c[i] = f / 100
+		} else {
+			f, err := strconv.ParseFloat(parts[i], 64)
+			if err != nil {
+				return matrix.Color8{}, fmt.Errorf("invalid color channel: %s", parts[i])
+			}
+			c[i] = f / 255
+		}
+	}
+	a, err := colorAlpha(parts)
+	return color8(c[0], c[1], c[2], a), err
+}
+
+// HSL reads the arguments of hsl() or hsla(), the hue can be given in deg,
+// rad, grad or turn and is in degrees when it has no unit
+func HSL(args []string) (matrix.Color8, error) {
+	parts, err := colorParts(args)
+	if err != nil {
+		return matrix.Color8{}, err
+	}
+	h, err := hue(parts[0])
+	if err != nil {
+		return matrix.Color8{}, err
+	}
+	s, errS := strconv.ParseFloat(strings.TrimSuffix(parts[1], "%"), 64)
+	l, errL := strconv.ParseFloat(strings.TrimSuffix(parts[2], "%"), 64)
+	if errS != nil || errL != nil {
+		return matrix.Color8{}, fmt.Errorf("invalid saturation or lightness: %s %s", parts[1], parts[2])
+	}
+	s, l = clamp01(s/100), clamp01(l/100)
+	f := func(n float64) float64 {
+		k := math.Mod(n+h/30, 12)
+		return l - s*min(l, 1-l)*max(-1, min(k-3, 9-k, 1))
+	}
+	a, err := colorAlpha(parts)
+	return color8(f(0), f(8), f(4), a), err
+}
+
+func colorParts(args []string) ([]string, error) {
+	parts := make([]string, 0, len(args))
+	for _, a := range args {
+		if a != "/" && a != "," {
+			parts = append(parts, a)
+		}
+	}
+	if len(parts) < 3 || len(parts) > 4 {
+		return nil, fmt.Errorf("expected 3 or 4 color arguments but got %d", len(parts))
+	}
+	return parts, nil
+}
+
+func colorAlpha(parts []string) (float64, error) {
+	if len(parts) < 4 {
+		return 1, nil
+	}
+	if v, ok := strings.CutSuffix(parts[3], "%"); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		return f / 100, err
+	}
+	return strconv.ParseFloat(parts[3], 64)
+}
+
+func hue(str string) (float64, error) {
+	units := []struct {
+		suffix string
+		scale  float64
+	}{
+		{"grad", 0.9}, {"turn", 360}, {"deg", 1}, {"rad", 180 / math.Pi},
+	}
+	scale := 1.0
+	for _, u := range units {
+		if v, ok := strings.CutSuffix(str, u.suffix); ok {
+			str, scale = v, u.scale
+			break
+		}
+	}
+	h, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hue: %s", str)
+	}
+	h = math.Mod(h*scale, 360)
+	if h < 0 {
+		h += 360
+	}
+	return h, nil
+}
+
+func clamp01(v float64) float64 { return max(0, min(1, v)) }
+
+func color8(r, g, b, a float64) matrix.Color8 {
+	c := func(v float64) uint8 { return uint8(math.Round(clamp01(v) * 255)) }
+	return matrix.Color8{R: c(r), G: c(g), B: c(b), A: c(a)}
+}
@@ -0,0 +1,196 @@
+/******************************************************************************/
+/* expression.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package values
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Evaluate solves a math expression, as found in the arguments of calc(), into
+// a single number. The tokens can hold +, -, * and /, parentheses and nested
+// calc(), min(), max() and clamp() functions. Plain numbers are read as they
+// are, any other operand (like 10px or 50%) is given to length to resolve.
+func Evaluate(tokens []string, length func(token string) float64) (float64, error) {
+	e := evaluator{tokens: tokens, length: length}
+	v, err := e.sum()
+	if err == nil && e.pos < len(e.tokens) {
+		err = fmt.Errorf("unexpected %q in expression", e.tokens[e.pos])
+	}
+	return v, err
+}
+
+type evaluator struct {
+	tokens []string
+	pos    int
+	length func(token string) float64
+}
+
+func (e *evaluator) peek() string {
+	if e.pos < len(e.tokens) {
+		return e.tokens[e.pos]
+	}
+	return ""
+}
+
+func (e *evaluator) sum() (float64, error) {
+	v, err := e.product()
+	for err == nil {
+		var rhs float64
+		switch e.peek() {
+		case "+":
+			e.pos++
+			rhs, err = e.product()
+			v += rhs
+		case "-":
+			e.pos++
+			rhs, err = e.product()
+			v -= rhs
+		default:
+			return v, nil
+		}
+	}
+	return v, err
+}
+
+func (e *evaluator) product() (float64, error) {
+	v, err := e.operand()
+	for err == nil {
+		var rhs float64
+		switch e.peek() {
+		case "*":
+			e.pos++
+			rhs, err = e.operand()
+			v *= rhs
+		case "/":
+			e.pos++
+			if rhs, err = e.operand(); err == nil && rhs == 0 {
+				err = errors.New("division by zero in expression")
+			}
+			v /= rhs
+		default:
+			return v, nil
+		}
+	}
+	return v, err
+}
+
+func (e *evaluator) operand() (float64, error) {
+	tok := e.peek()
+	if tok == "" {
+		return 0, errors.New("unexpected end of expression")
+	}
+	e.pos++
+	switch strings.ToLower(tok) {
+	case "(", "calc(":
+		v, err := e.sum()
+		if err != nil {
+			return v, err
+		}
+		return v, e.expect(")")
+	case "min(", "max(", "clamp(":
+		args, err := e.arguments()
+		if err != nil {
+			return 0, err
+		}
+		return mathFunction(strings.ToLower(tok), args)
+	case "-":
+		v, err := e.operand()
+		return -v, err
+	case "+":
+		return e.operand()
+	}
+	if strings.HasSuffix(tok, "(") {
+		return 0, fmt.Errorf("unsupported function %q in expression", tok)
+	} else if f, err := strconv.ParseFloat(tok, 64); err == nil {
+		return f, nil
+	}
+	return e.length(tok), nil
+}
+
+// arguments reads the expressions up to the closing parenthesis, since the
+// commas are not kept an argument ends where an operand follows an operand
+func (e *evaluator) arguments() ([]float64, error) {
+	args := make([]float64, 0, 2)
+	for {
+		v, err := e.sum()
+		if err != nil {
+			return args, err
+		}
+		args = append(args, v)
+		switch e.peek() {
+		case ")":
+			e.pos++
+			return args, nil
+		case "":
+			return args, errors.New("missing ) in expression")
+		}
+	}
+}
+
+func (e *evaluator) expect(tok string) error {
+	if e.peek() != tok {
+		return fmt.Errorf("expected %q in expression", tok)
+	}
+	e.pos++
+	return nil
+}
+
+func mathFunction(name string, args []float64) (float64, error) {
+	switch name {
+	case "min(":
+		v := args[0]
+		for _, a := range args[1:] {
+			v = min(v, a)
+		}
+		return v, nil
+	case "max(":
+		v := args[0]
+		for _, a := range args[1:] {
+			v = max(v, a)
+		}
+		return v, nil
+	default:
+		if len(args) != 3 {
+			return 0, fmt.Errorf("clamp() expects 3 arguments but got %d", len(args))
+		}
+		return max(args[0], min(args[1], args[2])), nil
+	}
+}
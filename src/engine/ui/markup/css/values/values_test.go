@@ -0,0 +1,178 @@
+/******************************************************************************/
+/* values_test.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package values
+
+import (
+	"kaiju/engine/ui/markup/css/rules"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func px(token string) float64 {
+	v, _ := strconv.ParseFloat(strings.TrimSuffix(token, "px"), 64)
+	return v
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"10px", 10},
+		{"10px + 5px * 2", 20},
+		{"( 10px + 5px ) * 2", 30},
+		{"100px / 4 - 5px", 20},
+		{"calc( 1px + 2px ) * 3", 9},
+		{"min( 10px + 5px 12px )", 12},
+		{"max( 1px 2px calc( 1px + 2px ) )", 3},
+		{"clamp( 10px 50px 20px )", 20},
+		{"- 5px + 10px", 5},
+	}
+	for _, test := range tests {
+		got, err := Evaluate(strings.Fields(test.expr), px)
+		if err != nil {
+			t.Errorf("%q failed: %v", test.expr, err)
+		} else if math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%q expected %v but got %v", test.expr, test.want, got)
+		}
+	}
+	for _, bad := range []string{"10px +", "( 1px", "1px / 0", "1px 2px", "var( --x )"} {
+		if _, err := Evaluate(strings.Fields(bad), px); err == nil {
+			t.Errorf("%q expected an error", bad)
+		}
+	}
+}
+
+func TestColors(t *testing.T) {
+	tests := []struct {
+		fn   func([]string) (string, error)
+		args string
+		want string
+	}{
+		{hex(RGB), "255 0 0", "#ff0000ff"},
+		{hex(RGB), "0 128 255 0.5", "#0080ff80"},
+		{hex(RGB), "100% 50% 0% / 25%", "#ff800040"},
+		{hex(HSL), "0 100% 50%", "#ff0000ff"},
+		{hex(HSL), "120deg 100% 25%", "#008000ff"},
+		{hex(HSL), "0.5turn 100% 50% / 0", "#00ffff00"},
+		{hex(HSL), "240 100 50", "#0000ffff"},
+	}
+	for _, test := range tests {
+		got, err := test.fn(strings.Fields(test.args))
+		if err != nil {
+			t.Errorf("%q failed: %v", test.args, err)
+		} else if got != test.want {
+			t.Errorf("%q expected %s but got %s", test.args, test.want, got)
+		}
+	}
+	if _, err := RGB([]string{"1", "2"}); err == nil {
+		t.Error("expected an error for too few channels")
+	}
+}
+
+func hex[T interface{ Hex() string }](fn func([]string) (T, error)) func([]string) (string, error) {
+	return func(args []string) (string, error) {
+		c, err := fn(args)
+		return c.Hex(), err
+	}
+}
+
+func parseValues(t *testing.T, css string) []rules.PropertyValue {
+	t.Helper()
+	sheet := rules.NewStyleSheet()
+	return sheet.ParseInline("x: " + css).Rules[0].Values
+}
+
+func TestSubstitute(t *testing.T) {
+	vars := map[string][]rules.PropertyValue{
+		"--pad":   parseValues(t, "4px"),
+		"--color": parseValues(t, "rgb(1, 2, 3)"),
+		"--twice": parseValues(t, "calc(var(--pad) * 2)"),
+		"--loop":  parseValues(t, "var(--loop)"),
+	}
+	lookup := func(name string) ([]rules.PropertyValue, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+	tests := []struct {
+		css  string
+		want []string
+	}{
+		{"var(--pad) 2px", []string{"4px", "2px"}},
+		{"var(--missing, 1px 2px)", []string{"1px", "2px"}},
+		{"var(--missing, var(--pad))", []string{"4px"}},
+		{"var(--color)", []string{"rgb(", "1", "2", "3", ")"}},
+		{"calc(var(--pad) + 1px)", []string{"calc(", "4px", "+", "1px", ")"}},
+		{"var(--twice)", []string{"calc(", "4px", "*", "2", ")"}},
+	}
+	for _, test := range tests {
+		res, err := Substitute(parseValues(t, test.css), lookup)
+		if err != nil {
+			t.Errorf("%q failed: %v", test.css, err)
+		} else if got := Tokens(res); !slices.Equal(got, test.want) {
+			t.Errorf("%q expected %v but got %v", test.css, test.want, got)
+		}
+	}
+	if _, err := Substitute(parseValues(t, "var(--loop)"), lookup); err == nil {
+		t.Error("expected an error for a custom property that references itself")
+	}
+	if _, err := Substitute(parseValues(t, "var(--missing)"), lookup); err == nil {
+		t.Error("expected an error for an undefined custom property")
+	}
+}
+
+func TestCustomPropertyRules(t *testing.T) {
+	sheet := rules.NewStyleSheet()
+	sheet.Parse(":root { --accent: #f00; --pad: calc(2px + 3px) 4px; } a { color: var(--accent); }")
+	if len(sheet.Groups) != 2 {
+		t.Fatalf("expected 2 groups but got %d", len(sheet.Groups))
+	}
+	root := sheet.Groups[0].Rules
+	if len(root) != 2 || root[0].Property != "--accent" || root[1].Property != "--pad" {
+		t.Fatalf("expected the custom properties as rules but got %v", root)
+	}
+	if got := Tokens(root[1].Values); !slices.Equal(got, []string{"calc(", "2px", "+", "3px", ")", "4px"}) {
+		t.Errorf("unexpected value for --pad: %v", got)
+	}
+	if sheet.Groups[1].Rules[0].Values[0].Str != "var" {
+		t.Errorf("expected var() in the color but got %v", sheet.Groups[1].Rules[0].Values)
+	}
+}
@@ -0,0 +1,179 @@
+/******************************************************************************/
+/* vars.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package values
+
+import (
+	"errors"
+	"fmt"
+	"kaiju/engine/ui/markup/css/rules"
+	"strings"
+)
+
+// maxVarDepth limits how deep custom properties can reference each other, it
+// is how a custom property that references itself is caught
+const maxVarDepth = 32
+
+// IsCustomProperty returns true for the name of a custom property, like
+// --accent
+func IsCustomProperty(name string) bool { return strings.HasPrefix(name, "--") }
+
+// Substitute replaces the var() functions within the values, including those
+// nested in the arguments of other functions, with the values of the custom
+// properties found through lookup. When a custom property is not found, the
+// fallback given to var() is used in its place.
+func Substitute(values []rules.PropertyValue, lookup func(name string) ([]rules.PropertyValue, bool)) ([]rules.PropertyValue, error) {
+	return substitute(values, lookup, 0)
+}
+
+func substitute(values []rules.PropertyValue, lookup func(string) ([]rules.PropertyValue, bool), depth int) ([]rules.PropertyValue, error) {
+	if depth > maxVarDepth {
+		return nil, errors.New("custom properties reference each other in a cycle")
+	}
+	out := make([]rules.PropertyValue, 0, len(values))
+	for _, v := range values {
+		if !v.IsFunction() {
+			out = append(out, v)
+			continue
+		}
+		if v.Str == "var" {
+			tokens, err := resolveVar(v.Args, lookup, depth)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, FromTokens(tokens)...)
+			continue
+		}
+		args, err := substituteTokens(v.Args, lookup, depth)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rules.PropertyValue{Str: v.Str, Args: args})
+	}
+	return out, nil
+}
+
+// substituteTokens replaces the var( ... ) runs within the arguments of a
+// function
+func substituteTokens(tokens []string, lookup func(string) ([]rules.PropertyValue, bool), depth int) ([]string, error) {
+	out := make([]string, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i] != "var(" {
+			out = append(out, tokens[i])
+			continue
+		}
+		end := closing(tokens, i)
+		if end < 0 {
+			return nil, errors.New("missing ) for var()")
+		}
+		res, err := resolveVar(tokens[i+1:end], lookup, depth)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res...)
+		i = end
+	}
+	return out, nil
+}
+
+func resolveVar(args []string, lookup func(string) ([]rules.PropertyValue, bool), depth int) ([]string, error) {
+	if len(args) == 0 || !IsCustomProperty(args[0]) {
+		return nil, errors.New("var() expects the name of a custom property")
+	}
+	if vals, ok := lookup(args[0]); ok {
+		res, err := substitute(vals, lookup, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return Tokens(res), nil
+	} else if len(args) > 1 {
+		return substituteTokens(args[1:], lookup, depth+1)
+	}
+	return nil, fmt.Errorf("custom property %s is not defined", args[0])
+}
+
+func closing(tokens []string, start int) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		if strings.HasSuffix(tokens[i], "(") {
+			depth++
+		} else if tokens[i] == ")" {
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Tokens flattens the values into tokens, functions are written the way they
+// are kept in the arguments of other functions, like calc( 1px + 2px )
+func Tokens(values []rules.PropertyValue) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v.IsFunction() {
+			out = append(out, v.Str+"(")
+			out = append(out, v.Args...)
+			out = append(out, ")")
+		} else {
+			out = append(out, v.Str)
+		}
+	}
+	return out
+}
+
+// FromTokens is the opposite of Tokens, it rebuilds the values with functions
+// holding the tokens between their parentheses as arguments
+func FromTokens(tokens []string) []rules.PropertyValue {
+	out := make([]rules.PropertyValue, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		name, isFunc := strings.CutSuffix(tokens[i], "(")
+		if !isFunc || name == "" {
+			out = append(out, rules.PropertyValue{Str: tokens[i], Args: []string{}})
+			continue
+		}
+		end := closing(tokens, i)
+		if end < 0 {
+			end = len(tokens)
+		}
+		args := make([]string, 0, end-i-1)
+		args = append(args, tokens[i+1:min(end, len(tokens))]...)
+		out = append(out, rules.PropertyValue{Str: name, Args: args})
+		i = end
+	}
+	return out
+}
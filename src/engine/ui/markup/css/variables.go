@@ -0,0 +1,138 @@
+/******************************************************************************/
+/* variables.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package css
+
+import (
+	"kaiju/engine/ui/markup/css/functions"
+	"kaiju/engine/ui/markup/css/rules"
+	"kaiju/engine/ui/markup/css/values"
+	"kaiju/engine/ui/markup/document"
+	"maps"
+	"slices"
+)
+
+// eagerFunctions are solved while the element is styled so that properties
+// and transitions only see their results
+var eagerFunctions = map[string]bool{
+	"rgb": true, "rgba": true, "hsl": true, "hsla": true, "attr": true,
+	"calc": true, "min": true, "max": true,
+}
+
+// layoutFunctions are solved by the layout for width and height, as their
+// percentages depend on the size of the parent at the time of layout
+var layoutFunctions = map[string]bool{"calc": true, "min": true, "max": true}
+
+// customProperties are the inherited custom properties along with the ones
+// declared in the style of the element. A custom property is resolved where
+// it is declared, so var() within it reads the properties of this element.
+func customProperties(inherited map[string][]rules.PropertyValue, style []rules.Rule) map[string][]rules.PropertyValue {
+	declared := make(map[string][]rules.PropertyValue)
+	for i := range style {
+		if values.IsCustomProperty(style[i].Property) {
+			declared[style[i].Property] = style[i].Values
+		}
+	}
+	if len(declared) == 0 {
+		return inherited
+	}
+	lookup := func(name string) ([]rules.PropertyValue, bool) {
+		if v, ok := declared[name]; ok {
+			return v, true
+		}
+		v, ok := inherited[name]
+		return v, ok
+	}
+	out := make(map[string][]rules.PropertyValue, len(inherited)+len(declared))
+	maps.Copy(out, inherited)
+	for name, v := range declared {
+		// A custom property that can't be resolved is as if it wasn't set
+		if res, err := values.Substitute(v, lookup); err == nil {
+			out[name] = res
+		} else {
+			delete(out, name)
+		}
+	}
+	return out
+}
+
+// resolveStyle substitutes the var() functions within the style and solves
+// the functions that don't depend on the layout. Custom properties are left
+// out, they are held in the CustomProperties of the element.
+func resolveStyle(elm *document.Element, style []rules.Rule) ([]rules.Rule, []error) {
+	out := make([]rules.Rule, 0, len(style))
+	problems := make([]error, 0)
+	for i := range style {
+		prop := style[i].Property
+		if values.IsCustomProperty(prop) {
+			continue
+		}
+		vals, err := values.Substitute(style[i].Values, elm.CustomProperty)
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		for j := range vals {
+			name := vals[j].Str
+			if !vals[j].IsFunction() || !eagerFunctions[name] {
+				continue
+			} else if layoutFunctions[name] && (prop == "width" || prop == "height") {
+				continue
+			}
+			v := vals[j]
+			if layoutFunctions[name] {
+				v.Args = append(slices.Clone(v.Args), prop)
+			}
+			res, err := functions.FunctionMap[name].Process(elm.UIPanel, elm, v)
+			if err != nil {
+				problems = append(problems, err)
+				continue
+			}
+			vals[j] = rules.PropertyValue{Str: res, Args: []string{}}
+		}
+		out = append(out, rules.Rule{Property: prop, Values: vals})
+	}
+	return out, problems
+}
+
+func sameCustomProperties(a, b map[string][]rules.PropertyValue) bool {
+	return maps.EqualFunc(a, b, func(x, y []rules.PropertyValue) bool {
+		return slices.EqualFunc(x, y, func(p, q rules.PropertyValue) bool {
+			return p.Str == q.Str && slices.Equal(p.Args, q.Args)
+		})
+	})
+}
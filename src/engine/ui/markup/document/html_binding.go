@@ -0,0 +1,629 @@
+/******************************************************************************/
+/* html_binding.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package document
+
+import (
+	"kaiju/engine/systems/events"
+	"kaiju/engine/ui"
+	"kaiju/engine/ui/markup/binding"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// bindings are the data-* attributes of a document connected to its model
+type bindings struct {
+	root     *binding.Scope
+	set      bindingSet
+	created  []*Element
+	updateId int
+}
+
+// bindingSet holds the bindings of a part of the document, each row of a
+// data-for loop has its own so they are released along with the row
+type bindingSet struct {
+	values     []*valueBinding
+	conditions []*conditionBinding
+	loops      []*loopBinding
+}
+
+// valueBinding is a data-bind (attr is empty) or a data-bind-<attr> on an
+// element, last is the text the element was last given
+type valueBinding struct {
+	elm      *Element
+	scope    *binding.Scope
+	path     string
+	attr     string
+	last     string
+	synced   bool
+	failed   bool
+	writing  bool
+	changeId events.Id
+}
+
+// conditionBinding is a data-if on an element, shown is the state the element
+// was last put in (-1 before the first update)
+type conditionBinding struct {
+	elm    *Element
+	scope  *binding.Scope
+	path   string
+	negate bool
+	shown  int
+	failed bool
+}
+
+// loopBinding is a data-for, the element that held it is kept as a template
+// for the rows. The rows of the loop are placed after the element (or the
+// loop) that came before the template in its parent. The node of the element
+// is kept as it was to put it back when the document is unbound.
+type loopBinding struct {
+	parent    *Element
+	template  *html.Node
+	source    *html.Node
+	scope     *binding.Scope
+	name      string
+	path      string
+	after     *Element
+	afterLoop *loopBinding
+	rows      []*loopRow
+	failed    bool
+}
+
+type loopRow struct {
+	elm *Element
+	set bindingSet
+}
+
+// Bind connects the document to the model through the attributes below, the
+// paths are field names (or methods), indexes and map keys of the model.
+//
+//	data-bind="Player.Health"       the text of the element, or the value of an input
+//	data-bind-src="Player.Portrait" any other attribute of the element
+//	data-if="Player.IsAlive"        shows the element while the value is true (use !Path to invert)
+//	data-for="item in Inventory"    repeats the element for each item of the slice
+//
+// The model is checked on each UI update and only the elements whose values
+// changed are patched. Inputs, checkboxes, sliders and selects write their
+// changes back to the model, for this the model needs to be a pointer.
+func (d *Document) Bind(model any) {
+	d.Unbind()
+	bodies := d.GetElementsByTagName("body")
+	if len(bodies) == 0 || d.uiMan == nil {
+		return
+	}
+	d.bindings = &bindings{root: binding.NewScope(model)}
+	d.bindChildren(bodies[0], d.bindings.root, &d.bindings.set)
+	// The data-for templates were taken out, so the document is restyled
+	// even if nothing else changed
+	d.syncBindings(true)
+	d.bindings.updateId = d.uiMan.Host.UIUpdater.AddUpdate(func(float64) {
+		d.UpdateBindings()
+	})
+}
+
+// Unbind stops the document from following its model, the elements keep the
+// values they were last given. The rows of the data-for loops are taken out
+// and their elements put back, so the document can be bound again.
+func (d *Document) Unbind() { d.unbind(true) }
+
+// unbind stops following the model, the loops are only restored when the
+// document is going to be kept
+func (d *Document) unbind(restoreLoops bool) {
+	b := d.bindings
+	if b == nil {
+		return
+	}
+	d.uiMan.Host.UIUpdater.RemoveUpdate(b.updateId)
+	if restoreLoops {
+		// In reverse as a loop finds its place from the rows of the loop
+		// before it
+		for i := len(b.set.loops) - 1; i >= 0; i-- {
+			d.restoreLoop(b.set.loops[i])
+		}
+	}
+	b.set.release()
+	if restoreLoops {
+		d.elementsChanged(b.created)
+	}
+	d.bindings = nil
+}
+
+// UpdateBindings patches the elements whose bound values changed in the model,
+// this is done on each UI update though it can be called to see the changes
+// right away
+func (d *Document) UpdateBindings() {
+	if d.bindings != nil {
+		d.syncBindings(false)
+	}
+}
+
+func (d *Document) syncBindings(changed bool) {
+	b := d.bindings
+	d.updateSet(&b.set, &changed)
+	if changed {
+		d.elementsChanged(b.created)
+	}
+	b.created = b.created[:0]
+}
+
+func (d *Document) bindChildren(parent *Element, scope *binding.Scope, set *bindingSet) {
+	var prev *Element
+	var prevLoop *loopBinding
+	for i := 0; i < len(parent.Children); {
+		c := parent.Children[i]
+		if c.IsElement() {
+			if expr := c.Attribute("data-for"); expr != "" {
+				if loop := d.bindLoop(c, scope, expr, prev, prevLoop); loop != nil {
+					set.loops = append(set.loops, loop)
+					prev, prevLoop = nil, loop
+					continue
+				}
+			}
+			d.bindElement(c, scope, set)
+		}
+		if c.UI != nil {
+			prev, prevLoop = c, nil
+		}
+		i++
+	}
+}
+
+func (d *Document) bindElement(elm *Element, scope *binding.Scope, set *bindingSet) {
+	if path := strings.TrimSpace(elm.Attribute("data-if")); path != "" {
+		negate := strings.HasPrefix(path, "!")
+		set.conditions = append(set.conditions, &conditionBinding{
+			elm:    elm,
+			scope:  scope,
+			path:   strings.TrimSpace(strings.TrimPrefix(path, "!")),
+			negate: negate,
+			shown:  -1,
+		})
+	}
+	for _, a := range slices.Clone(elm.node.Attr) {
+		if a.Key == "data-bind" {
+			set.values = append(set.values, d.bindValue(elm, scope, a.Val))
+		} else if attr, ok := strings.CutPrefix(a.Key, "data-bind-"); ok && attr != "" {
+			set.values = append(set.values, &valueBinding{
+				elm:   elm,
+				scope: scope,
+				path:  strings.TrimSpace(a.Val),
+				attr:  attr,
+			})
+		}
+	}
+	d.bindChildren(elm, scope, set)
+}
+
+func (d *Document) bindValue(elm *Element, scope *binding.Scope, path string) *valueBinding {
+	vb := &valueBinding{elm: elm, scope: scope, path: strings.TrimSpace(path)}
+	if elm.UI == nil {
+		return vb
+	}
+	switch elm.UI.Type() {
+	case ui.ElementTypeInput, ui.ElementTypeCheckbox,
+		ui.ElementTypeSlider, ui.ElementTypeSelect, ui.ElementTypeTextArea:
+		vb.changeId = elm.UI.AddEvent(ui.EventTypeChange, vb.writeBack)
+	}
+	return vb
+}
+
+// bindLoop takes the data-for element out of the document and keeps it as
+// the template of the rows of the loop
+func (d *Document) bindLoop(elm *Element, scope *binding.Scope, expr string, after *Element, afterLoop *loopBinding) *loopBinding {
+	name, path, err := binding.ParseLoop(expr)
+	if err != nil {
+		slog.Error("invalid data-for binding", "error", err)
+		return nil
+	}
+	template := cloneNode(elm.node)
+	template.Attr = slices.DeleteFunc(template.Attr, func(a html.Attribute) bool {
+		return a.Key == "data-for"
+	})
+	loop := &loopBinding{
+		parent:    elm.Parent.Value(),
+		template:  template,
+		source:    elm.node,
+		scope:     scope,
+		name:      name,
+		path:      path,
+		after:     after,
+		afterLoop: afterLoop,
+	}
+	d.destroyElement(elm)
+	return loop
+}
+
+func (d *Document) updateSet(set *bindingSet, changed *bool) {
+	for _, c := range set.conditions {
+		d.updateCondition(c)
+	}
+	for _, v := range set.values {
+		if d.updateValue(v) {
+			*changed = true
+		}
+	}
+	for _, l := range set.loops {
+		if d.updateLoop(l) {
+			*changed = true
+		}
+		for _, r := range l.rows {
+			d.updateSet(&r.set, changed)
+		}
+	}
+}
+
+func (d *Document) updateCondition(c *conditionBinding) {
+	v, err := c.scope.Lookup(c.path)
+	if err != nil {
+		if !c.failed {
+			slog.Error("failed to read the data-if binding", "error", err)
+			c.failed = true
+		}
+		return
+	}
+	c.failed = false
+	shown := 0
+	if binding.Truthy(v) != c.negate {
+		shown = 1
+	}
+	if shown == c.shown {
+		return
+	}
+	c.shown = shown
+	if shown == 1 {
+		c.elm.UI.Entity().Activate()
+	} else {
+		c.elm.UI.Entity().Deactivate()
+	}
+}
+
+// updateValue patches the element if the bound value changed, returning true
+// when the document needs to be restyled
+func (d *Document) updateValue(vb *valueBinding) bool {
+	v, err := vb.scope.Lookup(vb.path)
+	if err != nil {
+		if !vb.failed {
+			slog.Error("failed to read the data-bind binding", "error", err)
+			vb.failed = true
+		}
+		return false
+	}
+	vb.failed = false
+	text := binding.Format(v)
+	if vb.synced && text == vb.last {
+		return false
+	}
+	if vb.attr != "" {
+		vb.last, vb.synced = text, true
+		d.SetAttribute(vb.elm, vb.attr, text)
+		return false
+	}
+	u := vb.elm.UI
+	if u == nil {
+		return false
+	}
+	vb.writing = true
+	defer func() { vb.writing = false }()
+	switch u.Type() {
+	case ui.ElementTypeInput:
+		input := u.ToInput()
+		// Don't pull the text out from under the one typing it
+		if input.IsFocused() {
+			return false
+		}
+		input.SetTextWithoutEvent(text)
+	case ui.ElementTypeTextArea:
+		area := u.ToTextArea()
+		if area.IsFocused() {
+			return false
+		}
+		area.SetTextWithoutEvent(text)
+	case ui.ElementTypeCheckbox:
+		u.ToCheckbox().SetChecked(binding.Truthy(v))
+	case ui.ElementTypeSlider:
+		f, err := strconv.ParseFloat(text, 32)
+		if err != nil {
+			slog.Error("the data-bind of a slider needs a number", "path", vb.path)
+			return false
+		}
+		u.ToSlider().SetValue(float32(f))
+	case ui.ElementTypeSelect:
+		u.ToSelect().PickOptionByLabel(text)
+	default:
+		vb.last, vb.synced = text, true
+		return d.setElementText(vb.elm, text)
+	}
+	vb.last, vb.synced = text, true
+	return false
+}
+
+// writeBack sets the value of the input on the model
+func (vb *valueBinding) writeBack() {
+	if vb.writing {
+		return
+	}
+	u := vb.elm.UI
+	var value any
+	switch u.Type() {
+	case ui.ElementTypeInput:
+		value = u.ToInput().Text()
+	case ui.ElementTypeTextArea:
+		value = u.ToTextArea().Text()
+	case ui.ElementTypeCheckbox:
+		value = u.ToCheckbox().IsChecked()
+	case ui.ElementTypeSlider:
+		value = u.ToSlider().Value()
+	case ui.ElementTypeSelect:
+		value = u.ToSelect().Value()
+	default:
+		return
+	}
+	if err := vb.scope.Set(vb.path, value); err != nil {
+		slog.Error("failed to write the data-bind binding", "error", err)
+		return
+	}
+	// Read back what the model took so it isn't written over on the next update
+	if v, err := vb.scope.Lookup(vb.path); err == nil {
+		vb.last, vb.synced = binding.Format(v), true
+	}
+}
+
+func (vb *valueBinding) release() {
+	if vb.changeId != 0 {
+		vb.elm.UI.RemoveEvent(ui.EventTypeChange, vb.changeId)
+		vb.changeId = 0
+	}
+}
+
+// setElementText replaces the text of the element, a text element is created
+// if it has none. Returns true if an element was created.
+func (d *Document) setElementText(elm *Element, text string) bool {
+	for _, c := range elm.Children {
+		if c.IsText() {
+			c.node.Data = text
+			c.UI.ToLabel().SetText(labelText(text))
+			return false
+		}
+	}
+	if elm.UIPanel == nil {
+		return false
+	}
+	node := &html.Node{Type: html.TextNode, Data: text}
+	elm.node.AppendChild(node)
+	child := toElement(node)
+	elm.Children = append(elm.Children, child)
+	child.setParents(elm)
+	d.createElements(child, lastDescendant(elm))
+	d.elementsCreated(elm.Children[:len(elm.Children)-1], child)
+	return true
+}
+
+// updateLoop adds or removes rows so the loop has one for each item of the
+// slice, returning true if any were
+func (d *Document) updateLoop(l *loopBinding) bool {
+	v, err := l.scope.Lookup(l.path)
+	var count int
+	if err == nil {
+		count, err = binding.Len(v)
+	}
+	if err != nil {
+		if !l.failed {
+			slog.Error("failed to read the data-for binding", "error", err)
+			l.failed = true
+		}
+		return false
+	}
+	l.failed = false
+	if count == len(l.rows) {
+		return false
+	}
+	for len(l.rows) > count {
+		last := l.rows[len(l.rows)-1]
+		last.set.release()
+		d.destroyElement(last.elm)
+		l.rows = l.rows[:len(l.rows)-1]
+	}
+	pos := l.position()
+	for i := len(l.rows); i < count; i++ {
+		l.rows = append(l.rows, d.addRow(l, pos+i, i))
+	}
+	return true
+}
+
+func (l *loopBinding) position() int {
+	if l.afterLoop != nil {
+		return l.afterLoop.position() + len(l.afterLoop.rows)
+	} else if l.after != nil {
+		return slices.Index(l.parent.Children, l.after) + 1
+	}
+	return 0
+}
+
+// addRow builds the element for the item at index from the template of the
+// loop and puts it at pos in the children of the parent of the loop
+func (d *Document) addRow(l *loopBinding, pos, index int) *loopRow {
+	elm := d.insertNode(l.parent, cloneNode(l.template), pos)
+	elm.boundRow = true
+	row := &loopRow{elm: elm}
+	scope := l.scope.With(l.name, l.path+"["+strconv.Itoa(index)+"]")
+	d.bindElement(elm, scope, &row.set)
+	return row
+}
+
+// restoreLoop destroys the rows of the loop and puts the data-for element
+// back in their place
+func (d *Document) restoreLoop(l *loopBinding) {
+	for _, r := range l.rows {
+		r.set.release()
+		d.destroyElement(r.elm)
+	}
+	l.rows = nil
+	d.insertNode(l.parent, l.source, l.position())
+}
+
+// insertNode builds the element for the node and puts it at pos in the
+// children of the parent
+func (d *Document) insertNode(parent *Element, node *html.Node, pos int) *Element {
+	var next *html.Node
+	if pos < len(parent.Children) {
+		next = parent.Children[pos].node
+	}
+	parent.node.InsertBefore(node, next)
+	elm := toElement(node)
+	elm.setParents(parent)
+	parent.Children = slices.Insert(parent.Children, pos, elm)
+	prev := parent
+	for i := pos - 1; i >= 0; i-- {
+		if parent.Children[i].UI != nil {
+			prev = lastDescendant(parent.Children[i])
+			break
+		}
+	}
+	d.createElements(elm, prev)
+	d.elementsCreated(parent.Children[:pos], elm)
+	return elm
+}
+
+// createElements builds the UI for the element and its children, the new
+// elements are put right after prev in the elements of the document
+func (d *Document) createElements(elm *Element, prev *Element) {
+	start := len(d.Elements)
+	d.createUIElement(d.uiMan, elm, elm.Parent.Value().UIPanel)
+	created := slices.Clone(d.Elements[start:])
+	d.Elements = d.Elements[:start]
+	at := slices.Index(d.Elements, prev) + 1
+	d.Elements = slices.Insert(d.Elements, at, created...)
+	for _, e := range created {
+		setupEvents(e, d.funcMap)
+	}
+	d.bindings.created = append(d.bindings.created, created...)
+}
+
+// elementsCreated moves the entity of the new element after the entities of
+// the siblings that come before it
+func (d *Document) elementsCreated(before []*Element, elm *Element) {
+	if elm.UI == nil {
+		return
+	}
+	parent := elm.Parent.Value().UIPanel
+	idx := 0
+	for i := len(before) - 1; i >= 0; i-- {
+		if before[i].UI != nil {
+			idx = slices.Index(parent.Base().Entity().Children, before[i].UI.Entity()) + 1
+			break
+		}
+	}
+	parent.InsertChild(elm.UI, idx)
+}
+
+// destroyElement removes the element from the document and destroys its UI
+func (d *Document) destroyElement(elm *Element) {
+	d.removeElement(elm)
+	d.bindings.created = slices.DeleteFunc(d.bindings.created, func(e *Element) bool {
+		return e == elm || isChildOf(e, elm)
+	})
+	if elm.UI != nil {
+		elm.UI.Entity().Destroy()
+	}
+}
+
+// unsync has the bindings of the set patch their elements on the next update
+// even if the values didn't change, as the text of the elements was replaced
+func (s *bindingSet) unsync() {
+	for _, v := range s.values {
+		v.synced = false
+	}
+	for _, l := range s.loops {
+		for _, r := range l.rows {
+			r.set.unsync()
+		}
+	}
+}
+
+func (s *bindingSet) release() {
+	for _, v := range s.values {
+		v.release()
+	}
+	for _, l := range s.loops {
+		for _, r := range l.rows {
+			r.set.release()
+		}
+	}
+	*s = bindingSet{}
+}
+
+func cloneNode(node *html.Node) *html.Node {
+	clone := &html.Node{
+		Type:      node.Type,
+		DataAtom:  node.DataAtom,
+		Data:      node.Data,
+		Namespace: node.Namespace,
+		Attr:      slices.Clone(node.Attr),
+	}
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		clone.AppendChild(cloneNode(c))
+	}
+	return clone
+}
+
+func lastDescendant(elm *Element) *Element {
+	for {
+		var last *Element
+		for _, c := range elm.Children {
+			if c.UI != nil {
+				last = c
+			}
+		}
+		if last == nil {
+			return elm
+		}
+		elm = last
+	}
+}
+
+func isChildOf(elm, parent *Element) bool {
+	for p := elm.Parent.Value(); p != nil; p = p.Parent.Value() {
+		if p == parent {
+			return true
+		}
+	}
+	return false
+}
@@ -38,8 +38,11 @@
 package document
 
 import (
+	"kaiju/engine/systems/events"
 	"kaiju/engine/ui"
+	"kaiju/engine/ui/markup/css/animation"
 	"kaiju/engine/ui/markup/css/rules"
+	"kaiju/engine/ui/richtext"
 	"kaiju/matrix"
 	"strings"
 	"weak"
@@ -55,6 +58,80 @@ type Element struct {
 	node       *html.Node
 	attr       map[string]*html.Attribute
 	StyleRules []rules.Rule
+	// CustomProperties are the custom properties (--name) of the element,
+	// including the ones it inherited from its parents
+	CustomProperties map[string][]rules.PropertyValue
+	Animations       *ElementAnimations
+	// spans are the styles of the text of a text element made from inline
+	// elements like <b>, nil for plain text
+	spans []richtext.Span
+	// listeners are the event listeners of the element by the event type and
+	// listening has a bit set for each event type the UI is listened to for
+	listeners      map[ui.EventType][]eventListener
+	listening      uint64
+	nextListenerId EventListenerId
+	event          *Event
+	// boundRow is set on the elements made for the rows of a data-for loop
+	boundRow bool
+	// styleEvents are the ids of the UI events that the style properties of
+	// the element have added, so styling it again replaces them
+	styleEvents map[styleEventKey]events.Id
+	// styleFunctions are the layout functions that the style properties of
+	// the element have added, other layout functions of the UI are kept when
+	// it is restyled
+	styleFunctions []ui.LayoutFuncId
+}
+
+type styleEventKey struct {
+	property string
+	evtType  ui.EventType
+}
+
+// ElementAnimations holds the player for the transitions and animations of an
+// element, the style it was last given and the id of the update that drives
+// it (0 while nothing is playing)
+type ElementAnimations struct {
+	Player   *animation.Player
+	Style    []rules.Rule
+	UpdateId int
+}
+
+// SetStyleEvent adds the call to the event of the element's UI on behalf of a
+// style property, replacing the call that the property had added before. The
+// properties are processed each time the element is restyled or animated.
+func (e *Element) SetStyleEvent(property string, evtType ui.EventType, call func()) {
+	key := styleEventKey{property, evtType}
+	if e.styleEvents == nil {
+		e.styleEvents = make(map[styleEventKey]events.Id)
+	} else if id, ok := e.styleEvents[key]; ok {
+		e.UI.RemoveEvent(evtType, id)
+	}
+	e.styleEvents[key] = e.UI.AddEvent(evtType, call)
+}
+
+// ClearStyleEvents removes the calls that the style properties had added to
+// the events of the element's UI
+func (e *Element) ClearStyleEvents() {
+	for key, id := range e.styleEvents {
+		e.UI.RemoveEvent(key.evtType, id)
+	}
+	clear(e.styleEvents)
+}
+
+// AddStyleFunction adds the layout function to the element's UI on behalf of
+// a style property, it is removed by #Element.ClearStyleFunctions
+func (e *Element) AddStyleFunction(fn func(l *ui.Layout)) {
+	e.styleFunctions = append(e.styleFunctions, e.UI.Layout().AddFunction(fn))
+}
+
+// ClearStyleFunctions removes the layout functions that the style properties
+// had added to the element's UI
+func (e *Element) ClearStyleFunctions() {
+	layout := e.UI.Layout()
+	for _, id := range e.styleFunctions {
+		layout.RemoveFunction(id)
+	}
+	e.styleFunctions = e.styleFunctions[:0]
 }
 
 func (d Element) InnerLabel() *ui.Label {
@@ -90,6 +167,10 @@ func (e *Element) IsText() bool {
 	return e.node.Type == html.TextNode && (e.Parent.Value() == nil || e.Parent.Value().node.Data != "option")
 }
 
+func (e *Element) IsElement() bool {
+	return e.node.Type == html.ElementNode
+}
+
 func (e *Element) IsButton() bool {
 	return e.node.Data == "button"
 }
@@ -110,6 +191,18 @@ func (e *Element) IsSelectOption() bool {
 	return e.node.Data == "option"
 }
 
+func (e *Element) IsTextArea() bool {
+	return e.node.Data == "textarea"
+}
+
+func (e *Element) IsTree() bool {
+	return e.node.Data == "tree"
+}
+
+func (e *Element) IsTreeItem() bool {
+	return e.node.Data == "item"
+}
+
 func NewHTML(htmlStr string) *Element {
 	doc, _ := html.Parse(strings.NewReader(htmlStr))
 	return createElement(doc)
@@ -134,9 +227,7 @@ func toElement(node *html.Node) *Element {
 		attr:     make(map[string]*html.Attribute),
 		Children: make([]*Element, 0),
 	}
-	for i := 0; i < len(node.Attr); i++ {
-		elm.attr[node.Attr[i].Key] = &node.Attr[i]
-	}
+	elm.resetAttributes()
 	for c := node.FirstChild; c != nil; c = c.NextSibling {
 		if len(strings.TrimSpace(c.Data)) > 0 {
 			elm.Children = append(elm.Children, toElement(c))
@@ -145,6 +236,15 @@ func toElement(node *html.Node) *Element {
 	return &elm
 }
 
+// resetAttributes maps the attributes of the node, this is needed any time
+// the attribute slice of the node is changed
+func (e *Element) resetAttributes() {
+	clear(e.attr)
+	for i := 0; i < len(e.node.Attr); i++ {
+		e.attr[e.node.Attr[i].Key] = &e.node.Attr[i]
+	}
+}
+
 func (e *Element) Root() *Element {
 	if e.Parent.Value() == nil {
 		return e
@@ -190,6 +290,18 @@ func (e *Element) Attribute(key string) string {
 	return ""
 }
 
+// CustomProperty returns the value of a custom property (--name) that is set
+// on the element or inherited from its parents
+func (e *Element) CustomProperty(name string) ([]rules.PropertyValue, bool) {
+	v, ok := e.CustomProperties[name]
+	return v, ok
+}
+
+func (e *Element) HasAttribute(key string) bool {
+	_, ok := e.attr[key]
+	return ok
+}
+
 func (e *Element) FindElementById(id string) *Element {
 	if e.Attribute("id") == id {
 		return e
@@ -0,0 +1,328 @@
+/******************************************************************************/
+/* html_event.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package document
+
+import (
+	"errors"
+	"kaiju/engine/ui"
+	"kaiju/engine/ui/markup/propagation"
+	"kaiju/matrix"
+	"kaiju/platform/hid"
+	"slices"
+)
+
+// EventPhase is the part of the dispatch of an event that a listener is
+// being called in
+type EventPhase = propagation.Phase
+
+const (
+	EventPhaseNone      = propagation.PhaseNone
+	EventPhaseCapturing = propagation.PhaseCapturing
+	EventPhaseAtTarget  = propagation.PhaseAtTarget
+	EventPhaseBubbling  = propagation.PhaseBubbling
+)
+
+// EventListenerId is the id of a listener added with
+// #Element.AddEventListener, used to remove it
+type EventListenerId = int64
+
+// Event is an event being dispatched through the elements of a document. It
+// is first given to the capturing listeners of the ancestors of the target,
+// root first, then to the listeners of the target and then, if the event
+// bubbles, to the bubbling listeners of the ancestors back up to the root.
+type Event struct {
+	// Type is the name of the event without the "on", like "click"
+	Type string
+	// Target is the element the event happened on, for pointer events it's
+	// the innermost element under the cursor
+	Target *Element
+	// CurrentTarget is the element whose listener is being called
+	CurrentTarget *Element
+	// Cursor is the position of the cursor in the window, from the top left
+	Cursor matrix.Vec2
+	// Buttons has a bit set (1 << hid.MouseButtonLeft and so on) for each
+	// mouse button that is down
+	Buttons int
+	// Key is the key of a key down or key up event, otherwise it is
+	// #hid.KeyBoardKeyInvalid
+	Key              hid.KeyboardKey
+	Ctrl, Shift, Alt bool
+	ScrollDelta      matrix.Vec2
+	evtType          ui.EventType
+	// Flow has the phase of the event and the ways to stop it
+	propagation.Flow
+}
+
+type eventListener struct {
+	id      EventListenerId
+	call    func(*Event)
+	capture bool
+}
+
+// eventTypeNames are the names of the events, the "on" attributes in
+// #eventAttributes are aliases of these
+var eventTypeNames = map[string]ui.EventType{
+	"click":      ui.EventTypeClick,
+	"rightclick": ui.EventTypeRightClick,
+	"dblclick":   ui.EventTypeDoubleClick,
+	"miss":       ui.EventTypeMiss,
+	"submit":     ui.EventTypeSubmit,
+	"keydown":    ui.EventTypeKeyDown,
+	"keyup":      ui.EventTypeKeyUp,
+	"mouseenter": ui.EventTypeEnter,
+	"mouseleave": ui.EventTypeExit,
+	"mousemove":  ui.EventTypeMove,
+	"mousedown":  ui.EventTypeDown,
+	"mouseup":    ui.EventTypeUp,
+	"wheel":      ui.EventTypeScroll,
+	"change":     ui.EventTypeChange,
+	"input":      ui.EventTypeInput,
+	"focus":      ui.EventTypeFocus,
+	"blur":       ui.EventTypeBlur,
+	"dragenter":  ui.EventTypeDropEnter,
+	"dragleave":  ui.EventTypeDropExit,
+	"dragstart":  ui.EventTypeDragStart,
+	"drop":       ui.EventTypeDrop,
+	"dragend":    ui.EventTypeDragEnd,
+}
+
+func eventName(evtType ui.EventType) string {
+	for name, t := range eventTypeNames {
+		if t == evtType {
+			return name
+		}
+	}
+	return ""
+}
+
+func eventBubbles(evtType ui.EventType) bool {
+	return propagation.Bubbles(eventName(evtType))
+}
+
+// eventIsPointed returns true for the events that happen where the cursor
+// is, their target is the innermost element under the cursor
+func eventIsPointed(evtType ui.EventType) bool {
+	switch evtType {
+	case ui.EventTypeClick, ui.EventTypeRightClick, ui.EventTypeDoubleClick,
+		ui.EventTypeDown, ui.EventTypeUp, ui.EventTypeScroll, ui.EventTypeDrop:
+		return true
+	}
+	return false
+}
+
+// eventIsConsumed returns true for the events that the #ui.Group only gives
+// to the front most element with a handler for it, the rest are given to
+// every element they happen to
+func eventIsConsumed(evtType ui.EventType) bool {
+	switch evtType {
+	case ui.EventTypeMiss, ui.EventTypeKeyDown, ui.EventTypeKeyUp,
+		ui.EventTypeChange, ui.EventTypeSubmit, ui.EventTypeFocus,
+		ui.EventTypeBlur, ui.EventTypeInput:
+		return false
+	}
+	return true
+}
+
+// Event is the event currently being dispatched to the listeners of this
+// element, nil when there isn't one. It's how the functions of the "on"
+// attributes, which are only given the element, get to the event.
+func (e *Element) Event() *Event { return e.event }
+
+// AddEventListener calls the listener when the event with the name (like
+// "click" or "keydown") is dispatched to the element or one of its
+// descendants. A capturing listener is called on the way down to the target
+// rather than on the way back up.
+func (e *Element) AddEventListener(name string, listener func(*Event), capture bool) (EventListenerId, error) {
+	evtType, ok := eventTypeNames[name]
+	if !ok {
+		return 0, errors.New("unknown event type: " + name)
+	}
+	id := e.addEventListener(evtType, listener, capture)
+	var descend func(elm *Element)
+	descend = func(elm *Element) {
+		for _, c := range elm.Children {
+			c.listenForDescendant(evtType)
+			descend(c)
+		}
+	}
+	descend(e)
+	return id, nil
+}
+
+// RemoveEventListener removes the listener that was added for the event
+func (e *Element) RemoveEventListener(name string, id EventListenerId) {
+	evtType, ok := eventTypeNames[name]
+	if !ok {
+		return
+	}
+	e.listeners[evtType] = slices.DeleteFunc(e.listeners[evtType],
+		func(l eventListener) bool { return l.id == id })
+}
+
+func (e *Element) addEventListener(evtType ui.EventType, listener func(*Event), capture bool) EventListenerId {
+	if e.listeners == nil {
+		e.listeners = make(map[ui.EventType][]eventListener)
+	}
+	e.nextListenerId++
+	e.listeners[evtType] = append(e.listeners[evtType],
+		eventListener{e.nextListenerId, listener, capture})
+	e.listen(evtType)
+	return e.nextListenerId
+}
+
+// listen dispatches the event through the document when the UI of the
+// element gets it
+func (e *Element) listen(evtType ui.EventType) {
+	if e.UI == nil || e.listening&(1<<evtType) != 0 {
+		return
+	}
+	e.listening |= 1 << evtType
+	e.UI.AddEvent(evtType, func() { dispatchEvent(e, evtType) })
+}
+
+// listenForDescendant listens for the event on an element that has no
+// listener for it when one of its ancestors does, so the event is still
+// dispatched to the ancestor when it happens on this element. An event the
+// group only gives to the front most element with a handler is only listened
+// for when the UI already handles it, otherwise this element would take it
+// from the UI elements behind it.
+func (e *Element) listenForDescendant(evtType ui.EventType) {
+	if e.UI == nil || e.listening&(1<<evtType) != 0 {
+		return
+	}
+	if eventIsConsumed(evtType) && e.UI.Event(evtType).IsEmpty() {
+		return
+	}
+	bubbles := eventBubbles(evtType)
+	for p := e.Parent.Value(); p != nil; p = p.Parent.Value() {
+		for _, l := range p.listeners[evtType] {
+			if l.capture || bubbles {
+				e.listen(evtType)
+				return
+			}
+		}
+	}
+}
+
+// pointedTarget is the innermost element under the cursor within the
+// element, the element itself if none of its children are
+func pointedTarget(elm *Element) *Element {
+	for {
+		var next *Element
+		for _, c := range elm.Children {
+			if c.UI == nil || !c.UI.Entity().IsActive() || !c.UI.IsHovered() {
+				continue
+			}
+			if next == nil || c.UI.IsInFrontOf(next.UI) {
+				next = c
+			}
+		}
+		if next == nil {
+			return elm
+		}
+		elm = next
+	}
+}
+
+func newEvent(from, target *Element, evtType ui.EventType) *Event {
+	evt := &Event{
+		Type:    eventName(evtType),
+		Target:  target,
+		Key:     hid.KeyBoardKeyInvalid,
+		evtType: evtType,
+		Flow:    propagation.NewFlow(eventBubbles(evtType)),
+	}
+	window := from.UI.Host().Window
+	evt.Cursor = window.Cursor.ScreenPosition()
+	for i := range hid.MouseButtonLast {
+		if s := window.Mouse.ButtonState(i); s == hid.MousePress || s == hid.MouseRepeat {
+			evt.Buttons |= 1 << i
+		}
+	}
+	if evtType == ui.EventTypeKeyDown || evtType == ui.EventTypeKeyUp {
+		evt.Key = from.UI.LastKey()
+	}
+	evt.Ctrl = window.Keyboard.HasCtrl()
+	evt.Shift = window.Keyboard.HasShift()
+	evt.Alt = window.Keyboard.HasAlt()
+	evt.ScrollDelta = window.Mouse.Scroll()
+	return evt
+}
+
+// dispatchEvent sends the event that the UI of the element got through the
+// capture, target and bubble phases of the document
+func dispatchEvent(elm *Element, evtType ui.EventType) {
+	target := elm
+	if eventIsPointed(evtType) {
+		target = pointedTarget(elm)
+	}
+	evt := newEvent(elm, target, evtType)
+	evt.dispatch()
+	if evt.DefaultPrevented() {
+		elm.UI.PreventDefault()
+	}
+}
+
+// dispatch gives the event to the listeners of the target and its ancestors
+func (evt *Event) dispatch() {
+	path := []*Element{evt.Target}
+	for p := evt.Target.Parent.Value(); p != nil; p = p.Parent.Value() {
+		path = append(path, p)
+	}
+	slices.Reverse(path)
+	propagation.Dispatch(&evt.Flow, path, func(e *Element) { e.callListeners(evt) })
+	evt.CurrentTarget = nil
+}
+
+func (e *Element) callListeners(evt *Event) {
+	listeners := e.listeners[evt.evtType]
+	if len(listeners) == 0 {
+		return
+	}
+	// Listeners can be added or removed by the listeners being called
+	listeners = slices.Clone(listeners)
+	last := e.event
+	e.event = evt
+	evt.CurrentTarget = e
+	for _, l := range listeners {
+		if evt.Listens(l.capture) {
+			l.call(evt)
+		}
+	}
+	e.event = last
+}
@@ -38,15 +38,49 @@
 package document
 
 import (
-	"kaiju/engine/systems/events"
 	"kaiju/engine/ui"
 	"log/slog"
 )
 
-func tryMap(attr string, elm *Element, evt *events.Event, funcMap map[string]func(*Element)) {
+// eventAttributes are the html attributes of the events, in the order the
+// listeners for them are added to the element
+var eventAttributes = []struct {
+	attr    string
+	evtType ui.EventType
+}{
+	{"onclick", ui.EventTypeClick},
+	{"onrightclick", ui.EventTypeRightClick},
+	{"onmiss", ui.EventTypeMiss},
+	{"onsubmit", ui.EventTypeSubmit},
+	{"onkeydown", ui.EventTypeKeyDown},
+	{"onkeyup", ui.EventTypeKeyUp},
+	{"ondblclick", ui.EventTypeDoubleClick},
+	{"onmouseover", ui.EventTypeEnter},
+	{"onmouseenter", ui.EventTypeEnter},
+	{"onmouseleave", ui.EventTypeExit},
+	{"onmouseexit", ui.EventTypeExit},
+	{"onmousedown", ui.EventTypeDown},
+	{"onmouseup", ui.EventTypeUp},
+	{"onmousewheel", ui.EventTypeScroll},
+	{"onwheel", ui.EventTypeScroll},
+	{"onchange", ui.EventTypeChange},
+	{"oninput", ui.EventTypeInput},
+	{"onfocus", ui.EventTypeFocus},
+	{"onblur", ui.EventTypeBlur},
+	{"ondragenter", ui.EventTypeDropEnter},
+	{"ondragleave", ui.EventTypeDropExit},
+	{"ondragstart", ui.EventTypeDragStart},
+	{"ondrop", ui.EventTypeDrop},
+	{"ondragend", ui.EventTypeDragEnd},
+}
+
+// tryMap adds the function named by the attribute as a bubbling listener of
+// the event. The function is given the element the attribute is on, the
+// event being dispatched to it is #Element.Event.
+func tryMap(attr string, elm *Element, evtType ui.EventType, funcMap map[string]func(*Element)) {
 	if funcName := elm.Attribute(attr); len(funcName) > 0 {
 		if f, ok := funcMap[funcName]; ok {
-			evt.Add(func() { f(elm) })
+			elm.addEventListener(evtType, func(evt *Event) { f(evt.CurrentTarget) }, false)
 		} else {
 			slog.Warn("Failed to find the event function",
 				slog.String("func", funcName),
@@ -56,24 +90,10 @@ func tryMap(attr string, elm *Element, evt *events.Event, funcMap map[string]fun
 }
 
 func setupEvents(elm *Element, funcMap map[string]func(*Element)) {
-	tryMap("onclick", elm, elm.UI.Event(ui.EventTypeClick), funcMap)
-	tryMap("onrightclick", elm, elm.UI.Event(ui.EventTypeRightClick), funcMap)
-	tryMap("onmiss", elm, elm.UI.Event(ui.EventTypeMiss), funcMap)
-	tryMap("onsubmit", elm, elm.UI.Event(ui.EventTypeSubmit), funcMap)
-	tryMap("onkeydown", elm, elm.UI.Event(ui.EventTypeKeyDown), funcMap)
-	tryMap("onkeyup", elm, elm.UI.Event(ui.EventTypeKeyUp), funcMap)
-	tryMap("ondblclick", elm, elm.UI.Event(ui.EventTypeDoubleClick), funcMap)
-	tryMap("onmouseover", elm, elm.UI.Event(ui.EventTypeEnter), funcMap)
-	tryMap("onmouseenter", elm, elm.UI.Event(ui.EventTypeEnter), funcMap)
-	tryMap("onmouseleave", elm, elm.UI.Event(ui.EventTypeExit), funcMap)
-	tryMap("onmouseexit", elm, elm.UI.Event(ui.EventTypeExit), funcMap)
-	tryMap("onmousedown", elm, elm.UI.Event(ui.EventTypeDown), funcMap)
-	tryMap("onmouseup", elm, elm.UI.Event(ui.EventTypeUp), funcMap)
-	tryMap("onmousewheel", elm, elm.UI.Event(ui.EventTypeScroll), funcMap)
-	tryMap("onchange", elm, elm.UI.Event(ui.EventTypeChange), funcMap)
-	tryMap("ondragenter", elm, elm.UI.Event(ui.EventTypeDropEnter), funcMap)
-	tryMap("ondragleave", elm, elm.UI.Event(ui.EventTypeDropExit), funcMap)
-	tryMap("ondragstart", elm, elm.UI.Event(ui.EventTypeDragStart), funcMap)
-	tryMap("ondrop", elm, elm.UI.Event(ui.EventTypeDrop), funcMap)
-	tryMap("ondragend", elm, elm.UI.Event(ui.EventTypeDragEnd), funcMap)
+	for _, a := range eventAttributes {
+		tryMap(a.attr, elm, a.evtType, funcMap)
+	}
+	for evtType := range ui.EventTypeEnd {
+		elm.listenForDescendant(evtType)
+	}
 }
@@ -0,0 +1,149 @@
+/******************************************************************************/
+/* html_localization.go                                                       */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package document
+
+import (
+	"kaiju/engine/ui/richtext"
+	"log/slog"
+
+	"golang.org/x/net/html"
+)
+
+// Relocalize applies the text of the current language to the document. This
+// is called when the language of the host's localization changes. The
+// template of the document is run again and the new text and attributes are
+// copied onto the elements that are already built, so the document keeps
+// its state (such as entered text, styles and events) rather than being
+// rebuilt.
+func (d *Document) Relocalize() {
+	bodies := d.GetElementsByTagName("body")
+	if d.source == "" || len(bodies) == 0 {
+		return
+	}
+	transformed, err := TransformLocalizedHTML(d.source, d.withData, d.localizer)
+	if err != nil {
+		slog.Error("failed to localize the html file", "error", err)
+		return
+	}
+	fresh := NewHTML(transformed).Body()
+	if fresh == nil || !relocalizeElement(bodies[0], fresh) {
+		slog.Warn("the html document changed structure with the language, some of its text was not updated")
+	}
+	// The bound text was replaced with the text of the template
+	if d.bindings != nil {
+		d.bindings.set.unsync()
+		d.UpdateBindings()
+	}
+}
+
+// relocalizeElement copies the text and attributes of the fresh element onto
+// the current one, returning false if the two don't have the same structure.
+// The current element can have more children than the fresh one, these are
+// elements of other documents that were rooted in it. The rows of a bound
+// data-for are built from the model, so they are skipped along with the
+// template they came from.
+func relocalizeElement(current, fresh *Element) bool {
+	if current.node.Type != fresh.node.Type || current.node.Type == html.ElementNode &&
+		current.node.Data != fresh.node.Data {
+		return false
+	}
+	if current.node.Type == html.TextNode {
+		if current.node.Data != fresh.node.Data {
+			current.node.Data = fresh.node.Data
+			if current.UI != nil && current.IsText() {
+				current.UI.ToLabel().SetText(labelText(current.node.Data))
+			}
+		}
+		return true
+	}
+	for _, a := range fresh.node.Attr {
+		current.relocalizeAttribute(a.Key, a.Val)
+	}
+	if current.isCollapsedRichText() {
+		return relocalizeRichText(current, fresh)
+	}
+	same := true
+	i := 0
+	for _, f := range fresh.Children {
+		if f.IsElement() && f.Attribute("data-for") != "" &&
+			(i >= len(current.Children) || current.Children[i].Attribute("data-for") == "") {
+			for i < len(current.Children) && current.Children[i].boundRow {
+				i++
+			}
+			continue
+		}
+		if i >= len(current.Children) {
+			return false
+		}
+		same = relocalizeElement(current.Children[i], f) && same
+		i++
+	}
+	return same
+}
+
+// relocalizeRichText gives the text element that the inline elements of the
+// current element were collapsed into the spans of the fresh element
+func relocalizeRichText(current, fresh *Element) bool {
+	spans := make([]richtext.Span, 0, len(fresh.Children))
+	if !inlineSpans(fresh.node, richtext.Style{}, &spans) {
+		return false
+	}
+	spans = collapseSpaces(spans)
+	text := current.Children[0]
+	text.node.Data = richtext.Plain(spans)
+	text.spans = spans
+	if text.UI != nil {
+		text.UI.ToLabel().SetSpans(spans)
+	}
+	return true
+}
+
+func (e *Element) relocalizeAttribute(key, value string) {
+	a, ok := e.attr[key]
+	if !ok || a.Val == value {
+		return
+	}
+	// The value of an input is what the player typed, so it is kept
+	if key == "value" && e.IsInput() {
+		return
+	}
+	a.Val = value
+	if key == "placeholder" && e.IsInput() && e.Attribute("type") == "text" && e.UI != nil {
+		e.UI.ToInput().SetPlaceholder(value)
+	}
+}
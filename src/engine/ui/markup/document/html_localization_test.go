@@ -0,0 +1,92 @@
+/******************************************************************************/
+/* html_localization_test.go                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package document
+
+import (
+	"kaiju/engine/ui/richtext"
+	"slices"
+	"testing"
+)
+
+func TestRelocalizeRichText(t *testing.T) {
+	current := NewHTML(`<body><p>Hello <b>world</b></p></body>`).Body()
+	p := current.Children[0]
+	collapseRichText(p)
+	if !p.isCollapsedRichText() {
+		t.Fatal("expected the paragraph to be collapsed into rich text")
+	}
+	fresh := NewHTML(`<body><p>Bonjour <b>le monde</b> !</p></body>`).Body()
+	if !relocalizeElement(current, fresh) {
+		t.Fatal("expected the collapsed paragraph to be relocalized")
+	}
+	text := p.Children[0]
+	expected := []richtext.Span{
+		{Text: "Bonjour "},
+		{Text: "le monde", Style: richtext.Style{Weight: "bold"}},
+		{Text: " !"},
+	}
+	if !slices.Equal(text.spans, expected) {
+		t.Errorf("expected the spans %v but got %v", expected, text.spans)
+	}
+	if text.node.Data != "Bonjour le monde !" {
+		t.Errorf("unexpected text %q", text.node.Data)
+	}
+}
+
+func TestRelocalizeSkipsLoopRows(t *testing.T) {
+	current := NewHTML(`<body><div><p>Title</p><p>a</p><p>b</p><p>End</p></div></body>`).Body()
+	div := current.Children[0]
+	div.Children[1].boundRow = true
+	div.Children[2].boundRow = true
+	fresh := NewHTML(`<body><div><p>Titre</p><p data-for="i in Items">x</p><p>Fin</p></div></body>`).Body()
+	if !relocalizeElement(current, fresh) {
+		t.Fatal("expected the rows of the loop to be skipped")
+	}
+	texts := []string{}
+	for _, c := range div.Children {
+		texts = append(texts, c.Children[0].node.Data)
+	}
+	if expected := []string{"Titre", "a", "b", "Fin"}; !slices.Equal(texts, expected) {
+		t.Errorf("expected the texts %v but got %v", expected, texts)
+	}
+	empty := NewHTML(`<body><div><p>Title</p></div></body>`).Body()
+	fresh = NewHTML(`<body><div><p>Titre</p><p data-for="i in Items">x</p></div></body>`).Body()
+	if !relocalizeElement(empty, fresh) {
+		t.Error("expected a loop without rows to be skipped")
+	}
+}
@@ -40,9 +40,12 @@ package document
 import (
 	"html/template"
 	"kaiju/engine"
+	"kaiju/engine/systems/events"
+	"kaiju/engine/systems/localization"
 	"kaiju/klib"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/elements"
+	"kaiju/engine/ui/tree"
 	"kaiju/matrix"
 	"kaiju/rendering"
 	"kaiju/engine/ui"
@@ -50,6 +53,8 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+
+	"golang.org/x/net/html"
 )
 
 type TemplateIndexedAny struct {
@@ -79,6 +84,21 @@ var funcMap = template.FuncMap{
 	},
 }
 
+// localizedFuncMap adds the localization functions to the template
+// functions, "tr" looks up the text of a key and "trn" looks up the plural
+// text of a key for a count:
+//
+//	{{tr "menu.play"}} {{trn "inventory.apples" .Apples}}
+func localizedFuncMap(loc *localization.Localizer) template.FuncMap {
+	funcs := make(template.FuncMap, len(funcMap)+2)
+	for k, v := range funcMap {
+		funcs[k] = v
+	}
+	funcs["tr"] = loc.Text
+	funcs["trn"] = loc.Plural
+	return funcs
+}
+
 type Document struct {
 	host          *engine.Host
 	Elements      []*Element
@@ -90,9 +110,20 @@ type Document struct {
 	tagElements   map[string][]*Element
 	style         rules.StyleSheet
 	stylizer      func(rules.StyleSheet, *Document, *engine.Host)
+	styleState    StyleState
+	elementSetup  func(elms []*Element)
+	uiMan         *ui.Manager
+	funcMap       map[string]func(*Element)
+	bindings      *bindings
 	// TODO:  Should this be here?
 	firstInput *ui.Input
 	lastInput  *ui.Input
+	// The template and data the document was made from, these are used to
+	// apply the text of a new language to the document
+	source        string
+	withData      any
+	localizer     *localization.Localizer
+	localeEventId events.Id
 }
 
 func (d *Document) SetupStylizer(style rules.StyleSheet, host *engine.Host,
@@ -107,6 +138,77 @@ func (d *Document) ApplyStyle() {
 	d.stylizer(d.style, d, d.host)
 }
 
+// StyleState is kept by the stylizer to restyle the elements of the document
+// as their state changes after the style has been applied
+type StyleState interface {
+	AttributeChanged(elm *Element, key string)
+	// ElementsChanged is called after elements were added to or removed from
+	// the document, added holds the elements that were built
+	ElementsChanged(added []*Element)
+	Release()
+}
+
+// SetStyleState replaces the style state of the document, releasing the one
+// that was previously set
+func (d *Document) SetStyleState(state StyleState) {
+	if d.styleState != nil {
+		d.styleState.Release()
+	}
+	d.styleState = state
+}
+
+// SetupNewElements gives the function that prepares the elements built after
+// the document was created, like the rows of a data-for binding, the same way
+// the elements of the document were prepared when it was created
+func (d *Document) SetupNewElements(setup func(elms []*Element)) {
+	d.elementSetup = setup
+}
+
+// elementsChanged restyles the document after elements were added or removed
+func (d *Document) elementsChanged(added []*Element) {
+	if d.styleState != nil {
+		d.styleState.ElementsChanged(added)
+	} else if d.stylizer != nil {
+		d.ApplyStyle()
+	}
+	if d.elementSetup != nil && len(added) > 0 {
+		d.elementSetup(added)
+	}
+}
+
+// SetAttribute sets the value of an attribute on the element, the element is
+// re-indexed and restyled to reflect the change
+func (d *Document) SetAttribute(elm *Element, key, value string) {
+	d.removeIndexedAttributes(elm)
+	if a, ok := elm.attr[key]; ok {
+		a.Val = value
+	} else {
+		elm.node.Attr = append(elm.node.Attr, html.Attribute{Key: key, Val: value})
+		elm.resetAttributes()
+	}
+	d.indexAttributes(elm)
+	if d.styleState != nil {
+		d.styleState.AttributeChanged(elm, key)
+	}
+}
+
+// RemoveAttribute removes an attribute from the element, the element is
+// re-indexed and restyled to reflect the change
+func (d *Document) RemoveAttribute(elm *Element, key string) {
+	if _, ok := elm.attr[key]; !ok {
+		return
+	}
+	d.removeIndexedAttributes(elm)
+	elm.node.Attr = slices.DeleteFunc(elm.node.Attr, func(a html.Attribute) bool {
+		return a.Key == key
+	})
+	elm.resetAttributes()
+	d.indexAttributes(elm)
+	if d.styleState != nil {
+		d.styleState.AttributeChanged(elm, key)
+	}
+}
+
 func (h *Document) GetElementById(id string) (*Element, bool) {
 	if e, ok := h.ids[id]; ok {
 		return e, ok
@@ -139,8 +241,17 @@ func (h *Document) GetElementsByTagName(tag string) []*Element {
 	}
 }
 
+// TransformHTML runs the HTML template with the data. The localization
+// functions of the template give back the key they are given, use
+// #TransformLocalizedHTML to look up the text of the keys.
 func TransformHTML(htmlStr string, withData any) (string, error) {
-	tpl, err := template.New("html").Funcs(funcMap).Parse(htmlStr)
+	return TransformLocalizedHTML(htmlStr, withData, nil)
+}
+
+// TransformLocalizedHTML runs the HTML template with the data, looking up
+// the keys of the localization functions in the localizer
+func TransformLocalizedHTML(htmlStr string, withData any, loc *localization.Localizer) (string, error) {
+	tpl, err := template.New("html").Funcs(localizedFuncMap(loc)).Parse(htmlStr)
 	if err != nil {
 		return "", err
 	}
@@ -164,13 +275,12 @@ func (d *Document) createUIElement(uiMan *ui.Manager, e *Element, parent *ui.Pan
 	}
 	if e.IsText() {
 		anchor := ui.AnchorTopLeft
-		txt := strings.TrimSpace(e.Data())
-		txt = strings.ReplaceAll(txt, "\r", "")
-		txt = strings.ReplaceAll(txt, "\n", " ")
-		txt = strings.ReplaceAll(txt, "\t", " ")
-		txt = klib.ReplaceStringRecursive(txt, "  ", " ")
+		txt := labelText(e.Data())
 		label := uiMan.Add().ToLabel()
 		label.Init(txt, anchor)
+		if e.spans != nil {
+			label.SetSpans(e.spans)
+		}
 		label.SetJustify(rendering.FontJustifyLeft)
 		label.SetBaseline(rendering.FontBaselineTop)
 		label.SetBGColor(matrix.ColorTransparent())
@@ -245,12 +355,44 @@ func (d *Document) createUIElement(uiMan *ui.Manager, e *Element, parent *ui.Pan
 					}
 				}
 			}
+		} else if e.IsTextArea() {
+			area := panel.Base().ToTextArea()
+			area.Init(e.Attribute("placeholder"), ui.AnchorTopLeft)
+			if a := e.Attribute("maxlength"); a != "" {
+				if n, err := strconv.Atoi(a); err == nil {
+					area.SetMaxLength(n)
+				}
+			}
+			area.SetWordWrap(e.Attribute("wrap") != "off")
+			// The text of a text area is kept as it was written
+			if len(e.Children) > 0 && e.Children[0].IsText() {
+				area.SetTextWithoutEvent(strings.TrimPrefix(e.Children[0].Data(), "\n"))
+			}
+		} else if e.IsTree() {
+			view := panel.Base().ToTreeView()
+			rowHeight := float32(ui.LabelFontSize + 6)
+			if a := e.Attribute("row-height"); a != "" {
+				if f, err := strconv.ParseFloat(a, 32); err == nil {
+					rowHeight = float32(f)
+				}
+			}
+			view.Init(rowHeight, nil, ui.AnchorTopLeft)
+			view.SetMultiSelect(e.HasAttribute("multiple"))
+			if e.HasAttribute("draggable") {
+				view.EnableDrag(nil)
+			}
+			addTreeItems(view.Tree(), view.Tree().Root(), e.Children)
+			view.Refresh()
 		} else {
 			panel.Init(nil, ui.AnchorTopLeft, ui.ElementTypePanel)
 			panel.SetOverflow(ui.OverflowVisible)
 		}
 		entry := appendElement(panel.Base(), panel)
-		for i := range e.Children {
+		setFocusAttributes(e, panel.Base())
+		collapseRichText(e)
+		// The items of a tree are its nodes and the text of a text area is
+		// its value, rather than elements of their own
+		for i := 0; i < len(e.Children) && !e.IsTree() && !e.IsTextArea(); i++ {
 			d.createUIElement(uiMan, e.Children[i], panel)
 		}
 		id := e.Attribute("id")
@@ -279,6 +421,56 @@ func (d *Document) createUIElement(uiMan *ui.Manager, e *Element, parent *ui.Pan
 	}
 }
 
+// setFocusAttributes reads the tabindex, disabled and autofocus attributes
+// of the element. This is done before the children are created so that the
+// element comes before them in the tab order, as it does in the document.
+func setFocusAttributes(e *Element, target *ui.UI) {
+	if idx, err := strconv.Atoi(e.Attribute("tabindex")); err == nil {
+		target.SetTabIndex(idx)
+	}
+	if e.HasAttribute("disabled") {
+		target.SetFocusable(false)
+	} else if e.HasAttribute("autofocus") && target.IsFocusable() {
+		target.Focus()
+	}
+}
+
+// labelText collapses the white space of the text of an element the same
+// way a browser would
+func labelText(data string) string {
+	txt := strings.TrimSpace(data)
+	txt = strings.ReplaceAll(txt, "\r", "")
+	txt = strings.ReplaceAll(txt, "\n", " ")
+	txt = strings.ReplaceAll(txt, "\t", " ")
+	return klib.ReplaceStringRecursive(txt, "  ", " ")
+}
+
+// addTreeItems adds a node for each <item> to the parent, the label of the
+// node is the label attribute of the item or else its text. The item element
+// is kept as the data of the node.
+func addTreeItems(t *tree.Tree, parent *tree.Node, items []*Element) {
+	for _, item := range items {
+		if !item.IsTreeItem() {
+			continue
+		}
+		label := item.Attribute("label")
+		if label == "" {
+			for _, c := range item.Children {
+				if c.IsText() {
+					label = labelText(c.Data())
+					break
+				}
+			}
+		}
+		node := tree.NewNode(label, item)
+		parent.Add(node)
+		addTreeItems(t, node, item.Children)
+		if item.HasAttribute("expanded") {
+			t.Expand(node)
+		}
+	}
+}
+
 func (d *Document) tagElement(elm *Element, tag string) {
 	if m, ok := d.tagElements[tag]; ok {
 		d.tagElements[tag] = append(m, elm)
@@ -325,8 +517,13 @@ func DocumentFromHTMLString(uiMan *ui.Manager, htmlStr string, withData any, fun
 		classElements: map[string][]*Element{},
 		tagElements:   map[string][]*Element{},
 		HeadElements:  make([]*Element, 0),
+		source:        htmlStr,
+		withData:      withData,
+		localizer:     uiMan.Host.Localization(),
+		uiMan:         uiMan,
+		funcMap:       funcMap,
 	}
-	transformed, err := TransformHTML(htmlStr, withData)
+	transformed, err := TransformLocalizedHTML(htmlStr, withData, parsed.localizer)
 	if err != nil {
 		slog.Error("failed to parse the html file", "error", err)
 		return parsed
@@ -345,6 +542,7 @@ func DocumentFromHTMLString(uiMan *ui.Manager, htmlStr string, withData any, fun
 	for i := range parsed.Elements {
 		setupEvents(parsed.Elements[i], funcMap)
 	}
+	parsed.localeEventId = parsed.localizer.OnLanguageChanged.Add(parsed.Relocalize)
 	for _, elm := range h.Children[len(h.Children)-1].Children {
 		if elm.Data() == "head" {
 			for _, child := range elm.Children {
@@ -376,6 +574,11 @@ func (d *Document) Deactivate() {
 }
 
 func (d *Document) Destroy() {
+	d.unbind(false)
+	d.SetStyleState(nil)
+	if d.localizer != nil {
+		d.localizer.OnLanguageChanged.Remove(d.localeEventId)
+	}
 	for i := range d.Elements {
 		d.Elements[i].UI.Entity().Destroy()
 	}
@@ -389,6 +592,10 @@ func (d *Document) Clean() {
 
 func (d *Document) indexElement(elm *Element) {
 	d.Elements = append(d.Elements, elm)
+	d.indexAttributes(elm)
+}
+
+func (d *Document) indexAttributes(elm *Element) {
 	if id := elm.Attribute("id"); id != "" {
 		d.ids[id] = elm
 	}
@@ -411,12 +618,17 @@ func (d *Document) indexElement(elm *Element) {
 }
 
 func (d *Document) removeIndexedElement(elm *Element) {
+	// The elements are kept in document order for styling
 	for i, e := range d.Elements {
 		if e == elm {
-			klib.RemoveUnordered(d.Elements, i)
+			d.Elements = slices.Delete(d.Elements, i, i+1)
 			break
 		}
 	}
+	d.removeIndexedAttributes(elm)
+}
+
+func (d *Document) removeIndexedAttributes(elm *Element) {
 	delete(d.ids, elm.Attribute("id"))
 	if group := elm.Attribute("group"); group != "" {
 		for i := range d.groups[group] {
@@ -461,6 +673,13 @@ func (d *Document) AddChildElement(parent *Element, elm *Element) {
 }
 
 func (d *Document) RemoveElement(elm *Element) {
+	d.removeElement(elm)
+	d.elementsChanged(nil)
+}
+
+// removeElement takes the element and its children out of the document
+// without restyling it
+func (d *Document) removeElement(elm *Element) {
 	if elm.Parent.Value() != nil {
 		for i, c := range elm.Parent.Value().Children {
 			if c == elm {
@@ -469,6 +688,15 @@ func (d *Document) RemoveElement(elm *Element) {
 			}
 		}
 	}
-	d.removeIndexedElement(elm)
-	d.ApplyStyle()
+	if elm.node.Parent != nil {
+		elm.node.Parent.RemoveChild(elm.node)
+	}
+	var unindex func(e *Element)
+	unindex = func(e *Element) {
+		d.removeIndexedElement(e)
+		for _, c := range e.Children {
+			unindex(c)
+		}
+	}
+	unindex(elm)
 }
@@ -0,0 +1,232 @@
+/******************************************************************************/
+/* html_rich_text.go                                                          */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package document
+
+import (
+	"kaiju/engine/ui/markup/css/helpers"
+	"kaiju/engine/ui/richtext"
+	"kaiju/klib"
+	"kaiju/matrix"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// inlineTextTags are the elements that only change how the text inside of
+// them looks, along with the style they give it
+var inlineTextTags = map[string]func(style *richtext.Style){
+	"b":      func(s *richtext.Style) { s.Weight = "bold" },
+	"strong": func(s *richtext.Style) { s.Weight = "bold" },
+	"i":      func(s *richtext.Style) { s.Italic = true },
+	"em":     func(s *richtext.Style) { s.Italic = true },
+	"u":      func(s *richtext.Style) { s.Underline = true },
+	"ins":    func(s *richtext.Style) { s.Underline = true },
+	"s":      func(s *richtext.Style) { s.Strikethrough = true },
+	"strike": func(s *richtext.Style) { s.Strikethrough = true },
+	"del":    func(s *richtext.Style) { s.Strikethrough = true },
+	"span":   func(s *richtext.Style) {},
+	"font":   func(s *richtext.Style) {},
+}
+
+// collapseRichText replaces the children of the element with a single text
+// element when they are only text and elements like <b> or
+// <span style="color: red">, so the text is shown in one label that wraps
+// across all of it. Nothing is collapsed when none of the text ends up with
+// a style, like text that is only in plain <span> elements. The html of the
+// element is left as it was, only the elements of the inline tags are gone
+// from the document.
+func collapseRichText(e *Element) {
+	styled := false
+	for _, c := range e.Children {
+		if !c.IsText() {
+			styled = true
+		}
+	}
+	if !styled {
+		return
+	}
+	// The white space between inline elements is not kept as an element, so
+	// the spans come from the html rather than from the children
+	spans := make([]richtext.Span, 0, len(e.Children))
+	if !inlineSpans(e.node, richtext.Style{}, &spans) {
+		return
+	}
+	spans = collapseSpaces(spans)
+	if !slices.ContainsFunc(spans, func(s richtext.Span) bool {
+		return s.Style != richtext.Style{}
+	}) {
+		return
+	}
+	child := toElement(&html.Node{Type: html.TextNode, Data: richtext.Plain(spans)})
+	child.spans = spans
+	e.Children = []*Element{child}
+	child.setParents(e)
+}
+
+// isCollapsedRichText returns true if the children of the element were
+// replaced by #collapseRichText
+func (e *Element) isCollapsedRichText() bool {
+	return len(e.Children) > 0 && e.Children[0].spans != nil
+}
+
+// inlineSpans adds a span for each text under the node, false if anything
+// under it is more than styled text. An inline element that can be found by
+// the document or styled by a stylesheet (an id, class, event or data
+// attribute) is kept as an element of its own.
+func inlineSpans(node *html.Node, style richtext.Style, spans *[]richtext.Span) bool {
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.TextNode:
+			*spans = append(*spans, richtext.Span{Text: c.Data, Style: style})
+		case html.CommentNode:
+		case html.ElementNode:
+			apply, ok := inlineTextTags[strings.ToLower(c.Data)]
+			if !ok {
+				return false
+			}
+			inner := style
+			apply(&inner)
+			for _, a := range c.Attr {
+				key := strings.ToLower(a.Key)
+				switch {
+				case key == "id", key == "class",
+					strings.HasPrefix(key, "on"), strings.HasPrefix(key, "data-"):
+					return false
+				}
+				switch key {
+				case "color":
+					if color, ok := parseTextColor(a.Val); ok {
+						inner.Color, inner.HasColor = color, true
+					}
+				case "style":
+					applyInlineStyle(&inner, a.Val)
+				}
+			}
+			if !inlineSpans(c, inner, spans) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// applyInlineStyle reads the text properties of a style attribute
+func applyInlineStyle(style *richtext.Style, attr string) {
+	for _, decl := range strings.Split(attr, ";") {
+		name, value, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		value = strings.ToLower(strings.TrimSpace(value))
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "color":
+			if c, ok := parseTextColor(value); ok {
+				style.Color, style.HasColor = c, true
+			}
+		case "font-weight":
+			style.Weight = inlineFontWeight(value, style.Weight)
+		case "font-style":
+			style.Italic = value == "italic" || value == "oblique"
+		case "font-size":
+			if f, err := strconv.ParseFloat(strings.TrimSuffix(value, "px"), 32); err == nil && f > 0 {
+				style.Size = float32(f)
+			}
+		case "text-decoration", "text-decoration-line":
+			style.Underline = strings.Contains(value, "underline")
+			style.Strikethrough = strings.Contains(value, "line-through")
+		}
+	}
+}
+
+func inlineFontWeight(value, current string) string {
+	switch value {
+	case "normal", "bold", "bolder", "lighter":
+		return value
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		switch {
+		case n >= 800:
+			return "bolder"
+		case n >= 600:
+			return "bold"
+		case n <= 300:
+			return "lighter"
+		}
+		return "normal"
+	}
+	return current
+}
+
+// parseTextColor reads a hex or named color
+func parseTextColor(value string) (matrix.Color, bool) {
+	value = strings.TrimSpace(value)
+	if hex, ok := helpers.ColorMap[strings.ToLower(value)]; ok {
+		value = hex
+	}
+	if !strings.HasPrefix(value, "#") {
+		return matrix.Color{}, false
+	}
+	c, err := matrix.ColorFromHexString(value)
+	return c, err == nil
+}
+
+// collapseSpaces collapses the white space of the spans the way a browser
+// would, across the spans as if they were one text
+func collapseSpaces(spans []richtext.Span) []richtext.Span {
+	lastSpace := true
+	for i := range spans {
+		txt := strings.NewReplacer("\r", "", "\n", " ", "\t", " ").Replace(spans[i].Text)
+		txt = klib.ReplaceStringRecursive(txt, "  ", " ")
+		if lastSpace {
+			txt = strings.TrimPrefix(txt, " ")
+		}
+		if txt != "" {
+			lastSpace = strings.HasSuffix(txt, " ")
+		}
+		spans[i].Text = txt
+	}
+	spans = richtext.Merge(spans)
+	if n := len(spans); n > 0 {
+		spans[n-1].Text = strings.TrimSuffix(spans[n-1].Text, " ")
+	}
+	return richtext.Merge(spans)
+}
@@ -149,6 +149,7 @@ var ElementMap = map[string]Element{
 	"title":      Title{},
 	"tr":         Tr{},
 	"track":      Track{},
+	"tree":       Tree{},
 	"u":          U{},
 	"ul":         Ul{},
 	"var":        Var{},
@@ -465,6 +465,10 @@ type Track struct{}
 
 func (p Track) Key() string { return "track" }
 
+type Tree struct{}
+
+func (p Tree) Key() string { return "tree" }
+
 type U struct{}
 
 func (p U) Key() string { return "u" }
@@ -39,29 +39,36 @@ package markup
 
 import (
 	"kaiju/engine"
+	"kaiju/engine/ui"
 	"kaiju/engine/ui/markup/css"
 	"kaiju/engine/ui/markup/css/rules"
 	"kaiju/engine/ui/markup/document"
-	"kaiju/engine/ui"
 	"weak"
 )
 
-func sizeTexts(doc *document.Document, host *engine.Host) {
-	for i := range doc.Elements {
-		e := doc.Elements[i]
+func sizeTexts(elms []*document.Element, host *engine.Host) {
+	for i := range elms {
+		e := elms[i]
 		if e.IsText() {
 			parentWidth := float32(-1.0)
+			text := e.Data()
 			updateSize := func(l *ui.Layout) {
 				if p := ui.FirstOnEntity(l.Ui().Entity().Parent); p != nil {
 					newParentWidth := p.Layout().PixelSize().Width()
 					height := l.PixelSize().Height()
-					if newParentWidth != parentWidth {
+					// The text changes when the document is localized
+					if newParentWidth != parentWidth || text != e.Data() {
 						parentWidth = newParentWidth
+						text = e.Data()
 						lbl := l.Ui().ToLabel()
-						textSize := host.FontCache().MeasureStringWithin(
-							lbl.FontFace(), e.Data(), lbl.FontSize(),
-							parentWidth, lbl.LineHeight())
-						height = textSize.Height()
+						if lbl.HasSpans() {
+							height = lbl.MeasureWithin(parentWidth).Height()
+						} else {
+							textSize := host.FontCache().MeasureStringWithin(
+								lbl.FontFace(), e.Data(), lbl.FontSize(),
+								parentWidth, lbl.LineHeight())
+							height = textSize.Height()
+						}
 					}
 					l.Scale(parentWidth, height)
 				}
@@ -126,6 +133,7 @@ func DocumentFromHTMLString(uiMan *ui.Manager, html, cssStr string, withData any
 		}
 	}
 	doc.SetupStylizer(s, host, css.Apply)
-	sizeTexts(doc, host)
+	sizeTexts(doc.Elements, host)
+	doc.SetupNewElements(func(elms []*document.Element) { sizeTexts(elms, host) })
 	return doc
 }
@@ -0,0 +1,137 @@
+/******************************************************************************/
+/* propagation.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+// Package propagation is how an event travels through a tree of elements:
+// down through the capturing ancestors, to the target and back up through the
+// bubbling ones. The #document package dispatches its events with it.
+package propagation
+
+// Phase is the part of the dispatch of an event that a listener is being
+// called in
+type Phase int
+
+const (
+	PhaseNone = Phase(iota)
+	// PhaseCapturing is while the event goes down from the root of the tree
+	// to the parent of the target
+	PhaseCapturing
+	PhaseAtTarget
+	// PhaseBubbling is while the event goes back up from the parent of the
+	// target to the root of the tree
+	PhaseBubbling
+)
+
+// Flow is the state of an event while it is being dispatched, it is meant
+// to be embedded in the event given to the listeners
+type Flow struct {
+	Phase     Phase
+	bubbles   bool
+	stopped   bool
+	immediate bool
+	prevented bool
+}
+
+// NewFlow starts the flow of an event, only an event that bubbles is given
+// back to the ancestors of the target after the target
+func NewFlow(bubbles bool) Flow { return Flow{bubbles: bubbles} }
+
+// Bubbles returns false for the events named "mouseenter", "mouseleave",
+// "mousemove", "miss", "focus" and "blur", these are only given to the
+// capturing listeners of the ancestors and to the target
+func Bubbles(name string) bool {
+	switch name {
+	case "mouseenter", "mouseleave", "mousemove", "miss", "focus", "blur":
+		return false
+	}
+	return true
+}
+
+// StopPropagation stops the event from being given to any more elements,
+// the rest of the listeners of the current element are still called
+func (f *Flow) StopPropagation() { f.stopped = true }
+
+// StopImmediatePropagation stops the event from being given to any more
+// listeners, including the rest of the ones on the current element
+func (f *Flow) StopImmediatePropagation() { f.stopped, f.immediate = true, true }
+
+// PreventDefault stops the UI from doing what it would normally do with the
+// input of the event, like the focus moving when tab is pressed
+func (f *Flow) PreventDefault() { f.prevented = true }
+
+func (f *Flow) DefaultPrevented() bool { return f.prevented }
+
+func (f *Flow) Bubbles() bool { return f.bubbles }
+
+// Listens returns true if a listener, added as capturing or not, is to be
+// called in the current phase. Nothing listens once the propagation has been
+// stopped immediately.
+func (f *Flow) Listens(capture bool) bool {
+	switch {
+	case f.immediate:
+		return false
+	case f.Phase == PhaseCapturing:
+		return capture
+	case f.Phase == PhaseBubbling:
+		return !capture
+	}
+	return true
+}
+
+// Dispatch visits the nodes of the path with the phase of the flow set for
+// each visit. The path runs from the root down to the target, the ancestors
+// are visited capturing root first, then the target and then, if the event
+// bubbles, the ancestors again back up to the root. Nothing more is visited
+// after the propagation is stopped.
+func Dispatch[N any](f *Flow, path []N, visit func(node N)) {
+	if len(path) == 0 {
+		return
+	}
+	target := len(path) - 1
+	f.Phase = PhaseCapturing
+	for i := 0; i < target && !f.stopped; i++ {
+		visit(path[i])
+	}
+	if !f.stopped {
+		f.Phase = PhaseAtTarget
+		visit(path[target])
+	}
+	f.Phase = PhaseBubbling
+	for i := target - 1; i >= 0 && !f.stopped && f.bubbles; i-- {
+		visit(path[i])
+	}
+	f.Phase = PhaseNone
+}
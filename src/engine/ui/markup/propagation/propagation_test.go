@@ -0,0 +1,152 @@
+/******************************************************************************/
+/* propagation_test.go                                                        */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package propagation
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+type listener struct {
+	capture bool
+	call    func(f *Flow)
+}
+
+type node struct {
+	name      string
+	listeners []listener
+}
+
+// dispatch sends an event through the path and returns the listeners that
+// were called as "node:phase"
+func dispatch(bubbles bool, path []*node) ([]string, Flow) {
+	called := []string{}
+	f := NewFlow(bubbles)
+	Dispatch(&f, path, func(n *node) {
+		for _, l := range n.listeners {
+			if f.Listens(l.capture) {
+				called = append(called, fmt.Sprintf("%s:%d", n.name, f.Phase))
+				if l.call != nil {
+					l.call(&f)
+				}
+			}
+		}
+	})
+	return called, f
+}
+
+// tree makes a root, parent and target that each have a capturing and a
+// bubbling listener
+func tree() []*node {
+	path := []*node{{name: "root"}, {name: "parent"}, {name: "target"}}
+	for _, n := range path {
+		n.listeners = []listener{{capture: true}, {capture: false}}
+	}
+	return path
+}
+
+func TestPhaseOrder(t *testing.T) {
+	called, f := dispatch(true, tree())
+	expected := []string{"root:1", "parent:1", "target:2", "target:2", "parent:3", "root:3"}
+	if !slices.Equal(called, expected) {
+		t.Errorf("expected the calls %v but got %v", expected, called)
+	}
+	if f.Phase != PhaseNone {
+		t.Errorf("expected no phase after the dispatch, got %d", f.Phase)
+	}
+}
+
+func TestNotBubbling(t *testing.T) {
+	called, _ := dispatch(Bubbles("focus"), tree())
+	expected := []string{"root:1", "parent:1", "target:2", "target:2"}
+	if !slices.Equal(called, expected) {
+		t.Errorf("expected the calls %v but got %v", expected, called)
+	}
+	for _, name := range []string{"mouseenter", "mouseleave", "mousemove", "miss", "focus", "blur"} {
+		if Bubbles(name) {
+			t.Errorf("%s should not bubble", name)
+		}
+	}
+	for _, name := range []string{"click", "keydown", "input", "change", "drop", "wheel"} {
+		if !Bubbles(name) {
+			t.Errorf("%s should bubble", name)
+		}
+	}
+}
+
+func TestStopPropagation(t *testing.T) {
+	path := tree()
+	path[1].listeners[0].call = func(f *Flow) { f.StopPropagation() }
+	path[1].listeners = append(path[1].listeners, listener{capture: true})
+	called, _ := dispatch(true, path)
+	expected := []string{"root:1", "parent:1", "parent:1"}
+	if !slices.Equal(called, expected) {
+		t.Errorf("the rest of the element's listeners should be called, got %v", called)
+	}
+	path = tree()
+	path[2].listeners[1].call = func(f *Flow) { f.StopPropagation() }
+	called, _ = dispatch(true, path)
+	expected = []string{"root:1", "parent:1", "target:2", "target:2"}
+	if !slices.Equal(called, expected) {
+		t.Errorf("the event should not bubble once stopped, got %v", called)
+	}
+}
+
+func TestStopImmediatePropagation(t *testing.T) {
+	path := tree()
+	path[1].listeners[0].call = func(f *Flow) { f.StopImmediatePropagation() }
+	path[1].listeners = append(path[1].listeners, listener{capture: true})
+	called, _ := dispatch(true, path)
+	expected := []string{"root:1", "parent:1"}
+	if !slices.Equal(called, expected) {
+		t.Errorf("no more listeners should be called, got %v", called)
+	}
+}
+
+func TestPreventDefault(t *testing.T) {
+	path := tree()
+	path[2].listeners[0].call = func(f *Flow) { f.PreventDefault() }
+	called, f := dispatch(true, path)
+	if !f.DefaultPrevented() {
+		t.Error("expected the default to be prevented")
+	}
+	if len(called) != 6 {
+		t.Errorf("preventing the default should not stop the event, got %v", called)
+	}
+}
@@ -137,13 +137,20 @@ func writePseudoFile() error {
 
 import (
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/markup"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
 type Pseudo interface {
 	Key() string
 	IsFunction() bool
-	Process(elm document.DocumentElement, value rules.SelectorPart) ([]document.DocumentElement, error)
+	Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error)
+}
+
+// Lookup finds a pseudo-class by its name, it is what a selector.Matcher
+// uses to match the pseudo-classes of a selector
+func Lookup(name string) (selector.Pseudo, bool) {
+	p, ok := PseudoMap[name]
+	return p, ok
 }
 
 var PseudoMap = map[string]Pseudo{
@@ -187,11 +194,11 @@ func (p %s) IsFunction() bool { return %v }
 import (
 	"errors"
 	"kaiju/engine/ui/markup/css/rules"
-	"kaiju/engine/ui/markup/markup"
+	"kaiju/engine/ui/markup/css/selector"
 )
 
-func (p %s) Process(elm document.DocumentElement, value rules.SelectorPart) ([]document.DocumentElement, error) {
-	return []document.DocumentElement{elm}, errors.New("not implemented")
+func (p %s) Matches(m selector.Matcher, node selector.Node, part rules.SelectorPart) (bool, error) {
+	return false, errors.New("not implemented")
 }
 `, p.StructName()))
 			}
@@ -39,6 +39,8 @@ package ui
 
 import (
 	"kaiju/engine/assets"
+	"kaiju/engine/ui/flex"
+	"kaiju/engine/ui/grid"
 	"kaiju/matrix"
 	"kaiju/platform/profiler/tracing"
 	"kaiju/rendering"
@@ -111,6 +113,10 @@ type panelData struct {
 	dragging                  bool
 	frozen                    bool
 	allowDragScroll           bool
+	isFlex                    bool
+	isGrid                    bool
+	flex                      flex.Container
+	grid                      grid.Container
 }
 
 func (p *panelData) innerPanelData() *panelData { return p }
@@ -134,6 +140,7 @@ func (panel *Panel) Init(texture *rendering.Texture, anchor Anchor, elmType Elem
 	pd.scrollSpeed = 20.0
 	pd.scrollDirection = PanelScrollDirectionVertical
 	pd.fitContent = ContentFitBoth
+	pd.flex = flex.DefaultContainer()
 	pd.enforcedColorStack = make([]matrix.Color, 0)
 	panel.postLayoutUpdate = panel.panelPostLayoutUpdate
 	panel.render = panel.panelRender
@@ -331,16 +338,9 @@ func (rb rowBuilder) setElements(offsetX, offsetY float32) {
 		case PositioningAbsolute:
 			fallthrough
 		case PositioningRelative:
-			if layout.Anchor().IsLeft() {
-				x += layout.InnerOffset().Left()
-			} else {
-				x += layout.InnerOffset().Right()
-			}
-			if layout.Anchor().IsTop() {
-				y += layout.InnerOffset().Top()
-			} else {
-				y += layout.InnerOffset().Bottom()
-			}
+			inner := layout.anchoredInnerOffset()
+			x += inner.X()
+			y += inner.Y()
 		}
 		x += layout.margin.X()
 		y += rb.maxMarginTop
@@ -381,17 +381,14 @@ func (p *Panel) panelPostLayoutUpdate() {
 		return
 	}
 	pd := p.PanelData()
-	if pd.requestScrollX.requested {
-		x := matrix.Clamp(pd.requestScrollX.to, 0, pd.maxScroll.X())
-		pd.scroll.SetX(x)
-		pd.requestScrollX.requested = false
-	}
-	if pd.requestScrollY.requested {
-		y := matrix.Clamp(-pd.requestScrollY.to, -pd.maxScroll.Y(), 0)
-		pd.scroll.SetY(y)
-		pd.requestScrollY.requested = false
+	offsetStart := p.applyScrollRequests()
+	if pd.isFlex {
+		p.flexPostLayoutUpdate(offsetStart)
+		return
+	} else if pd.isGrid {
+		p.gridPostLayoutUpdate(offsetStart)
+		return
 	}
-	offsetStart := matrix.Vec2{-pd.scroll.X(), pd.scroll.Y()}
 	rows := make([]rowBuilder, 0)
 	ps := p.layout.PixelSize()
 	areaWidth := ps.X() - p.layout.padding.X() - p.layout.padding.Z() -
@@ -409,23 +406,7 @@ func (p *Panel) panelPostLayoutUpdate() {
 		kLayout := kui.Layout()
 		switch kLayout.Positioning() {
 		case PositioningAbsolute:
-			if kLayout.Anchor().IsTop() {
-				kLayout.rowLayoutOffset.SetY(p.layout.InnerOffset().Top() +
-					p.layout.padding.Top() + p.layout.border.Top())
-			} else if kLayout.Anchor().IsBottom() {
-				kLayout.rowLayoutOffset.SetY(p.layout.InnerOffset().Bottom() +
-					p.layout.padding.Bottom() + p.layout.border.Bottom())
-			}
-			if kLayout.Anchor().IsLeft() {
-				kLayout.rowLayoutOffset.SetX(p.layout.InnerOffset().Left() +
-					p.layout.padding.Left() + p.layout.border.Left())
-			} else if kLayout.Anchor().IsRight() {
-				kLayout.rowLayoutOffset.SetX(p.layout.InnerOffset().Right() +
-					p.layout.padding.Right() + p.layout.border.Right())
-			}
-			kws := kid.Transform.WorldScale()
-			maxSize[matrix.Vx] = max(maxSize.X(), kLayout.left+kLayout.offset.X()+kws.Width())
-			maxSize[matrix.Vy] = max(maxSize.Y(), kLayout.top+kLayout.offset.Y()+kws.Height())
+			p.placeAbsoluteChild(kui, &maxSize)
 		case PositioningRelative:
 			fallthrough
 		case PositioningStatic:
@@ -464,6 +445,50 @@ func (p *Panel) panelPostLayoutUpdate() {
 	} else {
 		bounds.SetX(maxRowsX)
 	}
+	p.updateMaxScroll(bounds)
+}
+
+// applyScrollRequests moves the scroll to where #Panel.SetScrollX and
+// #Panel.SetScrollY asked for, returning where the children start because of
+// the scroll
+func (p *Panel) applyScrollRequests() matrix.Vec2 {
+	pd := p.PanelData()
+	if pd.requestScrollX.requested {
+		x := matrix.Clamp(pd.requestScrollX.to, 0, pd.maxScroll.X())
+		pd.scroll.SetX(x)
+		pd.requestScrollX.requested = false
+	}
+	if pd.requestScrollY.requested {
+		y := matrix.Clamp(-pd.requestScrollY.to, -pd.maxScroll.Y(), 0)
+		pd.scroll.SetY(y)
+		pd.requestScrollY.requested = false
+	}
+	return matrix.Vec2{-pd.scroll.X(), pd.scroll.Y()}
+}
+
+func (p *Panel) placeAbsoluteChild(kui *UI, maxSize *matrix.Vec2) {
+	kLayout := kui.Layout()
+	if kLayout.Anchor().IsTop() {
+		kLayout.rowLayoutOffset.SetY(p.layout.InnerOffset().Top() +
+			p.layout.padding.Top() + p.layout.border.Top())
+	} else if kLayout.Anchor().IsBottom() {
+		kLayout.rowLayoutOffset.SetY(p.layout.InnerOffset().Bottom() +
+			p.layout.padding.Bottom() + p.layout.border.Bottom())
+	}
+	if kLayout.Anchor().IsLeft() {
+		kLayout.rowLayoutOffset.SetX(p.layout.InnerOffset().Left() +
+			p.layout.padding.Left() + p.layout.border.Left())
+	} else if kLayout.Anchor().IsRight() {
+		kLayout.rowLayoutOffset.SetX(p.layout.InnerOffset().Right() +
+			p.layout.padding.Right() + p.layout.border.Right())
+	}
+	kws := kui.entity.Transform.WorldScale()
+	maxSize[matrix.Vx] = max(maxSize.X(), kLayout.left+kLayout.offset.X()+kws.Width())
+	maxSize[matrix.Vy] = max(maxSize.Y(), kLayout.top+kLayout.offset.Y()+kws.Height())
+}
+
+func (p *Panel) updateMaxScroll(bounds matrix.Vec2) {
+	pd := p.PanelData()
 	last := pd.maxScroll
 	ws := p.entity.Transform.WorldScale()
 	pd.maxScroll = matrix.NewVec2(max(0, bounds.X()-ws.X()), max(0.0, bounds.Y()-ws.Y()))
@@ -498,6 +523,7 @@ func (p *Panel) InsertChild(target *UI, idx int) {
 }
 
 func (p *Panel) RemoveChild(target *UI) {
+	target.Layout().releaseSize()
 	target.Entity().SetParent(nil)
 	target.setScissor(matrix.Vec4{-matrix.FloatMax, -matrix.FloatMax, matrix.FloatMax, matrix.FloatMax})
 	target.Layout().update()
@@ -0,0 +1,137 @@
+/******************************************************************************/
+/* panel_container.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package ui
+
+import (
+	"kaiju/matrix"
+	"log/slog"
+)
+
+// releaseContainerChildren is used when the panel stops being a flex or grid
+// container to give the children back their own sizes
+func (p *Panel) releaseContainerChildren() {
+	for _, kid := range p.entity.Children {
+		if kui := FirstOnEntity(kid); kui != nil {
+			kui.Layout().releaseSize()
+		}
+	}
+	p.Base().SetDirty(DirtyTypeLayout)
+}
+
+// containerChildren returns the children that the flex or grid container
+// places, handing their size over to it. Absolute children are placed the
+// same as they are for rows and grow the max size.
+func (p *Panel) containerChildren(maxSize *matrix.Vec2) []*UI {
+	kids := make([]*UI, 0, len(p.entity.Children))
+	for _, kid := range p.entity.Children {
+		if !kid.IsActive() || kid.IsDestroyed() {
+			continue
+		}
+		kui := FirstOnEntity(kid)
+		if kui == nil {
+			slog.Error("No UI component on entity")
+			continue
+		}
+		kLayout := kui.Layout()
+		switch kLayout.Positioning() {
+		case PositioningAbsolute:
+			kLayout.releaseSize()
+			p.placeAbsoluteChild(kui, maxSize)
+		case PositioningRelative:
+			fallthrough
+		case PositioningStatic:
+			kLayout.manageSize()
+			kids = append(kids, kui)
+		}
+	}
+	return kids
+}
+
+// containerContentSize is the size within the padding and border that the
+// children are placed in. Sizes that fit the content are indefinite (-1),
+// unless this panel is itself being sized by a container.
+func (p *Panel) containerContentSize() (float32, float32) {
+	pad, border := p.layout.padding, p.layout.border
+	ps := p.layout.PixelSize()
+	width := ps.X() - pad.Left() - pad.Right() - border.Left() - border.Right()
+	height := ps.Y() - pad.Top() - pad.Bottom() - border.Top() - border.Bottom()
+	if p.FittingContentWidth() && !p.layout.item.managed {
+		width = -1
+	}
+	if p.FittingContentHeight() && !p.layout.item.managed {
+		height = -1
+	}
+	return width, height
+}
+
+// placeContainerChildren sizes and positions the children to the rects (x,
+// y, width, height) from the container, relative to the content box, then
+// fits the panel to the content if needed
+func (p *Panel) placeContainerChildren(offsetStart matrix.Vec2, kids []*UI, rects []matrix.Vec4, content, maxSize matrix.Vec2) {
+	pd := p.PanelData()
+	pad, border := p.layout.padding, p.layout.border
+	origin := offsetStart.Add(matrix.Vec2{
+		pad.Left() + border.Left(), pad.Top() + border.Top()})
+	for i, kui := range kids {
+		r := rects[i]
+		kLayout := kui.Layout()
+		kLayout.scaleManaged(r.Z(), r.W())
+		pos := origin.Add(matrix.Vec2{r.X(), r.Y()})
+		if kLayout.Positioning() == PositioningRelative {
+			pos.AddAssign(kLayout.anchoredInnerOffset())
+		}
+		kLayout.SetRowLayoutOffset(pos)
+	}
+	if p.FittingContent() {
+		w := max(1, content.X()+border.Left()+border.Right())
+		h := max(1, content.Y()+border.Top()+border.Bottom())
+		switch pd.fitContent {
+		case ContentFitWidth:
+			p.layout.ScaleWidth(w)
+		case ContentFitHeight:
+			p.layout.ScaleHeight(h)
+		case ContentFitBoth:
+			p.layout.Scale(w, h)
+		}
+	}
+	bounds := matrix.Vec2{
+		content.X() + pad.Left() + pad.Right() + border.Left() + border.Right(),
+		content.Y() + pad.Top() + pad.Bottom() + border.Top() + border.Bottom(),
+	}
+	p.updateMaxScroll(matrix.Vec2{max(bounds.X(), maxSize.X()), max(bounds.Y(), maxSize.Y())})
+}
@@ -0,0 +1,94 @@
+/******************************************************************************/
+/* panel_flex.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package ui
+
+import (
+	"kaiju/engine/ui/flex"
+	"kaiju/matrix"
+)
+
+// EnableFlex makes the panel a flex container, its children are placed using
+// the settings of #Panel.FlexContainer rather than being placed in rows
+func (p *Panel) EnableFlex() {
+	pd := p.PanelData()
+	if pd.isFlex {
+		return
+	}
+	pd.isFlex = true
+	pd.isGrid = false
+	p.Base().SetDirty(DirtyTypeLayout)
+}
+
+// DisableFlex places the children of the panel in rows again, giving them
+// back the size they had before they were flexed
+func (p *Panel) DisableFlex() {
+	pd := p.PanelData()
+	if !pd.isFlex {
+		return
+	}
+	pd.isFlex = false
+	p.releaseContainerChildren()
+}
+
+func (p *Panel) IsFlex() bool { return p.PanelData().isFlex }
+
+// FlexContainer returns the flex settings of the panel, they are kept even
+// while the panel isn't a flex container
+func (p *Panel) FlexContainer() flex.Container { return p.PanelData().flex }
+
+func (p *Panel) SetFlexContainer(container flex.Container) {
+	p.PanelData().flex = container
+	p.Base().SetDirty(DirtyTypeLayout)
+}
+
+func (p *Panel) flexPostLayoutUpdate(offsetStart matrix.Vec2) {
+	maxSize := matrix.Vec2{}
+	kids := p.containerChildren(&maxSize)
+	items := make([]flex.Item, len(kids))
+	for i := range kids {
+		items[i] = kids[i].Layout().flexItemSized()
+	}
+	width, height := p.containerContentSize()
+	res := flex.Arrange(p.PanelData().flex, width, height, items)
+	rects := make([]matrix.Vec4, len(res.Rects))
+	for i, r := range res.Rects {
+		rects[i] = matrix.Vec4{r.X, r.Y, r.Width, r.Height}
+	}
+	p.placeContainerChildren(offsetStart, kids, rects,
+		matrix.Vec2{res.ContentWidth, res.ContentHeight}, maxSize)
+}
@@ -0,0 +1,94 @@
+/******************************************************************************/
+/* panel_grid.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package ui
+
+import (
+	"kaiju/engine/ui/grid"
+	"kaiju/matrix"
+)
+
+// EnableGrid makes the panel a grid container, its children are placed using
+// the settings of #Panel.GridContainer rather than being placed in rows
+func (p *Panel) EnableGrid() {
+	pd := p.PanelData()
+	if pd.isGrid {
+		return
+	}
+	pd.isGrid = true
+	pd.isFlex = false
+	p.Base().SetDirty(DirtyTypeLayout)
+}
+
+// DisableGrid places the children of the panel in rows again, giving them
+// back the size they had before they were placed in the grid
+func (p *Panel) DisableGrid() {
+	pd := p.PanelData()
+	if !pd.isGrid {
+		return
+	}
+	pd.isGrid = false
+	p.releaseContainerChildren()
+}
+
+func (p *Panel) IsGrid() bool { return p.PanelData().isGrid }
+
+// GridContainer returns the grid settings of the panel, they are kept even
+// while the panel isn't a grid container
+func (p *Panel) GridContainer() grid.Container { return p.PanelData().grid }
+
+func (p *Panel) SetGridContainer(container grid.Container) {
+	p.PanelData().grid = container
+	p.Base().SetDirty(DirtyTypeLayout)
+}
+
+func (p *Panel) gridPostLayoutUpdate(offsetStart matrix.Vec2) {
+	maxSize := matrix.Vec2{}
+	kids := p.containerChildren(&maxSize)
+	items := make([]grid.Item, len(kids))
+	for i := range kids {
+		items[i] = kids[i].Layout().gridItemSized()
+	}
+	width, height := p.containerContentSize()
+	res := grid.Arrange(p.PanelData().grid, width, height, items)
+	rects := make([]matrix.Vec4, len(res.Rects))
+	for i, r := range res.Rects {
+		rects[i] = matrix.Vec4{r.X, r.Y, r.Width, r.Height}
+	}
+	p.placeContainerChildren(offsetStart, kids, rects,
+		matrix.Vec2{res.ContentWidth, res.ContentHeight}, maxSize)
+}
@@ -0,0 +1,163 @@
+/******************************************************************************/
+/* layout.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package richtext
+
+import "unicode"
+
+// Run is the part of a span that is on a line, the runes [Start, End) of
+// the text of the span
+type Run struct {
+	Span       int
+	Start, End int
+	X, Width   float32
+}
+
+// Line is a line of runs. Y is how far the top of the line is from the top
+// of the text and Ascent is how far below that the baseline is.
+type Line struct {
+	Runs                     []Run
+	Y, Height, Ascent, Width float32
+}
+
+// Measurer gives the sizes of runes in a style
+type Measurer interface {
+	// Advance is how far the rune moves the next one to the right
+	Advance(r rune, style Style) float32
+	// LineMetrics are the height of a line in the style and how far below
+	// the top of the line the baseline is
+	LineMetrics(style Style) (height, ascent float32)
+}
+
+type styledRune struct {
+	r     rune
+	span  int
+	index int
+}
+
+// Layout breaks the spans into the lines they are shown in, wrapping after
+// white space when a line gets wider than maxWidth, even when the word goes
+// across spans. Words wider than maxWidth are broken. A maxWidth of 0 or
+// less only breaks lines at new lines.
+func Layout(spans []Span, maxWidth float32, m Measurer) []Line {
+	runes := make([]styledRune, 0)
+	for i := range spans {
+		index := 0
+		for _, r := range spans[i].Text {
+			runes = append(runes, styledRune{r, i, index})
+			index++
+		}
+	}
+	lines := make([]Line, 0, 1)
+	y := float32(0)
+	for start := 0; ; {
+		x, brk, i := float32(0), -1, start
+		for ; i < len(runes) && runes[i].r != '\n'; i++ {
+			w := m.Advance(runes[i].r, spans[runes[i].span].Style)
+			// White space hangs past the edge rather than starting a line
+			if maxWidth > 0 && x+w > maxWidth && i > start && !unicode.IsSpace(runes[i].r) {
+				break
+			}
+			x += w
+			if unicode.IsSpace(runes[i].r) {
+				brk = i + 1
+			}
+		}
+		end, next := i, i
+		if i == len(runes) || runes[i].r == '\n' {
+			next = i + 1
+		} else if brk > start {
+			end, next = brk, brk
+		}
+		line := buildLine(spans, runes[start:end], m, emptyLineSpan(runes, start, len(spans)))
+		line.Y = y
+		y += line.Height
+		lines = append(lines, line)
+		if end == len(runes) {
+			return lines
+		}
+		start = next
+	}
+}
+
+// emptyLineSpan is the span an empty line starting at the rune is sized by,
+// -1 when there are no spans
+func emptyLineSpan(runes []styledRune, at, spanCount int) int {
+	if at < len(runes) {
+		return runes[at].span
+	} else if at > 0 {
+		return runes[at-1].span
+	} else if spanCount > 0 {
+		return 0
+	}
+	return -1
+}
+
+func buildLine(spans []Span, runes []styledRune, m Measurer, emptySpan int) Line {
+	line := Line{}
+	if len(runes) == 0 {
+		style := Style{}
+		if emptySpan >= 0 {
+			style = spans[emptySpan].Style
+		}
+		line.Height, line.Ascent = m.LineMetrics(style)
+		return line
+	}
+	descent := float32(0)
+	for _, sr := range runes {
+		style := spans[sr.span].Style
+		w := m.Advance(sr.r, style)
+		if n := len(line.Runs); n > 0 && line.Runs[n-1].Span == sr.span {
+			line.Runs[n-1].End = sr.index + 1
+			line.Runs[n-1].Width += w
+		} else {
+			line.Runs = append(line.Runs, Run{
+				Span:  sr.span,
+				Start: sr.index,
+				End:   sr.index + 1,
+				X:     line.Width,
+				Width: w,
+			})
+			h, a := m.LineMetrics(style)
+			line.Ascent = max(line.Ascent, a)
+			descent = max(descent, h-a)
+			line.Height = line.Ascent + descent
+		}
+		line.Width += w
+	}
+	return line
+}
@@ -0,0 +1,140 @@
+/******************************************************************************/
+/* markup.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package richtext
+
+import (
+	"kaiju/matrix"
+	"strconv"
+	"strings"
+)
+
+type openTag struct {
+	name  string
+	style Style
+}
+
+// Parse reads text written in the lightweight rich text markup:
+//
+//	<b>bold</b> <i>italic</i> <u>underline</u> <s>strikethrough</s>
+//	<color=#f00>red</color> <size=20>big</size> <weight=lighter>thin</weight>
+//
+// Tags can be nested and a closing tag also closes the tags opened inside of
+// it. Anything that isn't a known tag, like a lone "<", is kept as text.
+func Parse(text string) []Span {
+	spans := make([]Span, 0, 1)
+	stack := []openTag{{}}
+	for len(text) > 0 {
+		start := strings.IndexByte(text, '<')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(text[start:], '>')
+		if end < 0 {
+			break
+		}
+		tag := text[start+1 : start+end]
+		top := stack[len(stack)-1].style
+		if name, ok := strings.CutPrefix(tag, "/"); ok {
+			if i := openIndex(stack, name); i > 0 {
+				spans = append(spans, Span{Text: text[:start], Style: top})
+				stack = stack[:i]
+				text = text[start+end+1:]
+				continue
+			}
+		} else if style, ok := applyTag(top, tag); ok {
+			spans = append(spans, Span{Text: text[:start], Style: top})
+			name, _, _ := strings.Cut(tag, "=")
+			stack = append(stack, openTag{name: strings.ToLower(name), style: style})
+			text = text[start+end+1:]
+			continue
+		}
+		// Not a tag, keep the "<" as text and look for the next one
+		spans = append(spans, Span{Text: text[:start+1], Style: top})
+		text = text[start+1:]
+	}
+	spans = append(spans, Span{Text: text, Style: stack[len(stack)-1].style})
+	return Merge(spans)
+}
+
+// openIndex finds the innermost open tag with the name
+func openIndex(stack []openTag, name string) int {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i := len(stack) - 1; i > 0; i-- {
+		if stack[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func applyTag(style Style, tag string) (Style, bool) {
+	name, value, hasValue := strings.Cut(tag, "=")
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "b":
+		style.Weight = "bold"
+	case "i":
+		style.Italic = true
+	case "u":
+		style.Underline = true
+	case "s":
+		style.Strikethrough = true
+	case "color":
+		c, err := matrix.ColorFromHexString(value)
+		if !hasValue || err != nil {
+			return style, false
+		}
+		style.Color, style.HasColor = c, true
+	case "size":
+		size, err := strconv.ParseFloat(strings.TrimSuffix(value, "px"), 32)
+		if !hasValue || err != nil || size <= 0 {
+			return style, false
+		}
+		style.Size = float32(size)
+	case "weight":
+		switch value {
+		case "normal", "bold", "bolder", "lighter":
+			style.Weight = value
+		default:
+			return style, false
+		}
+	default:
+		return style, false
+	}
+	return style, true
+}
@@ -0,0 +1,148 @@
+/******************************************************************************/
+/* richtext_test.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package richtext
+
+import (
+	"kaiju/matrix"
+	"testing"
+)
+
+// testMeasurer makes every rune as wide as its size is tall, with a line a
+// fifth taller than the size
+type testMeasurer struct{}
+
+func size(style Style) float32 {
+	if style.Size == 0 {
+		return 1
+	}
+	return style.Size
+}
+
+func (testMeasurer) Advance(r rune, style Style) float32 { return size(style) }
+
+func (testMeasurer) LineMetrics(style Style) (float32, float32) {
+	return size(style) * 1.2, size(style)
+}
+
+func near(a, b float32) bool { return matrix.Abs(a-b) < 0.0001 }
+
+func TestParse(t *testing.T) {
+	spans := Parse("a <b>bold <color=#f00>red</color></b> <size=20>big</b> 1 < 2</size>")
+	red := matrix.Color{1, 0, 0, 1}
+	expected := []Span{
+		{Text: "a "},
+		{Text: "bold ", Style: Style{Weight: "bold"}},
+		{Text: "red", Style: Style{Weight: "bold", Color: red, HasColor: true}},
+		{Text: " "},
+		{Text: "big</b> 1 < 2", Style: Style{Size: 20}},
+	}
+	if len(spans) != len(expected) {
+		t.Fatalf("expected %d spans but got %d: %v", len(expected), len(spans), spans)
+	}
+	for i := range spans {
+		if spans[i] != expected[i] {
+			t.Errorf("span %d: expected %+v but got %+v", i, expected[i], spans[i])
+		}
+	}
+	if Plain(spans) != "a bold red big</b> 1 < 2" {
+		t.Errorf("unexpected plain text %q", Plain(spans))
+	}
+}
+
+func TestParseNesting(t *testing.T) {
+	spans := Parse("<i><u>x</i>y<s><color=nope>z</s>")
+	if len(spans) != 3 {
+		t.Fatalf("unexpected spans %v", spans)
+	}
+	if !spans[0].Style.Italic || !spans[0].Style.Underline {
+		t.Error("nested tags should combine their styles")
+	}
+	if spans[1].Style != (Style{}) {
+		t.Error("closing the outer tag should close the inner one")
+	}
+	if spans[2].Text != "<color=nope>z" || !spans[2].Style.Strikethrough {
+		t.Errorf("an invalid tag should be kept as text, got %+v", spans[2])
+	}
+}
+
+func TestLayoutWrapsAcrossSpans(t *testing.T) {
+	spans := []Span{{Text: "one tw"}, {Text: "o three", Style: Style{Weight: "bold"}}}
+	lines := Layout(spans, 6, testMeasurer{})
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines but got %d", len(lines))
+	}
+	first := lines[0].Runs
+	if len(first) != 1 || first[0].Start != 0 || first[0].End != 4 {
+		t.Errorf("the word split across spans should move to the next line, got %+v", first)
+	}
+	second := lines[1].Runs
+	if len(second) != 2 || second[0].Start != 4 || second[1].Span != 1 ||
+		second[1].End != 2 || second[1].X != 2 {
+		t.Errorf("unexpected second line %+v", second)
+	}
+	if !near(lines[1].Y, 1.2) {
+		t.Errorf("the second line should be below the first, got %v", lines[1].Y)
+	}
+}
+
+func TestLayoutLineMetrics(t *testing.T) {
+	spans := []Span{{Text: "a"}, {Text: "B", Style: Style{Size: 3}}, {Text: "\n\nc"}}
+	lines := Layout(spans, 0, testMeasurer{})
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines but got %d", len(lines))
+	}
+	if lines[0].Ascent != 3 || !near(lines[0].Height, 3.6) {
+		t.Errorf("the tallest span should size the line, got %+v", lines[0])
+	}
+	if len(lines[1].Runs) != 0 || !near(lines[1].Height, 1.2) {
+		t.Errorf("an empty line should have the height of its text, got %+v", lines[1])
+	}
+	if !near(lines[2].Y, 4.8) || lines[2].Width != 1 {
+		t.Errorf("unexpected last line %+v", lines[2])
+	}
+	if lines := Layout(nil, 10, testMeasurer{}); len(lines) != 1 {
+		t.Error("empty text should still have a line")
+	}
+}
+
+func TestLayoutBreaksLongWords(t *testing.T) {
+	lines := Layout([]Span{{Text: "abcdef"}}, 4, testMeasurer{})
+	if len(lines) != 2 || lines[0].Runs[0].End != 4 || lines[1].Runs[0].Start != 4 {
+		t.Errorf("a word wider than the line should be broken, got %+v", lines)
+	}
+}
@@ -0,0 +1,94 @@
+/******************************************************************************/
+/* span.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+// Package richtext is the model behind the rich text of #ui.Label: spans of
+// text that each have their own color, weight, size and decorations, the
+// lightweight markup they are written in and how they wrap into lines. It
+// has no UI so that it can be tested on its own.
+package richtext
+
+import (
+	"kaiju/matrix"
+	"strings"
+)
+
+// Style is how the text of a span looks, the zero value looks like the
+// label the span is shown in
+type Style struct {
+	Color    matrix.Color
+	HasColor bool
+	// Weight is one of the weights #ui.Label.SetFontWeight takes ("bold",
+	// "bolder", "lighter" or "normal"), empty keeps the weight of the label
+	Weight string
+	Italic bool
+	// Size is the font size, 0 keeps the size of the label
+	Size          float32
+	Underline     bool
+	Strikethrough bool
+}
+
+// Span is a piece of text in a single style
+type Span struct {
+	Text  string
+	Style Style
+}
+
+// Plain is the text of the spans without their styles
+func Plain(spans []Span) string {
+	sb := strings.Builder{}
+	for i := range spans {
+		sb.WriteString(spans[i].Text)
+	}
+	return sb.String()
+}
+
+// Merge joins the neighboring spans that have the same style and drops the
+// empty ones
+func Merge(spans []Span) []Span {
+	out := make([]Span, 0, len(spans))
+	for _, s := range spans {
+		if s.Text == "" {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Style == s.Style {
+			out[n-1].Text += s.Text
+		} else {
+			out = append(out, s)
+		}
+	}
+	return out
+}
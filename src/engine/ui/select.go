@@ -39,6 +39,7 @@ func (s *Select) Init(text string, options []string, anchor Anchor) {
 	bg, _ := s.man.Host.TextureCache().Texture(
 		assets.TextureSquare, rendering.TextureFilterLinear)
 	p.Init(bg, anchor, ElementTypeSelect)
+	s.Base().SetFocusable(true)
 	data.selected = -1
 	{
 		// Create the label
@@ -145,6 +146,7 @@ func (s *Select) PickOption(index int) {
 	if data.selected != index {
 		data.selected = index
 		if index >= 0 {
+			s.Base().ExecuteEvent(EventTypeInput)
 			s.Base().ExecuteEvent(EventTypeChange)
 			s.Base().ExecuteEvent(EventTypeSubmit)
 			data.label.SetText(data.options[index])
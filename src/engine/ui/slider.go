@@ -68,6 +68,7 @@ func (s *Slider) Init(anchor Anchor) {
 	s.elmData = ld
 	p := s.Base().ToPanel()
 	p.Init(nil, anchor, ElementTypeSlider)
+	s.Base().SetFocusable(true)
 	host := p.man.Host
 	tex, _ := host.TextureCache().Texture(
 		assets.TextureSquare, rendering.TextureFilterLinear)
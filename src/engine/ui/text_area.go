@@ -0,0 +1,641 @@
+/******************************************************************************/
+/* text_area.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package ui
+
+import (
+	"kaiju/engine/assets"
+	"kaiju/engine/ui/textedit"
+	"kaiju/matrix"
+	"kaiju/platform/hid"
+	"kaiju/rendering"
+	"strings"
+	"unicode"
+)
+
+const textAreaTabWidth = 4
+
+type textAreaData struct {
+	panelData
+	buffer      *textedit.Buffer
+	list        *ListView
+	placeholder *Label
+	cursor      *Panel
+	cursorRow   *Panel
+	fontFace    rendering.FontFace
+	fontSize    float32
+	lineHeight  float32
+	widths      map[rune]float32
+	wrap        bool
+	wrapWidth   float32
+	fgColor     matrix.Color
+	selectColor matrix.Color
+	isActive    bool
+	selecting   bool
+	cursorBlink float32
+	updateId    int
+}
+
+func (t *textAreaData) innerPanelData() *panelData { return &t.panelData }
+
+// TextArea is a multiline text input. Lines wrap at the width of the area
+// unless wrapping is turned off, and the area scrolls vertically through
+// them. Only the lines that can be seen have labels so it can hold large
+// amounts of text. The editing itself is done by a #textedit.Buffer.
+type TextArea Panel
+
+func (u *UI) ToTextArea() *TextArea { return (*TextArea)(u) }
+func (t *TextArea) Base() *UI       { return (*UI)(t) }
+
+func (t *TextArea) TextAreaData() *textAreaData {
+	return t.elmData.(*textAreaData)
+}
+
+func (t *TextArea) Init(placeholderText string, anchor Anchor) {
+	td := &textAreaData{
+		buffer:      textedit.New(),
+		fontSize:    LabelFontSize,
+		widths:      make(map[rune]float32),
+		wrap:        true,
+		fgColor:     matrix.ColorBlack(),
+		selectColor: matrix.Color{1, 1, 0, 0.5},
+	}
+	t.elmData = td
+	p := (*Panel)(t)
+	host := t.man.Host
+	tex, _ := host.TextureCache().Texture(assets.TextureSquare, rendering.TextureFilterLinear)
+	p.Init(tex, anchor, ElementTypeTextArea)
+	t.Base().SetFocusable(true)
+	p.DontFitContent()
+	p.SetColor(matrix.ColorWhite())
+
+	td.placeholder = t.man.Add().ToLabel()
+	td.placeholder.Init(placeholderText, AnchorTopLeft)
+	td.placeholder.SetBaseline(rendering.FontBaselineTop)
+	td.placeholder.SetBGColor(matrix.ColorTransparent())
+	td.placeholder.layout.SetPositioning(PositioningAbsolute)
+	td.placeholder.layout.SetOffset(horizontalPadding, verticalPadding)
+	p.AddChild(td.placeholder.Base())
+	td.fontFace = td.placeholder.LabelData().fontFace
+	td.lineHeight = t.measureLineHeight()
+
+	td.list = t.man.Add().ToListView()
+	td.list.Init(ListViewSource{
+		Count:     func() int { return len(td.buffer.Lines()) },
+		CreateRow: t.createRow,
+		BindRow:   t.bindRow,
+		RowHeight: func(int) float32 { return td.lineHeight },
+		// Clicks place the cursor rather than selecting rows
+		Click: func(*Panel, int) {},
+	}, td.lineHeight, AnchorTopLeft)
+	(*Panel)(td.list).SetColor(matrix.ColorTransparent())
+	td.list.layout.AddFunction(func(l *Layout) {
+		l.Scale(p.layout.ContentSize())
+	})
+	p.AddChild(td.list.Base())
+
+	td.cursor = t.man.Add().ToPanel()
+	td.cursor.Init(tex, AnchorTopLeft, ElementTypePanel)
+	td.cursor.DontFitContent()
+	td.cursor.SetColor(matrix.ColorBlack())
+	td.cursor.layout.SetPositioning(PositioningAbsolute)
+	td.cursor.layout.SetZ(cursorZ)
+	td.cursor.layout.Scale(cursorWidth, td.lineHeight)
+	p.AddChild(td.cursor.Base())
+	td.cursor.entity.Deactivate()
+
+	t.setMeasure()
+	base := t.Base()
+	base.AddEvent(EventTypeEnter, func() { host.Window.CursorIbeam() })
+	base.AddEvent(EventTypeExit, func() { host.Window.CursorStandard() })
+	base.AddEvent(EventTypeDown, t.onDown)
+	base.AddEvent(EventTypeDoubleClick, t.onDoubleClick)
+	base.AddEvent(EventTypeMiss, t.RemoveFocus)
+	base.AddEvent(EventTypeRender, t.rewrap)
+	keyId := host.Window.Keyboard.AddKeyCallback(t.keyPressed)
+	base.AddEvent(EventTypeDestroy, func() {
+		host.Window.Keyboard.RemoveKeyCallback(keyId)
+		if td.updateId != 0 {
+			host.UIUpdater.RemoveUpdate(td.updateId)
+		}
+	})
+	t.entity.OnDeactivate.Add(t.RemoveFocus)
+}
+
+// Buffer is the editing model of the text area, call #TextArea.Refresh
+// after changing it directly
+func (t *TextArea) Buffer() *textedit.Buffer { return t.TextAreaData().buffer }
+
+func (t *TextArea) Text() string { return t.TextAreaData().buffer.Text() }
+
+// SetText replaces the text and clears the undo history
+func (t *TextArea) SetText(text string) {
+	if t.Text() != text {
+		t.SetTextWithoutEvent(text)
+		t.Base().ExecuteEvent(EventTypeChange)
+	}
+}
+
+func (t *TextArea) SetTextWithoutEvent(text string) {
+	t.TextAreaData().buffer.SetText(text)
+	t.Refresh()
+}
+
+// SetMaxLength limits how many characters the text can have, 0 is no limit.
+// Text over the limit is cut off.
+func (t *TextArea) SetMaxLength(length int) {
+	b := t.TextAreaData().buffer
+	b.MaxLength = max(length, 0)
+	if b.MaxLength > 0 && b.Len() > b.MaxLength {
+		b.SetText(string([]rune(b.Text())[:b.MaxLength]))
+		t.Refresh()
+	}
+}
+
+// SetTabSpaces makes tab insert that many spaces, 0 inserts a tab character
+func (t *TextArea) SetTabSpaces(spaces int) {
+	t.TextAreaData().buffer.TabSpaces = max(spaces, 0)
+	t.setMeasure()
+}
+
+// SetWordWrap turns the wrapping of long lines on or off, without it the
+// area scrolls horizontally
+func (t *TextArea) SetWordWrap(wrap bool) {
+	td := t.TextAreaData()
+	td.wrap = wrap
+	td.wrapWidth = -1
+	t.rewrap()
+}
+
+func (t *TextArea) SetPlaceholder(text string) {
+	t.TextAreaData().placeholder.SetText(text)
+}
+
+func (t *TextArea) SetFontSize(fontSize float32) {
+	td := t.TextAreaData()
+	td.fontSize = fontSize
+	td.placeholder.SetFontSize(fontSize)
+	td.lineHeight = t.measureLineHeight()
+	td.cursor.layout.Scale(cursorWidth, td.lineHeight)
+	clear(td.widths)
+	td.list.ListViewData().recycler.All(func(row *Panel) {
+		FirstOnEntity(row.entity.Children[1]).ToLabel().SetFontSize(fontSize)
+	})
+	t.setMeasure()
+}
+
+func (t *TextArea) SetFGColor(color matrix.Color) {
+	td := t.TextAreaData()
+	td.fgColor = color
+	td.placeholder.SetColor(matrix.ColorMix(color, color.Inverted(), 0.5))
+	td.list.Refresh()
+}
+
+func (t *TextArea) SetBGColor(color matrix.Color) { (*Panel)(t).SetColor(color) }
+
+func (t *TextArea) SetCursorColor(color matrix.Color) {
+	t.TextAreaData().cursor.SetColor(color)
+}
+
+func (t *TextArea) SetSelectColor(color matrix.Color) {
+	t.TextAreaData().selectColor = color
+	t.TextAreaData().list.Refresh()
+}
+
+// LineColumn is the line and column of the cursor, counting from 0
+func (t *TextArea) LineColumn() (line, column int) {
+	return t.TextAreaData().buffer.LineColumn()
+}
+
+func (t *TextArea) SelectAll() {
+	t.TextAreaData().buffer.SelectAll()
+	t.cursorMoved()
+}
+
+func (t *TextArea) SelectedText() string {
+	return t.TextAreaData().buffer.SelectedText()
+}
+
+func (t *TextArea) Undo() {
+	if t.TextAreaData().buffer.Undo() {
+		t.changed()
+	}
+}
+
+func (t *TextArea) Redo() {
+	if t.TextAreaData().buffer.Redo() {
+		t.changed()
+	}
+}
+
+// InsertText types the text at the cursor, replacing the selection
+func (t *TextArea) InsertText(text string) {
+	if t.TextAreaData().buffer.Paste(text) {
+		t.changed()
+	}
+}
+
+// Refresh shows the text of the buffer again after it was changed
+func (t *TextArea) Refresh() {
+	td := t.TextAreaData()
+	if td.buffer.Len() == 0 {
+		td.placeholder.Show()
+	} else {
+		td.placeholder.Hide()
+	}
+	td.list.Refresh()
+	td.list.ScrollIntoView(td.buffer.LineOf(td.buffer.Cursor()))
+	td.cursorBlink = cursorBlinkRate
+	t.updateCursor()
+}
+
+func (t *TextArea) IsFocused() bool { return t.TextAreaData().isActive }
+
+func (t *TextArea) Focus() {
+	td := t.TextAreaData()
+	if td.isActive {
+		return
+	}
+	td.isActive = true
+	td.cursorBlink = cursorBlinkRate
+	td.updateId = t.man.Host.UIUpdater.AddUpdate(t.update)
+	t.updateCursor()
+	if t.group != nil {
+		t.group.setFocus(t.Base())
+	}
+	t.Base().requestEvent(EventTypeFocus)
+}
+
+func (t *TextArea) RemoveFocus() {
+	td := t.TextAreaData()
+	if !td.isActive {
+		return
+	}
+	td.isActive = false
+	td.selecting = false
+	t.man.Host.UIUpdater.RemoveUpdate(td.updateId)
+	td.updateId = 0
+	t.updateCursor()
+	if t.group != nil {
+		t.group.setFocus(nil)
+	}
+	t.Base().requestEvent(EventTypeBlur)
+}
+
+func (t *TextArea) measureLineHeight() float32 {
+	td := t.TextAreaData()
+	return t.man.Host.FontCache().MeasureStringWithin(td.fontFace, " ",
+		td.fontSize, 100000, 0).Y()
+}
+
+// setMeasure gives the buffer the width of each rune in the font, tabs are
+// shown as spaces
+func (t *TextArea) setMeasure() {
+	td := t.TextAreaData()
+	td.wrapWidth = -1
+	td.buffer.SetWrap(0, func(r rune) float32 {
+		if w, ok := td.widths[r]; ok {
+			return w
+		}
+		s := string(r)
+		if r == '\t' {
+			s = t.tabText()
+		}
+		w := t.man.Host.FontCache().MeasureString(td.fontFace, s, td.fontSize)
+		td.widths[r] = w
+		return w
+	})
+	t.rewrap()
+}
+
+func (t *TextArea) tabText() string {
+	spaces := t.TextAreaData().buffer.TabSpaces
+	if spaces <= 0 {
+		spaces = textAreaTabWidth
+	}
+	return strings.Repeat(" ", spaces)
+}
+
+// rewrap wraps the lines again when the width of the area changed, it runs
+// after layout so the width is known
+func (t *TextArea) rewrap() {
+	td := t.TextAreaData()
+	width := float32(0)
+	if td.wrap {
+		w, _ := (*Panel)(td.list).containerContentSize()
+		width = w - horizontalPadding*2
+	}
+	if width == td.wrapWidth {
+		return
+	}
+	td.wrapWidth = width
+	td.buffer.SetWrap(width, nil)
+	if !td.wrap {
+		td.list.SetContentWidth(t.longestLine() + horizontalPadding*2 + cursorWidth)
+	} else {
+		td.list.SetContentWidth(0)
+	}
+	t.Refresh()
+}
+
+func (t *TextArea) longestLine() float32 {
+	b := t.TextAreaData().buffer
+	width := float32(0)
+	for i, l := range b.Lines() {
+		width = max(width, b.LineX(i, l.End))
+	}
+	return width
+}
+
+func (t *TextArea) createRow(man *Manager) *Panel {
+	td := t.TextAreaData()
+	row := man.Add().ToPanel()
+	row.Init(nil, AnchorTopLeft, ElementTypePanel)
+	row.SetColor(matrix.ColorTransparent())
+	row.SetScrollDirection(PanelScrollDirectionNone)
+	highlight := man.Add().ToPanel()
+	highlight.Init(nil, AnchorTopLeft, ElementTypePanel)
+	highlight.DontFitContent()
+	highlight.layout.SetPositioning(PositioningAbsolute)
+	highlight.layout.SetZ(highlightZ)
+	row.AddChild(highlight.Base())
+	label := man.Add().ToLabel()
+	label.Init("", AnchorTopLeft)
+	label.SetBaseline(rendering.FontBaselineTop)
+	label.SetBGColor(matrix.ColorTransparent())
+	label.SetFontSize(td.fontSize)
+	label.SetMaxWidth(100000.0)
+	label.LabelData().wordWrap = false
+	label.layout.SetPositioning(PositioningAbsolute)
+	label.layout.SetOffset(horizontalPadding, 0)
+	row.AddChild(label.Base())
+	return row
+}
+
+// bindRow shows the line on the row along with the part of the selection on
+// it. The cursor moves to the row of its line.
+func (t *TextArea) bindRow(row *Panel, index int, _ bool) {
+	td := t.TextAreaData()
+	b := td.buffer
+	label := FirstOnEntity(row.entity.Children[1]).ToLabel()
+	label.SetText(strings.ReplaceAll(b.LineText(index), "\t", t.tabText()))
+	label.SetColor(td.fgColor)
+	highlight := FirstOnEntity(row.entity.Children[0]).ToPanel()
+	line := b.Lines()[index]
+	start, end := b.Selection()
+	if start < end && start <= line.End && end > line.Start {
+		x0 := b.LineX(index, start)
+		x1 := b.LineX(index, end)
+		if end > line.End && !line.Wrapped {
+			// The selected new line is shown as a space at the end of the line
+			x1 += t.man.Host.FontCache().MeasureString(
+				td.fontFace, " ", td.fontSize)
+		}
+		highlight.SetColor(td.selectColor)
+		highlight.layout.SetOffset(horizontalPadding+x0, 0)
+		highlight.layout.Scale(max(x1-x0, 1), td.lineHeight)
+		if !highlight.entity.IsActive() {
+			highlight.entity.Activate()
+		}
+	} else if highlight.entity.IsActive() {
+		highlight.entity.Deactivate()
+	}
+	if index == b.LineOf(b.Cursor()) {
+		td.cursorRow = row
+		if td.cursor.entity.Parent != &row.entity {
+			row.AddChild(td.cursor.Base())
+		}
+		td.cursor.layout.SetOffset(horizontalPadding+b.LineX(index, b.Cursor()), 0)
+	} else if td.cursorRow == row {
+		td.cursorRow = nil
+	}
+	t.updateCursor()
+}
+
+// updateCursor shows the cursor while the area has focus and the line of the
+// cursor is shown, it blinks while nothing is typed
+func (t *TextArea) updateCursor() {
+	td := t.TextAreaData()
+	show := td.isActive && td.cursorRow != nil && td.cursorBlink > 0 &&
+		td.cursor.entity.Parent == &td.cursorRow.entity
+	if show != td.cursor.entity.IsActive() {
+		td.cursor.entity.SetActive(show)
+	}
+}
+
+func (t *TextArea) update(deltaTime float64) {
+	td := t.TextAreaData()
+	if !t.entity.IsActive() {
+		return
+	}
+	td.cursorBlink -= float32(deltaTime)
+	if td.cursorBlink < -cursorBlinkRate {
+		td.cursorBlink = cursorBlinkRate
+	}
+	if td.selecting {
+		if t.man.Host.Window.Cursor.Held() {
+			if pos := t.posAtCursor(); pos != td.buffer.Cursor() {
+				td.buffer.MoveTo(pos, true)
+				t.cursorMoved()
+			}
+		} else {
+			td.selecting = false
+		}
+	}
+	t.updateCursor()
+}
+
+// posAtCursor is the position in the text closest to the mouse cursor, above
+// or below the lines it is on the first or last line
+func (t *TextArea) posAtCursor() int {
+	td := t.TextAreaData()
+	lp := (*Panel)(td.list)
+	pos := t.Base().cursorPos(&t.man.Host.Window.Cursor)
+	wp := td.list.entity.Transform.WorldPosition()
+	ws := td.list.entity.Transform.WorldScale()
+	y := wp.Y() + ws.Y()*0.5 - pos.Y() - lp.layout.padding.Top() -
+		lp.layout.border.Top() + lp.ScrollY()
+	x := pos.X() - (wp.X() - ws.X()*0.5) - lp.layout.padding.Left() -
+		lp.layout.border.Left() + lp.ScrollX() - horizontalPadding
+	line := int(y / td.lineHeight)
+	if y < 0 {
+		return 0
+	} else if line >= len(td.buffer.Lines()) {
+		return td.buffer.Len()
+	}
+	return td.buffer.PosAt(line, x)
+}
+
+func (t *TextArea) onDown() {
+	td := t.TextAreaData()
+	t.Focus()
+	td.buffer.MoveTo(t.posAtCursor(), t.man.Host.Window.Keyboard.HasShift())
+	td.selecting = true
+	t.cursorMoved()
+}
+
+func (t *TextArea) onDoubleClick() {
+	td := t.TextAreaData()
+	td.selecting = false
+	td.buffer.SelectWord(t.posAtCursor())
+	t.cursorMoved()
+}
+
+// cursorMoved shows the new selection and keeps the cursor in view
+func (t *TextArea) cursorMoved() {
+	td := t.TextAreaData()
+	td.list.Refresh()
+	td.list.ScrollIntoView(td.buffer.LineOf(td.buffer.Cursor()))
+	td.cursorBlink = cursorBlinkRate
+	t.updateCursor()
+}
+
+func (t *TextArea) changed() {
+	td := t.TextAreaData()
+	if !td.wrap {
+		td.list.SetContentWidth(t.longestLine() + horizontalPadding*2 + cursorWidth)
+	}
+	t.Refresh()
+	t.Base().ExecuteEvent(EventTypeInput)
+	t.Base().ExecuteEvent(EventTypeChange)
+}
+
+// visibleLines is how many lines fit in the area, it is how far page up and
+// page down move
+func (t *TextArea) visibleLines() int {
+	td := t.TextAreaData()
+	_, h := (*Panel)(td.list).containerContentSize()
+	return max(int(h/td.lineHeight), 1)
+}
+
+func (t *TextArea) keyPressed(keyId int, keyState hid.KeyState) {
+	td := t.TextAreaData()
+	if !t.entity.IsActive() || !td.isActive {
+		return
+	}
+	if keyState == hid.KeyStateUp {
+		t.Base().requestKeyEvent(EventTypeKeyUp, keyId)
+		return
+	} else if keyState != hid.KeyStateDown {
+		return
+	}
+	b := td.buffer
+	kb := &t.man.Host.Window.Keyboard
+	ctrl, shift := kb.HasCtrl(), kb.HasShift()
+	edited, moved := false, true
+	if c := kb.KeyToRune(keyId); c != 0 && !ctrl {
+		edited = b.Insert(string(c))
+	} else if c != 0 {
+		switch unicode.ToLower(c) {
+		case 'c':
+			t.copyToClipboard()
+		case 'x':
+			t.copyToClipboard()
+			edited = b.DeleteSelection()
+		case 'v':
+			edited = b.Paste(t.man.Host.Window.ClipboardContents())
+		case 'a':
+			b.SelectAll()
+		case 'z':
+			if shift {
+				edited = b.Redo()
+			} else {
+				edited = b.Undo()
+			}
+		case 'y':
+			edited = b.Redo()
+		default:
+			moved = false
+		}
+	} else {
+		switch keyId {
+		case hid.KeyboardKeyEscape:
+			t.RemoveFocus()
+			return
+		case hid.KeyboardKeyBackspace:
+			edited = b.Backspace(ctrl)
+		case hid.KeyboardKeyDelete:
+			edited = b.Delete(ctrl)
+		case hid.KeyboardKeyLeft:
+			b.MoveLeft(ctrl, shift)
+		case hid.KeyboardKeyRight:
+			b.MoveRight(ctrl, shift)
+		case hid.KeyboardKeyUp:
+			b.MoveUp(shift)
+		case hid.KeyboardKeyDown:
+			b.MoveDown(shift)
+		case hid.KeyboardKeyPageUp:
+			b.MoveLines(-t.visibleLines(), shift)
+		case hid.KeyboardKeyPageDown:
+			b.MoveLines(t.visibleLines(), shift)
+		case hid.KeyboardKeyHome:
+			if ctrl {
+				b.MoveTo(0, shift)
+			} else {
+				b.LineStart(shift)
+			}
+		case hid.KeyboardKeyEnd:
+			if ctrl {
+				b.MoveTo(b.Len(), shift)
+			} else {
+				b.LineEnd(shift)
+			}
+		case hid.KeyboardKeyReturn, hid.KeyboardKeyEnter:
+			if ctrl {
+				t.Base().requestEvent(EventTypeSubmit)
+			} else {
+				edited = b.Insert("\n")
+			}
+		case hid.KeyboardKeyTab:
+			edited = b.Tab(shift)
+		default:
+			moved = false
+		}
+	}
+	if edited {
+		t.changed()
+	} else if moved {
+		t.cursorMoved()
+	}
+	t.Base().requestKeyEvent(EventTypeKeyDown, keyId)
+}
+
+func (t *TextArea) copyToClipboard() {
+	if text := t.SelectedText(); text != "" {
+		t.man.Host.Window.CopyToClipboard(text)
+	}
+}
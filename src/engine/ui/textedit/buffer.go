@@ -0,0 +1,407 @@
+/******************************************************************************/
+/* buffer.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+// Package textedit is the editing model behind #ui.TextArea: the text, the
+// cursor and selection, the undo history and the lines the text wraps into.
+// It has no UI so that editing can be tested on its own.
+package textedit
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+type editKind int
+
+const (
+	editOther = editKind(iota)
+	editTyping
+	editBackspace
+	editDelete
+)
+
+// edit replaced the removed runes at the index with the inserted ones, the
+// cursor and anchor before and after it are kept to restore the selection
+type edit struct {
+	at                int
+	removed, inserted []rune
+	kind              editKind
+	before, after     [2]int
+}
+
+// Buffer is the text being edited, the position of the cursor and the
+// anchor the selection is extended from. Positions are rune indexes
+// into the text.
+type Buffer struct {
+	text    []rune
+	cursor  int
+	anchor  int
+	undo    []edit
+	redo    []edit
+	merge   bool
+	lines   []Line
+	stale   bool
+	width   float32
+	measure func(r rune) float32
+	goalX   float32
+	hasGoal bool
+	// MaxLength is the most runes the text can have, 0 is no limit
+	MaxLength int
+	// TabSpaces is the number of spaces a tab inserts, 0 inserts a tab
+	TabSpaces int
+}
+
+// New creates an empty buffer that doesn't wrap its lines and measures each
+// rune as 1 wide, so x positions are columns until #Buffer.SetWrap is used
+func New() *Buffer {
+	return &Buffer{stale: true, measure: func(rune) float32 { return 1 }}
+}
+
+func (b *Buffer) Text() string { return string(b.text) }
+func (b *Buffer) Len() int     { return len(b.text) }
+func (b *Buffer) Cursor() int  { return b.cursor }
+
+// SetText replaces the text and forgets the undo history, the cursor is put
+// at the end of the text
+func (b *Buffer) SetText(text string) {
+	b.text = b.limit([]rune(normalize(text)), 0)
+	b.undo = b.undo[:0]
+	b.redo = b.redo[:0]
+	b.merge = false
+	b.cursor = len(b.text)
+	b.anchor = b.cursor
+	b.hasGoal = false
+	b.stale = true
+}
+
+// Selection is the selected range, start and end are the same when nothing
+// is selected
+func (b *Buffer) Selection() (start, end int) {
+	return min(b.cursor, b.anchor), max(b.cursor, b.anchor)
+}
+
+func (b *Buffer) HasSelection() bool { return b.cursor != b.anchor }
+
+func (b *Buffer) SelectedText() string {
+	start, end := b.Selection()
+	return string(b.text[start:end])
+}
+
+// Select selects from the anchor to the cursor, the cursor is left where the
+// selection was extended to
+func (b *Buffer) Select(anchor, cursor int) {
+	b.anchor = b.clamp(anchor)
+	b.cursor = b.clamp(cursor)
+	b.moved()
+}
+
+func (b *Buffer) SelectAll() { b.Select(0, len(b.text)) }
+
+// SelectWord selects the word (or run of white space) around the position
+func (b *Buffer) SelectWord(pos int) {
+	pos = b.clamp(pos)
+	if pos == len(b.text) || (pos > 0 && isWordBreak(b.text[pos]) && !isWordBreak(b.text[pos-1])) {
+		pos = max(pos-1, 0)
+	}
+	if pos == len(b.text) {
+		b.Select(pos, pos)
+		return
+	}
+	space := isWordBreak(b.text[pos])
+	start, end := pos, pos
+	for start > 0 && isWordBreak(b.text[start-1]) == space && b.text[start-1] != '\n' {
+		start--
+	}
+	for end < len(b.text) && isWordBreak(b.text[end]) == space && b.text[end] != '\n' {
+		end++
+	}
+	b.Select(start, end)
+}
+
+// Insert types the text over the selection. Consecutive typing is undone a
+// word at a time, along with the white space typed after the word.
+func (b *Buffer) Insert(text string) bool {
+	return b.replaceSelection([]rune(normalize(text)), editTyping)
+}
+
+// Paste inserts the text over the selection as a single undo step
+func (b *Buffer) Paste(text string) bool {
+	return b.replaceSelection([]rune(normalize(text)), editOther)
+}
+
+// DeleteSelection removes the selected text
+func (b *Buffer) DeleteSelection() bool {
+	return b.HasSelection() && b.replaceSelection(nil, editOther)
+}
+
+// Backspace removes the selection or the rune before the cursor, word
+// removes up to the start of the word before the cursor instead
+func (b *Buffer) Backspace(word bool) bool {
+	if b.HasSelection() {
+		return b.DeleteSelection()
+	}
+	from := b.cursor - 1
+	if word {
+		from = b.wordLeft(b.cursor)
+	}
+	if from < 0 {
+		return false
+	}
+	return b.apply(from, b.cursor-from, nil, editBackspace)
+}
+
+// Delete removes the selection or the rune after the cursor, word removes
+// up to the start of the next word instead
+func (b *Buffer) Delete(word bool) bool {
+	if b.HasSelection() {
+		return b.DeleteSelection()
+	}
+	to := b.cursor + 1
+	if word {
+		to = b.wordRight(b.cursor)
+	}
+	if to > len(b.text) {
+		return false
+	}
+	return b.apply(b.cursor, to-b.cursor, nil, editDelete)
+}
+
+// Tab inserts a tab (or #Buffer.TabSpaces spaces). When the selection spans
+// more than one line the lines are indented instead, shift takes one level
+// of indentation off the lines.
+func (b *Buffer) Tab(shift bool) bool {
+	start, end := b.Selection()
+	multiline := slices.Contains(b.text[start:end], '\n')
+	if !shift && !multiline {
+		return b.replaceSelection([]rune(b.indent()), editOther)
+	}
+	first := b.paragraphStart(start)
+	last := end
+	if multiline && end > start && b.text[end-1] == '\n' {
+		// A selection ending at the start of a line doesn't include it
+		last--
+	}
+	last = b.paragraphEnd(last)
+	lines := strings.Split(string(b.text[first:last]), "\n")
+	for i, l := range lines {
+		if shift {
+			lines[i] = b.unindent(l)
+		} else {
+			lines[i] = b.indent() + l
+		}
+	}
+	indented := []rune(strings.Join(lines, "\n"))
+	if b.MaxLength > 0 && len(b.text)-(last-first)+len(indented) > b.MaxLength {
+		// Limiting the lines like other edits would cut off the selected text
+		return false
+	}
+	cursor := b.cursor + len(indented) - (last - first)
+	if !b.apply(first, last-first, indented, editOther) {
+		return false
+	}
+	if multiline {
+		b.setAfter(first, first+len(indented))
+	} else {
+		cursor = max(cursor, first)
+		b.setAfter(cursor, cursor)
+	}
+	return true
+}
+
+// Undo reverts the last edit, returns false if there is nothing to undo
+func (b *Buffer) Undo() bool {
+	if len(b.undo) == 0 {
+		return false
+	}
+	e := b.undo[len(b.undo)-1]
+	b.undo = b.undo[:len(b.undo)-1]
+	b.splice(e.at, len(e.inserted), e.removed)
+	b.anchor, b.cursor = e.before[0], e.before[1]
+	b.redo = append(b.redo, e)
+	b.moved()
+	return true
+}
+
+// Redo applies the last undone edit again, returns false if there is
+// nothing to redo
+func (b *Buffer) Redo() bool {
+	if len(b.redo) == 0 {
+		return false
+	}
+	e := b.redo[len(b.redo)-1]
+	b.redo = b.redo[:len(b.redo)-1]
+	b.splice(e.at, len(e.removed), e.inserted)
+	b.anchor, b.cursor = e.after[0], e.after[1]
+	b.undo = append(b.undo, e)
+	b.moved()
+	return true
+}
+
+func (b *Buffer) CanUndo() bool { return len(b.undo) > 0 }
+func (b *Buffer) CanRedo() bool { return len(b.redo) > 0 }
+
+func (b *Buffer) replaceSelection(insert []rune, kind editKind) bool {
+	start, end := b.Selection()
+	if kind == editTyping && start != end {
+		kind = editOther
+	}
+	return b.apply(start, end-start, insert, kind)
+}
+
+// apply replaces count runes at the index with the inserted ones and puts
+// the cursor after them. The edit is merged into the last one when both are
+// typing or both remove runes next to each other.
+func (b *Buffer) apply(at, count int, insert []rune, kind editKind) bool {
+	insert = b.limit(insert, count)
+	if count == 0 && len(insert) == 0 {
+		return false
+	}
+	e := edit{
+		at:       at,
+		removed:  slices.Clone(b.text[at : at+count]),
+		inserted: slices.Clone(insert),
+		kind:     kind,
+		before:   [2]int{b.anchor, b.cursor},
+	}
+	b.splice(at, count, insert)
+	b.cursor = at + len(insert)
+	b.anchor = b.cursor
+	e.after = [2]int{b.anchor, b.cursor}
+	b.redo = b.redo[:0]
+	if !b.mergeEdit(e) {
+		b.undo = append(b.undo, e)
+	}
+	b.merge = kind != editOther
+	b.hasGoal = false
+	return true
+}
+
+func (b *Buffer) mergeEdit(e edit) bool {
+	if !b.merge || len(b.undo) == 0 {
+		return false
+	}
+	last := &b.undo[len(b.undo)-1]
+	if last.kind != e.kind {
+		return false
+	}
+	switch e.kind {
+	case editTyping:
+		if len(e.inserted) != 1 || last.at+len(last.inserted) != e.at ||
+			e.inserted[0] == '\n' || startsWord(last.inserted, e.inserted[0]) {
+			return false
+		}
+		last.inserted = append(last.inserted, e.inserted...)
+	case editBackspace:
+		if e.at+len(e.removed) != last.at {
+			return false
+		}
+		last.at = e.at
+		last.removed = append(e.removed, last.removed...)
+	case editDelete:
+		if e.at != last.at {
+			return false
+		}
+		last.removed = append(last.removed, e.removed...)
+	default:
+		return false
+	}
+	last.after = e.after
+	return true
+}
+
+// startsWord is true if the rune starts a new word after what was typed
+func startsWord(typed []rune, r rune) bool {
+	return len(typed) > 0 && isWordBreak(typed[len(typed)-1]) && !isWordBreak(r)
+}
+
+func (b *Buffer) splice(at, count int, insert []rune) {
+	b.text = slices.Insert(slices.Delete(b.text, at, at+count), at, insert...)
+	b.stale = true
+}
+
+// limit cuts the runes to what fits within the max length once count runes
+// are removed
+func (b *Buffer) limit(insert []rune, count int) []rune {
+	if b.MaxLength <= 0 {
+		return insert
+	}
+	room := max(b.MaxLength-(len(b.text)-count), 0)
+	return insert[:min(len(insert), room)]
+}
+
+// setAfter moves the selection after an edit, it is where redoing the edit
+// puts the selection too
+func (b *Buffer) setAfter(anchor, cursor int) {
+	b.anchor, b.cursor = b.clamp(anchor), b.clamp(cursor)
+	b.undo[len(b.undo)-1].after = [2]int{b.anchor, b.cursor}
+}
+
+// unindent takes a tab, or up to a tab worth of spaces, off the line
+func (b *Buffer) unindent(line string) string {
+	if strings.HasPrefix(line, "\t") {
+		return line[1:]
+	}
+	n := 0
+	for n < max(b.TabSpaces, 4) && n < len(line) && line[n] == ' ' {
+		n++
+	}
+	return line[n:]
+}
+
+func (b *Buffer) indent() string {
+	if b.TabSpaces <= 0 {
+		return "\t"
+	}
+	return strings.Repeat(" ", b.TabSpaces)
+}
+
+// moved ends the merging of edits, the next edit starts a new undo step
+func (b *Buffer) moved() {
+	b.merge = false
+	b.hasGoal = false
+}
+
+func (b *Buffer) clamp(pos int) int { return min(max(pos, 0), len(b.text)) }
+
+func normalize(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+}
+
+func isWordBreak(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r)
+}
@@ -0,0 +1,232 @@
+/******************************************************************************/
+/* navigation.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package textedit
+
+import "slices"
+
+// SetWrap sets the width lines wrap at and how wide each rune is, a width of
+// 0 or less doesn't wrap lines
+func (b *Buffer) SetWrap(width float32, measure func(r rune) float32) {
+	b.width = width
+	if measure != nil {
+		b.measure = measure
+	}
+	b.stale = true
+}
+
+// Lines are the lines the text is shown in
+func (b *Buffer) Lines() []Line {
+	if b.stale {
+		b.lines = Wrap(b.text, b.width, b.measure)
+		b.stale = false
+	}
+	return b.lines
+}
+
+// LineOf is the line the position is shown on. A position where a line was
+// wrapped is at the start of the next line.
+func (b *Buffer) LineOf(pos int) int {
+	lines := b.Lines()
+	i, _ := slices.BinarySearchFunc(lines, pos, func(l Line, pos int) int {
+		if l.Start <= pos {
+			return -1
+		}
+		return 1
+	})
+	return max(i-1, 0)
+}
+
+// X is how far from the start of its line the position is
+func (b *Buffer) X(pos int) float32 { return b.LineX(b.LineOf(pos), pos) }
+
+// LineX is how far from the start of the line the position is, positions
+// past the end of the line are at its end
+func (b *Buffer) LineX(line, pos int) float32 {
+	l := b.Lines()[line]
+	x := float32(0)
+	for _, r := range b.text[l.Start:min(max(pos, l.Start), l.End)] {
+		x += b.measure(r)
+	}
+	return x
+}
+
+// LineText is the text shown on the line
+func (b *Buffer) LineText(line int) string {
+	l := b.Lines()[line]
+	return string(b.text[l.Start:l.End])
+}
+
+// PosAt is the position on the line that is closest to x
+func (b *Buffer) PosAt(line int, x float32) int {
+	lines := b.Lines()
+	l := lines[min(max(line, 0), len(lines)-1)]
+	pos, at := l.Start, float32(0)
+	for pos < lineEnd(l) {
+		w := b.measure(b.text[pos])
+		if at+w*0.5 > x {
+			break
+		}
+		at += w
+		pos++
+	}
+	return pos
+}
+
+// LineColumn is the line and column of the cursor in the text, counting
+// from 0, wrapped lines aren't counted as separate lines
+func (b *Buffer) LineColumn() (line, column int) {
+	start := 0
+	for i, r := range b.text[:b.cursor] {
+		if r == '\n' {
+			line++
+			start = i + 1
+		}
+	}
+	return line, b.cursor - start
+}
+
+// MoveTo moves the cursor to the position, extend keeps the anchor where it
+// is so the selection grows or shrinks to the cursor
+func (b *Buffer) MoveTo(pos int, extend bool) {
+	b.cursor = b.clamp(pos)
+	if !extend {
+		b.anchor = b.cursor
+	}
+	b.moved()
+}
+
+// MoveLeft moves the cursor a rune, or a word, to the left. Without extend
+// a selection collapses to its start.
+func (b *Buffer) MoveLeft(word, extend bool) {
+	if start, _ := b.Selection(); b.HasSelection() && !extend {
+		b.MoveTo(start, false)
+	} else if word {
+		b.MoveTo(b.wordLeft(b.cursor), extend)
+	} else {
+		b.MoveTo(b.cursor-1, extend)
+	}
+}
+
+// MoveRight moves the cursor a rune, or a word, to the right. Without extend
+// a selection collapses to its end.
+func (b *Buffer) MoveRight(word, extend bool) {
+	if _, end := b.Selection(); b.HasSelection() && !extend {
+		b.MoveTo(end, false)
+	} else if word {
+		b.MoveTo(b.wordRight(b.cursor), extend)
+	} else {
+		b.MoveTo(b.cursor+1, extend)
+	}
+}
+
+// MoveLines moves the cursor up (negative) or down that many lines. The
+// cursor stays as close as it can to where it started moving from
+// vertically, even when it passes through shorter lines.
+func (b *Buffer) MoveLines(count int, extend bool) {
+	goal := b.goalX
+	if !b.hasGoal {
+		goal = b.X(b.cursor)
+	}
+	line := b.LineOf(b.cursor) + count
+	pos := b.PosAt(line, goal)
+	if line < 0 {
+		pos = 0
+	} else if line >= len(b.Lines()) {
+		pos = len(b.text)
+	}
+	b.MoveTo(pos, extend)
+	b.goalX, b.hasGoal = goal, true
+}
+
+func (b *Buffer) MoveUp(extend bool)   { b.MoveLines(-1, extend) }
+func (b *Buffer) MoveDown(extend bool) { b.MoveLines(1, extend) }
+
+// LineStart moves the cursor to the start of the line it is shown on
+func (b *Buffer) LineStart(extend bool) {
+	b.MoveTo(b.Lines()[b.LineOf(b.cursor)].Start, extend)
+}
+
+// LineEnd moves the cursor to the end of the line it is shown on
+func (b *Buffer) LineEnd(extend bool) {
+	b.MoveTo(lineEnd(b.Lines()[b.LineOf(b.cursor)]), extend)
+}
+
+// lineEnd is the last position on the line, for a wrapped line it is before
+// the rune it wrapped after since the position after it is on the next line
+func lineEnd(l Line) int {
+	if l.Wrapped {
+		return l.End - 1
+	}
+	return l.End
+}
+
+func (b *Buffer) wordLeft(pos int) int {
+	for pos > 0 && isWordBreak(b.text[pos-1]) {
+		pos--
+	}
+	for pos > 0 && !isWordBreak(b.text[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+func (b *Buffer) wordRight(pos int) int {
+	for pos < len(b.text) && !isWordBreak(b.text[pos]) {
+		pos++
+	}
+	for pos < len(b.text) && isWordBreak(b.text[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// paragraphStart is the start of the text after the new line before pos
+func (b *Buffer) paragraphStart(pos int) int {
+	for pos > 0 && b.text[pos-1] != '\n' {
+		pos--
+	}
+	return pos
+}
+
+// paragraphEnd is the position of the new line after pos
+func (b *Buffer) paragraphEnd(pos int) int {
+	for pos < len(b.text) && b.text[pos] != '\n' {
+		pos++
+	}
+	return pos
+}
@@ -0,0 +1,217 @@
+/******************************************************************************/
+/* textedit_test.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package textedit
+
+import (
+	"slices"
+	"testing"
+)
+
+func expectText(t *testing.T, b *Buffer, expected string) {
+	t.Helper()
+	if b.Text() != expected {
+		t.Errorf("expected text %q but got %q", expected, b.Text())
+	}
+}
+
+func typeText(b *Buffer, text string) {
+	for _, r := range text {
+		b.Insert(string(r))
+	}
+}
+
+func TestWrap(t *testing.T) {
+	one := func(rune) float32 { return 1 }
+	lines := Wrap([]rune("hello world\nabcdefghij"), 6, one)
+	expected := []Line{
+		{Start: 0, End: 6, Wrapped: true},
+		{Start: 6, End: 11},
+		{Start: 12, End: 18, Wrapped: true},
+		{Start: 18, End: 22},
+	}
+	if !slices.Equal(lines, expected) {
+		t.Errorf("expected lines %v but got %v", expected, lines)
+	}
+	if lines := Wrap([]rune("a\n"), 0, one); len(lines) != 2 || lines[1] != (Line{2, 2, false}) {
+		t.Errorf("a trailing new line should start an empty line, got %v", lines)
+	}
+}
+
+func TestTypingUndoRedo(t *testing.T) {
+	b := New()
+	typeText(b, "hello big world")
+	b.Undo()
+	expectText(t, b, "hello big ")
+	b.Undo()
+	expectText(t, b, "hello ")
+	b.Redo()
+	expectText(t, b, "hello big ")
+	if b.Cursor() != 10 {
+		t.Errorf("redo should put the cursor after the text, got %d", b.Cursor())
+	}
+	b.MoveTo(0, false)
+	typeText(b, "oh")
+	b.Undo()
+	expectText(t, b, "hello big ")
+	if b.CanRedo() != true {
+		t.Error("undo should allow redo")
+	}
+	b.Insert("!")
+	if b.CanRedo() {
+		t.Error("editing should clear the redo history")
+	}
+}
+
+func TestBackspaceDeleteCoalescing(t *testing.T) {
+	b := New()
+	b.SetText("abcdef")
+	b.Backspace(false)
+	b.Backspace(false)
+	expectText(t, b, "abcd")
+	b.MoveTo(1, false)
+	b.Delete(false)
+	b.Delete(false)
+	expectText(t, b, "ad")
+	b.Undo()
+	expectText(t, b, "abcd")
+	b.Undo()
+	expectText(t, b, "abcdef")
+	if b.CanUndo() {
+		t.Error("setting the text should clear the history")
+	}
+	b.MoveTo(4, false)
+	b.Backspace(true)
+	expectText(t, b, "ef")
+}
+
+func TestSelectionAcrossLines(t *testing.T) {
+	b := New()
+	b.SetText("one\ntwo\nthree")
+	b.MoveTo(1, false)
+	b.MoveDown(true)
+	b.MoveDown(true)
+	if b.SelectedText() != "ne\ntwo\nt" {
+		t.Errorf("unexpected selection %q", b.SelectedText())
+	}
+	b.Insert("X")
+	expectText(t, b, "oXhree")
+	b.Undo()
+	expectText(t, b, "one\ntwo\nthree")
+	if b.SelectedText() != "ne\ntwo\nt" {
+		t.Error("undo should restore the selection")
+	}
+}
+
+func TestLineNavigation(t *testing.T) {
+	b := New()
+	b.SetText("a long line\nab\nanother long line")
+	b.MoveTo(9, false)
+	b.MoveDown(false)
+	if b.Cursor() != 14 {
+		t.Errorf("moving down onto a short line should go to its end, got %d", b.Cursor())
+	}
+	b.MoveDown(false)
+	if line, col := b.LineColumn(); line != 2 || col != 9 {
+		t.Errorf("the column should be kept through short lines, got %d:%d", line, col)
+	}
+	b.LineStart(false)
+	if b.Cursor() != 15 {
+		t.Errorf("unexpected line start %d", b.Cursor())
+	}
+	b.MoveRight(true, false)
+	if b.Cursor() != 23 {
+		t.Errorf("unexpected word right %d", b.Cursor())
+	}
+	b.SetWrap(8, nil)
+	b.MoveTo(0, false)
+	b.MoveDown(false)
+	if b.LineOf(b.Cursor()) != 1 || b.Cursor() != 7 {
+		t.Errorf("moving down should follow wrapped lines, got %d", b.Cursor())
+	}
+	b.LineEnd(false)
+	if b.Cursor() != 11 {
+		t.Errorf("unexpected line end %d", b.Cursor())
+	}
+}
+
+func TestTabs(t *testing.T) {
+	b := New()
+	b.Tab(false)
+	expectText(t, b, "\t")
+	b.TabSpaces = 2
+	b.SetText("a\nb\nc")
+	b.Select(0, 3)
+	b.Tab(false)
+	expectText(t, b, "  a\n  b\nc")
+	if b.SelectedText() != "  a\n  b" {
+		t.Errorf("indenting should keep the lines selected, got %q", b.SelectedText())
+	}
+	b.Tab(true)
+	expectText(t, b, "a\nb\nc")
+	b.Undo()
+	expectText(t, b, "  a\n  b\nc")
+}
+
+func TestMaxLength(t *testing.T) {
+	b := New()
+	b.MaxLength = 5
+	b.SetText("abcdefg")
+	expectText(t, b, "abcde")
+	if b.Insert("x") {
+		t.Error("nothing should be inserted when the text is full")
+	}
+	b.Select(1, 3)
+	b.Paste("xyz")
+	expectText(t, b, "axyde")
+}
+
+func TestIndentPastMaxLength(t *testing.T) {
+	b := New()
+	b.MaxLength = 8
+	b.SetText("abc\ndef")
+	b.SelectAll()
+	if b.Tab(false) {
+		t.Error("the lines should not be indented past the max length")
+	}
+	expectText(t, b, "abc\ndef")
+	b.MaxLength = 9
+	if !b.Tab(false) {
+		t.Error("the lines should be indented when they fit")
+	}
+	expectText(t, b, "\tabc\n\tdef")
+}
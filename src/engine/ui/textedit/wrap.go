@@ -0,0 +1,96 @@
+/******************************************************************************/
+/* wrap.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package textedit
+
+import "unicode"
+
+// Line is a line of text as it is shown, the runes in [Start, End). Lines
+// end at a new line, which isn't part of either line, or are wrapped where
+// the text gets wider than the area it is shown in.
+type Line struct {
+	Start, End int
+	// Wrapped is set when the text continues on the next line without a new
+	// line between them
+	Wrapped bool
+}
+
+// Wrap breaks the text into the lines it is shown in. Lines are wrapped
+// after white space where possible, words wider than the width are broken.
+// A width of 0 or less doesn't wrap lines at all.
+func Wrap(text []rune, width float32, measure func(r rune) float32) []Line {
+	lines := make([]Line, 0, 1)
+	for start := 0; start <= len(text); {
+		end := start
+		for end < len(text) && text[end] != '\n' {
+			end++
+		}
+		lines = wrapLine(lines, text, start, end, width, measure)
+		start = end + 1
+	}
+	return lines
+}
+
+func wrapLine(lines []Line, text []rune, start, end int, width float32, measure func(r rune) float32) []Line {
+	for width > 0 {
+		x, brk, i := float32(0), -1, start
+		for ; i < end; i++ {
+			w := measure(text[i])
+			if x+w > width && i > start {
+				break
+			}
+			x += w
+			if unicode.IsSpace(text[i]) {
+				brk = i + 1
+			}
+		}
+		// White space hangs past the edge rather than starting the next line
+		for i < end && unicode.IsSpace(text[i]) {
+			i++
+			brk = i
+		}
+		if i == end {
+			break
+		}
+		if brk <= start {
+			brk = i
+		}
+		lines = append(lines, Line{Start: start, End: brk, Wrapped: true})
+		start = brk
+	}
+	return append(lines, Line{Start: start, End: end})
+}
@@ -0,0 +1,140 @@
+/******************************************************************************/
+/* drop.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package tree
+
+import (
+	"errors"
+	"slices"
+)
+
+// DropPosition is where dragged nodes go relative to the node they are
+// dropped on
+type DropPosition int
+
+const (
+	DropBefore = DropPosition(iota)
+	DropInside
+	DropAfter
+)
+
+// Drop is where dragged nodes would land
+type Drop struct {
+	Target   *Node
+	Position DropPosition
+}
+
+// ErrDropIntoSelf is returned when nodes are dropped onto themselves or into
+// one of their own children
+var ErrDropIntoSelf = errors.New("nodes can't be moved into themselves")
+
+// DropAt finds where nodes dropped on the row land, fraction is how far down
+// the row (0 at the top, 1 at the bottom) they were dropped. The top and
+// bottom quarters of the row drop before and after the node, the middle drops
+// inside it.
+func (t *Tree) DropAt(row int, fraction float32) (Drop, bool) {
+	rows := t.Rows()
+	if row < 0 || row >= len(rows) {
+		return Drop{}, false
+	}
+	drop := Drop{Target: rows[row], Position: DropInside}
+	if fraction < 0.25 {
+		drop.Position = DropBefore
+	} else if fraction > 0.75 {
+		drop.Position = DropAfter
+	}
+	return drop, true
+}
+
+// CanDrop is false if the drop would move a node into itself
+func (t *Tree) CanDrop(nodes []*Node, drop Drop) bool {
+	if drop.Target == nil {
+		return false
+	}
+	for _, n := range nodes {
+		if n == drop.Target || n.IsAncestorOf(drop.Target) {
+			return false
+		}
+	}
+	return true
+}
+
+// Move moves the nodes to where they were dropped, keeping the order they
+// have in the rows. Nodes that are inside other moved nodes go with them.
+func (t *Tree) Move(nodes []*Node, drop Drop) error {
+	if !t.CanDrop(nodes, drop) {
+		return ErrDropIntoSelf
+	}
+	moving := make([]*Node, 0, len(nodes))
+	for _, n := range nodes {
+		inside := slices.ContainsFunc(nodes, func(other *Node) bool {
+			return other.IsAncestorOf(n)
+		})
+		if !inside && !slices.Contains(moving, n) {
+			moving = append(moving, n)
+		}
+	}
+	rows := t.Rows()
+	slices.SortStableFunc(moving, func(a, b *Node) int {
+		return slices.Index(rows, a) - slices.Index(rows, b)
+	})
+	for _, n := range moving {
+		n.detach()
+	}
+	target := drop.Target
+	parent, index := target, len(target.children)
+	switch drop.Position {
+	case DropBefore:
+		parent = target.parent
+		index = slices.Index(parent.children, target)
+	case DropAfter:
+		parent = target.parent
+		index = slices.Index(parent.children, target) + 1
+	case DropInside:
+		// Lazy children are loaded first so the nodes are added after them
+		t.Expand(target)
+		index = len(target.children)
+	}
+	for i, n := range moving {
+		parent.Insert(n, index+i)
+	}
+	if drop.Position == DropInside {
+		target.expanded = true
+	}
+	t.Changed()
+	return nil
+}
@@ -0,0 +1,96 @@
+/******************************************************************************/
+/* navigation.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package tree
+
+// Key is a key that moves the focus through the rows of the tree
+type Key int
+
+const (
+	KeyUp = Key(iota)
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+)
+
+// Navigate moves the focus the way the key does in a tree view and selects
+// the focused node, shift extends the selection to it. Left collapses the
+// focused node or moves to its parent, right expands it or moves to its
+// first child. Returns the focused node, nil if the tree has no rows.
+func (t *Tree) Navigate(key Key, shift bool) *Node {
+	rows := t.Rows()
+	if len(rows) == 0 {
+		return nil
+	}
+	row := t.RowOf(t.focus)
+	if row < 0 {
+		t.Click(rows[0], false, false)
+		return t.focus
+	}
+	node := t.focus
+	next := node
+	switch key {
+	case KeyUp:
+		next = rows[max(row-1, 0)]
+	case KeyDown:
+		next = rows[min(row+1, len(rows)-1)]
+	case KeyHome:
+		next = rows[0]
+	case KeyEnd:
+		next = rows[len(rows)-1]
+	case KeyLeft:
+		if node.expanded {
+			t.Collapse(node)
+			return node
+		} else if node.parent != t.root {
+			next = node.parent
+		}
+	case KeyRight:
+		if !node.expanded && node.CanExpand() {
+			t.Expand(node)
+			return node
+		} else if node.expanded && len(node.children) > 0 {
+			next = node.children[0]
+		}
+	}
+	if next != node {
+		t.Click(next, false, shift)
+	}
+	return t.focus
+}
@@ -0,0 +1,308 @@
+/******************************************************************************/
+/* tree.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+// Package tree is the model behind #ui.TreeView: the nodes, which of them are
+// expanded, the rows the expanded nodes flatten into, the selection and where
+// dragged nodes are dropped. It has no UI so that it can be tested on its own.
+package tree
+
+import "slices"
+
+// Node is a node of the tree. A node that loads its children lazily sets
+// HasChildren so it can be expanded before they are loaded.
+type Node struct {
+	Label       string
+	Data        any
+	HasChildren bool
+	parent      *Node
+	children    []*Node
+	expanded    bool
+	loaded      bool
+}
+
+// NewNode creates a node that is not yet in a tree
+func NewNode(label string, data any) *Node {
+	return &Node{Label: label, Data: data}
+}
+
+func (n *Node) Parent() *Node     { return n.parent }
+func (n *Node) Children() []*Node { return n.children }
+func (n *Node) IsExpanded() bool  { return n.expanded }
+
+// CanExpand is true if the node has children, or will once they are loaded
+func (n *Node) CanExpand() bool {
+	return len(n.children) > 0 || (n.HasChildren && !n.loaded)
+}
+
+// Add appends the children to the node
+func (n *Node) Add(children ...*Node) {
+	for _, c := range children {
+		c.detach()
+		c.parent = n
+		n.children = append(n.children, c)
+	}
+}
+
+// Insert puts the child at index in the children of the node
+func (n *Node) Insert(child *Node, index int) {
+	child.detach()
+	child.parent = n
+	index = min(max(index, 0), len(n.children))
+	n.children = slices.Insert(n.children, index, child)
+}
+
+// Remove takes the node out of its parent
+func (n *Node) Remove() { n.detach() }
+
+// Clear removes all the children of the node, a lazily loaded node will load
+// them again the next time it is expanded
+func (n *Node) Clear() {
+	for _, c := range n.children {
+		c.parent = nil
+	}
+	n.children = nil
+	n.loaded = false
+}
+
+// Depth is how many parents the node has below the root of the tree
+func (n *Node) Depth() int {
+	depth := -1
+	for p := n.parent; p != nil; p = p.parent {
+		depth++
+	}
+	return depth
+}
+
+// IsAncestorOf is true if the other node is somewhere below this node
+func (n *Node) IsAncestorOf(other *Node) bool {
+	for p := other.parent; p != nil; p = p.parent {
+		if p == n {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Node) detach() {
+	if n.parent == nil {
+		return
+	}
+	if i := slices.Index(n.parent.children, n); i >= 0 {
+		n.parent.children = slices.Delete(n.parent.children, i, i+1)
+	}
+	n.parent = nil
+}
+
+// Tree holds the root of the nodes and the rows they are shown in. The root
+// itself isn't shown, its children are the top rows.
+type Tree struct {
+	root     *Node
+	rows     []*Node
+	load     func(node *Node)
+	selected map[*Node]struct{}
+	anchor   *Node
+	focus    *Node
+	Multiple bool
+	stale    bool
+}
+
+// New creates an empty tree, load is called the first time a node that has
+// HasChildren set is expanded so it can add its children (it can be nil)
+func New(load func(node *Node)) *Tree {
+	return &Tree{
+		root:     &Node{expanded: true, loaded: true},
+		load:     load,
+		selected: make(map[*Node]struct{}),
+		stale:    true,
+	}
+}
+
+// Root is the hidden node that the top nodes of the tree are added to
+func (t *Tree) Root() *Node { return t.root }
+
+// Changed is to be called after nodes were added, removed or moved outside of
+// the tree so the rows are built again
+func (t *Tree) Changed() {
+	t.stale = true
+	for n := range t.selected {
+		if !t.contains(n) {
+			delete(t.selected, n)
+		}
+	}
+	if t.anchor != nil && !t.contains(t.anchor) {
+		t.anchor = nil
+	}
+	if t.focus != nil && !t.contains(t.focus) {
+		t.focus = nil
+	}
+}
+
+// Rows are the nodes that can be seen, in the order they are shown
+func (t *Tree) Rows() []*Node {
+	if t.stale {
+		t.rows = t.rows[:0]
+		t.flatten(t.root)
+		t.stale = false
+	}
+	return t.rows
+}
+
+// RowOf is the row the node is shown in, or -1 if a parent is collapsed
+func (t *Tree) RowOf(node *Node) int {
+	return slices.Index(t.Rows(), node)
+}
+
+func (t *Tree) Expand(node *Node) {
+	if node.expanded || !node.CanExpand() {
+		return
+	}
+	if node.HasChildren && !node.loaded {
+		node.loaded = true
+		if t.load != nil {
+			t.load(node)
+		}
+	}
+	node.expanded = true
+	t.stale = true
+}
+
+func (t *Tree) Collapse(node *Node) {
+	if !node.expanded || node == t.root {
+		return
+	}
+	node.expanded = false
+	t.stale = true
+}
+
+func (t *Tree) Toggle(node *Node) {
+	if node.expanded {
+		t.Collapse(node)
+	} else {
+		t.Expand(node)
+	}
+}
+
+// Reveal expands the parents of the node so that it has a row
+func (t *Tree) Reveal(node *Node) {
+	for p := node.parent; p != nil && p != t.root; p = p.parent {
+		t.Expand(p)
+	}
+}
+
+func (t *Tree) IsSelected(node *Node) bool {
+	_, ok := t.selected[node]
+	return ok
+}
+
+// Selected are the selected nodes in the order of their rows, selected nodes
+// in collapsed parents come last
+func (t *Tree) Selected() []*Node {
+	out := make([]*Node, 0, len(t.selected))
+	for _, n := range t.Rows() {
+		if t.IsSelected(n) {
+			out = append(out, n)
+		}
+	}
+	for n := range t.selected {
+		if !slices.Contains(out, n) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// Focused is the node that was last clicked or moved to with the keyboard
+func (t *Tree) Focused() *Node { return t.focus }
+
+// Click selects the node the way a click on its row does, ctrl toggles it and
+// shift selects the rows from the last node clicked without shift when
+// Multiple is set. A node without a visible row can't be the end of a range
+// so shift only selects it.
+func (t *Tree) Click(node *Node, ctrl, shift bool) {
+	t.focus = node
+	ranged := shift && t.anchor != nil && t.RowOf(node) >= 0
+	if !t.Multiple || (!ctrl && !shift) || (shift && !ranged) {
+		clear(t.selected)
+		t.selected[node] = struct{}{}
+		t.anchor = node
+		return
+	}
+	if shift {
+		if !ctrl {
+			clear(t.selected)
+		}
+		from, to := t.RowOf(t.anchor), t.RowOf(node)
+		if from < 0 {
+			from = to
+		}
+		for i := min(from, to); i <= max(from, to); i++ {
+			t.selected[t.rows[i]] = struct{}{}
+		}
+		return
+	}
+	if t.IsSelected(node) {
+		delete(t.selected, node)
+	} else {
+		t.selected[node] = struct{}{}
+	}
+	t.anchor = node
+}
+
+func (t *Tree) ClearSelection() {
+	clear(t.selected)
+	t.anchor = nil
+	t.focus = nil
+}
+
+func (t *Tree) flatten(node *Node) {
+	if !node.expanded {
+		return
+	}
+	for _, c := range node.children {
+		t.rows = append(t.rows, c)
+		t.flatten(c)
+	}
+}
+
+func (t *Tree) contains(node *Node) bool {
+	for p := node; p != nil; p = p.parent {
+		if p == t.root {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+/******************************************************************************/
+/* tree_test.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package tree
+
+import (
+	"slices"
+	"testing"
+)
+
+func labels(nodes []*Node) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.Label
+	}
+	return out
+}
+
+func expectRows(t *testing.T, tr *Tree, expected ...string) {
+	t.Helper()
+	if got := labels(tr.Rows()); !slices.Equal(got, expected) {
+		t.Errorf("expected rows %v but got %v", expected, got)
+	}
+}
+
+// sample builds the tree
+//
+//	a
+//	  a1
+//	  a2
+//	b
+//	c (lazy)
+func sample() (*Tree, map[string]*Node) {
+	loads := 0
+	tr := New(func(n *Node) {
+		loads++
+		n.Add(NewNode(n.Label+"1", loads))
+	})
+	nodes := map[string]*Node{}
+	for _, l := range []string{"a", "a1", "a2", "b", "c"} {
+		nodes[l] = NewNode(l, nil)
+	}
+	tr.Root().Add(nodes["a"], nodes["b"], nodes["c"])
+	nodes["a"].Add(nodes["a1"], nodes["a2"])
+	nodes["c"].HasChildren = true
+	tr.Changed()
+	return tr, nodes
+}
+
+func TestExpandCollapse(t *testing.T) {
+	tr, n := sample()
+	expectRows(t, tr, "a", "b", "c")
+	tr.Expand(n["a"])
+	expectRows(t, tr, "a", "a1", "a2", "b", "c")
+	tr.Collapse(n["a"])
+	expectRows(t, tr, "a", "b", "c")
+	if n["b"].CanExpand() || !n["c"].CanExpand() {
+		t.Error("only nodes with children (or lazy ones) can be expanded")
+	}
+	if n["a1"].Depth() != 1 || n["a"].Depth() != 0 {
+		t.Error("unexpected depth")
+	}
+}
+
+func TestLazyLoading(t *testing.T) {
+	tr, n := sample()
+	tr.Expand(n["c"])
+	expectRows(t, tr, "a", "b", "c", "c1")
+	tr.Collapse(n["c"])
+	tr.Expand(n["c"])
+	if len(n["c"].Children()) != 1 {
+		t.Error("the children should only be loaded once")
+	}
+	n["c"].Clear()
+	tr.Collapse(n["c"])
+	tr.Expand(n["c"])
+	if len(n["c"].Children()) != 1 || n["c"].Children()[0].Data != 2 {
+		t.Error("clearing the node should load the children again")
+	}
+}
+
+func TestMultiSelect(t *testing.T) {
+	tr, n := sample()
+	tr.Expand(n["a"])
+	tr.Click(n["a1"], false, false)
+	tr.Click(n["b"], true, false)
+	if got := labels(tr.Selected()); !slices.Equal(got, []string{"b"}) {
+		t.Errorf("single selection should keep the last node, got %v", got)
+	}
+	tr.Multiple = true
+	tr.Click(n["a1"], false, false)
+	tr.Click(n["b"], false, true)
+	if got := labels(tr.Selected()); !slices.Equal(got, []string{"a1", "a2", "b"}) {
+		t.Errorf("shift should select the range, got %v", got)
+	}
+	tr.Click(n["a2"], true, false)
+	if got := labels(tr.Selected()); !slices.Equal(got, []string{"a1", "b"}) {
+		t.Errorf("ctrl should toggle the node, got %v", got)
+	}
+	n["b"].Remove()
+	tr.Changed()
+	if tr.IsSelected(n["b"]) {
+		t.Error("removed nodes should be deselected")
+	}
+	tr.Collapse(n["a"])
+	tr.Click(n["a2"], false, true)
+	if got := labels(tr.Selected()); !slices.Equal(got, []string{"a2"}) {
+		t.Errorf("shift on a hidden node should only select it, got %v", got)
+	}
+}
+
+func TestNavigate(t *testing.T) {
+	tr, n := sample()
+	if tr.Navigate(KeyDown, false) != n["a"] {
+		t.Fatal("the first key should focus the first row")
+	}
+	tr.Navigate(KeyRight, false)
+	if !n["a"].IsExpanded() {
+		t.Error("right should expand the node")
+	}
+	if tr.Navigate(KeyRight, false) != n["a1"] {
+		t.Error("right on an expanded node should move to its first child")
+	}
+	if tr.Navigate(KeyDown, false) != n["a2"] {
+		t.Error("down should move to the next row")
+	}
+	if tr.Navigate(KeyLeft, false) != n["a"] {
+		t.Error("left on a leaf should move to its parent")
+	}
+	tr.Navigate(KeyLeft, false)
+	if n["a"].IsExpanded() {
+		t.Error("left on an expanded node should collapse it")
+	}
+	if tr.Navigate(KeyEnd, false) != n["c"] || !tr.IsSelected(n["c"]) {
+		t.Error("end should focus and select the last row")
+	}
+}
+
+func TestDrop(t *testing.T) {
+	tr, n := sample()
+	tr.Expand(n["a"])
+	drop, _ := tr.DropAt(tr.RowOf(n["b"]), 0.1)
+	if drop.Target != n["b"] || drop.Position != DropBefore {
+		t.Errorf("unexpected drop %v", drop)
+	}
+	if err := tr.Move([]*Node{n["a2"], n["a1"]}, drop); err != nil {
+		t.Fatal(err)
+	}
+	expectRows(t, tr, "a", "a1", "a2", "b", "c")
+	if n["a1"].Parent() != tr.Root() {
+		t.Error("the nodes should have moved to the top")
+	}
+	drop, _ = tr.DropAt(tr.RowOf(n["b"]), 0.5)
+	if err := tr.Move([]*Node{n["a"], n["a1"]}, drop); err != nil {
+		t.Fatal(err)
+	}
+	expectRows(t, tr, "a2", "b", "a", "a1", "c")
+	if err := tr.Move([]*Node{n["b"]}, Drop{Target: n["a"], Position: DropAfter}); err != ErrDropIntoSelf {
+		t.Error("a node shouldn't be dropped into its own children")
+	}
+	tr.Move([]*Node{n["a2"]}, Drop{Target: n["c"], Position: DropInside})
+	expectRows(t, tr, "b", "a", "a1", "c", "c1", "a2")
+}
@@ -0,0 +1,395 @@
+/******************************************************************************/
+/* tree_view.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package ui
+
+import (
+	"kaiju/engine/systems/events"
+	"kaiju/engine/ui/tree"
+	"kaiju/matrix"
+	"kaiju/platform/hid"
+	"kaiju/rendering"
+)
+
+const treeViewIndicatorSize = 2
+
+type treeViewData struct {
+	panelData
+	tree          *tree.Tree
+	list          *ListView
+	rowHeight     float32
+	indent        float32
+	hasFocus      bool
+	keyId         int
+	selectedColor matrix.Color
+	dropColor     matrix.Color
+	indicator     *Panel
+	canDrag       bool
+	dropFilter    func(nodes []*tree.Node, drop tree.Drop) bool
+	dragging      []*tree.Node
+	drop          tree.Drop
+	canDrop       bool
+	dragUpdate    int
+	onMove        events.Event
+}
+
+func (t *treeViewData) innerPanelData() *panelData { return &t.panelData }
+
+// TreeView shows the nodes of a #tree.Tree as rows that can be expanded and
+// collapsed. It is built on #ListView so only the rows that can be seen have
+// panels. Selecting rows fires #EventTypeChange, double clicking a row or
+// pressing enter fires #EventTypeSubmit.
+type TreeView Panel
+
+func (u *UI) ToTreeView() *TreeView { return (*TreeView)(u) }
+func (t *TreeView) Base() *UI       { return (*UI)(t) }
+
+func (t *TreeView) TreeViewData() *treeViewData {
+	return t.elmData.(*treeViewData)
+}
+
+// Init sets up the tree view, load is called the first time a node that has
+// HasChildren set is expanded so its children can be added (it can be nil)
+func (t *TreeView) Init(rowHeight float32, load func(node *tree.Node), anchor Anchor) {
+	td := &treeViewData{
+		tree:          tree.New(load),
+		rowHeight:     max(rowHeight, 1),
+		indent:        max(rowHeight, 1),
+		selectedColor: matrix.ColorDarkBlue(),
+		dropColor:     matrix.ColorOrange(),
+	}
+	t.elmData = td
+	p := (*Panel)(t)
+	p.Init(nil, anchor, ElementTypePanel)
+	p.DontFitContent()
+	p.SetScrollDirection(PanelScrollDirectionNone)
+	td.list = t.man.Add().ToListView()
+	td.list.Init(ListViewSource{
+		Count:     func() int { return len(td.tree.Rows()) },
+		CreateRow: t.createRow,
+		BindRow:   t.bindRow,
+		RowHeight: func(int) float32 { return td.rowHeight },
+		Click:     t.rowClicked,
+	}, td.rowHeight, AnchorTopLeft)
+	td.list.layout.AddFunction(func(l *Layout) {
+		l.Scale(p.layout.ContentSize())
+	})
+	p.AddChild(td.list.Base())
+	td.indicator = t.man.Add().ToPanel()
+	td.indicator.Init(nil, AnchorTopLeft, ElementTypePanel)
+	td.indicator.DontFitContent()
+	td.indicator.layout.SetPositioning(PositioningAbsolute)
+	td.indicator.layout.SetZ(0.1)
+	td.indicator.SetColor(td.dropColor)
+	p.AddChild(td.indicator.Base())
+	td.indicator.entity.Deactivate()
+	base := t.Base()
+	base.AddEvent(EventTypeDown, func() { td.hasFocus = true })
+	base.AddEvent(EventTypeMiss, func() { td.hasFocus = false })
+	td.keyId = t.man.Host.Window.Keyboard.AddKeyCallback(t.keyPressed)
+	base.AddEvent(EventTypeDestroy, func() {
+		t.man.Host.Window.Keyboard.RemoveKeyCallback(td.keyId)
+		if td.dragUpdate != 0 {
+			t.man.Host.UIUpdater.RemoveUpdate(td.dragUpdate)
+		}
+	})
+}
+
+// Tree is the model of the nodes, call #TreeView.Refresh after nodes were
+// added, removed or moved through it
+func (t *TreeView) Tree() *tree.Tree { return t.TreeViewData().tree }
+
+// List is the list of the rows of the tree
+func (t *TreeView) List() *ListView { return t.TreeViewData().list }
+
+// Refresh updates the rows after the nodes of the tree were changed
+func (t *TreeView) Refresh() {
+	td := t.TreeViewData()
+	td.tree.Changed()
+	td.list.Refresh()
+}
+
+func (t *TreeView) SetMultiSelect(multiple bool) { t.TreeViewData().tree.Multiple = multiple }
+
+// SetIndent sets how far each level of the tree is pushed to the right
+func (t *TreeView) SetIndent(indent float32) {
+	t.TreeViewData().indent = max(indent, 0)
+	t.TreeViewData().list.Refresh()
+}
+
+func (t *TreeView) SetSelectedColor(color matrix.Color) {
+	t.TreeViewData().selectedColor = color
+	t.TreeViewData().list.Refresh()
+}
+
+// EnableDrag lets the selected nodes be dragged onto other nodes to move them
+// there, filter can refuse drops (it can be nil)
+func (t *TreeView) EnableDrag(filter func(nodes []*tree.Node, drop tree.Drop) bool) {
+	td := t.TreeViewData()
+	td.canDrag = true
+	td.dropFilter = filter
+}
+
+func (t *TreeView) DisableDrag() { t.TreeViewData().canDrag = false }
+
+// OnMove is called after dragged nodes were dropped and moved
+func (t *TreeView) OnMove() *events.Event { return &t.TreeViewData().onMove }
+
+// Selected are the selected nodes in the order of their rows
+func (t *TreeView) Selected() []*tree.Node { return t.TreeViewData().tree.Selected() }
+
+// Select makes the node the only selected one, its parents are expanded and
+// the list is scrolled to it
+func (t *TreeView) Select(node *tree.Node) {
+	td := t.TreeViewData()
+	td.tree.Reveal(node)
+	td.tree.Click(node, false, false)
+	t.selectionChanged()
+}
+
+func (t *TreeView) Expand(node *tree.Node) {
+	t.TreeViewData().tree.Expand(node)
+	t.TreeViewData().list.Refresh()
+}
+
+func (t *TreeView) Collapse(node *tree.Node) {
+	t.TreeViewData().tree.Collapse(node)
+	t.TreeViewData().list.Refresh()
+}
+
+func (t *TreeView) selectionChanged() {
+	td := t.TreeViewData()
+	td.list.Refresh()
+	if row := td.tree.RowOf(td.tree.Focused()); row >= 0 {
+		td.list.ScrollIntoView(row)
+	}
+	t.Base().ExecuteEvent(EventTypeChange)
+}
+
+func (t *TreeView) createRow(man *Manager) *Panel {
+	row := man.Add().ToPanel()
+	row.Init(nil, AnchorTopLeft, ElementTypePanel)
+	row.SetScrollDirection(PanelScrollDirectionNone)
+	for range 2 {
+		label := man.Add().ToLabel()
+		label.Init("", AnchorTopLeft)
+		label.SetBaseline(rendering.FontBaselineCenter)
+		label.SetBGColor(matrix.ColorTransparent())
+		row.AddChild(label.Base())
+	}
+	row.Base().AddEvent(EventTypeDoubleClick, func() { t.rowDoubleClicked(row) })
+	row.Base().AddEvent(EventTypeDragStart, func() { t.startDrag(row) })
+	return row
+}
+
+func (t *TreeView) bindRow(row *Panel, index int, _ bool) {
+	td := t.TreeViewData()
+	node := td.tree.Rows()[index]
+	depth := float32(node.Depth())
+	row.layout.SetPadding(depth*td.indent, 0, 0, 0)
+	toggle := FirstOnEntity(row.entity.Children[0]).ToLabel()
+	switch {
+	case !node.CanExpand():
+		toggle.SetText(" ")
+	case node.IsExpanded():
+		toggle.SetText("-")
+	default:
+		toggle.SetText("+")
+	}
+	toggle.SetMaxWidth(td.indent)
+	FirstOnEntity(row.entity.Children[1]).ToLabel().SetText(node.Label)
+	if td.tree.IsSelected(node) {
+		row.SetColor(td.selectedColor)
+	} else {
+		row.SetColor(matrix.ColorTransparent())
+	}
+}
+
+// rowNode is the node the row is showing
+func (t *TreeView) rowNode(row *Panel) (*tree.Node, bool) {
+	td := t.TreeViewData()
+	index, ok := td.list.ListViewData().shown[row]
+	if !ok || index >= len(td.tree.Rows()) {
+		return nil, false
+	}
+	return td.tree.Rows()[index], true
+}
+
+func (t *TreeView) rowClicked(row *Panel, index int) {
+	td := t.TreeViewData()
+	node := td.tree.Rows()[index]
+	// Clicking left of the label, on the toggle, expands the node
+	pos := row.Base().cursorPos(&t.man.Host.Window.Cursor)
+	left := row.entity.Transform.WorldPosition().X() - row.entity.Transform.WorldScale().X()*0.5
+	if x := pos.X() - left - row.layout.padding.Left(); x >= 0 && x < td.indent && node.CanExpand() {
+		t.toggle(node)
+		return
+	}
+	kb := &t.man.Host.Window.Keyboard
+	td.tree.Click(node, kb.HasCtrl(), kb.HasShift())
+	t.selectionChanged()
+}
+
+func (t *TreeView) rowDoubleClicked(row *Panel) {
+	if node, ok := t.rowNode(row); ok {
+		t.toggle(node)
+		t.Base().ExecuteEvent(EventTypeSubmit)
+	}
+}
+
+func (t *TreeView) toggle(node *tree.Node) {
+	td := t.TreeViewData()
+	td.tree.Toggle(node)
+	td.list.Refresh()
+}
+
+func (t *TreeView) keyPressed(keyId int, keyState hid.KeyState) {
+	td := t.TreeViewData()
+	if !td.hasFocus || keyState != hid.KeyStateDown || !t.entity.IsActive() {
+		return
+	}
+	var key tree.Key
+	switch keyId {
+	case hid.KeyboardKeyUp:
+		key = tree.KeyUp
+	case hid.KeyboardKeyDown:
+		key = tree.KeyDown
+	case hid.KeyboardKeyLeft:
+		key = tree.KeyLeft
+	case hid.KeyboardKeyRight:
+		key = tree.KeyRight
+	case hid.KeyboardKeyHome:
+		key = tree.KeyHome
+	case hid.KeyboardKeyEnd:
+		key = tree.KeyEnd
+	case hid.KeyboardKeySpace:
+		if node := td.tree.Focused(); node != nil {
+			t.toggle(node)
+		}
+		return
+	case hid.KeyboardKeyReturn, hid.KeyboardKeyEnter:
+		if td.tree.Focused() != nil {
+			t.Base().ExecuteEvent(EventTypeSubmit)
+		}
+		return
+	default:
+		return
+	}
+	before := td.tree.Focused()
+	td.tree.Navigate(key, t.man.Host.Window.Keyboard.HasShift())
+	if td.tree.Focused() != before {
+		t.selectionChanged()
+	} else {
+		td.list.Refresh()
+	}
+}
+
+func (t *TreeView) startDrag(row *Panel) {
+	td := t.TreeViewData()
+	node, ok := t.rowNode(row)
+	if !td.canDrag || !ok {
+		return
+	}
+	if !td.tree.IsSelected(node) {
+		td.tree.Click(node, false, false)
+		t.selectionChanged()
+	}
+	td.dragging = td.tree.Selected()
+	if td.dragUpdate == 0 {
+		td.dragUpdate = t.man.Host.UIUpdater.AddUpdate(t.dragUpdate)
+	}
+}
+
+// dragUpdate shows where the dragged nodes would be dropped and moves them
+// there once the cursor is released
+func (t *TreeView) dragUpdate(float64) {
+	td := t.TreeViewData()
+	if !t.man.Host.Window.Cursor.Held() {
+		t.man.Host.UIUpdater.RemoveUpdate(td.dragUpdate)
+		td.dragUpdate = 0
+		td.indicator.entity.Deactivate()
+		if td.canDrop && td.tree.Move(td.dragging, td.drop) == nil {
+			t.Refresh()
+			td.onMove.Execute()
+		}
+		td.dragging = nil
+		td.canDrop = false
+		return
+	}
+	td.canDrop = false
+	index, fraction, ok := td.list.RowAtCursor()
+	if ok {
+		td.drop, ok = td.tree.DropAt(index, fraction)
+	}
+	ok = ok && td.tree.CanDrop(td.dragging, td.drop) &&
+		(td.dropFilter == nil || td.dropFilter(td.dragging, td.drop))
+	row, shown := td.list.Row(index)
+	if !ok || !shown {
+		td.indicator.entity.Deactivate()
+		return
+	}
+	td.canDrop = true
+	t.placeIndicator(row)
+}
+
+// placeIndicator moves the drop indicator onto the row, a line above or below
+// it for dropping next to the node and the whole row for dropping inside it
+func (t *TreeView) placeIndicator(row *Panel) {
+	td := t.TreeViewData()
+	ind := td.indicator
+	if ind.entity.Parent != &row.entity {
+		row.AddChild(ind.Base())
+	}
+	w := row.layout.PixelSize().Width() - row.layout.padding.Left()
+	color := td.dropColor
+	switch td.drop.Position {
+	case tree.DropBefore:
+		ind.layout.SetInnerOffsetTop(0)
+		ind.layout.Scale(w, treeViewIndicatorSize)
+	case tree.DropAfter:
+		ind.layout.SetInnerOffsetTop(td.rowHeight - treeViewIndicatorSize)
+		ind.layout.Scale(w, treeViewIndicatorSize)
+	case tree.DropInside:
+		ind.layout.SetInnerOffsetTop(0)
+		ind.layout.Scale(w, td.rowHeight)
+		color.SetA(0.35)
+	}
+	ind.SetColor(color)
+	if !ind.entity.IsActive() {
+		ind.entity.Activate()
+	}
+}
@@ -78,6 +78,7 @@ const (
 	ElementTypeProgressBar
 	ElementTypeSelect
 	ElementTypeSlider
+	ElementTypeTextArea
 )
 
 type UIElementData interface {
@@ -100,6 +101,8 @@ type UI struct {
 	shaderData       *ShaderData
 	textureSize      matrix.Vec2
 	lastClick        float64
+	tabIndex         int
+	lastKey          hid.KeyboardKey
 	poolId           pooling.PoolGroupId
 	id               pooling.PoolIndex
 	hovering         bool
@@ -109,6 +112,7 @@ type UI struct {
 	drag             bool
 	lastActive       bool
 	dontClean        bool
+	focusable        bool
 }
 
 func (ui *UI) isActive() bool { return ui.entity.IsActive() }
@@ -135,6 +139,7 @@ func (ui *UI) init(textureSize matrix.Vec2, anchor Anchor) {
 		ui.man.Host.Window.OnResize.Remove(rzId)
 		ui.shaderData.Destroy()
 		ui.events[EventTypeDestroy].Execute()
+		ui.SetFocusable(false)
 		ui.elmData = nil
 		ui.man.Remove(ui)
 	})
@@ -152,6 +157,12 @@ func (ui *UI) Type() ElementType               { return ui.elmType }
 
 func (ui *UI) SetDontClean(val bool) { ui.dontClean = val }
 
+// IsHovered returns true while the cursor is over the element
+func (ui *UI) IsHovered() bool { return ui.hovering }
+
+// IsDown returns true while the element is being pressed
+func (ui *UI) IsDown() bool { return ui.isDown }
+
 func (ui *UI) ExecuteEvent(evtType EventType) bool {
 	defer tracing.NewRegion("UI::ExecuteEvent").End()
 	ui.events[evtType].Execute()
@@ -299,6 +310,33 @@ func (ui *UI) setScissorInternal(scissor matrix.Vec4) {
 	}
 }
 
+// requestKeyEvent requests the key down or up event, the handlers of the
+// event can read the key from #UI.LastKey
+func (ui *UI) requestKeyEvent(evtType EventType, keyId hid.KeyboardKey) {
+	if ui.events[evtType].IsEmpty() {
+		return
+	}
+	if ui.group != nil {
+		ui.group.requestKeyEvent(ui, evtType, keyId)
+	} else {
+		ui.lastKey = keyId
+		ui.ExecuteEvent(evtType)
+	}
+}
+
+// LastKey is the key of the key down or key up event of the element that is
+// being executed
+func (ui *UI) LastKey() hid.KeyboardKey { return ui.lastKey }
+
+// PreventDefault stops the group from doing what it would normally do with
+// the input of this frame, like moving the focus when tab is pressed. It is
+// meant to be called by the handlers of the events of the element.
+func (ui *UI) PreventDefault() {
+	if ui.group != nil {
+		ui.group.prevented = true
+	}
+}
+
 func (ui *UI) requestEvent(evtType EventType) {
 	if ui.events[evtType].IsEmpty() {
 		return
@@ -426,6 +464,7 @@ func (ui *UI) containedCheck(cursor *hid.Cursor, entity *engine.Entity) {
 }
 
 func (ui *UI) changed() {
+	ui.ExecuteEvent(EventTypeInput)
 	ui.ExecuteEvent(EventTypeChange)
 }
 
@@ -475,6 +514,8 @@ func (ui *UI) updateFromManager(deltaTime float64) {
 		ui.ToImage().update(deltaTime)
 	case ElementTypeCheckbox:
 		ui.ToPanel().update(deltaTime)
+	case ElementTypeTextArea:
+		ui.ToPanel().update(deltaTime)
 	}
 }
 
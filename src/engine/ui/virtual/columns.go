@@ -0,0 +1,111 @@
+/******************************************************************************/
+/* columns.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package virtual
+
+import "sort"
+
+// Column is the size of a column of a data grid
+type Column struct {
+	Width    float32
+	MinWidth float32
+}
+
+// Offsets are the distances from the left of the grid to the left of each
+// column, with the total width of the columns at the end
+func Offsets(columns []Column) []float32 {
+	offsets := make([]float32, len(columns)+1)
+	for i, c := range columns {
+		offsets[i+1] = offsets[i] + c.Width
+	}
+	return offsets
+}
+
+// Resize changes the width of the column by delta, it doesn't go below the
+// min width of the column. Returns the amount the column actually changed.
+func Resize(columns []Column, index int, delta float32) float32 {
+	if index < 0 || index >= len(columns) {
+		return 0
+	}
+	c := &columns[index]
+	width := max(c.Width+delta, c.MinWidth, 1)
+	delta = width - c.Width
+	c.Width = width
+	return delta
+}
+
+// Sort is the order of the rows of a grid sorted by a column, Column is -1
+// when the rows aren't sorted
+type Sort struct {
+	Column     int
+	Descending bool
+}
+
+// Unsorted is the order of the rows when no column is sorted
+var Unsorted = Sort{Column: -1}
+
+// Toggle is the sort after the header of the column was clicked, a column is
+// first sorted ascending, then descending and then no longer sorted
+func (s Sort) Toggle(column int) Sort {
+	switch {
+	case s.Column != column:
+		return Sort{Column: column}
+	case !s.Descending:
+		return Sort{Column: column, Descending: true}
+	}
+	return Unsorted
+}
+
+// Order gives the order of count rows with less comparing the rows at the two
+// indexes. Rows that compare equal keep their order and descending reverses
+// the order of the rest. order[i] is the index of the row shown at i.
+func Order(count int, less func(a, b int) bool, descending bool) []int {
+	order := make([]int, count)
+	for i := range order {
+		order[i] = i
+	}
+	if less == nil {
+		return order
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if descending {
+			return less(order[j], order[i])
+		}
+		return less(order[i], order[j])
+	})
+	return order
+}
@@ -0,0 +1,135 @@
+/******************************************************************************/
+/* recycler.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package virtual
+
+import (
+	"maps"
+	"slices"
+)
+
+// Recycler keeps the elements that show the rows of a list. Only the visible
+// rows have an element, when rows scroll out of view their elements are given
+// to the rows that scroll into it rather than making new ones.
+type Recycler[T any] struct {
+	active map[int]T
+	free   []T
+	create func() T
+	bind   func(item T, index int)
+}
+
+// NewRecycler creates a recycler that makes elements with create and fills an
+// element for the row at index with bind
+func NewRecycler[T any](create func() T, bind func(item T, index int)) *Recycler[T] {
+	return &Recycler[T]{
+		active: make(map[int]T),
+		create: create,
+		bind:   bind,
+	}
+}
+
+// Show makes the rows in [first, last) the ones that have elements, the rows
+// that left the range give up their elements first so they can be reused.
+// Returns true if any row was given an element or gave one up.
+func (r *Recycler[T]) Show(first, last int) bool {
+	changed := false
+	for idx, item := range r.active {
+		if idx < first || idx >= last {
+			delete(r.active, idx)
+			r.free = append(r.free, item)
+			changed = true
+		}
+	}
+	for idx := first; idx < last; idx++ {
+		if _, ok := r.active[idx]; ok {
+			continue
+		}
+		var item T
+		if len(r.free) > 0 {
+			item = r.free[len(r.free)-1]
+			r.free = r.free[:len(r.free)-1]
+		} else {
+			item = r.create()
+		}
+		r.active[idx] = item
+		r.bind(item, idx)
+		changed = true
+	}
+	return changed
+}
+
+// Item returns the element showing the row at index, if it has one
+func (r *Recycler[T]) Item(index int) (T, bool) {
+	item, ok := r.active[index]
+	return item, ok
+}
+
+// Indices are the rows that have elements, in order
+func (r *Recycler[T]) Indices() []int {
+	return slices.Sorted(maps.Keys(r.active))
+}
+
+// Free are the elements that aren't showing a row, they are kept hidden until
+// they are needed again
+func (r *Recycler[T]) Free() []T { return r.free }
+
+// Rebind fills the elements of the rows again, used when the items changed
+// but the rows that can be seen didn't
+func (r *Recycler[T]) Rebind() {
+	for idx, item := range r.active {
+		r.bind(item, idx)
+	}
+}
+
+// Clear takes the elements away from all rows
+func (r *Recycler[T]) Clear() {
+	for idx, item := range r.active {
+		delete(r.active, idx)
+		r.free = append(r.free, item)
+	}
+}
+
+// All calls fn for every element the recycler has made, both those showing a
+// row and the free ones
+func (r *Recycler[T]) All(fn func(item T)) {
+	for _, item := range r.active {
+		fn(item)
+	}
+	for _, item := range r.free {
+		fn(item)
+	}
+}
@@ -0,0 +1,170 @@
+/******************************************************************************/
+/* rows.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+// Package virtual is the bookkeeping of virtualized lists: where each row is
+// when rows can have different heights, which rows can be seen for a scroll
+// position, and the recycling of the elements that show them. It works on
+// plain values so it can be used (and tested) without any UI, #ui.ListView and
+// #ui.DataGrid are built on it.
+package virtual
+
+// Rows holds the heights of the rows of a list. Rows that were never measured
+// use the estimated height, the offsets are kept in a Fenwick tree so that
+// finding a row by its offset stays fast for lists of any length.
+type Rows struct {
+	estimate float32
+	heights  []float32
+	tree     []float32
+}
+
+// NewRows creates the rows of a list with an estimate for the height of the
+// rows that haven't been measured yet
+func NewRows(estimate float32) *Rows {
+	return &Rows{estimate: max(estimate, 1)}
+}
+
+func (r *Rows) Len() int          { return len(r.heights) }
+func (r *Rows) Estimate() float32 { return r.estimate }
+
+// SetLen changes the number of rows, new rows take the estimated height
+func (r *Rows) SetLen(count int) {
+	count = max(count, 0)
+	if count == len(r.heights) {
+		return
+	}
+	for len(r.heights) < count {
+		r.heights = append(r.heights, r.estimate)
+	}
+	r.heights = r.heights[:count]
+	r.rebuild()
+}
+
+// Reset gives all the rows the estimated height again, this is used when the
+// items of the list were replaced
+func (r *Rows) Reset() {
+	for i := range r.heights {
+		r.heights[i] = r.estimate
+	}
+	r.rebuild()
+}
+
+func (r *Rows) Height(index int) float32 {
+	if index < 0 || index >= len(r.heights) {
+		return 0
+	}
+	return r.heights[index]
+}
+
+// SetHeight sets the measured height of the row, returning true if it changed
+func (r *Rows) SetHeight(index int, height float32) bool {
+	if index < 0 || index >= len(r.heights) {
+		return false
+	}
+	height = max(height, 0)
+	delta := height - r.heights[index]
+	if delta == 0 {
+		return false
+	}
+	r.heights[index] = height
+	for i := index + 1; i <= len(r.tree); i += i & -i {
+		r.tree[i-1] += delta
+	}
+	return true
+}
+
+// Offset is the distance from the top of the list to the top of the row
+func (r *Rows) Offset(index int) float32 {
+	index = min(max(index, 0), len(r.heights))
+	sum := float32(0)
+	for i := index; i > 0; i -= i & -i {
+		sum += r.tree[i-1]
+	}
+	return sum
+}
+
+// Total is the height of all the rows together
+func (r *Rows) Total() float32 { return r.Offset(len(r.heights)) }
+
+// IndexAt finds the row at the offset from the top of the list, offsets past
+// either end give the first or last row and -1 is returned when there are no
+// rows
+func (r *Rows) IndexAt(offset float32) int {
+	if len(r.heights) == 0 {
+		return -1
+	}
+	idx := 0
+	step := 1
+	for step*2 <= len(r.tree) {
+		step *= 2
+	}
+	// Walk down the tree for the last row that starts at or before the offset
+	for ; step > 0; step /= 2 {
+		next := idx + step
+		if next <= len(r.tree) && r.tree[next-1] <= offset {
+			idx = next
+			offset -= r.tree[next-1]
+		}
+	}
+	return min(idx, len(r.heights)-1)
+}
+
+// Visible gives the range [first, last) of the rows that can be seen in the
+// viewport when the list is scrolled by scroll. Overscan adds rows above and
+// below so the rows are already there when scrolling starts.
+func (r *Rows) Visible(scroll, viewport float32, overscan int) (first, last int) {
+	if len(r.heights) == 0 || viewport <= 0 {
+		return 0, 0
+	}
+	first = r.IndexAt(scroll)
+	last = r.IndexAt(scroll+viewport) + 1
+	if last < len(r.heights) && r.Offset(last-1) == scroll+viewport && last-1 > first {
+		// The row starts exactly at the bottom edge, so it isn't seen
+		last--
+	}
+	first = max(first-overscan, 0)
+	last = min(last+overscan, len(r.heights))
+	return first, last
+}
+
+func (r *Rows) rebuild() {
+	r.tree = append(r.tree[:0], r.heights...)
+	for i := 1; i <= len(r.tree); i++ {
+		if parent := i + (i & -i); parent <= len(r.tree) {
+			r.tree[parent-1] += r.tree[i-1]
+		}
+	}
+}
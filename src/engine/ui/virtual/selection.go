@@ -0,0 +1,145 @@
+/******************************************************************************/
+/* selection.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package virtual
+
+import (
+	"maps"
+	"slices"
+)
+
+// Selection is the set of selected rows. With Multiple set, rows are added
+// with ctrl and ranges with shift the way file browsers do.
+type Selection struct {
+	Multiple bool
+	selected map[int]struct{}
+	anchor   int
+}
+
+func (s *Selection) IsSelected(index int) bool {
+	_, ok := s.selected[index]
+	return ok
+}
+
+func (s *Selection) Len() int { return len(s.selected) }
+
+// Indices are the selected rows in order
+func (s *Selection) Indices() []int {
+	return slices.Sorted(maps.Keys(s.selected))
+}
+
+// Anchor is the row that was last clicked, it is where shift ranges start
+func (s *Selection) Anchor() int { return s.anchor }
+
+// Click selects the row as a click on it would, ctrl toggles the row and
+// shift selects the rows from the anchor to it when multiple rows can be
+// selected
+func (s *Selection) Click(index int, ctrl, shift bool) {
+	if s.selected == nil {
+		s.selected = make(map[int]struct{})
+	}
+	if !s.Multiple || (!ctrl && !shift) {
+		clear(s.selected)
+		s.selected[index] = struct{}{}
+		s.anchor = index
+		return
+	}
+	if shift {
+		if !ctrl {
+			clear(s.selected)
+		}
+		from, to := min(s.anchor, index), max(s.anchor, index)
+		for i := from; i <= to; i++ {
+			s.selected[i] = struct{}{}
+		}
+		return
+	}
+	if s.IsSelected(index) {
+		delete(s.selected, index)
+	} else {
+		s.selected[index] = struct{}{}
+	}
+	s.anchor = index
+}
+
+// Set selects or deselects the row without changing the anchor
+func (s *Selection) Set(index int, selected bool) {
+	if s.selected == nil {
+		s.selected = make(map[int]struct{})
+	}
+	if selected {
+		s.selected[index] = struct{}{}
+	} else {
+		delete(s.selected, index)
+	}
+}
+
+func (s *Selection) Clear() {
+	clear(s.selected)
+	s.anchor = 0
+}
+
+// Truncate drops the selected rows that are past the end of a list that now
+// has count rows
+func (s *Selection) Truncate(count int) {
+	for idx := range s.selected {
+		if idx >= count {
+			delete(s.selected, idx)
+		}
+	}
+	s.anchor = min(s.anchor, max(count-1, 0))
+}
+
+// Remap moves the selection to follow the rows when they were reordered,
+// order[i] is the old index of the row that is now at i
+func (s *Selection) Remap(order []int) {
+	if len(s.selected) == 0 {
+		return
+	}
+	moved := make(map[int]struct{}, len(s.selected))
+	anchor := s.anchor
+	for now, was := range order {
+		if s.IsSelected(was) {
+			moved[now] = struct{}{}
+		}
+		if was == s.anchor {
+			anchor = now
+		}
+	}
+	s.selected = moved
+	s.anchor = anchor
+}
@@ -0,0 +1,197 @@
+/******************************************************************************/
+/* virtual_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package virtual
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRowsOffsets(t *testing.T) {
+	r := NewRows(20)
+	r.SetLen(5)
+	if r.Total() != 100 {
+		t.Fatalf("expected the total to be 100 but got %f", r.Total())
+	}
+	r.SetHeight(1, 50)
+	r.SetHeight(3, 5)
+	expected := []float32{0, 20, 70, 90, 95, 115}
+	for i, e := range expected {
+		if got := r.Offset(i); got != e {
+			t.Errorf("expected the offset of row %d to be %f but got %f", i, e, got)
+		}
+	}
+	r.SetLen(6)
+	if r.Total() != 135 || r.Height(5) != 20 {
+		t.Errorf("new rows should use the estimate, total was %f", r.Total())
+	}
+}
+
+func TestRowsIndexAt(t *testing.T) {
+	r := NewRows(10)
+	r.SetLen(100)
+	r.SetHeight(0, 100)
+	tests := []struct {
+		offset float32
+		index  int
+	}{
+		{-5, 0}, {0, 0}, {99, 0}, {100, 1}, {109, 1}, {110, 2}, {1085, 99}, {5000, 99},
+	}
+	for _, test := range tests {
+		if got := r.IndexAt(test.offset); got != test.index {
+			t.Errorf("expected the row at %f to be %d but got %d", test.offset, test.index, got)
+		}
+	}
+	if NewRows(10).IndexAt(0) != -1 {
+		t.Error("expected -1 for a list without rows")
+	}
+}
+
+func TestRowsVisible(t *testing.T) {
+	r := NewRows(10)
+	r.SetLen(1000)
+	first, last := r.Visible(55, 30, 0)
+	if first != 5 || last != 9 {
+		t.Errorf("expected rows [5, 9) but got [%d, %d)", first, last)
+	}
+	first, last = r.Visible(50, 30, 0)
+	if first != 5 || last != 8 {
+		t.Errorf("expected rows [5, 8) but got [%d, %d)", first, last)
+	}
+	first, last = r.Visible(0, 30, 2)
+	if first != 0 || last != 5 {
+		t.Errorf("expected the overscan to be clamped to [0, 5) but got [%d, %d)", first, last)
+	}
+}
+
+func TestRecyclerReusesElements(t *testing.T) {
+	created := 0
+	bound := map[*int]int{}
+	r := NewRecycler(func() *int {
+		created++
+		return new(int)
+	}, func(item *int, index int) { bound[item] = index })
+	r.Show(0, 10)
+	if created != 10 {
+		t.Fatalf("expected 10 elements but %d were made", created)
+	}
+	if !r.Show(5, 15) {
+		t.Error("expected the rows to change")
+	}
+	if created != 10 {
+		t.Errorf("expected the elements to be reused but %d were made", created)
+	}
+	if !slices.Equal(r.Indices(), []int{5, 6, 7, 8, 9, 10, 11, 12, 13, 14}) {
+		t.Errorf("unexpected rows %v", r.Indices())
+	}
+	for _, idx := range r.Indices() {
+		item, _ := r.Item(idx)
+		if bound[item] != idx {
+			t.Errorf("the element of row %d shows row %d", idx, bound[item])
+		}
+	}
+	if r.Show(5, 15) {
+		t.Error("expected nothing to change when the rows are the same")
+	}
+	r.Show(0, 2)
+	if len(r.Free()) != 8 {
+		t.Errorf("expected 8 free elements but got %d", len(r.Free()))
+	}
+}
+
+func TestSelection(t *testing.T) {
+	s := Selection{}
+	s.Click(3, true, false)
+	s.Click(5, true, false)
+	if !slices.Equal(s.Indices(), []int{5}) {
+		t.Errorf("single selection should only keep the last row, got %v", s.Indices())
+	}
+	s.Multiple = true
+	s.Click(2, false, false)
+	s.Click(6, true, false)
+	if !slices.Equal(s.Indices(), []int{2, 6}) {
+		t.Errorf("ctrl should add the row, got %v", s.Indices())
+	}
+	s.Click(4, false, true)
+	if !slices.Equal(s.Indices(), []int{4, 5, 6}) {
+		t.Errorf("shift should select from the anchor, got %v", s.Indices())
+	}
+	s.Click(6, true, false)
+	if s.IsSelected(6) {
+		t.Error("ctrl should deselect a selected row")
+	}
+	s.Remap([]int{5, 4, 3, 2, 1, 0, 6})
+	if !slices.Equal(s.Indices(), []int{0, 1}) {
+		t.Errorf("the selection should follow the rows, got %v", s.Indices())
+	}
+	s.Truncate(1)
+	if !slices.Equal(s.Indices(), []int{0}) {
+		t.Errorf("expected rows past the end to be dropped, got %v", s.Indices())
+	}
+}
+
+func TestColumns(t *testing.T) {
+	cols := []Column{{Width: 100, MinWidth: 40}, {Width: 50}}
+	if d := Resize(cols, 0, -80); d != -60 || cols[0].Width != 40 {
+		t.Errorf("expected the column to stop at its min width, changed by %f", d)
+	}
+	if !slices.Equal(Offsets(cols), []float32{0, 40, 90}) {
+		t.Errorf("unexpected offsets %v", Offsets(cols))
+	}
+}
+
+func TestOrder(t *testing.T) {
+	values := []int{3, 1, 2, 1}
+	less := func(a, b int) bool { return values[a] < values[b] }
+	if o := Order(4, less, false); !slices.Equal(o, []int{1, 3, 2, 0}) {
+		t.Errorf("unexpected ascending order %v", o)
+	}
+	if o := Order(4, less, true); !slices.Equal(o, []int{0, 2, 1, 3}) {
+		t.Errorf("unexpected descending order %v", o)
+	}
+	s := Unsorted.Toggle(2)
+	if s != (Sort{Column: 2}) {
+		t.Errorf("unexpected sort %v", s)
+	}
+	if s = s.Toggle(2); !s.Descending {
+		t.Error("expected the second click to sort descending")
+	}
+	if s = s.Toggle(2); s != Unsorted {
+		t.Error("expected the third click to remove the sort")
+	}
+}
@@ -5,12 +5,15 @@ go 1.24.0
 require (
 	github.com/KaijuEngine/uuid v1.0.0
 	github.com/ebitengine/oto/v3 v3.2.0
+	github.com/hajimehoshi/go-mp3 v0.3.4
+	github.com/jfreymuth/oggvorbis v1.0.5
 	github.com/tdewolff/parse/v2 v2.7.11
 	golang.org/x/net v0.21.0
 	golang.org/x/text v0.14.0
 )
 
 require (
+	github.com/jfreymuth/vorbis v1.0.2 // indirect
 	golang.org/x/exp v0.0.0-20190731235908-ec7cb31e5a56 // indirect
 	golang.org/x/image v0.6.0 // indirect
 	golang.org/x/mobile v0.0.0-20230301163155-e0f57694e12c // indirect
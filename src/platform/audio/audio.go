@@ -39,24 +39,46 @@ package audio
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"kaiju/editor/cache/project_cache"
+	"kaiju/engine/assets"
+	"kaiju/engine/assets/asset_info"
+	"kaiju/matrix"
 	"kaiju/platform/audio/audio_system"
+	"kaiju/platform/audio/decoder"
+	"kaiju/platform/audio/mixer"
+	"kaiju/platform/audio/spatial"
 	"log/slog"
-	"time"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/ebitengine/oto/v3"
 )
 
+// outputBufferFrames is the number of frames the audio device buffers ahead
+// of what is being heard, lower values reduce latency at the risk of crackles
+const outputBufferFrames = 2048
+
 type Audio struct {
-	otoCtx  *oto.Context
-	options oto.NewContextOptions
+	otoCtx            *oto.Context
+	options           oto.NewContextOptions
+	player            *oto.Player
+	mixer             *mixer.Mixer
+	listener          spatial.Listener
+	listenerTransform *matrix.Transform
 }
 
 func NewAudio() (Audio, error) {
 	a := Audio{
-		options: oto.NewContextOptions{},
+		options:  oto.NewContextOptions{},
+		listener: spatial.NewListener(),
 	}
-	a.options.SampleRate = 48000
-	a.options.ChannelCount = 2
+	a.options.SampleRate = mixer.DefaultSampleRate
+	a.options.ChannelCount = mixer.OutputChannels
 	a.options.Format = oto.FormatFloat32LE
 	otoCtx, readyChan, err := oto.NewContext(&a.options)
 	if err != nil {
@@ -64,42 +86,171 @@ func NewAudio() (Audio, error) {
 	}
 	a.otoCtx = otoCtx
 	<-readyChan
+	a.mixer = mixer.New(a.options.SampleRate, mixer.DefaultMaxVoices)
+	a.player = otoCtx.NewPlayer(a.mixer)
+	a.player.SetBufferSize(outputBufferFrames * mixer.OutputChannels * 4)
+	a.player.Play()
 	return a, nil
 }
 
-func (a *Audio) Play(wav *audio_system.Wav) {
+// Mixer returns the mixer that all of the audio is played through, this will
+// be nil if the audio has not been initialized
+func (a *Audio) Mixer() *mixer.Mixer { return a.mixer }
+
+// Listener returns the listener that positional sounds are heard from. The
+// host moves the listener every frame to follow the camera, or the listener
+// transform if one has been set.
+func (a *Audio) Listener() *spatial.Listener { return &a.listener }
+
+// ListenerTransform returns the transform the listener is following, nil
+// means that the listener follows the camera
+func (a *Audio) ListenerTransform() *matrix.Transform { return a.listenerTransform }
+
+// SetListenerTransform makes the listener follow the transform rather than
+// the camera, set it to nil to go back to following the camera
+func (a *Audio) SetListenerTransform(transform *matrix.Transform) {
+	a.listenerTransform = transform
+}
+
+// Play will play the wav once through the SFX bus, the returned voice can be
+// used to control the playback. Nil is returned if audio is not initialized.
+func (a *Audio) Play(wav *audio_system.Wav) *mixer.Voice {
+	return a.PlayWithOptions(wav, mixer.DefaultPlayOptions())
+}
+
+// PlayWithOptions will play the wav through the mixer using the options, the
+// returned voice can be used to control the playback. Nil is returned if
+// audio is not initialized.
+func (a *Audio) PlayWithOptions(wav *audio_system.Wav, options mixer.PlayOptions) *mixer.Voice {
 	if wav == nil {
 		slog.Error("Wav is nil")
-		return
-	}
-	data := wav.WavData
-	// TODO:  Rather than doing this real-time, it should be a part of the
-	// import process of the asset.  This is a temporary solution.
-	if int(wav.Channels) != a.options.ChannelCount {
-		slog.Warn("Rechanneling audio, this is a temporary solution",
-			slog.Int("channels", int(wav.Channels)),
-			slog.Int("target", int(a.options.ChannelCount)))
-		data = audio_system.Rechannel(wav, int16(a.options.ChannelCount))
-	}
-	if int(wav.SampleRate) != a.options.SampleRate {
-		slog.Warn("Resampling audio, this is a temporary solution",
-			slog.Int("sampleRate", int(wav.SampleRate)),
-			slog.Int("target", int(a.options.SampleRate)))
-		data = audio_system.Resample(wav, int32(a.options.SampleRate))
-	}
-	if wav.FormatType != audio_system.WavFormatFloat {
-		slog.Warn("Converting audio to float, this is a temporary solution",
-			slog.String("format", "Float"))
-		data = audio_system.Pcm2Float(wav)
-	}
-	player := a.otoCtx.NewPlayer(bytes.NewReader(data))
-	player.Play()
-	go func() {
-		for player.IsPlaying() {
-			time.Sleep(time.Millisecond)
+		return nil
+	}
+	if a.mixer == nil {
+		slog.Error("audio has not been initialized")
+		return nil
+	}
+	return a.mixer.Play(SoundFromWav(wav), options)
+}
+
+// PlayAsset plays the audio asset with the settings it was imported with, it
+// goes through the bus it was imported to and loops between its loop points.
+// A sound imported to stream is streamed, any other is fully loaded first.
+func (a *Audio) PlayAsset(assetDatabase *assets.Database, key string) (*mixer.Voice, error) {
+	settings, _ := ReadImportSettings(key)
+	options := settings.PlayOptions()
+	if settings.IsStream() {
+		return a.PlayStream(assetDatabase, key, options)
+	}
+	if a.mixer == nil {
+		return nil, errors.New("audio has not been initialized")
+	}
+	sound, err := LoadSound(assetDatabase, key)
+	if err != nil {
+		return nil, err
+	}
+	return a.mixer.Play(sound, options), nil
+}
+
+// PlayStream will stream the audio asset through the mixer using the options,
+// the asset is decoded a little at a time while it plays rather than all at
+// once. This should be used for long sounds such as music. WAV, Ogg Vorbis,
+// MP3 and FLAC are supported. If the options loop without loop points, the
+// loop points stored in the imported sound are used.
+func (a *Audio) PlayStream(assetDatabase *assets.Database, key string, options mixer.PlayOptions) (*mixer.Voice, error) {
+	if a.mixer == nil {
+		return nil, errors.New("audio has not been initialized")
+	}
+	f, name, err := openSound(assetDatabase, key)
+	if err != nil {
+		return nil, err
+	}
+	d, err := decoder.New(name, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if w, ok := d.(*decoder.WavDecoder); ok && options.Loop && options.LoopEnd <= 0 {
+		if start, end, ok := w.LoopPoints(); ok {
+			options.LoopStart, options.LoopEnd = start, end
 		}
-		if err := player.Close(); err != nil {
-			slog.Error(err.Error())
+	}
+	stream := decoder.NewStream(d, decoder.StreamOptions{
+		SampleRate: a.mixer.SampleRate(),
+		Closer:     f,
+	})
+	return a.mixer.PlayStream(stream, options), nil
+}
+
+// SoundFromWav converts the wav data into float samples that can be played
+// through the mixer. The mixer takes care of playing the sound at the correct
+// rate, so no resampling is done here. The result should be kept around if
+// the same wav is to be played many times.
+func SoundFromWav(wav *audio_system.Wav) *mixer.Sound {
+	var samples []float32
+	if wav.FormatType == audio_system.WavFormatFloat {
+		samples = make([]float32, len(wav.WavData)/4)
+		for i := range samples {
+			samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(wav.WavData[i*4:]))
+		}
+	} else {
+		samples = make([]float32, len(wav.WavData)/2)
+		for i := range samples {
+			samples[i] = float32(int16(binary.LittleEndian.Uint16(wav.WavData[i*2:]))) / math.MaxInt16
+		}
+	}
+	return mixer.NewSound(samples, int(wav.Channels), int(wav.SampleRate))
+}
+
+// openSound opens the converted copy of the audio asset that was cached when
+// it was imported, falling back to the asset itself if it hasn't been
+// imported. The name returned is used to pick the decoder.
+func openSound(assetDatabase *assets.Database, key string) (io.ReadSeekCloser, string, error) {
+	if f, ok := openCachedSound(key); ok {
+		return f, f.Name(), nil
+	}
+	f, err := assetDatabase.Open(key)
+	return f, key, err
+}
+
+// openCachedSound opens the converted WAVE file cached for the audio asset,
+// ok is false if the asset hasn't been imported
+func openCachedSound(key string) (*os.File, bool) {
+	adi, err := asset_info.Lookup(key)
+	if err != nil {
+		return nil, false
+	}
+	f, err := project_cache.OpenCachedAudio(adi.ID)
+	return f, err == nil
+}
+
+// LoadSound reads the audio asset and fully decodes it into a sound that can
+// be played through the mixer. WAV, Ogg Vorbis, MP3 and FLAC are supported.
+// Imported sounds are loaded from their converted copy so they are already
+// at the rate of the mixer.
+func LoadSound(assetDatabase *assets.Database, key string) (*mixer.Sound, error) {
+	if f, ok := openCachedSound(key); ok {
+		defer f.Close()
+		d, err := decoder.NewWav(f)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.DecodeAll(d)
+	}
+	if strings.ToLower(filepath.Ext(key)) == ".wav" {
+		wav, err := audio_system.LoadWav(assetDatabase, key)
+		if err != nil {
+			return nil, err
 		}
-	}()
+		return SoundFromWav(wav), nil
+	}
+	data, err := assetDatabase.Read(key)
+	if err != nil {
+		return nil, err
+	}
+	d, err := decoder.New(key, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return decoder.DecodeAll(d)
 }
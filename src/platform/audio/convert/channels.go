@@ -0,0 +1,184 @@
+/******************************************************************************/
+/* channels.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package convert
+
+import "math"
+
+// minus3dB is the gain used when a channel is split evenly across two
+// speakers, so that its power stays the same
+const minus3dB = math.Sqrt2 / 2
+
+// speaker is the position a channel is meant to be played from
+type speaker int
+
+const (
+	speakerFrontLeft speaker = iota
+	speakerFrontRight
+	speakerCenter
+	speakerLowFrequency
+	speakerBackLeft
+	speakerBackRight
+	speakerSideLeft
+	speakerSideRight
+	speakerBackCenter
+)
+
+// layouts are the default speaker positions for the channel counts used by
+// WAVE, Vorbis and FLAC, which all share the same order for their common
+// layouts
+var layouts = map[int][]speaker{
+	3: {speakerFrontLeft, speakerFrontRight, speakerCenter},
+	4: {speakerFrontLeft, speakerFrontRight, speakerBackLeft, speakerBackRight},
+	5: {speakerFrontLeft, speakerFrontRight, speakerCenter, speakerBackLeft, speakerBackRight},
+	6: {speakerFrontLeft, speakerFrontRight, speakerCenter, speakerLowFrequency,
+		speakerBackLeft, speakerBackRight},
+	7: {speakerFrontLeft, speakerFrontRight, speakerCenter, speakerLowFrequency,
+		speakerBackCenter, speakerSideLeft, speakerSideRight},
+	8: {speakerFrontLeft, speakerFrontRight, speakerCenter, speakerLowFrequency,
+		speakerBackLeft, speakerBackRight, speakerSideLeft, speakerSideRight},
+}
+
+// stereoGains returns the left and right gain for a speaker when it is
+// mixed down to stereo, following the ITU-R BS.775 down mix. The low
+// frequency channel is dropped as stereo speakers can't reproduce it.
+func stereoGains(s speaker) (float64, float64) {
+	switch s {
+	case speakerFrontLeft:
+		return 1, 0
+	case speakerFrontRight:
+		return 0, 1
+	case speakerCenter, speakerBackCenter:
+		return minus3dB, minus3dB
+	case speakerBackLeft, speakerSideLeft:
+		return minus3dB, 0
+	case speakerBackRight, speakerSideRight:
+		return 0, minus3dB
+	}
+	return 0, 0
+}
+
+// ChannelMatrix returns the gains to mix each of the from channels into each
+// of the to channels, indexed as [to][from]. Mono is spread to both sides of
+// stereo, stereo is averaged into mono and surround layouts are down mixed
+// to stereo (and then mono) with the standard gains. Going from fewer to more
+// channels (other than mono) keeps the matching channels and leaves the rest
+// silent.
+func ChannelMatrix(from, to int) [][]float32 {
+	m := make([][]float32, to)
+	for i := range m {
+		m[i] = make([]float32, from)
+	}
+	switch {
+	case from == to:
+		for i := range m {
+			m[i][i] = 1
+		}
+	case from == 1:
+		for i := range min(to, 2) {
+			m[i][0] = 1
+		}
+	case to <= 2:
+		left := make([]float64, from)
+		right := make([]float64, from)
+		if layout, ok := layouts[from]; ok {
+			for i, s := range layout {
+				left[i], right[i] = stereoGains(s)
+			}
+		} else {
+			// Without a known layout, even channels go left and odd go right
+			for i := range from {
+				if i%2 == 0 {
+					left[i] = 1
+				} else {
+					right[i] = 1
+				}
+			}
+		}
+		// Scale so that a full scale signal on every channel doesn't clip
+		lSum, rSum := 0.0, 0.0
+		for i := range from {
+			lSum += left[i]
+			rSum += right[i]
+		}
+		scale := 1 / max(lSum, rSum, 1)
+		for i := range from {
+			if to == 1 {
+				m[0][i] = float32((left[i] + right[i]) * scale / 2)
+			} else {
+				m[0][i] = float32(left[i] * scale)
+				m[1][i] = float32(right[i] * scale)
+			}
+		}
+	default:
+		for i := range min(from, to) {
+			m[i][i] = 1
+		}
+	}
+	return m
+}
+
+// Rechannel mixes the interleaved samples from one channel count to another
+// using #ChannelMatrix
+func Rechannel(samples []float32, from, to int) []float32 {
+	if from == to {
+		return samples
+	}
+	return RechannelInto(samples, from, to, ChannelMatrix(from, to), nil)
+}
+
+// RechannelInto is the same as #Rechannel but uses the already built matrix
+// and reuses the out buffer if it is big enough, this is used when
+// converting a stream in chunks
+func RechannelInto(samples []float32, from, to int, matrix [][]float32, out []float32) []float32 {
+	frames := len(samples) / from
+	if cap(out) < frames*to {
+		out = make([]float32, frames*to)
+	}
+	out = out[:frames*to]
+	for f := 0; f < frames; f++ {
+		in := samples[f*from : (f+1)*from]
+		for c := 0; c < to; c++ {
+			var sum float32
+			for i, g := range matrix[c] {
+				sum += in[i] * g
+			}
+			out[f*to+c] = sum
+		}
+	}
+	return out
+}
@@ -0,0 +1,147 @@
+/******************************************************************************/
+/* convert_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package convert
+
+import (
+	"math"
+	"testing"
+)
+
+func testSine(frequency float64, rate, frames int) []float32 {
+	samples := make([]float32, frames)
+	for i := range samples {
+		samples[i] = float32(math.Sin(2 * math.Pi * frequency * float64(i) / float64(rate)))
+	}
+	return samples
+}
+
+func testRMS(samples []float32) float64 {
+	sum := 0.0
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+func TestResampleUp(t *testing.T) {
+	in := testSine(1000, 44100, 44100)
+	out := Resample(in, 1, 44100, 48000)
+	if len(out) != 48000 {
+		t.Fatalf("expected 48000 frames, got %d", len(out))
+	}
+	expected := testSine(1000, 48000, 48000)
+	// The ends are filtered against the silence around the sound
+	for i := 100; i < len(out)-100; i++ {
+		if math.Abs(float64(out[i]-expected[i])) > 0.001 {
+			t.Fatalf("frame %d expected %f, got %f", i, expected[i], out[i])
+		}
+	}
+}
+
+func TestResampleDownFiltersAliasing(t *testing.T) {
+	// 15kHz is above the Nyquist frequency of 22050 so it must be removed
+	// rather than folding back down as a 7kHz tone
+	out := Resample(testSine(15000, 48000, 48000), 1, 48000, 22050)
+	if len(out) != 22050 {
+		t.Fatalf("expected 22050 frames, got %d", len(out))
+	}
+	if rms := testRMS(out[200 : len(out)-200]); rms > 0.001 {
+		t.Fatalf("expected the tone to be filtered out, got an RMS of %f", rms)
+	}
+	// A tone under the new Nyquist frequency is kept at the same level
+	out = Resample(testSine(5000, 48000, 48000), 1, 48000, 22050)
+	if rms := testRMS(out[200 : len(out)-200]); math.Abs(rms-math.Sqrt2/2) > 0.01 {
+		t.Fatalf("expected the tone to be kept, got an RMS of %f", rms)
+	}
+}
+
+func TestResamplerChunks(t *testing.T) {
+	// Interleaved stereo with a different tone in each channel
+	left := testSine(440, 32000, 8000)
+	right := testSine(3000, 32000, 8000)
+	in := make([]float32, 16000)
+	for i := range left {
+		in[i*2], in[i*2+1] = left[i], right[i]
+	}
+	all := Resample(in, 2, 32000, 44100)
+	r := NewResampler(2, 32000, 44100)
+	var chunked []float32
+	for start := 0; start < len(in); start += 2 * 333 {
+		chunked = r.Process(in[start:min(start+2*333, len(in))], chunked)
+	}
+	chunked = r.Flush(chunked)
+	if len(chunked) != len(all) {
+		t.Fatalf("expected %d samples, got %d", len(all), len(chunked))
+	}
+	for i := range all {
+		if chunked[i] != all[i] {
+			t.Fatalf("sample %d expected %f, got %f", i, all[i], chunked[i])
+		}
+	}
+}
+
+func TestResampleSameRate(t *testing.T) {
+	in := []float32{1, 2, 3}
+	if out := Resample(in, 1, 48000, 48000); &out[0] != &in[0] {
+		t.Error("expected the samples to be passed through")
+	}
+}
+
+func TestRechannel(t *testing.T) {
+	out := Rechannel([]float32{0.5, -0.25}, 1, 2)
+	if out[0] != 0.5 || out[1] != 0.5 || out[2] != -0.25 || out[3] != -0.25 {
+		t.Errorf("unexpected mono to stereo %v", out)
+	}
+	out = Rechannel([]float32{1, 0, 0.5, 0.5}, 2, 1)
+	if out[0] != 0.5 || out[1] != 0.5 {
+		t.Errorf("unexpected stereo to mono %v", out)
+	}
+	// 5.1: left, right, center, LFE, back left, back right
+	out = Rechannel([]float32{1, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0}, 6, 2)
+	scale := float32(1 / (1 + 2*minus3dB))
+	if math.Abs(float64(out[0]-scale)) > 0.0001 || out[1] != 0 {
+		t.Errorf("expected front left to only be on the left, got %v", out[:2])
+	}
+	if math.Abs(float64(out[2]-minus3dB*scale)) > 0.0001 || out[2] != out[3] {
+		t.Errorf("expected the center to be split evenly, got %v", out[2:])
+	}
+	out = Rechannel([]float32{1, 1, 1, 1, 1, 1}, 6, 2)
+	if out[0] > 1 || out[1] > 1 {
+		t.Errorf("expected the down mix to not clip, got %v", out)
+	}
+}
@@ -0,0 +1,242 @@
+/******************************************************************************/
+/* resampler.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package convert
+
+import "math"
+
+const (
+	// zeroCrossings is the number of zero crossings of the sinc on each side
+	// of its center, more gives a sharper filter at the cost of speed
+	zeroCrossings = 16
+	// filterPhases is the number of fractional positions the filter is
+	// precomputed for, positions in between are linearly interpolated
+	filterPhases = 256
+	// kaiserBeta shapes the window of the sinc, this gives roughly 90dB of
+	// stop band attenuation
+	kaiserBeta = 8.6
+	// cutoffScale moves the cutoff just under the Nyquist frequency so that
+	// the transition band of the filter doesn't alias
+	cutoffScale = 0.95
+)
+
+// Resampler converts interleaved samples from one sample rate to another
+// with a polyphase windowed-sinc filter. The input can be given in chunks of
+// any size, the filter state is kept between calls to #Resampler.Process so
+// that the output is the same as converting everything at once.
+type Resampler struct {
+	channels    int
+	halfWidth   int
+	table       []float32
+	pending     []float32
+	base        int64
+	inFrames    int64
+	outFrames   int64
+	fromRate    int
+	toRate      int
+	passthrough bool
+}
+
+// NewResampler creates a resampler for interleaved samples of the given
+// number of channels. When the rates are the same the samples are passed
+// through untouched.
+func NewResampler(channels, fromRate, toRate int) *Resampler {
+	r := &Resampler{
+		channels:    max(channels, 1),
+		fromRate:    max(fromRate, 1),
+		toRate:      max(toRate, 1),
+		passthrough: fromRate == toRate,
+	}
+	if !r.passthrough {
+		// Downsampling lowers the cutoff below the new Nyquist frequency, so
+		// the filter is widened to keep the same number of zero crossings
+		cutoff := min(1, float64(r.toRate)/float64(r.fromRate)) * cutoffScale
+		r.halfWidth = int(math.Ceil(zeroCrossings / cutoff))
+		r.table = filterTable(r.halfWidth, cutoff)
+	}
+	r.Reset()
+	return r
+}
+
+// Reset clears the filter history so that the next samples processed are
+// treated as the start of a new sound
+func (r *Resampler) Reset() {
+	// The history starts as silence so the first output frame lines up with
+	// the first input frame
+	r.pending = make([]float32, r.halfWidth*r.channels, (r.halfWidth*2+1024)*r.channels)
+	r.base = -int64(r.halfWidth)
+	r.inFrames, r.outFrames = 0, 0
+}
+
+// Process converts the interleaved input and appends it to out, the
+// extended out is returned. The filter needs to look ahead of the frame it
+// is producing, so the last few input frames are held until more input is
+// given or #Resampler.Flush is called.
+func (r *Resampler) Process(in, out []float32) []float32 {
+	in = in[:len(in)-len(in)%r.channels]
+	if r.passthrough {
+		return append(out, in...)
+	}
+	r.inFrames += int64(len(in) / r.channels)
+	r.pending = append(r.pending, in...)
+	return r.drain(out, -1)
+}
+
+// Flush converts the input frames that are being held back for the filter
+// and appends them to out, the extended out is returned. The total output
+// is the length of the whole input scaled by the ratio of the rates.
+func (r *Resampler) Flush(out []float32) []float32 {
+	if r.passthrough {
+		return out
+	}
+	expected := (r.inFrames*int64(r.toRate) + int64(r.fromRate) - 1) / int64(r.fromRate)
+	r.pending = append(r.pending, make([]float32, (r.halfWidth+1)*r.channels)...)
+	return r.drain(out, expected)
+}
+
+// inputPosition returns the input frame an output frame lines up with and
+// how far it is between that frame and the next. This is worked out from
+// the frame count rather than accumulated so that the result doesn't drift
+// or depend on how the input was split into chunks.
+func (r *Resampler) inputPosition(outFrame int64) (int64, float64) {
+	scaled := outFrame * int64(r.fromRate)
+	return scaled / int64(r.toRate), float64(scaled%int64(r.toRate)) / float64(r.toRate)
+}
+
+// drain produces every output frame that has enough input after it, or up
+// to limit total output frames if limit isn't negative
+func (r *Resampler) drain(out []float32, limit int64) []float32 {
+	c := r.channels
+	frames := len(r.pending) / c
+	width := r.halfWidth * 2
+	for limit < 0 || r.outFrames < limit {
+		frame, frac := r.inputPosition(r.outFrames)
+		index := int(frame - r.base)
+		if index+r.halfWidth >= frames {
+			break
+		}
+		phase := frac * filterPhases
+		row := int(phase)
+		t := float32(phase - float64(row))
+		a := r.table[row*width : (row+1)*width]
+		b := r.table[(row+1)*width : (row+2)*width]
+		start := (index - r.halfWidth + 1) * c
+		for ch := 0; ch < c; ch++ {
+			var sumA, sumB float32
+			for k := 0; k < width; k++ {
+				s := r.pending[start+k*c+ch]
+				sumA += s * a[k]
+				sumB += s * b[k]
+			}
+			out = append(out, sumA+(sumB-sumA)*t)
+		}
+		r.outFrames++
+	}
+	// Drop the input that no future output frame will need
+	next, _ := r.inputPosition(r.outFrames)
+	if drop := min(int(next-r.base)-r.halfWidth+1, frames); drop > 0 {
+		r.pending = append(r.pending[:0], r.pending[drop*c:]...)
+		r.base += int64(drop)
+	}
+	return out
+}
+
+// filterTable builds the windowed-sinc coefficients for each phase, there is
+// one extra phase so the last phase can be interpolated towards the next
+// whole frame
+func filterTable(halfWidth int, cutoff float64) []float32 {
+	width := halfWidth * 2
+	table := make([]float32, (filterPhases+1)*width)
+	norm := besselI0(kaiserBeta)
+	for p := 0; p <= filterPhases; p++ {
+		frac := float64(p) / filterPhases
+		row := table[p*width : (p+1)*width]
+		sum := 0.0
+		for k := range row {
+			x := frac - float64(k-halfWidth+1)
+			w := 0.0
+			if r := x / float64(halfWidth); r > -1 && r < 1 {
+				w = besselI0(kaiserBeta*math.Sqrt(1-r*r)) / norm
+			}
+			v := cutoff * sinc(cutoff*x) * w
+			row[k] = float32(v)
+			sum += v
+		}
+		// Normalizing each phase keeps the gain at 0Hz exactly 1
+		for k := range row {
+			row[k] = float32(float64(row[k]) / sum)
+		}
+	}
+	return table
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	x *= math.Pi
+	return math.Sin(x) / x
+}
+
+// besselI0 is the zeroth order modified Bessel function of the first kind,
+// used to build the Kaiser window
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	half := x / 2
+	for k := 1; k < 50; k++ {
+		term *= half / float64(k)
+		sum += term * term
+		if term*term < sum*1e-12 {
+			break
+		}
+	}
+	return sum
+}
+
+// Resample converts all of the interleaved samples from one sample rate to
+// another, the result has the length of the input scaled by the ratio of
+// the rates
+func Resample(samples []float32, channels, fromRate, toRate int) []float32 {
+	if fromRate == toRate {
+		return samples
+	}
+	r := NewResampler(channels, fromRate, toRate)
+	frames := int64(len(samples) / r.channels)
+	out := make([]float32, 0, (frames*int64(toRate)/int64(fromRate)+1)*int64(r.channels))
+	out = r.Process(samples, out)
+	return r.Flush(out)
+}
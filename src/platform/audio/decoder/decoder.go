@@ -0,0 +1,133 @@
+/******************************************************************************/
+/* decoder.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package decoder
+
+import (
+	"errors"
+	"io"
+	"kaiju/platform/audio/mixer"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ExtensionOgg  = ".ogg"
+	ExtensionMP3  = ".mp3"
+	ExtensionFLAC = ".flac"
+	ExtensionWav  = ".wav"
+)
+
+// readChunkFrames is the number of frames read at a time when decoding an
+// entire stream through #DecodeAll
+const readChunkFrames = 4096
+
+var ErrUnsupportedFormat = errors.New("unsupported audio format")
+
+// Decoder is a stream of decoded audio. All decoders produce interleaved
+// 32-bit float samples in the range [-1, 1], which is the same format that is
+// consumed by the mixer, regardless of how the samples were encoded.
+type Decoder interface {
+	// Channels returns the number of interleaved channels in the stream
+	Channels() int
+	// SampleRate returns the number of frames per second of the stream
+	SampleRate() int
+	// Length returns the total number of frames in the stream, or -1 if
+	// the length is not known
+	Length() int64
+	// Read fills the samples with the next interleaved samples of the
+	// stream. Only whole frames are read, so the number of samples read will
+	// always be a multiple of the channel count. When there are no more
+	// samples to read, io.EOF is returned.
+	Read(samples []float32) (int, error)
+	// SetPosition moves the stream so that the next call to Read will start
+	// at the given frame
+	SetPosition(frame int64) error
+}
+
+// IsSupported returns true if there is a decoder for the file extension of
+// the given path
+func IsSupported(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ExtensionOgg, ExtensionMP3, ExtensionFLAC, ExtensionWav:
+		return true
+	}
+	return false
+}
+
+// New creates the decoder that matches the file extension of the path. The
+// path is only used to select the decoder, the data is read from r.
+func New(path string, r io.ReadSeeker) (Decoder, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ExtensionOgg:
+		return NewOgg(r)
+	case ExtensionMP3:
+		return NewMP3(r)
+	case ExtensionFLAC:
+		return NewFLAC(r)
+	case ExtensionWav:
+		return NewWav(r)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// DecodeAll reads the entire decoder into a sound that can be played through
+// the mixer. This should only be used for short sounds, longer sounds should
+// be streamed.
+func DecodeAll(d Decoder) (*mixer.Sound, error) {
+	channels := d.Channels()
+	var samples []float32
+	if length := d.Length(); length > 0 {
+		samples = make([]float32, 0, length*int64(channels))
+	}
+	buff := make([]float32, readChunkFrames*channels)
+	for {
+		n, err := d.Read(buff)
+		samples = append(samples, buff[:n]...)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return mixer.NewSound(samples, channels, d.SampleRate()), nil
+}
+
+// wholeFrames trims the sample buffer so that it only holds whole frames
+func wholeFrames(samples []float32, channels int) []float32 {
+	return samples[:len(samples)-len(samples)%channels]
+}
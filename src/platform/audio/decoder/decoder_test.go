@@ -0,0 +1,299 @@
+/******************************************************************************/
+/* decoder_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package decoder
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"kaiju/platform/audio/mixer"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testOpen(t *testing.T, name string) Decoder {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := New(name, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to create decoder for %s: %v", name, err)
+	}
+	return d
+}
+
+func testDecodeAll(t *testing.T, d Decoder) []float32 {
+	t.Helper()
+	sound, err := DecodeAll(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sound.Channels() != d.Channels() || sound.SampleRate() != d.SampleRate() {
+		t.Fatal("decoded sound does not match the decoder format")
+	}
+	return sound.Samples()
+}
+
+func testCompare(t *testing.T, got, expected []float32, tolerance float64) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d samples, got %d", len(expected), len(got))
+	}
+	for i := range got {
+		if math.Abs(float64(got[i]-expected[i])) > tolerance {
+			t.Fatalf("sample %d expected %f, got %f", i, expected[i], got[i])
+		}
+	}
+}
+
+func testSeek(t *testing.T, d Decoder, all []float32, frames ...int64) {
+	t.Helper()
+	channels := d.Channels()
+	buff := make([]float32, 300*channels)
+	for _, frame := range frames {
+		if err := d.SetPosition(frame); err != nil {
+			t.Fatal(err)
+		}
+		n, err := d.Read(buff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := int(frame) * channels
+		testCompare(t, buff[:n], all[start:start+n], 0.0001)
+	}
+}
+
+// testReadRaw reads reference decoder output stored as 32-bit little endian
+// floats
+func testReadRaw(t *testing.T, name string) []float32 {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := make([]float32, len(raw)/4)
+	for i := range samples {
+		samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:]))
+	}
+	return samples
+}
+
+func TestDecodeOgg(t *testing.T) {
+	expected := testReadRaw(t, "test.raw")
+	d := testOpen(t, "test.ogg")
+	if d.Channels() != 1 || d.SampleRate() != 44100 || d.Length() != 44100 {
+		t.Fatalf("unexpected format %d channels, %d hz, %d frames",
+			d.Channels(), d.SampleRate(), d.Length())
+	}
+	all := testDecodeAll(t, d)
+	testCompare(t, all, expected, 0.00002)
+	testSeek(t, d, all, 20000, 0, 43000)
+}
+
+func TestDecodeFLAC(t *testing.T) {
+	for _, name := range []string{"243749.flac", "59996.flac", "189983.flac"} {
+		t.Run(name, func(t *testing.T) {
+			d := testOpen(t, name)
+			flac := d.(*FLACDecoder)
+			all := testDecodeAll(t, d)
+			if int64(len(all)) != flac.Length()*int64(flac.Channels()) {
+				t.Fatalf("expected %d frames, got %d", flac.Length(), len(all)/flac.Channels())
+			}
+			// FLAC is lossless, so the decoded samples must exactly match the
+			// MD5 of the original audio that the encoder stored in the stream
+			bps := flac.bitsPerSample
+			scale := float32(int64(1) << (bps - 1))
+			size := int((bps + 7) / 8)
+			hash := md5.New()
+			buff := make([]byte, 4)
+			for _, s := range all {
+				binary.LittleEndian.PutUint32(buff, uint32(int32(s*scale)))
+				hash.Write(buff[:size])
+			}
+			if !bytes.Equal(hash.Sum(nil), flac.md5[:]) {
+				t.Fatal("decoded samples do not match the STREAMINFO MD5")
+			}
+			length := flac.Length()
+			testSeek(t, d, all, length/2, 0, length-200)
+		})
+	}
+}
+
+func TestDecodeMP3(t *testing.T) {
+	// The reference output is from minimp3, the file is mono so each of its
+	// samples is expected on both channels of the decoded output
+	expected := testReadRaw(t, "speech.raw")
+	d := testOpen(t, "speech.mp3")
+	if d.Channels() != 2 || d.SampleRate() != 22050 {
+		t.Fatalf("unexpected format %d channels, %d hz", d.Channels(), d.SampleRate())
+	}
+	if d.Length() != int64(len(expected)) {
+		t.Fatalf("expected length %d, got %d", len(expected), d.Length())
+	}
+	all := testDecodeAll(t, d)
+	stereo := make([]float32, len(expected)*2)
+	for i, s := range expected {
+		stereo[i*2], stereo[i*2+1] = s, s
+	}
+	// The decoder produces 16-bit samples, so they are only as close to the
+	// reference as the 16-bit rounding allows
+	testCompare(t, all, stereo, 0.0001)
+	testSeek(t, d, all, 10000, 0, 576)
+}
+
+func TestDecodeUnsupported(t *testing.T) {
+	if _, err := New("sound.xyz", bytes.NewReader(nil)); err != ErrUnsupportedFormat {
+		t.Error("expected an unsupported format error")
+	}
+	if _, err := NewFLAC(bytes.NewReader([]byte("OggS0000"))); err == nil {
+		t.Error("expected an error for a stream without the FLAC marker")
+	}
+	if !IsSupported("music/Theme.OGG") || IsSupported("image.png") {
+		t.Error("unexpected result from IsSupported")
+	}
+}
+
+func testWavFile(format, bitsPerSample, channels int, samples []float32) []byte {
+	var data bytes.Buffer
+	for _, s := range samples {
+		switch {
+		case format == wavFormatFloat:
+			binary.Write(&data, binary.LittleEndian, s)
+		case bitsPerSample == 8:
+			data.WriteByte(byte(int(s*128) + 128))
+		case bitsPerSample == 16:
+			binary.Write(&data, binary.LittleEndian, int16(s*math.MaxInt16))
+		case bitsPerSample == 24:
+			v := int32(s * (1 << 23))
+			data.Write([]byte{byte(v), byte(v >> 8), byte(v >> 16)})
+		}
+	}
+	blockAlign := channels * bitsPerSample / 8
+	var out bytes.Buffer
+	out.WriteString("RIFF")
+	binary.Write(&out, binary.LittleEndian, uint32(0))
+	out.WriteString("WAVE")
+	// An odd sized chunk before the format checks that padding is skipped
+	out.WriteString("junk")
+	binary.Write(&out, binary.LittleEndian, uint32(3))
+	out.Write([]byte{1, 2, 3, 0})
+	out.WriteString("fmt ")
+	binary.Write(&out, binary.LittleEndian, uint32(16))
+	for _, v := range []uint16{uint16(format), uint16(channels)} {
+		binary.Write(&out, binary.LittleEndian, v)
+	}
+	binary.Write(&out, binary.LittleEndian, []uint32{44100, uint32(44100 * blockAlign)})
+	binary.Write(&out, binary.LittleEndian, []uint16{uint16(blockAlign), uint16(bitsPerSample)})
+	out.WriteString("data")
+	binary.Write(&out, binary.LittleEndian, uint32(data.Len()))
+	out.Write(data.Bytes())
+	return out.Bytes()
+}
+
+func TestDecodeWav(t *testing.T) {
+	samples := make([]float32, 2000)
+	for i := range samples {
+		samples[i] = float32(math.Sin(float64(i) * 0.05))
+	}
+	tests := []struct {
+		name          string
+		format        int
+		bitsPerSample int
+		channels      int
+		tolerance     float64
+	}{
+		{"pcm8", wavFormatPCM, 8, 1, 1.0 / 64},
+		{"pcm16", wavFormatPCM, 16, 2, 0.0001},
+		{"pcm24", wavFormatPCM, 24, 1, 0.000001},
+		{"float32", wavFormatFloat, 32, 2, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := testWavFile(test.format, test.bitsPerSample, test.channels, samples)
+			d, err := New("sound.WAV", bytes.NewReader(file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Channels() != test.channels || d.SampleRate() != 44100 ||
+				d.Length() != int64(len(samples)/test.channels) {
+				t.Fatalf("unexpected format %d channels, %d hz, %d frames",
+					d.Channels(), d.SampleRate(), d.Length())
+			}
+			all := testDecodeAll(t, d)
+			testCompare(t, all, samples, test.tolerance)
+			testSeek(t, d, all, 500, 0, d.Length()-100)
+		})
+	}
+}
+
+func TestEncodeWav(t *testing.T) {
+	samples := make([]float32, 1000)
+	for i := range samples {
+		samples[i] = float32(math.Sin(float64(i) * 0.05))
+	}
+	var buff bytes.Buffer
+	if err := EncodeWav(&buff, mixer.NewSound(samples, 2, 48000), 100, 400); err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewWav(bytes.NewReader(buff.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Channels() != 2 || d.SampleRate() != 48000 || d.Length() != 500 {
+		t.Fatalf("unexpected format %d channels, %d hz, %d frames",
+			d.Channels(), d.SampleRate(), d.Length())
+	}
+	if start, end, ok := d.LoopPoints(); !ok || start != 100 || end != 400 {
+		t.Errorf("expected the loop 100 to 400, got %d to %d (%t)", start, end, ok)
+	}
+	testCompare(t, testDecodeAll(t, d), samples, 0)
+	buff.Reset()
+	if err := EncodeWav(&buff, mixer.NewSound(samples, 1, 48000), 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if d, err = NewWav(bytes.NewReader(buff.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := d.LoopPoints(); ok {
+		t.Error("expected no loop points")
+	}
+}
@@ -0,0 +1,562 @@
+/******************************************************************************/
+/* flac.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package decoder
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	flacMarker           = "fLaC"
+	flacMetaStreamInfo   = 0
+	flacFrameSync        = 0x3FFE
+	flacChannelLeftSide  = 8
+	flacChannelSideRight = 9
+	flacChannelMidSide   = 10
+)
+
+var (
+	errFLACMarker      = errors.New("missing fLaC stream marker")
+	errFLACStreamInfo  = errors.New("missing or invalid FLAC STREAMINFO block")
+	errFLACSync        = errors.New("lost FLAC frame sync")
+	errFLACReserved    = errors.New("reserved value used in FLAC frame")
+	errFLACSampleCount = errors.New("FLAC frame has more samples than its block size")
+)
+
+var (
+	flacBlockSizes  = [16]int{0, 192, 576, 1152, 2304, 4608, 0, 0, 256, 512, 1024, 2048, 4096, 8192, 16384, 32768}
+	flacSampleSizes = [8]uint{0, 8, 12, 0, 16, 20, 24, 32}
+)
+
+// FLACDecoder decodes a native FLAC stream. The decoder does not validate the
+// frame checksums, a corrupt stream will most likely fail to keep frame sync
+// and report an error.
+type FLACDecoder struct {
+	source        io.ReadSeeker
+	bits          flacBitReader
+	channels      int
+	sampleRate    int
+	bitsPerSample uint
+	maxBlockSize  int
+	totalFrames   int64
+	md5           [16]byte
+	firstFrame    int64
+	subframes     [][]int64
+	block         []float32
+	blockRead     int
+	position      int64
+}
+
+// NewFLAC creates a decoder for the FLAC stream, the metadata of the stream
+// is read immediately so that invalid streams are reported early
+func NewFLAC(r io.ReadSeeker) (*FLACDecoder, error) {
+	d := &FLACDecoder{source: r}
+	if err := d.readMetadata(); err != nil {
+		return nil, err
+	}
+	offset, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	d.firstFrame = offset
+	d.bits.reset(r)
+	d.subframes = make([][]int64, d.channels)
+	return d, nil
+}
+
+// Channels returns the number of interleaved channels in the stream
+func (d *FLACDecoder) Channels() int { return d.channels }
+
+// SampleRate returns the number of frames per second of the stream
+func (d *FLACDecoder) SampleRate() int { return d.sampleRate }
+
+// Length returns the total number of frames in the stream
+func (d *FLACDecoder) Length() int64 {
+	if d.totalFrames == 0 {
+		return -1
+	}
+	return d.totalFrames
+}
+
+// Read fills the samples with the next interleaved samples of the stream
+func (d *FLACDecoder) Read(samples []float32) (int, error) {
+	samples = wholeFrames(samples, d.channels)
+	total := 0
+	for total < len(samples) {
+		if d.blockRead == len(d.block) {
+			if err := d.decodeFrame(); err != nil {
+				if err == io.EOF && total > 0 {
+					return total, nil
+				}
+				return total, err
+			}
+		}
+		n := copy(samples[total:], d.block[d.blockRead:])
+		d.blockRead += n
+		total += n
+	}
+	return total, nil
+}
+
+// SetPosition moves the stream so that the next call to Read will start
+// at the given frame. FLAC frames can only be decoded in order, so this will
+// decode from the start of the stream up to the frame.
+func (d *FLACDecoder) SetPosition(frame int64) error {
+	if _, err := d.source.Seek(d.firstFrame, io.SeekStart); err != nil {
+		return err
+	}
+	d.bits.reset(d.source)
+	d.block = d.block[:0]
+	d.blockRead = 0
+	d.position = 0
+	for {
+		if err := d.decodeFrame(); err == io.EOF {
+			d.blockRead = len(d.block)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		frames := int64(len(d.block) / d.channels)
+		if frame < d.position+frames {
+			d.blockRead = int(frame-d.position) * d.channels
+			return nil
+		}
+	}
+}
+
+func (d *FLACDecoder) readMetadata() error {
+	var marker [4]byte
+	if _, err := io.ReadFull(d.source, marker[:]); err != nil {
+		return err
+	}
+	if string(marker[:3]) == "ID3" {
+		if err := d.skipID3(); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(d.source, marker[:]); err != nil {
+			return err
+		}
+	}
+	if string(marker[:]) != flacMarker {
+		return errFLACMarker
+	}
+	foundInfo := false
+	for last := false; !last; {
+		var header [4]byte
+		if _, err := io.ReadFull(d.source, header[:]); err != nil {
+			return err
+		}
+		last = header[0]&0x80 != 0
+		kind := header[0] & 0x7F
+		size := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+		block := make([]byte, size)
+		if _, err := io.ReadFull(d.source, block); err != nil {
+			return err
+		}
+		if kind == flacMetaStreamInfo {
+			if err := d.readStreamInfo(block); err != nil {
+				return err
+			}
+			foundInfo = true
+		}
+	}
+	if !foundInfo {
+		return errFLACStreamInfo
+	}
+	return nil
+}
+
+// skipID3 skips over an ID3v2 tag that some tools place before the marker,
+// the first 4 bytes of the tag have already been read
+func (d *FLACDecoder) skipID3() error {
+	var rest [6]byte
+	if _, err := io.ReadFull(d.source, rest[:]); err != nil {
+		return err
+	}
+	size := int64(rest[2])<<21 | int64(rest[3])<<14 | int64(rest[4])<<7 | int64(rest[5])
+	_, err := d.source.Seek(size, io.SeekCurrent)
+	return err
+}
+
+func (d *FLACDecoder) readStreamInfo(block []byte) error {
+	if len(block) < 34 {
+		return errFLACStreamInfo
+	}
+	d.maxBlockSize = int(binary.BigEndian.Uint16(block[2:]))
+	packed := binary.BigEndian.Uint64(block[10:])
+	d.sampleRate = int(packed >> 44)
+	d.channels = int(packed>>41&0x7) + 1
+	d.bitsPerSample = uint(packed>>36&0x1F) + 1
+	d.totalFrames = int64(packed & 0xFFFFFFFFF)
+	copy(d.md5[:], block[18:34])
+	if d.sampleRate == 0 {
+		return errFLACStreamInfo
+	}
+	return nil
+}
+
+func (d *FLACDecoder) decodeFrame() error {
+	br := &d.bits
+	d.position += int64(len(d.block) / d.channels)
+	d.block = d.block[:0]
+	d.blockRead = 0
+	if br.atEnd() {
+		return io.EOF
+	}
+	header, err := br.read(32)
+	if err != nil {
+		return err
+	}
+	if header>>18 != flacFrameSync {
+		return errFLACSync
+	}
+	blockCode := header >> 12 & 0xF
+	rateCode := header >> 8 & 0xF
+	channelCode := int(header >> 4 & 0xF)
+	sizeCode := header >> 1 & 0x7
+	if blockCode == 0 || rateCode == 15 || sizeCode == 3 || channelCode > flacChannelMidSide {
+		return errFLACReserved
+	}
+	if err := d.skipCodedNumber(); err != nil {
+		return err
+	}
+	blockSize := flacBlockSizes[blockCode]
+	switch blockCode {
+	case 6:
+		v, err := br.read(8)
+		if err != nil {
+			return err
+		}
+		blockSize = int(v) + 1
+	case 7:
+		v, err := br.read(16)
+		if err != nil {
+			return err
+		}
+		blockSize = int(v) + 1
+	}
+	// The sample rate from the STREAMINFO is used for playback, but the
+	// frame may still carry its own rate which needs to be skipped
+	switch rateCode {
+	case 12:
+		_, err = br.read(8)
+	case 13, 14:
+		_, err = br.read(16)
+	}
+	if err != nil {
+		return err
+	}
+	// CRC-8 of the frame header
+	if _, err := br.read(8); err != nil {
+		return err
+	}
+	bps := flacSampleSizes[sizeCode]
+	if bps == 0 {
+		bps = d.bitsPerSample
+	}
+	channels := channelCode + 1
+	if channelCode >= flacChannelLeftSide {
+		channels = 2
+	}
+	if channels != d.channels {
+		return errFLACReserved
+	}
+	for ch := 0; ch < channels; ch++ {
+		if cap(d.subframes[ch]) < blockSize {
+			d.subframes[ch] = make([]int64, max(blockSize, d.maxBlockSize))
+		}
+		d.subframes[ch] = d.subframes[ch][:blockSize]
+		chBps := bps
+		if (channelCode == flacChannelLeftSide || channelCode == flacChannelMidSide) && ch == 1 {
+			chBps++
+		} else if channelCode == flacChannelSideRight && ch == 0 {
+			chBps++
+		}
+		if err := d.decodeSubframe(d.subframes[ch], chBps); err != nil {
+			return err
+		}
+	}
+	br.align()
+	// CRC-16 of the whole frame
+	if _, err := br.read(16); err != nil {
+		return err
+	}
+	d.decorrelate(channelCode)
+	d.writeBlock(blockSize, bps)
+	return nil
+}
+
+// skipCodedNumber skips the UTF-8 style coded frame/sample number
+func (d *FLACDecoder) skipCodedNumber() error {
+	first, err := d.bits.read(8)
+	if err != nil {
+		return err
+	}
+	for mask := uint64(0x40); first&0x80 != 0 && first&mask != 0; mask >>= 1 {
+		if _, err := d.bits.read(8); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *FLACDecoder) decodeSubframe(out []int64, bps uint) error {
+	br := &d.bits
+	header, err := br.read(8)
+	if err != nil {
+		return err
+	}
+	if header&0x80 != 0 {
+		return errFLACReserved
+	}
+	kind := header >> 1 & 0x3F
+	wasted := uint(0)
+	if header&1 != 0 {
+		count, err := br.readUnary()
+		if err != nil {
+			return err
+		}
+		wasted = uint(count) + 1
+		bps -= wasted
+	}
+	switch {
+	case kind == 0:
+		v, err := br.readSigned(bps)
+		if err != nil {
+			return err
+		}
+		for i := range out {
+			out[i] = v
+		}
+	case kind == 1:
+		for i := range out {
+			if out[i], err = br.readSigned(bps); err != nil {
+				return err
+			}
+		}
+	case kind >= 8 && kind <= 12:
+		err = d.decodeFixed(out, int(kind-8), bps)
+	case kind >= 32:
+		err = d.decodeLPC(out, int(kind-31), bps)
+	default:
+		err = errFLACReserved
+	}
+	if err != nil {
+		return err
+	}
+	if wasted > 0 {
+		for i := range out {
+			out[i] <<= wasted
+		}
+	}
+	return nil
+}
+
+func (d *FLACDecoder) readWarmup(out []int64, order int, bps uint) error {
+	if order > len(out) {
+		return errFLACSampleCount
+	}
+	var err error
+	for i := 0; i < order; i++ {
+		if out[i], err = d.bits.readSigned(bps); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *FLACDecoder) decodeFixed(out []int64, order int, bps uint) error {
+	if err := d.readWarmup(out, order, bps); err != nil {
+		return err
+	}
+	if err := d.decodeResidual(out, order); err != nil {
+		return err
+	}
+	for i := order; i < len(out); i++ {
+		switch order {
+		case 1:
+			out[i] += out[i-1]
+		case 2:
+			out[i] += 2*out[i-1] - out[i-2]
+		case 3:
+			out[i] += 3*out[i-1] - 3*out[i-2] + out[i-3]
+		case 4:
+			out[i] += 4*out[i-1] - 6*out[i-2] + 4*out[i-3] - out[i-4]
+		}
+	}
+	return nil
+}
+
+func (d *FLACDecoder) decodeLPC(out []int64, order int, bps uint) error {
+	br := &d.bits
+	if err := d.readWarmup(out, order, bps); err != nil {
+		return err
+	}
+	precision, err := br.read(4)
+	if err != nil {
+		return err
+	}
+	if precision == 0xF {
+		return errFLACReserved
+	}
+	shift, err := br.readSigned(5)
+	if err != nil {
+		return err
+	}
+	if shift < 0 {
+		return errFLACReserved
+	}
+	var coefficients [32]int64
+	for i := 0; i < order; i++ {
+		if coefficients[i], err = br.readSigned(uint(precision) + 1); err != nil {
+			return err
+		}
+	}
+	if err := d.decodeResidual(out, order); err != nil {
+		return err
+	}
+	for i := order; i < len(out); i++ {
+		sum := int64(0)
+		for j := 0; j < order; j++ {
+			sum += coefficients[j] * out[i-1-j]
+		}
+		out[i] += sum >> shift
+	}
+	return nil
+}
+
+// decodeResidual reads the rice coded residual into out, starting after the
+// warmup samples of the predictor
+func (d *FLACDecoder) decodeResidual(out []int64, order int) error {
+	br := &d.bits
+	method, err := br.read(2)
+	if err != nil {
+		return err
+	}
+	if method > 1 {
+		return errFLACReserved
+	}
+	paramBits := uint(4 + method)
+	escape := uint64(1)<<paramBits - 1
+	partitionOrder, err := br.read(4)
+	if err != nil {
+		return err
+	}
+	partitions := 1 << partitionOrder
+	partitionSize := len(out) >> partitionOrder
+	idx := order
+	for p := 0; p < partitions; p++ {
+		count := partitionSize
+		if p == 0 {
+			count -= order
+		}
+		if count < 0 || idx+count > len(out) {
+			return errFLACSampleCount
+		}
+		param, err := br.read(paramBits)
+		if err != nil {
+			return err
+		}
+		if param == escape {
+			size, err := br.read(5)
+			if err != nil {
+				return err
+			}
+			for i := 0; i < count; i++ {
+				if out[idx], err = br.readSigned(uint(size)); err != nil {
+					return err
+				}
+				idx++
+			}
+			continue
+		}
+		k := uint(param)
+		for i := 0; i < count; i++ {
+			q, err := br.readUnary()
+			if err != nil {
+				return err
+			}
+			r, err := br.read(k)
+			if err != nil {
+				return err
+			}
+			u := q<<k | r
+			out[idx] = int64(u>>1) ^ -int64(u&1)
+			idx++
+		}
+	}
+	return nil
+}
+
+func (d *FLACDecoder) decorrelate(channelCode int) {
+	switch channelCode {
+	case flacChannelLeftSide:
+		left, side := d.subframes[0], d.subframes[1]
+		for i := range side {
+			side[i] = left[i] - side[i]
+		}
+	case flacChannelSideRight:
+		side, right := d.subframes[0], d.subframes[1]
+		for i := range side {
+			side[i] += right[i]
+		}
+	case flacChannelMidSide:
+		mid, side := d.subframes[0], d.subframes[1]
+		for i := range mid {
+			m := mid[i]<<1 | side[i]&1
+			mid[i] = (m + side[i]) >> 1
+			side[i] = (m - side[i]) >> 1
+		}
+	}
+}
+
+func (d *FLACDecoder) writeBlock(blockSize int, bps uint) {
+	size := blockSize * d.channels
+	if cap(d.block) < size {
+		d.block = make([]float32, size)
+	}
+	d.block = d.block[:size]
+	scale := 1 / float32(int64(1)<<(bps-1))
+	for ch, samples := range d.subframes {
+		for i, s := range samples {
+			d.block[i*d.channels+ch] = float32(s) * scale
+		}
+	}
+}
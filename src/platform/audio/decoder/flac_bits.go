@@ -0,0 +1,145 @@
+/******************************************************************************/
+/* flac_bits.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package decoder
+
+import (
+	"bufio"
+	"io"
+	"math/bits"
+)
+
+// flacBitReader reads big endian bit fields from a byte stream. Bits are kept
+// left aligned in a 64-bit cache which is refilled a byte at a time.
+type flacBitReader struct {
+	reader *bufio.Reader
+	cache  uint64
+	bits   uint
+	eof    bool
+}
+
+func (b *flacBitReader) reset(r io.Reader) {
+	if b.reader == nil {
+		b.reader = bufio.NewReader(r)
+	} else {
+		b.reader.Reset(r)
+	}
+	b.cache = 0
+	b.bits = 0
+	b.eof = false
+}
+
+func (b *flacBitReader) fill(count uint) error {
+	for b.bits < count {
+		if b.eof {
+			return io.ErrUnexpectedEOF
+		}
+		c, err := b.reader.ReadByte()
+		if err != nil {
+			b.eof = true
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		b.cache |= uint64(c) << (56 - b.bits)
+		b.bits += 8
+	}
+	return nil
+}
+
+// atEnd returns true if there are no more bits left in the stream
+func (b *flacBitReader) atEnd() bool {
+	if b.bits > 0 {
+		return false
+	}
+	_, err := b.reader.Peek(1)
+	return err != nil
+}
+
+// read returns the next count bits as an unsigned value, count must not be
+// larger than 56
+func (b *flacBitReader) read(count uint) (uint64, error) {
+	if count == 0 {
+		return 0, nil
+	}
+	if err := b.fill(count); err != nil {
+		return 0, err
+	}
+	v := b.cache >> (64 - count)
+	b.cache <<= count
+	b.bits -= count
+	return v, nil
+}
+
+// readSigned returns the next count bits as a two's complement value
+func (b *flacBitReader) readSigned(count uint) (int64, error) {
+	v, err := b.read(count)
+	if err != nil || count == 0 {
+		return 0, err
+	}
+	shift := 64 - count
+	return int64(v<<shift) >> shift, nil
+}
+
+// readUnary counts the number of 0 bits before the next 1 bit
+func (b *flacBitReader) readUnary() (uint64, error) {
+	count := uint64(0)
+	for {
+		if b.bits == 0 {
+			if err := b.fill(8); err != nil {
+				return 0, err
+			}
+		}
+		if b.cache == 0 {
+			count += uint64(b.bits)
+			b.bits = 0
+			continue
+		}
+		zeros := uint(bits.LeadingZeros64(b.cache))
+		b.cache <<= zeros + 1
+		b.bits -= zeros + 1
+		return count + uint64(zeros), nil
+	}
+}
+
+// align skips the remaining bits of the current byte
+func (b *flacBitReader) align() {
+	skip := b.bits % 8
+	b.cache <<= skip
+	b.bits -= skip
+}
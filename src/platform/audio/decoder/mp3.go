@@ -0,0 +1,125 @@
+/******************************************************************************/
+/* mp3.go                                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package decoder
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/hajimehoshi/go-mp3"
+)
+
+const (
+	// mp3Channels is the number of channels that the MP3 decoder always
+	// outputs, mono streams are duplicated into both channels
+	mp3Channels = 2
+	// mp3FrameBytes is the size of a single 16-bit stereo output frame
+	mp3FrameBytes = mp3Channels * 2
+	// mp3SeekPreroll is the number of frames decoded before the target of a
+	// seek. Layer III frames borrow data from the frames before them (the bit
+	// reservoir) and overlap with them, so decoding must start early to get
+	// the exact same samples as decoding from the start.
+	mp3SeekPreroll = 1152 * 3
+)
+
+// MP3Decoder decodes an MPEG-1/2 layer III stream. The output is always
+// stereo, mono streams will have the same samples in both channels.
+type MP3Decoder struct {
+	decoder *mp3.Decoder
+	buffer  []byte
+}
+
+// NewMP3 creates a decoder for the MP3 stream, the stream is scanned for its
+// frames immediately so that the length is known and invalid streams are
+// reported early
+func NewMP3(r io.ReadSeeker) (*MP3Decoder, error) {
+	decoder, err := mp3.NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+	return &MP3Decoder{decoder: decoder}, nil
+}
+
+// Channels returns the number of interleaved channels in the stream
+func (d *MP3Decoder) Channels() int { return mp3Channels }
+
+// SampleRate returns the number of frames per second of the stream
+func (d *MP3Decoder) SampleRate() int { return d.decoder.SampleRate() }
+
+// Length returns the total number of frames in the stream
+func (d *MP3Decoder) Length() int64 {
+	if l := d.decoder.Length(); l >= 0 {
+		return l / mp3FrameBytes
+	}
+	return -1
+}
+
+// Read fills the samples with the next interleaved samples of the stream
+func (d *MP3Decoder) Read(samples []float32) (int, error) {
+	samples = wholeFrames(samples, mp3Channels)
+	size := len(samples) * 2
+	if cap(d.buffer) < size {
+		d.buffer = make([]byte, size)
+	}
+	buff := d.buffer[:size]
+	n, err := io.ReadFull(d.decoder, buff)
+	n -= n % mp3FrameBytes
+	for i := 0; i < n/2; i++ {
+		samples[i] = float32(int16(binary.LittleEndian.Uint16(buff[i*2:]))) / math.MaxInt16
+	}
+	if err == io.ErrUnexpectedEOF || (err == io.EOF && n > 0) {
+		err = nil
+	}
+	return n / 2, err
+}
+
+// SetPosition moves the stream so that the next call to Read will start
+// at the given frame
+func (d *MP3Decoder) SetPosition(frame int64) error {
+	start := max(frame-mp3SeekPreroll, 0)
+	if _, err := d.decoder.Seek(start*mp3FrameBytes, io.SeekStart); err != nil {
+		return err
+	}
+	skip := (frame - start) * mp3FrameBytes
+	_, err := io.CopyN(io.Discard, d.decoder, skip)
+	if err == io.EOF {
+		err = nil
+	}
+	return err
+}
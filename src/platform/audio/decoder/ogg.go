@@ -0,0 +1,91 @@
+/******************************************************************************/
+/* ogg.go                                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package decoder
+
+import (
+	"io"
+
+	"github.com/jfreymuth/oggvorbis"
+)
+
+// OggDecoder decodes an Ogg Vorbis stream
+type OggDecoder struct {
+	reader *oggvorbis.Reader
+}
+
+// NewOgg creates a decoder for the Ogg Vorbis stream, the headers of the
+// stream are read immediately so that invalid streams are reported early
+func NewOgg(r io.ReadSeeker) (*OggDecoder, error) {
+	reader, err := oggvorbis.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &OggDecoder{reader: reader}, nil
+}
+
+// Channels returns the number of interleaved channels in the stream
+func (d *OggDecoder) Channels() int { return d.reader.Channels() }
+
+// SampleRate returns the number of frames per second of the stream
+func (d *OggDecoder) SampleRate() int { return d.reader.SampleRate() }
+
+// Length returns the total number of frames in the stream
+func (d *OggDecoder) Length() int64 { return d.reader.Length() }
+
+// Read fills the samples with the next interleaved samples of the stream
+func (d *OggDecoder) Read(samples []float32) (int, error) {
+	samples = wholeFrames(samples, d.Channels())
+	total := 0
+	for total < len(samples) {
+		n, err := d.reader.Read(samples[total:])
+		total += n
+		if err != nil {
+			if err == io.EOF && total > 0 {
+				return total, nil
+			}
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// SetPosition moves the stream so that the next call to Read will start
+// at the given frame
+func (d *OggDecoder) SetPosition(frame int64) error {
+	return d.reader.SetPosition(frame)
+}
@@ -0,0 +1,409 @@
+/******************************************************************************/
+/* stream.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package decoder
+
+import (
+	"io"
+	"kaiju/platform/audio/convert"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+const (
+	// DefaultStreamBufferFrames is the number of converted frames a stream
+	// keeps decoded ahead of playback when no buffer size is given
+	DefaultStreamBufferFrames = 1 << 15
+	// streamChunkFrames is the number of frames decoded at a time by the
+	// background goroutine of a stream
+	streamChunkFrames = 4096
+	// streamMaxChannels is the most channels a stream outputs, anything with
+	// more channels is mixed down to stereo
+	streamMaxChannels = 2
+)
+
+// StreamOptions are the settings used when creating a #Stream
+type StreamOptions struct {
+	// SampleRate is the rate the stream converts the decoded samples to, it
+	// should be the rate of the mixer. 0 keeps the rate of the decoder.
+	SampleRate int
+	// BufferFrames is the number of converted frames that are decoded ahead
+	// of playback, 0 uses #DefaultStreamBufferFrames
+	BufferFrames int
+	// Closer is closed once the stream no longer needs the decoder, typically
+	// this is the file that the decoder is reading from
+	Closer io.Closer
+}
+
+// streamSegment is a run of frames in the ring buffer that are contiguous in
+// the stream, a new segment starts every time the stream loops
+type streamSegment struct {
+	start  int64
+	frames int
+}
+
+// Stream decodes a #Decoder on a background goroutine into a fixed size ring
+// buffer so that long sounds, such as music, can be played without decoding
+// the whole thing into memory. The decoded samples are converted to the
+// sample rate of the stream and mixed down to at most 2 channels as they are
+// decoded. Stream implements #mixer.Streamer, so it is played by handing it
+// to #mixer.Mixer.PlayStream. Frame numbers used by the stream are in its
+// output sample rate.
+type Stream struct {
+	decoder    Decoder
+	closer     io.Closer
+	mutex      sync.Mutex
+	cond       *sync.Cond
+	ring       []float32
+	capacity   int
+	readIndex  int
+	count      int
+	segments   []streamSegment
+	position   int64
+	seekTo     int64
+	generation uint64
+	channels   int
+	sampleRate int
+	srcRate    int
+	srcLength  int64
+	loop       bool
+	loopStart  int64
+	loopEnd    int64
+	finished   bool
+	closed     bool
+}
+
+// NewStream creates a stream for the decoder and starts decoding it on a
+// background goroutine. The stream owns the decoder from this point on, it
+// should not be used by anything else. #Stream.Close must be called once the
+// stream is no longer needed, the mixer does this when its voice stops.
+func NewStream(d Decoder, options StreamOptions) *Stream {
+	if options.SampleRate <= 0 {
+		options.SampleRate = d.SampleRate()
+	}
+	if options.BufferFrames <= 0 {
+		options.BufferFrames = DefaultStreamBufferFrames
+	}
+	s := &Stream{
+		decoder:    d,
+		closer:     options.Closer,
+		capacity:   options.BufferFrames,
+		seekTo:     -1,
+		channels:   min(d.Channels(), streamMaxChannels),
+		sampleRate: options.SampleRate,
+		srcRate:    d.SampleRate(),
+		srcLength:  d.Length(),
+	}
+	s.cond = sync.NewCond(&s.mutex)
+	s.ring = make([]float32, s.capacity*s.channels)
+	go s.run()
+	return s
+}
+
+// Channels returns the number of interleaved channels in the stream
+func (s *Stream) Channels() int { return s.channels }
+
+// SampleRate returns the number of frames per second of the stream
+func (s *Stream) SampleRate() int { return s.sampleRate }
+
+// Length returns the total number of frames in the stream, or -1 if the
+// length is not known
+func (s *Stream) Length() int64 {
+	if s.srcLength >= 0 {
+		return s.toOutput(s.srcLength)
+	}
+	return -1
+}
+
+// BufferedFrames returns the number of frames that have been decoded but not
+// yet read
+func (s *Stream) BufferedFrames() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.count
+}
+
+// Position returns the frame of the stream that the next call to
+// #Stream.Read will start at
+func (s *Stream) Position() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.segments) > 0 {
+		return s.segments[0].start
+	}
+	return s.position
+}
+
+// Read fills the samples with the next interleaved frames that have been
+// decoded. Read never waits on the decoder, if nothing has been decoded yet
+// then 0 frames are returned. The start is the frame of the stream that the
+// first frame read belongs to and finished is true once the end of the
+// stream has been read.
+func (s *Stream) Read(samples []float32) (start int64, frames int, finished bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.count == 0 || len(s.segments) == 0 {
+		return s.position, 0, s.finished
+	}
+	segment := &s.segments[0]
+	start = segment.start
+	frames = min(len(samples)/s.channels, s.count, segment.frames)
+	first := min(frames, s.capacity-s.readIndex)
+	copy(samples, s.ring[s.readIndex*s.channels:(s.readIndex+first)*s.channels])
+	copy(samples[first*s.channels:], s.ring[:(frames-first)*s.channels])
+	s.readIndex = (s.readIndex + frames) % s.capacity
+	s.count -= frames
+	segment.start += int64(frames)
+	segment.frames -= frames
+	if segment.frames == 0 {
+		s.segments = slices.Delete(s.segments, 0, 1)
+	}
+	s.position = start + int64(frames)
+	s.cond.Broadcast()
+	return start, frames, s.finished && s.count == 0
+}
+
+// SetPosition drops all of the decoded frames and continues decoding from
+// the given frame, nothing can be read until the new frames are decoded
+func (s *Stream) SetPosition(frame int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.generation++
+	s.seekTo = max(frame, 0)
+	s.position = s.seekTo
+	s.readIndex, s.count = 0, 0
+	s.segments = s.segments[:0]
+	s.finished = false
+	s.cond.Broadcast()
+}
+
+// SetLoop sets if the stream should continue from the start frame when it
+// reaches the end frame rather than finishing. An end of 0 or less loops at
+// the end of the stream. Frames that are already decoded are not changed.
+func (s *Stream) SetLoop(loop bool, start, end int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.loop = loop
+	s.loopStart, s.loopEnd = max(start, 0), end
+	s.cond.Broadcast()
+}
+
+// Close stops decoding and releases the decoder, Close does not wait for the
+// background goroutine to finish. It is safe to call Close more than once.
+func (s *Stream) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+	s.cond.Broadcast()
+}
+
+func (s *Stream) toOutput(frame int64) int64 {
+	return frame * int64(s.sampleRate) / int64(s.srcRate)
+}
+
+func (s *Stream) toSource(frame int64) int64 {
+	return frame * int64(s.srcRate) / int64(s.sampleRate)
+}
+
+// run is the background goroutine that keeps the ring buffer full. The
+// decoder is only ever touched from here so it doesn't need to be safe to
+// use from multiple goroutines.
+func (s *Stream) run() {
+	defer func() {
+		if s.closer != nil {
+			if err := s.closer.Close(); err != nil {
+				slog.Error("failed to close the audio stream", "error", err)
+			}
+		}
+	}()
+	srcChannels := s.decoder.Channels()
+	conv := newStreamConverter(srcChannels, s.channels, s.srcRate, s.sampleRate)
+	read := make([]float32, streamChunkFrames*srcChannels)
+	var srcPosition, outPosition int64
+	jump := func(frame int64) bool {
+		srcPosition = s.toSource(frame)
+		outPosition = frame
+		if err := s.decoder.SetPosition(srcPosition); err != nil {
+			slog.Error("failed to set the position of the audio stream", "error", err)
+			return false
+		}
+		return true
+	}
+	for {
+		s.mutex.Lock()
+		for !s.closed && s.seekTo < 0 &&
+			((s.finished && !s.loop) || s.count == s.capacity) {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.mutex.Unlock()
+			return
+		}
+		seek := s.seekTo
+		s.seekTo = -1
+		generation := s.generation
+		srcLoopStart := s.toSource(s.loopStart)
+		srcLoopEnd := s.toSource(s.loopEnd)
+		if s.loopEnd <= 0 || (s.srcLength >= 0 && srcLoopEnd > s.srcLength) {
+			srcLoopEnd = s.srcLength
+		}
+		// A loop can't be played if it doesn't have any frames in it
+		loop := s.loop && (srcLoopEnd < 0 || srcLoopStart < srcLoopEnd)
+		restart := s.finished && loop
+		s.finished = s.finished && !restart
+		loopStart := s.loopStart
+		s.mutex.Unlock()
+		ok := true
+		if seek >= 0 {
+			conv.reset()
+			ok = jump(seek)
+		} else if restart {
+			conv.reset()
+			ok = jump(loopStart)
+		}
+		frames := int64(streamChunkFrames)
+		if loop && srcLoopEnd >= 0 {
+			frames = min(frames, srcLoopEnd-srcPosition)
+		}
+		var n int
+		var err error
+		if ok && frames > 0 {
+			n, err = s.decoder.Read(read[:frames*int64(srcChannels)])
+			n /= srcChannels
+			srcPosition += int64(n)
+		}
+		if err != nil && err != io.EOF {
+			slog.Error("failed to decode the audio stream", "error", err)
+			ok = false
+		}
+		if out := conv.convert(read[:n*srcChannels]); len(out) > 0 {
+			if !s.push(out, outPosition, generation) {
+				continue
+			}
+			outPosition += int64(len(out) / s.channels)
+		}
+		if !ok || frames <= 0 || n == 0 {
+			if ok && loop && jump(loopStart) {
+				continue
+			}
+			if out := conv.flush(); len(out) > 0 && !s.push(out, outPosition, generation) {
+				continue
+			}
+			s.mutex.Lock()
+			if generation == s.generation {
+				s.finished = true
+			}
+			s.mutex.Unlock()
+		}
+	}
+}
+
+// push writes the converted samples into the ring buffer, waiting for room
+// to be made if it is full. False is returned if the stream was closed or
+// moved to a new position while waiting, in which case the samples are
+// dropped.
+func (s *Stream) push(samples []float32, start int64, generation uint64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	frames := len(samples) / s.channels
+	for frames > 0 {
+		for !s.closed && generation == s.generation && s.count == s.capacity {
+			s.cond.Wait()
+		}
+		if s.closed || generation != s.generation {
+			return false
+		}
+		n := min(frames, s.capacity-s.count)
+		write := (s.readIndex + s.count) % s.capacity
+		first := min(n, s.capacity-write)
+		copy(s.ring[write*s.channels:], samples[:first*s.channels])
+		copy(s.ring, samples[first*s.channels:n*s.channels])
+		if last := len(s.segments) - 1; last >= 0 &&
+			s.segments[last].start+int64(s.segments[last].frames) == start {
+			s.segments[last].frames += n
+		} else {
+			s.segments = append(s.segments, streamSegment{start, n})
+		}
+		s.count += n
+		samples = samples[n*s.channels:]
+		frames -= n
+		start += int64(n)
+	}
+	return true
+}
+
+// streamConverter mixes the decoded samples down to the channels of the
+// stream and resamples them to its rate, one chunk at a time
+type streamConverter struct {
+	inChannels  int
+	outChannels int
+	matrix      [][]float32
+	resampler   *convert.Resampler
+	mixed       []float32
+	out         []float32
+}
+
+func newStreamConverter(inChannels, outChannels, inRate, outRate int) *streamConverter {
+	return &streamConverter{
+		inChannels:  inChannels,
+		outChannels: outChannels,
+		matrix:      convert.ChannelMatrix(inChannels, outChannels),
+		resampler:   convert.NewResampler(outChannels, inRate, outRate),
+	}
+}
+
+// reset forgets the previous chunks, used when the stream jumps to a
+// position that doesn't follow on from the last chunk. Loops don't reset
+// the converter so the filter carries on seamlessly across the loop.
+func (c *streamConverter) reset() { c.resampler.Reset() }
+
+func (c *streamConverter) convert(in []float32) []float32 {
+	if c.inChannels != c.outChannels {
+		c.mixed = convert.RechannelInto(in, c.inChannels, c.outChannels, c.matrix, c.mixed)
+		in = c.mixed
+	}
+	c.out = c.resampler.Process(in, c.out[:0])
+	return c.out
+}
+
+// flush returns the last frames held back by the resampler at the end of
+// the stream
+func (c *streamConverter) flush() []float32 {
+	c.out = c.resampler.Flush(c.out[:0])
+	return c.out
+}
@@ -0,0 +1,217 @@
+/******************************************************************************/
+/* stream_test.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package decoder
+
+import (
+	"io"
+	"math"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// rampDecoder is a decoder where every sample holds the frame number it
+// belongs to, which makes it easy to check which frames a stream produced
+type rampDecoder struct {
+	channels   int
+	sampleRate int
+	frames     int64
+	position   int64
+}
+
+func (d *rampDecoder) Channels() int   { return d.channels }
+func (d *rampDecoder) SampleRate() int { return d.sampleRate }
+func (d *rampDecoder) Length() int64   { return d.frames }
+
+func (d *rampDecoder) Read(samples []float32) (int, error) {
+	samples = wholeFrames(samples, d.channels)
+	n := min(int64(len(samples)/d.channels), d.frames-d.position)
+	if n <= 0 {
+		return 0, io.EOF
+	}
+	for i := 0; i < int(n)*d.channels; i++ {
+		samples[i] = float32(d.position + int64(i/d.channels))
+	}
+	d.position += n
+	return int(n) * d.channels, nil
+}
+
+func (d *rampDecoder) SetPosition(frame int64) error {
+	d.position = frame
+	return nil
+}
+
+// testReadStream reads the stream the same way the mixer does, waiting on
+// the background decoding whenever nothing is ready yet
+func testReadStream(t *testing.T, s *Stream, frames int, each func(start int64, samples []float32)) int {
+	t.Helper()
+	buff := make([]float32, 512*s.Channels())
+	total := 0
+	deadline := time.Now().Add(10 * time.Second)
+	for total < frames {
+		start, n, finished := s.Read(buff[:min(512, frames-total)*s.Channels()])
+		if n > 0 {
+			each(start, buff[:n*s.Channels()])
+			total += n
+		} else if finished {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the stream")
+		} else {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	return total
+}
+
+func TestStreamMatchesDecodeAll(t *testing.T) {
+	all := testDecodeAll(t, testOpen(t, "59996.flac"))
+	s := NewStream(testOpen(t, "59996.flac"), StreamOptions{BufferFrames: 1000})
+	defer s.Close()
+	var got []float32
+	testReadStream(t, s, len(all), func(start int64, samples []float32) {
+		if start != int64(len(got)/s.Channels()) {
+			t.Fatalf("expected frames starting at %d, got %d", len(got)/s.Channels(), start)
+		}
+		got = append(got, samples...)
+	})
+	testCompare(t, got, all, 0)
+	if _, n, finished := s.Read(make([]float32, 64)); n != 0 || !finished {
+		t.Error("expected the stream to be finished")
+	}
+}
+
+func TestStreamLoop(t *testing.T) {
+	const loopStart, loopEnd = 200, 600
+	s := NewStream(&rampDecoder{channels: 1, sampleRate: 100, frames: 1000},
+		StreamOptions{BufferFrames: 128})
+	defer s.Close()
+	s.SetLoop(true, loopStart, loopEnd)
+	expected := int64(0)
+	testReadStream(t, s, 3000, func(start int64, samples []float32) {
+		if start != expected {
+			t.Fatalf("expected frames starting at %d, got %d", expected, start)
+		}
+		for _, v := range samples {
+			if int64(v) != expected {
+				t.Fatalf("expected frame %d, got %d", expected, int64(v))
+			}
+			expected++
+			if expected == loopEnd {
+				expected = loopStart
+			}
+		}
+	})
+}
+
+func TestStreamSeek(t *testing.T) {
+	s := NewStream(&rampDecoder{channels: 2, sampleRate: 100, frames: 10000},
+		StreamOptions{BufferFrames: 256})
+	defer s.Close()
+	testReadStream(t, s, 100, func(int64, []float32) {})
+	s.SetPosition(5000)
+	if s.Position() != 5000 {
+		t.Fatalf("expected position 5000, got %d", s.Position())
+	}
+	first := true
+	testReadStream(t, s, 1, func(start int64, samples []float32) {
+		if first && (start != 5000 || samples[0] != 5000 || samples[1] != 5000) {
+			t.Fatalf("expected frame 5000 after seeking, got %d", start)
+		}
+		first = false
+	})
+}
+
+func TestStreamConvert(t *testing.T) {
+	// 4 channels at 100 Hz should come out as stereo at 200 Hz
+	s := NewStream(&rampDecoder{channels: 4, sampleRate: 100, frames: 1000},
+		StreamOptions{SampleRate: 200, BufferFrames: 256})
+	defer s.Close()
+	if s.Channels() != 2 || s.SampleRate() != 200 || s.Length() != 2000 {
+		t.Fatalf("unexpected format %d channels, %d hz, %d frames",
+			s.Channels(), s.SampleRate(), s.Length())
+	}
+	var got []float32
+	read := testReadStream(t, s, 3000, func(_ int64, samples []float32) {
+		got = append(got, samples...)
+	})
+	if read != 2000 {
+		t.Fatalf("expected 2000 frames, got %d", read)
+	}
+	// The ends are filtered against the silence around the sound
+	for i := 100; i < 1900; i++ {
+		expected := float32(i) / 2
+		if math.Abs(float64(got[i*2]-expected)) > 0.01 || got[i*2] != got[i*2+1] {
+			t.Fatalf("frame %d expected %f, got %f", i, expected, got[i*2])
+		}
+	}
+}
+
+func TestStreamBoundedMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("streams 5 minutes of audio")
+	}
+	const sampleRate = 48000
+	const frames = 5 * 60 * sampleRate
+	var before runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	s := NewStream(&rampDecoder{channels: 2, sampleRate: sampleRate, frames: frames},
+		StreamOptions{})
+	defer s.Close()
+	peak := uint64(0)
+	read := testReadStream(t, s, frames, func(start int64, _ []float32) {
+		if s.BufferedFrames() > DefaultStreamBufferFrames {
+			t.Fatal("the stream buffered more frames than its buffer size")
+		}
+		if start%(sampleRate*30) < 512 {
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			peak = max(peak, stats.HeapAlloc)
+		}
+	})
+	if read != frames {
+		t.Fatalf("expected %d frames, got %d", frames, read)
+	}
+	// The whole track would be 115MB of samples, the stream should only ever
+	// hold its buffer and a chunk of decoded samples
+	const limit = 16 << 20
+	if peak > before.HeapAlloc+limit {
+		t.Fatalf("stream used %d bytes, expected less than %d", peak-before.HeapAlloc, limit)
+	}
+}
@@ -0,0 +1,329 @@
+/******************************************************************************/
+/* wav.go                                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package decoder
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"kaiju/platform/audio/mixer"
+	"math"
+)
+
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+	// wavSamplerSize is the size of the smpl chunk header, each loop adds
+	// wavSampleLoopSize bytes after it
+	wavSamplerSize    = 36
+	wavSampleLoopSize = 24
+)
+
+// WavDecoder decodes a RIFF WAVE stream of 8, 16, 24 or 32-bit integer PCM
+// or 32 and 64-bit float samples. Unlike #audio_system.LoadWav, the samples
+// are read from the stream as they are needed rather than all at once.
+type WavDecoder struct {
+	reader        io.ReadSeeker
+	buffer        []byte
+	dataOffset    int64
+	frames        int64
+	position      int64
+	channels      int
+	sampleRate    int
+	bitsPerSample int
+	blockAlign    int
+	format        uint16
+	loopStart     int64
+	loopEnd       int64
+	hasLoop       bool
+}
+
+// NewWav creates a decoder for the WAVE stream, the chunks up to the start
+// of the sample data are read immediately so that invalid streams are
+// reported early. Loop points are read from a smpl chunk if there is one
+// before the sample data.
+func NewWav(r io.ReadSeeker) (*WavDecoder, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if string(header[:4]) != "RIFF" || string(header[8:]) != "WAVE" {
+		return nil, errors.New("the stream is not a RIFF WAVE file")
+	}
+	d := &WavDecoder{reader: r}
+	offset := int64(len(header))
+	hasFormat := false
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, errors.New("the WAVE file has no data chunk")
+		}
+		offset += int64(len(chunk))
+		size := int64(binary.LittleEndian.Uint32(chunk[4:]))
+		switch string(chunk[:4]) {
+		case "fmt ":
+			if size < 16 {
+				return nil, errors.New("the WAVE format chunk is too small")
+			}
+			fmtChunk := make([]byte, size)
+			if _, err := io.ReadFull(r, fmtChunk); err != nil {
+				return nil, err
+			}
+			if err := d.readFormat(fmtChunk); err != nil {
+				return nil, err
+			}
+			hasFormat = true
+		case "smpl":
+			smplChunk := make([]byte, size)
+			if _, err := io.ReadFull(r, smplChunk); err != nil {
+				return nil, err
+			}
+			d.readSampler(smplChunk)
+		case "data":
+			if !hasFormat {
+				return nil, errors.New("the WAVE data chunk comes before the format chunk")
+			}
+			d.dataOffset = offset
+			// Streaming writers leave the size as 0 or the max value when the
+			// length wasn't known, so fall back to the end of the stream
+			if end, err := r.Seek(0, io.SeekEnd); err == nil && (size == 0 || offset+size > end) {
+				size = end - offset
+			}
+			d.frames = size / int64(d.blockAlign)
+			if _, err := r.Seek(offset, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return d, nil
+		default:
+			if _, err := r.Seek(size, io.SeekCurrent); err != nil {
+				return nil, err
+			}
+		}
+		// Chunks are padded to an even number of bytes
+		if size%2 == 1 {
+			if _, err := r.Seek(1, io.SeekCurrent); err != nil {
+				return nil, err
+			}
+			size++
+		}
+		offset += size
+	}
+}
+
+func (d *WavDecoder) readFormat(chunk []byte) error {
+	d.format = binary.LittleEndian.Uint16(chunk)
+	d.channels = int(binary.LittleEndian.Uint16(chunk[2:]))
+	d.sampleRate = int(binary.LittleEndian.Uint32(chunk[4:]))
+	d.blockAlign = int(binary.LittleEndian.Uint16(chunk[12:]))
+	d.bitsPerSample = int(binary.LittleEndian.Uint16(chunk[14:]))
+	if d.format == wavFormatExtensible {
+		// The first two bytes of the sub format GUID hold the real format
+		if len(chunk) < 26 {
+			return errors.New("the WAVE extensible format chunk is too small")
+		}
+		d.format = binary.LittleEndian.Uint16(chunk[24:])
+	}
+	if d.channels <= 0 || d.sampleRate <= 0 {
+		return errors.New("the WAVE format has no channels or sample rate")
+	}
+	valid := false
+	switch d.format {
+	case wavFormatPCM:
+		valid = d.bitsPerSample == 8 || d.bitsPerSample == 16 ||
+			d.bitsPerSample == 24 || d.bitsPerSample == 32
+	case wavFormatFloat:
+		valid = d.bitsPerSample == 32 || d.bitsPerSample == 64
+	}
+	if !valid {
+		return ErrUnsupportedFormat
+	}
+	if minAlign := d.channels * d.bitsPerSample / 8; d.blockAlign < minAlign {
+		d.blockAlign = minAlign
+	}
+	return nil
+}
+
+// readSampler reads the first loop of the smpl chunk, other loops and the
+// MIDI details of the chunk aren't used
+func (d *WavDecoder) readSampler(chunk []byte) {
+	if len(chunk) < wavSamplerSize+wavSampleLoopSize {
+		return
+	}
+	if binary.LittleEndian.Uint32(chunk[28:]) == 0 {
+		return
+	}
+	loop := chunk[wavSamplerSize:]
+	start := int64(binary.LittleEndian.Uint32(loop[8:]))
+	// The end of a smpl loop is the last frame played rather than the frame
+	// after it
+	end := int64(binary.LittleEndian.Uint32(loop[12:])) + 1
+	if end > start {
+		d.loopStart, d.loopEnd, d.hasLoop = start, end, true
+	}
+}
+
+// LoopPoints returns the frames the stream is meant to loop between, ok is
+// false if the stream has no loop
+func (d *WavDecoder) LoopPoints() (start, end int64, ok bool) {
+	return d.loopStart, d.loopEnd, d.hasLoop
+}
+
+// Channels returns the number of interleaved channels in the stream
+func (d *WavDecoder) Channels() int { return d.channels }
+
+// SampleRate returns the number of frames per second of the stream
+func (d *WavDecoder) SampleRate() int { return d.sampleRate }
+
+// Length returns the total number of frames in the stream
+func (d *WavDecoder) Length() int64 { return d.frames }
+
+// Read fills the samples with the next interleaved samples of the stream
+func (d *WavDecoder) Read(samples []float32) (int, error) {
+	samples = wholeFrames(samples, d.channels)
+	frames := min(int64(len(samples)/d.channels), d.frames-d.position)
+	if frames <= 0 {
+		return 0, io.EOF
+	}
+	size := int(frames) * d.blockAlign
+	if cap(d.buffer) < size {
+		d.buffer = make([]byte, size)
+	}
+	buff := d.buffer[:size]
+	n, err := io.ReadFull(d.reader, buff)
+	frames = int64(n / d.blockAlign)
+	if frames == 0 {
+		if err == nil || err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return 0, err
+	}
+	bytesPerSample := d.bitsPerSample / 8
+	for f := 0; f < int(frames); f++ {
+		frame := buff[f*d.blockAlign:]
+		for c := 0; c < d.channels; c++ {
+			samples[f*d.channels+c] = d.sample(frame[c*bytesPerSample:])
+		}
+	}
+	d.position += frames
+	return int(frames) * d.channels, nil
+}
+
+func (d *WavDecoder) sample(b []byte) float32 {
+	switch d.bitsPerSample {
+	case 8:
+		// 8-bit samples are the only unsigned samples
+		return float32(int(b[0])-128) / 128
+	case 16:
+		return float32(int16(binary.LittleEndian.Uint16(b))) / math.MaxInt16
+	case 24:
+		s := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+		return float32(s) / (1 << 23)
+	case 32:
+		if d.format == wavFormatFloat {
+			return math.Float32frombits(binary.LittleEndian.Uint32(b))
+		}
+		return float32(float64(int32(binary.LittleEndian.Uint32(b))) / math.MaxInt32)
+	default:
+		return float32(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+	}
+}
+
+// SetPosition moves the stream so that the next call to Read will start
+// at the given frame
+func (d *WavDecoder) SetPosition(frame int64) error {
+	frame = max(0, min(frame, d.frames))
+	if _, err := d.reader.Seek(d.dataOffset+frame*int64(d.blockAlign), io.SeekStart); err != nil {
+		return err
+	}
+	d.position = frame
+	return nil
+}
+
+// EncodeWav writes the sound as a 32-bit float WAVE stream. If loopEnd is
+// past loopStart, the loop is written to a smpl chunk so that it can be read
+// back with #WavDecoder.LoopPoints.
+func EncodeWav(w io.Writer, sound *mixer.Sound, loopStart, loopEnd int64) error {
+	channels := sound.Channels()
+	samples := wholeFrames(sound.Samples(), channels)
+	dataSize := len(samples) * 4
+	hasLoop := loopEnd > loopStart && loopStart >= 0
+	riffSize := 4 + 8 + 16 + 8 + dataSize
+	if hasLoop {
+		riffSize += 8 + wavSamplerSize + wavSampleLoopSize
+	}
+	buff := make([]byte, 0, riffSize+8)
+	le := binary.LittleEndian
+	buff = append(buff, "RIFF"...)
+	buff = le.AppendUint32(buff, uint32(riffSize))
+	buff = append(buff, "WAVE"...)
+	buff = append(buff, "fmt "...)
+	buff = le.AppendUint32(buff, 16)
+	buff = le.AppendUint16(buff, wavFormatFloat)
+	buff = le.AppendUint16(buff, uint16(channels))
+	buff = le.AppendUint32(buff, uint32(sound.SampleRate()))
+	buff = le.AppendUint32(buff, uint32(sound.SampleRate()*channels*4))
+	buff = le.AppendUint16(buff, uint16(channels*4))
+	buff = le.AppendUint16(buff, 32)
+	if hasLoop {
+		buff = append(buff, "smpl"...)
+		buff = le.AppendUint32(buff, wavSamplerSize+wavSampleLoopSize)
+		buff = le.AppendUint32(buff, 0) // Manufacturer
+		buff = le.AppendUint32(buff, 0) // Product
+		buff = le.AppendUint32(buff, uint32(1e9/sound.SampleRate()))
+		buff = le.AppendUint32(buff, 60) // MIDI unity note (middle C)
+		buff = le.AppendUint32(buff, 0)  // MIDI pitch fraction
+		buff = le.AppendUint32(buff, 0)  // SMPTE format
+		buff = le.AppendUint32(buff, 0)  // SMPTE offset
+		buff = le.AppendUint32(buff, 1)  // Sample loops
+		buff = le.AppendUint32(buff, 0)  // Sampler data
+		buff = le.AppendUint32(buff, 0)  // Cue point ID
+		buff = le.AppendUint32(buff, 0)  // Forward loop
+		buff = le.AppendUint32(buff, uint32(loopStart))
+		buff = le.AppendUint32(buff, uint32(loopEnd-1))
+		buff = le.AppendUint32(buff, 0) // Fraction
+		buff = le.AppendUint32(buff, 0) // Play forever
+	}
+	buff = append(buff, "data"...)
+	buff = le.AppendUint32(buff, uint32(dataSize))
+	for _, v := range samples {
+		buff = le.AppendUint32(buff, math.Float32bits(v))
+	}
+	_, err := w.Write(buff)
+	return err
+}
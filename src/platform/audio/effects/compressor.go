@@ -0,0 +1,155 @@
+/******************************************************************************/
+/* compressor.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package effects
+
+import "math"
+
+// silenceDecibels is the level used in place of negative infinity when the
+// audio is silent
+const silenceDecibels = -200
+
+// Compressor reduces the volume of audio that is louder than its threshold,
+// evening out the level of a mix. A limiter is a compressor with an infinite
+// ratio and an instant attack, which stops the audio from ever going over
+// the threshold. Both sides are compressed together so that the stereo
+// image doesn't shift.
+type Compressor struct {
+	// Threshold is the level in decibels above which audio is compressed
+	Threshold Param
+	// Ratio is how many decibels over the threshold the input has to go for
+	// the output to go one decibel over, an infinite ratio limits the audio
+	Ratio Param
+	// Attack is the number of seconds the compressor takes to react to audio
+	// going over the threshold
+	Attack Param
+	// Release is the number of seconds the compressor takes to recover once
+	// the audio drops back under the threshold
+	Release Param
+	// Knee is the width in decibels of the soft transition around the
+	// threshold, 0 for a hard knee
+	Knee Param
+	// Makeup is the gain in decibels added after the compression to make up
+	// for the lost volume
+	Makeup    Param
+	reduction float64
+}
+
+// NewCompressor creates a compressor with a hard knee and no makeup gain,
+// the attack and release are in seconds
+func NewCompressor(threshold, ratio, attack, release float32) *Compressor {
+	return &Compressor{
+		Threshold: newParam(threshold),
+		Ratio:     newParam(ratio),
+		Attack:    newParam(attack),
+		Release:   newParam(release),
+		Knee:      newParam(0),
+		Makeup:    newParam(0),
+	}
+}
+
+// NewLimiter creates a compressor that keeps the audio at or under the
+// ceiling in decibels
+func NewLimiter(ceiling float32) *Compressor {
+	return NewCompressor(ceiling, float32(math.Inf(1)), 0, 0.05)
+}
+
+// GainReduction returns how many decibels the compressor is currently
+// turning the audio down by, this should only be used for metering
+func (c *Compressor) GainReduction() float32 { return float32(-c.reduction) }
+
+func (c *Compressor) Reset() { c.reduction = 0 }
+
+func (c *Compressor) Process(samples []float32, sampleRate int) {
+	for len(samples) > 0 {
+		var block []float32
+		block, samples = nextBlock(samples)
+		n := len(block) / channels
+		threshold := float64(c.Threshold.advance(n, sampleRate))
+		slope := 1 - 1/max(1, float64(c.Ratio.advance(n, sampleRate)))
+		attack := smoothing(c.Attack.advance(n, sampleRate), sampleRate)
+		release := smoothing(c.Release.advance(n, sampleRate), sampleRate)
+		knee := max(0, float64(c.Knee.advance(n, sampleRate)))
+		makeup := float64(c.Makeup.advance(n, sampleRate))
+		for i := 0; i < len(block); i += channels {
+			peak := math.Abs(float64(block[i]))
+			for ch := 1; ch < channels; ch++ {
+				peak = max(peak, math.Abs(float64(block[i+ch])))
+			}
+			level := float64(silenceDecibels)
+			if peak > 0 {
+				level = 20 * math.Log10(peak)
+			}
+			target := compressorReduction(level, threshold, slope, knee)
+			// The reduction is negative, so more reduction is an attack
+			if target < c.reduction {
+				c.reduction = target + (c.reduction-target)*attack
+			} else {
+				c.reduction = target + (c.reduction-target)*release
+			}
+			gain := float32(math.Pow(10, (c.reduction+makeup)/20))
+			for ch := range channels {
+				block[i+ch] *= gain
+			}
+		}
+	}
+}
+
+// compressorReduction returns the change in decibels for audio at the level,
+// where slope is 1-1/ratio
+func compressorReduction(level, threshold, slope, knee float64) float64 {
+	over := level - threshold
+	switch {
+	case 2*over < -knee:
+		return 0
+	case 2*math.Abs(over) <= knee:
+		// Ease into the ratio across the knee
+		x := over + knee/2
+		return -slope * x * x / (2 * knee)
+	default:
+		return -slope * over
+	}
+}
+
+// smoothing returns the coefficient of a one pole smoother that takes
+// roughly the given number of seconds to settle, 0 seconds is instant
+func smoothing(seconds float32, sampleRate int) float64 {
+	if seconds <= 0 {
+		return 0
+	}
+	return math.Exp(-1 / (float64(seconds) * float64(sampleRate)))
+}
@@ -0,0 +1,97 @@
+/******************************************************************************/
+/* delay.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package effects
+
+// MaxDelayTime is the longest time in seconds a #Delay can be set to
+const MaxDelayTime = 2
+
+// Delay repeats the audio after a set time, with each repeat fed back into
+// the delay at a lower volume to make a fading echo
+type Delay struct {
+	// Time is the number of seconds between each echo
+	Time Param
+	// Feedback is how much of each echo is fed back into the delay, from 0
+	// for a single echo up to just under 1 for a very long tail
+	Feedback Param
+	// Mix is the balance between the dry audio at 0 and the echoes at 1
+	Mix        Param
+	buffer     []float32
+	write      int
+	sampleRate int
+}
+
+// NewDelay creates an echo with the time between echoes in seconds
+func NewDelay(time, feedback, mix float32) *Delay {
+	return &Delay{
+		Time:     newParam(time),
+		Feedback: newParam(feedback),
+		Mix:      newParam(mix),
+	}
+}
+
+func (d *Delay) Reset() {
+	clear(d.buffer)
+	d.write = 0
+}
+
+func (d *Delay) Process(samples []float32, sampleRate int) {
+	if sampleRate != d.sampleRate {
+		d.sampleRate = sampleRate
+		d.buffer = make([]float32, (MaxDelayTime*sampleRate+1)*channels)
+		d.write = 0
+	}
+	frames := len(d.buffer) / channels
+	for len(samples) > 0 {
+		var block []float32
+		block, samples = nextBlock(samples)
+		n := len(block) / channels
+		time := d.Time.advance(n, sampleRate)
+		feedback := max(0, min(d.Feedback.advance(n, sampleRate), 0.99))
+		mix := max(0, min(d.Mix.advance(n, sampleRate), 1))
+		delay := max(1, min(int(time*float32(sampleRate)+0.5), frames-1))
+		for i := 0; i < len(block); i += channels {
+			read := (d.write - delay + frames) % frames
+			for c := range channels {
+				echo := d.buffer[read*channels+c]
+				d.buffer[d.write*channels+c] = block[i+c] + echo*feedback
+				block[i+c] = block[i+c]*(1-mix) + echo*mix
+			}
+			d.write = (d.write + 1) % frames
+		}
+	}
+}
@@ -0,0 +1,245 @@
+/******************************************************************************/
+/* effects_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package effects
+
+import (
+	"kaiju/platform/audio/mixer"
+	"math"
+	"testing"
+)
+
+const testRate = 48000
+
+var (
+	_ mixer.Effect = (*Filter)(nil)
+	_ mixer.Effect = (*EQ)(nil)
+	_ mixer.Effect = (*Delay)(nil)
+	_ mixer.Effect = (*Reverb)(nil)
+	_ mixer.Effect = (*Compressor)(nil)
+)
+
+// testSine returns a stereo buffer with the same tone on both sides
+func testSine(frequency, amplitude float64, frames int) []float32 {
+	samples := make([]float32, frames*channels)
+	for i := range frames {
+		v := float32(amplitude * math.Sin(2*math.Pi*frequency*float64(i)/testRate))
+		samples[i*2], samples[i*2+1] = v, v
+	}
+	return samples
+}
+
+// testPeak returns the loudest sample after skipping the frames it takes a
+// filter to settle
+func testPeak(samples []float32, skipFrames int) float64 {
+	peak := 0.0
+	for _, s := range samples[skipFrames*channels:] {
+		peak = max(peak, math.Abs(float64(s)))
+	}
+	return peak
+}
+
+func testApprox(t *testing.T, name string, got, expected, tolerance float64) {
+	t.Helper()
+	if math.Abs(got-expected) > tolerance {
+		t.Errorf("%s expected %f, got %f", name, expected, got)
+	}
+}
+
+func TestLowPass(t *testing.T) {
+	f := NewLowPass(1000)
+	low := testSine(100, 1, testRate/10)
+	f.Process(low, testRate)
+	testApprox(t, "pass band", testPeak(low, 1000), 1, 0.01)
+	f.Reset()
+	high := testSine(10000, 1, testRate/10)
+	f.Process(high, testRate)
+	if peak := testPeak(high, 1000); peak > 0.02 {
+		t.Errorf("expected the high tone to be removed, got a peak of %f", peak)
+	}
+	testApprox(t, "cutoff response", float64(f.Response(1000, testRate)), math.Sqrt2/2, 0.001)
+}
+
+func TestHighPass(t *testing.T) {
+	f := NewHighPass(1000)
+	low := testSine(50, 1, testRate/10)
+	f.Process(low, testRate)
+	if peak := testPeak(low, 2000); peak > 0.01 {
+		t.Errorf("expected the low tone to be removed, got a peak of %f", peak)
+	}
+	f.Reset()
+	high := testSine(10000, 1, testRate/10)
+	f.Process(high, testRate)
+	testApprox(t, "pass band", testPeak(high, 1000), 1, 0.01)
+}
+
+func TestFilterAutomation(t *testing.T) {
+	f := NewLowPass(20000)
+	f.Frequency.RampTo(200, 0.5)
+	if !f.Frequency.IsRamping() {
+		t.Fatal("expected the frequency to be ramping")
+	}
+	samples := testSine(5000, 1, testRate/4)
+	f.Process(samples, testRate)
+	testApprox(t, "half way", float64(f.Frequency.Value()), 10100, 100)
+	f.Process(testSine(5000, 1, testRate/4), testRate)
+	if f.Frequency.Value() != 200 || f.Frequency.IsRamping() {
+		t.Errorf("expected the ramp to finish at 200, got %f", f.Frequency.Value())
+	}
+	samples = testSine(5000, 1, testRate/10)
+	f.Process(samples, testRate)
+	if peak := testPeak(samples, 500); peak > 0.01 {
+		t.Errorf("expected the automated filter to close, got a peak of %f", peak)
+	}
+}
+
+func TestEQ(t *testing.T) {
+	eq := NewEQ(
+		NewFilter(FilterLowShelf, 200, DefaultQ, -12),
+		NewEQBand(1000, 1, 6),
+		NewFilter(FilterHighShelf, 8000, DefaultQ, 3),
+	)
+	testApprox(t, "low shelf", float64(GainToDecibels(eq.Response(20, testRate))), -12, 0.1)
+	testApprox(t, "peak", float64(GainToDecibels(eq.Response(1000, testRate))), 6, 0.2)
+	testApprox(t, "high shelf", float64(GainToDecibels(eq.Response(20000, testRate))), 3, 0.1)
+	samples := testSine(1000, 0.25, testRate/10)
+	eq.Process(samples, testRate)
+	testApprox(t, "boosted tone", testPeak(samples, 1000), 0.25*float64(eq.Response(1000, testRate)), 0.005)
+	eq.Band(1).Gain.Set(0)
+	samples = testSine(1000, 0.25, testRate/10)
+	eq.Process(samples, testRate)
+	testApprox(t, "flat band", testPeak(samples, 1000), 0.25*float64(eq.Response(1000, testRate)), 0.005)
+}
+
+func TestDelay(t *testing.T) {
+	d := NewDelay(0.01, 0.5, 0.5)
+	samples := make([]float32, 2000*channels)
+	samples[0], samples[1] = 1, 1
+	d.Process(samples, testRate)
+	// 10ms at 48kHz is 480 frames
+	expected := map[int]float32{0: 0.5, 480: 0.5, 960: 0.25, 1440: 0.125, 1920: 0.0625}
+	for i := 0; i < 2000; i++ {
+		if samples[i*2] != expected[i] || samples[i*2+1] != expected[i] {
+			t.Fatalf("frame %d expected %f, got %f", i, expected[i], samples[i*2])
+		}
+	}
+	d.Reset()
+	samples = make([]float32, 1000*channels)
+	d.Process(samples, testRate)
+	if testPeak(samples, 0) != 0 {
+		t.Error("expected the echoes to be cleared by the reset")
+	}
+}
+
+func TestReverb(t *testing.T) {
+	r := NewReverb(0.8, 0.5, 1)
+	samples := make([]float32, testRate*channels)
+	samples[0], samples[1] = 1, 1
+	r.Process(samples, testRate)
+	if samples[0] != 0 {
+		t.Errorf("expected a fully wet reverb to remove the dry impulse, got %f", samples[0])
+	}
+	early := testPeak(samples[:testRate/10*channels], 0)
+	late := testPeak(samples[testRate*9/10*channels:], 0)
+	if early == 0 || late >= early {
+		t.Errorf("expected the reverb tail to fade, got %f early and %f late", early, late)
+	}
+	if samples[testRate/10*2] == samples[testRate/10*2+1] {
+		t.Error("expected the two sides of the reverb to differ")
+	}
+	r.Reset()
+	r.Mix.Set(0)
+	dry := testSine(440, 0.5, 1000)
+	samples = append([]float32{}, dry...)
+	r.Process(samples, testRate)
+	for i := range dry {
+		if samples[i] != dry[i] {
+			t.Fatalf("sample %d expected a dry reverb to pass through, got %f", i, samples[i])
+		}
+	}
+}
+
+func TestCompressor(t *testing.T) {
+	c := NewCompressor(-20, 4, 0.001, 0.1)
+	// A 0dB tone is 20dB over, so should come out 15dB down
+	samples := testSine(1000, 1, testRate/2)
+	c.Process(samples, testRate)
+	testApprox(t, "compressed", float64(GainToDecibels(float32(testPeak(samples, testRate/4)))), -15, 0.5)
+	testApprox(t, "reduction", float64(c.GainReduction()), 15, 0.5)
+	// Quiet audio under the threshold is left alone once released
+	samples = testSine(1000, 0.05, testRate)
+	c.Process(samples, testRate)
+	testApprox(t, "under threshold", testPeak(samples, testRate/2), 0.05, 0.001)
+	c.Makeup.Set(6)
+	samples = testSine(1000, 0.05, testRate/10)
+	c.Process(samples, testRate)
+	testApprox(t, "makeup", testPeak(samples, 100), 0.05*float64(DecibelsToGain(6)), 0.001)
+}
+
+func TestCompressorKnee(t *testing.T) {
+	testApprox(t, "below knee", compressorReduction(-30, -20, 0.5, 10), 0, 0)
+	testApprox(t, "at threshold", compressorReduction(-20, -20, 0.5, 10), -0.625, 0.0001)
+	testApprox(t, "above knee", compressorReduction(-10, -20, 0.5, 10), -5, 0.0001)
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(-6)
+	ceiling := float64(DecibelsToGain(-6))
+	samples := testSine(200, 2, testRate/10)
+	l.Process(samples, testRate)
+	if peak := testPeak(samples, 0); peak > ceiling+0.0001 {
+		t.Errorf("expected the limiter to hold the ceiling of %f, got %f", ceiling, peak)
+	}
+	testApprox(t, "limited peak", testPeak(samples, 0), ceiling, 0.001)
+}
+
+func TestParamRamp(t *testing.T) {
+	p := newParam(0)
+	p.RampTo(1, 1)
+	if v := p.advance(testRate/4, testRate); v != 0 {
+		t.Errorf("expected the ramp to start at 0, got %f", v)
+	}
+	testApprox(t, "quarter", float64(p.Value()), 0.25, 0.0001)
+	p.advance(testRate, testRate)
+	if p.Value() != 1 || p.IsRamping() || p.Target() != 1 {
+		t.Errorf("expected the ramp to finish at 1, got %f", p.Value())
+	}
+	p.RampTo(0.5, 0)
+	if p.Value() != 0.5 {
+		t.Errorf("expected a ramp of no time to set the value, got %f", p.Value())
+	}
+}
@@ -0,0 +1,84 @@
+/******************************************************************************/
+/* eq.go                                                                      */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package effects
+
+// EQ is a parametric equalizer made of any number of filter bands that the
+// audio passes through in order. Bands are typically a low shelf, a few
+// peaks and a high shelf.
+type EQ struct {
+	bands []*Filter
+}
+
+// NewEQ creates an equalizer from the bands, the bands can be changed
+// through their parameters while the EQ is playing
+func NewEQ(bands ...*Filter) *EQ {
+	return &EQ{bands: bands}
+}
+
+// NewEQBand creates a peak filter that boosts or cuts by gain decibels
+// around the frequency, the Q sets how narrow the band is
+func NewEQBand(frequency, q, gain float32) *Filter {
+	return NewFilter(FilterPeak, frequency, q, gain)
+}
+
+// Bands returns the filters of the equalizer
+func (e *EQ) Bands() []*Filter { return e.bands }
+
+// Band returns the filter of the band at the given index
+func (e *EQ) Band(index int) *Filter { return e.bands[index] }
+
+func (e *EQ) Reset() {
+	for _, b := range e.bands {
+		b.Reset()
+	}
+}
+
+func (e *EQ) Process(samples []float32, sampleRate int) {
+	for _, b := range e.bands {
+		b.Process(samples, sampleRate)
+	}
+}
+
+// Response returns the combined gain of all of the bands at the frequency
+func (e *EQ) Response(frequency float32, sampleRate int) float32 {
+	gain := float32(1)
+	for _, b := range e.bands {
+		gain *= b.Response(frequency, sampleRate)
+	}
+	return gain
+}
@@ -0,0 +1,210 @@
+/******************************************************************************/
+/* filter.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package effects
+
+import "math"
+
+type FilterType int
+
+const (
+	FilterLowPass = FilterType(iota)
+	FilterHighPass
+	FilterBandPass
+	FilterNotch
+	FilterPeak
+	FilterLowShelf
+	FilterHighShelf
+)
+
+const (
+	// DefaultQ is the Q of a Butterworth filter, which is as flat as
+	// possible in the pass band without a resonant peak
+	DefaultQ = math.Sqrt2 / 2
+	// minFrequency keeps the filter away from 0Hz where it falls apart
+	minFrequency = 1
+)
+
+// biquad holds the normalized coefficients of a second order filter
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+}
+
+// biquadState is the history of one channel through a biquad, stored in the
+// transposed direct form II
+type biquadState struct {
+	z1, z2 float64
+}
+
+func (s *biquadState) process(c *biquad, x float64) float64 {
+	y := c.b0*x + s.z1
+	s.z1 = c.b1*x - c.a1*y + s.z2
+	s.z2 = c.b2*x - c.a2*y
+	return y
+}
+
+// Filter is a biquad filter following the formulas of the Audio EQ Cookbook.
+// Low and high pass filters are what are typically used for muffling sounds
+// (such as when under water) or thinning them out (such as over a radio),
+// while the peak and shelf filters are the bands of an #EQ.
+type Filter struct {
+	// Frequency is the cutoff or center frequency of the filter in Hz
+	Frequency Param
+	// Q is the resonance of the filter, or the width of a band for the band
+	// pass, notch and peak filters
+	Q Param
+	// Gain is the boost or cut in decibels of the peak and shelf filters,
+	// the other filters ignore it
+	Gain       Param
+	filterType FilterType
+	coeffs     biquad
+	frequency  float32
+	q          float32
+	gain       float32
+	sampleRate int
+	state      [channels]biquadState
+}
+
+// NewFilter creates a biquad filter of the given type
+func NewFilter(filterType FilterType, frequency, q, gain float32) *Filter {
+	return &Filter{
+		Frequency:  newParam(frequency),
+		Q:          newParam(q),
+		Gain:       newParam(gain),
+		filterType: filterType,
+	}
+}
+
+// NewLowPass creates a filter that removes frequencies above the cutoff
+func NewLowPass(cutoff float32) *Filter {
+	return NewFilter(FilterLowPass, cutoff, DefaultQ, 0)
+}
+
+// NewHighPass creates a filter that removes frequencies below the cutoff
+func NewHighPass(cutoff float32) *Filter {
+	return NewFilter(FilterHighPass, cutoff, DefaultQ, 0)
+}
+
+// Type returns the type of the filter
+func (f *Filter) Type() FilterType { return f.filterType }
+
+func (f *Filter) Reset() { f.state = [channels]biquadState{} }
+
+func (f *Filter) Process(samples []float32, sampleRate int) {
+	for len(samples) > 0 {
+		var block []float32
+		block, samples = nextBlock(samples)
+		frames := len(block) / channels
+		f.update(f.Frequency.advance(frames, sampleRate), f.Q.advance(frames, sampleRate),
+			f.Gain.advance(frames, sampleRate), sampleRate)
+		for i := 0; i < len(block); i += channels {
+			for c := range channels {
+				block[i+c] = float32(f.state[c].process(&f.coeffs, float64(block[i+c])))
+			}
+		}
+	}
+}
+
+// Response returns the gain of the filter at the given frequency for its
+// current settings, this is useful for drawing the curve of an EQ
+func (f *Filter) Response(frequency float32, sampleRate int) float32 {
+	c := filterCoefficients(f.filterType, f.Frequency.Value(), f.Q.Value(), f.Gain.Value(), sampleRate)
+	w := 2 * math.Pi * float64(frequency) / float64(sampleRate)
+	// Evaluate the transfer function on the unit circle, z = e^(jw)
+	cos1, sin1 := math.Cos(w), math.Sin(w)
+	cos2, sin2 := math.Cos(2*w), math.Sin(2*w)
+	numRe := c.b0 + c.b1*cos1 + c.b2*cos2
+	numIm := -c.b1*sin1 - c.b2*sin2
+	denRe := 1 + c.a1*cos1 + c.a2*cos2
+	denIm := -c.a1*sin1 - c.a2*sin2
+	return float32(math.Sqrt((numRe*numRe + numIm*numIm) / (denRe*denRe + denIm*denIm)))
+}
+
+// update only works out the coefficients again when a setting has changed
+func (f *Filter) update(frequency, q, gain float32, sampleRate int) {
+	if frequency == f.frequency && q == f.q && gain == f.gain && sampleRate == f.sampleRate {
+		return
+	}
+	f.frequency, f.q, f.gain, f.sampleRate = frequency, q, gain, sampleRate
+	f.coeffs = filterCoefficients(f.filterType, frequency, q, gain, sampleRate)
+}
+
+func filterCoefficients(filterType FilterType, frequency, q, gain float32, sampleRate int) biquad {
+	nyquist := float64(sampleRate) / 2
+	freq := max(minFrequency, min(float64(frequency), nyquist*0.99))
+	w0 := 2 * math.Pi * freq / float64(sampleRate)
+	cos, sin := math.Cos(w0), math.Sin(w0)
+	alpha := sin / (2 * max(float64(q), 0.01))
+	a := math.Pow(10, float64(gain)/40)
+	var b0, b1, b2, a0, a1, a2 float64
+	switch filterType {
+	case FilterLowPass:
+		b0, b1, b2 = (1-cos)/2, 1-cos, (1-cos)/2
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case FilterHighPass:
+		b0, b1, b2 = (1+cos)/2, -(1 + cos), (1+cos)/2
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case FilterBandPass:
+		b0, b1, b2 = alpha, 0, -alpha
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case FilterNotch:
+		b0, b1, b2 = 1, -2*cos, 1
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case FilterPeak:
+		b0, b1, b2 = 1+alpha*a, -2*cos, 1-alpha*a
+		a0, a1, a2 = 1+alpha/a, -2*cos, 1-alpha/a
+	case FilterLowShelf:
+		s := 2 * math.Sqrt(a) * alpha
+		b0 = a * ((a + 1) - (a-1)*cos + s)
+		b1 = 2 * a * ((a - 1) - (a+1)*cos)
+		b2 = a * ((a + 1) - (a-1)*cos - s)
+		a0 = (a + 1) + (a-1)*cos + s
+		a1 = -2 * ((a - 1) + (a+1)*cos)
+		a2 = (a + 1) + (a-1)*cos - s
+	case FilterHighShelf:
+		s := 2 * math.Sqrt(a) * alpha
+		b0 = a * ((a + 1) + (a-1)*cos + s)
+		b1 = -2 * a * ((a - 1) + (a+1)*cos)
+		b2 = a * ((a + 1) + (a-1)*cos - s)
+		a0 = (a + 1) - (a-1)*cos + s
+		a1 = 2 * ((a - 1) - (a+1)*cos)
+		a2 = (a + 1) - (a-1)*cos - s
+	default:
+		return biquad{b0: 1}
+	}
+	return biquad{b0: b0 / a0, b1: b1 / a0, b2: b2 / a0, a1: a1 / a0, a2: a2 / a0}
+}
@@ -0,0 +1,127 @@
+/******************************************************************************/
+/* param.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package effects
+
+import "sync"
+
+// blockFrames is how many frames are processed between reading the
+// parameters of an effect, so that automated parameters change smoothly
+// even when the mixer renders large buffers
+const blockFrames = 64
+
+// Param is a setting of an effect that can be changed or automated from any
+// goroutine while the mixer is rendering. A ramp moves the parameter to a
+// new value over time, which is what should be used for audible changes such
+// as opening up a low pass filter when leaving the water.
+type Param struct {
+	mutex   sync.Mutex
+	value   float32
+	start   float32
+	target  float32
+	time    float64
+	elapsed float64
+}
+
+func newParam(value float32) Param {
+	return Param{value: value, start: value, target: value}
+}
+
+// Value returns the current value of the parameter, this will be part way
+// between the start and end of a ramp that is in progress
+func (p *Param) Value() float32 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.value
+}
+
+// Target returns the value the parameter is ramping to, or the current value
+// if it isn't ramping
+func (p *Param) Target() float32 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.target
+}
+
+// Set changes the value of the parameter immediately, cancelling any ramp
+func (p *Param) Set(value float32) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.value, p.start, p.target = value, value, value
+	p.time, p.elapsed = 0, 0
+}
+
+// RampTo moves the parameter linearly from its current value to the new
+// value over the given number of seconds of audio
+func (p *Param) RampTo(value float32, seconds float64) {
+	if seconds <= 0 {
+		p.Set(value)
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.start, p.target = p.value, value
+	p.time, p.elapsed = seconds, 0
+}
+
+// IsRamping returns true if the parameter hasn't finished a ramp yet
+func (p *Param) IsRamping() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.elapsed < p.time
+}
+
+// advance returns the value to use for the next frames and then moves any
+// ramp along by the time those frames take to play
+func (p *Param) advance(frames, sampleRate int) float32 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	v := p.value
+	if p.elapsed < p.time {
+		p.elapsed += float64(frames) / float64(sampleRate)
+		t := float32(min(p.elapsed/p.time, 1))
+		p.value = p.start + (p.target-p.start)*t
+	}
+	return v
+}
+
+// nextBlock returns the samples of the next block of frames to process and
+// the remaining samples after it
+func nextBlock(samples []float32) ([]float32, []float32) {
+	n := min(len(samples), blockFrames*channels)
+	return samples[:n], samples[n:]
+}
@@ -0,0 +1,178 @@
+/******************************************************************************/
+/* reverb.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package effects
+
+// The Freeverb tunings, given in frames at 44.1kHz
+var (
+	reverbCombTuning    = [...]int{1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617}
+	reverbAllPassTuning = [...]int{556, 441, 341, 225}
+)
+
+const (
+	reverbTuningRate   = 44100
+	reverbStereoSpread = 23
+	reverbFixedGain    = 0.015
+	reverbScaleWet     = 3
+	reverbScaleRoom    = 0.28
+	reverbOffsetRoom   = 0.7
+	reverbScaleDamping = 0.4
+	reverbAllPassGain  = 0.5
+)
+
+// reverbComb is a feedback comb filter with a low pass in its feedback,
+// which is what makes the high frequencies die out before the low ones
+type reverbComb struct {
+	buffer []float32
+	index  int
+	store  float32
+}
+
+func (c *reverbComb) process(in, feedback, damping float32) float32 {
+	out := c.buffer[c.index]
+	c.store = out*(1-damping) + c.store*damping
+	c.buffer[c.index] = in + c.store*feedback
+	c.index = (c.index + 1) % len(c.buffer)
+	return out
+}
+
+// reverbAllPass spreads the echoes of the combs out in time without
+// changing their color
+type reverbAllPass struct {
+	buffer []float32
+	index  int
+}
+
+func (a *reverbAllPass) process(in float32) float32 {
+	delayed := a.buffer[a.index]
+	a.buffer[a.index] = in + delayed*reverbAllPassGain
+	a.index = (a.index + 1) % len(a.buffer)
+	return delayed - in
+}
+
+// Reverb simulates the reflections of a room with the Freeverb algorithm, a
+// set of parallel comb filters followed by a chain of all pass filters (as
+// described by Schroeder) for each side
+type Reverb struct {
+	// RoomSize sets how long the reverb takes to die out, from 0 for a small
+	// room to 1 for a large hall or cave
+	RoomSize Param
+	// Damping is how quickly the high frequencies die out, from 0 for hard
+	// walls to 1 for soft walls
+	Damping Param
+	// Width is how far apart the left and right reverb are, from 0 for mono
+	// to 1 for full stereo
+	Width Param
+	// Mix is the balance between the dry audio at 0 and the reverb at 1
+	Mix        Param
+	combs      [channels][len(reverbCombTuning)]reverbComb
+	allPasses  [channels][len(reverbAllPassTuning)]reverbAllPass
+	sampleRate int
+}
+
+// NewReverb creates a reverb, all of the settings range from 0 to 1
+func NewReverb(roomSize, damping, mix float32) *Reverb {
+	return &Reverb{
+		RoomSize: newParam(roomSize),
+		Damping:  newParam(damping),
+		Width:    newParam(1),
+		Mix:      newParam(mix),
+	}
+}
+
+func (r *Reverb) Reset() {
+	for c := range channels {
+		for i := range r.combs[c] {
+			clear(r.combs[c][i].buffer)
+			r.combs[c][i].store = 0
+		}
+		for i := range r.allPasses[c] {
+			clear(r.allPasses[c][i].buffer)
+		}
+	}
+}
+
+// setup sizes the filters for the sample rate, the right side is spread a
+// little longer than the left so that the two sides don't match
+func (r *Reverb) setup(sampleRate int) {
+	r.sampleRate = sampleRate
+	scale := float64(sampleRate) / reverbTuningRate
+	for c := range channels {
+		spread := c * reverbStereoSpread
+		for i, t := range reverbCombTuning {
+			size := max(1, int(float64(t+spread)*scale))
+			r.combs[c][i] = reverbComb{buffer: make([]float32, size)}
+		}
+		for i, t := range reverbAllPassTuning {
+			size := max(1, int(float64(t+spread)*scale))
+			r.allPasses[c][i] = reverbAllPass{buffer: make([]float32, size)}
+		}
+	}
+}
+
+func (r *Reverb) Process(samples []float32, sampleRate int) {
+	if sampleRate != r.sampleRate {
+		r.setup(sampleRate)
+	}
+	for len(samples) > 0 {
+		var block []float32
+		block, samples = nextBlock(samples)
+		n := len(block) / channels
+		room := max(0, min(r.RoomSize.advance(n, sampleRate), 1))
+		feedback := room*reverbScaleRoom + reverbOffsetRoom
+		damping := max(0, min(r.Damping.advance(n, sampleRate), 1)) * reverbScaleDamping
+		width := max(0, min(r.Width.advance(n, sampleRate), 1))
+		mix := max(0, min(r.Mix.advance(n, sampleRate), 1))
+		wet := mix * reverbScaleWet
+		wet1 := wet * (width/2 + 0.5)
+		wet2 := wet * (1 - width) / 2
+		for i := 0; i < len(block); i += channels {
+			in := (block[i] + block[i+1]) * reverbFixedGain
+			var out [channels]float32
+			for c := range channels {
+				for j := range r.combs[c] {
+					out[c] += r.combs[c][j].process(in, feedback, damping)
+				}
+				for j := range r.allPasses[c] {
+					out[c] = r.allPasses[c][j].process(out[c])
+				}
+			}
+			block[i] = block[i]*(1-mix) + out[0]*wet1 + out[1]*wet2
+			block[i+1] = block[i+1]*(1-mix) + out[1]*wet1 + out[0]*wet2
+		}
+	}
+}
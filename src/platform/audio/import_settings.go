@@ -0,0 +1,114 @@
+/******************************************************************************/
+/* import_settings.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"kaiju/engine/assets/asset_info"
+	"kaiju/platform/audio/mixer"
+)
+
+var (
+	// BusOptions are the names of the buses a sound can be imported to play
+	// through, mapped to the name of the bus in the mixer
+	BusOptions = map[string]string{
+		"Master": mixer.BusMaster,
+		"Music":  mixer.BusMusic,
+		"SFX":    mixer.BusSFX,
+		"Voice":  mixer.BusVoice,
+	}
+	// LoadOptions are the ways a sound can be imported to be loaded, true
+	// for the ones that are streamed
+	LoadOptions = map[string]bool{
+		"Preload": false,
+		"Stream":  true,
+	}
+)
+
+// ImportSettings holds the import settings of a sound. The loop points are
+// frames of the imported sound, which is always converted to
+// #mixer.DefaultSampleRate. A loop end of 0 loops at the end of the sound.
+type ImportSettings struct {
+	Bus       string `options:"audioBusOptions"`
+	Load      string `options:"audioLoadOptions"`
+	LoopStart int64
+	LoopEnd   int64
+}
+
+// DefaultImportSettings are the settings of a sound that hasn't been
+// imported, or that is imported for the first time
+func DefaultImportSettings() ImportSettings {
+	return ImportSettings{Bus: "SFX", Load: "Preload"}
+}
+
+// ReadImportSettings reads the settings the audio asset was imported with,
+// ok is false (and the defaults are returned) if it hasn't been imported
+func ReadImportSettings(key string) (settings ImportSettings, ok bool) {
+	settings = DefaultImportSettings()
+	adi, err := asset_info.Lookup(key)
+	if err != nil {
+		return settings, false
+	}
+	if _, err := asset_info.Read(adi.Path, &settings); err != nil {
+		return DefaultImportSettings(), false
+	}
+	return settings, true
+}
+
+// BusName returns the name of the mixer bus the sound plays through by
+// default
+func (s *ImportSettings) BusName() string {
+	if b, ok := BusOptions[s.Bus]; ok {
+		return b
+	}
+	return mixer.BusSFX
+}
+
+// IsStream returns true if the sound should be streamed as it plays rather
+// than fully decoded before it is played
+func (s *ImportSettings) IsStream() bool { return LoadOptions[s.Load] }
+
+// PlayOptions returns the default options for playing the sound through the
+// mixer with the bus and loop points of the settings
+func (s *ImportSettings) PlayOptions() mixer.PlayOptions {
+	options := mixer.DefaultPlayOptions()
+	options.Bus = s.BusName()
+	options.LoopStart = s.LoopStart
+	options.LoopEnd = s.LoopEnd
+	options.Loop = s.LoopEnd > s.LoopStart
+	return options
+}
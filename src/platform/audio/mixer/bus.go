@@ -0,0 +1,158 @@
+/******************************************************************************/
+/* bus.go                                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package mixer
+
+import "slices"
+
+// Bus is a named group of voices that share a volume, mute setting and
+// chain of effects. The output of a bus is fed into its parent bus, all the
+// way up to the master bus which is what is ultimately written to the output.
+type Bus struct {
+	mixer   *Mixer
+	name    string
+	parent  *Bus
+	buffer  []float32
+	effects []Effect
+	volume  float32
+	gain    float32
+	muted   bool
+}
+
+// Name returns the unique name of the bus
+func (b *Bus) Name() string { return b.name }
+
+// Parent returns the bus that this bus outputs into, this will be nil for
+// the master bus
+func (b *Bus) Parent() *Bus { return b.parent }
+
+// Volume returns the linear gain that is applied to the bus
+func (b *Bus) Volume() float32 {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	return b.volume
+}
+
+// SetVolume sets the linear gain that is applied to everything that plays
+// through this bus, where 1 leaves the audio unchanged
+func (b *Bus) SetVolume(volume float32) {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	b.volume = max(volume, 0)
+}
+
+// IsMuted returns true if the bus has been muted
+func (b *Bus) IsMuted() bool {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	return b.muted
+}
+
+// SetMuted will silence the bus (and any buses that output into it) without
+// changing its volume
+func (b *Bus) SetMuted(muted bool) {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	b.muted = muted
+}
+
+// Effects returns a copy of the effects on the bus in the order they are
+// applied
+func (b *Bus) Effects() []Effect {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	return append([]Effect{}, b.effects...)
+}
+
+// AddEffect adds the effect to the end of the bus's chain of effects. The
+// effect is reset so that it doesn't carry over audio from anywhere else.
+func (b *Bus) AddEffect(effect Effect) {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	effect.Reset()
+	b.effects = append(b.effects, effect)
+}
+
+// RemoveEffect takes the effect out of the bus's chain of effects, false is
+// returned if the effect isn't on the bus
+func (b *Bus) RemoveEffect(effect Effect) bool {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	for i := range b.effects {
+		if b.effects[i] == effect {
+			b.effects = slices.Delete(b.effects, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// ClearEffects removes all of the effects from the bus
+func (b *Bus) ClearEffects() {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	b.effects = b.effects[:0]
+}
+
+func (b *Bus) targetGain() float32 {
+	if b.muted {
+		return 0
+	}
+	return b.volume
+}
+
+// applyEffects runs the bus's buffer through each of its effects in order
+func (b *Bus) applyEffects(frames, sampleRate int) {
+	for _, e := range b.effects {
+		e.Process(b.buffer[:frames*OutputChannels], sampleRate)
+	}
+}
+
+// mixInto applies the bus gain to its buffer and adds the result into the
+// output. The gain is ramped across the buffer when it changes to prevent
+// clicks in the output.
+func (b *Bus) mixInto(out []float32, frames int) {
+	target := b.targetGain()
+	gain := b.gain
+	step := (target - gain) / float32(frames)
+	for i := 0; i < frames; i++ {
+		gain += step
+		out[i*OutputChannels] += b.buffer[i*OutputChannels] * gain
+		out[i*OutputChannels+1] += b.buffer[i*OutputChannels+1] * gain
+	}
+	b.gain = target
+}
@@ -0,0 +1,297 @@
+/******************************************************************************/
+/* mixer.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package mixer
+
+import (
+	"encoding/binary"
+	"errors"
+	"kaiju/klib"
+	"math"
+	"sync"
+	"unsafe"
+)
+
+const (
+	// OutputChannels is the number of interleaved channels the mixer renders
+	OutputChannels = 2
+	// DefaultSampleRate is the sample rate the mixer is typically run at
+	DefaultSampleRate = 48000
+	// DefaultMaxVoices is the default limit of voices that can be alive in
+	// the mixer at the same time
+	DefaultMaxVoices = 64
+)
+
+const (
+	BusMaster = "master"
+	BusMusic  = "music"
+	BusSFX    = "sfx"
+	BusVoice  = "voice"
+)
+
+// Mixer combines any number of voices into a single stereo output. It does
+// not depend on an audio device, the output is pulled from it either through
+// #Mixer.Render or by using it as an io.Reader of 32-bit little endian float
+// samples. All functions on the mixer, its buses and its voices are safe to
+// call from any goroutine.
+type Mixer struct {
+	mutex      sync.Mutex
+	sampleRate int
+	maxVoices  int
+	voices     []*Voice
+	buses      []*Bus
+	busLookup  map[string]*Bus
+	playCount  uint64
+	readBuffer []float32
+}
+
+// New creates a mixer that renders at the given sample rate and allows up to
+// maxVoices voices to be alive at once. The master, music, SFX and voice
+// buses are created automatically.
+func New(sampleRate, maxVoices int) *Mixer {
+	m := &Mixer{
+		sampleRate: max(sampleRate, 1),
+		maxVoices:  max(maxVoices, 1),
+		busLookup:  make(map[string]*Bus),
+	}
+	m.addBus(BusMaster, nil)
+	master := m.buses[0]
+	m.addBus(BusMusic, master)
+	m.addBus(BusSFX, master)
+	m.addBus(BusVoice, master)
+	return m
+}
+
+// SampleRate returns the number of frames per second the mixer renders at
+func (m *Mixer) SampleRate() int { return m.sampleRate }
+
+// MaxVoices returns the limit of voices that can be alive at once
+func (m *Mixer) MaxVoices() int { return m.maxVoices }
+
+// Master returns the bus that all other buses eventually output into
+func (m *Mixer) Master() *Bus { return m.buses[0] }
+
+// Bus returns the bus with the given name, or nil if it doesn't exist
+func (m *Mixer) Bus(name string) *Bus {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.busLookup[name]
+}
+
+// AddBus creates a new bus that outputs into the parent bus. An error is
+// returned if the name is already in use or the parent does not exist.
+func (m *Mixer) AddBus(name, parent string) (*Bus, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, ok := m.busLookup[name]; ok {
+		return nil, errors.New("a bus with the name " + name + " already exists")
+	}
+	p, ok := m.busLookup[parent]
+	if !ok {
+		return nil, errors.New("the parent bus " + parent + " does not exist")
+	}
+	return m.addBus(name, p), nil
+}
+
+func (m *Mixer) addBus(name string, parent *Bus) *Bus {
+	b := &Bus{
+		mixer:  m,
+		name:   name,
+		parent: parent,
+		volume: 1,
+		gain:   1,
+	}
+	// Buses are always created after their parent, so mixing the list in
+	// reverse will always mix a child before its parent
+	m.buses = append(m.buses, b)
+	m.busLookup[name] = b
+	return b
+}
+
+// VoiceCount returns the number of voices that are playing or paused
+func (m *Mixer) VoiceCount() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.removeStoppedVoices()
+	return len(m.voices)
+}
+
+func (m *Mixer) removeStoppedVoices() {
+	for i := 0; i < len(m.voices); i++ {
+		if m.voices[i].state == VoiceStateStopped {
+			m.voices[i].release()
+			m.voices = klib.RemoveUnordered(m.voices, i)
+			i--
+		}
+	}
+}
+
+// Play adds a new voice for the source to the mixer. If the voice limit has
+// been reached, the lowest priority (and then oldest) voice is stopped to make
+// room. If all of the voices have a higher priority than the new one, the
+// returned voice will already be stopped.
+func (m *Mixer) Play(source Source, options PlayOptions) *Voice {
+	return m.play(&Voice{source: source}, options)
+}
+
+// PlayStream adds a new voice for the stream to the mixer in the same way as
+// #Mixer.Play. The voice takes ownership of the stream and will close it once
+// the voice has stopped, so a stream should only be played once.
+func (m *Mixer) PlayStream(stream Streamer, options PlayOptions) *Voice {
+	v := &Voice{stream: stream}
+	v.streamState.reset(0)
+	stream.SetLoop(options.Loop, max(options.LoopStart, 0), options.LoopEnd)
+	return m.play(v, options)
+}
+
+func (m *Mixer) play(v *Voice, options PlayOptions) *Voice {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	bus, ok := m.busLookup[options.Bus]
+	if !ok {
+		bus = m.buses[0]
+	}
+	m.playCount++
+	*v = Voice{
+		mixer:     m,
+		source:    v.source,
+		stream:    v.stream,
+		bus:       bus,
+		volume:    max(options.Volume, 0),
+		pan:       max(-1, min(options.Pan, 1)),
+		pitch:     max(options.Pitch, minPitch),
+		loop:      options.Loop,
+		loopStart: max(options.LoopStart, 0),
+		loopEnd:   options.LoopEnd,
+		priority:  options.Priority,
+		order:     m.playCount,
+		state:     VoiceStatePlaying,
+	}
+	if options.Paused {
+		v.state = VoiceStatePaused
+	}
+	v.resetGains()
+	m.removeStoppedVoices()
+	if len(m.voices) >= m.maxVoices {
+		victim := m.lowestPriorityVoice()
+		if m.voices[victim].priority > v.priority {
+			v.state = VoiceStateStopped
+			v.release()
+			return v
+		}
+		m.voices[victim].state = VoiceStateStopped
+		m.voices[victim].release()
+		m.voices = klib.RemoveUnordered(m.voices, victim)
+	}
+	m.voices = append(m.voices, v)
+	return v
+}
+
+func (m *Mixer) lowestPriorityVoice() int {
+	idx := 0
+	for i := 1; i < len(m.voices); i++ {
+		a, b := m.voices[i], m.voices[idx]
+		if a.priority < b.priority || (a.priority == b.priority && a.order < b.order) {
+			idx = i
+		}
+	}
+	return idx
+}
+
+// StopAll will stop every voice in the mixer
+func (m *Mixer) StopAll() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, v := range m.voices {
+		v.state = VoiceStateStopped
+		v.release()
+	}
+	m.voices = m.voices[:0]
+}
+
+// Render mixes all of the playing voices into the stereo interleaved output
+// buffer. Any existing data in the output is overwritten. Each call advances
+// the playback of the voices by len(out)/2 frames.
+func (m *Mixer) Render(out []float32) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	frames := len(out) / OutputChannels
+	size := frames * OutputChannels
+	for _, b := range m.buses {
+		if cap(b.buffer) < size {
+			b.buffer = make([]float32, size)
+		}
+		b.buffer = b.buffer[:size]
+		clear(b.buffer)
+	}
+	clear(out)
+	if frames == 0 {
+		return
+	}
+	for _, v := range m.voices {
+		if v.state == VoiceStatePlaying {
+			v.mix(v.bus.buffer, frames, m.sampleRate)
+		}
+	}
+	m.removeStoppedVoices()
+	for i := len(m.buses) - 1; i > 0; i-- {
+		b := m.buses[i]
+		b.applyEffects(frames, m.sampleRate)
+		b.mixInto(b.parent.buffer, frames)
+	}
+	m.buses[0].applyEffects(frames, m.sampleRate)
+	m.buses[0].mixInto(out, frames)
+}
+
+// Read renders the mixer into p as 32-bit little endian float samples so that
+// the mixer can be handed directly to an audio device as its stream. Read
+// never returns an error and always fills as many whole frames as fit in p.
+// Read should only be called from a single goroutine at a time.
+func (m *Mixer) Read(p []byte) (int, error) {
+	const frameSize = OutputChannels * int(unsafe.Sizeof(float32(0)))
+	frames := len(p) / frameSize
+	size := frames * OutputChannels
+	if cap(m.readBuffer) < size {
+		m.readBuffer = make([]float32, size)
+	}
+	buff := m.readBuffer[:size]
+	m.Render(buff)
+	for i, s := range buff {
+		binary.LittleEndian.PutUint32(p[i*4:], math.Float32bits(s))
+	}
+	return frames * frameSize, nil
+}
@@ -0,0 +1,373 @@
+/******************************************************************************/
+/* mixer_test.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package mixer
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+const testRate = 100
+
+func testConstantSound(value float32, channels, frames int) *Sound {
+	samples := make([]float32, channels*frames)
+	for i := range samples {
+		samples[i] = value
+	}
+	return NewSound(samples, channels, testRate)
+}
+
+func testRampSound(frames int) *Sound {
+	samples := make([]float32, frames)
+	for i := range samples {
+		samples[i] = float32(i)
+	}
+	return NewSound(samples, 1, testRate)
+}
+
+func testApprox(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 0.0001
+}
+
+func testExpectFrames(t *testing.T, out []float32, left, right float32) {
+	t.Helper()
+	for i := 0; i < len(out); i += OutputChannels {
+		if !testApprox(out[i], left) || !testApprox(out[i+1], right) {
+			t.Fatalf("frame %d expected (%f, %f) but got (%f, %f)",
+				i/OutputChannels, left, right, out[i], out[i+1])
+		}
+	}
+}
+
+func TestMixerSumsVoices(t *testing.T) {
+	m := New(testRate, DefaultMaxVoices)
+	opts := DefaultPlayOptions()
+	m.Play(testConstantSound(0.25, 2, 100), opts)
+	m.Play(testConstantSound(0.5, 2, 100), opts)
+	out := make([]float32, 20)
+	m.Render(out)
+	testExpectFrames(t, out, 0.75, 0.75)
+}
+
+func TestMixerVoiceFinishes(t *testing.T) {
+	m := New(testRate, DefaultMaxVoices)
+	v := m.Play(testConstantSound(1, 2, 4), DefaultPlayOptions())
+	out := make([]float32, 16)
+	m.Render(out)
+	testExpectFrames(t, out[:8], 1, 1)
+	testExpectFrames(t, out[8:], 0, 0)
+	if !v.IsStopped() || m.VoiceCount() != 0 {
+		t.Error("expected the voice to stop once it reached the end")
+	}
+}
+
+func TestMixerVoiceLoop(t *testing.T) {
+	m := New(testRate, DefaultMaxVoices)
+	opts := DefaultPlayOptions()
+	opts.Loop = true
+	v := m.Play(testConstantSound(1, 2, 4), opts)
+	out := make([]float32, 32)
+	m.Render(out)
+	testExpectFrames(t, out, 1, 1)
+	if !v.IsPlaying() {
+		t.Error("expected a looping voice to keep playing")
+	}
+}
+
+func TestMixerPauseResumeStop(t *testing.T) {
+	m := New(testRate, DefaultMaxVoices)
+	v := m.Play(testConstantSound(1, 2, 100), DefaultPlayOptions())
+	out := make([]float32, 8)
+	v.Pause()
+	m.Render(out)
+	testExpectFrames(t, out, 0, 0)
+	if v.Position() != 0 {
+		t.Error("expected a paused voice to keep its position")
+	}
+	v.Resume()
+	m.Render(out)
+	testExpectFrames(t, out, 1, 1)
+	v.Stop()
+	m.Render(out)
+	testExpectFrames(t, out, 0, 0)
+	v.Resume()
+	if !v.IsStopped() {
+		t.Error("expected a stopped voice to not be resumable")
+	}
+}
+
+func TestMixerSeekAndPitch(t *testing.T) {
+	m := New(testRate, DefaultMaxVoices)
+	opts := DefaultPlayOptions()
+	opts.Pan = -1
+	v := m.Play(testRampSound(100), opts)
+	v.Seek(0.5)
+	v.SetPitch(2)
+	out := make([]float32, 6)
+	m.Render(out)
+	for i, expect := range []float32{50, 52, 54} {
+		if !testApprox(out[i*2], expect) || out[i*2+1] != 0 {
+			t.Fatalf("frame %d expected %f, got %f", i, expect, out[i*2])
+		}
+	}
+	if p := v.Position(); math.Abs(p-0.56) > 0.0001 {
+		t.Errorf("expected position 0.56, got %f", p)
+	}
+}
+
+func TestMixerResampleInterpolates(t *testing.T) {
+	m := New(testRate*2, DefaultMaxVoices)
+	opts := DefaultPlayOptions()
+	opts.Pan = 1
+	m.Play(testRampSound(100), opts)
+	out := make([]float32, 8)
+	m.Render(out)
+	for i, expect := range []float32{0, 0.5, 1, 1.5} {
+		if !testApprox(out[i*2+1], expect) {
+			t.Fatalf("frame %d expected %f, got %f", i, expect, out[i*2+1])
+		}
+	}
+}
+
+func TestMixerMonoPan(t *testing.T) {
+	m := New(testRate, DefaultMaxVoices)
+	m.Play(testConstantSound(1, 1, 100), DefaultPlayOptions())
+	out := make([]float32, 4)
+	m.Render(out)
+	center := float32(math.Sqrt2 / 2)
+	testExpectFrames(t, out, center, center)
+}
+
+func TestMixerBuses(t *testing.T) {
+	m := New(testRate, DefaultMaxVoices)
+	music := DefaultPlayOptions()
+	music.Bus = BusMusic
+	m.Play(testConstantSound(1, 2, 1000), music)
+	m.Play(testConstantSound(1, 2, 1000), DefaultPlayOptions())
+	m.Bus(BusMusic).SetVolume(0.5)
+	m.Master().SetVolume(0.5)
+	out := make([]float32, 16)
+	// The first render ramps the gain to the new volume
+	m.Render(out)
+	m.Render(out)
+	testExpectFrames(t, out, 0.75, 0.75)
+	m.Bus(BusSFX).SetMuted(true)
+	m.Render(out)
+	m.Render(out)
+	testExpectFrames(t, out, 0.25, 0.25)
+	ui, err := m.AddBus("ui", BusSFX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ui.Parent() != m.Bus(BusSFX) {
+		t.Error("expected the bus to output into its parent")
+	}
+	if _, err := m.AddBus("ui", BusMaster); err == nil {
+		t.Error("expected an error for a duplicate bus name")
+	}
+	if _, err := m.AddBus("other", "missing"); err == nil {
+		t.Error("expected an error for a missing parent bus")
+	}
+}
+
+func TestMixerVoiceLimit(t *testing.T) {
+	m := New(testRate, 2)
+	low := DefaultPlayOptions()
+	high := DefaultPlayOptions()
+	high.Priority = 10
+	sound := testConstantSound(1, 2, 100)
+	a := m.Play(sound, high)
+	b := m.Play(sound, low)
+	c := m.Play(sound, low)
+	if !a.IsPlaying() || !b.IsStopped() || !c.IsPlaying() {
+		t.Fatal("expected the oldest low priority voice to be stolen")
+	}
+	d := m.Play(sound, DefaultPlayOptions())
+	if !c.IsStopped() || !d.IsPlaying() {
+		t.Fatal("expected the equal priority voice to be stolen")
+	}
+	a.SetPriority(0)
+	d.SetPriority(20)
+	e := m.Play(sound, PlayOptions{Priority: -1, Volume: 1, Pitch: 1})
+	if !e.IsStopped() || m.VoiceCount() != 2 {
+		t.Error("expected a lower priority voice to be rejected")
+	}
+}
+
+func TestMixerRead(t *testing.T) {
+	m := New(testRate, DefaultMaxVoices)
+	m.Play(testConstantSound(0.5, 2, 100), DefaultPlayOptions())
+	buff := make([]byte, 35)
+	n, err := m.Read(buff)
+	if err != nil || n != 32 {
+		t.Fatalf("expected to read 32 bytes, read %d", n)
+	}
+	for i := 0; i < n; i += 4 {
+		if s := math.Float32frombits(binary.LittleEndian.Uint32(buff[i:])); s != 0.5 {
+			t.Fatalf("expected sample 0.5, got %f", s)
+		}
+	}
+}
+
+func TestMixerVoiceLoopPoints(t *testing.T) {
+	m := New(testRate, DefaultMaxVoices)
+	opts := DefaultPlayOptions()
+	opts.Pan = -1
+	opts.Loop = true
+	opts.LoopStart = 2
+	opts.LoopEnd = 6
+	m.Play(testRampSound(10), opts)
+	out := make([]float32, 14*OutputChannels)
+	m.Render(out)
+	expected := []float32{0, 1, 2, 3, 4, 5, 2, 3, 4, 5, 2, 3, 4, 5}
+	for i, e := range expected {
+		if !testApprox(out[i*OutputChannels], e) {
+			t.Fatalf("frame %d expected %f, got %f", i, e, out[i*OutputChannels])
+		}
+	}
+}
+
+// testStream is a stream of a mono ramp that can be told to run dry to
+// simulate the decoder not keeping up with the mixer
+type testStream struct {
+	frames   int64
+	position int64
+	ready    int64
+	closed   bool
+}
+
+func (s *testStream) Channels() int                       { return 1 }
+func (s *testStream) SampleRate() int                     { return testRate }
+func (s *testStream) Length() int64                       { return s.frames }
+func (s *testStream) SetPosition(frame int64)             { s.position = frame }
+func (s *testStream) SetLoop(loop bool, start, end int64) {}
+func (s *testStream) Close()                              { s.closed = true }
+
+func (s *testStream) Read(samples []float32) (int64, int, bool) {
+	start := s.position
+	n := min(int64(len(samples)), s.ready-s.position)
+	for i := range n {
+		samples[i] = float32(s.position + i)
+	}
+	s.position += n
+	return start, int(n), s.position == s.frames
+}
+
+func TestMixerStreamVoice(t *testing.T) {
+	m := New(testRate, DefaultMaxVoices)
+	opts := DefaultPlayOptions()
+	opts.Pan = -1
+	s := &testStream{frames: 20, ready: 6}
+	v := m.PlayStream(s, opts)
+	out := make([]float32, 8*OutputChannels)
+	m.Render(out)
+	// Only 6 frames were ready, so the voice plays up to the last frame it
+	// can interpolate from and leaves the rest silent
+	for i := 0; i < 8; i++ {
+		expected := float32(i)
+		if i >= 5 {
+			expected = 0
+		}
+		if !testApprox(out[i*OutputChannels], expected) {
+			t.Fatalf("frame %d expected %f, got %f", i, expected, out[i*OutputChannels])
+		}
+	}
+	if !v.IsPlaying() || v.Position() != 5.0/testRate {
+		t.Fatalf("expected the voice to wait at frame 5, got %f", v.Position()*testRate)
+	}
+	s.ready = s.frames
+	m.Render(out)
+	for i := 0; i < 8; i++ {
+		if !testApprox(out[i*OutputChannels], float32(5+i)) {
+			t.Fatalf("frame %d expected %d, got %f", i, 5+i, out[i*OutputChannels])
+		}
+	}
+	v.Seek(0.15)
+	m.Render(out)
+	if !testApprox(out[0], 15) {
+		t.Fatalf("expected frame 15 after seeking, got %f", out[0])
+	}
+	if !v.IsStopped() || !s.closed {
+		t.Error("expected the voice to stop and close the stream at the end")
+	}
+}
+
+type testGainEffect struct {
+	gain   float32
+	resets int
+}
+
+func (e *testGainEffect) Process(samples []float32, _ int) {
+	for i := range samples {
+		samples[i] *= e.gain
+	}
+}
+
+func (e *testGainEffect) Reset() { e.resets++ }
+
+func TestMixerBusEffects(t *testing.T) {
+	m := New(testRate, DefaultMaxVoices)
+	half := &testGainEffect{gain: 0.5}
+	double := &testGainEffect{gain: 2}
+	m.Bus(BusSFX).AddEffect(half)
+	m.Master().AddEffect(double)
+	m.Master().AddEffect(half)
+	if half.resets != 2 || double.resets != 1 {
+		t.Error("expected the effects to be reset when added")
+	}
+	opts := DefaultPlayOptions()
+	opts.Bus = BusSFX
+	m.Play(testConstantSound(0.8, 2, 100), opts)
+	opts.Bus = BusMusic
+	m.Play(testConstantSound(0.2, 2, 100), opts)
+	out := make([]float32, 8)
+	m.Render(out)
+	testExpectFrames(t, out, 0.6, 0.6)
+	if !m.Master().RemoveEffect(half) || m.Master().RemoveEffect(half) {
+		t.Error("expected the effect to only be removed once")
+	}
+	if len(m.Master().Effects()) != 1 {
+		t.Errorf("expected 1 master effect, got %d", len(m.Master().Effects()))
+	}
+	m.Render(out)
+	testExpectFrames(t, out, 1.2, 1.2)
+	m.Bus(BusSFX).ClearEffects()
+	m.Render(out)
+	testExpectFrames(t, out, 2, 2)
+}
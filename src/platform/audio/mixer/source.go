@@ -0,0 +1,93 @@
+/******************************************************************************/
+/* source.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package mixer
+
+// Source is anything that can supply samples to a #Voice. Samples are read
+// by frame, where a frame is one sample for each of the channels.
+type Source interface {
+	// Channels returns the number of interleaved channels in the source
+	Channels() int
+	// SampleRate returns the number of frames per second of the source
+	SampleRate() int
+	// Frames returns the total number of frames in the source
+	Frames() int
+	// Sample returns the sample for the channel of the given frame, the
+	// frame will always be in the range [0, Frames)
+	Sample(frame, channel int) float32
+}
+
+// Sound is an in-memory #Source of 32-bit float samples. The samples are
+// interleaved by channel and are expected to be in the range [-1, 1].
+type Sound struct {
+	samples    []float32
+	channels   int
+	sampleRate int
+}
+
+// NewSound creates a sound from the interleaved samples. The sound does not
+// make a copy of the samples, so they should not be modified while the sound
+// is being played.
+func NewSound(samples []float32, channels, sampleRate int) *Sound {
+	return &Sound{
+		samples:    samples,
+		channels:   max(channels, 1),
+		sampleRate: max(sampleRate, 1),
+	}
+}
+
+// Samples returns the interleaved samples for this sound
+func (s *Sound) Samples() []float32 { return s.samples }
+
+// Channels returns the number of interleaved channels in the sound
+func (s *Sound) Channels() int { return s.channels }
+
+// SampleRate returns the number of frames per second of the sound
+func (s *Sound) SampleRate() int { return s.sampleRate }
+
+// Frames returns the total number of frames in the sound
+func (s *Sound) Frames() int { return len(s.samples) / s.channels }
+
+// Sample returns the sample for the channel of the given frame
+func (s *Sound) Sample(frame, channel int) float32 {
+	return s.samples[frame*s.channels+channel]
+}
+
+// Duration returns the length of the sound in seconds
+func (s *Sound) Duration() float64 {
+	return float64(s.Frames()) / float64(s.sampleRate)
+}
@@ -0,0 +1,184 @@
+/******************************************************************************/
+/* stream.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package mixer
+
+// streamReadFrames is the number of frames a voice pulls from its stream at
+// a time while mixing
+const streamReadFrames = 256
+
+// Streamer is a source of samples that can only be read forward, such as a
+// long piece of music that is decoded while it plays. Unlike a #Source, the
+// samples are not all available up front, so a voice pulls them in order
+// and never asks for a frame twice. All frame numbers are in the sample rate
+// of the streamer.
+type Streamer interface {
+	// Channels returns the number of interleaved channels in the stream
+	Channels() int
+	// SampleRate returns the number of frames per second of the stream
+	SampleRate() int
+	// Length returns the total number of frames in the stream, or -1 if
+	// the length is not known
+	Length() int64
+	// Read fills the samples with the next interleaved frames of the stream
+	// and returns the frame number of the first frame read along with the
+	// number of frames read. The frames read are always contiguous, a loop
+	// back to the start will be returned by the next call. Read is called
+	// while mixing, so it must never block; if no frames are ready it should
+	// return 0 frames. Finished is true once the stream has no more frames.
+	Read(samples []float32) (start int64, frames int, finished bool)
+	// SetPosition drops any frames that have not yet been read and continues
+	// the stream from the given frame
+	SetPosition(frame int64)
+	// SetLoop sets if the stream should go back to the start frame when it
+	// reaches the end frame. An end of 0 or less is the end of the stream.
+	SetLoop(loop bool, start, end int64)
+	// Close releases the stream, it is called once the voice has stopped
+	Close()
+}
+
+// streamState holds the frames a voice has pulled from its #Streamer so that
+// it can interpolate between them
+type streamState struct {
+	buffer   []float32
+	start    int64
+	read     int
+	count    int
+	frames   [2][OutputChannels]float32
+	position [2]int64
+	loaded   int
+	phase    float64
+	finished bool
+}
+
+// reset drops all of the pulled frames, the position will report the given
+// frame until new frames are pulled
+func (s *streamState) reset(frame int64) {
+	s.start = frame
+	s.read, s.count, s.loaded = 0, 0, 0
+	s.phase = 0
+	s.finished = false
+}
+
+// nextFrame pulls the next frame of the stream into slot of the loaded
+// frames, false is returned if no frame was available
+func (v *Voice) nextFrame(slot int) bool {
+	s := &v.streamState
+	channels := v.stream.Channels()
+	if s.read == s.count {
+		if s.finished {
+			return false
+		}
+		if s.buffer == nil {
+			s.buffer = make([]float32, streamReadFrames*channels)
+		}
+		s.start, s.count, s.finished = v.stream.Read(s.buffer)
+		s.read = 0
+		if s.count == 0 {
+			return false
+		}
+	}
+	sample := s.buffer[s.read*channels:]
+	s.frames[slot][0] = sample[0]
+	s.frames[slot][1] = sample[0]
+	if channels > 1 {
+		s.frames[slot][1] = sample[1]
+	}
+	s.position[slot] = s.start + int64(s.read)
+	s.read++
+	return true
+}
+
+// advance makes sure that the two frames the playback position is between
+// are loaded, false is returned if the stream can't supply them yet
+func (v *Voice) advance() bool {
+	s := &v.streamState
+	for s.loaded < 2 || s.phase >= 1 {
+		if s.loaded == 2 {
+			s.frames[0] = s.frames[1]
+			s.position[0] = s.position[1]
+			s.loaded = 1
+			s.phase--
+		}
+		if !v.nextFrame(s.loaded) {
+			return false
+		}
+		s.loaded++
+	}
+	return true
+}
+
+// mixStream is the same as #Voice.mix but for voices that are playing a
+// #Streamer. If the stream can't keep up, the rest of the buffer is left
+// silent and the voice continues from where it was on the next mix.
+func (v *Voice) mixStream(out []float32, frames, sampleRate int) {
+	s := &v.streamState
+	step := float64(v.pitch) * float64(v.stream.SampleRate()) / float64(sampleRate)
+	targetL, targetR := panGains(v.volume, v.pan, v.stream.Channels())
+	stepL := (targetL - v.gainL) / float32(frames)
+	stepR := (targetR - v.gainR) / float32(frames)
+	gainL, gainR := v.gainL, v.gainR
+	for i := 0; i < frames; i++ {
+		if !v.advance() {
+			if s.finished && s.read == s.count {
+				v.state = VoiceStateStopped
+			}
+			break
+		}
+		t := float32(s.phase)
+		l := s.frames[0][0] + (s.frames[1][0]-s.frames[0][0])*t
+		r := s.frames[0][1] + (s.frames[1][1]-s.frames[0][1])*t
+		gainL += stepL
+		gainR += stepR
+		out[i*OutputChannels] += l * gainL
+		out[i*OutputChannels+1] += r * gainR
+		s.phase += step
+	}
+	v.gainL, v.gainR = targetL, targetR
+}
+
+// streamPosition returns the frame of the stream that is currently playing
+func (v *Voice) streamPosition() float64 {
+	s := &v.streamState
+	if s.loaded == 0 {
+		if s.read < s.count {
+			return float64(s.start + int64(s.read))
+		}
+		return float64(s.start + int64(s.count))
+	}
+	return float64(s.position[0]) + min(s.phase, 1)
+}
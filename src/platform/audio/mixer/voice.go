@@ -0,0 +1,390 @@
+/******************************************************************************/
+/* voice.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package mixer
+
+import "math"
+
+// VoiceState is the current playback state of a #Voice
+type VoiceState int
+
+const (
+	VoiceStatePlaying VoiceState = iota
+	VoiceStatePaused
+	VoiceStateStopped
+)
+
+// minPitch is the slowest a voice is allowed to play back at, it prevents a
+// voice from getting stuck on a single frame forever
+const minPitch = 0.01
+
+// PlayOptions are the initial settings for a voice when it is played through
+// #Mixer.Play. Use #DefaultPlayOptions to get reasonable defaults.
+type PlayOptions struct {
+	// Bus is the name of the bus the voice will play through, if the bus
+	// does not exist then the master bus is used
+	Bus string
+	// Volume is the linear gain of the voice, where 1 is unchanged
+	Volume float32
+	// Pan is the stereo position of the voice from -1 (left) to 1 (right)
+	Pan float32
+	// Pitch is the playback speed of the voice, where 1 is unchanged
+	Pitch float32
+	// Loop will restart the voice from the beginning when it reaches the end
+	Loop bool
+	// LoopStart is the frame the voice goes back to when it loops
+	LoopStart int64
+	// LoopEnd is the frame that a looping voice goes back to LoopStart at,
+	// 0 or less will loop at the end of the source
+	LoopEnd int64
+	// Priority decides which voices are stopped when the voice limit has
+	// been reached, voices with a higher priority are kept over lower ones
+	Priority int
+	// Paused will add the voice to the mixer without starting it
+	Paused bool
+}
+
+// DefaultPlayOptions returns the options to play a sound once, unchanged,
+// through the SFX bus
+func DefaultPlayOptions() PlayOptions {
+	return PlayOptions{
+		Bus:    BusSFX,
+		Volume: 1,
+		Pitch:  1,
+	}
+}
+
+// Voice is a handle to a single sound that is playing through the #Mixer.
+// All of the functions on a voice are safe to call at any time, even after
+// the voice has stopped; calls on a stopped voice will be ignored.
+type Voice struct {
+	mixer       *Mixer
+	source      Source
+	stream      Streamer
+	streamState streamState
+	bus         *Bus
+	position    float64
+	volume      float32
+	pan         float32
+	pitch       float32
+	gainL       float32
+	gainR       float32
+	priority    int
+	order       uint64
+	state       VoiceState
+	loop        bool
+	loopStart   int64
+	loopEnd     int64
+}
+
+// Source returns the source of samples that this voice is playing, this will
+// be nil if the voice is playing a stream
+func (v *Voice) Source() Source { return v.source }
+
+// Stream returns the stream that this voice is playing, this will be nil if
+// the voice is playing a source
+func (v *Voice) Stream() Streamer { return v.stream }
+
+func (v *Voice) channels() int {
+	if v.stream != nil {
+		return v.stream.Channels()
+	}
+	return v.source.Channels()
+}
+
+func (v *Voice) sampleRate() int {
+	if v.stream != nil {
+		return v.stream.SampleRate()
+	}
+	return v.source.SampleRate()
+}
+
+// release closes the stream of the voice once it has been removed from the
+// mixer, the stream is owned by the voice
+func (v *Voice) release() {
+	if v.stream != nil {
+		v.stream.Close()
+	}
+}
+
+// Bus returns the bus that this voice is playing through
+func (v *Voice) Bus() *Bus { return v.bus }
+
+// State returns the current playback state of the voice
+func (v *Voice) State() VoiceState {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	return v.state
+}
+
+// IsPlaying returns true if the voice is actively being mixed into the output
+func (v *Voice) IsPlaying() bool { return v.State() == VoiceStatePlaying }
+
+// IsPaused returns true if the voice has been paused
+func (v *Voice) IsPaused() bool { return v.State() == VoiceStatePaused }
+
+// IsStopped returns true if the voice has finished or was stopped, a stopped
+// voice can not be played again
+func (v *Voice) IsStopped() bool { return v.State() == VoiceStateStopped }
+
+// Stop will stop the voice and remove it from the mixer
+func (v *Voice) Stop() {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	v.state = VoiceStateStopped
+}
+
+// Pause will stop the voice from being mixed while keeping its position
+func (v *Voice) Pause() {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	if v.state == VoiceStatePlaying {
+		v.state = VoiceStatePaused
+	}
+}
+
+// Resume will continue playing a paused voice from where it was paused
+func (v *Voice) Resume() {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	if v.state == VoiceStatePaused {
+		v.state = VoiceStatePlaying
+	}
+}
+
+// Position returns the current playback position of the voice in seconds
+func (v *Voice) Position() float64 {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	if v.stream != nil {
+		return v.streamPosition() / float64(v.stream.SampleRate())
+	}
+	return v.position / float64(v.source.SampleRate())
+}
+
+// Seek will move the playback position of the voice to the given time in
+// seconds. The time is clamped to the length of the source. Streams will be
+// silent for a moment after seeking while the new position is decoded.
+func (v *Voice) Seek(seconds float64) {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	if v.stream != nil {
+		frame := max(0, int64(seconds*float64(v.stream.SampleRate())))
+		if length := v.stream.Length(); length >= 0 {
+			frame = min(frame, length)
+		}
+		v.stream.SetPosition(frame)
+		v.streamState.reset(frame)
+		return
+	}
+	frame := seconds * float64(v.source.SampleRate())
+	v.position = max(0, min(frame, float64(v.source.Frames())))
+}
+
+// IsLooping returns true if the voice will restart when it reaches the end
+func (v *Voice) IsLooping() bool {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	return v.loop
+}
+
+// SetLoop sets if the voice should restart when it reaches the end
+func (v *Voice) SetLoop(loop bool) {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	v.loop = loop
+	if v.stream != nil {
+		v.stream.SetLoop(v.loop, v.loopStart, v.loopEnd)
+	}
+}
+
+// LoopPoints returns the frames that the voice loops between
+func (v *Voice) LoopPoints() (start, end int64) {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	return v.loopStart, v.loopEnd
+}
+
+// SetLoopPoints sets the frames that the voice loops between when looping is
+// enabled. When the voice reaches the end frame it continues from the start
+// frame without any gap. An end of 0 or less loops at the end of the source.
+func (v *Voice) SetLoopPoints(start, end int64) {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	v.loopStart, v.loopEnd = max(start, 0), end
+	if v.stream != nil {
+		v.stream.SetLoop(v.loop, v.loopStart, v.loopEnd)
+	}
+}
+
+// loopRange returns the frames a looping voice of total frames plays between
+func (v *Voice) loopRange(total int) (int, int) {
+	end := total
+	if v.loopEnd > 0 && v.loopEnd < int64(total) {
+		end = int(v.loopEnd)
+	}
+	start := int(min(v.loopStart, int64(end-1)))
+	return start, end
+}
+
+// Volume returns the linear gain of the voice
+func (v *Voice) Volume() float32 {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	return v.volume
+}
+
+// SetVolume sets the linear gain of the voice, where 1 is unchanged
+func (v *Voice) SetVolume(volume float32) {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	v.volume = max(volume, 0)
+}
+
+// Pan returns the stereo position of the voice
+func (v *Voice) Pan() float32 {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	return v.pan
+}
+
+// SetPan sets the stereo position of the voice from -1 (left) to 1 (right)
+func (v *Voice) SetPan(pan float32) {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	v.pan = max(-1, min(pan, 1))
+}
+
+// Pitch returns the playback speed of the voice
+func (v *Voice) Pitch() float32 {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	return v.pitch
+}
+
+// SetPitch sets the playback speed of the voice, where 1 is unchanged, 2 is
+// twice as fast (an octave higher) and 0.5 is half as fast
+func (v *Voice) SetPitch(pitch float32) {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	v.pitch = max(pitch, minPitch)
+}
+
+// Priority returns the priority of the voice used for voice stealing
+func (v *Voice) Priority() int {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	return v.priority
+}
+
+// SetPriority sets the priority of the voice used for voice stealing
+func (v *Voice) SetPriority(priority int) {
+	v.mixer.mutex.Lock()
+	defer v.mixer.mutex.Unlock()
+	v.priority = priority
+}
+
+// panGains returns the left and right gains for the voice. Mono sources use
+// a constant power pan so the perceived loudness is the same across the
+// stereo field, stereo sources use a balance control instead.
+func panGains(volume, pan float32, channels int) (float32, float32) {
+	if channels == 1 {
+		angle := float64(pan+1) * math.Pi / 4
+		return volume * float32(math.Cos(angle)), volume * float32(math.Sin(angle))
+	}
+	if pan < 0 {
+		return volume, volume * (1 + pan)
+	}
+	return volume * (1 - pan), volume
+}
+
+func (v *Voice) resetGains() {
+	v.gainL, v.gainR = panGains(v.volume, v.pan, v.channels())
+}
+
+// mix adds the output of the voice into the stereo interleaved buffer. The
+// source is read with linear interpolation so that it can play at any pitch
+// and sample rate. Volume and pan changes are ramped across the buffer.
+func (v *Voice) mix(out []float32, frames, sampleRate int) {
+	if v.stream != nil {
+		v.mixStream(out, frames, sampleRate)
+		return
+	}
+	src := v.source
+	total := src.Frames()
+	if total == 0 {
+		v.state = VoiceStateStopped
+		return
+	}
+	stereo := src.Channels() > 1
+	step := float64(v.pitch) * float64(src.SampleRate()) / float64(sampleRate)
+	targetL, targetR := panGains(v.volume, v.pan, src.Channels())
+	stepL := (targetL - v.gainL) / float32(frames)
+	stepR := (targetR - v.gainR) / float32(frames)
+	gainL, gainR := v.gainL, v.gainR
+	loopStart, loopEnd := v.loopRange(total)
+	for i := 0; i < frames; i++ {
+		if v.loop && v.position >= float64(loopEnd) {
+			over := v.position - float64(loopEnd)
+			v.position = float64(loopStart) + math.Mod(over, float64(loopEnd-loopStart))
+		} else if v.position >= float64(total) {
+			v.state = VoiceStateStopped
+			break
+		}
+		frame := int(v.position)
+		next := frame + 1
+		if v.loop && next >= loopEnd {
+			next = loopStart
+		} else if next >= total {
+			next = frame
+		}
+		t := float32(v.position - float64(frame))
+		l := src.Sample(frame, 0)
+		l += (src.Sample(next, 0) - l) * t
+		r := l
+		if stereo {
+			r = src.Sample(frame, 1)
+			r += (src.Sample(next, 1) - r) * t
+		}
+		gainL += stepL
+		gainR += stepR
+		out[i*OutputChannels] += l * gainL
+		out[i*OutputChannels+1] += r * gainR
+		v.position += step
+	}
+	v.gainL, v.gainR = targetL, targetR
+}
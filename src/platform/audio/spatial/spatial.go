@@ -0,0 +1,272 @@
+/******************************************************************************/
+/* spatial.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package spatial
+
+import "kaiju/matrix"
+
+// DefaultSpeedOfSound is the speed of sound in air, in units per second,
+// assuming that 1 unit is 1 meter
+const DefaultSpeedOfSound = 343.3
+
+// AttenuationModel is how the volume of an emitter falls off with distance
+type AttenuationModel int
+
+const (
+	// AttenuationNone keeps the volume the same at any distance
+	AttenuationNone AttenuationModel = iota
+	// AttenuationInverse falls off quickly near the emitter and slowly
+	// further away, which is the closest to how sound behaves in the world
+	AttenuationInverse
+	// AttenuationLinear falls off evenly from the min distance to silence
+	// at the max distance
+	AttenuationLinear
+	// AttenuationExponential falls off by a power of the distance
+	AttenuationExponential
+)
+
+// Listener is the position and orientation that emitters are heard from,
+// typically this follows the camera
+type Listener struct {
+	Position matrix.Vec3
+	Velocity matrix.Vec3
+	Forward  matrix.Vec3
+	Up       matrix.Vec3
+	placed   bool
+}
+
+// NewListener creates a listener at the origin looking down the forward axis
+func NewListener() Listener {
+	return Listener{
+		Forward: matrix.Vec3Forward(),
+		Up:      matrix.Vec3Up(),
+	}
+}
+
+// Right returns the direction of the listener's right ear
+func (l *Listener) Right() matrix.Vec3 {
+	return matrix.Vec3Cross(l.Forward, l.Up).Normal()
+}
+
+// Update moves the listener to the new position and orientation, the
+// velocity is worked out from how far the listener moved in the time. The
+// first update only places the listener, so it doesn't appear to have moved
+// there from the origin.
+func (l *Listener) Update(position, forward, up matrix.Vec3, deltaTime float64) {
+	l.Velocity = velocity(l.Position, position, deltaTime, l.placed)
+	l.placed = true
+	l.Position = position
+	l.Forward = forward.Normal()
+	l.Up = up.Normal()
+}
+
+// Cone makes an emitter directional, it is at full volume inside of the
+// inner angle and fades to the outer gain at the outer angle. The angles
+// are the full width of the cone in degrees, an inner angle of 360 makes the
+// emitter the same volume in all directions.
+type Cone struct {
+	InnerAngle matrix.Float
+	OuterAngle matrix.Float
+	OuterGain  matrix.Float
+}
+
+// OmniCone returns a cone that plays the same in all directions
+func OmniCone() Cone {
+	return Cone{InnerAngle: 360, OuterAngle: 360, OuterGain: 1}
+}
+
+// Gain returns the volume of the cone pointing in the forward direction for
+// a listener in the toListener direction
+func (c Cone) Gain(forward, toListener matrix.Vec3) matrix.Float {
+	if c.InnerAngle >= 360 || forward.IsZero() || toListener.IsZero() {
+		return 1
+	}
+	cos := matrix.Clamp(matrix.Vec3Dot(forward.Normal(), toListener.Normal()), -1, 1)
+	angle := matrix.Rad2Deg(matrix.Acos(cos)) * 2
+	inner := max(c.InnerAngle, 0)
+	outer := max(c.OuterAngle, inner)
+	if angle <= inner {
+		return 1
+	} else if angle >= outer {
+		return c.OuterGain
+	}
+	t := (angle - inner) / (outer - inner)
+	return 1 + (c.OuterGain-1)*t
+}
+
+// Emitter is the position and settings of a sound in the world
+type Emitter struct {
+	Position    matrix.Vec3
+	Velocity    matrix.Vec3
+	Forward     matrix.Vec3
+	Attenuation AttenuationModel
+	// MinDistance is the distance the emitter is at full volume within
+	MinDistance matrix.Float
+	// MaxDistance is the distance the emitter stops getting any quieter at,
+	// or is silent at for linear attenuation
+	MaxDistance matrix.Float
+	// Rolloff scales how quickly the volume falls off with distance
+	Rolloff matrix.Float
+	Cone    Cone
+	// DopplerFactor scales the pitch shift from the emitter and listener
+	// moving towards or away from each other, 0 disables it
+	DopplerFactor matrix.Float
+	// SpeedOfSound is used for the Doppler shift, 0 uses the default
+	SpeedOfSound matrix.Float
+	placed       bool
+}
+
+// NewEmitter creates emitter settings with an inverse distance falloff that
+// is at full volume within 1 unit, and is heard in all directions
+func NewEmitter() Emitter {
+	return Emitter{
+		Forward:       matrix.Vec3Forward(),
+		Attenuation:   AttenuationInverse,
+		MinDistance:   1,
+		MaxDistance:   100,
+		Rolloff:       1,
+		Cone:          OmniCone(),
+		DopplerFactor: 1,
+		SpeedOfSound:  DefaultSpeedOfSound,
+	}
+}
+
+// Update moves the emitter to the new position and direction, the velocity
+// is worked out from how far the emitter moved in the time. Like the
+// listener, the first update only places the emitter.
+func (e *Emitter) Update(position, forward matrix.Vec3, deltaTime float64) {
+	e.Velocity = velocity(e.Position, position, deltaTime, e.placed)
+	e.placed = true
+	e.Position = position
+	e.Forward = forward
+}
+
+// Result is how an emitter should be played to sound like it is coming
+// from its place in the world
+type Result struct {
+	// Gain is the linear volume scale from distance and the cone
+	Gain float32
+	// Pan is the stereo position from -1 (left) to 1 (right)
+	Pan float32
+	// Pitch is the playback speed scale from the Doppler shift
+	Pitch float32
+}
+
+// Attenuation returns the volume scale of a sound at the distance for the
+// model. The distance is clamped between the min and max distance, which
+// matches the clamped distance models of OpenAL.
+func Attenuation(model AttenuationModel, distance, minDistance, maxDistance, rolloff matrix.Float) matrix.Float {
+	minDistance = max(minDistance, 0)
+	maxDistance = max(maxDistance, minDistance)
+	distance = matrix.Clamp(distance, minDistance, maxDistance)
+	switch model {
+	case AttenuationInverse:
+		denominator := minDistance + rolloff*(distance-minDistance)
+		if denominator <= 0 {
+			return 1
+		}
+		return minDistance / denominator
+	case AttenuationLinear:
+		if maxDistance <= minDistance {
+			return 1
+		}
+		gain := 1 - rolloff*(distance-minDistance)/(maxDistance-minDistance)
+		return matrix.Clamp(gain, 0, 1)
+	case AttenuationExponential:
+		if minDistance <= 0 {
+			return 1
+		}
+		return matrix.Pow(distance/minDistance, -rolloff)
+	default:
+		return 1
+	}
+}
+
+// Pan returns the stereo position of a sound in the direction from the
+// listener, where -1 is fully left and 1 is fully right. Sounds in front of,
+// behind, above or below the listener are centered.
+func Pan(listener *Listener, direction matrix.Vec3) matrix.Float {
+	if direction.IsZero() {
+		return 0
+	}
+	return matrix.Clamp(matrix.Vec3Dot(direction.Normal(), listener.Right()), -1, 1)
+}
+
+// DopplerPitch returns the pitch scale of a sound heard by a listener from
+// an emitter as they move relative to each other. Movement towards each
+// other raises the pitch and movement away lowers it. This uses the same
+// formula as OpenAL, the speeds are clamped just under the speed of sound.
+func DopplerPitch(listener *Listener, emitter *Emitter) matrix.Float {
+	if emitter.DopplerFactor <= 0 {
+		return 1
+	}
+	speed := emitter.SpeedOfSound
+	if speed <= 0 {
+		speed = DefaultSpeedOfSound
+	}
+	toListener := listener.Position.Subtract(emitter.Position)
+	distance := toListener.Length()
+	if distance <= matrix.Tiny {
+		return 1
+	}
+	limit := speed / emitter.DopplerFactor * 0.99
+	listenerSpeed := min(matrix.Vec3Dot(toListener, listener.Velocity)/distance, limit)
+	emitterSpeed := min(matrix.Vec3Dot(toListener, emitter.Velocity)/distance, limit)
+	return (speed - emitter.DopplerFactor*listenerSpeed) /
+		(speed - emitter.DopplerFactor*emitterSpeed)
+}
+
+// Spatialize works out how the emitter sounds to the listener
+func Spatialize(listener *Listener, emitter *Emitter) Result {
+	toEmitter := emitter.Position.Subtract(listener.Position)
+	distance := toEmitter.Length()
+	gain := Attenuation(emitter.Attenuation, distance,
+		emitter.MinDistance, emitter.MaxDistance, emitter.Rolloff)
+	gain *= emitter.Cone.Gain(emitter.Forward, toEmitter.Negative())
+	return Result{
+		Gain:  float32(gain),
+		Pan:   float32(Pan(listener, toEmitter)),
+		Pitch: float32(DopplerPitch(listener, emitter)),
+	}
+}
+
+func velocity(from, to matrix.Vec3, deltaTime float64, placed bool) matrix.Vec3 {
+	if !placed || deltaTime <= 0 {
+		return matrix.Vec3Zero()
+	}
+	return to.Subtract(from).Shrink(matrix.Float(deltaTime))
+}
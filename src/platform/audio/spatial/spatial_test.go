@@ -0,0 +1,129 @@
+/******************************************************************************/
+/* spatial_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package spatial
+
+import (
+	"kaiju/matrix"
+	"testing"
+)
+
+func testApprox(t *testing.T, name string, got, expected matrix.Float) {
+	t.Helper()
+	if matrix.Abs(got-expected) > 0.001 {
+		t.Errorf("%s expected %f, got %f", name, expected, got)
+	}
+}
+
+func TestAttenuation(t *testing.T) {
+	testApprox(t, "none", Attenuation(AttenuationNone, 50, 1, 100, 1), 1)
+	testApprox(t, "inside min", Attenuation(AttenuationInverse, 0.5, 1, 100, 1), 1)
+	testApprox(t, "inverse", Attenuation(AttenuationInverse, 4, 1, 100, 1), 0.25)
+	testApprox(t, "inverse clamped", Attenuation(AttenuationInverse, 1000, 1, 100, 1), 0.01)
+	testApprox(t, "inverse rolloff", Attenuation(AttenuationInverse, 3, 1, 100, 0.5), 0.5)
+	testApprox(t, "linear", Attenuation(AttenuationLinear, 50.5, 1, 100, 1), 0.5)
+	testApprox(t, "linear max", Attenuation(AttenuationLinear, 200, 1, 100, 1), 0)
+	testApprox(t, "exponential", Attenuation(AttenuationExponential, 4, 2, 100, 2), 0.25)
+}
+
+func TestPan(t *testing.T) {
+	l := NewListener()
+	testApprox(t, "right", Pan(&l, matrix.Vec3Right()), 1)
+	testApprox(t, "left", Pan(&l, matrix.Vec3{-5, 0, 0}), -1)
+	testApprox(t, "front", Pan(&l, matrix.Vec3Forward()), 0)
+	testApprox(t, "behind", Pan(&l, matrix.Vec3Backward()), 0)
+	testApprox(t, "above", Pan(&l, matrix.Vec3Up()), 0)
+	testApprox(t, "same place", Pan(&l, matrix.Vec3Zero()), 0)
+	// Turning the listener to face right puts the right side behind them
+	l.Forward = matrix.Vec3Right()
+	testApprox(t, "turned", Pan(&l, matrix.Vec3Right()), 0)
+	testApprox(t, "turned behind", Pan(&l, matrix.Vec3Backward()), 1)
+}
+
+func TestConeGain(t *testing.T) {
+	c := Cone{InnerAngle: 90, OuterAngle: 180, OuterGain: 0.2}
+	forward := matrix.Vec3Forward()
+	testApprox(t, "in front", c.Gain(forward, forward), 1)
+	testApprox(t, "inside inner", c.Gain(forward, matrix.Vec3{0.5, 0, -1}), 1)
+	testApprox(t, "between", c.Gain(forward, matrix.Vec3{0.9239, 0, -0.3827}), 0.6)
+	testApprox(t, "side", c.Gain(forward, matrix.Vec3Right()), 0.2)
+	testApprox(t, "behind", c.Gain(forward, matrix.Vec3Backward()), 0.2)
+	testApprox(t, "omni", OmniCone().Gain(forward, matrix.Vec3Backward()), 1)
+}
+
+func TestDopplerPitch(t *testing.T) {
+	l := NewListener()
+	e := NewEmitter()
+	e.Position = matrix.Vec3{0, 0, -10}
+	testApprox(t, "still", DopplerPitch(&l, &e), 1)
+	e.Velocity = matrix.Vec3{0, 0, DefaultSpeedOfSound / 2}
+	testApprox(t, "approaching", DopplerPitch(&l, &e), 2)
+	e.Velocity = matrix.Vec3{0, 0, -DefaultSpeedOfSound}
+	testApprox(t, "leaving", DopplerPitch(&l, &e), 0.5)
+	e.Velocity = matrix.Vec3{DefaultSpeedOfSound / 2, 0, 0}
+	testApprox(t, "passing", DopplerPitch(&l, &e), 1)
+	e.Velocity = matrix.Vec3Zero()
+	l.Velocity = matrix.Vec3{0, 0, -DefaultSpeedOfSound / 2}
+	testApprox(t, "listener approaching", DopplerPitch(&l, &e), 1.5)
+	e.DopplerFactor = 0
+	testApprox(t, "disabled", DopplerPitch(&l, &e), 1)
+}
+
+func TestSpatialize(t *testing.T) {
+	l := NewListener()
+	l.Update(matrix.Vec3{0, 0, 5}, matrix.Vec3Forward(), matrix.Vec3Up(), 1)
+	if !l.Velocity.IsZero() {
+		t.Errorf("expected the first update to only place the listener, got %v", l.Velocity)
+	}
+	l.Update(matrix.Vec3{0, 0, 10}, matrix.Vec3Forward(), matrix.Vec3Up(), 0.5)
+	if !matrix.Vec3Approx(l.Velocity, matrix.Vec3{0, 0, 10}) {
+		t.Errorf("expected the listener velocity from its movement, got %v", l.Velocity)
+	}
+	l.Velocity = matrix.Vec3Zero()
+	e := NewEmitter()
+	e.Position = matrix.Vec3{4, 0, 10}
+	e.Forward = matrix.Vec3Left()
+	e.Cone = Cone{InnerAngle: 30, OuterAngle: 60, OuterGain: 0.5}
+	r := Spatialize(&l, &e)
+	testApprox(t, "gain", matrix.Float(r.Gain), 0.25)
+	testApprox(t, "pan", matrix.Float(r.Pan), 1)
+	testApprox(t, "pitch", matrix.Float(r.Pitch), 1)
+	// Facing away from the listener drops it to the outer gain of the cone
+	e.Forward = matrix.Vec3Right()
+	r = Spatialize(&l, &e)
+	testApprox(t, "facing away", matrix.Float(r.Gain), 0.125)
+}
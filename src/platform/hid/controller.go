@@ -85,6 +85,7 @@ type ControllerDevice struct {
 	buttons [ControllerButtonMax]int
 	axis    [ControllerAxisMax]float32
 	id      int
+	guid    string
 }
 
 type Controller struct {
@@ -131,9 +132,28 @@ func (c *Controller) Disconnected(id int) {
 	err := validateJoystick(id)
 	if err == nil && c.devices[id].id >= 0 {
 		c.devices[id].id = -1
+		c.devices[id].guid = ""
 	}
 }
 
+// SetGUID sets the GUID (in the SDL game controller database format) of the
+// device for the given controller. This is called automatically by the
+// system and should not be called by the end-developer
+func (c *Controller) SetGUID(id int, guid string) {
+	if validateJoystick(id) == nil {
+		c.devices[id].guid = guid
+	}
+}
+
+// GUID returns the GUID of the device for the given controller, which is
+// empty if the controller isn't connected or the platform doesn't know it
+func (c *Controller) GUID(id int) string {
+	if validateJoystick(id) != nil {
+		return ""
+	}
+	return c.devices[id].guid
+}
+
 func (device *ControllerDevice) endUpdate() {
 	if device.id >= 0 {
 		for i := 0; i < ControllerButtonMax; i++ {
@@ -39,24 +39,76 @@ package hid
 
 import (
 	"kaiju/matrix"
+	"time"
+)
+
+const (
+	// emulatedFingerId is the id of the finger made by the left mouse button
+	// when emulating gestures
+	emulatedFingerId = -1000 - iota
+	// emulatedPinchIds are the ids of the two fingers made by the right mouse
+	// button when emulating gestures
+	emulatedPinchIdA
+	emulatedPinchIdB
 )
 
 type Cursor struct {
-	mouse    *Mouse
-	touch    *Touch
-	stylus   *Stylus
-	pos      matrix.Vec2
-	lastPos  matrix.Vec2
-	pressure float32
-	distance float32
+	// Gestures recognizes the gestures of the touch device, or the mouse when
+	// gesture emulation is on
+	Gestures        GestureRecognizer
+	mouse           *Mouse
+	touch           *Touch
+	stylus          *Stylus
+	pos             matrix.Vec2
+	lastPos         matrix.Vec2
+	pressure        float32
+	distance        float32
+	contacts        []GestureContact
+	start           time.Time
+	emulateGestures bool
 }
 
 func NewCursor(mouse *Mouse, touch *Touch, stylus *Stylus) Cursor {
 	return Cursor{
-		mouse:  mouse,
-		touch:  touch,
-		stylus: stylus,
+		Gestures: NewGestureRecognizer(DefaultGestureSettings()),
+		mouse:    mouse,
+		touch:    touch,
+		stylus:   stylus,
+		contacts: make([]GestureContact, 0, MaxTouchPointersAvailable),
+		start:    time.Now(),
+	}
+}
+
+// SetGestureEmulation turns on making gestures from the mouse so that touch
+// controls can be tested on desktop. The left button acts as one finger. The
+// right button acts as two fingers mirrored around the center of the window,
+// so dragging towards or away from the center pinches and dragging around it
+// rotates.
+func (c *Cursor) SetGestureEmulation(enabled bool) { c.emulateGestures = enabled }
+
+// GestureEmulation returns true if gestures are being made from the mouse
+func (c *Cursor) GestureEmulation() bool { return c.emulateGestures }
+
+// GestureContacts returns the fingers that are down for gesture recognition.
+// The touch device is used when it has pointers down, otherwise the mouse is
+// used if gesture emulation is on.
+func (c *Cursor) GestureContacts() []GestureContact {
+	c.contacts = TouchContacts(c.touch, c.contacts[:0])
+	if len(c.contacts) > 0 || !c.emulateGestures {
+		return c.contacts
+	}
+	m := c.mouse
+	pos := m.ScreenPosition()
+	if m.Pressed(MouseButtonLeft) || m.Held(MouseButtonLeft) {
+		c.contacts = append(c.contacts, GestureContact{emulatedFingerId, pos})
+	} else if m.Pressed(MouseButtonRight) || m.Held(MouseButtonRight) {
+		center := matrix.Vec2{pos.X() - matrix.Float(m.CX), pos.Y() + matrix.Float(m.CY)}
+		mirror := center.Scale(2).Subtract(pos)
+		c.contacts = append(c.contacts,
+			GestureContact{emulatedPinchIdA, pos},
+			GestureContact{emulatedPinchIdB, mirror})
 	}
+	return c.contacts
 }
 
 func (c *Cursor) Moved() bool {
@@ -84,6 +136,7 @@ func (c *Cursor) Poll() {
 		c.pressure = c.stylus.Pressure
 	}
 	c.distance = c.stylus.Distance
+	c.Gestures.Update(c.GestureContacts(), time.Since(c.start).Seconds())
 }
 
 func (c *Cursor) ScreenPosition() matrix.Vec2 {
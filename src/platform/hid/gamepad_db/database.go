@@ -0,0 +1,186 @@
+/******************************************************************************/
+/* database.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+// Package gamepad_db reads the SDL game controller database format
+// (gamecontrollerdb.txt), which maps the raw buttons and axes of hundreds of
+// gamepad models to a standard layout. The mapped input is then passed
+// through per stick deadzones and response curves before being given to a
+// #hid.Controller.
+package gamepad_db
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Database holds the mappings for each device GUID. Mappings added later
+// replace earlier ones with the same GUID, which is how custom mappings
+// override the ones that ship with the game. It is safe to use from any
+// goroutine.
+type Database struct {
+	mutex    sync.RWMutex
+	mappings map[string]*Mapping
+	platform string
+}
+
+// NewDatabase creates an empty database that keeps the mappings for the
+// platform the game is running on
+func NewDatabase() *Database {
+	return &Database{
+		mappings: make(map[string]*Mapping),
+		platform: CurrentPlatform(),
+	}
+}
+
+// CurrentPlatform returns the name SDL uses for the current platform in the
+// platform field of its mappings
+func CurrentPlatform() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "Windows"
+	case "darwin":
+		return "Mac OS X"
+	case "android":
+		return "Android"
+	case "ios":
+		return "iOS"
+	default:
+		return "Linux"
+	}
+}
+
+// SetPlatform changes which platform's mappings are kept, this only applies
+// to mappings that are added after it is called
+func (db *Database) SetPlatform(platform string) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	db.platform = platform
+}
+
+// Load reads every mapping in the SDL database format, one per line. Blank
+// lines, comments and mappings for other platforms are skipped. Lines that
+// fail to parse are logged and skipped so that one bad line doesn't lose
+// the whole database. The number of mappings added is returned.
+func (db *Database) Load(r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	count := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m, err := ParseMapping(line)
+		if err != nil {
+			slog.Warn("skipping an invalid gamepad mapping", "error", err)
+			continue
+		}
+		if db.add(m) {
+			count++
+		}
+	}
+	return count, scanner.Err()
+}
+
+// Add parses and adds a single mapping line, this is how custom mappings
+// (such as ones made in a remapping menu) are added at runtime. A mapping
+// for another platform is ignored.
+func (db *Database) Add(line string) error {
+	m, err := ParseMapping(line)
+	if err != nil {
+		return err
+	}
+	db.add(m)
+	return nil
+}
+
+func (db *Database) add(m *Mapping) bool {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if m.Platform != "" && !strings.EqualFold(m.Platform, db.platform) {
+		return false
+	}
+	db.mappings[m.GUID] = m
+	return true
+}
+
+// Remove deletes the mapping for the GUID
+func (db *Database) Remove(guid string) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	delete(db.mappings, strings.ToLower(guid))
+}
+
+// Len returns the number of mappings in the database
+func (db *Database) Len() int {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	return len(db.mappings)
+}
+
+// Lookup finds the mapping for the device GUID. Newer versions of SDL put a
+// CRC of the device name into the GUID, so if there isn't an exact match the
+// GUID is also tried without its CRC.
+func (db *Database) Lookup(guid string) (*Mapping, bool) {
+	guid = strings.ToLower(guid)
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	if m, ok := db.mappings[guid]; ok {
+		return m, true
+	}
+	if len(guid) == 32 {
+		m, ok := db.mappings[guid[:4]+"0000"+guid[8:]]
+		return m, ok
+	}
+	return nil, false
+}
+
+// CreateGUID builds the GUID SDL uses for a device from its bus type (such
+// as 0x03 for USB) and the vendor, product and version ids it reports
+func CreateGUID(bus, vendor, product, version uint16) string {
+	var b [16]byte
+	put := func(i int, v uint16) { b[i], b[i+1] = byte(v), byte(v>>8) }
+	put(0, bus)
+	put(4, vendor)
+	put(8, product)
+	put(12, version)
+	return hex.EncodeToString(b[:])
+}
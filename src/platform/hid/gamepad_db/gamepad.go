@@ -0,0 +1,161 @@
+/******************************************************************************/
+/* gamepad.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package gamepad_db
+
+import (
+	"kaiju/platform/hid"
+	"math"
+)
+
+type Curve int
+
+const (
+	// CurveLinear passes the stick through unchanged
+	CurveLinear = Curve(iota)
+	// CurveQuadratic gives finer control near the center of the stick
+	CurveQuadratic
+	// CurveCubic gives even finer control near the center of the stick
+	CurveCubic
+	// CurvePower raises the stick to the exponent of its settings
+	CurvePower
+)
+
+const (
+	DefaultStickDeadzone   = 0.15
+	DefaultTriggerDeadzone = 0.05
+)
+
+// StickSettings shape the input of a stick. The deadzones are radial so
+// that the stick isn't pulled towards the axes, and the range between them
+// is stretched so the output still goes from 0 to 1.
+type StickSettings struct {
+	// Deadzone is how far the stick has to move from the center to register
+	Deadzone float32
+	// OuterDeadzone is how far out the stick has to be to read as fully
+	// pushed, 0 is treated as 1
+	OuterDeadzone float32
+	Curve         Curve
+	// Exponent is used by #CurvePower
+	Exponent float32
+}
+
+// DefaultStickSettings returns a linear stick with the default deadzone
+func DefaultStickSettings() StickSettings {
+	return StickSettings{Deadzone: DefaultStickDeadzone, OuterDeadzone: 1, Exponent: 1}
+}
+
+// Apply shapes the x and y of a stick with the settings
+func (s StickSettings) Apply(x, y float32) (float32, float32) {
+	length := float32(math.Hypot(float64(x), float64(y)))
+	if length <= s.Deadzone || length == 0 {
+		return 0, 0
+	}
+	outer := s.OuterDeadzone
+	if outer <= s.Deadzone || outer > 1 {
+		outer = 1
+	}
+	scaled := s.shape(min((length-s.Deadzone)/(outer-s.Deadzone), 1))
+	return x / length * scaled, y / length * scaled
+}
+
+func (s StickSettings) shape(v float32) float32 {
+	switch s.Curve {
+	case CurveQuadratic:
+		return v * v
+	case CurveCubic:
+		return v * v * v
+	case CurvePower:
+		return float32(math.Pow(float64(v), float64(max(s.Exponent, 0.01))))
+	}
+	return v
+}
+
+// Gamepad converts the raw input of a device to the standard layout with
+// its mapping, shapes the sticks and triggers and gives the result to a
+// #hid.Controller
+type Gamepad struct {
+	Mapping         *Mapping
+	LeftStick       StickSettings
+	RightStick      StickSettings
+	TriggerDeadzone float32
+}
+
+// NewGamepad creates a gamepad with the default stick settings
+func NewGamepad(mapping *Mapping) *Gamepad {
+	return &Gamepad{
+		Mapping:         mapping,
+		LeftStick:       DefaultStickSettings(),
+		RightStick:      DefaultStickSettings(),
+		TriggerDeadzone: DefaultTriggerDeadzone,
+	}
+}
+
+// Process maps the raw state and applies the stick and trigger settings
+func (g *Gamepad) Process(raw RawState) State {
+	s := g.Mapping.Map(raw)
+	lx, ly := &s.Axes[hid.ControllerAxisLeftHorizontal], &s.Axes[hid.ControllerAxisLeftVertical]
+	*lx, *ly = g.LeftStick.Apply(*lx, *ly)
+	rx, ry := &s.Axes[hid.ControllerAxisRightHorizontal], &s.Axes[hid.ControllerAxisRightVertical]
+	*rx, *ry = g.RightStick.Apply(*rx, *ry)
+	for _, t := range []int{hid.ControllerAxisLeftTrigger, hid.ControllerAxisRightTrigger} {
+		v := s.Axes[t]
+		if v <= g.TriggerDeadzone {
+			s.Axes[t] = 0
+		} else {
+			s.Axes[t] = min((v-g.TriggerDeadzone)/(1-g.TriggerDeadzone), 1)
+		}
+	}
+	return s
+}
+
+// Update processes the raw state and writes it to the controller with the
+// id, this should be called by the platform each time the device is polled
+func (g *Gamepad) Update(controller *hid.Controller, id int, raw RawState) {
+	s := g.Process(raw)
+	controller.Connected(id)
+	for b, down := range s.Buttons {
+		if down {
+			controller.SetButtonDown(id, b)
+		} else {
+			controller.SetButtonUp(id, b)
+		}
+	}
+	for a, v := range s.Axes {
+		controller.SetAxis(id, a, v)
+	}
+}
@@ -0,0 +1,250 @@
+/******************************************************************************/
+/* gamepad_db_test.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package gamepad_db
+
+import (
+	"kaiju/platform/hid"
+	"math"
+	"strings"
+	"testing"
+)
+
+const testDB = `# Game Controller DB
+03000000de280000ff11000000000000,Steam Virtual Gamepad,a:b0,b:b1,back:b6,dpdown:h0.4,dpleft:h0.8,dpright:h0.2,dpup:h0.1,leftshoulder:b4,leftstick:b8,lefttrigger:+a2,leftx:a0,lefty:a1,rightshoulder:b5,rightstick:b9,righttrigger:-a2,rightx:a3,righty:a4,start:b7,x:b2,y:b3,platform:Windows,
+
+03000000de280000ff11000000000000,Steam Virtual Gamepad,a:b0,b:b1,platform:Linux,
+030000004c050000cc09000000000000,PS4 Controller,a:b1,b:b2,x:b0,y:b3,-leftx:b14,+leftx:b15,lefty:a1~,lefttrigger:a3,righttrigger:b7,paddle1:b16,platform:Windows,
+not a mapping
+`
+
+func testLoad(t *testing.T) *Database {
+	t.Helper()
+	db := NewDatabase()
+	db.SetPlatform("Windows")
+	count, err := db.Load(strings.NewReader(testDB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || db.Len() != 2 {
+		t.Fatalf("expected 2 mappings for Windows, got %d (%d)", count, db.Len())
+	}
+	return db
+}
+
+func testApprox(t *testing.T, name string, got, expected float32) {
+	t.Helper()
+	if math.Abs(float64(got-expected)) > 0.0001 {
+		t.Errorf("%s expected %f, got %f", name, expected, got)
+	}
+}
+
+func TestLoadAndLookup(t *testing.T) {
+	db := testLoad(t)
+	m, ok := db.Lookup("03000000DE280000FF11000000000000")
+	if !ok || m.Name != "Steam Virtual Gamepad" || m.Platform != "Windows" {
+		t.Fatalf("expected the Windows Steam mapping, got %+v", m)
+	}
+	// A GUID with a name CRC in it still finds the mapping without one
+	if _, ok := db.Lookup("03001a2bde280000ff11000000000000"); !ok {
+		t.Error("expected the lookup to ignore the CRC")
+	}
+	if _, ok := db.Lookup("05000000de280000ff11000000000000"); ok {
+		t.Error("expected an unknown GUID to not be found")
+	}
+	if guid := CreateGUID(0x03, 0x28de, 0x11ff, 0); guid != "03000000de280000ff11000000000000" {
+		t.Errorf("unexpected GUID %s", guid)
+	}
+}
+
+func TestMap(t *testing.T) {
+	db := testLoad(t)
+	m, _ := db.Lookup("03000000de280000ff11000000000000")
+	raw := RawState{
+		Buttons: make([]bool, 10),
+		Axes:    []float32{0.5, -1, 0.75, 0, 0},
+		Hats:    []uint8{1 | 8},
+	}
+	raw.Buttons[0] = true
+	raw.Buttons[7] = true
+	s := m.Map(raw)
+	for _, b := range []int{hid.ControllerButtonA, hid.ControllerButtonStart,
+		hid.ControllerButtonUp, hid.ControllerButtonLeft} {
+		if !s.Buttons[b] {
+			t.Errorf("expected button %d to be down", b)
+		}
+	}
+	if s.Buttons[hid.ControllerButtonB] || s.Buttons[hid.ControllerButtonDown] {
+		t.Error("expected B and down to be up")
+	}
+	testApprox(t, "leftx", s.Axes[hid.ControllerAxisLeftHorizontal], 0.5)
+	testApprox(t, "lefty", s.Axes[hid.ControllerAxisLeftVertical], -1)
+	// The shared trigger axis is split into its two halves
+	testApprox(t, "left trigger", s.Axes[hid.ControllerAxisLeftTrigger], 0.75)
+	testApprox(t, "right trigger", s.Axes[hid.ControllerAxisRightTrigger], 0)
+	raw.Axes[2] = -0.5
+	s = m.Map(raw)
+	testApprox(t, "left trigger released", s.Axes[hid.ControllerAxisLeftTrigger], 0)
+	testApprox(t, "right trigger", s.Axes[hid.ControllerAxisRightTrigger], 0.5)
+}
+
+func TestMapHalfAxesAndInversion(t *testing.T) {
+	db := testLoad(t)
+	m, _ := db.Lookup("030000004c050000cc09000000000000")
+	raw := RawState{Buttons: make([]bool, 17), Axes: []float32{0, 0.5, 0, -1}}
+	raw.Buttons[1] = true
+	raw.Buttons[14] = true
+	raw.Buttons[7] = true
+	s := m.Map(raw)
+	if !s.Buttons[hid.ControllerButtonA] || s.Buttons[hid.ControllerButtonX] {
+		t.Error("expected the remapped face buttons")
+	}
+	testApprox(t, "leftx from buttons", s.Axes[hid.ControllerAxisLeftHorizontal], -1)
+	testApprox(t, "inverted lefty", s.Axes[hid.ControllerAxisLeftVertical], -0.5)
+	// A full range trigger axis rests at -1
+	testApprox(t, "left trigger", s.Axes[hid.ControllerAxisLeftTrigger], 0)
+	testApprox(t, "button trigger", s.Axes[hid.ControllerAxisRightTrigger], 1)
+	raw.Buttons[15] = true
+	s = m.Map(raw)
+	testApprox(t, "both leftx buttons", s.Axes[hid.ControllerAxisLeftHorizontal], 0)
+}
+
+func TestCustomMapping(t *testing.T) {
+	db := testLoad(t)
+	err := db.Add("03000000de280000ff11000000000000,Remapped,a:b1,b:b0,platform:Windows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _ := db.Lookup("03000000de280000ff11000000000000")
+	s := m.Map(RawState{Buttons: []bool{true, false}})
+	if m.Name != "Remapped" || !s.Buttons[hid.ControllerButtonB] || s.Buttons[hid.ControllerButtonA] {
+		t.Error("expected the custom mapping to replace the loaded one")
+	}
+	if err := db.Add("1234,Short GUID,a:b0"); err == nil {
+		t.Error("expected an error for an invalid GUID")
+	}
+	if err := db.Add("03000000de280000ff11000000000000,Bad,a:q0"); err == nil {
+		t.Error("expected an error for an invalid input")
+	}
+	db.Remove("03000000DE280000FF11000000000000")
+	if _, ok := db.Lookup("03000000de280000ff11000000000000"); ok {
+		t.Error("expected the mapping to be removed")
+	}
+}
+
+func TestStickSettings(t *testing.T) {
+	s := StickSettings{Deadzone: 0.2, OuterDeadzone: 0.9}
+	x, y := s.Apply(0.1, 0.1)
+	if x != 0 || y != 0 {
+		t.Errorf("expected the stick to be in the deadzone, got %f, %f", x, y)
+	}
+	// Radial, so the direction is kept and only the length is rescaled
+	x, y = s.Apply(0.33, 0.44)
+	testApprox(t, "x", x, 0.6*0.5)
+	testApprox(t, "y", y, 0.8*0.5)
+	x, y = s.Apply(0.95, 0)
+	testApprox(t, "outer deadzone", x, 1)
+	testApprox(t, "outer deadzone y", y, 0)
+	s.Curve = CurveQuadratic
+	x, _ = s.Apply(0.55, 0)
+	testApprox(t, "quadratic", x, 0.25)
+	s.Curve = CurveCubic
+	x, _ = s.Apply(0.55, 0)
+	testApprox(t, "cubic", x, 0.125)
+	s.Curve, s.Exponent = CurvePower, 0.5
+	x, _ = s.Apply(0.375, 0)
+	testApprox(t, "power", x, 0.5)
+}
+
+func TestGamepadUpdate(t *testing.T) {
+	db := testLoad(t)
+	m, _ := db.Lookup("03000000de280000ff11000000000000")
+	g := NewGamepad(m)
+	g.LeftStick.Deadzone = 0.2
+	c := hid.NewController()
+	raw := RawState{Buttons: make([]bool, 10), Axes: []float32{0, 0.6, 1, 0, 0}}
+	raw.Buttons[0] = true
+	g.Update(&c, 2, raw)
+	if !c.Available(2) || !c.IsButtonDown(2, hid.ControllerButtonA) {
+		t.Fatal("expected the controller to be connected with A down")
+	}
+	testApprox(t, "left x", c.Axis(2, hid.ControllerAxisLeftHorizontal), 0)
+	testApprox(t, "left y", c.Axis(2, hid.ControllerAxisLeftVertical), 0.5)
+	testApprox(t, "left trigger", c.Axis(2, hid.ControllerAxisLeftTrigger), 1)
+	c.EndUpdate()
+	raw.Buttons[0] = false
+	g.Update(&c, 2, raw)
+	if !c.IsButtonUp(2, hid.ControllerButtonA) {
+		t.Error("expected A to be released")
+	}
+}
+
+func TestGamepadsXInput(t *testing.T) {
+	db := NewDatabase()
+	gp := NewGamepads(db)
+	c := hid.NewController()
+	// A, the d-pad right and the left stick pushed fully up
+	raw := XInputRawState(0x1000|0x0008, 0, 255, 0, math.MaxInt16, 0, 0)
+	gp.Update(&c, 1, XInputGUID, raw)
+	if !c.Available(1) || c.GUID(1) != XInputGUID {
+		t.Fatal("expected the XInput gamepad to be connected with its GUID")
+	}
+	if !c.IsButtonDown(1, hid.ControllerButtonA) || !c.IsButtonDown(1, hid.ControllerButtonRight) {
+		t.Error("expected A and right to be down")
+	}
+	testApprox(t, "left y", c.Axis(1, hid.ControllerAxisLeftVertical), -1)
+	testApprox(t, "left trigger", c.Axis(1, hid.ControllerAxisLeftTrigger), 0)
+	testApprox(t, "right trigger", c.Axis(1, hid.ControllerAxisRightTrigger), 1)
+	gp.Gamepad(1).LeftStick.Deadzone = 0.5
+	if err := db.Add(XInputGUID + ",Remapped,a:b1,b:b0,lefty:a1~"); err != nil {
+		t.Fatal(err)
+	}
+	c.EndUpdate()
+	gp.Update(&c, 1, XInputGUID, XInputRawState(0x1000, 0, 0, 0, math.MaxInt16/2, 0, 0))
+	if !c.IsButtonDown(1, hid.ControllerButtonB) || !c.IsButtonUp(1, hid.ControllerButtonA) {
+		t.Error("expected the custom mapping to be used for the device")
+	}
+	testApprox(t, "left y in the deadzone", c.Axis(1, hid.ControllerAxisLeftVertical), 0)
+	gp.Update(&c, 2, "03000000de280000ff11000000000000", RawState{})
+	if c.Available(2) {
+		t.Error("expected a device without a mapping to not be connected")
+	}
+	gp.Disconnected(&c, 1)
+	if c.Available(1) || c.GUID(1) != "" || gp.Gamepad(1) != nil {
+		t.Error("expected the gamepad to be disconnected")
+	}
+}
@@ -0,0 +1,108 @@
+/******************************************************************************/
+/* gamepads.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package gamepad_db
+
+import "kaiju/platform/hid"
+
+// Gamepads keeps a #Gamepad for each device of a #hid.Controller. Each time
+// a device is polled its mapping is looked up in the database by its GUID,
+// so mappings that are added or replaced at runtime are used straight away,
+// while the stick and trigger settings of the device are kept. Devices that
+// have no mapping are treated as disconnected.
+type Gamepads struct {
+	Database *Database
+	devices  [hid.ControllerMaxDevices]*Gamepad
+}
+
+// NewGamepads creates the gamepads for the devices that are found in the
+// database
+func NewGamepads(db *Database) *Gamepads {
+	return &Gamepads{Database: db}
+}
+
+// Gamepad returns the gamepad for the controller id, which can be used to
+// change its stick and trigger settings. Nil is returned if the device has
+// not been mapped yet.
+func (g *Gamepads) Gamepad(id int) *Gamepad {
+	if id < 0 || id >= hid.ControllerMaxDevices {
+		return nil
+	}
+	return g.devices[id]
+}
+
+// Update looks up the mapping for the device GUID and gives its processed
+// raw state to the controller with the id, this should be called by the
+// platform each time the device is polled
+func (g *Gamepads) Update(controller *hid.Controller, id int, guid string, raw RawState) {
+	if id < 0 || id >= hid.ControllerMaxDevices {
+		return
+	}
+	m, ok := g.Database.Lookup(guid)
+	if !ok && guid == XInputGUID {
+		m, ok = xinputMapping, true
+	}
+	if !ok {
+		g.Disconnected(controller, id)
+		return
+	}
+	if g.devices[id] == nil {
+		g.devices[id] = NewGamepad(m)
+	}
+	g.devices[id].Mapping = m
+	g.devices[id].Update(controller, id, raw)
+	controller.SetGUID(id, guid)
+}
+
+// Disconnected releases everything on the controller with the id and marks
+// it as disconnected, the settings of its gamepad are reset for the next
+// device to be connected
+func (g *Gamepads) Disconnected(controller *hid.Controller, id int) {
+	if id < 0 || id >= hid.ControllerMaxDevices {
+		return
+	}
+	if g.devices[id] != nil || controller.Available(id) {
+		for b := range hid.ControllerButtonMax {
+			controller.SetButtonUp(id, b)
+		}
+		for a := range hid.ControllerAxisMax {
+			controller.SetAxis(id, a, 0)
+		}
+	}
+	g.devices[id] = nil
+	controller.Disconnected(id)
+}
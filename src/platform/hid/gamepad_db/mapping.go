@@ -0,0 +1,304 @@
+/******************************************************************************/
+/* mapping.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package gamepad_db
+
+import (
+	"errors"
+	"kaiju/platform/hid"
+	"strconv"
+	"strings"
+)
+
+type inputKind int
+
+const (
+	inputNone = inputKind(iota)
+	inputButton
+	inputAxis
+	inputHat
+)
+
+// axisRange is the part of an axis that is used, half axes are written with
+// a + or - in front of them
+type axisRange int
+
+const (
+	axisFull = axisRange(iota)
+	axisPositive
+	axisNegative
+)
+
+// PressThreshold is how far an axis has to move to press a button it is
+// mapped to
+const PressThreshold = 0.5
+
+var (
+	buttonTargets = map[string]int{
+		"a":             hid.ControllerButtonA,
+		"b":             hid.ControllerButtonB,
+		"x":             hid.ControllerButtonX,
+		"y":             hid.ControllerButtonY,
+		"back":          hid.ControllerButtonSelect,
+		"start":         hid.ControllerButtonStart,
+		"guide":         hid.ControllerButtonEx1,
+		"misc1":         hid.ControllerButtonEx2,
+		"leftshoulder":  hid.ControllerButtonLeftBumper,
+		"rightshoulder": hid.ControllerButtonRightBumper,
+		"leftstick":     hid.ControllerButtonLeftStick,
+		"rightstick":    hid.ControllerButtonRightStick,
+		"dpup":          hid.ControllerButtonUp,
+		"dpdown":        hid.ControllerButtonDown,
+		"dpleft":        hid.ControllerButtonLeft,
+		"dpright":       hid.ControllerButtonRight,
+	}
+	axisTargets = map[string]int{
+		"leftx":        hid.ControllerAxisLeftHorizontal,
+		"lefty":        hid.ControllerAxisLeftVertical,
+		"rightx":       hid.ControllerAxisRightHorizontal,
+		"righty":       hid.ControllerAxisRightVertical,
+		"lefttrigger":  hid.ControllerAxisLeftTrigger,
+		"righttrigger": hid.ControllerAxisRightTrigger,
+	}
+)
+
+// input is the raw button, axis or hat of the device that a part of the
+// standard layout reads from
+type input struct {
+	kind    inputKind
+	index   int
+	hatMask uint8
+	axis    axisRange
+	invert  bool
+}
+
+// binding is a single element of a mapping, such as "a:b0" or "+leftx:b3"
+type binding struct {
+	source input
+	target int
+	isAxis bool
+	// output is the part of the target axis that is written to
+	output axisRange
+}
+
+// RawState is the unmapped state of a device as it is read from the
+// platform. Axes are from -1 to 1, hats are a mask of 1 (up), 2 (right),
+// 4 (down) and 8 (left).
+type RawState struct {
+	Buttons []bool
+	Axes    []float32
+	Hats    []uint8
+}
+
+// State is the state of a device in the standard layout of #hid.Controller
+type State struct {
+	Buttons [hid.ControllerButtonMax]bool
+	Axes    [hid.ControllerAxisMax]float32
+}
+
+// Mapping converts the raw input of one model of gamepad to the standard
+// layout. It is made from a line of the SDL game controller database.
+type Mapping struct {
+	GUID     string
+	Name     string
+	Platform string
+	bindings []binding
+}
+
+// ParseMapping reads a single line of the SDL game controller database, in
+// the form "GUID,name,a:b0,b:b1,leftx:a0,...,platform:Windows,"
+func ParseMapping(line string) (*Mapping, error) {
+	fields := strings.Split(strings.TrimSpace(line), ",")
+	if len(fields) < 2 {
+		return nil, errors.New("the gamepad mapping needs a GUID and a name")
+	}
+	m := &Mapping{
+		GUID: strings.ToLower(strings.TrimSpace(fields[0])),
+		Name: strings.TrimSpace(fields[1]),
+	}
+	if len(m.GUID) != 32 {
+		return nil, errors.New("the gamepad mapping GUID " + m.GUID + " is not 32 characters")
+	}
+	for _, f := range fields[2:] {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(f, ":")
+		if !ok {
+			return nil, errors.New("the gamepad mapping element " + f + " is missing a value")
+		}
+		if key == "platform" {
+			m.Platform = value
+			continue
+		}
+		b, ok, err := parseBinding(key, value)
+		if err != nil {
+			return nil, errors.New("failed to read " + f + " for " + m.Name + ": " + err.Error())
+		}
+		// Elements SDL supports that the standard layout doesn't have, such
+		// as paddles and touchpads, are skipped
+		if ok {
+			m.bindings = append(m.bindings, b)
+		}
+	}
+	return m, nil
+}
+
+func parseBinding(key, value string) (binding, bool, error) {
+	b := binding{}
+	switch key[0] {
+	case '+':
+		b.output, key = axisPositive, key[1:]
+	case '-':
+		b.output, key = axisNegative, key[1:]
+	}
+	if t, ok := buttonTargets[key]; ok {
+		b.target = t
+	} else if t, ok := axisTargets[key]; ok {
+		b.target, b.isAxis = t, true
+	} else {
+		return b, false, nil
+	}
+	src, err := parseInput(value)
+	b.source = src
+	return b, true, err
+}
+
+func parseInput(value string) (input, error) {
+	in := input{}
+	if value == "" {
+		return in, errors.New("empty input")
+	}
+	switch value[0] {
+	case '+':
+		in.axis, value = axisPositive, value[1:]
+	case '-':
+		in.axis, value = axisNegative, value[1:]
+	}
+	if strings.HasSuffix(value, "~") {
+		in.invert, value = true, value[:len(value)-1]
+	}
+	if len(value) < 2 {
+		return in, errors.New("invalid input " + value)
+	}
+	switch value[0] {
+	case 'b':
+		in.kind = inputButton
+	case 'a':
+		in.kind = inputAxis
+	case 'h':
+		in.kind = inputHat
+		hat, mask, ok := strings.Cut(value[1:], ".")
+		if !ok {
+			return in, errors.New("the hat " + value + " is missing its mask")
+		}
+		m, err := strconv.ParseUint(mask, 10, 8)
+		if err != nil {
+			return in, err
+		}
+		in.hatMask = uint8(m)
+		value = "h" + hat
+	default:
+		return in, errors.New("unknown input " + value)
+	}
+	idx, err := strconv.Atoi(value[1:])
+	if err != nil || idx < 0 {
+		return in, errors.New("invalid input index " + value)
+	}
+	in.index = idx
+	return in, nil
+}
+
+// read returns the value of the raw input from 0 to 1 for buttons, hats and
+// half axes, or -1 to 1 for full axes
+func (in *input) read(raw *RawState) float32 {
+	var v float32
+	switch in.kind {
+	case inputButton:
+		if in.index < len(raw.Buttons) && raw.Buttons[in.index] {
+			v = 1
+		}
+	case inputHat:
+		if in.index < len(raw.Hats) && raw.Hats[in.index]&in.hatMask != 0 {
+			v = 1
+		}
+	case inputAxis:
+		if in.index < len(raw.Axes) {
+			v = raw.Axes[in.index]
+		}
+		switch in.axis {
+		case axisPositive:
+			v = max(v, 0)
+		case axisNegative:
+			v = max(-v, 0)
+		}
+	}
+	if in.invert {
+		v = -v
+	}
+	return v
+}
+
+// Map converts the raw state of the device to the standard layout
+func (m *Mapping) Map(raw RawState) State {
+	var s State
+	for i := range m.bindings {
+		b := &m.bindings[i]
+		v := b.source.read(&raw)
+		if !b.isAxis {
+			if v >= PressThreshold || v <= -PressThreshold {
+				s.Buttons[b.target] = true
+			}
+			continue
+		}
+		isTrigger := b.target == hid.ControllerAxisLeftTrigger ||
+			b.target == hid.ControllerAxisRightTrigger
+		fullSource := b.source.kind == inputAxis && b.source.axis == axisFull
+		switch {
+		case b.output == axisPositive:
+			v = max(v, 0)
+		case b.output == axisNegative:
+			v = -max(v, 0)
+		case isTrigger && fullSource:
+			// Triggers that rest at -1 are moved to rest at 0
+			v = (v + 1) / 2
+		}
+		s.Axes[b.target] = max(-1, min(s.Axes[b.target]+v, 1))
+	}
+	return s
+}
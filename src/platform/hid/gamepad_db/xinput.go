@@ -0,0 +1,105 @@
+/******************************************************************************/
+/* xinput.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package gamepad_db
+
+import "math"
+
+// XInputGUID is the GUID SDL gives XInput gamepads, XInput doesn't report
+// the vendor or product of the device so they all share it
+const XInputGUID = "78696e70757401000000000000000000"
+
+// XInputMapping is the mapping SDL uses for XInput gamepads, it is used for
+// them when the database doesn't have one
+const XInputMapping = XInputGUID + ",XInput Controller,a:b0,b:b1,back:b6,dpdown:h0.4,dpleft:h0.8,dpright:h0.2,dpup:h0.1,guide:b10,leftshoulder:b4,leftstick:b8,lefttrigger:a2,leftx:a0,lefty:a1,rightshoulder:b5,rightstick:b9,righttrigger:a5,rightx:a3,righty:a4,start:b7,x:b2,y:b3,"
+
+var xinputMapping = func() *Mapping {
+	m, err := ParseMapping(XInputMapping)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}()
+
+// xinputButtons are the XInput button flags in the order SDL numbers them
+var xinputButtons = [...]uint16{
+	0x1000, // A
+	0x2000, // B
+	0x4000, // X
+	0x8000, // Y
+	0x0100, // Left shoulder
+	0x0200, // Right shoulder
+	0x0020, // Back
+	0x0010, // Start
+	0x0040, // Left thumb
+	0x0080, // Right thumb
+	0x0400, // Guide
+}
+
+// XInputRawState converts the state of an XInput gamepad to the raw layout
+// SDL reads it with, which is the layout the XInput mappings in the database
+// are written against. The y axes are flipped so that down is positive and
+// the triggers rest at -1, as they do in SDL.
+func XInputRawState(buttons uint16, leftTrigger, rightTrigger uint8, thumbLX, thumbLY, thumbRX, thumbRY int16) RawState {
+	raw := RawState{
+		Buttons: make([]bool, len(xinputButtons)),
+		Axes: []float32{
+			xinputStick(thumbLX), -xinputStick(thumbLY), xinputTrigger(leftTrigger),
+			xinputStick(thumbRX), -xinputStick(thumbRY), xinputTrigger(rightTrigger),
+		},
+		Hats: []uint8{0},
+	}
+	for i, flag := range xinputButtons {
+		raw.Buttons[i] = buttons&flag != 0
+	}
+	// The d-pad flags are up, down, left, right and the hat is up, right,
+	// down, left
+	for i, mask := range [...]uint8{1, 4, 8, 2} {
+		if buttons&(1<<i) != 0 {
+			raw.Hats[0] |= mask
+		}
+	}
+	return raw
+}
+
+func xinputStick(v int16) float32 {
+	return max(float32(v)/math.MaxInt16, -1)
+}
+
+func xinputTrigger(v uint8) float32 {
+	return float32(v)/math.MaxUint8*2 - 1
+}
@@ -0,0 +1,181 @@
+/******************************************************************************/
+/* gesture.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package hid
+
+import "kaiju/matrix"
+
+type GestureKind int
+
+const (
+	GestureTap = GestureKind(iota)
+	GestureDoubleTap
+	GestureLongPress
+	GestureSwipe
+	GesturePan
+	GesturePinch
+	GestureRotate
+)
+
+// GesturePhase is where a continuous gesture (pan, pinch and rotate) is in
+// its life, gestures that happen all at once (tap, double tap, long press and
+// swipe) are always #GesturePhaseEnded
+type GesturePhase int
+
+const (
+	GesturePhaseBegan = GesturePhase(iota)
+	GesturePhaseChanged
+	GesturePhaseEnded
+)
+
+type SwipeDirection int
+
+const (
+	SwipeUp = SwipeDirection(iota)
+	SwipeDown
+	SwipeLeft
+	SwipeRight
+)
+
+// Gesture is the information given to the events of a #GestureRecognizer.
+// Positions are in window pixels with Y going down, the same as
+// #TouchPointer. Which fields are set depends on the kind of gesture.
+type Gesture struct {
+	Kind  GestureKind
+	Phase GesturePhase
+	// Position is where the gesture is now, for two finger gestures this is
+	// the point between the fingers
+	Position matrix.Vec2
+	// Start is where the gesture started
+	Start matrix.Vec2
+	// Delta is the movement since the last event of a pan
+	Delta matrix.Vec2
+	// Translation is the total movement of a pan or swipe
+	Translation matrix.Vec2
+	// Velocity is the speed of a pan or swipe in pixels per second
+	Velocity  matrix.Vec2
+	Direction SwipeDirection
+	// Scale is the distance between the fingers of a pinch relative to the
+	// distance when it started
+	Scale matrix.Float
+	// Rotation is the angle in radians the fingers of a rotate have turned
+	// since it started, positive is clockwise on screen
+	Rotation matrix.Float
+	// Duration is how long in seconds the fingers have been down
+	Duration float64
+}
+
+// GestureSettings are the thresholds used to tell gestures apart, distances
+// are in pixels and durations are in seconds
+type GestureSettings struct {
+	// TapMaxDuration is the longest a finger can be down for a tap
+	TapMaxDuration float64
+	// TapMaxDistance is how far a finger can move and still be a tap (or a
+	// long press), moving further starts a pan
+	TapMaxDistance matrix.Float
+	// DoubleTapInterval is the longest time between two taps of a double tap
+	DoubleTapInterval float64
+	// DoubleTapMaxDistance is how far apart the two taps of a double tap can be
+	DoubleTapMaxDistance matrix.Float
+	// LongPressDuration is how long a finger has to be held still
+	LongPressDuration float64
+	// SwipeMinDistance is how far a finger has to move for a swipe
+	SwipeMinDistance matrix.Float
+	// SwipeMaxDuration is the longest a swipe can take
+	SwipeMaxDuration float64
+	// PinchMinDistance is how much the distance between two fingers has to
+	// change before a pinch starts
+	PinchMinDistance matrix.Float
+	// RotateMinAngle is how far in radians two fingers have to turn before a
+	// rotate starts
+	RotateMinAngle matrix.Float
+}
+
+// DefaultGestureSettings returns thresholds similar to the ones used by the
+// mobile operating systems
+func DefaultGestureSettings() GestureSettings {
+	return GestureSettings{
+		TapMaxDuration:       0.3,
+		TapMaxDistance:       10,
+		DoubleTapInterval:    0.3,
+		DoubleTapMaxDistance: 40,
+		LongPressDuration:    0.5,
+		SwipeMinDistance:     50,
+		SwipeMaxDuration:     0.5,
+		PinchMinDistance:     10,
+		RotateMinAngle:       0.1,
+	}
+}
+
+type GestureEventId = int64
+
+type gestureEventEntry struct {
+	id   GestureEventId
+	call func(gesture *Gesture)
+}
+
+type GestureEvent struct {
+	nextId GestureEventId
+	calls  []gestureEventEntry
+}
+
+func (e GestureEvent) IsEmpty() bool { return len(e.calls) == 0 }
+
+func (e *GestureEvent) Add(call func(gesture *Gesture)) GestureEventId {
+	e.nextId++
+	e.calls = append(e.calls, gestureEventEntry{e.nextId, call})
+	return e.nextId
+}
+
+func (e *GestureEvent) Remove(id GestureEventId) {
+	for i := range e.calls {
+		if e.calls[i].id == id {
+			last := len(e.calls) - 1
+			e.calls[i], e.calls[last] = e.calls[last], e.calls[i]
+			e.calls = e.calls[:last]
+			return
+		}
+	}
+}
+
+func (e *GestureEvent) Clear() { e.calls = e.calls[:0] }
+
+func (e *GestureEvent) Execute(gesture *Gesture) {
+	for i := range e.calls {
+		e.calls[i].call(gesture)
+	}
+}
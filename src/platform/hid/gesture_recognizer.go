@@ -0,0 +1,369 @@
+/******************************************************************************/
+/* gesture_recognizer.go                                                      */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package hid
+
+import (
+	"kaiju/matrix"
+	"math"
+)
+
+// GestureContact is a finger that is down this frame
+type GestureContact struct {
+	Id       int64
+	Position matrix.Vec2
+}
+
+type trackedContact struct {
+	id        int64
+	start     matrix.Vec2
+	position  matrix.Vec2
+	previous  matrix.Vec2
+	startTime float64
+}
+
+// GestureRecognizer turns the fingers that are down each frame into tap,
+// double tap, long press, swipe, pan, pinch and rotate events. One finger
+// gestures are only recognized when a single finger was used for the whole
+// touch, so lifting one finger of a pinch doesn't start a pan.
+//
+// Every tap raises #GestureRecognizer.OnTap, the second tap of a double tap
+// also raises #GestureRecognizer.OnDoubleTap. This keeps taps from being
+// delayed while waiting to see if a second one comes.
+type GestureRecognizer struct {
+	Settings    GestureSettings
+	OnTap       GestureEvent
+	OnDoubleTap GestureEvent
+	OnLongPress GestureEvent
+	OnSwipe     GestureEvent
+	OnPan       GestureEvent
+	OnPinch     GestureEvent
+	OnRotate    GestureEvent
+	contacts    []trackedContact
+	time        float64
+	deltaTime   float64
+	// State of the current touch, from the first finger down to the last up
+	touchStart   float64
+	maxContacts  int
+	moved        bool
+	longPressed  bool
+	panning      bool
+	pinching     bool
+	rotating     bool
+	twoStarted   bool
+	twoDistance  matrix.Float
+	twoAngle     matrix.Float
+	twoStart     matrix.Vec2
+	lastStart    matrix.Vec2
+	lastVelocity matrix.Vec2
+	lastTapTime  float64
+	lastTapPos   matrix.Vec2
+	hasLastTap   bool
+}
+
+func NewGestureRecognizer(settings GestureSettings) GestureRecognizer {
+	return GestureRecognizer{
+		Settings: settings,
+		contacts: make([]trackedContact, 0, MaxTouchPointersAvailable),
+	}
+}
+
+// TouchContacts returns the pointers of the touch device that are down
+func TouchContacts(touch *Touch, contacts []GestureContact) []GestureContact {
+	for _, p := range touch.Pointers {
+		switch p.State {
+		case TouchActionUp, TouchActionCancel, TouchActionNone:
+			continue
+		}
+		contacts = append(contacts, GestureContact{
+			Id:       p.Id,
+			Position: matrix.Vec2{matrix.Float(p.X), matrix.Float(p.Y)},
+		})
+	}
+	return contacts
+}
+
+// IsTracking returns true while a finger is down
+func (r *GestureRecognizer) IsTracking() bool { return len(r.contacts) > 0 }
+
+// Update gives the recognizer the fingers that are down at the time (in
+// seconds), it should be called once a frame even when there are none
+func (r *GestureRecognizer) Update(contacts []GestureContact, time float64) {
+	r.deltaTime = max(time-r.time, 0)
+	r.time = time
+	wasTracking := len(r.contacts) > 0
+	lastPos := r.centroid()
+	count := len(r.contacts)
+	r.trackContacts(contacts)
+	if len(r.contacts) > 0 && !wasTracking {
+		r.beginTouch()
+	}
+	r.maxContacts = max(r.maxContacts, len(r.contacts))
+	if count == 2 && len(r.contacts) != 2 {
+		r.endTwoFinger()
+	}
+	switch {
+	case len(r.contacts) == 1 && r.maxContacts == 1:
+		r.updateOneFinger()
+	case len(r.contacts) == 2:
+		r.updateTwoFinger()
+	case len(r.contacts) == 0 && wasTracking:
+		r.endTouch(lastPos)
+	}
+}
+
+func (r *GestureRecognizer) trackContacts(contacts []GestureContact) {
+	kept := r.contacts[:0]
+	for i := range r.contacts {
+		for j := range contacts {
+			if contacts[j].Id == r.contacts[i].id {
+				c := r.contacts[i]
+				c.previous, c.position = c.position, contacts[j].Position
+				kept = append(kept, c)
+				break
+			}
+		}
+	}
+	r.contacts = kept
+	for i := range contacts {
+		found := false
+		for j := range r.contacts {
+			found = found || r.contacts[j].id == contacts[i].Id
+		}
+		if !found {
+			p := contacts[i].Position
+			r.contacts = append(r.contacts, trackedContact{
+				id: contacts[i].Id, start: p, position: p, previous: p, startTime: r.time,
+			})
+		}
+	}
+}
+
+func (r *GestureRecognizer) beginTouch() {
+	r.touchStart = r.time
+	r.maxContacts = 0
+	r.moved = false
+	r.longPressed = false
+	r.panning = false
+}
+
+func (r *GestureRecognizer) centroid() matrix.Vec2 {
+	var sum matrix.Vec2
+	for i := range r.contacts {
+		sum.AddAssign(r.contacts[i].position)
+	}
+	if len(r.contacts) > 0 {
+		sum.ScaleAssign(1 / matrix.Float(len(r.contacts)))
+	}
+	return sum
+}
+
+func (r *GestureRecognizer) gesture(kind GestureKind, phase GesturePhase) Gesture {
+	return Gesture{
+		Kind:     kind,
+		Phase:    phase,
+		Scale:    1,
+		Duration: r.time - r.touchStart,
+	}
+}
+
+func (r *GestureRecognizer) velocity(c *trackedContact) matrix.Vec2 {
+	if r.deltaTime <= 0 {
+		return matrix.Vec2Zero()
+	}
+	return c.position.Subtract(c.previous).Scale(matrix.Float(1 / r.deltaTime))
+}
+
+func (r *GestureRecognizer) updateOneFinger() {
+	c := &r.contacts[0]
+	r.lastStart, r.lastVelocity = c.start, r.velocity(c)
+	if c.position.Distance(c.start) > r.Settings.TapMaxDistance {
+		r.moved = true
+	}
+	if !r.moved && !r.longPressed && r.time-c.startTime >= r.Settings.LongPressDuration {
+		r.longPressed = true
+		g := r.gesture(GestureLongPress, GesturePhaseEnded)
+		g.Position, g.Start = c.position, c.start
+		r.OnLongPress.Execute(&g)
+	}
+	if !r.moved {
+		return
+	}
+	phase := GesturePhaseChanged
+	if !r.panning {
+		r.panning = true
+		phase = GesturePhaseBegan
+	} else if c.position.Equals(c.previous) {
+		return
+	}
+	g := r.gesture(GesturePan, phase)
+	g.Position, g.Start = c.position, c.start
+	g.Translation = c.position.Subtract(c.start)
+	if phase == GesturePhaseBegan {
+		g.Delta = g.Translation
+	} else {
+		g.Delta = c.position.Subtract(c.previous)
+	}
+	g.Velocity = r.lastVelocity
+	r.OnPan.Execute(&g)
+}
+
+func (r *GestureRecognizer) twoFingerShape() (matrix.Float, matrix.Float) {
+	d := r.contacts[1].position.Subtract(r.contacts[0].position)
+	return d.Length(), matrix.Float(math.Atan2(float64(d.Y()), float64(d.X())))
+}
+
+func (r *GestureRecognizer) updateTwoFinger() {
+	r.moved = true
+	if r.panning {
+		r.panning = false
+		c := &r.contacts[0]
+		g := r.gesture(GesturePan, GesturePhaseEnded)
+		g.Position, g.Start = c.position, c.start
+		g.Translation = c.position.Subtract(c.start)
+		r.OnPan.Execute(&g)
+	}
+	distance, angle := r.twoFingerShape()
+	center := r.centroid()
+	if !r.twoStarted {
+		// Fingers that start on top of each other have no direction or scale
+		// to measure from yet
+		if distance <= matrix.FloatSmallestNonzero {
+			return
+		}
+		r.twoStarted = true
+		r.twoDistance, r.twoAngle, r.twoStart = distance, angle, center
+		return
+	}
+	scale := distance / r.twoDistance
+	if r.pinching || matrix.Abs(distance-r.twoDistance) >= r.Settings.PinchMinDistance {
+		g := r.gesture(GesturePinch, GesturePhaseChanged)
+		if !r.pinching {
+			r.pinching = true
+			g.Phase = GesturePhaseBegan
+		}
+		g.Position, g.Start, g.Scale = center, r.twoStart, scale
+		r.OnPinch.Execute(&g)
+	}
+	rotation := angle - r.twoAngle
+	for rotation > math.Pi {
+		rotation -= 2 * math.Pi
+	}
+	for rotation < -math.Pi {
+		rotation += 2 * math.Pi
+	}
+	if r.rotating || matrix.Abs(rotation) >= r.Settings.RotateMinAngle {
+		g := r.gesture(GestureRotate, GesturePhaseChanged)
+		if !r.rotating {
+			r.rotating = true
+			g.Phase = GesturePhaseBegan
+		}
+		g.Position, g.Start, g.Rotation = center, r.twoStart, rotation
+		r.OnRotate.Execute(&g)
+	}
+}
+
+func (r *GestureRecognizer) endTwoFinger() {
+	if r.pinching {
+		g := r.gesture(GesturePinch, GesturePhaseEnded)
+		g.Start = r.twoStart
+		r.OnPinch.Execute(&g)
+	}
+	if r.rotating {
+		g := r.gesture(GestureRotate, GesturePhaseEnded)
+		g.Start = r.twoStart
+		r.OnRotate.Execute(&g)
+	}
+	r.pinching, r.rotating, r.twoStarted = false, false, false
+}
+
+// endTouch is called when the last finger is lifted, pos is where it was
+// last seen
+func (r *GestureRecognizer) endTouch(pos matrix.Vec2) {
+	if r.maxContacts != 1 {
+		return
+	}
+	start := r.lastStart
+	duration := r.time - r.touchStart
+	if r.panning {
+		r.panning = false
+		g := r.gesture(GesturePan, GesturePhaseEnded)
+		g.Position, g.Start = pos, start
+		g.Translation = pos.Subtract(start)
+		g.Velocity = r.lastVelocity
+		r.OnPan.Execute(&g)
+		if g.Translation.Length() >= r.Settings.SwipeMinDistance &&
+			duration <= r.Settings.SwipeMaxDuration {
+			s := r.gesture(GestureSwipe, GesturePhaseEnded)
+			s.Position, s.Start, s.Translation = pos, start, g.Translation
+			if duration > 0 {
+				s.Velocity = g.Translation.Scale(matrix.Float(1 / duration))
+			}
+			s.Direction = swipeDirection(g.Translation)
+			r.OnSwipe.Execute(&s)
+		}
+		return
+	}
+	if r.moved || r.longPressed || duration > r.Settings.TapMaxDuration {
+		return
+	}
+	g := r.gesture(GestureTap, GesturePhaseEnded)
+	g.Position, g.Start = pos, start
+	r.OnTap.Execute(&g)
+	if r.hasLastTap && r.touchStart-r.lastTapTime <= r.Settings.DoubleTapInterval &&
+		pos.Distance(r.lastTapPos) <= r.Settings.DoubleTapMaxDistance {
+		r.hasLastTap = false
+		g.Kind = GestureDoubleTap
+		r.OnDoubleTap.Execute(&g)
+		return
+	}
+	r.hasLastTap = true
+	r.lastTapTime, r.lastTapPos = r.time, pos
+}
+
+func swipeDirection(translation matrix.Vec2) SwipeDirection {
+	if matrix.Abs(translation.X()) >= matrix.Abs(translation.Y()) {
+		if translation.X() < 0 {
+			return SwipeLeft
+		}
+		return SwipeRight
+	}
+	if translation.Y() < 0 {
+		return SwipeUp
+	}
+	return SwipeDown
+}
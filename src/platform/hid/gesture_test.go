@@ -0,0 +1,234 @@
+/******************************************************************************/
+/* gesture_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package hid
+
+import (
+	"kaiju/matrix"
+	"math"
+	"testing"
+)
+
+type gestureLog struct {
+	gestures []Gesture
+}
+
+func (l *gestureLog) listen(r *GestureRecognizer) {
+	for _, e := range []*GestureEvent{&r.OnTap, &r.OnDoubleTap, &r.OnLongPress,
+		&r.OnSwipe, &r.OnPan, &r.OnPinch, &r.OnRotate} {
+		e.Add(func(g *Gesture) { l.gestures = append(l.gestures, *g) })
+	}
+}
+
+func (l *gestureLog) kinds() []GestureKind {
+	kinds := make([]GestureKind, len(l.gestures))
+	for i := range l.gestures {
+		kinds[i] = l.gestures[i].Kind
+	}
+	return kinds
+}
+
+func (l *gestureLog) last(kind GestureKind) *Gesture {
+	for i := len(l.gestures) - 1; i >= 0; i-- {
+		if l.gestures[i].Kind == kind {
+			return &l.gestures[i]
+		}
+	}
+	return nil
+}
+
+func testRecognizer() (*GestureRecognizer, *gestureLog) {
+	r := NewGestureRecognizer(DefaultGestureSettings())
+	l := &gestureLog{}
+	l.listen(&r)
+	return &r, l
+}
+
+func finger(id int64, x, y matrix.Float) GestureContact {
+	return GestureContact{Id: id, Position: matrix.Vec2{x, y}}
+}
+
+func TestGestureTapAndDoubleTap(t *testing.T) {
+	r, l := testRecognizer()
+	r.Update([]GestureContact{finger(1, 100, 100)}, 0)
+	r.Update([]GestureContact{finger(1, 103, 101)}, 0.05)
+	r.Update(nil, 0.1)
+	if len(l.gestures) != 1 || l.gestures[0].Kind != GestureTap {
+		t.Fatalf("expected a tap, got %v", l.kinds())
+	}
+	r.Update([]GestureContact{finger(2, 110, 100)}, 0.25)
+	r.Update(nil, 0.3)
+	if len(l.gestures) != 3 || l.gestures[2].Kind != GestureDoubleTap {
+		t.Fatalf("expected a tap and a double tap, got %v", l.kinds())
+	}
+	// A third tap starts over rather than being another double tap
+	r.Update([]GestureContact{finger(3, 110, 100)}, 0.4)
+	r.Update(nil, 0.45)
+	if len(l.gestures) != 4 || l.gestures[3].Kind != GestureTap {
+		t.Fatalf("expected only a tap, got %v", l.kinds())
+	}
+	// Holding too long isn't a tap
+	r.Update([]GestureContact{finger(4, 0, 0)}, 2)
+	r.Update(nil, 2.4)
+	if len(l.gestures) != 4 {
+		t.Fatalf("expected no tap, got %v", l.kinds())
+	}
+}
+
+func TestGestureLongPress(t *testing.T) {
+	r, l := testRecognizer()
+	r.Update([]GestureContact{finger(1, 50, 50)}, 0)
+	r.Update([]GestureContact{finger(1, 52, 50)}, 0.4)
+	if len(l.gestures) != 0 {
+		t.Fatalf("expected nothing yet, got %v", l.kinds())
+	}
+	r.Update([]GestureContact{finger(1, 52, 50)}, 0.5)
+	r.Update([]GestureContact{finger(1, 52, 50)}, 0.6)
+	r.Update(nil, 0.7)
+	if len(l.gestures) != 1 || l.gestures[0].Kind != GestureLongPress {
+		t.Fatalf("expected a single long press, got %v", l.kinds())
+	}
+}
+
+func TestGesturePanAndSwipe(t *testing.T) {
+	r, l := testRecognizer()
+	r.Update([]GestureContact{finger(1, 100, 100)}, 0)
+	r.Update([]GestureContact{finger(1, 105, 100)}, 0.05)
+	if len(l.gestures) != 0 {
+		t.Fatalf("expected the pan to wait for the threshold, got %v", l.kinds())
+	}
+	r.Update([]GestureContact{finger(1, 140, 90)}, 0.1)
+	r.Update([]GestureContact{finger(1, 200, 80)}, 0.2)
+	r.Update(nil, 0.25)
+	pans := 0
+	for _, g := range l.gestures {
+		if g.Kind == GesturePan {
+			pans++
+		}
+	}
+	if pans != 3 {
+		t.Fatalf("expected a pan began, changed and ended, got %v", l.kinds())
+	}
+	changed := l.gestures[1]
+	if changed.Phase != GesturePhaseChanged ||
+		!matrix.Vec2ApproxTo(changed.Delta, matrix.Vec2{60, -10}, 0.001) ||
+		!matrix.Vec2ApproxTo(changed.Velocity, matrix.Vec2{600, -100}, 0.01) {
+		t.Errorf("unexpected pan change %+v", changed)
+	}
+	swipe := l.last(GestureSwipe)
+	if swipe == nil || swipe.Direction != SwipeRight ||
+		!matrix.Vec2ApproxTo(swipe.Translation, matrix.Vec2{100, -20}, 0.001) {
+		t.Fatalf("expected a swipe to the right, got %+v", swipe)
+	}
+	// A slow drag is a pan but not a swipe
+	l.gestures = l.gestures[:0]
+	r.Update([]GestureContact{finger(2, 100, 100)}, 1)
+	r.Update([]GestureContact{finger(2, 100, 40)}, 1.5)
+	r.Update([]GestureContact{finger(2, 100, 0)}, 2)
+	r.Update(nil, 2.1)
+	if l.last(GestureSwipe) != nil || l.last(GesturePan) == nil {
+		t.Errorf("expected only a pan, got %v", l.kinds())
+	}
+	if swipeDirection(matrix.Vec2{5, -30}) != SwipeUp {
+		t.Error("expected an upward swipe")
+	}
+}
+
+func TestGesturePinchAndRotate(t *testing.T) {
+	r, l := testRecognizer()
+	r.Update([]GestureContact{finger(1, 100, 100)}, 0)
+	r.Update([]GestureContact{finger(1, 100, 100), finger(2, 200, 100)}, 0.05)
+	r.Update([]GestureContact{finger(1, 50, 100), finger(2, 250, 100)}, 0.1)
+	pinch := l.last(GesturePinch)
+	if pinch == nil || pinch.Phase != GesturePhaseBegan || matrix.Abs(pinch.Scale-2) > 0.001 {
+		t.Fatalf("expected the pinch to begin at twice the size, got %+v", pinch)
+	}
+	if !matrix.Vec2ApproxTo(pinch.Position, matrix.Vec2{150, 100}, 0.001) {
+		t.Errorf("expected the pinch between the fingers, got %v", pinch.Position)
+	}
+	if l.last(GestureRotate) != nil {
+		t.Error("expected no rotation")
+	}
+	// Turning the fingers a quarter turn around their center
+	r.Update([]GestureContact{finger(1, 150, 0), finger(2, 150, 200)}, 0.15)
+	rotate := l.last(GestureRotate)
+	if rotate == nil || matrix.Abs(rotate.Rotation-math.Pi/2) > 0.001 {
+		t.Fatalf("expected a quarter turn, got %+v", rotate)
+	}
+	// Lifting one finger ends both and doesn't start a pan or tap
+	r.Update([]GestureContact{finger(2, 150, 300)}, 0.2)
+	r.Update(nil, 0.25)
+	if p := l.last(GesturePinch); p.Phase != GesturePhaseEnded {
+		t.Error("expected the pinch to end")
+	}
+	if p := l.last(GestureRotate); p.Phase != GesturePhaseEnded {
+		t.Error("expected the rotate to end")
+	}
+	if l.last(GesturePan) != nil || l.last(GestureTap) != nil {
+		t.Errorf("expected no one finger gestures, got %v", l.kinds())
+	}
+}
+
+func TestCursorGestureEmulation(t *testing.T) {
+	mouse := NewMouse()
+	touch := NewTouch()
+	stylus := NewStylus()
+	c := NewCursor(&mouse, &touch, &stylus)
+	mouse.SetPosition(500, 300, 800, 600)
+	mouse.SetDown(MouseButtonLeft)
+	if len(c.GestureContacts()) != 0 {
+		t.Fatal("expected the mouse to be ignored without emulation")
+	}
+	c.SetGestureEmulation(true)
+	if contacts := c.GestureContacts(); len(contacts) != 1 ||
+		!matrix.Vec2ApproxTo(contacts[0].Position, matrix.Vec2{500, 300}, 0.001) {
+		t.Fatalf("expected one finger at the mouse, got %v", contacts)
+	}
+	mouse.SetUp(MouseButtonLeft)
+	mouse.EndUpdate()
+	mouse.SetDown(MouseButtonRight)
+	contacts := c.GestureContacts()
+	if len(contacts) != 2 ||
+		!matrix.Vec2ApproxTo(contacts[1].Position, matrix.Vec2{300, 300}, 0.001) {
+		t.Fatalf("expected a finger mirrored around the center, got %v", contacts)
+	}
+	// Real touches take priority over the mouse
+	touch.SetDown(7, 10, 20, 600)
+	if contacts := c.GestureContacts(); len(contacts) != 1 || contacts[0].Id != 7 {
+		t.Fatalf("expected the touch pointer, got %v", contacts)
+	}
+}
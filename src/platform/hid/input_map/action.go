@@ -0,0 +1,160 @@
+/******************************************************************************/
+/* action.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package input_map
+
+import (
+	"kaiju/matrix"
+)
+
+type ActionType = string
+
+const (
+	// ActionButton is an action that is either pressed or not, such as jump
+	ActionButton ActionType = "button"
+	// ActionAxis is an action with a value from -1 to 1, such as throttle
+	ActionAxis ActionType = "axis"
+	// ActionAxis2D is an action with a 2D value, such as movement
+	ActionAxis2D ActionType = "axis2d"
+)
+
+// PressThreshold is how far an axis bound to a button action has to move
+// for the action to be pressed, and how far an axis action has to move to
+// count as pressed
+const PressThreshold = 0.5
+
+// Action is a named input of the game, such as "jump" or "move", that is
+// triggered by any of its bindings. Gameplay reads the action rather than
+// the devices so that the controls can be rebound.
+type Action struct {
+	Name     string
+	Type     ActionType
+	Bindings []Binding
+	value    matrix.Vec2
+	down     bool
+	wasDown  bool
+}
+
+// Value returns the value of a button or axis action, a button is 1 when it
+// is held and 0 otherwise
+func (a *Action) Value() matrix.Float { return a.value.X() }
+
+// Vec2 returns the value of a 2D axis action
+func (a *Action) Vec2() matrix.Vec2 { return a.value }
+
+// Pressed returns true on the frame the action was pressed
+func (a *Action) Pressed() bool { return a.down && !a.wasDown }
+
+// Held returns true while the action is pressed, including the first frame
+func (a *Action) Held() bool { return a.down }
+
+// Released returns true on the frame the action stopped being pressed
+func (a *Action) Released() bool { return !a.down && a.wasDown }
+
+// AddBinding adds another way to trigger the action, an error is returned
+// if the binding has unknown controls
+func (a *Action) AddBinding(binding Binding) error {
+	if err := binding.compile(); err != nil {
+		return err
+	}
+	a.Bindings = append(a.Bindings, binding)
+	return nil
+}
+
+// SetBinding replaces the binding at the index, this is what is used when
+// the player rebinds a control
+func (a *Action) SetBinding(index int, binding Binding) error {
+	if err := binding.compile(); err != nil {
+		return err
+	}
+	a.Bindings[index] = binding
+	return nil
+}
+
+// RemoveBinding removes the binding at the index
+func (a *Action) RemoveBinding(index int) {
+	a.Bindings = append(a.Bindings[:index], a.Bindings[index+1:]...)
+}
+
+func (a *Action) compile() error {
+	for i := range a.Bindings {
+		if err := a.Bindings[i].compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// update reads the bindings, the binding with the largest value wins so
+// that two devices don't add up to more than full input
+func (a *Action) update(d *Devices, gamepad int, mouseDelta matrix.Vec2) {
+	a.wasDown = a.down
+	var best matrix.Vec2
+	bestLength := matrix.Float(0)
+	for i := range a.Bindings {
+		v := a.Bindings[i].read(d, gamepad, mouseDelta)
+		if l := v.Length(); l > bestLength {
+			best, bestLength = v, l
+		}
+	}
+	a.down = bestLength >= PressThreshold
+	switch a.Type {
+	case ActionAxis:
+		a.value = matrix.Vec2{best.X(), 0}
+	case ActionAxis2D:
+		a.value = best
+	default:
+		a.value = matrix.Vec2Zero()
+		if a.down {
+			a.value = matrix.Vec2{1, 0}
+		}
+	}
+}
+
+// clear releases the action while its context is blocked, so that it is
+// reported as released on the first blocked frame
+func (a *Action) clear() {
+	a.wasDown = a.down
+	a.down = false
+	a.value = matrix.Vec2Zero()
+}
+
+// reset puts the action back to having never been pressed
+func (a *Action) reset() {
+	a.clear()
+	a.wasDown = false
+}
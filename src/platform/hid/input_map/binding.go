@@ -0,0 +1,204 @@
+/******************************************************************************/
+/* binding.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package input_map
+
+import (
+	"errors"
+	"kaiju/matrix"
+	"strings"
+)
+
+const (
+	ModifierCtrl  = "ctrl"
+	ModifierShift = "shift"
+	ModifierAlt   = "alt"
+)
+
+// Binding ties an action to one or more controls. Only one of the groups of
+// fields should be set:
+//
+// [-] Control: a single button or axis, such as "keyboard/space"
+// [-] Negative and Positive: two buttons that make a 1D axis, such as "keyboard/a" and "keyboard/d"
+// [-] Up, Down, Left and Right: four buttons that make a 2D axis, such as WASD
+// [-] X and Y: two axes that make a 2D axis, such as "gamepad/leftx" and "gamepad/lefty"
+//
+// See #ControlNames for the names of the controls of each device.
+type Binding struct {
+	Control  string `json:",omitempty"`
+	Negative string `json:",omitempty"`
+	Positive string `json:",omitempty"`
+	Up       string `json:",omitempty"`
+	Down     string `json:",omitempty"`
+	Left     string `json:",omitempty"`
+	Right    string `json:",omitempty"`
+	X        string `json:",omitempty"`
+	Y        string `json:",omitempty"`
+	// Modifiers are the keys ("ctrl", "shift" or "alt") that must be held
+	// for the binding to have any effect
+	Modifiers []string `json:",omitempty"`
+	// Deadzone is how far an axis has to move before it registers, the rest
+	// of the range is scaled so that the value still starts from 0. Two axes
+	// use a radial deadzone so that diagonals aren't cut off.
+	Deadzone float32 `json:",omitempty"`
+	// Scale multiplies the value of the binding, a negative scale inverts
+	// it. 0 is treated as 1.
+	Scale    float32 `json:",omitempty"`
+	controls []control
+	mods     []string
+}
+
+// compile parses the control names of the binding so they don't need to be
+// looked up every frame
+func (b *Binding) compile() error {
+	var names []string
+	switch {
+	case b.Control != "":
+		names = []string{b.Control}
+	case b.Negative != "" || b.Positive != "":
+		names = []string{b.Negative, b.Positive}
+	case b.Up != "" || b.Down != "" || b.Left != "" || b.Right != "":
+		names = []string{b.Up, b.Down, b.Left, b.Right}
+	case b.X != "" || b.Y != "":
+		names = []string{b.X, b.Y}
+	default:
+		return errors.New("the binding has no controls")
+	}
+	b.controls = make([]control, len(names))
+	for i, n := range names {
+		if n == "" {
+			// Parts of a composite can be left out, such as a 1D axis that
+			// only goes in the positive direction
+			b.controls[i] = control{device: -1}
+			continue
+		}
+		c, err := parseControl(n)
+		if err != nil {
+			return err
+		}
+		b.controls[i] = c
+	}
+	b.mods = b.mods[:0]
+	for _, m := range b.Modifiers {
+		m = strings.ToLower(m)
+		if m != ModifierCtrl && m != ModifierShift && m != ModifierAlt {
+			return errors.New("unknown modifier " + m)
+		}
+		b.mods = append(b.mods, m)
+	}
+	return nil
+}
+
+func (b *Binding) modifiersHeld(d *Devices) bool {
+	if len(b.mods) == 0 {
+		return true
+	}
+	if d.Keyboard == nil {
+		return false
+	}
+	for _, m := range b.mods {
+		held := false
+		switch m {
+		case ModifierCtrl:
+			held = d.Keyboard.HasCtrl()
+		case ModifierShift:
+			held = d.Keyboard.HasShift()
+		case ModifierAlt:
+			held = d.Keyboard.HasAlt()
+		}
+		if !held {
+			return false
+		}
+	}
+	return true
+}
+
+// read returns the value of the binding, 1D bindings only use the X
+func (b *Binding) read(d *Devices, gamepad int, mouseDelta matrix.Vec2) matrix.Vec2 {
+	if len(b.controls) == 0 || !b.modifiersHeld(d) {
+		return matrix.Vec2Zero()
+	}
+	v := func(i int) matrix.Float { return b.controls[i].value(d, gamepad, mouseDelta) }
+	var out matrix.Vec2
+	switch {
+	case b.Control != "":
+		out = matrix.Vec2{applyDeadzone(v(0), matrix.Float(b.Deadzone)), 0}
+	case len(b.controls) == 2 && (b.Negative != "" || b.Positive != ""):
+		out = matrix.Vec2{v(1) - v(0), 0}
+	case len(b.controls) == 4:
+		out = matrix.Vec2{v(3) - v(2), v(0) - v(1)}
+		// Diagonals of a composite shouldn't move faster than straight lines
+		if out.Length() > 1 {
+			out = out.Normal()
+		}
+	default:
+		out = applyRadialDeadzone(matrix.Vec2{v(0), v(1)}, matrix.Float(b.Deadzone))
+	}
+	if b.Scale != 0 {
+		out = out.Scale(matrix.Float(b.Scale))
+	}
+	return out
+}
+
+func applyDeadzone(v, deadzone matrix.Float) matrix.Float {
+	if deadzone <= 0 {
+		return v
+	}
+	a := matrix.Abs(v)
+	if a <= deadzone {
+		return 0
+	}
+	return min((a-deadzone)/(1-deadzone), 1) * sign(v)
+}
+
+func applyRadialDeadzone(v matrix.Vec2, deadzone matrix.Float) matrix.Vec2 {
+	if deadzone <= 0 {
+		return v
+	}
+	l := v.Length()
+	if l <= deadzone {
+		return matrix.Vec2Zero()
+	}
+	return v.Scale(min((l-deadzone)/(1-deadzone), 1) / l)
+}
+
+func sign(v matrix.Float) matrix.Float {
+	if v < 0 {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,239 @@
+/******************************************************************************/
+/* control.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package input_map
+
+import (
+	"errors"
+	"kaiju/klib"
+	"kaiju/matrix"
+	"kaiju/platform/hid"
+	"strconv"
+	"strings"
+)
+
+type deviceType int
+
+const (
+	deviceKeyboard = deviceType(iota)
+	deviceMouse
+	deviceGamepad
+	deviceTouch
+)
+
+type controlKind int
+
+const (
+	controlButton = controlKind(iota)
+	controlAxis
+	controlMouseScrollX
+	controlMouseScrollY
+	controlMouseDeltaX
+	controlMouseDeltaY
+)
+
+const (
+	DeviceKeyboard = "keyboard"
+	DeviceMouse    = "mouse"
+	DeviceGamepad  = "gamepad"
+	DeviceTouch    = "touch"
+)
+
+var (
+	keyboardControls = map[string]hid.KeyboardKey{
+		"leftalt": hid.KeyboardKeyLeftAlt, "rightalt": hid.KeyboardKeyRightAlt,
+		"leftctrl": hid.KeyboardKeyLeftCtrl, "rightctrl": hid.KeyboardKeyRightCtrl,
+		"leftshift": hid.KeyboardKeyLeftShift, "rightshift": hid.KeyboardKeyRightShift,
+		"left": hid.KeyboardKeyLeft, "up": hid.KeyboardKeyUp,
+		"right": hid.KeyboardKeyRight, "down": hid.KeyboardKeyDown,
+		"escape": hid.KeyboardKeyEscape, "tab": hid.KeyboardKeyTab,
+		"space": hid.KeyboardKeySpace, "backspace": hid.KeyboardKeyBackspace,
+		"backquote": hid.KeyboardKeyBackQuote, "delete": hid.KeyboardKeyDelete,
+		"return": hid.KeyboardKeyReturn, "enter": hid.KeyboardKeyEnter,
+		"comma": hid.KeyboardKeyComma, "period": hid.KeyboardKeyPeriod,
+		"backslash": hid.KeyboardKeyBackSlash, "slash": hid.KeyboardKeyForwardSlash,
+		"openbracket": hid.KeyboardKeyOpenBracket, "closebracket": hid.KeyboardKeyCloseBracket,
+		"semicolon": hid.KeyboardKeySemicolon, "quote": hid.KeyboardKeyQuote,
+		"equal": hid.KeyboardKeyEqual, "minus": hid.KeyboardKeyMinus,
+		"capslock": hid.KeyboardKeyCapsLock, "scrolllock": hid.KeyboardKeyScrollLock,
+		"numlock": hid.KeyboardKeyNumLock, "printscreen": hid.KeyboardKeyPrintScreen,
+		"pause": hid.KeyboardKeyPause, "insert": hid.KeyboardKeyInsert,
+		"home": hid.KeyboardKeyHome, "end": hid.KeyboardKeyEnd,
+		"pageup": hid.KeyboardKeyPageUp, "pagedown": hid.KeyboardKeyPageDown,
+	}
+	mouseControls = map[string]int{
+		"left": hid.MouseButtonLeft, "middle": hid.MouseButtonMiddle,
+		"right": hid.MouseButtonRight, "x1": hid.MouseButtonX1, "x2": hid.MouseButtonX2,
+	}
+	mouseAxisControls = map[string]controlKind{
+		"scrollx": controlMouseScrollX, "scrolly": controlMouseScrollY,
+		"deltax": controlMouseDeltaX, "deltay": controlMouseDeltaY,
+	}
+	gamepadControls = map[string]int{
+		"up": hid.ControllerButtonUp, "down": hid.ControllerButtonDown,
+		"left": hid.ControllerButtonLeft, "right": hid.ControllerButtonRight,
+		"start": hid.ControllerButtonStart, "select": hid.ControllerButtonSelect,
+		"leftstick": hid.ControllerButtonLeftStick, "rightstick": hid.ControllerButtonRightStick,
+		"leftbumper": hid.ControllerButtonLeftBumper, "rightbumper": hid.ControllerButtonRightBumper,
+		"a": hid.ControllerButtonA, "b": hid.ControllerButtonB,
+		"x": hid.ControllerButtonX, "y": hid.ControllerButtonY,
+	}
+	gamepadAxisControls = map[string]int{
+		"leftx": hid.ControllerAxisLeftHorizontal, "lefty": hid.ControllerAxisLeftVertical,
+		"rightx": hid.ControllerAxisRightHorizontal, "righty": hid.ControllerAxisRightVertical,
+		"lefttrigger": hid.ControllerAxisLeftTrigger, "righttrigger": hid.ControllerAxisRightTrigger,
+	}
+)
+
+func init() {
+	for i := range 26 {
+		keyboardControls[string(rune('a'+i))] = hid.KeyboardKeyA + i
+	}
+	for i := range 10 {
+		keyboardControls[string(rune('0'+i))] = hid.KeyboardKey0 + i
+		keyboardControls["num"+string(rune('0'+i))] = hid.KeyboardNumKey0 + i
+	}
+	for i := range 12 {
+		keyboardControls["f"+strconv.Itoa(i+1)] = hid.KeyboardKeyF1 + i
+	}
+}
+
+// control is a parsed control name such as "keyboard/space"
+type control struct {
+	device deviceType
+	kind   controlKind
+	index  int
+}
+
+// parseControl reads a control name in the form "device/control", such as
+// "keyboard/w", "mouse/left", "gamepad/leftx" or "touch/press". Names are
+// not case sensitive.
+func parseControl(name string) (control, error) {
+	device, ctrl, ok := strings.Cut(strings.ToLower(strings.TrimSpace(name)), "/")
+	if !ok {
+		return control{}, errors.New("the control name " + name + " is missing its device")
+	}
+	switch device {
+	case DeviceKeyboard:
+		if k, ok := keyboardControls[ctrl]; ok {
+			return control{device: deviceKeyboard, index: k}, nil
+		}
+	case DeviceMouse:
+		if b, ok := mouseControls[ctrl]; ok {
+			return control{device: deviceMouse, index: b}, nil
+		}
+		if k, ok := mouseAxisControls[ctrl]; ok {
+			return control{device: deviceMouse, kind: k}, nil
+		}
+	case DeviceGamepad:
+		if b, ok := gamepadControls[ctrl]; ok {
+			return control{device: deviceGamepad, index: b}, nil
+		}
+		if a, ok := gamepadAxisControls[ctrl]; ok {
+			return control{device: deviceGamepad, kind: controlAxis, index: a}, nil
+		}
+	case DeviceTouch:
+		if ctrl == "press" {
+			return control{device: deviceTouch}, nil
+		}
+	}
+	return control{}, errors.New("unknown control " + name)
+}
+
+// value reads the control from the devices, buttons are 0 or 1 and axes are
+// from -1 to 1 (0 to 1 for triggers)
+func (c control) value(d *Devices, gamepad int, mouseDelta matrix.Vec2) matrix.Float {
+	switch c.device {
+	case deviceKeyboard:
+		if d.Keyboard != nil && d.Keyboard.KeyHeld(c.index) {
+			return 1
+		}
+	case deviceMouse:
+		if d.Mouse == nil {
+			return 0
+		}
+		switch c.kind {
+		case controlButton:
+			if d.Mouse.Pressed(c.index) || d.Mouse.Held(c.index) {
+				return 1
+			}
+		case controlMouseScrollX:
+			return matrix.Float(d.Mouse.ScrollX)
+		case controlMouseScrollY:
+			return matrix.Float(d.Mouse.ScrollY)
+		case controlMouseDeltaX:
+			return mouseDelta.X()
+		case controlMouseDeltaY:
+			return mouseDelta.Y()
+		}
+	case deviceGamepad:
+		if d.Controller == nil || gamepad < 0 {
+			return 0
+		}
+		if c.kind == controlAxis {
+			return matrix.Float(d.Controller.Axis(gamepad, c.index))
+		}
+		if d.Controller.IsButtonDown(gamepad, c.index) || d.Controller.IsButtonHeld(gamepad, c.index) {
+			return 1
+		}
+	case deviceTouch:
+		if d.Touch != nil && (d.Touch.Pressed() || d.Touch.Held()) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// ControlNames returns every control name that can be used in a binding for
+// the given device, this is useful for listing the options when rebinding
+func ControlNames(device string) []string {
+	var keys []string
+	switch device {
+	case DeviceKeyboard:
+		keys = klib.MapKeysSorted(keyboardControls)
+	case DeviceMouse:
+		keys = append(klib.MapKeysSorted(mouseControls), klib.MapKeysSorted(mouseAxisControls)...)
+	case DeviceGamepad:
+		keys = append(klib.MapKeysSorted(gamepadControls), klib.MapKeysSorted(gamepadAxisControls)...)
+	case DeviceTouch:
+		keys = []string{"press"}
+	}
+	for i := range keys {
+		keys[i] = device + "/" + keys[i]
+	}
+	return keys
+}
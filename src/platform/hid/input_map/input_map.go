@@ -0,0 +1,303 @@
+/******************************************************************************/
+/* input_map.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+// Package input_map maps the raw keyboard, mouse, gamepad and touch input of
+// the hid package to named actions that can be rebound. Actions are grouped
+// into contexts (such as gameplay and menu) which are pushed on and popped
+// off a stack as the game changes state. Bindings are saved as a JSON
+// profile so the player's controls can be kept between sessions.
+package input_map
+
+import (
+	"encoding/json"
+	"errors"
+	"kaiju/klib"
+	"kaiju/matrix"
+	"kaiju/platform/hid"
+	"slices"
+)
+
+// Devices are the hid devices the input map reads from, typically the ones
+// on the window. Any of them can be nil if they aren't used.
+type Devices struct {
+	Keyboard   *hid.Keyboard
+	Mouse      *hid.Mouse
+	Controller *hid.Controller
+	Touch      *hid.Touch
+}
+
+// Context is a named group of actions that are active together. A blocking
+// context stops every context under it on the stack from receiving input,
+// such as a pause menu stopping the player from moving.
+type Context struct {
+	Name     string
+	Blocking bool
+	Actions  []*Action
+}
+
+// Action returns the action with the name, or nil if the context doesn't
+// have it
+func (c *Context) Action(name string) *Action {
+	for _, a := range c.Actions {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// AddAction creates a new action in the context with the bindings
+func (c *Context) AddAction(name string, actionType ActionType, bindings ...Binding) (*Action, error) {
+	if c.Action(name) != nil {
+		return nil, errors.New("the action " + name + " already exists in " + c.Name)
+	}
+	a := &Action{Name: name, Type: actionType}
+	for _, b := range bindings {
+		if err := a.AddBinding(b); err != nil {
+			return nil, err
+		}
+	}
+	c.Actions = append(c.Actions, a)
+	return a, nil
+}
+
+func (c *Context) clear() {
+	for _, a := range c.Actions {
+		a.clear()
+	}
+}
+
+func (c *Context) reset() {
+	for _, a := range c.Actions {
+		a.reset()
+	}
+}
+
+// InputMap holds the contexts of actions and the stack of contexts that are
+// active. #InputMap.Update should be called once a frame before gameplay
+// reads the actions and before the devices end their frame.
+type InputMap struct {
+	Contexts []*Context
+	// Gamepad is the index of the controller that gamepad bindings read
+	// from, -1 reads from the first one that is connected
+	Gamepad   int
+	devices   Devices
+	stack     []*Context
+	lastMouse matrix.Vec2
+	hasMouse  bool
+}
+
+// New creates an empty input map that reads from the devices
+func New(devices Devices) *InputMap {
+	return &InputMap{devices: devices, Gamepad: -1}
+}
+
+// Load creates an input map from a JSON profile, such as one written by
+// #InputMap.Save
+func Load(devices Devices, profile []byte) (*InputMap, error) {
+	m := New(devices)
+	if err := m.LoadProfile(profile); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadProfile replaces the contexts with the ones in the JSON profile, the
+// stack is cleared
+func (m *InputMap) LoadProfile(profile []byte) error {
+	var loaded InputMap
+	loaded.Gamepad = -1
+	if err := json.Unmarshal(profile, &loaded); err != nil {
+		return err
+	}
+	for _, c := range loaded.Contexts {
+		for _, a := range c.Actions {
+			if err := a.compile(); err != nil {
+				return errors.New("failed to load " + c.Name + "." + a.Name + ": " + err.Error())
+			}
+		}
+	}
+	m.Contexts = loaded.Contexts
+	m.Gamepad = loaded.Gamepad
+	m.stack = m.stack[:0]
+	return nil
+}
+
+// Save writes the contexts and their bindings as a JSON profile
+func (m *InputMap) Save() ([]byte, error) {
+	return json.MarshalIndent(m, "", "\t")
+}
+
+// Context returns the context with the name, or nil if it doesn't exist
+func (m *InputMap) Context(name string) *Context {
+	for _, c := range m.Contexts {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// AddContext creates a new context, it isn't active until it is pushed
+func (m *InputMap) AddContext(name string, blocking bool) (*Context, error) {
+	if m.Context(name) != nil {
+		return nil, errors.New("the input context " + name + " already exists")
+	}
+	c := &Context{Name: name, Blocking: blocking}
+	m.Contexts = append(m.Contexts, c)
+	return c, nil
+}
+
+// Push makes the context active on top of the stack, if it was already on
+// the stack it is moved to the top
+func (m *InputMap) Push(name string) error {
+	c := m.Context(name)
+	if c == nil {
+		return errors.New("the input context " + name + " does not exist")
+	}
+	m.stack = slices.DeleteFunc(m.stack, func(s *Context) bool { return s == c })
+	m.stack = append(m.stack, c)
+	return nil
+}
+
+// Pop removes the top context from the stack and returns it, nil is
+// returned if the stack is empty
+func (m *InputMap) Pop() *Context {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	c := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	c.reset()
+	return c
+}
+
+// Active returns the context at the top of the stack, or nil if the stack
+// is empty
+func (m *InputMap) Active() *Context {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	return m.stack[len(m.stack)-1]
+}
+
+// Action finds the action by name in the active contexts, starting from the
+// top of the stack. An empty action is returned if none of the active
+// contexts have it, so the result can always be read.
+func (m *InputMap) Action(name string) *Action {
+	for i := len(m.stack) - 1; i >= 0; i-- {
+		if a := m.stack[i].Action(name); a != nil {
+			return a
+		}
+	}
+	return &Action{Name: name}
+}
+
+// Update reads the devices into the actions of the contexts on the stack,
+// contexts under a blocking context are released
+func (m *InputMap) Update() {
+	gamepad := m.gamepad()
+	var delta matrix.Vec2
+	if m.devices.Mouse != nil {
+		p := m.devices.Mouse.Position()
+		if m.hasMouse {
+			delta = p.Subtract(m.lastMouse)
+		}
+		m.lastMouse, m.hasMouse = p, true
+	}
+	blocked := false
+	for i := len(m.stack) - 1; i >= 0; i-- {
+		c := m.stack[i]
+		if blocked {
+			c.clear()
+			continue
+		}
+		for _, a := range c.Actions {
+			a.update(&m.devices, gamepad, delta)
+		}
+		blocked = c.Blocking
+	}
+}
+
+func (m *InputMap) gamepad() int {
+	if m.Gamepad >= 0 || m.devices.Controller == nil {
+		return m.Gamepad
+	}
+	for i := range hid.ControllerMaxDevices {
+		if m.devices.Controller.Available(i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// PressedControl returns the name of a button that was pressed this frame,
+// or an axis that was pushed past #PressThreshold. This is used to listen
+// for the control the player wants to rebind an action to.
+func (m *InputMap) PressedControl() (string, bool) {
+	d := &m.devices
+	if d.Keyboard != nil {
+		for _, name := range klib.MapKeysSorted(keyboardControls) {
+			if d.Keyboard.KeyDown(keyboardControls[name]) {
+				return DeviceKeyboard + "/" + name, true
+			}
+		}
+	}
+	if d.Mouse != nil {
+		for _, name := range klib.MapKeysSorted(mouseControls) {
+			if d.Mouse.Pressed(mouseControls[name]) {
+				return DeviceMouse + "/" + name, true
+			}
+		}
+	}
+	if gamepad := m.gamepad(); d.Controller != nil && gamepad >= 0 {
+		for _, name := range klib.MapKeysSorted(gamepadControls) {
+			if d.Controller.IsButtonDown(gamepad, gamepadControls[name]) {
+				return DeviceGamepad + "/" + name, true
+			}
+		}
+		for _, name := range klib.MapKeysSorted(gamepadAxisControls) {
+			if matrix.Abs(matrix.Float(d.Controller.Axis(gamepad, gamepadAxisControls[name]))) >= PressThreshold {
+				return DeviceGamepad + "/" + name, true
+			}
+		}
+	}
+	if d.Touch != nil && d.Touch.Pressed() {
+		return DeviceTouch + "/press", true
+	}
+	return "", false
+}
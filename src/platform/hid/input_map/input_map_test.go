@@ -0,0 +1,266 @@
+/******************************************************************************/
+/* input_map_test.go                                                          */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package input_map
+
+import (
+	"kaiju/matrix"
+	"kaiju/platform/hid"
+	"testing"
+)
+
+type testDevices struct {
+	keyboard   hid.Keyboard
+	mouse      hid.Mouse
+	controller hid.Controller
+	touch      hid.Touch
+}
+
+func newTestMap(t *testing.T) (*InputMap, *testDevices) {
+	t.Helper()
+	d := &testDevices{
+		keyboard:   hid.NewKeyboard(),
+		mouse:      hid.NewMouse(),
+		controller: hid.NewController(),
+		touch:      hid.NewTouch(),
+	}
+	d.controller.Connected(0)
+	m := New(Devices{
+		Keyboard:   &d.keyboard,
+		Mouse:      &d.mouse,
+		Controller: &d.controller,
+		Touch:      &d.touch,
+	})
+	return m, d
+}
+
+func (d *testDevices) endFrame() {
+	d.keyboard.EndUpdate()
+	d.mouse.EndUpdate()
+	d.controller.EndUpdate()
+	d.touch.EndUpdate()
+}
+
+func TestButtonAction(t *testing.T) {
+	m, d := newTestMap(t)
+	c, _ := m.AddContext("gameplay", false)
+	jump, err := c.AddAction("jump", ActionButton,
+		Binding{Control: "keyboard/space"}, Binding{Control: "gamepad/a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Push("gameplay")
+	d.keyboard.SetKeyDown(hid.KeyboardKeySpace)
+	m.Update()
+	if !jump.Pressed() || !jump.Held() || jump.Value() != 1 {
+		t.Error("expected jump to be pressed")
+	}
+	d.endFrame()
+	m.Update()
+	if jump.Pressed() || !jump.Held() {
+		t.Error("expected jump to be held after the first frame")
+	}
+	d.keyboard.SetKeyUp(hid.KeyboardKeySpace)
+	d.controller.SetButtonDown(0, hid.ControllerButtonA)
+	m.Update()
+	if !jump.Held() || jump.Released() {
+		t.Error("expected the gamepad to keep jump held")
+	}
+	d.controller.SetButtonUp(0, hid.ControllerButtonA)
+	d.endFrame()
+	m.Update()
+	if !jump.Released() || jump.Held() {
+		t.Error("expected jump to be released")
+	}
+}
+
+func TestModifiers(t *testing.T) {
+	m, d := newTestMap(t)
+	c, _ := m.AddContext("editor", false)
+	save, err := c.AddAction("save", ActionButton,
+		Binding{Control: "keyboard/s", Modifiers: []string{"Ctrl"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Push("editor")
+	d.keyboard.SetKeyDown(hid.KeyboardKeyS)
+	m.Update()
+	if save.Held() {
+		t.Error("expected save to need ctrl")
+	}
+	d.keyboard.SetKeyDown(hid.KeyboardKeyLeftCtrl)
+	m.Update()
+	if !save.Pressed() {
+		t.Error("expected ctrl+s to press save")
+	}
+	if _, err := c.AddAction("bad", ActionButton, Binding{Control: "keyboard/s", Modifiers: []string{"meta"}}); err == nil {
+		t.Error("expected an unknown modifier to fail")
+	}
+}
+
+func TestAxisActions(t *testing.T) {
+	m, d := newTestMap(t)
+	c, _ := m.AddContext("gameplay", false)
+	move, _ := c.AddAction("move", ActionAxis2D,
+		Binding{Up: "keyboard/w", Down: "keyboard/s", Left: "keyboard/a", Right: "keyboard/d"},
+		Binding{X: "gamepad/leftx", Y: "gamepad/lefty", Deadzone: 0.2})
+	throttle, _ := c.AddAction("throttle", ActionAxis,
+		Binding{Negative: "keyboard/down", Positive: "keyboard/up"},
+		Binding{Control: "gamepad/righttrigger", Deadzone: 0.1})
+	m.Push("gameplay")
+	d.keyboard.SetKeyDown(hid.KeyboardKeyW)
+	d.keyboard.SetKeyDown(hid.KeyboardKeyD)
+	d.keyboard.SetKeyDown(hid.KeyboardKeyDown)
+	m.Update()
+	if !matrix.Vec2ApproxTo(move.Vec2(), matrix.Vec2{0.7071, 0.7071}, 0.0001) {
+		t.Errorf("expected WASD diagonals to be normalized, got %v", move.Vec2())
+	}
+	if throttle.Value() != -1 {
+		t.Errorf("expected the negative key to give -1, got %f", throttle.Value())
+	}
+	d.keyboard.Reset()
+	d.endFrame()
+	d.controller.SetAxis(0, hid.ControllerAxisLeftHorizontal, 0.1)
+	d.controller.SetAxis(0, hid.ControllerAxisLeftVertical, 0.1)
+	d.controller.SetAxis(0, hid.ControllerAxisRightTrigger, 0.55)
+	m.Update()
+	if !matrix.Vec2ApproxTo(move.Vec2(), matrix.Vec2Zero(), 0.0001) {
+		t.Errorf("expected the stick to be inside the deadzone, got %v", move.Vec2())
+	}
+	if matrix.Abs(throttle.Value()-0.5) > 0.0001 || !throttle.Held() {
+		t.Errorf("expected the trigger to be rescaled past the deadzone, got %f", throttle.Value())
+	}
+	d.controller.SetAxis(0, hid.ControllerAxisLeftHorizontal, 0.6)
+	d.controller.SetAxis(0, hid.ControllerAxisLeftVertical, 0)
+	m.Update()
+	if !matrix.Vec2ApproxTo(move.Vec2(), matrix.Vec2{0.5, 0}, 0.0001) {
+		t.Errorf("expected the stick to be rescaled past the deadzone, got %v", move.Vec2())
+	}
+}
+
+func TestContextStack(t *testing.T) {
+	m, d := newTestMap(t)
+	game, _ := m.AddContext("gameplay", false)
+	menu, _ := m.AddContext("menu", true)
+	jump, _ := game.AddAction("jump", ActionButton, Binding{Control: "keyboard/space"})
+	accept, _ := menu.AddAction("accept", ActionButton, Binding{Control: "keyboard/space"})
+	m.Push("gameplay")
+	d.keyboard.SetKeyDown(hid.KeyboardKeySpace)
+	m.Update()
+	if !jump.Held() || accept.Held() {
+		t.Error("expected only gameplay to be active")
+	}
+	if err := m.Push("menu"); err != nil {
+		t.Fatal(err)
+	}
+	m.Update()
+	if !jump.Released() || !accept.Pressed() {
+		t.Error("expected the menu to block gameplay")
+	}
+	if m.Action("accept") != accept || m.Action("jump") != jump || m.Action("missing").Held() {
+		t.Error("expected actions to be found through the stack")
+	}
+	if m.Pop() != menu || m.Active() != game {
+		t.Error("expected popping to return to gameplay")
+	}
+	m.Update()
+	if !jump.Held() || accept.Held() {
+		t.Error("expected gameplay to receive input again")
+	}
+	if m.Push("missing") == nil {
+		t.Error("expected pushing an unknown context to fail")
+	}
+}
+
+func TestProfile(t *testing.T) {
+	m, d := newTestMap(t)
+	c, _ := m.AddContext("gameplay", false)
+	c.AddAction("fire", ActionButton, Binding{Control: "mouse/left"})
+	c.AddAction("look", ActionAxis2D, Binding{X: "mouse/deltax", Y: "mouse/deltay", Scale: 0.5})
+	data, err := m.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(Devices{Mouse: &d.mouse}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fire := loaded.Context("gameplay").Action("fire")
+	if fire == nil || fire.Bindings[0].Control != "mouse/left" {
+		t.Fatalf("expected the fire binding to be loaded, got %+v", fire)
+	}
+	loaded.Push("gameplay")
+	d.mouse.SetPosition(10, 10, 100, 100)
+	loaded.Update()
+	d.mouse.SetDown(hid.MouseButtonLeft)
+	d.mouse.SetPosition(14, 10, 100, 100)
+	loaded.Update()
+	if !fire.Pressed() {
+		t.Error("expected the loaded binding to work")
+	}
+	if look := loaded.Action("look").Vec2(); !matrix.Vec2ApproxTo(look, matrix.Vec2{2, 0}, 0.0001) {
+		t.Errorf("expected the scaled mouse movement, got %v", look)
+	}
+	// Rebind fire to the right mouse button
+	if err := fire.SetBinding(0, Binding{Control: "mouse/right"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fire.SetBinding(0, Binding{Control: "mouse/nothing"}); err == nil {
+		t.Error("expected an unknown control to fail")
+	}
+	if _, err := Load(Devices{}, []byte(`{"Contexts":[{"Name":"a","Actions":[{"Name":"b","Bindings":[{"Control":"pad/a"}]}]}]}`)); err == nil {
+		t.Error("expected a profile with an unknown control to fail")
+	}
+}
+
+func TestPressedControl(t *testing.T) {
+	m, d := newTestMap(t)
+	if _, ok := m.PressedControl(); ok {
+		t.Error("expected nothing to be pressed")
+	}
+	d.controller.SetButtonDown(0, hid.ControllerButtonY)
+	if name, _ := m.PressedControl(); name != "gamepad/y" {
+		t.Errorf("expected gamepad/y, got %s", name)
+	}
+	d.keyboard.SetKeyDown(hid.KeyboardKeyF5)
+	if name, _ := m.PressedControl(); name != "keyboard/f5" {
+		t.Errorf("expected keyboard/f5, got %s", name)
+	}
+	if _, err := parseControl(ControlNames(DeviceKeyboard)[0]); err != nil {
+		t.Error(err)
+	}
+}
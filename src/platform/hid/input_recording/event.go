@@ -0,0 +1,197 @@
+/******************************************************************************/
+/* event.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package input_recording
+
+import (
+	"kaiju/platform/hid"
+)
+
+type EventKind uint8
+
+const (
+	EventKeyDown = EventKind(iota)
+	EventKeyUp
+	EventKeyDownUp
+	EventMousePosition
+	EventMouseDown
+	EventMouseUp
+	EventMouseScroll
+	EventControllerConnected
+	EventControllerDisconnected
+	EventControllerButtonDown
+	EventControllerButtonUp
+	EventControllerAxis
+	EventTouchDown
+	EventTouchUp
+	EventTouchMoved
+	EventTouchPressure
+	EventTouchCancel
+	EventStylusState
+	EventStylusPosition
+	EventStylusDistance
+	eventKindMax
+)
+
+// eventLayout describes which fields of an event are used by its kind, only
+// those fields are written to the file
+type eventLayout struct {
+	device bool
+	index  bool
+	values int
+}
+
+var eventLayouts = [eventKindMax]eventLayout{
+	EventKeyDown:                {index: true},
+	EventKeyUp:                  {index: true},
+	EventKeyDownUp:              {index: true},
+	EventMousePosition:          {values: 4},
+	EventMouseDown:              {index: true},
+	EventMouseUp:                {index: true},
+	EventMouseScroll:            {values: 2},
+	EventControllerConnected:    {device: true},
+	EventControllerDisconnected: {device: true},
+	EventControllerButtonDown:   {device: true, index: true},
+	EventControllerButtonUp:     {device: true, index: true},
+	EventControllerAxis:         {device: true, index: true, values: 1},
+	EventTouchDown:              {index: true, values: 3},
+	EventTouchUp:                {index: true, values: 3},
+	EventTouchMoved:             {index: true, values: 3},
+	EventTouchPressure:          {index: true, values: 1},
+	EventTouchCancel:            {},
+	EventStylusState:            {index: true},
+	EventStylusPosition:         {values: 4},
+	EventStylusDistance:         {values: 1},
+}
+
+// Event is a single change to the state of a device. Device is the
+// controller the event is for, Index is the key, button, axis or touch
+// pointer and Values are the arguments to the setter of the device.
+type Event struct {
+	Kind   EventKind
+	Device int
+	Index  int64
+	Values [4]float32
+}
+
+// apply feeds the event back into the devices through the same functions
+// the platform calls when the input happens
+func (e *Event) apply(d *Devices) {
+	v := e.Values
+	switch e.Kind {
+	case EventKeyDown:
+		if d.Keyboard != nil {
+			d.Keyboard.SetKeyDown(hid.KeyboardKey(e.Index))
+		}
+	case EventKeyUp:
+		if d.Keyboard != nil {
+			d.Keyboard.SetKeyUp(hid.KeyboardKey(e.Index))
+		}
+	case EventKeyDownUp:
+		if d.Keyboard != nil {
+			d.Keyboard.SetKeyDownUp(hid.KeyboardKey(e.Index))
+		}
+	case EventMousePosition:
+		if d.Mouse != nil {
+			d.Mouse.SetPosition(v[0], v[1], v[2], v[3])
+		}
+	case EventMouseDown:
+		if d.Mouse != nil {
+			d.Mouse.SetDown(int(e.Index))
+		}
+	case EventMouseUp:
+		if d.Mouse != nil {
+			d.Mouse.SetUp(int(e.Index))
+		}
+	case EventMouseScroll:
+		if d.Mouse != nil {
+			d.Mouse.SetScroll(v[0], v[1])
+		}
+	case EventControllerConnected:
+		if d.Controller != nil {
+			d.Controller.Connected(e.Device)
+		}
+	case EventControllerDisconnected:
+		if d.Controller != nil {
+			d.Controller.Disconnected(e.Device)
+		}
+	case EventControllerButtonDown:
+		if d.Controller != nil {
+			d.Controller.SetButtonDown(e.Device, int(e.Index))
+		}
+	case EventControllerButtonUp:
+		if d.Controller != nil {
+			d.Controller.SetButtonUp(e.Device, int(e.Index))
+		}
+	case EventControllerAxis:
+		if d.Controller != nil {
+			d.Controller.SetAxis(e.Device, int(e.Index), v[0])
+		}
+	case EventTouchDown:
+		if d.Touch != nil {
+			d.Touch.SetDown(e.Index, v[0], v[1], v[2])
+		}
+	case EventTouchUp:
+		if d.Touch != nil {
+			d.Touch.SetUp(e.Index, v[0], v[1], v[2])
+		}
+	case EventTouchMoved:
+		if d.Touch != nil {
+			d.Touch.SetMoved(e.Index, v[0], v[1], v[2])
+		}
+	case EventTouchPressure:
+		if d.Touch != nil {
+			d.Touch.SetPressure(e.Index, v[0])
+		}
+	case EventTouchCancel:
+		if d.Touch != nil {
+			d.Touch.Cancel()
+		}
+	case EventStylusState:
+		if d.Stylus != nil {
+			d.Stylus.SetActionState(hid.StylusActionState(e.Index))
+		}
+	case EventStylusPosition:
+		if d.Stylus != nil {
+			d.Stylus.Set(v[0], v[1], v[2], v[3])
+		}
+	case EventStylusDistance:
+		if d.Stylus != nil {
+			d.Stylus.SetDistance(v[0])
+		}
+	}
+}
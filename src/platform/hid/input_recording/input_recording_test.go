@@ -0,0 +1,219 @@
+/******************************************************************************/
+/* input_recording_test.go                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package input_recording
+
+import (
+	"bytes"
+	"fmt"
+	"kaiju/platform/hid"
+	"strings"
+	"testing"
+)
+
+type testDevices struct {
+	keyboard   hid.Keyboard
+	mouse      hid.Mouse
+	controller hid.Controller
+	touch      hid.Touch
+	stylus     hid.Stylus
+}
+
+func newTestDevices() *testDevices {
+	return &testDevices{
+		keyboard:   hid.NewKeyboard(),
+		mouse:      hid.NewMouse(),
+		controller: hid.NewController(),
+		touch:      hid.NewTouch(),
+		stylus:     hid.NewStylus(),
+	}
+}
+
+func (d *testDevices) devices() Devices {
+	return Devices{&d.keyboard, &d.mouse, &d.controller, &d.touch, &d.stylus}
+}
+
+func (d *testDevices) endFrame() {
+	d.keyboard.EndUpdate()
+	d.mouse.EndUpdate()
+	d.controller.EndUpdate()
+	d.touch.EndUpdate()
+	d.stylus.EndUpdate()
+}
+
+// snapshot describes everything gameplay could read from the devices
+func (d *testDevices) snapshot() string {
+	sb := strings.Builder{}
+	for k := range hid.KeyboardKeyMaximum {
+		if s := d.keyboard.KeyState(k); s != hid.KeyStateIdle {
+			fmt.Fprintf(&sb, "k%d=%d ", k, s)
+		}
+	}
+	m := &d.mouse
+	fmt.Fprintf(&sb, "m%v %v %v %v %v %t ", m.Position(), m.ScreenPosition(),
+		m.CenteredPosition(), m.Scroll(), m.Moved(), m.Scrolled())
+	for i := range hid.MouseButtonLast {
+		fmt.Fprintf(&sb, "%d", m.ButtonState(i))
+	}
+	for id := range hid.ControllerMaxDevices {
+		if !d.controller.Available(id) {
+			continue
+		}
+		fmt.Fprintf(&sb, " c%d", id)
+		for b := range hid.ControllerButtonMax {
+			fmt.Fprintf(&sb, "%t%t%t", d.controller.IsButtonDown(id, b),
+				d.controller.IsButtonHeld(id, b), d.controller.IsButtonUp(id, b))
+		}
+		for a := range hid.ControllerAxisMax {
+			fmt.Fprintf(&sb, " %f", d.controller.Axis(id, a))
+		}
+	}
+	for _, p := range d.touch.Pointers {
+		fmt.Fprintf(&sb, " t%+v", *p)
+	}
+	fmt.Fprintf(&sb, " s%f %f %f %f %f %d", d.stylus.X, d.stylus.Y, d.stylus.IY,
+		d.stylus.Pressure, d.stylus.Distance, d.stylus.ActionState())
+	return sb.String()
+}
+
+// testSession drives the devices the way a platform would over a few frames
+var testSession = []func(d *testDevices){
+	func(d *testDevices) {
+		d.keyboard.SetKeyDown(hid.KeyboardKeyW)
+		d.mouse.SetPosition(10, 20, 800, 600)
+		d.controller.Connected(1)
+	},
+	func(d *testDevices) {
+		d.mouse.SetDown(hid.MouseButtonLeft)
+		d.mouse.SetScroll(0, -1)
+		d.controller.SetButtonDown(1, hid.ControllerButtonA)
+		d.controller.SetAxis(1, hid.ControllerAxisLeftHorizontal, 0.75)
+		d.touch.SetDown(7, 100, 200, 600)
+		d.stylus.SetActionState(hid.StylusActionHoverEnter)
+		d.stylus.Set(5, 6, 600, 0)
+		d.stylus.SetDistance(3)
+	},
+	func(d *testDevices) {
+		d.keyboard.SetKeyUp(hid.KeyboardKeyW)
+		d.keyboard.SetKeyDownUp(hid.KeyboardKeySpace)
+		d.mouse.SetPosition(15, 25, 800, 600)
+		d.touch.SetMoved(7, 110, 205, 600)
+		d.touch.SetPressure(7, 0.5)
+		d.touch.SetDown(8, 300, 300, 600)
+		d.stylus.SetActionState(hid.StylusActionDown)
+		d.stylus.Set(5, 6, 600, 0.8)
+	},
+	func(d *testDevices) {
+		d.mouse.SetUp(hid.MouseButtonLeft)
+		d.controller.SetButtonUp(1, hid.ControllerButtonA)
+		d.controller.SetAxis(1, hid.ControllerAxisLeftHorizontal, 0)
+		d.touch.SetUp(7, 110, 205, 600)
+	},
+	func(d *testDevices) {
+		d.touch.Cancel()
+		d.controller.Disconnected(1)
+		d.stylus.SetActionState(hid.StylusActionUp)
+	},
+	func(d *testDevices) {},
+}
+
+func TestRecordAndPlayback(t *testing.T) {
+	live := newTestDevices()
+	rec := NewRecorder(live.devices(), 1234)
+	var expected []string
+	for i, step := range testSession {
+		step(live)
+		rec.Capture(float64(i+1) / 60)
+		expected = append(expected, live.snapshot())
+		live.endFrame()
+	}
+	var file bytes.Buffer
+	if err := rec.Recording().Write(&file); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadRecording(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Seed != 1234 || len(loaded.Frames) != len(testSession) {
+		t.Fatalf("expected seed 1234 and %d frames, got %d and %d",
+			len(testSession), loaded.Seed, len(loaded.Frames))
+	}
+	replay := newTestDevices()
+	p := NewPlayer(loaded, replay.devices())
+	for i := range expected {
+		dt, ok := p.Next()
+		if !ok || dt != float64(i+1)/60 {
+			t.Fatalf("frame %d expected a delta time of %f, got %f", i, float64(i+1)/60, dt)
+		}
+		if got := replay.snapshot(); got != expected[i] {
+			t.Fatalf("frame %d did not match\nexpected: %s\ngot:      %s", i, expected[i], got)
+		}
+		replay.endFrame()
+	}
+	if _, ok := p.Next(); ok || !p.IsFinished() {
+		t.Error("expected the playback to finish")
+	}
+}
+
+func TestRecordingIsCompact(t *testing.T) {
+	d := newTestDevices()
+	rec := NewRecorder(d.devices(), 0)
+	for range 600 {
+		rec.Capture(1.0 / 60)
+		d.endFrame()
+	}
+	var file bytes.Buffer
+	rec.Recording().Write(&file)
+	// Idle frames only hold their delta time and an empty event count
+	if file.Len() > 600*9+16 {
+		t.Errorf("expected idle frames to be small, got %d bytes", file.Len())
+	}
+}
+
+func TestReadRecordingErrors(t *testing.T) {
+	if _, err := ReadRecording(strings.NewReader("nope!")); err == nil {
+		t.Error("expected an error for a file that isn't a recording")
+	}
+	var file bytes.Buffer
+	r := Recording{Frames: []Frame{{Events: []Event{{Kind: EventKeyDown, Index: 3}}}}}
+	r.Write(&file)
+	data := file.Bytes()
+	if _, err := ReadRecording(bytes.NewReader(data[:len(data)-1])); err == nil {
+		t.Error("expected an error for a truncated recording")
+	}
+}
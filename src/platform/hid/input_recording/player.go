@@ -0,0 +1,79 @@
+/******************************************************************************/
+/* player.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package input_recording
+
+// Player feeds a recording back into the devices one frame at a time.
+// #Player.Next should be called at the same point in the frame that
+// #Recorder.Capture was, after the devices have been polled. Real input
+// should not be given to the devices while a recording is playing, as it
+// will be mixed in with the recorded input.
+type Player struct {
+	devices   Devices
+	recording *Recording
+	frame     int
+}
+
+// NewPlayer prepares the recording to be played back into the devices
+func NewPlayer(recording *Recording, devices Devices) *Player {
+	return &Player{devices: devices, recording: recording}
+}
+
+// Recording returns the recording being played
+func (p *Player) Recording() *Recording { return p.recording }
+
+// Frame returns the index of the next frame that will be played
+func (p *Player) Frame() int { return p.frame }
+
+// IsFinished returns true once every frame has been played
+func (p *Player) IsFinished() bool { return p.frame >= len(p.recording.Frames) }
+
+// Next applies the input of the next frame to the devices and returns the
+// delta time the frame was recorded with, which should be used to update
+// the frame in place of the real delta time. False is returned once the
+// recording has finished.
+func (p *Player) Next() (float64, bool) {
+	if p.IsFinished() {
+		return 0, false
+	}
+	f := &p.recording.Frames[p.frame]
+	p.frame++
+	for i := range f.Events {
+		f.Events[i].apply(&p.devices)
+	}
+	return f.DeltaTime, true
+}
@@ -0,0 +1,250 @@
+/******************************************************************************/
+/* recorder.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+// Package input_recording captures the state changes of the hid devices each
+// frame so that they can be saved and fed back through the devices later.
+// Along with the recorded delta times and a seeded random number generator,
+// playing back a recording reproduces the same session, which is useful for
+// reproducing bug reports and for scripted gameplay tests.
+package input_recording
+
+import (
+	"kaiju/platform/hid"
+)
+
+// Devices are the hid devices that are recorded or played back into,
+// typically the ones on the window. Any of them can be nil if they aren't
+// used.
+type Devices struct {
+	Keyboard   *hid.Keyboard
+	Mouse      *hid.Mouse
+	Controller *hid.Controller
+	Touch      *hid.Touch
+	Stylus     *hid.Stylus
+}
+
+type touchSnapshot struct {
+	x, y, iy, pressure float32
+}
+
+type stylusSnapshot struct {
+	x, y, iy, pressure, distance float32
+	state                        hid.StylusActionState
+}
+
+// Recorder captures the changes to the devices each frame. #Recorder.Capture
+// must be called once a frame after the devices have been polled and before
+// their frame is ended, as most changes only last for the frame they
+// happened in.
+type Recorder struct {
+	devices   Devices
+	recording Recording
+	connected [hid.ControllerMaxDevices]bool
+	axes      [hid.ControllerMaxDevices][hid.ControllerAxisMax]float32
+	touches   map[int64]touchSnapshot
+	stylus    stylusSnapshot
+}
+
+// NewRecorder starts a recording of the devices, the seed is stored with
+// the recording for the game to use when it is played back
+func NewRecorder(devices Devices, seed int64) *Recorder {
+	return &Recorder{
+		devices:   devices,
+		recording: Recording{Seed: seed},
+		touches:   make(map[int64]touchSnapshot),
+		stylus:    stylusSnapshot{state: hid.StylusActionNone},
+	}
+}
+
+// Recording returns everything that has been captured so far
+func (r *Recorder) Recording() *Recording { return &r.recording }
+
+// Capture adds a frame to the recording with every change to the devices
+// since the last frame
+func (r *Recorder) Capture(deltaTime float64) {
+	f := Frame{DeltaTime: deltaTime}
+	r.captureKeyboard(&f)
+	r.captureMouse(&f)
+	r.captureController(&f)
+	r.captureTouch(&f)
+	r.captureStylus(&f)
+	r.recording.Frames = append(r.recording.Frames, f)
+}
+
+func (f *Frame) add(e Event) { f.Events = append(f.Events, e) }
+
+func (r *Recorder) captureKeyboard(f *Frame) {
+	k := r.devices.Keyboard
+	if k == nil {
+		return
+	}
+	for key := range hid.KeyboardKeyMaximum {
+		switch k.KeyState(key) {
+		case hid.KeyStateDown:
+			f.add(Event{Kind: EventKeyDown, Index: int64(key)})
+		case hid.KeyStateUp:
+			f.add(Event{Kind: EventKeyUp, Index: int64(key)})
+		case hid.KeyStatePressedAndReleased:
+			f.add(Event{Kind: EventKeyDownUp, Index: int64(key)})
+		}
+	}
+}
+
+func (r *Recorder) captureMouse(f *Frame) {
+	m := r.devices.Mouse
+	if m == nil {
+		return
+	}
+	if m.Moved() {
+		// The window size isn't kept on the mouse, but it can be worked out
+		// from the positions relative to the bottom and the center
+		f.add(Event{Kind: EventMousePosition,
+			Values: [4]float32{m.SX, m.SY, 2 * (m.SX - m.CX), m.Y + m.SY}})
+	}
+	for i := range hid.MouseButtonLast {
+		switch m.ButtonState(i) {
+		case hid.MousePress:
+			f.add(Event{Kind: EventMouseDown, Index: int64(i)})
+		case hid.MouseRelease:
+			f.add(Event{Kind: EventMouseUp, Index: int64(i)})
+		}
+	}
+	if m.Scrolled() {
+		f.add(Event{Kind: EventMouseScroll, Values: [4]float32{m.ScrollX, m.ScrollY}})
+	}
+}
+
+func (r *Recorder) captureController(f *Frame) {
+	c := r.devices.Controller
+	if c == nil {
+		return
+	}
+	for id := range hid.ControllerMaxDevices {
+		available := c.Available(id)
+		if available != r.connected[id] {
+			r.connected[id] = available
+			if available {
+				f.add(Event{Kind: EventControllerConnected, Device: id})
+			} else {
+				f.add(Event{Kind: EventControllerDisconnected, Device: id})
+			}
+		}
+		for b := range hid.ControllerButtonMax {
+			if c.IsButtonDown(id, b) {
+				f.add(Event{Kind: EventControllerButtonDown, Device: id, Index: int64(b)})
+			} else if c.IsButtonUp(id, b) {
+				f.add(Event{Kind: EventControllerButtonUp, Device: id, Index: int64(b)})
+			}
+		}
+		for a := range hid.ControllerAxisMax {
+			if v := c.Axis(id, a); v != r.axes[id][a] {
+				r.axes[id][a] = v
+				f.add(Event{Kind: EventControllerAxis, Device: id, Index: int64(a), Values: [4]float32{v}})
+			}
+		}
+	}
+}
+
+func (r *Recorder) captureTouch(f *Frame) {
+	t := r.devices.Touch
+	if t == nil {
+		return
+	}
+	if t.Pool[0].State == hid.TouchActionCancel {
+		f.add(Event{Kind: EventTouchCancel})
+		clear(r.touches)
+		return
+	}
+	for _, p := range t.Pointers {
+		pos := [4]float32{p.X, p.Y, p.IY + p.Y}
+		last, known := r.touches[p.Id]
+		switch {
+		case p.State == hid.TouchActionDown:
+			f.add(Event{Kind: EventTouchDown, Index: p.Id, Values: pos})
+		case p.State == hid.TouchActionUp:
+			f.add(Event{Kind: EventTouchUp, Index: p.Id, Values: pos})
+		case known && (last.x != p.X || last.y != p.Y || last.iy != p.IY):
+			f.add(Event{Kind: EventTouchMoved, Index: p.Id, Values: pos})
+		}
+		if p.Pressure != last.pressure {
+			f.add(Event{Kind: EventTouchPressure, Index: p.Id, Values: [4]float32{p.Pressure}})
+		}
+		if p.State == hid.TouchActionUp {
+			delete(r.touches, p.Id)
+		} else {
+			r.touches[p.Id] = touchSnapshot{p.X, p.Y, p.IY, p.Pressure}
+		}
+	}
+}
+
+func (r *Recorder) captureStylus(f *Frame) {
+	s := r.devices.Stylus
+	if s == nil {
+		return
+	}
+	last := r.stylus
+	if s.X != last.x || s.Y != last.y || s.IY != last.iy || s.Pressure != last.pressure {
+		f.add(Event{Kind: EventStylusPosition,
+			Values: [4]float32{s.X, s.Y, s.IY + s.Y, s.Pressure}})
+	}
+	if s.Distance != last.distance {
+		f.add(Event{Kind: EventStylusDistance, Values: [4]float32{s.Distance}})
+	}
+	// The state is only recorded when it isn't what the end of the last
+	// frame would have left it as
+	if state := s.ActionState(); state != stylusAfterFrame(last.state) || stylusIsTransient(state) {
+		f.add(Event{Kind: EventStylusState, Index: int64(state)})
+	}
+	r.stylus = stylusSnapshot{s.X, s.Y, s.IY, s.Pressure, s.Distance, s.ActionState()}
+}
+
+// stylusAfterFrame mirrors #hid.Stylus.EndUpdate
+func stylusAfterFrame(state hid.StylusActionState) hid.StylusActionState {
+	switch state {
+	case hid.StylusActionDown, hid.StylusActionMove:
+		return hid.StylusActionHeld
+	case hid.StylusActionUp, hid.StylusActionHoverExit:
+		return hid.StylusActionNone
+	case hid.StylusActionHoverEnter, hid.StylusActionHoverMove:
+		return hid.StylusActionHover
+	}
+	return state
+}
+
+func stylusIsTransient(state hid.StylusActionState) bool {
+	return stylusAfterFrame(state) != state
+}
@@ -0,0 +1,176 @@
+/******************************************************************************/
+/* recording.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package input_recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+const (
+	recordingMagic   = "KINP"
+	recordingVersion = 1
+)
+
+// Frame is the input of a single frame of the host, along with the delta
+// time that the frame was updated with
+type Frame struct {
+	DeltaTime float64
+	Events    []Event
+}
+
+// Recording is every frame of input captured by a #Recorder. The seed is
+// not used by the recording itself, it is kept so that the game can seed
+// its random numbers the same way when the recording is played back.
+type Recording struct {
+	Seed   int64
+	Frames []Frame
+}
+
+// Write encodes the recording in a compact binary form. Only the fields an
+// event uses are written and numbers are written as variable length where
+// they are typically small.
+func (r *Recording) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	var scratch [binary.MaxVarintLen64]byte
+	putUvarint := func(v uint64) {
+		bw.Write(scratch[:binary.PutUvarint(scratch[:], v)])
+	}
+	putVarint := func(v int64) {
+		bw.Write(scratch[:binary.PutVarint(scratch[:], v)])
+	}
+	bw.WriteString(recordingMagic)
+	bw.WriteByte(recordingVersion)
+	putVarint(r.Seed)
+	putUvarint(uint64(len(r.Frames)))
+	for i := range r.Frames {
+		f := &r.Frames[i]
+		binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(f.DeltaTime))
+		bw.Write(scratch[:8])
+		putUvarint(uint64(len(f.Events)))
+		for j := range f.Events {
+			e := &f.Events[j]
+			layout := eventLayouts[e.Kind]
+			bw.WriteByte(byte(e.Kind))
+			if layout.device {
+				putUvarint(uint64(e.Device))
+			}
+			if layout.index {
+				putVarint(e.Index)
+			}
+			for k := range layout.values {
+				binary.LittleEndian.PutUint32(scratch[:], math.Float32bits(e.Values[k]))
+				bw.Write(scratch[:4])
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadRecording decodes a recording that was written by #Recording.Write
+func ReadRecording(r io.Reader) (*Recording, error) {
+	br := bufio.NewReader(r)
+	var header [len(recordingMagic) + 1]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, err
+	}
+	if string(header[:len(recordingMagic)]) != recordingMagic {
+		return nil, errors.New("the file is not an input recording")
+	}
+	if header[len(recordingMagic)] != recordingVersion {
+		return nil, errors.New("the input recording version is not supported")
+	}
+	seed, err := binary.ReadVarint(br)
+	if err != nil {
+		return nil, err
+	}
+	frameCount, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	rec := &Recording{Seed: seed, Frames: make([]Frame, 0, min(frameCount, 1<<16))}
+	var scratch [8]byte
+	for range frameCount {
+		var f Frame
+		if _, err := io.ReadFull(br, scratch[:8]); err != nil {
+			return nil, err
+		}
+		f.DeltaTime = math.Float64frombits(binary.LittleEndian.Uint64(scratch[:]))
+		eventCount, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		f.Events = make([]Event, 0, min(eventCount, 1<<10))
+		for range eventCount {
+			var e Event
+			kind, err := br.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			if EventKind(kind) >= eventKindMax {
+				return nil, errors.New("the input recording has an unknown event")
+			}
+			e.Kind = EventKind(kind)
+			layout := eventLayouts[e.Kind]
+			if layout.device {
+				device, err := binary.ReadUvarint(br)
+				if err != nil {
+					return nil, err
+				}
+				e.Device = int(device)
+			}
+			if layout.index {
+				if e.Index, err = binary.ReadVarint(br); err != nil {
+					return nil, err
+				}
+			}
+			for k := range layout.values {
+				if _, err := io.ReadFull(br, scratch[:4]); err != nil {
+					return nil, err
+				}
+				e.Values[k] = math.Float32frombits(binary.LittleEndian.Uint32(scratch[:]))
+			}
+			f.Events = append(f.Events, e)
+		}
+		rec.Frames = append(rec.Frames, f)
+	}
+	return rec, nil
+}
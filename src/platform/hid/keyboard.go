@@ -203,6 +203,11 @@ func (k Keyboard) KeyHeld(key KeyboardKey) bool {
 		k.keyStates[key] == KeyStatePressedAndReleased
 }
 
+// KeyState returns the raw state of the key for this frame
+func (k Keyboard) KeyState(key KeyboardKey) KeyState {
+	return k.keyStates[key]
+}
+
 func (k Keyboard) HasCtrl() bool {
 	return k.KeyHeld(KeyboardKeyLeftCtrl) || k.KeyHeld(KeyboardKeyRightCtrl)
 }
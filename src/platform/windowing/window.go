@@ -41,6 +41,7 @@ import (
 	"errors"
 	"kaiju/engine/assets"
 	"kaiju/platform/hid"
+	"kaiju/platform/hid/gamepad_db"
 	"kaiju/klib"
 	"kaiju/matrix"
 	"kaiju/platform/profiler/tracing"
@@ -60,6 +61,7 @@ type Window struct {
 	Touch                    hid.Touch
 	Stylus                   hid.Stylus
 	Controller               hid.Controller
+	Gamepads                 *gamepad_db.Gamepads
 	Cursor                   hid.Cursor
 	Renderer                 rendering.Renderer
 	OnResize                 events.Event
@@ -76,6 +78,7 @@ type Window struct {
 	isCrashed                bool
 	fatalFromNativeAPI       bool
 	resizedFromNativeAPI     bool
+	inputIgnored             bool
 }
 
 type FileSearch struct {
@@ -90,6 +93,7 @@ func New(windowName string, width, height, x, y int, assets *assets.Database) (*
 		Touch:      hid.NewTouch(),
 		Stylus:     hid.NewStylus(),
 		Controller: hid.NewController(),
+		Gamepads:   gamepad_db.NewGamepads(gamepad_db.NewDatabase()),
 		width:      width,
 		height:     height,
 		x:          x,
@@ -251,19 +255,14 @@ func (w *Window) processKeyboardButtonEvent(evt *KeyboardButtonWindowEvent) {
 }
 
 func (w *Window) processControllerStateEvent(evt *ControllerStateWindowEvent) {
+	id := int(evt.controllerId)
 	if evt.connectionType == windowEventControllerConnectionTypeDisconnected {
-		w.Controller.Disconnected(int(evt.controllerId))
-	} else {
-		w.Controller.Connected(int(evt.controllerId))
-	}
-	for i := 0; i < int(unsafe.Sizeof(evt.buttons)*8); i++ {
-		buttonId := evt.buttons & (1 << i)
-		if buttonId != 0 {
-			w.Controller.SetButtonDown(int(evt.controllerId), i)
-		} else {
-			w.Controller.SetButtonUp(int(evt.controllerId), i)
-		}
+		w.Gamepads.Disconnected(&w.Controller, id)
+		return
 	}
+	raw := gamepad_db.XInputRawState(evt.buttons, evt.leftTrigger,
+		evt.rightTrigger, evt.thumbLX, evt.thumbLY, evt.thumbRX, evt.thumbRY)
+	w.Gamepads.Update(&w.Controller, id, gamepad_db.XInputGUID, raw)
 }
 
 func (w *Window) Poll() {
@@ -285,6 +284,21 @@ func (w *Window) Poll() {
 	w.Cursor.Poll()
 }
 
+// SetInputIgnored stops the input the window receives from being given to
+// its devices, so that they can be driven by something else (such as an
+// input recording). The devices are reset when the input starts being
+// ignored so that nothing is left held down.
+func (w *Window) SetInputIgnored(ignored bool) {
+	if ignored && !w.inputIgnored {
+		w.resetDevices()
+	}
+	w.inputIgnored = ignored
+}
+
+// IsInputIgnored returns true if the window isn't giving its input to its
+// devices, see #Window.SetInputIgnored
+func (w *Window) IsInputIgnored() bool { return w.inputIgnored }
+
 func (w *Window) EndUpdate() {
 	defer tracing.NewRegion("Window::EndUpdate").End()
 	w.Keyboard.EndUpdate()
@@ -417,6 +431,12 @@ func (w *Window) Center() (x int, y int) {
 }
 
 func (w *Window) becameInactive() {
+	if !w.inputIgnored {
+		w.resetDevices()
+	}
+}
+
+func (w *Window) resetDevices() {
 	w.Keyboard.Reset()
 	w.Mouse.Reset()
 	w.Touch.Reset()
@@ -436,6 +456,11 @@ func (w *Window) becameActive() {
 	klib.SliceMove(activeWindows, idx, 0)
 }
 
+func isInputEvent(eType WindowEventType) bool {
+	return eType >= windowEventTypeMouseMove &&
+		eType <= windowEventTypeControllerState
+}
+
 func goProcessEventsCommon(goWindow uint64, events unsafe.Pointer, eventCount uint32) {
 	var win *Window
 	gw := unsafe.Pointer(uintptr(goWindow))
@@ -447,6 +472,10 @@ func goProcessEventsCommon(goWindow uint64, events unsafe.Pointer, eventCount ui
 	}
 	for range eventCount {
 		eType, body := readType(events)
+		if win.inputIgnored && isInputEvent(eType) {
+			events = unsafe.Pointer(uintptr(body) + evtUnionSize)
+			continue
+		}
 		switch eType {
 		case windowEventTypeSetHandle:
 			evt := asSetHandleEvent(body)
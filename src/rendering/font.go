@@ -88,6 +88,10 @@ func (f FontFace) IsItalic() bool {
 	return strings.Contains(string(f), "Italic")
 }
 
+func (f FontFace) IsLight() bool {
+	return strings.Contains(string(f), "Light")
+}
+
 func (f FontFace) string() string { return string(f) }
 
 const (
@@ -135,6 +139,12 @@ type fontBinMetrics struct {
 	EMSize, LineHeight, Ascender, Descender, UnderlineY, UnderlineThickness float32
 }
 
+// FontMetrics are the vertical sizes of a font face at a font size, the
+// offsets are from the baseline with up being positive
+type FontMetrics struct {
+	LineHeight, Ascender, Descender, UnderlineY, UnderlineThickness float32
+}
+
 type fontBinChar struct {
 	letter                   rune
 	advance                  float32
@@ -611,6 +621,19 @@ func (cache *FontCache) MeasureString(face FontFace, text string, scale float32)
 	return maxX
 }
 
+// Metrics gives the vertical sizes of the font face at the font size
+func (cache *FontCache) Metrics(face FontFace, scale float32) FontMetrics {
+	cache.requireFace(face)
+	m := cache.fontFaces[face.string()].metrics
+	return FontMetrics{
+		LineHeight:         m.LineHeight * scale,
+		Ascender:           m.Ascender * scale,
+		Descender:          m.Descender * scale,
+		UnderlineY:         m.UnderlineY * scale,
+		UnderlineThickness: m.UnderlineThickness * scale,
+	}
+}
+
 func (cache *FontCache) MeasureStringWithin(face FontFace, text string, scale, maxWidth float32, lineHeight float32) matrix.Vec2 {
 	cache.requireFace(face)
 	fontFace := cache.fontFaces[face.string()]
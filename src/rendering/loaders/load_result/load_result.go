@@ -158,5 +158,5 @@ func (m *Mesh) GenerateBVH(threads *concurrent.Threads) *collision.BVH {
 		threads.AddWork(func(int) { construct(i*3, (i+3)*3) })
 	}
 	group.Wait()
-	return collision.BVHBottomUp(tris)
+	return collision.BVHBuildSAHParallel(tris, threads)
 }